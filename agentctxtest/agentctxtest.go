@@ -0,0 +1,266 @@
+// Package agentctxtest helps downstream users test Terraform modules built on
+// the agentctx provider. It runs the provider in-process against in-memory
+// targets, builds skill directories, and asserts on what a deployment left
+// on a target, so Terratest and terraform-plugin-testing suites can validate
+// deployments without a cloud account.
+//
+// A typical acceptance test configures the provider with a memory target,
+// applies a module, and checks the result:
+//
+//	resource.Test(t, resource.TestCase{
+//		ProtoV6ProviderFactories: agentctxtest.ProtoV6ProviderFactories,
+//		Steps: []resource.TestStep{{
+//			Config: config,
+//			Check: func(*terraform.State) error {
+//				agentctxtest.AssertDeployed(t, agentctxtest.Memory("primary"), "my-skill", sourceDir)
+//				return nil
+//			},
+//		}},
+//	})
+//
+// The same assertions work against real buckets opened with NewTarget.
+package agentctxtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/provider"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// Target is the storage abstraction the provider deploys to.
+type Target = target.Target
+
+// TargetConfig configures a target opened with NewTarget. Its fields mirror
+// the provider's target block.
+type TargetConfig = target.Config
+
+// MemoryTarget is the in-memory target behind provider targets of type
+// "memory".
+type MemoryTarget = target.MemoryTarget
+
+// FaultConfig describes faults a MemoryTarget injects. See
+// MemoryTarget.SetFaults.
+type FaultConfig = target.FaultConfig
+
+// Manifest is the manifest written alongside every deployment.
+type Manifest = manifest.Manifest
+
+// ErrNotFound is returned by Target.Get and Target.Head for missing keys.
+var ErrNotFound = target.ErrNotFound
+
+// ErrNoDeployment is returned by ReadActive when a skill has no ACTIVE
+// pointer on the target.
+var ErrNoDeployment = errors.New("agentctxtest: no active deployment")
+
+// ------------------------------------------------------------------------
+// Provider
+// ------------------------------------------------------------------------
+
+// ProtoV6ProviderFactories runs the agentctx provider in-process, for use
+// as resource.TestCase.ProtoV6ProviderFactories.
+var ProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"agentctx": providerserver.NewProtocol6WithError(provider.New("test")()),
+}
+
+// Reset discards every memory target. Memory targets are shared by name
+// across the process, so call it at the start of each test.
+func Reset(t testing.TB) {
+	t.Helper()
+	target.ResetMemoryTargets()
+	t.Cleanup(target.ResetMemoryTargets)
+}
+
+// ProviderConfig returns an HCL provider block with one memory target per
+// name. The first name is the default target.
+func ProviderConfig(names ...string) string {
+	var buf bytes.Buffer
+	buf.WriteString("provider \"agentctx\" {\n")
+	for _, n := range names {
+		fmt.Fprintf(&buf, "  target {\n    name = %q\n    type = \"memory\"\n  }\n", n)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// ------------------------------------------------------------------------
+// Targets
+// ------------------------------------------------------------------------
+
+// Memory returns the memory target with the given name, creating it if
+// needed. It is the same target the in-process provider writes to for a
+// target block of type "memory" with that name.
+func Memory(name string) *MemoryTarget {
+	return target.GetOrCreateMemoryTarget(name)
+}
+
+// NewTarget opens a real storage target, e.g. the bucket a module under
+// test deployed to, using the default credential chain of its backend.
+func NewTarget(cfg TargetConfig) (Target, error) {
+	return target.NewTarget(cfg)
+}
+
+// ------------------------------------------------------------------------
+// Bundles
+// ------------------------------------------------------------------------
+
+// WriteSkillDir writes files, keyed by slash-separated relative path, into
+// a temporary directory removed when the test finishes, and returns its
+// path.
+func WriteSkillDir(t testing.TB, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for relPath, content := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("create parent dir for %s: %s", relPath, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %s", relPath, err)
+		}
+	}
+	return dir
+}
+
+// BundleHash returns the bundle hash the provider computes for sourceDir,
+// after applying the given exclude patterns on top of the default ones.
+func BundleHash(sourceDir string, excludes ...string) (string, error) {
+	b, err := bundle.ScanBundle(sourceDir, excludes, false)
+	if err != nil {
+		return "", err
+	}
+	return b.BundleHash, nil
+}
+
+// ------------------------------------------------------------------------
+// Deployments
+// ------------------------------------------------------------------------
+
+// Deployment is the active deployment of a skill on a target.
+type Deployment struct {
+	ID       string
+	Manifest *Manifest
+	// MissingFiles lists manifest entries with no object on the target.
+	MissingFiles []string
+}
+
+// ReadActive reads the deployment the ACTIVE pointer of skillName selects
+// and checks that every file in its manifest exists. It returns
+// ErrNoDeployment when the skill is not deployed.
+func ReadActive(ctx context.Context, tgt Target, skillName string) (*Deployment, error) {
+	res, err := engine.New(concurrency.NewUniform(8)).Refresh(ctx, tgt, skillName, "", true)
+	if err != nil {
+		return nil, err
+	}
+	if res.ActiveDeploymentID == "" {
+		return nil, fmt.Errorf("%s on %s: %w", skillName, tgt.Name(), ErrNoDeployment)
+	}
+	if res.MissingManifest {
+		return nil, fmt.Errorf("%s on %s: deployment %s has no manifest", skillName, tgt.Name(), res.ActiveDeploymentID)
+	}
+	return &Deployment{
+		ID:           res.ActiveDeploymentID,
+		Manifest:     res.Manifest,
+		MissingFiles: res.MissingFiles,
+	}, nil
+}
+
+// ReadFile returns the content of relPath in the active deployment of
+// skillName.
+func ReadFile(ctx context.Context, tgt Target, skillName, relPath string) ([]byte, error) {
+	d, err := ReadActive(ctx, tgt, skillName)
+	if err != nil {
+		return nil, err
+	}
+	return readDeployedFile(ctx, tgt, skillName, d.ID, relPath)
+}
+
+// AssertDeployed fails the test unless the active deployment of skillName
+// on tgt holds exactly the bundle in sourceDir: the bundle hash matches,
+// every file is present, and every file's content matches its hash. It
+// returns the deployment's manifest.
+func AssertDeployed(t testing.TB, tgt Target, skillName, sourceDir string, excludes ...string) *Manifest {
+	t.Helper()
+	ctx := context.Background()
+
+	b, err := bundle.ScanBundle(sourceDir, excludes, false)
+	if err != nil {
+		t.Fatalf("scan %s: %s", sourceDir, err)
+	}
+	d, err := ReadActive(ctx, tgt, skillName)
+	if err != nil {
+		t.Fatalf("read active deployment: %s", err)
+	}
+
+	m := d.Manifest
+	if m.BundleHash != b.BundleHash {
+		t.Errorf("%s on %s: bundle hash %s, want %s", skillName, tgt.Name(), m.BundleHash, b.BundleHash)
+	}
+	if len(d.MissingFiles) > 0 {
+		sort.Strings(d.MissingFiles)
+		t.Errorf("%s on %s: missing files %v", skillName, tgt.Name(), d.MissingFiles)
+	}
+	for relPath, want := range b.FileHashes {
+		if _, ok := m.Files[relPath]; !ok {
+			t.Errorf("%s on %s: manifest does not list %s", skillName, tgt.Name(), relPath)
+		}
+		data, err := readDeployedFile(ctx, tgt, skillName, d.ID, relPath)
+		if err != nil {
+			if !errors.Is(err, target.ErrNotFound) {
+				t.Errorf("%s on %s: %s", skillName, tgt.Name(), err)
+			}
+			continue
+		}
+		if got := bundle.ComputeFileHashBytes(data); got != want {
+			t.Errorf("%s on %s: %s has hash %s, want %s", skillName, tgt.Name(), relPath, got, want)
+		}
+	}
+	for relPath := range m.Files {
+		if _, ok := b.FileHashes[relPath]; !ok {
+			t.Errorf("%s on %s: unexpected file %s", skillName, tgt.Name(), relPath)
+		}
+	}
+	return m
+}
+
+// AssertNotDeployed fails the test if skillName has an ACTIVE pointer on
+// tgt.
+func AssertNotDeployed(t testing.TB, tgt Target, skillName string) {
+	t.Helper()
+
+	d, err := ReadActive(context.Background(), tgt, skillName)
+	switch {
+	case errors.Is(err, ErrNoDeployment):
+	case err != nil:
+		t.Fatalf("read active deployment: %s", err)
+	default:
+		t.Errorf("%s on %s: deployment %s is active", skillName, tgt.Name(), d.ID)
+	}
+}
+
+// readDeployedFile reads relPath from the deployment's files/ prefix.
+func readDeployedFile(ctx context.Context, tgt Target, skillName, deploymentID, relPath string) ([]byte, error) {
+	key := skillName + "/.agentctx/deployments/" + deploymentID + "/files/" + relPath
+	rc, _, err := tgt.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", key, err)
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,134 @@
+package agentctxtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// recorder captures test failures so assertions can be tested.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func deploy(t *testing.T, tgt Target, skillName, dir string) *engine.DeployResult {
+	t.Helper()
+
+	b, err := bundle.ScanBundle(dir, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := engine.New(concurrency.NewUniform(4)).Deploy(context.Background(), tgt, engine.DeployInput{
+		SkillName:       skillName,
+		Bundle:          b,
+		CanonicalStore:  "memory://test",
+		ProviderVersion: "test",
+		ResourceName:    "test",
+		SourceDir:       dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestAssertDeployed(t *testing.T) {
+	Reset(t)
+	dir := WriteSkillDir(t, map[string]string{
+		"SKILL.md":       "# Skill\n",
+		"scripts/run.sh": "echo hi\n",
+	})
+	tgt := Memory("primary")
+	res := deploy(t, tgt, "my-skill", dir)
+
+	m := AssertDeployed(t, tgt, "my-skill", dir)
+	if m.DeploymentID != res.DeploymentID {
+		t.Errorf("DeploymentID = %q, want %q", m.DeploymentID, res.DeploymentID)
+	}
+	hash, err := BundleHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.BundleHash != hash {
+		t.Errorf("BundleHash = %q, want %q", m.BundleHash, hash)
+	}
+
+	data, err := ReadFile(context.Background(), tgt, "my-skill", "scripts/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "echo hi\n" {
+		t.Errorf("ReadFile = %q", data)
+	}
+}
+
+func TestAssertDeployed_DetectsTampering(t *testing.T) {
+	Reset(t)
+	dir := WriteSkillDir(t, map[string]string{"SKILL.md": "# Skill\n"})
+	tgt := Memory("primary")
+	res := deploy(t, tgt, "my-skill", dir)
+
+	key := "my-skill/.agentctx/deployments/" + res.DeploymentID + "/files/SKILL.md"
+	if err := tgt.Put(context.Background(), key, strings.NewReader("tampered"), target.PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{TB: t}
+	AssertDeployed(r, tgt, "my-skill", dir)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "SKILL.md has hash") {
+		t.Errorf("errors = %q, want one content mismatch", r.errors)
+	}
+}
+
+func TestAssertDeployed_DetectsOtherBundle(t *testing.T) {
+	Reset(t)
+	deployed := WriteSkillDir(t, map[string]string{"SKILL.md": "# Old\n", "old.txt": "x"})
+	want := WriteSkillDir(t, map[string]string{"SKILL.md": "# New\n"})
+	tgt := Memory("primary")
+	deploy(t, tgt, "my-skill", deployed)
+
+	r := &recorder{TB: t}
+	AssertDeployed(r, tgt, "my-skill", want)
+	joined := strings.Join(r.errors, "\n")
+	for _, sub := range []string{"bundle hash", "SKILL.md has hash", "unexpected file old.txt"} {
+		if !strings.Contains(joined, sub) {
+			t.Errorf("errors %q do not mention %q", r.errors, sub)
+		}
+	}
+}
+
+func TestReadActive_NoDeployment(t *testing.T) {
+	Reset(t)
+	_, err := ReadActive(context.Background(), Memory("primary"), "missing")
+	if !errors.Is(err, ErrNoDeployment) {
+		t.Fatalf("err = %v, want ErrNoDeployment", err)
+	}
+	AssertNotDeployed(t, Memory("primary"), "missing")
+}
+
+func TestProviderConfig(t *testing.T) {
+	got := ProviderConfig("a", "b")
+	if strings.Count(got, "type = \"memory\"") != 2 || !strings.Contains(got, `name = "b"`) {
+		t.Errorf("ProviderConfig = %s", got)
+	}
+}
@@ -0,0 +1,4 @@
+#!/bin/sh
+# Creates the bucket of the end-to-end acceptance tests once localstack is ready.
+set -e
+awslocal s3api create-bucket --bucket agentctx-e2e
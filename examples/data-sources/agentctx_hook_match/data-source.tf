@@ -0,0 +1,22 @@
+locals {
+  hooks = jsonencode({
+    hooks = {
+      PostToolUse = [
+        {
+          matcher = "Write|Edit"
+          hooks   = [{ type = "command", command = "$${CLAUDE_PLUGIN_ROOT}/scripts/format.sh" }]
+        },
+      ]
+    }
+  })
+}
+
+data "agentctx_hook_match" "edit" {
+  hooks_json = local.hooks
+  event      = "post_tool_use"
+  tool_name  = "Edit"
+}
+
+output "edit_hooks" {
+  value = data.agentctx_hook_match.edit.matches
+}
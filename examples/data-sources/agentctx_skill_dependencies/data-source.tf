@@ -0,0 +1,23 @@
+resource "agentctx_skill" "style_guide" {
+  source_dir = "${path.module}/skills/style-guide"
+}
+
+resource "agentctx_skill" "code_review" {
+  source_dir        = "${path.module}/skills/code-review"
+  depends_on_skills = [agentctx_skill.style_guide.skill_name]
+}
+
+# Checks every skill deployed on the target, including those deployed by
+# other stacks.
+data "agentctx_skill_dependencies" "primary" {
+  target = "primary"
+
+  depends_on = [agentctx_skill.style_guide, agentctx_skill.code_review]
+}
+
+check "skill_dependencies_deployed" {
+  assert {
+    condition     = data.agentctx_skill_dependencies.primary.complete
+    error_message = "Skills on primary depend on skills that are not deployed: ${jsonencode(data.agentctx_skill_dependencies.primary.dangling)}"
+  }
+}
@@ -0,0 +1,19 @@
+resource "agentctx_skill" "review" {
+  source_dir = "${path.module}/skills/review"
+}
+
+# Records when the bundle last changed; replaced only when the hash does.
+resource "time_static" "review_changed" {
+  triggers = {
+    bundle_hash = agentctx_skill.review.bundle_hash
+  }
+}
+
+resource "agentctx_plugin" "tools" {
+  name       = "team-tools"
+  output_dir = "${path.module}/dist/team-tools"
+  version = provider::agentctx::content_version(
+    agentctx_skill.review.bundle_hash,
+    time_static.review_changed.rfc3339,
+  )
+}
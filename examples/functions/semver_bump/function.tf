@@ -0,0 +1,16 @@
+variable "released_version" {
+  description = "Version of the plugin currently released, such as 1.4.2."
+  type        = string
+}
+
+variable "change" {
+  description = "Kind of change in this release: major, minor, or patch."
+  type        = string
+  default     = "patch"
+}
+
+resource "agentctx_plugin" "tools" {
+  name       = "team-tools"
+  output_dir = "${path.module}/dist/team-tools"
+  version    = provider::agentctx::semver_bump(var.released_version, var.change)
+}
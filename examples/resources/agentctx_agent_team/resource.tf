@@ -0,0 +1,50 @@
+# A review team: three specialised reviewers plus a coordination file that
+# tells the main conversation how to pick between them.
+resource "agentctx_agent_team" "review" {
+  name              = "review"
+  description       = "Reviews every pull request before merge."
+  output_dir        = "${path.module}/.claude/agents"
+  coordination_file = "${path.module}/.claude/teams/review.md"
+  model             = "sonnet"
+
+  selection_policy = <<-EOT
+    Run `review-security` first on any change that touches authentication,
+    secrets, or input handling. Run `review-performance` on hot paths and
+    database queries. Always finish with `review-docs`.
+  EOT
+
+  agent {
+    name        = "security"
+    description = "Reviews changes for injection, auth, and secret-handling issues."
+    tools       = ["Read", "Grep", "Glob"]
+    model       = "opus"
+
+    prompt = <<-EOT
+      You are a security reviewer. Report vulnerabilities with file, line,
+      severity, and a concrete fix.
+    EOT
+  }
+
+  agent {
+    name        = "performance"
+    description = "Reviews changes for algorithmic and query performance regressions."
+    tools       = ["Read", "Grep", "Glob", "Bash"]
+
+    prompt = <<-EOT
+      You are a performance reviewer. Flag N+1 queries, unbounded loops, and
+      allocations on hot paths.
+    EOT
+  }
+
+  agent {
+    name        = "docs"
+    description = "Checks that public APIs and user-facing behaviour changes are documented."
+    tools       = ["Read", "Grep", "Glob"]
+    model       = "haiku"
+
+    prompt = "You are a documentation reviewer. List undocumented changes."
+  }
+}
+
+# Add "@.claude/teams/review.md" to CLAUDE.md so the main conversation reads
+# the coordination file.
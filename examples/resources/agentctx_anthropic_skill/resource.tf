@@ -0,0 +1,25 @@
+# Registry-only setup: no storage targets are needed.
+provider "agentctx" {
+  anthropic {
+    api_key = var.anthropic_api_key
+  }
+}
+
+resource "agentctx_anthropic_skill" "example" {
+  source_dir    = "./skills/my-skill"
+  display_title = "My Example Skill"
+}
+
+resource "agentctx_skill_version" "current" {
+  skill_id   = agentctx_anthropic_skill.example.id
+  source_dir = "./skills/my-skill"
+}
+
+variable "anthropic_api_key" {
+  type      = string
+  sensitive = true
+}
+
+output "skill_id" {
+  value = agentctx_anthropic_skill.example.id
+}
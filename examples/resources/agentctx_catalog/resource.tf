@@ -0,0 +1,46 @@
+# An organisation-wide index of every skill and plugin this configuration
+# deploys, published next to the skills on the primary target.
+resource "agentctx_skill" "reports" {
+  source_dir = "${path.module}/skills/reports"
+  tags       = { owner = "data-platform" }
+}
+
+resource "agentctx_plugin" "review" {
+  name        = "review-tools"
+  version     = "1.2.0"
+  description = "Code review agents and commands."
+  output_dir  = "${path.module}/dist/plugins"
+}
+
+resource "agentctx_catalog" "org" {
+  name        = "acme"
+  description = "Skills and plugins maintained by Acme platform teams."
+  target      = "primary"
+
+  skill {
+    name        = agentctx_skill.reports.skill_name
+    description = agentctx_skill.reports.skill_description
+    version     = try(agentctx_skill.reports.registry_state.deployed_version, null)
+    owner       = agentctx_skill.reports.tags["owner"]
+    locations   = keys(agentctx_skill.reports.target_states)
+  }
+
+  plugin {
+    name        = agentctx_plugin.review.name
+    description = agentctx_plugin.review.description
+    version     = agentctx_plugin.review.version
+    owner       = "developer-experience"
+    locations   = [agentctx_plugin.review.plugin_dir]
+  }
+}
+
+# The same catalog as local files, e.g. for a docs site.
+resource "agentctx_catalog" "docs" {
+  name       = "acme"
+  output_dir = "${path.module}/docs/catalog"
+
+  skill {
+    name        = agentctx_skill.reports.skill_name
+    description = agentctx_skill.reports.skill_description
+  }
+}
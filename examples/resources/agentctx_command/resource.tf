@@ -0,0 +1,35 @@
+# A project slash command, invoked as /fix-issue 123 high.
+resource "agentctx_command" "fix_issue" {
+  name          = "fix-issue"
+  output_dir    = "${path.module}/.claude/commands"
+  description   = "Fix a GitHub issue"
+  argument_hint = "[issue-number] [priority]"
+  allowed_tools = ["Bash(gh issue view:*)", "Bash(git diff:*)"]
+
+  body = <<-EOT
+    Fix issue #$1 with priority $2.
+
+    Read the issue with `gh issue view $1`, find the relevant code, and
+    implement a fix with tests.
+  EOT
+}
+
+# A command generated outside the plugin and bundled into it.
+resource "agentctx_command" "release_notes" {
+  name        = "release-notes"
+  output_dir  = "${path.module}/build/commands"
+  description = "Draft release notes since the last tag"
+  model       = "claude-haiku-4-5"
+
+  body = "Summarize the commits since the last tag as release notes."
+}
+
+resource "agentctx_plugin" "release" {
+  name       = "release-tools"
+  output_dir = "${path.module}/build/plugins/release-tools"
+
+  command {
+    name        = "release-notes"
+    source_file = agentctx_command.release_notes.file_path
+  }
+}
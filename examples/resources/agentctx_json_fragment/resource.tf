@@ -0,0 +1,11 @@
+# Enable plugins in a settings file that is otherwise managed by hand (or by
+# Claude Code). Only the listed JSON paths are written or checked for drift.
+resource "agentctx_json_fragment" "enabled_plugins" {
+  path = "${path.module}/.claude/settings.json"
+
+  values = {
+    "$.enabledPlugins[\"formatter@acme-tools\"]" = jsonencode(true)
+    "$.enabledPlugins[\"linter@acme-tools\"]"    = jsonencode(true)
+    "$.permissions.additionalDirectories"        = jsonencode(["../shared"])
+  }
+}
@@ -0,0 +1,9 @@
+# Report what upgrading the skills in the production bucket would change.
+resource "agentctx_layout_migration" "production" {
+  target  = "production"
+  dry_run = true
+}
+
+output "pending_layout_changes" {
+  value = agentctx_layout_migration.production.changes
+}
@@ -0,0 +1,34 @@
+# A marketplace that lists a plugin generated alongside it, plus one hosted
+# on GitHub. Users add it with `/plugin marketplace add <repo>` and install
+# plugins as `<plugin>@acme-tools`.
+resource "agentctx_plugin" "formatter" {
+  name        = "formatter"
+  output_dir  = "${path.module}/marketplace/plugins/formatter"
+  version     = "1.2.0"
+  description = "Formats code on save."
+}
+
+resource "agentctx_marketplace" "acme" {
+  name        = "acme-tools"
+  output_dir  = "${path.module}/marketplace"
+  description = "Plugins maintained by the DevTools team."
+  version     = "1.0.0"
+
+  owner {
+    name  = "DevTools"
+    email = "devtools@example.com"
+  }
+
+  plugin {
+    name        = agentctx_plugin.formatter.name
+    source      = agentctx_plugin.formatter.plugin_dir
+    description = agentctx_plugin.formatter.description
+    version     = agentctx_plugin.formatter.version
+  }
+
+  plugin {
+    name        = "linter"
+    source      = "github:acme/claude-linter-plugin"
+    description = "Runs the team's lint rules."
+  }
+}
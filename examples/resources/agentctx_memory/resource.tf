@@ -0,0 +1,34 @@
+# Team instructions committed with the project.
+resource "agentctx_memory" "project" {
+  scope       = "project"
+  project_dir = path.module
+  title       = "Payments Service"
+
+  imports = ["README.md", "docs/architecture.md"]
+
+  section {
+    heading = "Build and Test"
+    content = "Run `make build` to build and `make test` to run the unit tests."
+    rules = [
+      "Run `make lint` before committing.",
+      "Never edit files under `gen/`; run `make generate` instead.",
+    ]
+  }
+
+  section {
+    heading = "Conventions"
+    rules   = ["Wrap errors with `fmt.Errorf(\"...: %w\", err)`."]
+    imports = ["docs/style.md"]
+  }
+}
+
+# Personal preferences for this project that should not be committed.
+resource "agentctx_memory" "local" {
+  scope       = "local"
+  project_dir = path.module
+
+  section {
+    heading = "Sandbox"
+    rules   = ["Use the staging database at localhost:5433."]
+  }
+}
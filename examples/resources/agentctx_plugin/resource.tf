@@ -7,8 +7,9 @@ resource "agentctx_plugin" "basic" {
   license     = "MIT"
 
   skill {
-    name    = "deploy-conventions"
-    content = <<-EOT
+    name        = "deploy-conventions"
+    description = "Deployment standards. Use when planning or reviewing a deployment."
+    content     = <<-EOT
       # Deployment Conventions
 
       Follow these deployment standards:
@@ -19,25 +20,31 @@ resource "agentctx_plugin" "basic" {
   }
 
   agent {
-    name    = "deployment-checker"
-    content = <<-EOT
-      ---
-      name: deployment-checker
-      description: Verifies deployment readiness and health
-      ---
-
+    name        = "deployment-checker"
+    description = "Verifies deployment readiness and health"
+    tools       = ["Read", "Bash"]
+    model       = "haiku"
+    content     = <<-EOT
       You are a deployment specialist. Check that all prerequisites
       are met before allowing a deployment to proceed.
     EOT
   }
 
   command {
-    name    = "deploy"
-    content = "Deploy the application to the specified environment."
+    name          = "deploy"
+    description   = "Deploy the application"
+    argument_hint = "[environment]"
+    allowed_tools = ["Bash(./scripts/deploy.sh:*)"]
+    content       = "Deploy the application to the $1 environment."
   }
 }
 
 # Plugin that references skills and subagents from other resources
+resource "agentctx_skill" "release_notes" {
+  source_dir = "${path.module}/skills/release-notes"
+  exclude    = ["drafts/**"]
+}
+
 resource "agentctx_subagent" "security_reviewer" {
   name        = "security-reviewer"
   description = "Reviews code for security vulnerabilities"
@@ -61,6 +68,16 @@ resource "agentctx_plugin" "enterprise" {
   license     = "Apache-2.0"
   keywords    = ["enterprise", "security", "deployment"]
 
+  requires_claude_version = ">= 2.0.45"
+
+  # Reject $${VAR} references users cannot satisfy. $${CLAUDE_PLUGIN_ROOT}
+  # paths are always checked against the files bundled below.
+  env_var_policy = "error"
+  known_env_vars = ["DEPLOY_API_TOKEN"]
+
+  # Ship tests/validate_plugin.py so CI can check the packaged plugin.
+  generate_tests = true
+
   author {
     name  = "Platform Team"
     email = "platform@example.com"
@@ -72,6 +89,12 @@ resource "agentctx_plugin" "enterprise" {
     source_dir = "${path.module}/skills/api-conventions"
   }
 
+  # Reuse the exact file set validated by an agentctx_skill resource
+  skill {
+    name          = "release-notes"
+    source_bundle = agentctx_skill.release_notes.bundle_json
+  }
+
   # Inline skill
   skill {
     name    = "error-handling"
@@ -100,7 +123,7 @@ resource "agentctx_plugin" "enterprise" {
   # Event hooks using $${CLAUDE_PLUGIN_ROOT} for portable paths
   hooks {
     post_tool_use {
-      matcher = "Write|Edit"
+      tools = ["Write", "Edit"]
       hook {
         type    = "command"
         command = "$${CLAUDE_PLUGIN_ROOT}/scripts/lint.sh"
@@ -121,6 +144,7 @@ resource "agentctx_plugin" "enterprise" {
     args    = ["--config", "$${CLAUDE_PLUGIN_ROOT}/config.json"]
     env = {
       LOG_LEVEL = "info"
+      API_TOKEN = "$${DEPLOY_API_TOKEN}"
     }
   }
 
@@ -149,6 +173,12 @@ resource "agentctx_plugin" "enterprise" {
     executable = true
   }
 
+  file {
+    path        = "servers/deploy-api"
+    source_file = "${path.module}/bin/deploy-api"
+    executable  = true
+  }
+
   file {
     path    = "config.json"
     content = jsonencode({ port = 3000, debug = false })
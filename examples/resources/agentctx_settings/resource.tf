@@ -0,0 +1,30 @@
+# Project settings shared with Claude Code. Only the keys below are managed;
+# anything Claude Code (or a developer) adds to the file is left untouched.
+resource "agentctx_settings" "project" {
+  path = "${path.module}/.claude/settings.json"
+
+  settings_json = jsonencode({
+    model             = "opus"
+    cleanupPeriodDays = 30
+
+    permissions = {
+      allow = ["Read", "Grep", "Glob", "Bash(git diff:*)"]
+      deny  = ["Bash(rm -rf:*)"]
+    }
+
+    env = {
+      CLAUDE_CODE_ENABLE_TELEMETRY = "1"
+    }
+  })
+}
+
+# Local overrides that should not be committed.
+resource "agentctx_settings" "local" {
+  path = "${path.module}/.claude/settings.local.json"
+
+  settings_json = jsonencode({
+    permissions = {
+      defaultMode = "acceptEdits"
+    }
+  })
+}
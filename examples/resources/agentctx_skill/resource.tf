@@ -13,6 +13,7 @@ resource "agentctx_skill" "example" {
     register      = true
     display_title = "My Example Skill"
     auto_version  = true
+    version_notes = "Managed by Terraform"
   }
 
   tags = {
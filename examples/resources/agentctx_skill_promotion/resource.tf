@@ -0,0 +1,24 @@
+# Stage new deployments of the skill without activating them.
+resource "agentctx_skill" "reports" {
+  source_dir          = "${path.module}/skills/reports"
+  targets             = ["production"]
+  deployment_strategy = "staged"
+}
+
+# Once a staged deployment checked out, promote it with
+#   terraform apply -var 'promoted_deployments={production="dep_..."}'
+variable "promoted_deployments" {
+  type    = map(string)
+  default = {}
+}
+
+resource "agentctx_skill_promotion" "reports" {
+  count = length(var.promoted_deployments) > 0 ? 1 : 0
+
+  skill_name     = agentctx_skill.reports.skill_name
+  deployment_ids = var.promoted_deployments
+}
+
+output "staged_deployment" {
+  value = agentctx_skill.reports.target_states["production"].staged_deployment_id
+}
@@ -0,0 +1,32 @@
+# Deploy in two steps, so that a rollback is not undone by the skill.
+resource "agentctx_skill" "reports" {
+  source_dir          = "${path.module}/skills/reports"
+  targets             = ["production"]
+  deployment_strategy = "staged"
+  deployment_alias    = var.release
+}
+
+variable "release" {
+  type = string
+}
+
+# To roll back, replace the promotion of the latest deployment with
+#   terraform apply -var 'release=2.4.0' -var 'rollback_to=2.3.1'
+variable "rollback_to" {
+  type    = string
+  default = ""
+}
+
+resource "agentctx_skill_promotion" "reports" {
+  count = var.rollback_to == "" ? 1 : 0
+
+  skill_name     = agentctx_skill.reports.skill_name
+  deployment_ids = { for name, ts in agentctx_skill.reports.target_states : name => ts.staged_deployment_id }
+}
+
+resource "agentctx_skill_rollback" "reports" {
+  count = var.rollback_to != "" ? 1 : 0
+
+  skill_name     = agentctx_skill.reports.skill_name
+  deployment_ids = { production = var.rollback_to }
+}
@@ -0,0 +1,10 @@
+# In the stack that uses a skill deployed by another stack.
+resource "agentctx_skill_verification" "summarizer" {
+  skill_name = "summarizer"
+  targets    = ["production"]
+  consumer   = "support-bot"
+}
+
+output "summarizer_deployment" {
+  value = agentctx_skill_verification.summarizer.active_deployment_ids["production"]
+}
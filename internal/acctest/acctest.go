@@ -29,6 +29,20 @@ func SetupTest(t *testing.T) {
 	})
 }
 
+// InjectFaults configures fault injection on the memory target with the
+// given name (see target.FaultConfig) and clears it when the test finishes.
+// Memory targets are wrapped in the provider's retry logic, so transient
+// faults exercise the same paths as network errors on real backends.
+func InjectFaults(t *testing.T, targetName string, cfg target.FaultConfig) *target.MemoryTarget {
+	t.Helper()
+	mt := target.GetOrCreateMemoryTarget(targetName)
+	mt.SetFaults(cfg)
+	t.Cleanup(func() {
+		mt.SetFaults(target.FaultConfig{})
+	})
+	return mt
+}
+
 // CreateTempSourceDir creates a temporary directory with the given files
 // and returns the absolute path. The files map keys are relative paths and
 // values are file contents. The directory is automatically cleaned up when
@@ -111,3 +125,18 @@ provider "agentctx" {
 }
 `, targetName, mockURL)
 }
+
+// ProviderConfigAnthropicOnly returns an HCL snippet that configures the
+// agentctx provider with an anthropic block pointing at the given mock server
+// URL and no storage targets.
+func ProviderConfigAnthropicOnly(mockURL string) string {
+	return fmt.Sprintf(`
+provider "agentctx" {
+  anthropic {
+    api_key        = "test-api-key"
+    base_url       = %q
+    destroy_remote = true
+  }
+}
+`, mockURL)
+}
@@ -0,0 +1,100 @@
+package acctest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// E2EEnvVar enables the end-to-end acceptance tests, which run against the
+// S3 target of AGENTCTX_ACC_BUCKET and AGENTCTX_ACC_REGION instead of a
+// memory target. make e2e sets it after starting the localstack container
+// of e2e/docker-compose.yml; the S3 client finds localstack through
+// AWS_ENDPOINT_URL_S3.
+const E2EEnvVar = "AGENTCTX_E2E"
+
+// PreCheckE2E skips t unless E2EEnvVar is set, and fails it when the S3
+// target it needs is not configured.
+func PreCheckE2E(t *testing.T) {
+	t.Helper()
+	if os.Getenv(E2EEnvVar) == "" {
+		t.Skipf("%s not set, skipping end-to-end test", E2EEnvVar)
+	}
+	if os.Getenv("AGENTCTX_ACC_BUCKET") == "" {
+		t.Fatalf("%s requires AGENTCTX_ACC_BUCKET", E2EEnvVar)
+	}
+}
+
+// E2ETarget returns a client for the S3 target of the end-to-end tests, so
+// a test can check the objects the provider left on it.
+func E2ETarget(t *testing.T) target.Target {
+	t.Helper()
+	tgt, err := target.NewTarget(target.Config{
+		Name:       "e2e",
+		Type:       "s3",
+		Bucket:     os.Getenv("AGENTCTX_ACC_BUCKET"),
+		Region:     e2eRegion(),
+		Prefix:     os.Getenv("AGENTCTX_ACC_PREFIX"),
+		MaxRetries: 3,
+	})
+	if err != nil {
+		t.Fatalf("creating e2e target: %s", err)
+	}
+	return tgt
+}
+
+// CreateE2ESourceDir is CreateTempSourceDir for a directory whose base
+// name, and so the skill name derived from it, is a RandomName. Skills the
+// end-to-end tests leave behind are then removed by the sweepers.
+func CreateE2ESourceDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := filepath.Join(CreateTempSourceDir(t, nil), RandomName())
+	for relPath, content := range files {
+		fullPath := filepath.Join(dir, relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("failed to create parent dir for %s: %s", relPath, err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file %s: %s", relPath, err)
+		}
+	}
+	return dir
+}
+
+// ProviderConfigE2E returns an HCL snippet that configures the agentctx
+// provider with the S3 target of the end-to-end tests, named targetName,
+// and an anthropic block pointing at the given mock server URL.
+func ProviderConfigE2E(targetName string, mockURL string) string {
+	var prefix string
+	if p := os.Getenv("AGENTCTX_ACC_PREFIX"); p != "" {
+		prefix = fmt.Sprintf("    prefix = %q\n", p)
+	}
+	return fmt.Sprintf(`
+provider "agentctx" {
+  target {
+    name   = %q
+    type   = "s3"
+    bucket = %q
+    region = %q
+%s  }
+
+  anthropic {
+    api_key        = "test-api-key"
+    base_url       = %q
+    destroy_remote = true
+  }
+}
+`, targetName, os.Getenv("AGENTCTX_ACC_BUCKET"), e2eRegion(), prefix, mockURL)
+}
+
+// e2eRegion returns AGENTCTX_ACC_REGION, or us-east-1, the region
+// localstack serves by default.
+func e2eRegion() string {
+	if r := os.Getenv("AGENTCTX_ACC_REGION"); r != "" {
+		return r
+	}
+	return "us-east-1"
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -69,6 +71,8 @@ func (m *MockAnthropicServer) handleSkills(w http.ResponseWriter, r *http.Reques
 	switch r.Method {
 	case http.MethodPost:
 		m.createSkill(w, r)
+	case http.MethodGet:
+		m.listSkills(w, r)
 	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 	}
@@ -107,7 +111,7 @@ func (m *MockAnthropicServer) handleSkillsWithID(w http.ResponseWriter, r *http.
 		case http.MethodPost:
 			m.createVersion(w, r, skillID)
 		case http.MethodGet:
-			m.listVersions(w, skillID)
+			m.listVersions(w, r, skillID)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -155,6 +159,19 @@ func (m *MockAnthropicServer) createSkill(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(skill)
 }
 
+func (m *MockAnthropicServer) listSkills(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	skills := make([]*mockSkill, 0, len(m.skills))
+	for _, skill := range m.skills {
+		skills = append(skills, skill)
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].ID < skills[j].ID })
+
+	writePage(w, r, skills)
+}
+
 func (m *MockAnthropicServer) getSkill(w http.ResponseWriter, skillID string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -246,7 +263,7 @@ func (m *MockAnthropicServer) createVersion(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(ver)
 }
 
-func (m *MockAnthropicServer) listVersions(w http.ResponseWriter, skillID string) {
+func (m *MockAnthropicServer) listVersions(w http.ResponseWriter, r *http.Request, skillID string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -255,16 +272,33 @@ func (m *MockAnthropicServer) listVersions(w http.ResponseWriter, skillID string
 		return
 	}
 
-	versions := m.versions[skillID]
-	if versions == nil {
-		versions = []*mockVersion{}
+	writePage(w, r, m.versions[skillID])
+}
+
+// mockPageSize is the page size of list requests without a limit, as in
+// the real API.
+const mockPageSize = 20
+
+// writePage writes the page of items that the limit and page query
+// parameters of r select. Page tokens are offsets into items.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	limit := mockPageSize
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
 	}
+	start, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	start = min(max(start, 0), len(items))
+	end := min(start+limit, len(items))
 
+	page := map[string]interface{}{
+		"data":     append([]T{}, items[start:end]...),
+		"has_more": end < len(items),
+	}
+	if end < len(items) {
+		page["next_page"] = strconv.Itoa(end)
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"data":     versions,
-		"has_more": false,
-	})
+	json.NewEncoder(w).Encode(page)
 }
 
 func (m *MockAnthropicServer) getVersion(w http.ResponseWriter, skillID, versionStr string) {
@@ -303,6 +337,37 @@ func (m *MockAnthropicServer) deleteVersion(w http.ResponseWriter, skillID, vers
 	writeAPIError(w, http.StatusNotFound, "not_found_error", fmt.Sprintf("Version %q not found", versionStr))
 }
 
+// AddVersion creates a version of skillID directly in the mock registry, as
+// a pipeline outside Terraform would, and returns its version string.
+func (m *MockAnthropicServer) AddVersion(skillID string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.versionCounts[skillID]++
+	vNum := m.versionCounts[skillID]
+	versionStr := fmt.Sprintf("v%d", vNum)
+	m.versions[skillID] = append(m.versions[skillID], &mockVersion{
+		ID:        fmt.Sprintf("ver_mock_%s_%03d", skillID, vNum),
+		Version:   versionStr,
+		SkillID:   skillID,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	return versionStr
+}
+
+// Versions returns the version strings of skillID in the mock registry,
+// oldest first.
+func (m *MockAnthropicServer) Versions(skillID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var versions []string
+	for _, v := range m.versions[skillID] {
+		versions = append(versions, v.Version)
+	}
+	return versions
+}
+
 func writeAPIError(w http.ResponseWriter, status int, errType, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
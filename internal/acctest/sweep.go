@@ -0,0 +1,165 @@
+package acctest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// NamePrefix starts the name of every skill an acceptance test creates on
+// a real target or in the Anthropic registry. Sweepers only delete skills
+// whose name carries it, so they can run against shared buckets and
+// workspaces.
+const NamePrefix = "tf-acc-"
+
+// RandomName returns a unique skill name with NamePrefix.
+func RandomName() string {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return NamePrefix + hex.EncodeToString(b[:])
+}
+
+// SweepTargetConfig returns the configuration of the real target that
+// acceptance tests run against, read from the environment:
+// AGENTCTX_ACC_TARGET_TYPE ("s3", "azure", or "gcs"), AGENTCTX_ACC_BUCKET,
+// AGENTCTX_ACC_REGION, AGENTCTX_ACC_PREFIX, AGENTCTX_ACC_STORAGE_ACCOUNT,
+// AGENTCTX_ACC_CONTAINER_NAME, AGENTCTX_ACC_SAS_TOKEN, and
+// AGENTCTX_ACC_GCS_CREDENTIALS. region, the region passed to -sweep, is
+// used when AGENTCTX_ACC_REGION is unset. ok is false when no target type
+// is set.
+func SweepTargetConfig(region string) (cfg target.Config, ok bool) {
+	typ := os.Getenv("AGENTCTX_ACC_TARGET_TYPE")
+	if typ == "" {
+		return target.Config{}, false
+	}
+	if r := os.Getenv("AGENTCTX_ACC_REGION"); r != "" {
+		region = r
+	}
+	return target.Config{
+		Name:           "sweep",
+		Type:           typ,
+		Bucket:         os.Getenv("AGENTCTX_ACC_BUCKET"),
+		Region:         region,
+		Prefix:         os.Getenv("AGENTCTX_ACC_PREFIX"),
+		StorageAccount: os.Getenv("AGENTCTX_ACC_STORAGE_ACCOUNT"),
+		ContainerName:  os.Getenv("AGENTCTX_ACC_CONTAINER_NAME"),
+		GCSCredentials: os.Getenv("AGENTCTX_ACC_GCS_CREDENTIALS"),
+		AzureSASToken:  os.Getenv("AGENTCTX_ACC_SAS_TOKEN"),
+		MaxRetries:     3,
+	}, true
+}
+
+// SweepRegistryClient returns a client for the Anthropic registry that
+// acceptance tests run against, configured by ANTHROPIC_API_KEY and, if
+// set, ANTHROPIC_BASE_URL. ok is false when no API key is set.
+func SweepRegistryClient() (client *anthropic.Client, ok bool) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, false
+	}
+	return anthropic.NewClient(anthropic.ClientConfig{
+		APIKey:         apiKey,
+		BaseURL:        os.Getenv("ANTHROPIC_BASE_URL"),
+		MaxRetries:     3,
+		TimeoutSeconds: 30,
+		DestroyRemote:  true,
+	}), true
+}
+
+// SweepTarget deletes every object under a top-level name with NamePrefix
+// on tgt, such as the skills and catalog prefixes of acceptance tests, and
+// the previews of skills named with NamePrefix. It returns the names it
+// removed, sorted, with previews as previews/<preview_id>/<skill_name>. A
+// failed delete does not stop the sweep; the errors are returned together.
+func SweepTarget(ctx context.Context, tgt target.Target) ([]string, error) {
+	var keys []string
+	skills := make(map[string]bool)
+
+	objects, err := tgt.List(ctx, NamePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list skills: %w", err)
+	}
+	for _, obj := range objects {
+		name, _, _ := strings.Cut(obj.Key, "/")
+		keys = append(keys, obj.Key)
+		skills[name] = true
+	}
+
+	// Previews live under previews/<preview_id>/<skill_name>/.
+	objects, err = tgt.List(ctx, "previews/")
+	if err != nil {
+		return nil, fmt.Errorf("list previews: %w", err)
+	}
+	for _, obj := range objects {
+		parts := strings.SplitN(obj.Key, "/", 4)
+		if len(parts) < 4 || !strings.HasPrefix(parts[2], NamePrefix) {
+			continue
+		}
+		keys = append(keys, obj.Key)
+		skills[strings.Join(parts[:3], "/")] = true
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := tgt.Delete(ctx, key); err != nil && !errors.Is(err, target.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("delete %s: %w", key, err))
+		}
+	}
+
+	names := make([]string, 0, len(skills))
+	for name := range skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, errors.Join(errs...)
+}
+
+// SweepRegistry deletes every custom skill in the registry whose display
+// title starts with NamePrefix, with all its versions, and returns the IDs
+// of the skills it removed. A skill that cannot be removed does not stop
+// the sweep; the errors are returned together.
+func SweepRegistry(ctx context.Context, client *anthropic.Client) ([]string, error) {
+	skills, err := client.ListSkills(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		removed []string
+		errs    []error
+	)
+	for _, skill := range skills {
+		if !strings.HasPrefix(skill.DisplayTitle, NamePrefix) {
+			continue
+		}
+		if err := deleteRegistrySkill(ctx, client, skill.ID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, skill.ID)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// deleteRegistrySkill deletes skillID after its versions, which the
+// registry requires.
+func deleteRegistrySkill(ctx context.Context, client *anthropic.Client, skillID string) error {
+	versions, err := client.ListVersions(ctx, skillID)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if err := client.DeleteVersion(ctx, skillID, v.Version); err != nil {
+			return err
+		}
+	}
+	return client.DeleteSkill(ctx, skillID)
+}
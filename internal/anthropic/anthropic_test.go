@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/progress"
 )
 
 // ---------------------------------------------------------------------------
@@ -24,8 +28,8 @@ func TestNewClient_Defaults(t *testing.T) {
 	if c.maxRetries != defaultMaxRetries {
 		t.Errorf("maxRetries = %d, want %d", c.maxRetries, defaultMaxRetries)
 	}
-	if c.httpClient.Timeout != time.Duration(defaultTimeoutSeconds)*time.Second {
-		t.Errorf("timeout = %v, want %v", c.httpClient.Timeout, time.Duration(defaultTimeoutSeconds)*time.Second)
+	if want := time.Duration(defaultTimeoutSeconds) * time.Second; c.metadataTimeout != want || c.uploadTimeout != want {
+		t.Errorf("timeouts = %v, %v, want %v", c.metadataTimeout, c.uploadTimeout, want)
 	}
 	if c.apiKey != "test-key" {
 		t.Errorf("apiKey = %q, want %q", c.apiKey, "test-key")
@@ -46,8 +50,8 @@ func TestNewClient_Custom(t *testing.T) {
 	if c.maxRetries != 5 {
 		t.Errorf("maxRetries = %d, want %d", c.maxRetries, 5)
 	}
-	if c.httpClient.Timeout != 60*time.Second {
-		t.Errorf("timeout = %v, want %v", c.httpClient.Timeout, 60*time.Second)
+	if c.metadataTimeout != 60*time.Second || c.uploadTimeout != 60*time.Second {
+		t.Errorf("timeouts = %v, %v, want %v", c.metadataTimeout, c.uploadTimeout, 60*time.Second)
 	}
 	if c.apiKey != "custom-key" {
 		t.Errorf("apiKey = %q, want %q", c.apiKey, "custom-key")
@@ -134,7 +138,7 @@ func TestCreateSkill(t *testing.T) {
 	}
 
 	c := testClient(t, server)
-	skill, err := c.CreateSkill(context.Background(), tmpDir, "My Test Skill")
+	skill, err := c.CreateSkill(context.Background(), tmpDir, "My Test Skill", "")
 	if err != nil {
 		t.Fatalf("CreateSkill() returned error: %v", err)
 	}
@@ -241,6 +245,104 @@ func TestDeleteSkill(t *testing.T) {
 	}
 }
 
+func TestReadOnlyClient(t *testing.T) {
+	var writes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(skillJSON())
+	}))
+	defer server.Close()
+
+	c := testClient(t, server)
+	c.readOnly = true
+	ctx := context.Background()
+
+	if _, err := c.GetSkill(ctx, "skill-abc-123"); err != nil {
+		t.Fatalf("GetSkill() returned error: %v", err)
+	}
+	if _, err := c.CreateSkill(ctx, t.TempDir(), "My Test Skill", ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CreateSkill() error = %v, want ErrReadOnly", err)
+	}
+	if err := c.DeleteSkill(ctx, "skill-abc-123"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("DeleteSkill() error = %v, want ErrReadOnly", err)
+	}
+	if n := writes.Load(); n != 0 {
+		t.Errorf("server received %d write requests, want 0", n)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"overloaded"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(skillJSON())
+	}))
+	defer server.Close()
+
+	c := testClient(t, server)
+	c.breaker = newBreaker(2)
+	now := time.Now()
+	c.breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := c.GetSkill(ctx, "skill-abc-123")
+		if !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("GetSkill() #%d error = %v, want ErrUnavailable from the server", i+1, err)
+		}
+	}
+	if _, err := c.GetSkill(ctx, "skill-abc-123"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GetSkill() with open breaker error = %v, want ErrCircuitOpen", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server received %d requests, want 2", n)
+	}
+
+	// After the cooldown a probe is sent; its success closes the breaker.
+	healthy.Store(true)
+	now = now.Add(breakerCooldown)
+	if _, err := c.GetSkill(ctx, "skill-abc-123"); err != nil {
+		t.Fatalf("GetSkill() probe returned error: %v", err)
+	}
+	if _, err := c.GetSkill(ctx, "skill-abc-123"); err != nil {
+		t.Fatalf("GetSkill() after probe returned error: %v", err)
+	}
+	if n := requests.Load(); n != 4 {
+		t.Errorf("server received %d requests, want 4", n)
+	}
+}
+
+func TestSkipOnFailure(t *testing.T) {
+	unavailable := &unavailableError{errors.New("anthropic: request failed after 0 retries")}
+	rejected := &APIError{StatusCode: http.StatusBadRequest, Message: "bad request"}
+
+	skip := NewClient(ClientConfig{FailurePolicy: FailurePolicyWarnAndSkip})
+	if !skip.SkipOnFailure(unavailable) {
+		t.Error("SkipOnFailure(unavailable) = false with warn_and_skip, want true")
+	}
+	if !skip.SkipOnFailure(fmt.Errorf("GET /v1/skills: %w", ErrCircuitOpen)) {
+		t.Error("SkipOnFailure(ErrCircuitOpen) = false with warn_and_skip, want true")
+	}
+	if skip.SkipOnFailure(rejected) {
+		t.Error("SkipOnFailure(APIError) = true, want false")
+	}
+	if NewClient(ClientConfig{}).SkipOnFailure(unavailable) {
+		t.Error("SkipOnFailure(unavailable) = true with the default policy, want false")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Request validation tests
 // ---------------------------------------------------------------------------
@@ -296,7 +398,7 @@ func TestAPIError(t *testing.T) {
 	os.WriteFile(tmpDir+"/test.py", []byte("x"), 0o644)
 
 	c := testClient(t, server)
-	_, err := c.CreateSkill(context.Background(), tmpDir, "")
+	_, err := c.CreateSkill(context.Background(), tmpDir, "", "")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -360,6 +462,185 @@ func TestRetry429(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Idempotency keys on create retries
+// ---------------------------------------------------------------------------
+
+func TestIdempotencyKey(t *testing.T) {
+	a := IdempotencyKey("skill-abc-123", "sha256:aa")
+	if a != IdempotencyKey("skill-abc-123", "sha256:aa") {
+		t.Error("IdempotencyKey is not deterministic")
+	}
+	if a == IdempotencyKey("skill-abc-123", "sha256:bb") {
+		t.Error("different bundle hashes produced the same key")
+	}
+	// Parts are delimited, so shifting characters between them changes the key.
+	if IdempotencyKey("ab", "c") == IdempotencyKey("a", "bc") {
+		t.Error("part boundaries are not part of the key")
+	}
+}
+
+func TestCreateVersion_RetriesReuseIdempotencyKey(t *testing.T) {
+	var callCount int32
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if atomic.AddInt32(&callCount, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"type":"overloaded_error","message":"overloaded"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(versionJSON())
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(tmpDir+"/test.py", []byte("print('hello')"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(ClientConfig{APIKey: "test-api-key", MaxRetries: 1, TimeoutSeconds: 5})
+	c.baseURL = server.URL
+
+	key := IdempotencyKey("skill-abc-123", "sha256:aa")
+	if _, err := c.CreateVersion(context.Background(), "skill-abc-123", tmpDir, CreateVersionRequest{IdempotencyKey: key}); err != nil {
+		t.Fatalf("CreateVersion() returned error: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("server received %d requests, want 2", len(keys))
+	}
+	for i, k := range keys {
+		if k != key {
+			t.Errorf("attempt %d Idempotency-Key = %q, want %q", i+1, k, key)
+		}
+	}
+}
+
+func TestCreateVersion_ReportsProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength <= 0 || len(r.TransferEncoding) > 0 {
+			t.Errorf("ContentLength = %d, TransferEncoding = %v; want a sized body", r.ContentLength, r.TransferEncoding)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(versionJSON())
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(tmpDir+"/test.py", []byte("print('hello')"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	progressPath := filepath.Join(t.TempDir(), "progress.jsonl")
+
+	c := NewClient(ClientConfig{APIKey: "test-api-key", TimeoutSeconds: 5, Progress: progress.NewReporter(0, progressPath)})
+	c.baseURL = server.URL
+	if _, err := c.CreateVersion(context.Background(), "skill-abc-123", tmpDir, CreateVersionRequest{}); err != nil {
+		t.Fatalf("CreateVersion() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var last progress.Snapshot
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if !last.Done || last.FilesDone != 1 || last.BytesTotal == 0 || last.BytesDone != last.BytesTotal {
+		t.Errorf("final progress = %+v, want one file and every byte done", last)
+	}
+	if last.Operation != "anthropic_version" || last.Labels["skill_id"] != "skill-abc-123" {
+		t.Errorf("final progress = %+v, want the version upload labelled with its skill", last)
+	}
+}
+
+func TestCreateSkill_NoIdempotencyKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Header["Idempotency-Key"]; ok {
+			t.Errorf("Idempotency-Key sent for an empty key")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(skillJSON())
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(tmpDir+"/test.py", []byte("print('hello')"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := testClient(t, server).CreateSkill(context.Background(), tmpDir, "", ""); err != nil {
+		t.Fatalf("CreateSkill() returned error: %v", err)
+	}
+}
+
+func TestDeleteOnDestroy(t *testing.T) {
+	cases := []struct {
+		onDestroy     string
+		destroyRemote bool
+		want          bool
+	}{
+		{"", false, false},
+		{"", true, true},
+		{OnDestroyDelete, false, true},
+		{OnDestroyDetach, true, false},
+	}
+	for _, tc := range cases {
+		c := NewClient(ClientConfig{APIKey: "test-key", DestroyRemote: tc.destroyRemote})
+		if got := c.DeleteOnDestroy(tc.onDestroy); got != tc.want {
+			t.Errorf("DeleteOnDestroy(%q) with destroy_remote=%v = %v, want %v", tc.onDestroy, tc.destroyRemote, got, tc.want)
+		}
+	}
+}
+func TestNewClient_OperationClassTimeouts(t *testing.T) {
+	c := NewClient(ClientConfig{APIKey: "test-key", TimeoutSeconds: 20, UploadTimeoutSeconds: 600})
+	if c.metadataTimeout != 20*time.Second || c.uploadTimeout != 600*time.Second {
+		t.Errorf("timeouts = %v, %v, want 20s, 10m0s", c.metadataTimeout, c.uploadTimeout)
+	}
+	c = NewClient(ClientConfig{APIKey: "test-key", MetadataTimeoutSeconds: 10})
+	if c.metadataTimeout != 10*time.Second || c.uploadTimeout != time.Duration(defaultTimeoutSeconds)*time.Second {
+		t.Errorf("timeouts = %v, %v, want 10s and the default", c.metadataTimeout, c.uploadTimeout)
+	}
+}
+
+func TestOperationClassTimeouts(t *testing.T) {
+	// Every response takes longer than the metadata timeout but well within
+	// the upload timeout.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			w.Write(versionJSON())
+			return
+		}
+		w.Write(skillJSON())
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(tmpDir+"/test.py", []byte("print('hello')"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := testClient(t, server)
+	c.metadataTimeout = 50 * time.Millisecond
+	c.uploadTimeout = 5 * time.Second
+
+	if _, err := c.GetSkill(context.Background(), "skill-abc-123"); !errors.Is(err, ErrUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetSkill() error = %v, want an unavailable error from the metadata timeout", err)
+	}
+	if _, err := c.CreateVersion(context.Background(), "skill-abc-123", tmpDir, CreateVersionRequest{}); err != nil {
+		t.Errorf("CreateVersion() returned error: %v", err)
+	}
+}
+
+
 // ---------------------------------------------------------------------------
 // MaxRetries: 0 means no retries (fix #8)
 // ---------------------------------------------------------------------------
@@ -456,8 +737,8 @@ func TestCreateVersion(t *testing.T) {
 	}
 
 	c := testClient(t, server)
-	// Fix #7: CreateVersion takes (ctx, skillID, sourceDir) — no bundleHash.
-	ver, err := c.CreateVersion(context.Background(), "skill-abc-123", tmpDir)
+	// Fix #7: CreateVersion takes no bundleHash.
+	ver, err := c.CreateVersion(context.Background(), "skill-abc-123", tmpDir, CreateVersionRequest{})
 	if err != nil {
 		t.Fatalf("CreateVersion() returned error: %v", err)
 	}
@@ -472,6 +753,41 @@ func TestCreateVersion(t *testing.T) {
 	}
 }
 
+func TestCreateVersion_NotesAndLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			t.Errorf("ParseMultipartForm failed: %v", err)
+		}
+		if got := r.FormValue("notes"); got != "Tighten lint rules" {
+			t.Errorf("notes = %q, want %q", got, "Tighten lint rules")
+		}
+		if got := r.FormValue("labels[team]"); got != "platform" {
+			t.Errorf("labels[team] = %q, want %q", got, "platform")
+		}
+		if got := r.FormValue("labels[commit]"); got != "abc123" {
+			t.Errorf("labels[commit] = %q, want %q", got, "abc123")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(versionJSON())
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(tmpDir+"/test.py", []byte("print('hello')"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := testClient(t, server)
+	_, err := c.CreateVersion(context.Background(), "skill-abc-123", tmpDir, CreateVersionRequest{
+		Notes:  "Tighten lint rules",
+		Labels: map[string]string{"team": "platform", "commit": "abc123"},
+	})
+	if err != nil {
+		t.Fatalf("CreateVersion() returned error: %v", err)
+	}
+}
+
 func TestGetVersion(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -584,6 +900,107 @@ func TestListVersions(t *testing.T) {
 	}
 }
 
+func TestListSkills_FollowsPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/skills" {
+			t.Errorf("request = %s %s, want GET /v1/skills", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("source"); got != "custom" {
+			t.Errorf("source = %q, want custom", got)
+		}
+
+		var resp ListSkillsResponse
+		switch page := r.URL.Query().Get("page"); page {
+		case "":
+			resp = ListSkillsResponse{
+				Data:     []Skill{{ID: "skill_1", DisplayTitle: "one", CreatedAt: skillFixtureTime}},
+				HasMore:  true,
+				NextPage: "page_2",
+			}
+		case "page_2":
+			resp = ListSkillsResponse{
+				Data: []Skill{{ID: "skill_2", DisplayTitle: "two", CreatedAt: skillFixtureTime}},
+			}
+		default:
+			t.Errorf("unexpected page %q", page)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	c := testClient(t, server)
+	skills, err := c.ListSkills(context.Background())
+	if err != nil {
+		t.Fatalf("ListSkills() returned error: %v", err)
+	}
+	if len(skills) != 2 || skills[0].ID != "skill_1" || skills[1].ID != "skill_2" {
+		t.Errorf("skills = %+v, want skill_1 and skill_2", skills)
+	}
+}
+
+func TestListVersions_FollowsPages(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if got := r.URL.Query().Get("limit"); got != "2" {
+			t.Errorf("limit = %q, want 2", got)
+		}
+
+		var resp ListVersionsResponse
+		switch page := r.URL.Query().Get("page"); page {
+		case "":
+			resp = ListVersionsResponse{
+				Data: []SkillVersion{
+					{ID: "sv_1", Version: "100", SkillID: "skill-abc-123", CreatedAt: skillFixtureTime},
+					{ID: "sv_2", Version: "200", SkillID: "skill-abc-123", CreatedAt: skillFixtureTime},
+				},
+				HasMore:  true,
+				NextPage: "page_2",
+			}
+		case "page_2":
+			resp = ListVersionsResponse{
+				Data: []SkillVersion{{ID: "sv_3", Version: "300", SkillID: "skill-abc-123", CreatedAt: skillFixtureTime}},
+			}
+		default:
+			t.Errorf("unexpected page %q", page)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	c := testClient(t, server)
+	c.pageSize = 2
+	versions, err := c.ListVersions(context.Background(), "skill-abc-123")
+	if err != nil {
+		t.Fatalf("ListVersions() returned error: %v", err)
+	}
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.Version)
+	}
+	if strings.Join(got, ",") != "100,200,300" {
+		t.Errorf("versions = %v, want 100, 200, and 300", got)
+	}
+
+	// An iteration that stops on the first page never requests the second.
+	atomic.StoreInt32(&requests, 0)
+	for v, err := range c.Versions(context.Background(), "skill-abc-123") {
+		if err != nil {
+			t.Fatalf("Versions() yielded error: %v", err)
+		}
+		if v.Version == "200" {
+			break
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
 // TestDeleteSkillRequiresNoVersions validates the pattern from fix #2:
 // The API rejects DeleteSkill when versions exist (409 Conflict).
 // The correct approach is to delete all versions first, then the skill.
@@ -758,3 +1175,22 @@ func TestVerifyBundle_ModifiedFile(t *testing.T) {
 		t.Error("ActualHash should differ from expected hash when a file is modified")
 	}
 }
+
+func FuzzParseAPIError(f *testing.F) {
+	f.Add(404, []byte(`{"type":"error","error":{"type":"not_found_error","message":"The requested resource was not found"}}`))
+	f.Add(400, []byte(`{"type":"invalid_request_error","message":"bad"}`))
+	f.Add(500, []byte(`<html>Internal Server Error</html>`))
+	f.Add(429, []byte(`{"error":null}`))
+	f.Add(502, []byte{})
+
+	f.Fuzz(func(t *testing.T, status int, body []byte) {
+		apiErr := parseAPIError(status, body)
+		if apiErr == nil {
+			t.Fatal("parseAPIError returned nil")
+		}
+		if apiErr.StatusCode != status {
+			t.Fatalf("StatusCode = %d, want %d", apiErr.StatusCode, status)
+		}
+		_ = apiErr.Error()
+	})
+}
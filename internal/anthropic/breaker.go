@@ -0,0 +1,99 @@
+package anthropic
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerThreshold = 3
+	breakerCooldown         = 30 * time.Second
+)
+
+// Values accepted by the registry_failure_policy provider attribute.
+const (
+	// FailurePolicyFail fails the apply when the registry is unavailable.
+	FailurePolicyFail = "fail"
+	// FailurePolicyWarnAndSkip deploys anthropic-enabled skills to storage
+	// only, with a warning, when the registry is unavailable.
+	FailurePolicyWarnAndSkip = "warn_and_skip"
+)
+
+// ErrUnavailable is wrapped by the error of every request that failed after
+// exhausting its retries on network errors, 429s, or 5xx responses, and of
+// every request the circuit breaker refused to send.
+var ErrUnavailable = errors.New("anthropic: registry unavailable")
+
+// ErrCircuitOpen is returned, wrapping ErrUnavailable, for requests that
+// were not sent because too many requests before them failed.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrUnavailable)
+
+// unavailableError marks err as caused by an unavailable registry without
+// changing its message.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string        { return e.err.Error() }
+func (e *unavailableError) Unwrap() error        { return e.err }
+func (e *unavailableError) Is(target error) bool { return target == ErrUnavailable }
+
+// breaker is a circuit breaker shared by all requests of a Client. After
+// threshold consecutive requests fail with ErrUnavailable it opens, and
+// requests fail with ErrCircuitOpen without being sent. Once the cooldown
+// has passed, one request is let through as a probe: success closes the
+// breaker, another failure keeps it open for a further cooldown. Any
+// response from the API, including an error status that is not retried,
+// counts as success.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	now       func() time.Time
+}
+
+func newBreaker(threshold int) *breaker {
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	return &breaker{threshold: threshold, cooldown: breakerCooldown, now: time.Now}
+}
+
+// allow returns ErrCircuitOpen, annotated with the request, if the breaker
+// is open.
+func (b *breaker) allow(method, path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.now().Sub(b.openedAt) < b.cooldown {
+		return fmt.Errorf("%s %s: %w", method, path, ErrCircuitOpen)
+	}
+	// Half-open: this request probes the registry. Restarting the cooldown
+	// keeps concurrent requests out until it returns.
+	b.openedAt = b.now()
+	return nil
+}
+
+// record updates the breaker with the outcome of a request that allow let
+// through. Errors that say nothing about the registry, such as a canceled
+// context, leave it unchanged.
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, ErrUnavailable):
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openedAt = b.now()
+		}
+	case err == nil, errors.As(err, &apiErr):
+		b.failures = 0
+	}
+}
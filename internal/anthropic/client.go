@@ -3,12 +3,17 @@ package anthropic
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/progress"
 )
 
 const (
@@ -17,8 +22,17 @@ const (
 	defaultTimeoutSeconds = 30
 	anthropicVersion      = "2023-06-01"
 	anthropicBeta         = "skills-2025-10-02"
+
+	// idempotencyKeyHeader carries the key that lets the API recognise a
+	// retried create request and return the original result instead of
+	// creating a duplicate.
+	idempotencyKeyHeader = "Idempotency-Key"
 )
 
+// ErrReadOnly is returned for every request that is not a GET when the
+// client is configured with ReadOnly.
+var ErrReadOnly = errors.New("anthropic: client is read-only")
+
 // ClientConfig holds configuration for constructing a new Client.
 type ClientConfig struct {
 	APIKey         string
@@ -26,15 +40,61 @@ type ClientConfig struct {
 	TimeoutSeconds int
 	DestroyRemote  bool
 	BaseURL        string
+	// MetadataTimeoutSeconds bounds each attempt of a JSON request, such
+	// as reading or deleting a skill or version, including reading the
+	// response. Zero or less uses TimeoutSeconds.
+	MetadataTimeoutSeconds int
+	// UploadTimeoutSeconds bounds each attempt of a request that transfers
+	// a version's files: creating a skill or version, and downloading a
+	// version's content. Zero or less uses TimeoutSeconds.
+	UploadTimeoutSeconds int
+	// Progress, when non-nil, reports the progress of version uploads.
+	Progress *progress.Reporter
+	// ReadOnly rejects every request that would modify the registry with
+	// ErrReadOnly before it is sent.
+	ReadOnly bool
+	// BreakerThreshold is the number of consecutive requests failing with
+	// ErrUnavailable after which the client stops sending requests for a
+	// while. Zero or less uses the default of 3.
+	BreakerThreshold int
+	// FailurePolicy is FailurePolicyFail (the default when empty) or
+	// FailurePolicyWarnAndSkip. See SkipOnFailure.
+	FailurePolicy string
+	// DebugHTTP logs every request attempt at debug level: method, path,
+	// status, duration, and redacted JSON payloads. File contents and
+	// credentials are never logged.
+	DebugHTTP bool
+	// RequestsPerMinute, when positive, spaces request attempts evenly so
+	// the client sends at most this many per minute, and makes a 429 with
+	// a Retry-After header pause every request of the client. Zero or less
+	// sends requests as soon as they are made.
+	RequestsPerMinute int
+	// PageSize is the number of skills or versions requested per page of
+	// a list request, sent as its limit. Zero or less leaves the page size
+	// to the API.
+	PageSize int
 }
 
 // Client is an HTTP client for the Anthropic Skills API.
 type Client struct {
-	httpClient    *http.Client
+	httpClient *http.Client
+	// metadataTimeout and uploadTimeout bound each attempt of a JSON
+	// request and of a file transfer respectively, including reading the
+	// response body.
+	metadataTimeout time.Duration
+	uploadTimeout   time.Duration
+
 	apiKey        string
 	maxRetries    int
 	destroyRemote bool
 	baseURL       string
+	progress      *progress.Reporter
+	readOnly      bool
+	breaker       *breaker
+	failurePolicy string
+	debugHTTP     bool
+	pacer         *pacer
+	pageSize      int
 }
 
 // NewClient creates a new Anthropic API client from the given configuration.
@@ -48,6 +108,13 @@ func NewClient(cfg ClientConfig) *Client {
 	if timeoutSec <= 0 {
 		timeoutSec = defaultTimeoutSeconds
 	}
+	metadataSec, uploadSec := cfg.MetadataTimeoutSeconds, cfg.UploadTimeoutSeconds
+	if metadataSec <= 0 {
+		metadataSec = timeoutSec
+	}
+	if uploadSec <= 0 {
+		uploadSec = timeoutSec
+	}
 
 	baseURL := defaultBaseURL
 	if cfg.BaseURL != "" {
@@ -55,13 +122,23 @@ func NewClient(cfg ClientConfig) *Client {
 	}
 
 	return &Client{
-		httpClient: &http.Client{
-			Timeout: time.Duration(timeoutSec) * time.Second,
-		},
+		// Each attempt gets a deadline for its class from send instead of
+		// one client-wide timeout.
+		httpClient:      &http.Client{},
+		metadataTimeout: time.Duration(metadataSec) * time.Second,
+		uploadTimeout:   time.Duration(uploadSec) * time.Second,
+
 		apiKey:        cfg.APIKey,
 		maxRetries:    maxRetries,
 		destroyRemote: cfg.DestroyRemote,
 		baseURL:       baseURL,
+		progress:      cfg.Progress,
+		readOnly:      cfg.ReadOnly,
+		breaker:       newBreaker(cfg.BreakerThreshold),
+		failurePolicy: cfg.FailurePolicy,
+		debugHTTP:     cfg.DebugHTTP,
+		pacer:         newPacer(cfg.RequestsPerMinute),
+		pageSize:      cfg.PageSize,
 	}
 }
 
@@ -71,16 +148,77 @@ func (c *Client) DestroyRemote() bool {
 	return c.destroyRemote
 }
 
+// SkipOnFailure reports whether a registry operation that failed with err
+// should be skipped, leaving a storage-only deployment, instead of failing
+// the apply: the failure policy is FailurePolicyWarnAndSkip and err means
+// the registry is unavailable rather than that the request was rejected.
+func (c *Client) SkipOnFailure(err error) bool {
+	return c.failurePolicy == FailurePolicyWarnAndSkip && errors.Is(err, ErrUnavailable)
+}
+
+// Values accepted by the on_destroy attribute of registry skill resources.
+const (
+	// OnDestroyDelete deletes the skill and its versions from the registry.
+	OnDestroyDelete = "delete"
+	// OnDestroyDetach removes the skill from Terraform state only, leaving
+	// it and every version in the registry for another owner to adopt.
+	OnDestroyDetach = "detach"
+)
+
+// DeleteOnDestroy reports whether destroying a registry skill should delete
+// it remotely. An explicit onDestroy wins; an empty value falls back to the
+// provider's destroy_remote setting.
+func (c *Client) DeleteOnDestroy(onDestroy string) bool {
+	switch onDestroy {
+	case OnDestroyDelete:
+		return true
+	case OnDestroyDetach:
+		return false
+	default:
+		return c.destroyRemote
+	}
+}
+
+// IdempotencyKey derives a stable idempotency key from parts, typically a
+// skill identifier and a bundle hash. The same parts always produce the same
+// key, so a create that timed out and is retried, within the same apply or a
+// later one, resolves to the object the first attempt created.
+func IdempotencyKey(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return "agentctx-" + hex.EncodeToString(h.Sum(nil))
+}
+
+// checkWritable returns ErrReadOnly if the client is read-only and method
+// would modify the registry.
+func (c *Client) checkWritable(method, path string) error {
+	if c.readOnly && method != http.MethodGet {
+		return fmt.Errorf("%s %s: %w", method, path, ErrReadOnly)
+	}
+	return nil
+}
+
 // do performs an HTTP request with JSON encoding/decoding and retry logic.
 // method is the HTTP method, path is appended to the base URL, body is
 // JSON-encoded as the request body (nil for no body), and result is decoded
 // from the response body (nil to discard the response).
-func (c *Client) do(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, result interface{}) (err error) {
+	if err := c.checkWritable(method, path); err != nil {
+		return err
+	}
+	if err := c.breaker.allow(method, path); err != nil {
+		return err
+	}
+	defer func() { c.breaker.record(err) }()
 	url := c.baseURL + path
 
 	var bodyReader io.Reader
+	var encoded []byte
 	if body != nil {
-		encoded, err := json.Marshal(body)
+		encoded, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("anthropic: marshal request body: %w", err)
 		}
@@ -105,6 +243,9 @@ func (c *Client) do(ctx context.Context, method, path string, body interface{},
 				bodyReader = bytes.NewReader(encoded)
 			}
 		}
+		if err := c.pacer.wait(ctx); err != nil {
+			return err
+		}
 
 		req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 		if err != nil {
@@ -120,15 +261,21 @@ func (c *Client) do(ctx context.Context, method, path string, body interface{},
 			req.Header.Set("Content-Type", "application/json")
 		}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
+		start := time.Now()
+		resp, respBody, err := c.send(req, c.metadataTimeout)
+		if resp == nil {
+			if c.debugHTTP {
+				c.logExchange(ctx, debugExchange{method: method, path: path, attempt: attempt, start: start, request: redactJSON(encoded), err: err})
+			}
 			lastErr = fmt.Errorf("anthropic: request failed: %w", err)
-			// Network errors are retryable.
+			// Network errors, including an attempt running out of time,
+			// are retryable.
 			continue
 		}
 
-		respBody, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
+		if c.debugHTTP {
+			c.logExchange(ctx, debugExchange{method: method, path: path, attempt: attempt, start: start, resp: resp, request: redactJSON(encoded), response: redactJSON(respBody), err: err})
+		}
 		if err != nil {
 			lastErr = fmt.Errorf("anthropic: read response body: %w", err)
 			continue
@@ -149,6 +296,9 @@ func (c *Client) do(ctx context.Context, method, path string, body interface{},
 
 		// Retry on 429 (rate limit) and 5xx (server errors).
 		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			if resp.StatusCode == 429 {
+				c.pacer.backOff(resp)
+			}
 			lastErr = apiErr
 			continue
 		}
@@ -158,15 +308,16 @@ func (c *Client) do(ctx context.Context, method, path string, body interface{},
 	}
 
 	// All retries exhausted.
-	if lastErr != nil {
-		return fmt.Errorf("anthropic: request failed after %d retries: %w", c.maxRetries, lastErr)
-	}
-	return fmt.Errorf("anthropic: request failed after %d retries", c.maxRetries)
+	return c.exhausted(lastErr)
 }
 
 // doRaw performs an HTTP request and returns the raw response body bytes.
 // It uses the same retry logic as do but does not JSON-decode the response.
-func (c *Client) doRaw(ctx context.Context, method, path string) ([]byte, error) {
+func (c *Client) doRaw(ctx context.Context, method, path string) (_ []byte, err error) {
+	if err := c.breaker.allow(method, path); err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.record(err) }()
 	url := c.baseURL + path
 
 	var lastErr error
@@ -180,6 +331,9 @@ func (c *Client) doRaw(ctx context.Context, method, path string) ([]byte, error)
 			case <-time.After(backoff):
 			}
 		}
+		if err := c.pacer.wait(ctx); err != nil {
+			return nil, err
+		}
 
 		req, err := http.NewRequestWithContext(ctx, method, url, nil)
 		if err != nil {
@@ -192,14 +346,24 @@ func (c *Client) doRaw(ctx context.Context, method, path string) ([]byte, error)
 			req.Header.Set("anthropic-beta", anthropicBeta)
 		}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
+		start := time.Now()
+		resp, respBody, err := c.send(req, c.uploadTimeout)
+		if resp == nil {
+			if c.debugHTTP {
+				c.logExchange(ctx, debugExchange{method: method, path: path, attempt: attempt, start: start, err: err})
+			}
 			lastErr = fmt.Errorf("anthropic: request failed: %w", err)
 			continue
 		}
 
-		respBody, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
+		if c.debugHTTP {
+			// A successful response is the version's files.
+			response := omittedBody("file content", int64(len(respBody)))
+			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+				response = redactJSON(respBody)
+			}
+			c.logExchange(ctx, debugExchange{method: method, path: path, attempt: attempt, start: start, resp: resp, response: response, err: err})
+		}
 		if err != nil {
 			lastErr = fmt.Errorf("anthropic: read response body: %w", err)
 			continue
@@ -212,6 +376,9 @@ func (c *Client) doRaw(ctx context.Context, method, path string) ([]byte, error)
 		apiErr := parseAPIError(resp.StatusCode, respBody)
 
 		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			if resp.StatusCode == 429 {
+				c.pacer.backOff(resp)
+			}
 			lastErr = apiErr
 			continue
 		}
@@ -219,16 +386,21 @@ func (c *Client) doRaw(ctx context.Context, method, path string) ([]byte, error)
 		return nil, apiErr
 	}
 
-	if lastErr != nil {
-		return nil, fmt.Errorf("anthropic: request failed after %d retries: %w", c.maxRetries, lastErr)
-	}
-	return nil, fmt.Errorf("anthropic: request failed after %d retries", c.maxRetries)
+	return nil, c.exhausted(lastErr)
 }
 
 // doMultipart performs an HTTP request with a pre-built body (for multipart
 // uploads) and retry logic. The buildBody function is called on each attempt
-// to produce a fresh body reader and the Content-Type header value.
-func (c *Client) doMultipart(ctx context.Context, method, path string, buildBody func() (io.Reader, string, error), result interface{}) error {
+// to produce a fresh body reader and the Content-Type header value. A
+// non-empty idempotencyKey is sent unchanged on every attempt.
+func (c *Client) doMultipart(ctx context.Context, method, path, idempotencyKey string, buildBody func() (io.Reader, string, error), result interface{}) (err error) {
+	if err := c.checkWritable(method, path); err != nil {
+		return err
+	}
+	if err := c.breaker.allow(method, path); err != nil {
+		return err
+	}
+	defer func() { c.breaker.record(err) }()
 	url := c.baseURL + path
 
 	var lastErr error
@@ -242,6 +414,9 @@ func (c *Client) doMultipart(ctx context.Context, method, path string, buildBody
 			case <-time.After(backoff):
 			}
 		}
+		if err := c.pacer.wait(ctx); err != nil {
+			return err
+		}
 
 		bodyReader, contentType, err := buildBody()
 		if err != nil {
@@ -259,15 +434,28 @@ func (c *Client) doMultipart(ctx context.Context, method, path string, buildBody
 			req.Header.Set("anthropic-beta", anthropicBeta)
 		}
 		req.Header.Set("Content-Type", contentType)
+		// NewRequest only sets the length of bodies of known types; keep it
+		// for bodies wrapped to track upload progress.
+		if l, ok := bodyReader.(interface{ Len() int }); ok && req.ContentLength == 0 {
+			req.ContentLength = int64(l.Len())
+		}
+		if idempotencyKey != "" {
+			req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+		}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
+		start := time.Now()
+		resp, respBody, err := c.send(req, c.uploadTimeout)
+		if resp == nil {
+			if c.debugHTTP {
+				c.logExchange(ctx, debugExchange{method: method, path: path, attempt: attempt, start: start, request: omittedBody("multipart upload", req.ContentLength), err: err})
+			}
 			lastErr = fmt.Errorf("anthropic: request failed: %w", err)
 			continue
 		}
 
-		respBody, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
+		if c.debugHTTP {
+			c.logExchange(ctx, debugExchange{method: method, path: path, attempt: attempt, start: start, resp: resp, request: omittedBody("multipart upload", req.ContentLength), response: redactJSON(respBody), err: err})
+		}
 		if err != nil {
 			lastErr = fmt.Errorf("anthropic: read response body: %w", err)
 			continue
@@ -285,6 +473,9 @@ func (c *Client) doMultipart(ctx context.Context, method, path string, buildBody
 		apiErr := parseAPIError(resp.StatusCode, respBody)
 
 		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			if resp.StatusCode == 429 {
+				c.pacer.backOff(resp)
+			}
 			lastErr = apiErr
 			continue
 		}
@@ -292,10 +483,33 @@ func (c *Client) doMultipart(ctx context.Context, method, path string, buildBody
 		return apiErr
 	}
 
+	return c.exhausted(lastErr)
+}
+
+// send sends req and reads the response body, both within timeout. A nil
+// response means the request could not be sent or no response arrived; a
+// response with a non-nil error means its body could not be read in full.
+func (c *Client) send(req *http.Request, timeout time.Duration) (*http.Response, []byte, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	return resp, body, err
+}
+
+// exhausted returns the error of a request whose every attempt failed with
+// a retryable error, the last of which was lastErr. It wraps ErrUnavailable.
+func (c *Client) exhausted(lastErr error) error {
 	if lastErr != nil {
-		return fmt.Errorf("anthropic: request failed after %d retries: %w", c.maxRetries, lastErr)
+		return &unavailableError{fmt.Errorf("anthropic: request failed after %d retries: %w", c.maxRetries, lastErr)}
 	}
-	return fmt.Errorf("anthropic: request failed after %d retries", c.maxRetries)
+	return &unavailableError{fmt.Errorf("anthropic: request failed after %d retries", c.maxRetries)}
 }
 
 // parseAPIError parses an error response body into an APIError.
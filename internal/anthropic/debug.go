@@ -0,0 +1,132 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// debugBodyLimit is how much of a redacted JSON payload is logged.
+const debugBodyLimit = 4096
+
+// redacted replaces the values of sensitive JSON fields in debug logs.
+const redacted = "[REDACTED]"
+
+// sensitiveFields are substrings of JSON field names, compared in lower
+// case, whose values are never logged: credentials, and file content.
+var sensitiveFields = []string{"key", "token", "secret", "password", "authorization", "credential", "content"}
+
+// debugExchange is one attempt of a registry request, as logged with
+// debug_http. Payload descriptions are already redacted.
+type debugExchange struct {
+	method   string
+	path     string
+	attempt  int
+	start    time.Time
+	resp     *http.Response // nil if the request failed before a response
+	request  string
+	response string
+	err      error
+}
+
+// logExchange logs ex at debug level. It is only called when the client is
+// configured with DebugHTTP. Headers are never logged except the response's
+// request-id, and the API key is masked should it appear anywhere else.
+func (c *Client) logExchange(ctx context.Context, ex debugExchange) {
+	if c.apiKey != "" {
+		ctx = tflog.MaskMessageStrings(ctx, c.apiKey)
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, c.apiKey)
+	}
+	fields := map[string]interface{}{
+		"method":      ex.method,
+		"path":        ex.path,
+		"attempt":     ex.attempt + 1,
+		"duration_ms": time.Since(ex.start).Milliseconds(),
+	}
+	if ex.request != "" {
+		fields["request_body"] = ex.request
+	}
+	if ex.resp != nil {
+		fields["status"] = ex.resp.StatusCode
+		if id := ex.resp.Header.Get("request-id"); id != "" {
+			fields["request_id"] = id
+		}
+	}
+	if ex.response != "" {
+		fields["response_body"] = ex.response
+	}
+	if ex.err != nil {
+		fields["error"] = ex.err.Error()
+	}
+	tflog.Debug(ctx, "anthropic http request", fields)
+}
+
+// redactJSON returns payload with the values of sensitive fields replaced,
+// truncated to debugBodyLimit. A payload that is not JSON is described by
+// its size only.
+func redactJSON(payload []byte) string {
+	if len(payload) == 0 {
+		return ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return fmt.Sprintf("<%d bytes, not JSON, not logged>", len(payload))
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes, not logged>", len(payload))
+	}
+	if len(out) > debugBodyLimit {
+		return string(out[:debugBodyLimit]) + "...(truncated)"
+	}
+	return string(out)
+}
+
+// redactValue returns v, a decoded JSON value, with the values of
+// sensitive object fields replaced at any depth.
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range v {
+			if sensitiveField(k) {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactValue(fv)
+		}
+		return v
+	case []interface{}:
+		for i, ev := range v {
+			v[i] = redactValue(ev)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// sensitiveField reports whether the value of the JSON field name must not
+// be logged.
+func sensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveFields {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// omittedBody describes a payload that is never logged, such as the files
+// of a version upload or download.
+func omittedBody(what string, size int64) string {
+	if size <= 0 {
+		return fmt.Sprintf("<%s, not logged>", what)
+	}
+	return fmt.Sprintf("<%s, %d bytes, not logged>", what, size)
+}
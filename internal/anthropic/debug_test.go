@@ -0,0 +1,114 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestRedactJSON(t *testing.T) {
+	got := redactJSON([]byte(`{"display_title":"Reports","api_key":"sk-1","files":[{"path":"SKILL.md","content":"# secret"}],"auth":{"Token":"t"}}`))
+	for _, leaked := range []string{"sk-1", "# secret", `"t"`} {
+		if strings.Contains(got, leaked) {
+			t.Errorf("redactJSON leaked %s: %s", leaked, got)
+		}
+	}
+	for _, kept := range []string{`"display_title":"Reports"`, `"path":"SKILL.md"`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("redactJSON dropped %s: %s", kept, got)
+		}
+	}
+
+	if got := redactJSON([]byte("PK\x03\x04 binary")); strings.Contains(got, "binary") {
+		t.Errorf("non-JSON payload logged: %s", got)
+	}
+	if got := redactJSON([]byte(`{"notes":"` + strings.Repeat("x", 2*debugBodyLimit) + `"}`)); !strings.HasSuffix(got, "...(truncated)") {
+		t.Errorf("long payload not truncated: %d bytes", len(got))
+	}
+}
+
+func TestDebugHTTP_LogsRedactedExchanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "req_123")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"skill_01","display_title":"Reports","created_at":"","updated_at":""}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &logs)
+
+	c := testClient(t, server)
+	c.debugHTTP = true
+
+	if _, err := c.UpdateSkill(ctx, "skill_01", UpdateSkillRequest{DisplayTitle: "Reports"}); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("FILE-CONTENT-MARKER"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateSkill(ctx, dir, "Reports", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exchanges []map[string]interface{}
+	for _, e := range entries {
+		if e["@message"] == "anthropic http request" {
+			exchanges = append(exchanges, e)
+		}
+	}
+	if len(exchanges) != 2 {
+		t.Fatalf("logged %d exchanges, want 2: %s", len(exchanges), logs.String())
+	}
+
+	put := exchanges[0]
+	if put["method"] != http.MethodPut || put["path"] != "/v1/skills/skill_01" || put["status"] != float64(200) || put["request_id"] != "req_123" {
+		t.Errorf("PUT logged as %v", put)
+	}
+	if put["request_body"] != `{"display_title":"Reports"}` {
+		t.Errorf("request_body = %v", put["request_body"])
+	}
+	if _, ok := put["duration_ms"]; !ok {
+		t.Error("duration_ms not logged")
+	}
+
+	upload := exchanges[1]
+	if body, _ := upload["request_body"].(string); !strings.Contains(body, "not logged") {
+		t.Errorf("multipart request_body = %q, want it omitted", body)
+	}
+
+	raw := logs.String()
+	for _, leaked := range []string{"test-api-key", "FILE-CONTENT-MARKER"} {
+		if strings.Contains(raw, leaked) {
+			t.Errorf("debug log contains %q", leaked)
+		}
+	}
+}
+
+func TestDebugHTTP_OffByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"skill_01"}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &logs)
+	if _, err := testClient(t, server).GetSkill(ctx, "skill_01"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(logs.String(), "anthropic http request") {
+		t.Errorf("request logged without debug_http: %s", logs.String())
+	}
+}
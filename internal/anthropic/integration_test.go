@@ -369,7 +369,7 @@ func createSkillWithRetry(t *testing.T, ctx context.Context, client *Client, sou
 
 	var lastErr error
 	for i := 1; i <= attempts; i++ {
-		skill, err := client.CreateSkill(ctx, sourceDir, displayTitle)
+		skill, err := client.CreateSkill(ctx, sourceDir, displayTitle, "")
 		if err == nil {
 			return skill
 		}
@@ -405,7 +405,7 @@ func createVersionWithRetry(t *testing.T, ctx context.Context, client *Client, s
 
 	var lastErr error
 	for i := 1; i <= attempts; i++ {
-		ver, err := client.CreateVersion(ctx, skillID, sourceDir)
+		ver, err := client.CreateVersion(ctx, skillID, sourceDir, CreateVersionRequest{})
 		if err == nil {
 			return ver
 		}
@@ -0,0 +1,103 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxRetryAfter caps how long a single 429 response can pause a pacer, so a
+// malformed or hostile Retry-After header cannot stall an apply.
+const maxRetryAfter = 5 * time.Minute
+
+// pacer spaces the requests of a Client evenly, so an apply that registers
+// many skills stays under the registry's rate limit instead of relying on
+// 429 retries. Every attempt, including retries, waits for its turn. A 429
+// response with a Retry-After header pauses every request of the client,
+// not only the one that was rejected, until the server's deadline has
+// passed. The provider shares one Client, and so one pacer, between every
+// resource and data source. A nil pacer never waits.
+type pacer struct {
+	limiter *rate.Limiter
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+	now         func() time.Time
+}
+
+// newPacer returns a pacer that lets requestsPerMinute requests through per
+// minute, one at a time, or nil when requestsPerMinute is zero or less.
+func newPacer(requestsPerMinute int) *pacer {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	return &pacer{
+		limiter: rate.NewLimiter(rate.Limit(float64(requestsPerMinute)/60), 1),
+		now:     time.Now,
+	}
+}
+
+// wait blocks until the next request may be sent or ctx is done.
+func (p *pacer) wait(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	pause := p.pausedUntil.Sub(p.now())
+	p.mu.Unlock()
+	if pause > 0 {
+		timer := time.NewTimer(pause)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return p.limiter.Wait(ctx)
+}
+
+// backOff pauses every request until the deadline in the Retry-After header
+// of resp, a 429 response, has passed. A missing or invalid header leaves
+// the pacer unchanged.
+func (p *pacer) backOff(resp *http.Response) {
+	if p == nil {
+		return
+	}
+	d, ok := retryAfter(resp.Header.Get("Retry-After"), p.now())
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until := p.now().Add(d); until.After(p.pausedUntil) {
+		p.pausedUntil = until
+	}
+}
+
+// retryAfter parses a Retry-After header value, either delay seconds or an
+// HTTP date, into a delay from now capped at maxRetryAfter.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	} else {
+		return 0, false
+	}
+	if d <= 0 {
+		return 0, false
+	}
+	return min(d, maxRetryAfter), true
+}
@@ -0,0 +1,96 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for value, want := range map[string]time.Duration{
+		"":      0,
+		"0":     0,
+		"-1":    0,
+		"soon":  0,
+		"2":     2 * time.Second,
+		"86400": maxRetryAfter,
+		now.Add(3 * time.Second).Format(http.TimeFormat): 3 * time.Second,
+		now.Add(-time.Minute).Format(http.TimeFormat):    0,
+	} {
+		got, ok := retryAfter(value, now)
+		if got != want || ok != (want > 0) {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v", value, got, ok, want)
+		}
+	}
+}
+
+func TestPacer_NilNeverWaits(t *testing.T) {
+	if newPacer(0) != nil {
+		t.Fatal("newPacer(0) should disable pacing")
+	}
+	var p *pacer
+	p.backOff(&http.Response{Header: http.Header{"Retry-After": {"60"}}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.wait(ctx); err != nil {
+		t.Errorf("nil pacer wait: %v", err)
+	}
+}
+
+func TestClient_PacesRequests(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"skill_01","display_title":"Paced"}`))
+	}))
+	defer srv.Close()
+
+	// 1200 requests per minute is one every 50ms.
+	c := NewClient(ClientConfig{APIKey: "test-key", BaseURL: srv.URL, MaxRetries: 0, RequestsPerMinute: 1200})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := c.GetSkill(context.Background(), "skill_01"); err != nil {
+			t.Fatalf("GetSkill: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("4 requests at 1200/min took %v, want at least 140ms", elapsed)
+	}
+	if n := requests.Load(); n != 4 {
+		t.Errorf("server saw %d requests, want 4", n)
+	}
+}
+
+func TestPacer_RetryAfterPausesEveryRequest(t *testing.T) {
+	p := newPacer(60000)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	p.backOff(&http.Response{Header: http.Header{"Retry-After": {"30"}}})
+
+	// Another request of the same client waits for the deadline, not only
+	// for the limiter.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("wait during a Retry-After pause = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Once the deadline has passed, requests flow again.
+	now = now.Add(31 * time.Second)
+	if err := p.wait(context.Background()); err != nil {
+		t.Errorf("wait after the pause: %v", err)
+	}
+
+	// A shorter Retry-After does not shorten a longer pause.
+	p.backOff(&http.Response{Header: http.Header{"Retry-After": {"30"}}})
+	p.backOff(&http.Response{Header: http.Header{"Retry-After": {"1"}}})
+	if want := now.Add(30 * time.Second); !p.pausedUntil.Equal(want) {
+		t.Errorf("pausedUntil = %v, want %v", p.pausedUntil, want)
+	}
+}
@@ -7,14 +7,18 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 // CreateSkill creates a new skill by uploading source files.
 // The sourceDir is walked and each file is uploaded as a files[] multipart field.
-// An optional displayTitle can be provided as a form field.
-func (c *Client) CreateSkill(ctx context.Context, sourceDir string, displayTitle string) (*Skill, error) {
+// An optional displayTitle can be provided as a form field. idempotencyKey,
+// usually built with IdempotencyKey, is sent on every attempt so retries
+// cannot register the skill twice; pass "" to omit it.
+func (c *Client) CreateSkill(ctx context.Context, sourceDir, displayTitle, idempotencyKey string) (*Skill, error) {
 	buildBody := func() (io.Reader, string, error) {
 		var buf bytes.Buffer
 		writer := multipart.NewWriter(&buf)
@@ -76,7 +80,7 @@ func (c *Client) CreateSkill(ctx context.Context, sourceDir string, displayTitle
 	}
 
 	var skill Skill
-	if err := c.doMultipart(ctx, http.MethodPost, "/v1/skills", buildBody, &skill); err != nil {
+	if err := c.doMultipart(ctx, http.MethodPost, "/v1/skills", idempotencyKey, buildBody, &skill); err != nil {
 		return nil, fmt.Errorf("create skill: %w", err)
 	}
 	return &skill, nil
@@ -91,6 +95,35 @@ func (c *Client) GetSkill(ctx context.Context, skillID string) (*Skill, error) {
 	return &skill, nil
 }
 
+// ListSkills returns every custom skill in the workspace, following the
+// pagination of the list endpoint. Anthropic's pre-built skills are not
+// included.
+func (c *Client) ListSkills(ctx context.Context) ([]Skill, error) {
+	var skills []Skill
+	query := c.pageQuery()
+	query.Set("source", "custom")
+	for {
+		var resp ListSkillsResponse
+		if err := c.do(ctx, http.MethodGet, "/v1/skills?"+query.Encode(), nil, &resp); err != nil {
+			return nil, fmt.Errorf("list skills: %w", err)
+		}
+		skills = append(skills, resp.Data...)
+		if !resp.HasMore || resp.NextPage == "" {
+			return skills, nil
+		}
+		query.Set("page", resp.NextPage)
+	}
+}
+
+// pageQuery returns the query of the first page of a list request.
+func (c *Client) pageQuery() url.Values {
+	query := url.Values{}
+	if c.pageSize > 0 {
+		query.Set("limit", strconv.Itoa(c.pageSize))
+	}
+	return query
+}
+
 // UpdateSkill updates an existing skill's metadata.
 func (c *Client) UpdateSkill(ctx context.Context, skillID string, req UpdateSkillRequest) (*Skill, error) {
 	var skill Skill
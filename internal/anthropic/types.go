@@ -21,6 +21,10 @@ type SkillVersion struct {
 	Directory   string `json:"directory,omitempty"`
 	Type        string `json:"type,omitempty"`
 	CreatedAt   string `json:"created_at"`
+	// Notes and Labels echo the metadata sent with CreateVersion. They are
+	// empty when the registry does not support version metadata.
+	Notes  string            `json:"notes,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // CreateSkillRequest is the request body for creating a new skill.
@@ -28,6 +32,19 @@ type CreateSkillRequest struct {
 	DisplayTitle string `json:"display_title"`
 }
 
+// CreateVersionRequest holds the optional parameters of CreateVersion.
+type CreateVersionRequest struct {
+	// IdempotencyKey, usually IdempotencyKey(skillID, bundleHash), is sent
+	// on every attempt so retries cannot create duplicate versions. Empty
+	// omits the header.
+	IdempotencyKey string
+	// Notes describes what changed in the version. Sent as the "notes" form
+	// field when non-empty.
+	Notes string
+	// Labels are sent as "labels[<key>]" form fields.
+	Labels map[string]string
+}
+
 // UpdateSkillRequest is the request body for updating an existing skill.
 type UpdateSkillRequest struct {
 	DisplayTitle string `json:"display_title"`
@@ -7,21 +7,51 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"iter"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/progress"
 )
 
 // CreateVersion uploads the bundle files from sourceDir as a multipart form
-// and creates a new version for the given skill.
-func (c *Client) CreateVersion(ctx context.Context, skillID string, sourceDir string) (*SkillVersion, error) {
+// and creates a new version for the given skill. req carries the idempotency
+// key and optional version notes and labels.
+func (c *Client) CreateVersion(ctx context.Context, skillID, sourceDir string, req CreateVersionRequest) (*SkillVersion, error) {
 	path := fmt.Sprintf("/v1/skills/%s/versions", skillID)
 
+	// Every attempt re-sends the whole body, so each one is tracked from
+	// zero.
+	var (
+		tracker *progress.Tracker
+		files   int
+	)
+	defer func() { tracker.Finish() }()
+
 	buildBody := func() (io.Reader, string, error) {
 		var buf bytes.Buffer
+		files = 0
 		writer := multipart.NewWriter(&buf)
 
+		if req.Notes != "" {
+			if err := writer.WriteField("notes", req.Notes); err != nil {
+				return nil, "", fmt.Errorf("write notes field: %w", err)
+			}
+		}
+		labelKeys := make([]string, 0, len(req.Labels))
+		for k := range req.Labels {
+			labelKeys = append(labelKeys, k)
+		}
+		sort.Strings(labelKeys)
+		for _, k := range labelKeys {
+			if err := writer.WriteField("labels["+k+"]", req.Labels[k]); err != nil {
+				return nil, "", fmt.Errorf("write label %q: %w", k, err)
+			}
+		}
+
 		// Walk the source directory and add each file as a form file part.
 		absRoot, err := filepath.Abs(sourceDir)
 		if err != nil {
@@ -59,6 +89,7 @@ func (c *Client) CreateVersion(ctx context.Context, skillID string, sourceDir st
 			if _, err := io.Copy(part, f); err != nil {
 				return fmt.Errorf("copy file %q: %w", rel, err)
 			}
+			files++
 
 			return nil
 		})
@@ -70,13 +101,18 @@ func (c *Client) CreateVersion(ctx context.Context, skillID string, sourceDir st
 			return nil, "", fmt.Errorf("close multipart writer: %w", err)
 		}
 
-		return &buf, writer.FormDataContentType(), nil
+		tracker.Finish()
+		tracker = c.progress.Start(ctx, "anthropic_version", map[string]string{
+			"skill_id": skillID,
+		}, files, int64(buf.Len()))
+		return tracker.Reader(&buf), writer.FormDataContentType(), nil
 	}
 
 	var version SkillVersion
-	if err := c.doMultipart(ctx, http.MethodPost, path, buildBody, &version); err != nil {
+	if err := c.doMultipart(ctx, http.MethodPost, path, req.IdempotencyKey, buildBody, &version); err != nil {
 		return nil, fmt.Errorf("create version for skill %q: %w", skillID, err)
 	}
+	tracker.AddFiles(files)
 	return &version, nil
 }
 
@@ -90,14 +126,49 @@ func (c *Client) GetVersion(ctx context.Context, skillID string, version string)
 	return &sv, nil
 }
 
-// ListVersions returns all versions for the given skill.
+// ListVersions returns all versions for the given skill, oldest first,
+// following the pagination of the list endpoint.
 func (c *Client) ListVersions(ctx context.Context, skillID string) ([]SkillVersion, error) {
-	path := fmt.Sprintf("/v1/skills/%s/versions", skillID)
-	var resp ListVersionsResponse
-	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
-		return nil, fmt.Errorf("list versions for skill %q: %w", skillID, err)
+	var versions []SkillVersion
+	for v, err := range c.Versions(ctx, skillID) {
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// Versions returns an iterator over the versions of the given skill, oldest
+// first. Pages are requested as the iteration reaches them, so a caller
+// that stops early does not read the rest, and a skill with many versions
+// is never held in memory at once. A failed request ends the iteration
+// with a zero SkillVersion and the error.
+func (c *Client) Versions(ctx context.Context, skillID string) iter.Seq2[SkillVersion, error] {
+	return func(yield func(SkillVersion, error) bool) {
+		path := fmt.Sprintf("/v1/skills/%s/versions", skillID)
+		query := c.pageQuery()
+		for {
+			var resp ListVersionsResponse
+			endpoint := path
+			if len(query) > 0 {
+				endpoint += "?" + query.Encode()
+			}
+			if err := c.do(ctx, http.MethodGet, endpoint, nil, &resp); err != nil {
+				yield(SkillVersion{}, fmt.Errorf("list versions for skill %q: %w", skillID, err))
+				return
+			}
+			for _, v := range resp.Data {
+				if !yield(v, nil) {
+					return
+				}
+			}
+			if !resp.HasMore || resp.NextPage == "" {
+				return
+			}
+			query.Set("page", resp.NextPage)
+		}
 	}
-	return resp.Data, nil
 }
 
 // DeleteVersion deletes a specific version of a skill.
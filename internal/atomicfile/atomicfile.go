@@ -0,0 +1,78 @@
+// Package atomicfile writes files so that readers observe either the old
+// content or the new content, never a partially written file.
+//
+// Content is written to a temporary file in the destination directory,
+// flushed to disk, and renamed over the destination. A crash or a failed
+// write leaves the previous file intact; at worst a stray temporary file
+// named ".<name>.tmp-*" remains next to it.
+package atomicfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// WriteFile atomically replaces name with data. Unlike os.WriteFile, perm
+// is applied whether or not name already exists, and is not subject to the
+// umask.
+func WriteFile(name string, data []byte, perm os.FileMode) error {
+	return WriteReader(name, bytes.NewReader(data), perm)
+}
+
+// WriteReader atomically replaces name with the content read from r. See
+// WriteFile.
+func WriteReader(name string, r io.Reader, perm os.FileMode) (err error) {
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("chmod %s: %w", name, err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", name, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", name, err)
+	}
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		return err
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry of a rename to disk, so the new file
+// survives a power loss. It is best-effort: some platforms and file systems,
+// Windows among them, cannot sync directories.
+func syncDir(dir string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	d.Close()
+}
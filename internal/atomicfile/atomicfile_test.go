@@ -0,0 +1,83 @@
+package atomicfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "plugin.json")
+
+	if err := WriteFile(name, []byte(`{"name":"a"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(name, []byte(`{"name":"b"}`), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"name":"b"}` {
+		t.Errorf("content = %s", data)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o755 {
+			t.Errorf("mode = %04o, want 0755", info.Mode().Perm())
+		}
+	}
+	assertNoTempFiles(t, dir)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("source went away")
+}
+
+func TestWriteReader_FailureKeepsOldContent(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "hooks.json")
+	if err := WriteFile(name, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WriteReader(name, failingReader{}, 0o644)
+	if err == nil || !strings.Contains(err.Error(), "source went away") {
+		t.Fatalf("err = %v, want the read error", err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil || string(data) != "old" {
+		t.Errorf("content = %q, %v; want the old content", data, err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteFile_MissingDirectory(t *testing.T) {
+	if err := WriteFile(filepath.Join(t.TempDir(), "missing", "f"), nil, 0o644); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temporary file %s left behind", e.Name())
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// MaxArchiveSize is the most bytes ExtractArchive writes for one archive.
+// It bounds the disk use of a malformed or malicious archive.
+const MaxArchiveSize = 1 << 30
+
+// archiveExtensions are the archive formats ExtractArchive supports, longest
+// suffix first.
+var archiveExtensions = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+// ArchiveName returns the base name of archivePath without its archive
+// extension, e.g. "my-skill" for "dist/my-skill.tar.gz". It returns an
+// error when the extension is not a supported archive format.
+func ArchiveName(archivePath string) (string, error) {
+	base := filepath.Base(archivePath)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(strings.ToLower(base), ext) {
+			if name := base[:len(base)-len(ext)]; name != "" {
+				return name, nil
+			}
+			break
+		}
+	}
+	return "", fmt.Errorf("bundle: archive %q must be a .zip, .tar, .tar.gz or .tgz file", archivePath)
+}
+
+// ExtractArchive extracts the zip or tar archive at archivePath into
+// destDir, which must exist. Only regular files and directories are
+// extracted: entries with an absolute path or one that leaves destDir,
+// symlinks and other special files are rejected.
+//
+// When every entry sits under one top-level directory, that directory is
+// stripped, so "my-skill/SKILL.md" is extracted as destDir/SKILL.md.
+func ExtractArchive(archivePath, destDir string) error {
+	if _, err := ArchiveName(archivePath); err != nil {
+		return err
+	}
+
+	var err error
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		err = extractZip(archivePath, destDir)
+	} else {
+		err = extractTar(archivePath, destDir)
+	}
+	if err != nil {
+		return fmt.Errorf("bundle: extract %q: %w", archivePath, err)
+	}
+	return stripArchiveRoot(destDir)
+}
+
+// archiveWriter writes archive entries below a destination directory,
+// enforcing MaxArchiveSize across all of them.
+type archiveWriter struct {
+	destDir string
+	written int64
+}
+
+// target returns the path name is extracted to, or an error when name is
+// absolute or leaves the destination directory.
+func (w *archiveWriter) target(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	clean := path.Clean(name)
+	if path.IsAbs(name) || filepath.IsAbs(name) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("entry %q is outside the archive root", name)
+	}
+	return filepath.Join(w.destDir, filepath.FromSlash(clean)), nil
+}
+
+func (w *archiveWriter) dir(name string) error {
+	dst, err := w.target(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(dst, 0o755)
+}
+
+func (w *archiveWriter) file(name string, r io.Reader) error {
+	dst, err := w.target(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, MaxArchiveSize-w.written+1))
+	w.written += n
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if w.written > MaxArchiveSize {
+		return fmt.Errorf("archive expands to more than %d bytes", int64(MaxArchiveSize))
+	}
+	return nil
+}
+
+func extractZip(archivePath, destDir string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	w := &archiveWriter{destDir: destDir}
+	for _, zf := range zr.File {
+		mode := zf.Mode()
+		switch {
+		case mode.IsDir():
+			err = w.dir(zf.Name)
+		case mode.IsRegular():
+			var rc io.ReadCloser
+			if rc, err = zf.Open(); err == nil {
+				err = w.file(zf.Name, rc)
+				rc.Close()
+			}
+		default:
+			err = fmt.Errorf("entry %q is not a regular file or directory", zf.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(archivePath, destDir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if lower := strings.ToLower(archivePath); strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	w := &archiveWriter{destDir: destDir}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = w.dir(hdr.Name)
+		case tar.TypeReg:
+			err = w.file(hdr.Name, tr)
+		case tar.TypeXGlobalHeader:
+			// PAX metadata, not a file.
+		default:
+			err = fmt.Errorf("entry %q is not a regular file or directory", hdr.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// stripArchiveRoot moves the contents of destDir's only entry up into
+// destDir when that entry is a directory.
+func stripArchiveRoot(destDir string) error {
+	entries, err := os.ReadDir(destDir)
+	if err != nil {
+		return fmt.Errorf("bundle: read extracted archive: %w", err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return nil
+	}
+
+	root := filepath.Join(destDir, entries[0].Name())
+	children, err := os.ReadDir(root)
+	if err != nil {
+		return fmt.Errorf("bundle: read extracted archive: %w", err)
+	}
+	// Move the root aside first, in case it contains an entry of its own
+	// name.
+	tmp := root + ".agentctx-root"
+	if err := os.Rename(root, tmp); err != nil {
+		return fmt.Errorf("bundle: strip archive root: %w", err)
+	}
+	for _, c := range children {
+		if err := os.Rename(filepath.Join(tmp, c.Name()), filepath.Join(destDir, c.Name())); err != nil {
+			return fmt.Errorf("bundle: strip archive root: %w", err)
+		}
+	}
+	return os.Remove(tmp)
+}
@@ -7,15 +7,19 @@ import (
 
 // Bundle is the result of scanning a source directory. It contains the
 // enumerated files, their individual hashes, and the overall bundle hash.
+// Skill is the frontmatter of the bundle's SKILL.md, or nil if the bundle
+// has none.
 type Bundle struct {
 	SourceDir  string
 	Files      []FileEntry
 	FileHashes map[string]string // relpath -> "sha256:<hex>"
 	BundleHash string            // "sha256:<hex>"
+	Skill      *SkillMetadata
 }
 
 // ScanBundle enumerates files in sourceDir, validates symlinks, computes
-// hashes, and returns a fully populated Bundle.
+// hashes, parses the SKILL.md frontmatter, and returns a fully populated
+// Bundle.
 func ScanBundle(sourceDir string, userExcludes []string, allowExternalSymlinks bool) (*Bundle, error) {
 	// 1. Enumerate files.
 	files, err := EnumerateFiles(sourceDir, userExcludes)
@@ -34,11 +38,18 @@ func ScanBundle(sourceDir string, userExcludes []string, allowExternalSymlinks b
 		return nil, fmt.Errorf("bundle: hash: %w", err)
 	}
 
+	// 4. Parse SKILL.md frontmatter.
+	skill, err := scanSkillFile(sourceDir, files)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: %s: %w", SkillFile, err)
+	}
+
 	return &Bundle{
 		SourceDir:  sourceDir,
 		Files:      files,
 		FileHashes: fileHashes,
 		BundleHash: bundleHash,
+		Skill:      skill,
 	}, nil
 }
 
@@ -66,10 +77,15 @@ func BundleFromFiles(files map[string][]byte) *Bundle {
 
 	bundleHash := ComputeBundleHash(fileHashes)
 
+	// Frontmatter errors are surfaced by ScanBundle; an in-memory bundle
+	// simply has no metadata.
+	skill, _ := ParseSkillFrontmatter(files[SkillFile])
+
 	return &Bundle{
 		SourceDir:  "",
 		Files:      entries,
 		FileHashes: fileHashes,
 		BundleHash: bundleHash,
+		Skill:      skill,
 	}
 }
@@ -1,11 +1,16 @@
 package bundle
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -202,6 +207,17 @@ func TestComputeFileHashBytes(t *testing.T) {
 	}
 }
 
+func TestComputeReaderHash(t *testing.T) {
+	data := []byte("deterministic test input")
+	hash, err := ComputeReaderHash(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("ComputeReaderHash: %v", err)
+	}
+	if want := ComputeFileHashBytes(data); hash != want {
+		t.Errorf("ComputeReaderHash = %q, want %q", hash, want)
+	}
+}
+
 func TestComputeBundleHash(t *testing.T) {
 	files := map[string]string{
 		"a.txt": "sha256:abc123",
@@ -781,3 +797,407 @@ func TestBundleFromFiles(t *testing.T) {
 		t.Errorf("BundleFromFiles not deterministic: %q != %q", b.BundleHash, b2.BundleHash)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Descriptor tests
+// ---------------------------------------------------------------------------
+
+func TestDescriptorRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	for rel, content := range map[string]string{
+		"SKILL.md":      "# Skill",
+		"lib/helper.py": "pass",
+		"notes.log":     "excluded",
+	} {
+		absPath := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(absPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := ScanBundle(dir, []string{"*.log"}, false)
+	if err != nil {
+		t.Fatalf("ScanBundle: %v", err)
+	}
+
+	data, err := b.MarshalDescriptor()
+	if err != nil {
+		t.Fatalf("MarshalDescriptor: %v", err)
+	}
+
+	d, err := ParseDescriptor(data)
+	if err != nil {
+		t.Fatalf("ParseDescriptor: %v", err)
+	}
+
+	if d.BundleHash != b.BundleHash {
+		t.Errorf("BundleHash = %q, want %q", d.BundleHash, b.BundleHash)
+	}
+	if !filepath.IsAbs(d.SourceDir) {
+		t.Errorf("SourceDir %q is not absolute", d.SourceDir)
+	}
+	if len(d.Files) != 2 {
+		t.Errorf("got %d files, want 2: %v", len(d.Files), d.Files)
+	}
+	if _, ok := d.Files["notes.log"]; ok {
+		t.Error("excluded file notes.log present in descriptor")
+	}
+	if d.Files["lib/helper.py"] != b.FileHashes["lib/helper.py"] {
+		t.Errorf("hash for lib/helper.py = %q, want %q", d.Files["lib/helper.py"], b.FileHashes["lib/helper.py"])
+	}
+
+	// Marshalling is deterministic.
+	again, err := b.MarshalDescriptor()
+	if err != nil {
+		t.Fatalf("MarshalDescriptor: %v", err)
+	}
+	if again != data {
+		t.Errorf("MarshalDescriptor not deterministic:\n%s\n%s", data, again)
+	}
+}
+
+func TestParseDescriptor_Invalid(t *testing.T) {
+	hash := ComputeFileHashBytes([]byte("x"))
+	valid := map[string]string{"SKILL.md": hash}
+
+	tests := []struct {
+		name string
+		desc Descriptor
+	}{
+		{"missing source dir", Descriptor{BundleHash: ComputeBundleHash(valid), Files: valid}},
+		{"relative source dir", Descriptor{SourceDir: "skills/x", BundleHash: ComputeBundleHash(valid), Files: valid}},
+		{"no files", Descriptor{SourceDir: "/skills/x", BundleHash: ComputeBundleHash(nil)}},
+		{"traversal", Descriptor{SourceDir: "/skills/x", BundleHash: ComputeBundleHash(map[string]string{"../x": hash}), Files: map[string]string{"../x": hash}}},
+		{"absolute path", Descriptor{SourceDir: "/skills/x", BundleHash: ComputeBundleHash(map[string]string{"/etc/x": hash}), Files: map[string]string{"/etc/x": hash}}},
+		{"malformed hash", Descriptor{SourceDir: "/skills/x", BundleHash: ComputeBundleHash(map[string]string{"a": "md5:x"}), Files: map[string]string{"a": "md5:x"}}},
+		{"hash mismatch", Descriptor{SourceDir: "/skills/x", BundleHash: "sha256:deadbeef", Files: valid}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.desc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ParseDescriptor(string(data)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	if _, err := ParseDescriptor("not json"); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
+
+func FuzzParseDescriptor(f *testing.F) {
+	files := map[string]string{"SKILL.md": ComputeFileHashBytes([]byte("# skill"))}
+	valid, _ := json.Marshal(Descriptor{SourceDir: "/skills/demo", BundleHash: ComputeBundleHash(files), Files: files})
+	f.Add(string(valid))
+	f.Add(`{"source_dir":"/x","bundle_hash":"","files":{"../a":"sha256:00"}}`)
+	f.Add(`{"source_dir":"relative","files":{}}`)
+	f.Add(`null`)
+
+	f.Fuzz(func(t *testing.T, data string) {
+		d, err := ParseDescriptor(data)
+		if err != nil {
+			return
+		}
+		for rel := range d.Files {
+			if filepath.IsAbs(rel) || strings.HasPrefix(rel, "/") {
+				t.Fatalf("accepted absolute file path %q", rel)
+			}
+			for _, part := range strings.Split(rel, "/") {
+				if part == ".." {
+					t.Fatalf("accepted escaping file path %q", rel)
+				}
+			}
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// SKILL.md frontmatter tests
+// ---------------------------------------------------------------------------
+
+func TestParseSkillFrontmatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *SkillMetadata
+		wantErr bool
+	}{
+		{
+			name: "name and description",
+			data: "---\nname: demo\ndescription: Does things.\n---\n# Demo\n",
+			want: &SkillMetadata{
+				Name:        "demo",
+				Description: "Does things.",
+				Fields:      map[string]string{"name": "demo", "description": "Does things."},
+			},
+		},
+		{
+			name: "extra fields",
+			data: "---\nname: demo\nversion: 2\nbeta: true\ntags: [a, b]\nowner:\n  team: data\n---\n",
+			want: &SkillMetadata{
+				Name: "demo",
+				Fields: map[string]string{
+					"name":    "demo",
+					"version": "2",
+					"beta":    "true",
+					"tags":    `["a","b"]`,
+					"owner":   `{"team":"data"}`,
+				},
+			},
+		},
+		{
+			name: "crlf line endings",
+			data: "---\r\nname: demo\r\ndescription: x\r\n---\r\nbody\r\n",
+			want: &SkillMetadata{
+				Name:        "demo",
+				Description: "x",
+				Fields:      map[string]string{"name": "demo", "description": "x"},
+			},
+		},
+		{
+			name: "closing delimiter at end of file",
+			data: "---\nname: demo\n---",
+			want: &SkillMetadata{Name: "demo", Fields: map[string]string{"name": "demo"}},
+		},
+		{
+			name: "empty frontmatter",
+			data: "---\n---\n# Demo\n",
+			want: &SkillMetadata{Fields: map[string]string{}},
+		},
+		{
+			name: "no frontmatter",
+			data: "# Demo\n\n---\nname: not frontmatter\n---\n",
+		},
+		{
+			name:    "unclosed",
+			data:    "---\nname: demo\n# Demo\n",
+			wantErr: true,
+		},
+		{
+			name:    "not a mapping",
+			data:    "---\n- a\n- b\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			data:    "---\nname: [demo\n---\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSkillFrontmatter([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSkillFrontmatter: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanBundle_SkillFrontmatter(t *testing.T) {
+	dir := t.TempDir()
+	skill := "---\nname: demo\ndescription: Summarises reports.\n---\n# Demo\n"
+	if err := os.WriteFile(filepath.Join(dir, SkillFile), []byte(skill), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ScanBundle(dir, nil, false)
+	if err != nil {
+		t.Fatalf("ScanBundle: %v", err)
+	}
+	if b.Skill == nil || b.Skill.Name != "demo" || b.Skill.Description != "Summarises reports." {
+		t.Errorf("Skill = %+v", b.Skill)
+	}
+
+	// A SKILL.md below the root is not the bundle's skill file.
+	nested := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(nested, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nested, "docs", SkillFile), []byte(skill), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err = ScanBundle(nested, nil, false)
+	if err != nil {
+		t.Fatalf("ScanBundle: %v", err)
+	}
+	if b.Skill != nil {
+		t.Errorf("Skill = %+v, want nil", b.Skill)
+	}
+
+	// Malformed frontmatter fails the scan.
+	bad := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bad, SkillFile), []byte("---\nname: demo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScanBundle(bad, nil, false); err == nil || !strings.Contains(err.Error(), SkillFile) {
+		t.Errorf("expected SKILL.md error, got %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Archive tests
+// ---------------------------------------------------------------------------
+
+func writeTestTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	cases := map[string]string{
+		"dist/my-skill.zip":    "my-skill",
+		"dist/my-skill.tar":    "my-skill",
+		"dist/my-skill.tar.gz": "my-skill",
+		"dist/my-skill.TGZ":    "my-skill",
+	}
+	for in, want := range cases {
+		got, err := ArchiveName(in)
+		if err != nil || got != want {
+			t.Errorf("ArchiveName(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"my-skill.rar", "my-skill", ".zip"} {
+		if _, err := ArchiveName(in); err == nil {
+			t.Errorf("ArchiveName(%q): expected an error", in)
+		}
+	}
+}
+
+func TestExtractArchive_MatchesDirectory(t *testing.T) {
+	files := map[string]string{
+		"SKILL.md":        "---\nname: my-skill\n---\n",
+		"scripts/run.sh":  "echo hi\n",
+		"docs/guide.md":   "# Guide\n",
+		"docs/ref/api.md": "# API\n",
+	}
+
+	src := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ScanBundle(src, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both a flat archive and one with a single top-level directory
+	// extract to the same bundle.
+	nested := make(map[string]string, len(files))
+	for name, content := range files {
+		nested["my-skill/"+name] = content
+	}
+	archives := t.TempDir()
+	zipPath := filepath.Join(archives, "my-skill.zip")
+	tgzPath := filepath.Join(archives, "my-skill.tar.gz")
+	writeTestZip(t, zipPath, files)
+	writeTestTarGz(t, tgzPath, nested)
+
+	for _, archive := range []string{zipPath, tgzPath} {
+		dest := t.TempDir()
+		if err := ExtractArchive(archive, dest); err != nil {
+			t.Fatalf("ExtractArchive(%q): %v", archive, err)
+		}
+		got, err := ScanBundle(dest, nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.BundleHash != want.BundleHash {
+			t.Errorf("%s: bundle hash = %s, want %s", filepath.Base(archive), got.BundleHash, want.BundleHash)
+		}
+	}
+}
+
+func TestExtractArchive_RejectsUnsafeEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	escape := filepath.Join(dir, "escape.zip")
+	writeTestZip(t, escape, map[string]string{"../evil.sh": "rm -rf /"})
+	if err := ExtractArchive(escape, t.TempDir()); err == nil {
+		t.Error("expected an error for an entry outside the archive root")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.sh")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("entry outside the archive root was written")
+	}
+
+	link := filepath.Join(dir, "link.tar")
+	f, err := os.Create(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(f)
+	if err := tw.WriteHeader(&tar.Header{Name: "secrets", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	f.Close()
+	if err := ExtractArchive(link, t.TempDir()); err == nil {
+		t.Error("expected an error for a symlink entry")
+	}
+}
@@ -0,0 +1,94 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Descriptor is the portable description of a scanned Bundle. It records the
+// exact set of files that survived exclusion and symlink validation, together
+// with their hashes, so consumers (e.g. the agentctx_plugin skill block) can
+// reproduce the bundle without re-applying exclusion rules of their own.
+type Descriptor struct {
+	SourceDir  string            `json:"source_dir"`
+	BundleHash string            `json:"bundle_hash"`
+	Files      map[string]string `json:"files"` // relpath -> "sha256:<hex>"
+}
+
+// Descriptor returns the Descriptor for b. The source directory is resolved
+// to an absolute path so the descriptor remains usable regardless of the
+// consumer's working directory.
+func (b *Bundle) Descriptor() (*Descriptor, error) {
+	absDir, err := filepath.Abs(b.SourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: resolve source dir: %w", err)
+	}
+
+	files := make(map[string]string, len(b.FileHashes))
+	for rel, hash := range b.FileHashes {
+		files[rel] = hash
+	}
+
+	return &Descriptor{
+		SourceDir:  absDir,
+		BundleHash: b.BundleHash,
+		Files:      files,
+	}, nil
+}
+
+// MarshalDescriptor returns the compact JSON encoding of b's Descriptor.
+// encoding/json sorts map keys, so the output is deterministic.
+func (b *Bundle) MarshalDescriptor() (string, error) {
+	d, err := b.Descriptor()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "", fmt.Errorf("bundle: marshal descriptor: %w", err)
+	}
+	return string(data), nil
+}
+
+// ParseDescriptor decodes and validates a descriptor produced by
+// MarshalDescriptor. It rejects descriptors whose file paths escape the
+// source directory or whose bundle hash does not match the listed files.
+func ParseDescriptor(data string) (*Descriptor, error) {
+	var d Descriptor
+	if err := json.Unmarshal([]byte(data), &d); err != nil {
+		return nil, fmt.Errorf("bundle: parse descriptor: %w", err)
+	}
+
+	if d.SourceDir == "" {
+		return nil, fmt.Errorf("bundle: descriptor is missing source_dir")
+	}
+	if !filepath.IsAbs(d.SourceDir) {
+		return nil, fmt.Errorf("bundle: descriptor source_dir %q is not absolute", d.SourceDir)
+	}
+	if len(d.Files) == 0 {
+		return nil, fmt.Errorf("bundle: descriptor lists no files")
+	}
+
+	for rel, hash := range d.Files {
+		if rel == "" || filepath.IsAbs(rel) || strings.HasPrefix(rel, "/") {
+			return nil, fmt.Errorf("bundle: descriptor file path %q must be relative", rel)
+		}
+		for _, part := range strings.Split(rel, "/") {
+			if part == ".." {
+				return nil, fmt.Errorf("bundle: descriptor file path %q must not contain '..'", rel)
+			}
+		}
+		if !strings.HasPrefix(hash, hashPrefix) {
+			return nil, fmt.Errorf("bundle: descriptor file %q has malformed hash %q", rel, hash)
+		}
+	}
+
+	if got := ComputeBundleHash(d.Files); got != d.BundleHash {
+		return nil, fmt.Errorf("bundle: descriptor bundle_hash %q does not match its files (computed %q)", d.BundleHash, got)
+	}
+
+	return &d, nil
+}
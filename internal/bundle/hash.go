@@ -21,8 +21,14 @@ func ComputeFileHash(path string) (string, error) {
 	}
 	defer f.Close()
 
+	return ComputeReaderHash(f)
+}
+
+// ComputeReaderHash reads r to the end and returns the SHA-256 hash of what
+// it read in the canonical format "sha256:<hex>".
+func ComputeReaderHash(r io.Reader) (string, error) {
 	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
+	if _, err := io.Copy(h, r); err != nil {
 		return "", fmt.Errorf("bundle: read for hash: %w", err)
 	}
 
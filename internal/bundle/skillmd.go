@@ -0,0 +1,107 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SkillFile is the file at the root of a skill bundle whose YAML
+// frontmatter names and describes the skill.
+const SkillFile = "SKILL.md"
+
+// SkillMetadata is the frontmatter of a bundle's SKILL.md.
+type SkillMetadata struct {
+	Name        string
+	Description string
+	// Fields holds every top-level frontmatter field, name and description
+	// included. Scalars are kept as written; lists and maps are encoded as
+	// JSON.
+	Fields map[string]string
+}
+
+// ParseSkillFrontmatter parses the YAML frontmatter at the start of a
+// SKILL.md, delimited by "---" lines. It returns nil if data has no
+// frontmatter, and an error if the frontmatter is not closed or is not a
+// YAML mapping.
+func ParseSkillFrontmatter(data []byte) (*SkillMetadata, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	rest, ok := bytes.CutPrefix(data, []byte("---\n"))
+	if !ok {
+		return nil, nil
+	}
+
+	var block []byte
+	switch {
+	case bytes.HasPrefix(rest, []byte("---\n")) || bytes.Equal(rest, []byte("---")):
+		// Empty frontmatter.
+	default:
+		end := bytes.Index(rest, []byte("\n---\n"))
+		if end < 0 && bytes.HasSuffix(rest, []byte("\n---")) {
+			end = len(rest) - len("\n---")
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("frontmatter is not closed by a \"---\" line")
+		}
+		block = rest[:end]
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(block, &raw); err != nil {
+		return nil, fmt.Errorf("frontmatter: %w", err)
+	}
+
+	m := &SkillMetadata{Fields: make(map[string]string, len(raw))}
+	for k, v := range raw {
+		s, err := frontmatterString(v)
+		if err != nil {
+			return nil, fmt.Errorf("frontmatter field %q: %w", k, err)
+		}
+		m.Fields[k] = s
+	}
+	m.Name = m.Fields["name"]
+	m.Description = m.Fields["description"]
+	return m, nil
+}
+
+// frontmatterString renders a decoded frontmatter value as a string.
+func frontmatterString(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// scanSkillFile parses the SKILL.md at the root of files, if there is one.
+func scanSkillFile(sourceDir string, files []FileEntry) (*SkillMetadata, error) {
+	for _, fe := range files {
+		if fe.RelPath != SkillFile {
+			continue
+		}
+		p := fe.AbsPath
+		if p == "" {
+			p = filepath.Join(sourceDir, SkillFile)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		return ParseSkillFrontmatter(data)
+	}
+	return nil, nil
+}
@@ -0,0 +1,215 @@
+// Package claudeversion tracks which generated features require a minimum
+// Claude Code release, checks those requirements against a plugin's
+// requires_claude_version constraint, and decides which features a
+// compatibility_level allows the provider to emit.
+package claudeversion
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Feature is a generated artifact feature that older Claude Code releases do
+// not understand.
+type Feature struct {
+	// Name is a human-readable description, e.g. "SessionEnd hook event".
+	Name string
+	// MinVersion is the first Claude Code release known to support it.
+	MinVersion string
+}
+
+// hookEventMinVersions lists hooks.json events that were added after the
+// initial hooks release, keyed by event name. The table is hand-maintained
+// and deliberately partial: events missing from it are assumed to be
+// supported by every release, so only add entries whose minimum version is
+// confirmed by the Claude Code changelog.
+var hookEventMinVersions = map[string]string{
+	"PreCompact":        "1.0.48",
+	"SessionStart":      "1.0.62",
+	"SessionEnd":        "1.0.85",
+	"SubagentStart":     "2.0.43",
+	"PermissionRequest": "2.0.45",
+}
+
+// manifestFieldMinVersions lists plugin.json fields added after the initial
+// plugin release, keyed by JSON field name. The same rules as for
+// hookEventMinVersions apply.
+var manifestFieldMinVersions = map[string]string{
+	"lspServers": "2.0.74",
+}
+
+// mcpFieldMinVersions lists MCP server fields, in .mcp.json and sub-agent
+// frontmatter alike, added after the initial MCP release, keyed by JSON
+// field name. The same rules as for hookEventMinVersions apply.
+var mcpFieldMinVersions = map[string]string{
+	"url": "1.0.27",
+}
+
+// subagentFieldMinVersions lists sub-agent frontmatter fields added after
+// the initial sub-agent release, keyed by YAML field name. The same rules
+// as for hookEventMinVersions apply.
+var subagentFieldMinVersions = map[string]string{
+	"hooks": "2.1.0",
+}
+
+// HookEventFeatures returns the features with a known minimum version among
+// the given hooks.json event names, sorted by name.
+func HookEventFeatures(events []string) []Feature {
+	var features []Feature
+	for _, event := range events {
+		if f, ok := HookEventFeature(event); ok {
+			features = append(features, f)
+		}
+	}
+	sort.Slice(features, func(i, j int) bool {
+		return features[i].Name < features[j].Name
+	})
+	return features
+}
+
+// HookEventFeature returns the feature for a hooks.json event name, and
+// false if the event has no known minimum version.
+func HookEventFeature(event string) (Feature, bool) {
+	return lookup(hookEventMinVersions, event, "%s hook event")
+}
+
+// ManifestFieldFeature returns the feature for a plugin.json field, and
+// false if the field has no known minimum version.
+func ManifestFieldFeature(field string) (Feature, bool) {
+	return lookup(manifestFieldMinVersions, field, "%s plugin manifest field")
+}
+
+// McpFieldFeature returns the feature for an MCP server field, and false if
+// the field has no known minimum version.
+func McpFieldFeature(field string) (Feature, bool) {
+	return lookup(mcpFieldMinVersions, field, "%s MCP server field")
+}
+
+// SubagentFieldFeature returns the feature for a sub-agent frontmatter
+// field, and false if the field has no known minimum version.
+func SubagentFieldFeature(field string) (Feature, bool) {
+	return lookup(subagentFieldMinVersions, field, "%s sub-agent frontmatter field")
+}
+
+func lookup(table map[string]string, key, nameFormat string) (Feature, bool) {
+	minVersion, ok := table[key]
+	if !ok {
+		return Feature{}, false
+	}
+	return Feature{Name: fmt.Sprintf(nameFormat, key), MinVersion: minVersion}, true
+}
+
+// Level is a parsed compatibility_level: the oldest Claude Code release that
+// generated artifacts must load in. A nil Level allows every feature.
+type Level struct {
+	v *version.Version
+}
+
+// ParseLevel parses a compatibility_level such as "2.0.30".
+func ParseLevel(s string) (*Level, error) {
+	v, err := version.NewVersion(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Level{v: v}, nil
+}
+
+// String returns the level as configured.
+func (l *Level) String() string {
+	if l == nil {
+		return ""
+	}
+	return l.v.Original()
+}
+
+// Allows reports whether every release at or after the level supports f.
+func (l *Level) Allows(f Feature) bool {
+	if l == nil {
+		return true
+	}
+	minVersion, err := version.NewVersion(f.MinVersion)
+	if err != nil {
+		return true
+	}
+	return !l.v.LessThan(minVersion)
+}
+
+// clausePattern splits a single constraint clause into its operator and
+// version, mirroring the operators accepted by go-version.
+var clausePattern = regexp.MustCompile(`^\s*(=|!=|>=|<=|>|<|~>)?\s*(\S+)\s*$`)
+
+// Constraint is a parsed requires_claude_version value.
+type Constraint struct {
+	constraints version.Constraints
+	// lower is the oldest version the constraint can admit, or nil when the
+	// constraint has no lower bound.
+	lower *version.Version
+}
+
+// ParseConstraint parses a version constraint such as ">= 1.0.85" or
+// ">= 2.0, < 3.0".
+func ParseConstraint(s string) (*Constraint, error) {
+	constraints, err := version.NewConstraint(s)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Constraint{constraints: constraints}
+	for _, clause := range strings.Split(s, ",") {
+		m := clausePattern.FindStringSubmatch(clause)
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "", "=", ">=", ">", "~>":
+			v, err := version.NewVersion(m[2])
+			if err != nil {
+				return nil, err
+			}
+			if c.lower == nil || v.GreaterThan(c.lower) {
+				c.lower = v
+			}
+		}
+	}
+	return c, nil
+}
+
+// Unsatisfied returns the features whose minimum version is newer than the
+// oldest release the constraint admits, i.e. the features that could be
+// loaded by a Claude Code release that does not support them.
+func (c *Constraint) Unsatisfied(features []Feature) []Feature {
+	var out []Feature
+	for _, f := range features {
+		minVersion, err := version.NewVersion(f.MinVersion)
+		if err != nil {
+			continue
+		}
+		if c.lower == nil || c.lower.LessThan(minVersion) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// MinVersion returns the newest MinVersion among features, or "" when
+// features is empty.
+func MinVersion(features []Feature) string {
+	var newest *version.Version
+	for _, f := range features {
+		v, err := version.NewVersion(f.MinVersion)
+		if err != nil {
+			continue
+		}
+		if newest == nil || v.GreaterThan(newest) {
+			newest = v
+		}
+	}
+	if newest == nil {
+		return ""
+	}
+	return newest.Original()
+}
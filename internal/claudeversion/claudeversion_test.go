@@ -0,0 +1,109 @@
+package claudeversion
+
+import "testing"
+
+func TestHookEventFeatures(t *testing.T) {
+	got := HookEventFeatures([]string{"PreToolUse", "SessionEnd", "PreCompact"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 features, got %v", got)
+	}
+	if got[0].Name != "PreCompact hook event" || got[1].Name != "SessionEnd hook event" {
+		t.Errorf("unexpected features: %v", got)
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, s := range []string{"", "latest", ">= one", "=> 1.0"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q): expected error", s)
+		}
+	}
+}
+
+func TestConstraint_Unsatisfied(t *testing.T) {
+	features := []Feature{
+		{Name: "SessionEnd hook event", MinVersion: "1.0.85"},
+		{Name: "SubagentStart hook event", MinVersion: "2.0.43"},
+	}
+
+	cases := []struct {
+		constraint string
+		want       int
+	}{
+		{">= 2.0.43", 0},
+		{">= 1.0.85", 1},
+		{"~> 2.1", 0},
+		{"= 1.0.90", 1},
+		{">= 1.0.0", 2},
+		{"< 3.0", 2},
+		{">= 1.0, < 3.0, >= 2.0.50", 0},
+	}
+	for _, tc := range cases {
+		c, err := ParseConstraint(tc.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q): %v", tc.constraint, err)
+		}
+		if got := c.Unsatisfied(features); len(got) != tc.want {
+			t.Errorf("%q: got %d unsatisfied features %v, want %d", tc.constraint, len(got), got, tc.want)
+		}
+	}
+}
+
+func TestMinVersion(t *testing.T) {
+	features := []Feature{
+		{Name: "a", MinVersion: "2.0.43"},
+		{Name: "b", MinVersion: "1.0.85"},
+	}
+	if got := MinVersion(features); got != "2.0.43" {
+		t.Errorf("MinVersion = %q, want 2.0.43", got)
+	}
+	if got := MinVersion(nil); got != "" {
+		t.Errorf("MinVersion(nil) = %q, want empty", got)
+	}
+}
+
+func TestParseLevel_Invalid(t *testing.T) {
+	for _, s := range []string{"", "latest", ">= 2.0"} {
+		if _, err := ParseLevel(s); err == nil {
+			t.Errorf("ParseLevel(%q): expected error", s)
+		}
+	}
+}
+
+func TestLevel_Allows(t *testing.T) {
+	sessionEnd, _ := HookEventFeature("SessionEnd")
+	lsp, _ := ManifestFieldFeature("lspServers")
+
+	level, err := ParseLevel("2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !level.Allows(sessionEnd) {
+		t.Errorf("level 2.0.0 should allow %s (>= %s)", sessionEnd.Name, sessionEnd.MinVersion)
+	}
+	if level.Allows(lsp) {
+		t.Errorf("level 2.0.0 should not allow %s (>= %s)", lsp.Name, lsp.MinVersion)
+	}
+
+	exact, _ := ParseLevel(lsp.MinVersion)
+	if !exact.Allows(lsp) {
+		t.Errorf("level %s should allow %s", lsp.MinVersion, lsp.Name)
+	}
+
+	var unset *Level
+	if !unset.Allows(lsp) {
+		t.Error("a nil level should allow every feature")
+	}
+}
+
+func TestFeatureLookups(t *testing.T) {
+	if _, ok := HookEventFeature("PreToolUse"); ok {
+		t.Error("PreToolUse should have no minimum version")
+	}
+	if f, ok := McpFieldFeature("url"); !ok || f.Name != "url MCP server field" {
+		t.Errorf("McpFieldFeature(url) = %v, %v", f, ok)
+	}
+	if f, ok := SubagentFieldFeature("hooks"); !ok || f.Name != "hooks sub-agent frontmatter field" {
+		t.Errorf("SubagentFieldFeature(hooks) = %v, %v", f, ok)
+	}
+}
@@ -0,0 +1,59 @@
+package concurrency
+
+import (
+	"context"
+	"sync"
+)
+
+// Pool bounds how many calls run at once across every Do on it. The
+// provider shares one Pool between all resources to cap concurrent
+// refreshes, independently of the slots of the Scheduler that uploads and
+// per-file checks share.
+type Pool struct {
+	workers chan struct{}
+}
+
+// NewPool returns a Pool with n workers. Values below 1 are treated as 1.
+func NewPool(n int) *Pool {
+	return &Pool{workers: make(chan struct{}, max(n, 1))}
+}
+
+// Size returns the number of workers.
+func (p *Pool) Size() int {
+	return cap(p.workers)
+}
+
+// Do calls fn(i) for each i in [0, n), each on its own goroutine once it
+// holds a worker, and waits for the calls to return. Calls still waiting
+// for a worker when ctx is done are not made, and Do returns ctx.Err().
+// A nil Pool makes the calls one at a time on the calling goroutine.
+func (p *Pool) Do(ctx context.Context, n int, fn func(i int)) error {
+	if p == nil {
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			fn(i)
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i := 0; i < n; i++ {
+		select {
+		case p.workers <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-p.workers
+				wg.Done()
+			}()
+			fn(i)
+		}()
+	}
+	return nil
+}
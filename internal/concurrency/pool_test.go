@@ -0,0 +1,90 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_BoundsConcurrentCallsAcrossDo(t *testing.T) {
+	p := NewPool(3)
+	var running, peak atomic.Int64
+	call := func(int) {
+		n := running.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+	}
+
+	// Two resources refreshing at the same time share the workers.
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.Do(context.Background(), 10, call); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 3 {
+		t.Errorf("%d calls ran at once, want at most 3", got)
+	}
+	if got := peak.Load(); got < 2 {
+		t.Errorf("calls never overlapped (peak %d)", got)
+	}
+}
+
+func TestPool_DoWaitsForCalls(t *testing.T) {
+	p := NewPool(4)
+	results := make([]int, 8)
+	if err := p.Do(context.Background(), len(results), func(i int) {
+		time.Sleep(time.Millisecond)
+		results[i] = i * i
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i, got := range results {
+		if got != i*i {
+			t.Errorf("results[%d] = %d, want %d: Do returned before the call finished", i, got, i*i)
+		}
+	}
+}
+
+func TestPool_CanceledSkipsWaitingCalls(t *testing.T) {
+	p := NewPool(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int64
+	err := p.Do(ctx, 5, func(int) {
+		calls.Add(1)
+		cancel()
+		time.Sleep(5 * time.Millisecond)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("%d calls made, want only the one running at cancellation", got)
+	}
+}
+
+func TestPool_NilRunsSequentially(t *testing.T) {
+	var p *Pool
+	var order []int
+	if err := p.Do(context.Background(), 3, func(i int) { order = append(order, i) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Errorf("calls made in order %v, want [0 1 2]", order)
+	}
+}
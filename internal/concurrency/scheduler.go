@@ -0,0 +1,189 @@
+// Package concurrency implements the slot scheduler that bounds the number
+// of storage operations the provider runs at once.
+package concurrency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Op classifies an operation for scheduling. Writes are uploads and
+// deletes made by apply and destroy; reads are refresh-time checks such as
+// HEAD requests. The zero value is Write, so a zero Config prioritizes
+// writes.
+type Op int
+
+const (
+	Write Op = iota
+	Read
+)
+
+func (o Op) String() string {
+	if o == Read {
+		return "read"
+	}
+	return "write"
+}
+
+// Config configures a Scheduler.
+type Config struct {
+	// MaxSlots is the total number of slots. Values below 1 are treated
+	// as 1.
+	MaxSlots int64
+	// ReadSlots caps the slots reads may hold at once, so a refresh of many
+	// resources leaves room for writes. 0 means MaxSlots.
+	ReadSlots int64
+	// Priority is the Op whose waiters are granted slots first when both
+	// kinds are queued. Defaults to Write.
+	Priority Op
+	// BytesPerSlot makes uploads weigh one slot per BytesPerSlot bytes, so
+	// large files take a larger share of the concurrency budget. 0 weighs
+	// every operation as one slot.
+	BytesPerSlot int64
+}
+
+// Scheduler is a weighted semaphore with separate queues for reads and
+// writes. Waiters of the priority Op are served first; within an Op,
+// waiters are served in arrival order. Like semaphore.Weighted, a waiter
+// at the head of a queue blocks those behind it until it fits, so large
+// requests are not starved by small ones.
+type Scheduler struct {
+	cfg Config
+
+	mu      sync.Mutex
+	cur     int64
+	curRead int64
+	waiters [2]list.List // indexed by Op
+}
+
+type waiter struct {
+	op    Op
+	n     int64
+	ready chan struct{}
+}
+
+// New returns a Scheduler for cfg.
+func New(cfg Config) *Scheduler {
+	if cfg.MaxSlots < 1 {
+		cfg.MaxSlots = 1
+	}
+	if cfg.ReadSlots <= 0 || cfg.ReadSlots > cfg.MaxSlots {
+		cfg.ReadSlots = cfg.MaxSlots
+	}
+	return &Scheduler{cfg: cfg}
+}
+
+// NewUniform returns a Scheduler with n slots, no read cap, and unit
+// weights, equivalent to semaphore.NewWeighted(n).
+func NewUniform(n int64) *Scheduler {
+	return New(Config{MaxSlots: n})
+}
+
+// Weight returns the number of slots an upload of size bytes should
+// acquire: one per started BytesPerSlot, at least one, and at most
+// MaxSlots.
+func (s *Scheduler) Weight(size int64) int64 {
+	if s.cfg.BytesPerSlot <= 0 || size <= s.cfg.BytesPerSlot {
+		return 1
+	}
+	return min((size+s.cfg.BytesPerSlot-1)/s.cfg.BytesPerSlot, s.cfg.MaxSlots)
+}
+
+// Weighted reports whether uploads are weighted by size.
+func (s *Scheduler) Weighted() bool {
+	return s.cfg.BytesPerSlot > 0
+}
+
+// Acquire blocks until n slots are available for op or ctx is done. n is
+// clamped to the slots op can ever hold, so an oversized request waits for
+// an idle scheduler instead of deadlocking. On failure no slots are held.
+func (s *Scheduler) Acquire(ctx context.Context, op Op, n int64) error {
+	n = s.clamp(op, n)
+
+	s.mu.Lock()
+	w := &waiter{op: op, n: n, ready: make(chan struct{})}
+	elem := s.waiters[op].PushBack(w)
+	s.grant()
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// Granted while the context was being canceled; hand the
+			// slots back so the caller sees a clean failure.
+			s.release(op, n)
+		default:
+			s.waiters[op].Remove(elem)
+			// The removed waiter may have been blocking the queue.
+			s.grant()
+		}
+		return ctx.Err()
+	}
+}
+
+// Release returns n slots acquired for op.
+func (s *Scheduler) Release(op Op, n int64) {
+	n = s.clamp(op, n)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.release(op, n)
+}
+
+func (s *Scheduler) release(op Op, n int64) {
+	s.cur -= n
+	if op == Read {
+		s.curRead -= n
+	}
+	if s.cur < 0 || s.curRead < 0 {
+		panic("concurrency: released more slots than held")
+	}
+	s.grant()
+}
+
+func (s *Scheduler) clamp(op Op, n int64) int64 {
+	limit := s.cfg.MaxSlots
+	if op == Read {
+		limit = s.cfg.ReadSlots
+	}
+	return max(1, min(n, limit))
+}
+
+// fitsTotal reports whether n more slots fit under MaxSlots.
+func (s *Scheduler) fitsTotal(n int64) bool {
+	return s.cur+n <= s.cfg.MaxSlots
+}
+
+// grant hands slots to queued waiters, priority queue first. If the head
+// of the priority queue is waiting for total capacity, the other queue gets
+// nothing, so a stream of small operations of the other kind cannot starve
+// it. A read head that is only over the read cap does not hold back writes.
+// The caller must hold s.mu.
+func (s *Scheduler) grant() {
+	order := [2]Op{s.cfg.Priority, 1 - s.cfg.Priority}
+	for _, op := range order {
+		q := &s.waiters[op]
+		for q.Len() > 0 {
+			front := q.Front()
+			w := front.Value.(*waiter)
+			if !s.fitsTotal(w.n) {
+				return
+			}
+			if w.op == Read && s.curRead+w.n > s.cfg.ReadSlots {
+				break
+			}
+			s.cur += w.n
+			if w.op == Read {
+				s.curRead += w.n
+			}
+			q.Remove(front)
+			close(w.ready)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquireAsync starts an Acquire and returns a channel that receives its
+// result.
+func acquireAsync(s *Scheduler, op Op, n int64) chan error {
+	done := make(chan error, 1)
+	go func() { done <- s.Acquire(context.Background(), op, n) }()
+	return done
+}
+
+func waitQueued(t *testing.T, s *Scheduler, op Op, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		l := s.waiters[op].Len()
+		s.mu.Unlock()
+		if l == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%s queue never reached %d waiters", op, n)
+}
+
+func assertPending(t *testing.T, done chan error, what string) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("%s acquired early (err=%v)", what, err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func assertAcquired(t *testing.T, done chan error, what string) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("%s: %v", what, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("%s never acquired", what)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	s := New(Config{MaxSlots: 4, BytesPerSlot: 100})
+	for _, tc := range []struct {
+		size, want int64
+	}{
+		{0, 1}, {100, 1}, {101, 2}, {300, 3}, {10_000, 4},
+	} {
+		if got := s.Weight(tc.size); got != tc.want {
+			t.Errorf("Weight(%d) = %d, want %d", tc.size, got, tc.want)
+		}
+	}
+
+	if got := NewUniform(4).Weight(10_000); got != 1 {
+		t.Errorf("unweighted Weight = %d, want 1", got)
+	}
+}
+
+func TestScheduler_WritePriority(t *testing.T) {
+	s := New(Config{MaxSlots: 1, Priority: Write})
+	ctx := context.Background()
+	if err := s.Acquire(ctx, Read, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	read := acquireAsync(s, Read, 1)
+	waitQueued(t, s, Read, 1)
+	write := acquireAsync(s, Write, 1)
+	waitQueued(t, s, Write, 1)
+
+	s.Release(Read, 1)
+	assertAcquired(t, write, "write")
+	assertPending(t, read, "read")
+
+	s.Release(Write, 1)
+	assertAcquired(t, read, "read")
+}
+
+func TestScheduler_ReadPriority(t *testing.T) {
+	s := New(Config{MaxSlots: 1, Priority: Read})
+	ctx := context.Background()
+	if err := s.Acquire(ctx, Write, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	write := acquireAsync(s, Write, 1)
+	waitQueued(t, s, Write, 1)
+	read := acquireAsync(s, Read, 1)
+	waitQueued(t, s, Read, 1)
+
+	s.Release(Write, 1)
+	assertAcquired(t, read, "read")
+	assertPending(t, write, "write")
+	s.Release(Read, 1)
+	assertAcquired(t, write, "write")
+}
+
+func TestScheduler_ReadSlotsLeaveRoomForWrites(t *testing.T) {
+	s := New(Config{MaxSlots: 3, ReadSlots: 2})
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := s.Acquire(ctx, Read, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := acquireAsync(s, Read, 1)
+	assertPending(t, read, "third read")
+
+	// The read blocked on the read cap must not hold back writes.
+	if err := s.Acquire(ctx, Write, 1); err != nil {
+		t.Fatal(err)
+	}
+	s.Release(Write, 1)
+
+	s.Release(Read, 1)
+	assertAcquired(t, read, "third read")
+}
+
+func TestScheduler_LargeWaiterBlocksSmallerOnes(t *testing.T) {
+	s := New(Config{MaxSlots: 4})
+	ctx := context.Background()
+	if err := s.Acquire(ctx, Write, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	big := acquireAsync(s, Write, 4)
+	waitQueued(t, s, Write, 1)
+	small := acquireAsync(s, Read, 1)
+	assertPending(t, small, "small read")
+
+	s.Release(Write, 3)
+	assertAcquired(t, big, "big write")
+	assertPending(t, small, "small read")
+	s.Release(Write, 4)
+	assertAcquired(t, small, "small read")
+}
+
+func TestScheduler_OversizedRequestIsClamped(t *testing.T) {
+	s := New(Config{MaxSlots: 2, ReadSlots: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := s.Acquire(ctx, Write, 10); err != nil {
+		t.Fatal(err)
+	}
+	s.Release(Write, 10)
+	if err := s.Acquire(ctx, Read, 10); err != nil {
+		t.Fatal(err)
+	}
+	s.Release(Read, 10)
+}
+
+func TestScheduler_CanceledWaiterHoldsNothing(t *testing.T) {
+	s := New(Config{MaxSlots: 2})
+	if err := s.Acquire(context.Background(), Write, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Acquire(ctx, Write, 2) }()
+	waitQueued(t, s, Write, 1)
+
+	// A small request queued behind the canceled head must proceed.
+	small := acquireAsync(s, Write, 1)
+	waitQueued(t, s, Write, 2)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	assertAcquired(t, small, "small write")
+
+	s.Release(Write, 1)
+	s.Release(Write, 1)
+	if s.cur != 0 {
+		t.Errorf("cur = %d after releasing everything", s.cur)
+	}
+}
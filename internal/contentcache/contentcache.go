@@ -0,0 +1,79 @@
+// Package contentcache stores file content in a directory keyed by its
+// SHA-256 hash, so generated files can be restored byte for byte in a fresh
+// workspace.
+//
+// An entry for the hash "sha256:<hex>" lives at <dir>/sha256/<hex[:2]>/<hex>.
+// Entries are immutable: Put skips content that is already cached, and Get
+// rejects an entry whose content no longer matches its name. Several
+// workspaces, such as CI runners sharing a mounted volume, may use the same
+// directory concurrently.
+package contentcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+)
+
+const hashPrefix = "sha256:"
+
+// Hash returns the key data is stored under, in "sha256:<hex>" form.
+func Hash(data []byte) string {
+	h := sha256.Sum256(data)
+	return hashPrefix + hex.EncodeToString(h[:])
+}
+
+// Put stores data in dir and returns its hash. Content that is already
+// cached is not rewritten.
+func Put(dir string, data []byte) (string, error) {
+	hash := Hash(data)
+	p, err := entryPath(dir, hash)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(p); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return "", fmt.Errorf("contentcache: %w", err)
+	}
+	if err := atomicfile.WriteFile(p, data, 0o644); err != nil {
+		return "", fmt.Errorf("contentcache: %w", err)
+	}
+	return hash, nil
+}
+
+// Get returns the content stored in dir under hash. A missing entry returns
+// an error satisfying os.IsNotExist; an entry whose content does not match
+// hash, such as one truncated by a full disk, is an error too.
+func Get(dir, hash string) ([]byte, error) {
+	p, err := entryPath(dir, hash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if got := Hash(data); got != hash {
+		return nil, fmt.Errorf("contentcache: entry %s is corrupt (content hashes to %s)", hash, got)
+	}
+	return data, nil
+}
+
+// entryPath returns the file an entry for hash is stored in.
+func entryPath(dir, hash string) (string, error) {
+	hexHash, ok := strings.CutPrefix(hash, hashPrefix)
+	if !ok || len(hexHash) != sha256.Size*2 {
+		return "", fmt.Errorf("contentcache: invalid hash %q", hash)
+	}
+	if _, err := hex.DecodeString(hexHash); err != nil {
+		return "", fmt.Errorf("contentcache: invalid hash %q", hash)
+	}
+	return filepath.Join(dir, "sha256", hexHash[:2], hexHash), nil
+}
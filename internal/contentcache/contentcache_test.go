@@ -0,0 +1,64 @@
+package contentcache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPutGet(t *testing.T) {
+	dir := t.TempDir()
+
+	hash, err := Put(dir, []byte("hello\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != Hash([]byte("hello\n")) {
+		t.Errorf("Put returned %q, want %q", hash, Hash([]byte("hello\n")))
+	}
+
+	// A second Put of the same content is a no-op.
+	if again, err := Put(dir, []byte("hello\n")); err != nil || again != hash {
+		t.Errorf("second Put = %q, %v", again, err)
+	}
+
+	data, err := Get(dir, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello\n" {
+		t.Errorf("Get = %q", data)
+	}
+}
+
+func TestGet_Missing(t *testing.T) {
+	_, err := Get(t.TempDir(), Hash([]byte("absent")))
+	if !os.IsNotExist(err) {
+		t.Errorf("err = %v, want not-exist", err)
+	}
+}
+
+func TestGet_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	hash, err := Put(dir, []byte("original"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hexHash := strings.TrimPrefix(hash, hashPrefix)
+	if err := os.WriteFile(filepath.Join(dir, "sha256", hexHash[:2], hexHash), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Get(dir, hash); err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("err = %v, want corrupt entry error", err)
+	}
+}
+
+func TestGet_InvalidHash(t *testing.T) {
+	for _, hash := range []string{"", "md5:abcd", "sha256:xyz", "sha256:../../etc/passwd"} {
+		if _, err := Get(t.TempDir(), hash); err == nil || os.IsNotExist(err) {
+			t.Errorf("Get(%q) err = %v, want invalid hash error", hash, err)
+		}
+	}
+}
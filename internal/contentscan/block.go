@@ -0,0 +1,136 @@
+package contentscan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// Values accepted by the policy attribute.
+const (
+	PolicyWarn  = "warn"
+	PolicyError = "error"
+)
+
+// BlockModel maps the optional content_scan {} block of the resources that
+// scan their content.
+type BlockModel struct {
+	Policy       types.String `tfsdk:"policy"`
+	IgnoreRules  types.List   `tfsdk:"ignore_rules"`
+	AllowedHosts types.List   `tfsdk:"allowed_hosts"`
+	AllowedPaths types.List   `tfsdk:"allowed_paths"`
+}
+
+// Block returns the schema of the content_scan block. what names the files
+// that are scanned, e.g. "the Markdown files of the skill bundle".
+func Block(what string) schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: fmt.Sprintf("Scans %s for patterns used to hide instructions from reviewers: instructions in HTML comments, "+
+			"URLs that can carry data out, long base64 runs, and invisible Unicode. At most one block may be specified.", what),
+		Validators: []validator.List{
+			listvalidator.SizeAtMost(1),
+		},
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"policy": schema.StringAttribute{
+					MarkdownDescription: "How findings are reported. Valid values: `warn` (default), `error`.",
+					Optional:            true,
+					Computed:            true,
+					Default:             stringdefault.StaticString(PolicyWarn),
+					Validators: []validator.String{
+						stringvalidator.OneOf(PolicyWarn, PolicyError),
+					},
+				},
+				"ignore_rules": schema.ListAttribute{
+					MarkdownDescription: "Rules that are not run: `" + strings.Join(Rules, "`, `") + "`.",
+					Optional:            true,
+					ElementType:         types.StringType,
+					Validators: []validator.List{
+						listvalidator.ValueStringsAre(stringvalidator.OneOf(Rules...)),
+					},
+				},
+				"allowed_hosts": schema.ListAttribute{
+					MarkdownDescription: "Hosts whose URLs are never reported by `exfiltration_url`, e.g. `docs.example.com`. A host also allows its subdomains.",
+					Optional:            true,
+					ElementType:         types.StringType,
+					Validators: []validator.List{
+						listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+					},
+				},
+				"allowed_paths": schema.ListAttribute{
+					MarkdownDescription: "Glob patterns, in `path.Match` syntax, of the slash-separated paths of files that are not scanned, e.g. `examples/*.md`. A pattern that names a directory skips everything under it.",
+					Optional:            true,
+					ElementType:         types.StringType,
+					Validators: []validator.List{
+						listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+					},
+				},
+			},
+		},
+	}
+}
+
+// Config returns the scan options and policy of blocks, a content_scan
+// block list. ok is false when there is no block, or when any of its
+// values is not yet known.
+func Config(ctx context.Context, blocks []BlockModel) (opts Options, policy string, ok bool, diags diag.Diagnostics) {
+	if len(blocks) == 0 {
+		return opts, "", false, diags
+	}
+	b := blocks[0]
+	if b.Policy.IsUnknown() || b.IgnoreRules.IsUnknown() || b.AllowedHosts.IsUnknown() || b.AllowedPaths.IsUnknown() {
+		return opts, "", false, diags
+	}
+	policy = PolicyWarn
+	if !b.Policy.IsNull() {
+		policy = b.Policy.ValueString()
+	}
+	for _, l := range []struct {
+		list types.List
+		dst  *[]string
+	}{
+		{b.IgnoreRules, &opts.IgnoreRules},
+		{b.AllowedHosts, &opts.AllowedHosts},
+		{b.AllowedPaths, &opts.AllowedPaths},
+	} {
+		if l.list.IsNull() {
+			continue
+		}
+		diags.Append(l.list.ElementsAs(ctx, l.dst, false)...)
+	}
+	return opts, policy, !diags.HasError(), diags
+}
+
+// Report returns one diagnostic listing findings, a warning or an error
+// according to policy, or none when there are no findings. subject names
+// what was scanned, e.g. `skill "review"`.
+func Report(findings []Finding, policy, subject string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(findings) == 0 {
+		return diags
+	}
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = "  - " + f.String()
+	}
+	summary := errcode.SuspiciousContent.Summary("Suspicious Content")
+	detail := fmt.Sprintf("The content of %s matches patterns used to hide instructions from reviewers:\n\n%s\n\n"+
+		"Review the content. If it is expected, allow it in the content_scan block with allowed_hosts, allowed_paths, or ignore_rules.",
+		subject, strings.Join(lines, "\n"))
+	if policy == PolicyError {
+		diags.AddError(summary, detail)
+	} else {
+		diags.AddWarning(summary, detail)
+	}
+	return diags
+}
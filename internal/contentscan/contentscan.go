@@ -0,0 +1,316 @@
+// Package contentscan flags patterns in Markdown that are used to smuggle
+// instructions to a model past a human reviewer: instructions hidden in
+// HTML comments, URLs that carry data out, long base64 runs, and invisible
+// Unicode. Skills and plugins run it as a supply-chain guard before their
+// content is distributed. The rules are heuristics; allowlists in Options
+// silence findings that are expected.
+package contentscan
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule names, as accepted by ignore_rules.
+const (
+	// RuleHiddenComment flags HTML comments with instruction-like text.
+	// Comments are not rendered, so a reviewer reading the rendered
+	// Markdown does not see what the model reads.
+	RuleHiddenComment = "hidden_comment"
+	// RuleExfiltrationURL flags URLs that point at request capture
+	// services or IP addresses, carry placeholders for data, or are
+	// images with a query string, which clients fetch without a click.
+	RuleExfiltrationURL = "exfiltration_url"
+	// RuleBase64Blob flags long base64 runs, which can hide instructions
+	// or payloads from review.
+	RuleBase64Blob = "base64_blob"
+	// RuleInvisibleUnicode flags zero-width, bidirectional control, and
+	// Unicode tag characters, which hide or reorder text.
+	RuleInvisibleUnicode = "invisible_unicode"
+)
+
+// Rules lists every rule.
+var Rules = []string{RuleHiddenComment, RuleExfiltrationURL, RuleBase64Blob, RuleInvisibleUnicode}
+
+// markdownExts are the extensions of the files Dir scans.
+var markdownExts = map[string]bool{".md": true, ".markdown": true, ".mdx": true}
+
+var (
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--(.*?)-->`)
+	// instructionPattern matches wording typical of instructions aimed at
+	// a model rather than notes for maintainers.
+	instructionPattern = regexp.MustCompile(`(?i)\b((?:ignore|disregard|forget)\s+(?:the|all|any|previous|prior|above|earlier|your|these|those|this)|override|instructions?|system prompt|you are|you must|you should|do not (?:tell|mention|reveal)|don't (?:tell|mention|reveal)|assistant|claude|secrets?|credentials?|api[ _-]?keys?|tokens?|exfiltrat\w*|upload|curl|wget)\b`)
+	urlPattern         = regexp.MustCompile("https?://[^\\s<>\"'`()\\[\\]]+")
+	imageURLPattern    = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?(https?://[^\s)>]+)`)
+	base64Pattern      = regexp.MustCompile(`[A-Za-z0-9+/_-]{120,}={0,2}`)
+)
+
+// captureHosts are request capture and tunneling services, which receive
+// whatever a fetched URL carries. Subdomains match too.
+var captureHosts = []string{
+	"webhook.site",
+	"requestbin.com",
+	"requestbin.net",
+	"requestcatcher.com",
+	"pipedream.net",
+	"beeceptor.com",
+	"hookbin.com",
+	"ngrok.io",
+	"ngrok.app",
+	"ngrok-free.app",
+	"trycloudflare.com",
+	"burpcollaborator.net",
+	"oastify.com",
+	"interact.sh",
+	"oast.fun",
+	"oast.live",
+	"oast.me",
+	"oast.online",
+	"oast.pro",
+	"oast.site",
+	"canarytokens.com",
+}
+
+// Options configures a scan. The zero value runs every rule on every file.
+type Options struct {
+	// IgnoreRules names rules that are not run.
+	IgnoreRules []string
+	// AllowedHosts are hosts whose URLs RuleExfiltrationURL never reports.
+	// A host also allows its subdomains.
+	AllowedHosts []string
+	// AllowedPaths are path.Match patterns of slash-separated paths,
+	// relative to the scanned root, of files that are not scanned. A
+	// pattern that names a directory skips everything under it.
+	AllowedPaths []string
+}
+
+// Finding is a match of a rule.
+type Finding struct {
+	// Path is the slash-separated path of the file, relative to the
+	// scanned root.
+	Path string
+	// Line is the 1-based line the match starts on.
+	Line int
+	Rule string
+	// Detail describes the match, with an excerpt where it helps.
+	Detail string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", f.Path, f.Line, f.Rule, f.Detail)
+}
+
+// Skip reports whether rel, a slash-separated path relative to the scanned
+// root, matches AllowedPaths.
+func (o Options) Skip(rel string) bool {
+	for _, p := range o.AllowedPaths {
+		p = strings.TrimSuffix(p, "/")
+		if ok, _ := path.Match(p, rel); ok || strings.HasPrefix(rel, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMarkdown reports whether rel names a Markdown file, the kind of file
+// Dir scans.
+func IsMarkdown(rel string) bool {
+	return markdownExts[strings.ToLower(path.Ext(rel))]
+}
+
+// File returns the findings in data, the content of the file at rel, in
+// line order. It does not consult AllowedPaths; see Skip.
+func (o Options) File(rel string, data []byte) []Finding {
+	text := string(data)
+	lines := newLineIndex(text)
+	var findings []Finding
+	add := func(offset int, rule, detail string) {
+		findings = append(findings, Finding{Path: rel, Line: lines.line(offset), Rule: rule, Detail: detail})
+	}
+
+	if o.runs(RuleHiddenComment) {
+		for _, m := range htmlCommentPattern.FindAllStringSubmatchIndex(text, -1) {
+			body := text[m[2]:m[3]]
+			if instructionPattern.MatchString(body) {
+				add(m[0], RuleHiddenComment, fmt.Sprintf("HTML comment with instruction-like text %q", excerpt(body)))
+			}
+		}
+	}
+
+	if o.runs(RuleExfiltrationURL) {
+		images := make(map[int]bool)
+		for _, m := range imageURLPattern.FindAllStringSubmatchIndex(text, -1) {
+			images[m[2]] = true
+		}
+		for _, m := range urlPattern.FindAllStringIndex(text, -1) {
+			raw := strings.TrimRight(text[m[0]:m[1]], ".,;:!?*_")
+			if reason := o.exfiltrationReason(raw, images[m[0]]); reason != "" {
+				add(m[0], RuleExfiltrationURL, fmt.Sprintf("%s: %s", reason, excerpt(raw)))
+			}
+		}
+	}
+
+	if o.runs(RuleBase64Blob) {
+		for _, m := range base64Pattern.FindAllStringIndex(text, -1) {
+			run := text[m[0]:m[1]]
+			if strings.ContainsAny(run, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") &&
+				strings.ContainsAny(run, "abcdefghijklmnopqrstuvwxyz") &&
+				strings.ContainsAny(run, "0123456789") {
+				add(m[0], RuleBase64Blob, fmt.Sprintf("base64-like run of %d characters %q", len(run), excerpt(run)))
+			}
+		}
+	}
+
+	if o.runs(RuleInvisibleUnicode) {
+		// One finding per line, listing the characters found on it.
+		lastLine := 0
+		for i, r := range text {
+			if !invisible(r) || (i == 0 && r == 0xFEFF) {
+				continue
+			}
+			line := lines.line(i)
+			if line == lastLine {
+				f := &findings[len(findings)-1]
+				if code := fmt.Sprintf("U+%04X", r); !strings.Contains(f.Detail, code) {
+					f.Detail += ", " + code
+				}
+				continue
+			}
+			lastLine = line
+			add(i, RuleInvisibleUnicode, fmt.Sprintf("invisible characters U+%04X", r))
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings
+}
+
+// Dir scans the Markdown files under root, except those AllowedPaths
+// matches and directories named .git, and returns the findings in path
+// order.
+func (o Options) Dir(root string) ([]Finding, error) {
+	var findings []Finding
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || (rel != "." && o.Skip(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !IsMarkdown(rel) || o.Skip(rel) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, o.File(rel, data)...)
+		return nil
+	})
+	return findings, err
+}
+
+// runs reports whether rule is not ignored.
+func (o Options) runs(rule string) bool {
+	for _, r := range o.IgnoreRules {
+		if r == rule {
+			return false
+		}
+	}
+	return true
+}
+
+// exfiltrationReason returns why raw looks like an exfiltration URL, or ""
+// when it does not. image is true when raw is the source of a Markdown
+// image.
+func (o Options) exfiltrationReason(raw string, image bool) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if matchesHost(host, o.AllowedHosts) {
+		return ""
+	}
+	switch {
+	case matchesHost(host, captureHosts):
+		return "URL of a request capture service"
+	case net.ParseIP(host) != nil:
+		return "URL with an IP address host"
+	case strings.ContainsAny(raw, "{}$") || strings.Contains(strings.ToLower(raw), "%7b"):
+		return "URL with a placeholder for data"
+	case image && u.RawQuery != "":
+		return "image URL with a query string, fetched without a click"
+	}
+	return ""
+}
+
+// matchesHost reports whether host is one of hosts or a subdomain of one.
+func matchesHost(host string, hosts []string) bool {
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimPrefix(h, "."))
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// invisible reports whether r is a zero-width, bidirectional control, or
+// tag character.
+func invisible(r rune) bool {
+	switch {
+	case r >= 0x200B && r <= 0x200F, // zero-width space, joiners, marks
+		r >= 0x202A && r <= 0x202E,   // bidirectional embeddings and overrides
+		r >= 0x2060 && r <= 0x2064,   // word joiner, invisible operators
+		r >= 0x2066 && r <= 0x2069,   // bidirectional isolates
+		r == 0xFEFF,                  // zero-width no-break space
+		r >= 0xE0000 && r <= 0xE007F: // tags
+		return true
+	}
+	return false
+}
+
+// excerpt returns s with whitespace collapsed, cut to 60 characters.
+func excerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= 60 {
+		return s
+	}
+	return string([]rune(s)[:57]) + "..."
+}
+
+// lineIndex maps byte offsets to line numbers.
+type lineIndex []int
+
+func newLineIndex(text string) lineIndex {
+	starts := lineIndex{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// line returns the 1-based line of offset.
+func (l lineIndex) line(offset int) int {
+	return sort.Search(len(l), func(i int) bool { return l[i] > offset })
+}
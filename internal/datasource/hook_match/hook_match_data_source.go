@@ -0,0 +1,330 @@
+package hookmatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// Compile-time interface checks.
+var _ datasource.DataSource = &HookMatchDataSource{}
+
+// NewHookMatchDataSource returns a new datasource.DataSource for the
+// agentctx_hook_match type.
+func NewHookMatchDataSource() datasource.DataSource {
+	return &HookMatchDataSource{}
+}
+
+// HookMatchDataSource implements the agentctx_hook_match data source. It
+// evaluates a hooks.json document against a sample event and tool name and
+// reports which matcher entries would fire, without touching disk.
+type HookMatchDataSource struct{}
+
+// hookEvents maps the snake_case block names used by agentctx_plugin to the
+// event keys written to hooks.json.
+var hookEvents = map[string]string{
+	"pre_tool_use":          "PreToolUse",
+	"post_tool_use":         "PostToolUse",
+	"post_tool_use_failure": "PostToolUseFailure",
+	"permission_request":    "PermissionRequest",
+	"user_prompt_submit":    "UserPromptSubmit",
+	"notification":          "Notification",
+	"stop":                  "Stop",
+	"subagent_start":        "SubagentStart",
+	"subagent_stop":         "SubagentStop",
+	"session_start":         "SessionStart",
+	"session_end":           "SessionEnd",
+	"teammate_idle":         "TeammateIdle",
+	"task_completed":        "TaskCompleted",
+	"pre_compact":           "PreCompact",
+}
+
+// matchAttrTypes returns the attribute type map for each entry in the
+// matches list.
+func matchAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"index":   types.Int64Type,
+		"matcher": types.StringType,
+		"hooks":   types.ListType{ElemType: types.ObjectType{AttrTypes: hookAttrTypes()}},
+	}
+}
+
+// hookAttrTypes returns the attribute type map for each hook action.
+func hookAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"type":    types.StringType,
+		"command": types.StringType,
+		"agent":   types.StringType,
+	}
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (d *HookMatchDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_hook_match"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (d *HookMatchDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Evaluates a `hooks.json` document against a sample event and tool name and returns the matcher entries that would fire. Use it to test hook matchers in Terraform before shipping a plugin.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"hooks_json": schema.StringAttribute{
+				MarkdownDescription: "Hooks configuration to evaluate, in `hooks.json` format. Both the wrapped form (`{\"hooks\": {...}}`) and the bare event map are accepted.",
+				Required:            true,
+			},
+			"event": schema.StringAttribute{
+				MarkdownDescription: "Hook event to evaluate, either as the `hooks.json` key (e.g. `PreToolUse`) or the `agentctx_plugin` block name (e.g. `pre_tool_use`).",
+				Required:            true,
+			},
+
+			// ---- Optional ----
+			"tool_name": schema.StringAttribute{
+				MarkdownDescription: "Sample tool name (or other match value such as a session start source) to test matchers against. When omitted, only matchers that match everything (omitted, empty, or `*`) fire.",
+				Optional:            true,
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the evaluation, formed from the event and tool name.",
+				Computed:            true,
+			},
+			"matched": schema.BoolAttribute{
+				MarkdownDescription: "Whether at least one matcher entry fires.",
+				Computed:            true,
+			},
+			"matches": schema.ListNestedAttribute{
+				MarkdownDescription: "Matcher entries that fire, in `hooks.json` order.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"index": schema.Int64Attribute{
+							MarkdownDescription: "Zero-based position of the matcher entry within the event.",
+							Computed:            true,
+						},
+						"matcher": schema.StringAttribute{
+							MarkdownDescription: "Matcher pattern of the entry (empty when the entry matches everything).",
+							Computed:            true,
+						},
+						"hooks": schema.ListNestedAttribute{
+							MarkdownDescription: "Hook actions of the entry, in declaration order.",
+							Computed:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"type": schema.StringAttribute{
+										MarkdownDescription: "Hook type: `command`, `prompt`, or `agent`.",
+										Computed:            true,
+									},
+									"command": schema.StringAttribute{
+										MarkdownDescription: "Shell command, prompt text, or agent description. Null for hooks that set `agent`.",
+										Computed:            true,
+									},
+									"agent": schema.StringAttribute{
+										MarkdownDescription: "Name of the plugin agent an `agent` hook runs. Null for hooks that set `command`.",
+										Computed:            true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (d *HookMatchDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config HookMatchDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	event, err := normalizeEvent(config.Event.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("event"), errcode.InvalidConfig.Summary("Invalid Hook Event"), err.Error())
+		return
+	}
+
+	hooks, err := parseHooks(config.HooksJSON.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("hooks_json"), errcode.InvalidJSON.Summary("Invalid Hooks JSON"), err.Error())
+		return
+	}
+
+	toolName := ""
+	hasTool := !config.ToolName.IsNull() && !config.ToolName.IsUnknown()
+	if hasTool {
+		toolName = config.ToolName.ValueString()
+	}
+
+	matches, err := matchHooks(hooks[event], toolName, hasTool)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("hooks_json"), errcode.InvalidConfig.Summary("Invalid Hook Matcher"), fmt.Sprintf("Event %s: %s", event, err))
+		return
+	}
+
+	matchValues := make([]MatchValue, 0, len(matches))
+	for _, m := range matches {
+		hookValues := make([]HookValue, 0, len(m.entry.Hooks))
+		for _, h := range m.entry.Hooks {
+			hookValues = append(hookValues, h.value())
+		}
+		hookList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: hookAttrTypes()}, hookValues)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		matchValues = append(matchValues, MatchValue{
+			Index:   types.Int64Value(int64(m.index)),
+			Matcher: types.StringValue(m.entry.Matcher),
+			Hooks:   hookList,
+		})
+	}
+
+	matchList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: matchAttrTypes()}, matchValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config.ID = types.StringValue(event + ":" + toolName)
+	config.Matched = types.BoolValue(len(matches) > 0)
+	config.Matches = matchList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
+
+// --------------------------------------------------------------------------
+// Matching
+// --------------------------------------------------------------------------
+
+// hookEntry is a single hook action in hooks.json. Agent hooks that run a
+// plugin agent set agent instead of command.
+type hookEntry struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	Agent   string `json:"agent"`
+}
+
+// value returns the hook as a matches.hooks element, with command and
+// agent null when the hook does not set them.
+func (h hookEntry) value() HookValue {
+	v := HookValue{
+		Type:    types.StringValue(h.Type),
+		Command: types.StringValue(h.Command),
+		Agent:   types.StringNull(),
+	}
+	if h.Agent != "" {
+		v.Agent = types.StringValue(h.Agent)
+		if h.Command == "" {
+			v.Command = types.StringNull()
+		}
+	}
+	return v
+}
+
+// matcherEntry is a single matcher entry for an event in hooks.json.
+type matcherEntry struct {
+	Matcher string      `json:"matcher"`
+	Hooks   []hookEntry `json:"hooks"`
+}
+
+// hookMatch is a matcher entry that fired, with its position in the event.
+type hookMatch struct {
+	index int
+	entry matcherEntry
+}
+
+// normalizeEvent converts an event name in either hooks.json or block form
+// to the hooks.json key.
+func normalizeEvent(name string) (string, error) {
+	if event, ok := hookEvents[name]; ok {
+		return event, nil
+	}
+	for _, event := range hookEvents {
+		if event == name {
+			return event, nil
+		}
+	}
+
+	valid := make([]string, 0, len(hookEvents))
+	for _, event := range hookEvents {
+		valid = append(valid, event)
+	}
+	sort.Strings(valid)
+	return "", fmt.Errorf("unknown hook event %q; expected one of: %s", name, strings.Join(valid, ", "))
+}
+
+// parseHooks decodes a hooks.json document. It accepts the wrapped form
+// written by agentctx_plugin ({"hooks": {...}}) as well as a bare event map.
+func parseHooks(data string) (map[string][]matcherEntry, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks JSON: %w", err)
+	}
+
+	if inner, ok := raw["hooks"]; ok && len(raw) == 1 {
+		raw = nil
+		if err := json.Unmarshal(inner, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse \"hooks\" object: %w", err)
+		}
+	}
+
+	result := make(map[string][]matcherEntry, len(raw))
+	for event, msg := range raw {
+		var entries []matcherEntry
+		if err := json.Unmarshal(msg, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse entries for event %q: %w", event, err)
+		}
+		result[event] = entries
+	}
+	return result, nil
+}
+
+// matchHooks returns the entries whose matcher fires for toolName. A matcher
+// that is empty or "*" matches everything; any other matcher is treated as a
+// regular expression that must match the whole tool name, so "Write" does
+// not fire for "WriteFile". When hasTool is false only match-everything
+// entries fire.
+func matchHooks(entries []matcherEntry, toolName string, hasTool bool) ([]hookMatch, error) {
+	var matches []hookMatch
+	for i, e := range entries {
+		if e.Matcher == "" || e.Matcher == "*" {
+			matches = append(matches, hookMatch{index: i, entry: e})
+			continue
+		}
+
+		re, err := regexp.Compile("^(?:" + e.Matcher + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("matcher %d (%q) is not a valid regular expression: %s", i, e.Matcher, err)
+		}
+		if hasTool && re.MatchString(toolName) {
+			matches = append(matches, hookMatch{index: i, entry: e})
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,33 @@
+package hookmatch
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// HookMatchDataSourceModel maps the agentctx_hook_match data source schema to
+// a Go struct.
+type HookMatchDataSourceModel struct {
+	// Required
+	HooksJSON types.String `tfsdk:"hooks_json"`
+	Event     types.String `tfsdk:"event"`
+
+	// Optional
+	ToolName types.String `tfsdk:"tool_name"`
+
+	// Computed
+	ID      types.String `tfsdk:"id"`
+	Matched types.Bool   `tfsdk:"matched"`
+	Matches types.List   `tfsdk:"matches"`
+}
+
+// MatchValue represents a single entry in the computed matches list.
+type MatchValue struct {
+	Index   types.Int64  `tfsdk:"index"`
+	Matcher types.String `tfsdk:"matcher"`
+	Hooks   types.List   `tfsdk:"hooks"`
+}
+
+// HookValue represents a single hook action within a MatchValue.
+type HookValue struct {
+	Type    types.String `tfsdk:"type"`
+	Command types.String `tfsdk:"command"`
+	Agent   types.String `tfsdk:"agent"`
+}
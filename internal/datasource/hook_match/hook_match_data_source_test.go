@@ -0,0 +1,170 @@
+package hookmatch
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleHooks = `{
+  "hooks": {
+    "PostToolUse": [
+      {
+        "matcher": "Write|Edit",
+        "hooks": [
+          {"type": "command", "command": "format.sh"},
+          {"type": "command", "command": "lint.sh"}
+        ]
+      },
+      {
+        "matcher": "Bash",
+        "hooks": [{"type": "command", "command": "audit.sh"}]
+      },
+      {
+        "hooks": [{"type": "command", "command": "log.sh"}]
+      }
+    ],
+    "PreToolUse": [
+      {
+        "matcher": "^(Write|Edit)$",
+        "hooks": [{"type": "agent", "agent": "code-reviewer"}]
+      }
+    ],
+    "Stop": [
+      {
+        "matcher": "*",
+        "hooks": [{"type": "prompt", "command": "Summarize the session."}]
+      }
+    ]
+  }
+}`
+
+func TestNormalizeEvent(t *testing.T) {
+	tests := map[string]string{
+		"PreToolUse":            "PreToolUse",
+		"pre_tool_use":          "PreToolUse",
+		"post_tool_use_failure": "PostToolUseFailure",
+		"SessionStart":          "SessionStart",
+	}
+	for in, want := range tests {
+		got, err := normalizeEvent(in)
+		if err != nil {
+			t.Errorf("normalizeEvent(%q): unexpected error: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("normalizeEvent(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if _, err := normalizeEvent("BeforeToolUse"); err == nil {
+		t.Error("expected error for unknown event")
+	} else if !strings.Contains(err.Error(), "PreToolUse") {
+		t.Errorf("expected error to list valid events, got %v", err)
+	}
+}
+
+func TestParseHooks_WrappedAndBare(t *testing.T) {
+	wrapped, err := parseHooks(sampleHooks)
+	if err != nil {
+		t.Fatalf("parseHooks(wrapped): %v", err)
+	}
+	if len(wrapped["PostToolUse"]) != 3 {
+		t.Errorf("expected 3 PostToolUse entries, got %d", len(wrapped["PostToolUse"]))
+	}
+
+	bare, err := parseHooks(`{"Stop": [{"hooks": [{"type": "command", "command": "x"}]}]}`)
+	if err != nil {
+		t.Fatalf("parseHooks(bare): %v", err)
+	}
+	if len(bare["Stop"]) != 1 {
+		t.Errorf("expected 1 Stop entry, got %d", len(bare["Stop"]))
+	}
+
+	if _, err := parseHooks(`{"hooks": `); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+	if _, err := parseHooks(`{"Stop": {"matcher": "x"}}`); err == nil {
+		t.Error("expected error when event entries are not a list")
+	}
+}
+
+func TestMatchHooks(t *testing.T) {
+	hooks, err := parseHooks(sampleHooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		hasTool bool
+		want    []int
+	}{
+		{"alternation matches Write", "Write", true, []int{0, 2}},
+		{"alternation matches Edit", "Edit", true, []int{0, 2}},
+		{"anchored, no partial match", "WriteFile", true, []int{2}},
+		{"case sensitive", "bash", true, []int{2}},
+		{"exact Bash", "Bash", true, []int{1, 2}},
+		{"no tool only wildcard", "", false, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := matchHooks(hooks["PostToolUse"], tt.tool, tt.hasTool)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []int
+			for _, m := range matches {
+				got = append(got, m.index)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("matched indexes = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("matched indexes = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// Star matchers fire regardless of tool.
+	matches, err := matchHooks(hooks["Stop"], "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].entry.Hooks[0].Type != "prompt" {
+		t.Errorf("expected the Stop wildcard entry to fire, got %+v", matches)
+	}
+}
+
+func TestMatchHooks_AgentHook(t *testing.T) {
+	hooks, err := parseHooks(sampleHooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := matchHooks(hooks["PreToolUse"], "Edit", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || len(matches[0].entry.Hooks) != 1 {
+		t.Fatalf("matches = %+v, want the agent hook entry", matches)
+	}
+	v := matches[0].entry.Hooks[0].value()
+	if v.Type.ValueString() != "agent" || v.Agent.ValueString() != "code-reviewer" || !v.Command.IsNull() {
+		t.Errorf("hook = %+v, want agent code-reviewer with a null command", v)
+	}
+
+	v = hooks["Stop"][0].Hooks[0].value()
+	if v.Command.ValueString() != "Summarize the session." || !v.Agent.IsNull() {
+		t.Errorf("hook = %+v, want the prompt with a null agent", v)
+	}
+}
+
+func TestMatchHooks_InvalidRegex(t *testing.T) {
+	entries := []matcherEntry{{Matcher: "Write|(Edit", Hooks: []hookEntry{{Type: "command", Command: "x"}}}}
+	if _, err := matchHooks(entries, "Write", true); err == nil {
+		t.Error("expected error for invalid matcher regex")
+	}
+}
@@ -0,0 +1,470 @@
+package renderpluginmanifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/plugin"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
+
+// Compile-time interface checks.
+var (
+	_ datasource.DataSource              = &RenderPluginManifestDataSource{}
+	_ datasource.DataSourceWithConfigure = &RenderPluginManifestDataSource{}
+)
+
+// NewRenderPluginManifestDataSource returns a new datasource.DataSource for
+// the agentctx_render_plugin_manifest type.
+func NewRenderPluginManifestDataSource() datasource.DataSource {
+	return &RenderPluginManifestDataSource{}
+}
+
+// RenderPluginManifestDataSource implements the
+// agentctx_render_plugin_manifest data source. It renders the plugin.json
+// manifest and the hooks, MCP, and LSP configuration exactly as
+// agentctx_plugin writes them, without touching disk.
+type RenderPluginManifestDataSource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (d *RenderPluginManifestDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_render_plugin_manifest"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (d *RenderPluginManifestDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	componentNames := func(description string) schema.ListAttribute {
+		return schema.ListAttribute{
+			MarkdownDescription: description,
+			Optional:            true,
+			ElementType:         types.StringType,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(validators.Name()),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders the `plugin.json` manifest and the `hooks/hooks.json`, `.mcp.json`, and `.lsp.json` files of a Claude Code plugin from the same arguments as `agentctx_plugin`, without writing anything to disk. Use it to inspect the exact output or to test modules.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Unique identifier for the plugin (kebab-case).",
+				Required:            true,
+				Validators: []validator.String{
+					validators.Name(),
+				},
+			},
+
+			// ---- Optional metadata ----
+			"version": schema.StringAttribute{
+				MarkdownDescription: "Semantic version of the plugin (e.g. `1.0.0`).",
+				Optional:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Brief explanation of the plugin's purpose.",
+				Optional:            true,
+			},
+			"homepage": schema.StringAttribute{
+				MarkdownDescription: "URL to the plugin's documentation or homepage.",
+				Optional:            true,
+			},
+			"repository": schema.StringAttribute{
+				MarkdownDescription: "URL to the plugin's source code repository.",
+				Optional:            true,
+			},
+			"license": schema.StringAttribute{
+				MarkdownDescription: "License identifier (e.g. `MIT`, `Apache-2.0`).",
+				Optional:            true,
+			},
+			"keywords": schema.ListAttribute{
+				MarkdownDescription: "Discovery tags for the plugin.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"requires_claude_version": schema.StringAttribute{
+				MarkdownDescription: "Version constraint on the Claude Code releases that can load the plugin (e.g. `>= 2.0.45`). Written to the manifest as `requiresClaudeVersion`.",
+				Optional:            true,
+			},
+			"compatibility_level": schema.StringAttribute{
+				MarkdownDescription: "Oldest Claude Code release, such as `2.0.30`, the rendered files must load in. Overrides the provider's `compatibility_level`. Hook events, manifest fields, and MCP servers that need a newer release are left out, with a warning.",
+				Optional:            true,
+			},
+			"json_format": schema.StringAttribute{
+				MarkdownDescription: "Formatting of the rendered JSON: `\"indented\"` (two spaces, the default) or `\"compact\"` (a single line).",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("indented", "compact"),
+				},
+			},
+			"json_schemas": schema.MapAttribute{
+				MarkdownDescription: "JSON Schema URLs to write as the `\"$schema\"` property of rendered files, keyed by the file's path in the plugin: `\".claude-plugin/plugin.json\"`, `\"hooks/hooks.json\"`, `\".mcp.json\"`, or `\".lsp.json\"`.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.OneOf(".claude-plugin/plugin.json", "hooks/hooks.json", ".mcp.json", ".lsp.json")),
+					mapvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"skills":   componentNames("Names of the plugin's skills, listed in the manifest as `./skills/<name>/`."),
+			"agents":   componentNames("Names of the plugin's agents, listed in the manifest as `./agents/<name>.md`."),
+			"commands": componentNames("Names of the plugin's commands, listed in the manifest as `./commands/<name>.md`."),
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the rendering: the plugin name.",
+				Computed:            true,
+			},
+			"manifest_json": schema.StringAttribute{
+				MarkdownDescription: "The rendered `.claude-plugin/plugin.json`, identical to the manifest `agentctx_plugin` writes for the same arguments.",
+				Computed:            true,
+			},
+			"hooks_json": schema.StringAttribute{
+				MarkdownDescription: "The rendered `hooks/hooks.json`. Null when the plugin has no hooks.",
+				Computed:            true,
+			},
+			"mcp_json": schema.StringAttribute{
+				MarkdownDescription: "The rendered `.mcp.json`. Null when the plugin has no MCP servers.",
+				Computed:            true,
+			},
+			"lsp_json": schema.StringAttribute{
+				MarkdownDescription: "The rendered `.lsp.json`. Null when the plugin has no LSP servers.",
+				Computed:            true,
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of `manifest_json`, prefixed with `sha256:`. Matches the `content_hash` of an `agentctx_plugin` with the same arguments.",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"author": schema.ListNestedBlock{
+				MarkdownDescription: "Author information for the plugin. At most one block may be specified.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Author name.",
+							Required:            true,
+						},
+						"email": schema.StringAttribute{
+							MarkdownDescription: "Author email address.",
+							Optional:            true,
+						},
+						"url": schema.StringAttribute{
+							MarkdownDescription: "Author URL (e.g. GitHub profile).",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"output_style": schema.ListNestedBlock{
+				MarkdownDescription: "Output style markdown files or directories to load, rendered to the manifest as `outputStyles` paths.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							MarkdownDescription: "Relative path to an output style markdown file or directory within the plugin.",
+							Required:            true,
+						},
+					},
+				},
+			},
+			"mcp_server": schema.ListNestedBlock{
+				MarkdownDescription: "MCP server definitions, rendered to `mcp_json`.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "MCP server name (used as key in `.mcp.json`).",
+							Required:            true,
+						},
+						"command": schema.StringAttribute{
+							MarkdownDescription: "Command to start the MCP server.",
+							Optional:            true,
+						},
+						"args": schema.ListAttribute{
+							MarkdownDescription: "Arguments for the MCP server command.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"env": schema.MapAttribute{
+							MarkdownDescription: "Environment variables for the MCP server process.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"url": schema.StringAttribute{
+							MarkdownDescription: "URL for a remote MCP server (SSE transport).",
+							Optional:            true,
+						},
+						"cwd": schema.StringAttribute{
+							MarkdownDescription: "Working directory for the MCP server process.",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"lsp_server": schema.ListNestedBlock{
+				MarkdownDescription: "LSP server configurations, rendered to `lsp_json`.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "LSP server name (used as key in `.lsp.json`).",
+							Required:            true,
+						},
+						"command": schema.StringAttribute{
+							MarkdownDescription: "The LSP binary to execute.",
+							Required:            true,
+						},
+						"args": schema.ListAttribute{
+							MarkdownDescription: "Command-line arguments for the LSP server.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"transport": schema.StringAttribute{
+							MarkdownDescription: "Communication transport: `stdio` (default) or `socket`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("stdio", "socket"),
+							},
+						},
+						"env": schema.MapAttribute{
+							MarkdownDescription: "Environment variables to set when starting the server.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"initialization_options": schema.MapAttribute{
+							MarkdownDescription: "Options passed to the server during initialization.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"settings": schema.MapAttribute{
+							MarkdownDescription: "Settings passed via `workspace/didChangeConfiguration`.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"extension_to_language": schema.MapAttribute{
+							MarkdownDescription: "Maps file extensions to language identifiers (e.g. `{\".go\" = \"go\"}`).",
+							Required:            true,
+							ElementType:         types.StringType,
+						},
+						"workspace_folder": schema.StringAttribute{
+							MarkdownDescription: "Workspace folder path for the server.",
+							Optional:            true,
+						},
+						"startup_timeout": schema.Int64Attribute{
+							MarkdownDescription: "Maximum time to wait for server startup in milliseconds.",
+							Optional:            true,
+						},
+						"shutdown_timeout": schema.Int64Attribute{
+							MarkdownDescription: "Maximum time to wait for graceful shutdown in milliseconds.",
+							Optional:            true,
+						},
+						"restart_on_crash": schema.BoolAttribute{
+							MarkdownDescription: "Whether to automatically restart the server if it crashes. Defaults to `false`.",
+							Optional:            true,
+						},
+						"max_restarts": schema.Int64Attribute{
+							MarkdownDescription: "Maximum number of restart attempts before giving up.",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"hooks": schema.ListNestedBlock{
+				MarkdownDescription: "Hook configurations, rendered to `hooks_json`. At most one block may be specified.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Blocks: map[string]schema.Block{
+						"pre_tool_use":          hookEventBlockSchema("Hooks that run before Claude uses a tool."),
+						"post_tool_use":         hookEventBlockSchema("Hooks that run after Claude successfully uses a tool."),
+						"post_tool_use_failure": hookEventBlockSchema("Hooks that run after a Claude tool execution fails."),
+						"permission_request":    hookEventBlockSchema("Hooks that run when a permission dialog is shown."),
+						"user_prompt_submit":    hookEventBlockSchema("Hooks that run when the user submits a prompt."),
+						"notification":          hookEventBlockSchema("Hooks that run when Claude Code sends notifications."),
+						"stop":                  hookEventBlockSchema("Hooks that run when Claude attempts to stop."),
+						"subagent_start":        hookEventBlockSchema("Hooks that run when a subagent is started."),
+						"subagent_stop":         hookEventBlockSchema("Hooks that run when a subagent attempts to stop."),
+						"session_start":         hookEventBlockSchema("Hooks that run at the beginning of sessions."),
+						"session_end":           hookEventBlockSchema("Hooks that run at the end of sessions."),
+						"teammate_idle":         hookEventBlockSchema("Hooks that run when an agent team teammate is about to go idle."),
+						"task_completed":        hookEventBlockSchema("Hooks that run when a task is being marked as completed."),
+						"pre_compact":           hookEventBlockSchema("Hooks that run before conversation history is compacted."),
+					},
+				},
+			},
+		},
+	}
+}
+
+// hookEventBlockSchema returns the schema for a hook event type block,
+// matching the agentctx_plugin one.
+func hookEventBlockSchema(description string) schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: description,
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"matcher": schema.StringAttribute{
+					MarkdownDescription: "Regex pattern to match tool names. If neither `matcher` nor `tools` is set, the hook matches all tools.",
+					Optional:            true,
+				},
+				"tools": schema.ListAttribute{
+					MarkdownDescription: "Tool names to match, such as `[\"Write\", \"Edit\"]`, written to `hooks.json` as an anchored regular expression matching exactly those tools (`^(Write|Edit)$`). Names are escaped, so they cannot be patterns. Conflicts with `matcher`.",
+					ElementType:         types.StringType,
+					Optional:            true,
+					Validators: []validator.List{
+						listvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("matcher")),
+						listvalidator.SizeAtLeast(1),
+						listvalidator.UniqueValues(),
+						listvalidator.ValueStringsAre(stringvalidator.RegexMatches(plugin.ToolNamePattern, "must be a tool name such as \"Write\" or \"mcp__github__create_issue\", not a pattern; use matcher for patterns")),
+					},
+				},
+				"order": schema.Int64Attribute{
+					MarkdownDescription: "Position of this matcher within the event in `hooks.json`. Matchers are written in ascending `order`; matchers without `order` are treated as `0`, and ties keep declaration order.",
+					Optional:            true,
+				},
+			},
+			Blocks: map[string]schema.Block{
+				"hook": schema.ListNestedBlock{
+					MarkdownDescription: "Hook actions to execute when the matcher matches.",
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"type": schema.StringAttribute{
+								MarkdownDescription: "Hook type: `command`, `prompt`, or `agent`.",
+								Required:            true,
+								Validators: []validator.String{
+									stringvalidator.OneOf("command", "prompt", "agent"),
+								},
+							},
+							"command": schema.StringAttribute{
+								MarkdownDescription: "Shell command to execute, prompt text, or agent description. Exactly one of `command` and `agent` must be set.",
+								Optional:            true,
+							},
+							"agent": schema.StringAttribute{
+								MarkdownDescription: "Name of one of the plugin's `agents` to run, for hooks of type `agent`, instead of a free-form description in `command`. Exactly one of `command` and `agent` must be set.",
+								Optional:            true,
+								Validators: []validator.String{
+									stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("command")),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (d *RenderPluginManifestDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Data Source Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (d *RenderPluginManifestDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config RenderPluginManifestDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(render(ctx, &config, d.providerData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
+
+// render fills the computed attributes of config from plugin.Render, using
+// the provider's compatibility_level when config does not set one. Agent
+// hooks must name one of agents, and URLs are checked against the
+// provider's allowed_url_schemes.
+func render(ctx context.Context, config *RenderPluginManifestDataSourceModel, pd *providerdata.ProviderData) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if level := config.CompatibilityLevel; !level.IsNull() && !level.IsUnknown() {
+		if _, err := claudeversion.ParseLevel(level.ValueString()); err != nil {
+			diags.AddAttributeError(
+				path.Root("compatibility_level"),
+				errcode.ClaudeVersion.Summary("Invalid Compatibility Level"),
+				fmt.Sprintf("compatibility_level %q is not a Claude Code version: %s", level.ValueString(), err),
+			)
+			return diags
+		}
+	}
+
+	model, d := config.resourceModel(ctx)
+	diags.Append(d...)
+	diags.Append(plugin.ValidateHookAgents(model)...)
+	diags.Append(plugin.ValidateURLs(model, pd.URLSchemes())...)
+	if diags.HasError() {
+		return diags
+	}
+
+	rendered, d := plugin.Render(ctx, model, pd.Compatibility(config.CompatibilityLevel))
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	config.ID = config.Name
+	config.ManifestJSON = types.StringValue(rendered.ManifestJSON)
+	config.HooksJSON = optionalString(rendered.HooksJSON)
+	config.McpJSON = optionalString(rendered.McpJSON)
+	config.LspJSON = optionalString(rendered.LspJSON)
+	config.ContentHash = types.StringValue(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(rendered.ManifestJSON))))
+	return diags
+}
+
+// optionalString returns s, or null for a file that is not generated.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
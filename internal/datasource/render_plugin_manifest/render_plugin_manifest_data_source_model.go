@@ -0,0 +1,107 @@
+package renderpluginmanifest
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/plugin"
+)
+
+// RenderPluginManifestDataSourceModel maps the agentctx_render_plugin_manifest
+// data source schema to a Go struct. Its arguments are those of
+// agentctx_plugin that affect the generated JSON files; components are
+// given by name only.
+type RenderPluginManifestDataSourceModel struct {
+	// Required
+	Name types.String `tfsdk:"name"`
+
+	// Optional – metadata
+	Version     types.String `tfsdk:"version"`
+	Description types.String `tfsdk:"description"`
+	Homepage    types.String `tfsdk:"homepage"`
+	Repository  types.String `tfsdk:"repository"`
+	License     types.String `tfsdk:"license"`
+	Keywords    types.List   `tfsdk:"keywords"`
+
+	// Optional – compatibility
+	RequiresClaudeVersion types.String `tfsdk:"requires_claude_version"`
+	CompatibilityLevel    types.String `tfsdk:"compatibility_level"`
+
+	// Optional – JSON output formatting
+	JSONFormat  types.String `tfsdk:"json_format"`
+	JSONSchemas types.Map    `tfsdk:"json_schemas"`
+
+	// Optional – component names
+	Skills   types.List `tfsdk:"skills"`
+	Agents   types.List `tfsdk:"agents"`
+	Commands types.List `tfsdk:"commands"`
+
+	// Optional – blocks
+	Author       []plugin.AuthorModel            `tfsdk:"author"`
+	OutputStyles []plugin.PluginOutputStyleModel `tfsdk:"output_style"`
+	McpServers   []plugin.PluginMcpModel         `tfsdk:"mcp_server"`
+	LspServers   []plugin.PluginLspModel         `tfsdk:"lsp_server"`
+	Hooks        []plugin.PluginHooksModel       `tfsdk:"hooks"`
+
+	// Computed
+	ID           types.String `tfsdk:"id"`
+	ManifestJSON types.String `tfsdk:"manifest_json"`
+	HooksJSON    types.String `tfsdk:"hooks_json"`
+	McpJSON      types.String `tfsdk:"mcp_json"`
+	LspJSON      types.String `tfsdk:"lsp_json"`
+	ContentHash  types.String `tfsdk:"content_hash"`
+}
+
+// resourceModel returns the agentctx_plugin model with the same arguments,
+// for plugin.Render. Components become blocks with only a name.
+func (m *RenderPluginManifestDataSourceModel) resourceModel(ctx context.Context) (*plugin.PluginResourceModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	model := &plugin.PluginResourceModel{
+		Name:                  m.Name,
+		Version:               m.Version,
+		Description:           m.Description,
+		Homepage:              m.Homepage,
+		Repository:            m.Repository,
+		License:               m.License,
+		Keywords:              m.Keywords,
+		RequiresClaudeVersion: m.RequiresClaudeVersion,
+		CompatibilityLevel:    m.CompatibilityLevel,
+		JSONFormat:            m.JSONFormat,
+		JSONSchemas:           m.JSONSchemas,
+		Author:                m.Author,
+		OutputStyles:          m.OutputStyles,
+		McpServers:            m.McpServers,
+		LspServers:            m.LspServers,
+		Hooks:                 m.Hooks,
+	}
+
+	skills, d := componentNames(ctx, m.Skills)
+	diags.Append(d...)
+	for _, name := range skills {
+		model.Skills = append(model.Skills, plugin.PluginSkillModel{Name: types.StringValue(name)})
+	}
+	agents, d := componentNames(ctx, m.Agents)
+	diags.Append(d...)
+	for _, name := range agents {
+		model.Agents = append(model.Agents, plugin.PluginAgentModel{Name: types.StringValue(name)})
+	}
+	commands, d := componentNames(ctx, m.Commands)
+	diags.Append(d...)
+	for _, name := range commands {
+		model.Commands = append(model.Commands, plugin.PluginCommandModel{Name: types.StringValue(name)})
+	}
+	return model, diags
+}
+
+// componentNames returns the elements of a component name list, or nil
+// when it is unset.
+func componentNames(ctx context.Context, list types.List) ([]string, diag.Diagnostics) {
+	var names []string
+	if list.IsNull() || list.IsUnknown() {
+		return nil, nil
+	}
+	diags := list.ElementsAs(ctx, &names, false)
+	return names, diags
+}
@@ -0,0 +1,191 @@
+package renderpluginmanifest
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/plugin"
+)
+
+func testConfig(t *testing.T) *RenderPluginManifestDataSourceModel {
+	t.Helper()
+	skills, diags := types.ListValueFrom(context.Background(), types.StringType, []string{"reports"})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return &RenderPluginManifestDataSourceModel{
+		Name:               types.StringValue("tools"),
+		Version:            types.StringValue("1.2.0"),
+		Keywords:           types.ListNull(types.StringType),
+		CompatibilityLevel: types.StringNull(),
+		JSONFormat:         types.StringNull(),
+		JSONSchemas:        types.MapNull(types.StringType),
+		Skills:             skills,
+		Agents:             types.ListNull(types.StringType),
+		Commands:           types.ListNull(types.StringType),
+		Hooks: []plugin.PluginHooksModel{{
+			PermissionRequest: []plugin.PluginHookMatcherModel{{
+				Matcher: types.StringValue("Bash"),
+				Hooks:   []plugin.PluginHookEntryModel{{Type: types.StringValue("command"), Command: types.StringValue("audit.sh")}},
+			}},
+		}},
+	}
+}
+
+func decode(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s, err)
+	}
+	return v
+}
+
+func TestRender(t *testing.T) {
+	config := testConfig(t)
+	if diags := render(context.Background(), config, nil); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	manifest := decode(t, config.ManifestJSON.ValueString())
+	if manifest["name"] != "tools" || manifest["version"] != "1.2.0" || manifest["hooks"] != "./hooks/hooks.json" {
+		t.Errorf("manifest = %v", manifest)
+	}
+	if skills, _ := manifest["skills"].([]interface{}); len(skills) != 1 || skills[0] != "./skills/reports/" {
+		t.Errorf("manifest skills = %v, want [./skills/reports/]", manifest["skills"])
+	}
+	if _, ok := decode(t, config.HooksJSON.ValueString())["hooks"].(map[string]interface{})["PermissionRequest"]; !ok {
+		t.Errorf("hooks_json = %s, want PermissionRequest", config.HooksJSON.ValueString())
+	}
+	if !config.McpJSON.IsNull() || !config.LspJSON.IsNull() {
+		t.Errorf("mcp_json = %v, lsp_json = %v, want null without servers", config.McpJSON, config.LspJSON)
+	}
+	if !strings.HasPrefix(config.ContentHash.ValueString(), "sha256:") {
+		t.Errorf("content_hash = %q, want a sha256: prefix", config.ContentHash.ValueString())
+	}
+}
+
+func TestRender_CompatibilityLevel(t *testing.T) {
+	level, err := claudeversion.ParseLevel("1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pd := &providerdata.ProviderData{CompatibilityLevel: level}
+
+	config := testConfig(t)
+	diags := render(context.Background(), config, pd)
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Fatalf("diagnostics = %v, want one warning", diags)
+	}
+	if !config.HooksJSON.IsNull() {
+		t.Errorf("hooks_json = %s, want null with its only event omitted", config.HooksJSON.ValueString())
+	}
+	if _, ok := decode(t, config.ManifestJSON.ValueString())["hooks"]; ok {
+		t.Error("manifest references hooks.json")
+	}
+
+	config = testConfig(t)
+	config.CompatibilityLevel = types.StringValue("not-a-version")
+	if diags := render(context.Background(), config, pd); !diags.HasError() {
+		t.Error("expected an error for an invalid compatibility_level")
+	}
+}
+
+// objectValue returns a value of typ with vals set and every other
+// attribute null.
+func objectValue(typ tftypes.Object, vals map[string]tftypes.Value) tftypes.Value {
+	attrs := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for name, attrType := range typ.AttributeTypes {
+		if v, ok := vals[name]; ok {
+			attrs[name] = v
+			continue
+		}
+		attrs[name] = tftypes.NewValue(attrType, nil)
+	}
+	return tftypes.NewValue(typ, attrs)
+}
+
+// elementType returns the element type of the list attribute name of typ.
+func elementType(typ tftypes.Object, name string) tftypes.Object {
+	return typ.AttributeTypes[name].(tftypes.List).ElementType.(tftypes.Object)
+}
+
+// hookConfig returns a configuration, decoded through the data source
+// schema, with agents and one pre_tool_use hook entry.
+func hookConfig(t *testing.T, agents []string, entry map[string]tftypes.Value) *RenderPluginManifestDataSourceModel {
+	t.Helper()
+	ctx := context.Background()
+
+	var resp datasource.SchemaResponse
+	NewRenderPluginManifestDataSource().Schema(ctx, datasource.SchemaRequest{}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	rootType := resp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	hooksType := elementType(rootType, "hooks")
+	matcherType := elementType(hooksType, "pre_tool_use")
+	entryType := elementType(matcherType, "hook")
+
+	agentValues := make([]tftypes.Value, 0, len(agents))
+	for _, name := range agents {
+		agentValues = append(agentValues, tftypes.NewValue(tftypes.String, name))
+	}
+	raw := objectValue(rootType, map[string]tftypes.Value{
+		"name":   tftypes.NewValue(tftypes.String, "tools"),
+		"agents": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, agentValues),
+		"hooks": tftypes.NewValue(rootType.AttributeTypes["hooks"], []tftypes.Value{
+			objectValue(hooksType, map[string]tftypes.Value{
+				"pre_tool_use": tftypes.NewValue(hooksType.AttributeTypes["pre_tool_use"], []tftypes.Value{
+					objectValue(matcherType, map[string]tftypes.Value{
+						"hook": tftypes.NewValue(matcherType.AttributeTypes["hook"], []tftypes.Value{
+							objectValue(entryType, entry),
+						}),
+					}),
+				}),
+			}),
+		}),
+	})
+
+	var config RenderPluginManifestDataSourceModel
+	c := tfsdk.Config{Schema: resp.Schema, Raw: raw}
+	if diags := c.Get(ctx, &config); diags.HasError() {
+		t.Fatalf("decoding the configuration: %v", diags)
+	}
+	return &config
+}
+
+func TestRender_AgentHook(t *testing.T) {
+	config := hookConfig(t, []string{"reviewer"}, map[string]tftypes.Value{
+		"type":  tftypes.NewValue(tftypes.String, "agent"),
+		"agent": tftypes.NewValue(tftypes.String, "reviewer"),
+	})
+	if diags := render(context.Background(), config, nil); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	event := decode(t, config.HooksJSON.ValueString())["hooks"].(map[string]interface{})["PreToolUse"].([]interface{})
+	hook := event[0].(map[string]interface{})["hooks"].([]interface{})[0].(map[string]interface{})
+	if hook["type"] != "agent" || hook["agent"] != "reviewer" {
+		t.Errorf("hook = %v, want an agent hook running reviewer", hook)
+	}
+	if _, ok := hook["command"]; ok {
+		t.Errorf("hook = %v, want no command", hook)
+	}
+
+	config = hookConfig(t, []string{"reviewer"}, map[string]tftypes.Value{
+		"type":  tftypes.NewValue(tftypes.String, "agent"),
+		"agent": tftypes.NewValue(tftypes.String, "auditor"),
+	})
+	diags := render(context.Background(), config, nil)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary(), "Unknown Hook Agent") {
+		t.Errorf("diagnostics = %v, want Unknown Hook Agent", diags)
+	}
+}
@@ -0,0 +1,300 @@
+package rendersubagent
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/subagent"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
+
+// Compile-time interface checks.
+var (
+	_ datasource.DataSource              = &RenderSubagentDataSource{}
+	_ datasource.DataSourceWithConfigure = &RenderSubagentDataSource{}
+)
+
+// NewRenderSubagentDataSource returns a new datasource.DataSource for the
+// agentctx_render_subagent type.
+func NewRenderSubagentDataSource() datasource.DataSource {
+	return &RenderSubagentDataSource{}
+}
+
+// RenderSubagentDataSource implements the agentctx_render_subagent data
+// source. It renders a sub-agent file exactly as agentctx_subagent writes
+// it, without touching disk.
+type RenderSubagentDataSource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (d *RenderSubagentDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_render_subagent"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (d *RenderSubagentDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders a Claude Code sub-agent file from the same arguments as `agentctx_subagent` and returns its content, without writing anything to disk. Use it to inspect the exact output or to test modules.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Unique identifier for the sub-agent. Must use lowercase letters, numbers, and hyphens (e.g. `code-reviewer`).",
+				Required:            true,
+				Validators: []validator.String{
+					validators.Name(),
+				},
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Describes when Claude should delegate to this sub-agent.",
+				Required:            true,
+			},
+			"prompt": schema.StringAttribute{
+				MarkdownDescription: "The system prompt for the sub-agent, rendered as the Markdown body after the YAML frontmatter.",
+				Required:            true,
+			},
+
+			// ---- Optional ----
+			"model": schema.StringAttribute{
+				MarkdownDescription: "Model the sub-agent uses. Valid values: `sonnet`, `opus`, `haiku`, `inherit`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("sonnet", "opus", "haiku", "inherit"),
+				},
+			},
+			"tools": schema.ListAttribute{
+				MarkdownDescription: "Tools the sub-agent can use.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"disallowed_tools": schema.ListAttribute{
+				MarkdownDescription: "Tools to deny, removed from the inherited or specified tool list.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"permission_mode": schema.StringAttribute{
+				MarkdownDescription: "Controls how the sub-agent handles permission prompts. Valid values: `default`, `acceptEdits`, `delegate`, `dontAsk`, `bypassPermissions`, `plan`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("default", "acceptEdits", "delegate", "dontAsk", "bypassPermissions", "plan"),
+				},
+			},
+			"max_turns": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of agentic turns before the sub-agent stops.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"skills": schema.ListAttribute{
+				MarkdownDescription: "Skills to preload into the sub-agent's context at startup.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"memory": schema.StringAttribute{
+				MarkdownDescription: "Persistent memory scope. Valid values: `user`, `project`, `local`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("user", "project", "local"),
+				},
+			},
+			"compatibility_level": schema.StringAttribute{
+				MarkdownDescription: "Oldest Claude Code release, such as `2.0.30`, the rendered file must load in. Overrides the provider's `compatibility_level`. Frontmatter fields and MCP servers that need a newer release are left out, with a warning.",
+				Optional:            true,
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the rendering: the sub-agent name.",
+				Computed:            true,
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "The rendered Markdown content (YAML frontmatter + prompt), identical to the file `agentctx_subagent` writes for the same arguments.",
+				Computed:            true,
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of `content`, prefixed with `sha256:`. Matches the `content_hash` of an `agentctx_subagent` with the same arguments.",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"mcp_server": schema.ListNestedBlock{
+				MarkdownDescription: "MCP servers available to this sub-agent. Each entry is either a server name referencing an already-configured server or an inline definition.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Server name. If only `name` is set, it references an already-configured MCP server.",
+							Required:            true,
+						},
+						"command": schema.StringAttribute{
+							MarkdownDescription: "Command to start the MCP server for inline definitions.",
+							Optional:            true,
+						},
+						"args": schema.ListAttribute{
+							MarkdownDescription: "Arguments for the MCP server command.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"env": schema.MapAttribute{
+							MarkdownDescription: "Environment variables for the MCP server process.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"url": schema.StringAttribute{
+							MarkdownDescription: "URL for a remote MCP server (SSE transport).",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"hooks": schema.ListNestedBlock{
+				MarkdownDescription: "Lifecycle hooks scoped to this sub-agent. At most one block may be specified.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Blocks: map[string]schema.Block{
+						"pre_tool_use":  hookMatcherBlockSchema("Hook matchers that run before the sub-agent uses a tool."),
+						"post_tool_use": hookMatcherBlockSchema("Hook matchers that run after the sub-agent uses a tool."),
+						"stop":          hookMatcherBlockSchema("Hook matchers that run when the sub-agent finishes."),
+					},
+				},
+			},
+		},
+	}
+}
+
+// hookMatcherBlockSchema returns the schema for a hook event type block,
+// matching the agentctx_subagent one.
+func hookMatcherBlockSchema(description string) schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: description,
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"matcher": schema.StringAttribute{
+					MarkdownDescription: "Regex pattern to match tool names. If omitted, the hook matches all tools.",
+					Optional:            true,
+				},
+			},
+			Blocks: map[string]schema.Block{
+				"hook": schema.ListNestedBlock{
+					MarkdownDescription: "Hook commands to execute when the matcher matches.",
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"type": schema.StringAttribute{
+								MarkdownDescription: "Hook type. Currently only `command` is supported.",
+								Required:            true,
+								Validators: []validator.String{
+									stringvalidator.OneOf("command"),
+								},
+							},
+							"command": schema.StringAttribute{
+								MarkdownDescription: "Shell command to execute.",
+								Required:            true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (d *RenderSubagentDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Data Source Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (d *RenderSubagentDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config RenderSubagentDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(render(ctx, &config, d.providerData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
+
+// render fills the computed attributes of config from subagent.Render,
+// using the provider's compatibility_level when config does not set one.
+// MCP server URLs are checked against the provider's allowed_url_schemes.
+func render(ctx context.Context, config *RenderSubagentDataSourceModel, pd *providerdata.ProviderData) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	model := config.resourceModel()
+	if level := config.CompatibilityLevel; !level.IsNull() && !level.IsUnknown() {
+		if _, err := claudeversion.ParseLevel(level.ValueString()); err != nil {
+			diags.AddAttributeError(
+				path.Root("compatibility_level"),
+				errcode.ClaudeVersion.Summary("Invalid Compatibility Level"),
+				fmt.Sprintf("compatibility_level %q is not a Claude Code version: %s", level.ValueString(), err),
+			)
+			return diags
+		}
+	} else if level := pd.Compatibility(types.StringNull()); level != nil {
+		model.CompatibilityLevel = types.StringValue(level.String())
+	}
+
+	diags.Append(subagent.ValidateURLs(model.McpServers, pd.URLSchemes())...)
+	if diags.HasError() {
+		return diags
+	}
+
+	content, d := subagent.Render(ctx, model)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	config.ID = config.Name
+	config.Content = types.StringValue(content)
+	config.ContentHash = types.StringValue(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content))))
+	return diags
+}
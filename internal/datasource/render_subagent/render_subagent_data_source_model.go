@@ -0,0 +1,56 @@
+package rendersubagent
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/subagent"
+)
+
+// RenderSubagentDataSourceModel maps the agentctx_render_subagent data
+// source schema to a Go struct. Its arguments are those of agentctx_subagent
+// that affect the rendered file.
+type RenderSubagentDataSourceModel struct {
+	// Required
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Prompt      types.String `tfsdk:"prompt"`
+
+	// Optional
+	Model              types.String `tfsdk:"model"`
+	Tools              types.List   `tfsdk:"tools"`
+	DisallowedTools    types.List   `tfsdk:"disallowed_tools"`
+	PermissionMode     types.String `tfsdk:"permission_mode"`
+	MaxTurns           types.Int64  `tfsdk:"max_turns"`
+	Skills             types.List   `tfsdk:"skills"`
+	Memory             types.String `tfsdk:"memory"`
+	CompatibilityLevel types.String `tfsdk:"compatibility_level"`
+
+	// Optional – blocks
+	McpServers []subagent.McpServerModel `tfsdk:"mcp_server"`
+	Hooks      []subagent.HooksModel     `tfsdk:"hooks"`
+
+	// Computed
+	ID          types.String `tfsdk:"id"`
+	Content     types.String `tfsdk:"content"`
+	ContentHash types.String `tfsdk:"content_hash"`
+}
+
+// resourceModel returns the agentctx_subagent model with the same
+// arguments, for subagent.Render.
+func (m *RenderSubagentDataSourceModel) resourceModel() *subagent.SubagentResourceModel {
+	return &subagent.SubagentResourceModel{
+		Name:               m.Name,
+		Description:        m.Description,
+		Prompt:             m.Prompt,
+		Model:              m.Model,
+		Tools:              m.Tools,
+		DisallowedTools:    m.DisallowedTools,
+		PermissionMode:     m.PermissionMode,
+		MaxTurns:           m.MaxTurns,
+		Skills:             m.Skills,
+		Memory:             m.Memory,
+		CompatibilityLevel: m.CompatibilityLevel,
+		McpServers:         m.McpServers,
+		Hooks:              m.Hooks,
+	}
+}
@@ -0,0 +1,85 @@
+package rendersubagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/subagent"
+)
+
+func testConfig() *RenderSubagentDataSourceModel {
+	return &RenderSubagentDataSourceModel{
+		Name:               types.StringValue("reviewer"),
+		Description:        types.StringValue("Reviews code"),
+		Prompt:             types.StringValue("Review the diff."),
+		Model:              types.StringValue("sonnet"),
+		Tools:              types.ListNull(types.StringType),
+		DisallowedTools:    types.ListNull(types.StringType),
+		Skills:             types.ListNull(types.StringType),
+		CompatibilityLevel: types.StringNull(),
+		Hooks: []subagent.HooksModel{{
+			Stop: []subagent.HookMatcherModel{{
+				Matcher: types.StringNull(),
+				Hooks:   []subagent.HookEntryModel{{Type: types.StringValue("command"), Command: types.StringValue("./done.sh")}},
+			}},
+		}},
+	}
+}
+
+func TestRender_MatchesSubagentResource(t *testing.T) {
+	config := testConfig()
+	if diags := render(context.Background(), config, nil); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	want, diags := subagent.Render(context.Background(), config.resourceModel())
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if config.Content.ValueString() != want {
+		t.Errorf("content = %q, want %q", config.Content.ValueString(), want)
+	}
+	if !strings.HasPrefix(config.ContentHash.ValueString(), "sha256:") {
+		t.Errorf("content_hash = %q, want a sha256: prefix", config.ContentHash.ValueString())
+	}
+	if config.ID.ValueString() != "reviewer" {
+		t.Errorf("id = %q, want the name", config.ID.ValueString())
+	}
+}
+
+func TestRender_CompatibilityLevel(t *testing.T) {
+	level, err := claudeversion.ParseLevel("1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pd := &providerdata.ProviderData{CompatibilityLevel: level}
+
+	config := testConfig()
+	diags := render(context.Background(), config, pd)
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Fatalf("diagnostics = %v, want one warning", diags)
+	}
+	if strings.Contains(config.Content.ValueString(), "hooks:") {
+		t.Error("hooks rendered below the provider's compatibility_level")
+	}
+
+	// The data source's own level overrides the provider's.
+	config = testConfig()
+	config.CompatibilityLevel = types.StringValue("2.1.0")
+	if diags := render(context.Background(), config, pd); diags.HasError() || diags.WarningsCount() != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if !strings.Contains(config.Content.ValueString(), "hooks:") {
+		t.Error("hooks omitted despite the data source's newer compatibility_level")
+	}
+
+	config.CompatibilityLevel = types.StringValue("latest")
+	if diags := render(context.Background(), config, pd); !diags.HasError() {
+		t.Error("expected an error for an invalid compatibility_level")
+	}
+}
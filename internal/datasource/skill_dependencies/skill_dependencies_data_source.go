@@ -0,0 +1,326 @@
+package skilldependencies
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
+
+// Compile-time interface checks.
+var (
+	_ datasource.DataSource              = &SkillDependenciesDataSource{}
+	_ datasource.DataSourceWithConfigure = &SkillDependenciesDataSource{}
+)
+
+// NewSkillDependenciesDataSource returns a new datasource.DataSource for the
+// agentctx_skill_dependencies type.
+func NewSkillDependenciesDataSource() datasource.DataSource {
+	return &SkillDependenciesDataSource{}
+}
+
+// SkillDependenciesDataSource implements the agentctx_skill_dependencies
+// data source. It reads the depends_on_skills recorded in the manifests of
+// the skills deployed on a target and reports the dependencies that are not
+// deployed there.
+type SkillDependenciesDataSource struct {
+	providerData *providerdata.ProviderData
+}
+
+// dependencyAttrTypes returns the attribute type map for each entry in the
+// dependencies list.
+func dependencyAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"skill_name":        types.StringType,
+		"deployment_id":     types.StringType,
+		"deployment_alias":  types.StringType,
+		"depends_on_skills": types.ListType{ElemType: types.StringType},
+	}
+}
+
+// danglingAttrTypes returns the attribute type map for each entry in the
+// dangling list.
+func danglingAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"skill_name": types.StringType,
+		"depends_on": types.StringType,
+	}
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (d *SkillDependenciesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_skill_dependencies"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (d *SkillDependenciesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads the `depends_on_skills` recorded in the manifests of the skills deployed on a target and reports the dependencies that are not deployed on it. " +
+			"Use it in a `postcondition` or a `check` block to catch a skill that was deployed without the skills it expects alongside it.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"target": schema.StringAttribute{
+				MarkdownDescription: "Name of the provider target to read. Replica targets are allowed.",
+				Required:            true,
+			},
+
+			// ---- Optional ----
+			"skills": schema.ListAttribute{
+				MarkdownDescription: "Names of the skills whose dependencies to check. When omitted, every skill deployed on the target is checked; previews are not.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(stringvalidator.RegexMatches(validators.NamePattern, "must contain only lowercase letters, numbers, and hyphens, and must start and end with a letter or number")),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Name of the target.",
+				Computed:            true,
+			},
+			"dependencies": schema.ListNestedAttribute{
+				MarkdownDescription: "The checked skills, sorted by name, with the dependencies of the deployments their ACTIVE marker points at.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"skill_name": schema.StringAttribute{
+							MarkdownDescription: "Name of the skill.",
+							Computed:            true,
+						},
+						"deployment_id": schema.StringAttribute{
+							MarkdownDescription: "Deployment the ACTIVE marker points at; the canary while one rolls out. Empty when the skill is not deployed.",
+							Computed:            true,
+						},
+						"deployment_alias": schema.StringAttribute{
+							MarkdownDescription: "The `deployment_alias` recorded for that deployment, such as a release tag. Empty when it has none.",
+							Computed:            true,
+						},
+						"depends_on_skills": schema.ListAttribute{
+							MarkdownDescription: "Skill names and Anthropic skill IDs the skill depends on, sorted. While a canary rolls out, the dependencies of both deployments.",
+							Computed:            true,
+							ElementType:         types.StringType,
+						},
+					},
+				},
+			},
+			"dangling": schema.ListNestedAttribute{
+				MarkdownDescription: "Dependencies on skill names that have no ACTIVE deployment on the target, sorted. Anthropic skill IDs are not checked.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"skill_name": schema.StringAttribute{
+							MarkdownDescription: "Skill that declares the dependency.",
+							Computed:            true,
+						},
+						"depends_on": schema.StringAttribute{
+							MarkdownDescription: "Skill it depends on that is not deployed.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"complete": schema.BoolAttribute{
+				MarkdownDescription: "Whether `dangling` is empty.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (d *SkillDependenciesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Data Source Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (d *SkillDependenciesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config SkillDependenciesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if d.providerData == nil {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Provider Not Configured"),
+			"agentctx_skill_dependencies requires a configured provider.",
+		)
+		return
+	}
+	tName := config.Target.ValueString()
+	tgt, ok := d.providerData.Targets.Get(tName)
+	if !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("target"),
+			errcode.UnknownTarget.Summary("Target Not Found"),
+			fmt.Sprintf("Target %q is not defined in the provider.", tName),
+		)
+		return
+	}
+
+	var names []string
+	if !config.Skills.IsNull() && !config.Skills.IsUnknown() {
+		resp.Diagnostics.Append(config.Skills.ElementsAs(ctx, &names, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	eng := engine.New(d.providerData.Scheduler, engine.WithListeners(d.providerData.Listeners...))
+	skills, dangling, err := checkDependencies(ctx, eng, tgt, names)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			errcode.TargetUnreachable.Summary("Dependency Check Failed"),
+			fmt.Sprintf("Failed to read skill dependencies from target %q: %s", tName, err),
+		)
+		return
+	}
+
+	depValues := make([]DependencyValue, 0, len(skills))
+	for _, s := range skills {
+		dependsOn, diags := types.ListValueFrom(ctx, types.StringType, append([]string{}, s.DependsOn...))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		depValues = append(depValues, DependencyValue{
+			SkillName:       types.StringValue(s.SkillName),
+			DeploymentID:    types.StringValue(s.DeploymentID),
+			DeploymentAlias: types.StringValue(s.DeploymentAlias),
+			DependsOnSkills: dependsOn,
+		})
+	}
+	depList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: dependencyAttrTypes()}, depValues)
+	resp.Diagnostics.Append(diags...)
+
+	danglingValues := make([]DanglingValue, 0, len(dangling))
+	for _, ref := range dangling {
+		danglingValues = append(danglingValues, DanglingValue{
+			SkillName: types.StringValue(ref.skillName),
+			DependsOn: types.StringValue(ref.dependsOn),
+		})
+	}
+	danglingList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: danglingAttrTypes()}, danglingValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config.ID = types.StringValue(tName)
+	config.Dependencies = depList
+	config.Dangling = danglingList
+	config.Complete = types.BoolValue(len(dangling) == 0)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
+
+// --------------------------------------------------------------------------
+// Checking
+// --------------------------------------------------------------------------
+
+// danglingRef is a dependency of skillName on a skill that is not deployed.
+type danglingRef struct {
+	skillName string
+	dependsOn string
+}
+
+// checkDependencies returns the dependencies of the skills names on tgt,
+// sorted by name, and the dependencies among them on skill names without an
+// ACTIVE deployment on tgt. Without names, every skill deployed on tgt
+// outside previews/ is checked.
+func checkDependencies(ctx context.Context, eng *engine.Engine, tgt target.Target, names []string) ([]engine.SkillDependencies, []danglingRef, error) {
+	listed := len(names) == 0
+	if listed {
+		all, err := eng.LayoutSkills(ctx, tgt)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range all {
+			if !strings.Contains(name, "/") {
+				names = append(names, name)
+			}
+		}
+	}
+
+	// deployed caches whether a skill has an ACTIVE deployment, for the
+	// skills checked and the skills they depend on.
+	deployed := make(map[string]bool)
+	var skills []engine.SkillDependencies
+	for _, name := range names {
+		deps, err := eng.Dependencies(ctx, tgt, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		deployed[name] = deps.DeploymentID != ""
+		if listed && !deployed[name] {
+			continue
+		}
+		skills = append(skills, deps)
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].SkillName < skills[j].SkillName })
+
+	var dangling []danglingRef
+	for _, s := range skills {
+		for _, ref := range s.DependsOn {
+			if validators.IsSkillID(ref) {
+				continue
+			}
+			ok, seen := deployed[ref]
+			if !seen {
+				deps, err := eng.Dependencies(ctx, tgt, ref)
+				if err != nil {
+					return nil, nil, err
+				}
+				ok = deps.DeploymentID != ""
+				deployed[ref] = ok
+			}
+			if !ok {
+				dangling = append(dangling, danglingRef{skillName: s.SkillName, dependsOn: ref})
+			}
+		}
+	}
+	return skills, dangling, nil
+}
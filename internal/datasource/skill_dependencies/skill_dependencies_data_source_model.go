@@ -0,0 +1,34 @@
+package skilldependencies
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// SkillDependenciesDataSourceModel maps the agentctx_skill_dependencies data
+// source schema to a Go struct.
+type SkillDependenciesDataSourceModel struct {
+	// Required
+	Target types.String `tfsdk:"target"`
+
+	// Optional
+	Skills types.List `tfsdk:"skills"` // list of strings
+
+	// Computed
+	ID           types.String `tfsdk:"id"`
+	Dependencies types.List   `tfsdk:"dependencies"`
+	Dangling     types.List   `tfsdk:"dangling"`
+	Complete     types.Bool   `tfsdk:"complete"`
+}
+
+// DependencyValue represents a single entry in the computed dependencies
+// list.
+type DependencyValue struct {
+	SkillName       types.String `tfsdk:"skill_name"`
+	DeploymentID    types.String `tfsdk:"deployment_id"`
+	DeploymentAlias types.String `tfsdk:"deployment_alias"`
+	DependsOnSkills types.List   `tfsdk:"depends_on_skills"` // list of strings
+}
+
+// DanglingValue represents a single entry in the computed dangling list.
+type DanglingValue struct {
+	SkillName types.String `tfsdk:"skill_name"`
+	DependsOn types.String `tfsdk:"depends_on"`
+}
@@ -0,0 +1,74 @@
+package skilldependencies
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// deploy deploys a one-file skill named name to tgt with the given
+// dependencies.
+func deploy(t *testing.T, eng *engine.Engine, tgt target.Target, name string, dependsOn ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("# "+name+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := bundle.ScanBundle(dir, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.Deploy(context.Background(), tgt, engine.DeployInput{
+		SkillName:       name,
+		Bundle:          b,
+		SourceDir:       dir,
+		DependsOnSkills: dependsOn,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	ctx := context.Background()
+	eng := engine.New(concurrency.NewUniform(4))
+	tgt := target.NewMemoryTarget("test")
+
+	deploy(t, eng, tgt, "style-guide")
+	deploy(t, eng, tgt, "code-review", "style-guide", "security-checklist", "skill_01abc")
+	deploy(t, eng, tgt, "release-notes", "code-review")
+	deploy(t, eng, tgt, engine.PreviewSkillName("42", "changelog"), "missing-in-preview")
+
+	skills, dangling, err := checkDependencies(ctx, eng, tgt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range skills {
+		names = append(names, s.SkillName)
+	}
+	if want := []string{"code-review", "release-notes", "style-guide"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("checked skills = %v, want %v", names, want)
+	}
+	if want := []danglingRef{{skillName: "code-review", dependsOn: "security-checklist"}}; !reflect.DeepEqual(dangling, want) {
+		t.Errorf("dangling = %+v, want %+v", dangling, want)
+	}
+
+	// Skills named explicitly are reported even when they are not deployed.
+	skills, dangling, err = checkDependencies(ctx, eng, tgt, []string{"release-notes", "security-checklist"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 2 || skills[1].SkillName != "security-checklist" || skills[1].DeploymentID != "" {
+		t.Errorf("skills = %+v, want release-notes and the undeployed security-checklist", skills)
+	}
+	if len(dangling) != 0 {
+		t.Errorf("dangling = %+v, want none", dangling)
+	}
+}
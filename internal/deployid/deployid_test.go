@@ -162,3 +162,23 @@ func TestIsValid(t *testing.T) {
 		})
 	}
 }
+
+func FuzzParse(f *testing.F) {
+	f.Add("dep_20260213T200102Z_6f2c9a1b")
+	f.Add("dep_")
+	f.Add("dep__")
+	f.Add("dep_20261399T999999Z_zzzzzzzz")
+
+	f.Fuzz(func(t *testing.T, id string) {
+		ts, err := Parse(id)
+		if err != nil {
+			if IsValid(id) {
+				t.Fatalf("IsValid(%q) = true but Parse failed: %v", id, err)
+			}
+			return
+		}
+		if ts.Location() != time.UTC {
+			t.Fatalf("Parse(%q) returned non-UTC time %v", id, ts)
+		}
+	})
+}
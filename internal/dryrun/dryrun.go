@@ -0,0 +1,185 @@
+// Package dryrun collects the changes resources would make while the
+// provider's dry_run is set, and writes them to a JSON report. Nothing in
+// the package writes anything but the report.
+package dryrun
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// FormatVersion is the format_version of the report. It changes only when
+// a field is removed or changes meaning.
+const FormatVersion = 1
+
+// Operations recorded in Change.Operation.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Actions recorded in FileChange.Action.
+const (
+	FileCreate    = "create"
+	FileModify    = "modify"
+	FileUnchanged = "unchanged"
+	FileDelete    = "delete"
+)
+
+// Actions recorded in RegistryChange.Action.
+const (
+	RegistryCreateSkill   = "create_skill"
+	RegistryUpdateSkill   = "update_skill"
+	RegistryCreateVersion = "create_version"
+	RegistryDeleteVersion = "delete_version"
+	RegistryDeleteSkill   = "delete_skill"
+)
+
+// Change is the simulated create, update, or delete of one resource.
+type Change struct {
+	// Resource is the resource type, e.g. agentctx_skill.
+	Resource  string `json:"resource"`
+	Operation string `json:"operation"`
+	// ID identifies the resource instance: its ID for updates and deletes,
+	// or the name or path it would get for creates.
+	ID string `json:"id"`
+	// Files are the local files the operation would write or remove.
+	Files []FileChange `json:"files,omitempty"`
+	// Targets are the storage target changes of agentctx_skill.
+	Targets []TargetChange `json:"targets,omitempty"`
+	// Registry lists the Anthropic registry requests that would be sent.
+	Registry []RegistryChange `json:"registry,omitempty"`
+}
+
+// FileChange is a local file an operation would write or remove.
+type FileChange struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	// Hash is the SHA-256 of the content that would be written, as
+	// "sha256:<hex>". Empty for deletes.
+	Hash string `json:"hash,omitempty"`
+}
+
+// TargetChange is what a deploy or destroy would do on one storage target.
+// For deploys, the file lists compare the bundle with the manifest of the
+// deployment ACTIVE points to.
+type TargetChange struct {
+	Target             string `json:"target"`
+	ActiveDeploymentID string `json:"active_deployment_id,omitempty"`
+	BundleHash         string `json:"bundle_hash,omitempty"`
+	// Added, Modified, Removed, and Unchanged are bundle-relative paths.
+	Added     []string `json:"added,omitempty"`
+	Modified  []string `json:"modified,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	Unchanged []string `json:"unchanged,omitempty"`
+	// Uploads and UploadBytes count the objects a new deployment writes:
+	// every bundle file, the manifest, and the ACTIVE pointer.
+	Uploads     int   `json:"uploads,omitempty"`
+	UploadBytes int64 `json:"upload_bytes,omitempty"`
+	// Deletes are the object keys a destroy would remove.
+	Deletes []string `json:"deletes,omitempty"`
+	// Rewrites are the object keys a layout migration would write.
+	Rewrites []string `json:"rewrites,omitempty"`
+	// Error is set when the target could not be read, so the change is
+	// incomplete.
+	Error string `json:"error,omitempty"`
+}
+
+// RegistryChange is an Anthropic registry request an operation would send.
+type RegistryChange struct {
+	Action  string `json:"action"`
+	SkillID string `json:"skill_id,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// report is the JSON document written by Report.
+type report struct {
+	FormatVersion   int      `json:"format_version"`
+	ProviderVersion string   `json:"provider_version"`
+	GeneratedAt     string   `json:"generated_at"`
+	Changes         []Change `json:"changes"`
+}
+
+// Report accumulates the changes of one provider process and rewrites the
+// report file after each. Terraform runs one provider process per command,
+// so the file holds every change of the last apply or destroy. A Report is
+// safe for concurrent use.
+type Report struct {
+	path            string
+	providerVersion string
+
+	mu      sync.Mutex
+	changes []Change
+}
+
+// New returns an empty report written to path.
+func New(path, providerVersion string) *Report {
+	return &Report{path: path, providerVersion: providerVersion}
+}
+
+// Path returns the file the report is written to.
+func (r *Report) Path() string {
+	return r.path
+}
+
+// Record adds change to the report and rewrites the report file.
+func (r *Report) Record(change Change) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = append(r.changes, change)
+	data, err := json.MarshalIndent(report{
+		FormatVersion:   FormatVersion,
+		ProviderVersion: r.providerVersion,
+		GeneratedAt:     time.Now().UTC().Format(time.RFC3339),
+		Changes:         r.changes,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode dry-run report: %w", err)
+	}
+	if err := atomicfile.WriteFile(longpath.Path(r.path), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write dry-run report: %w", err)
+	}
+	return nil
+}
+
+// WriteFile returns the change writing content to path would make: create
+// when path does not exist, unchanged when it already holds content, and
+// modify otherwise.
+func WriteFile(path string, content []byte) FileChange {
+	existing, err := os.ReadFile(longpath.Path(path))
+	return CompareFile(path, content, existing, err == nil)
+}
+
+// CompareFile is WriteFile for content stored elsewhere than the local
+// disk, such as an object on a storage target: existing is the current
+// content of path, and exists reports whether there is any.
+func CompareFile(path string, content, existing []byte, exists bool) FileChange {
+	change := FileChange{
+		Path:   path,
+		Action: FileCreate,
+		Hash:   fmt.Sprintf("sha256:%x", sha256.Sum256(content)),
+	}
+	switch {
+	case !exists:
+	case bytes.Equal(existing, content):
+		change.Action = FileUnchanged
+	default:
+		change.Action = FileModify
+	}
+	return change
+}
+
+// RemoveFile returns the change removing path would make.
+func RemoveFile(path string) FileChange {
+	return FileChange{Path: path, Action: FileDelete}
+}
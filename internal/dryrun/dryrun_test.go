@@ -0,0 +1,63 @@
+package dryrun
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "dry-run.json")
+	r := New(reportPath, "1.2.3")
+	for _, c := range []Change{
+		{Resource: "agentctx_subagent", Operation: OpCreate, ID: "reviewer"},
+		{Resource: "agentctx_skill", Operation: OpDelete, ID: "skills/reports"},
+	} {
+		if err := r.Record(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.FormatVersion != FormatVersion || got.ProviderVersion != "1.2.3" {
+		t.Errorf("format_version = %d, provider_version = %q", got.FormatVersion, got.ProviderVersion)
+	}
+	if len(got.Changes) != 2 || got.Changes[1].Resource != "agentctx_skill" {
+		t.Errorf("changes = %+v, want both recorded in order", got.Changes)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.md")
+	if err := os.WriteFile(existing, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		path, content, want string
+	}{
+		{filepath.Join(dir, "new.md"), "new", FileCreate},
+		{existing, "old", FileUnchanged},
+		{existing, "new", FileModify},
+	}
+	for _, c := range cases {
+		got := WriteFile(c.path, []byte(c.content))
+		if got.Action != c.want {
+			t.Errorf("WriteFile(%s, %q).Action = %q, want %q", filepath.Base(c.path), c.content, got.Action, c.want)
+		}
+	}
+	if got, want := WriteFile(existing, []byte("old")).Hash, fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("old"))); got != want {
+		t.Errorf("Hash = %q, want %q", got, want)
+	}
+}
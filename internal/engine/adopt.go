@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/deployid"
+	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// RejectedDeployment is an unmanaged deployment that AdoptableDeployments
+// would not adopt, and why.
+type RejectedDeployment struct {
+	DeploymentID string
+	Reason       string
+}
+
+// AdoptableDeployments returns the deployments of skillName on tgt that
+// are not in known, such as those written by other tools or by a state
+// that was lost, and whose manifests check out, sorted by ID. The
+// remaining unmanaged deployments are returned as rejected.
+//
+// A deployment is adoptable when its ID is a deployment ID, which Prune
+// needs to order it, its manifest parses and names the same deployment,
+// and every file the manifest lists is present. Deployments without a
+// manifest, typically partial uploads, are left to CleanupOrphans.
+func (e *Engine) AdoptableDeployments(ctx context.Context, tgt target.Target, skillName string, known []string) (adoptable []string, rejected []RejectedDeployment, err error) {
+	ids, err := deploymentIDs(ctx, tgt, skillName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("adoptable deployments: %w", err)
+	}
+	skip := make(map[string]struct{}, len(known))
+	for _, id := range known {
+		skip[id] = struct{}{}
+	}
+
+	for _, depID := range ids {
+		if _, ok := skip[depID]; ok {
+			continue
+		}
+		reason, err := e.checkAdoptable(ctx, tgt, skillName, depID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("adoptable deployments: %w", err)
+		}
+		if reason != "" {
+			rejected = append(rejected, RejectedDeployment{DeploymentID: depID, Reason: reason})
+			continue
+		}
+		adoptable = append(adoptable, depID)
+	}
+	return adoptable, rejected, nil
+}
+
+// checkAdoptable returns why deployment depID cannot be adopted, or "" if
+// it can. The error is set only when the target could not be read.
+func (e *Engine) checkAdoptable(ctx context.Context, tgt target.Target, skillName, depID string) (string, error) {
+	if _, err := deployid.Parse(depID); err != nil {
+		return "not a deployment ID", nil
+	}
+
+	rc, _, err := tgt.Get(ctx, deploymentPrefix(skillName, depID)+"manifest.json")
+	if errors.Is(err, target.ErrNotFound) {
+		return "no manifest", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read manifest of %q: %w", depID, err)
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return "", fmt.Errorf("read manifest of %q: %w", depID, err)
+	}
+	m, err := manifest.Unmarshal(data)
+	if err != nil {
+		return fmt.Sprintf("invalid manifest: %s", err), nil
+	}
+	if m.DeploymentID != "" && m.DeploymentID != depID {
+		return fmt.Sprintf("manifest names deployment %q", m.DeploymentID), nil
+	}
+
+	missing, err := e.checkFiles(ctx, tgt, skillName, depID, m)
+	if err != nil {
+		return "", err
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("%d of %d manifest files missing", len(missing), len(m.Files)), nil
+	}
+	return "", nil
+}
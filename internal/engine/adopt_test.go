@@ -0,0 +1,65 @@
+package engine_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestAdoptableDeployments(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n", "main.py": "pass\n"})
+	// Written by another tool, or by a state that was lost.
+	unmanaged := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	managed := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	put := func(key, body string) {
+		t.Helper()
+		if err := tgt.Put(ctx, key, strings.NewReader(body), target.PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prefix := "my-skill/.agentctx/deployments/"
+	// A partial upload without a manifest.
+	put(prefix+"dep_20200101T000000Z_00000001/files/SKILL.md", "# Skill\n")
+	// A manifest listing a file that is gone.
+	put(prefix+"dep_20200101T000000Z_00000002/manifest.json", `{"schema_version":2,"files":{"SKILL.md":"sha256:abc"}}`)
+	// A manifest copied from another deployment.
+	put(prefix+"dep_20200101T000000Z_00000003/manifest.json", `{"schema_version":2,"deployment_id":"dep_20200101T000000Z_00000004","files":{}}`)
+	// A prefix that is not a deployment ID.
+	put(prefix+"handwritten/manifest.json", `{"schema_version":2,"files":{}}`)
+
+	adoptable, rejected, err := eng.AdoptableDeployments(ctx, tgt, "my-skill", []string{managed.DeploymentID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(adoptable, []string{unmanaged.DeploymentID}) {
+		t.Errorf("adoptable = %v, want %v", adoptable, []string{unmanaged.DeploymentID})
+	}
+	reasons := make(map[string]string, len(rejected))
+	for _, rd := range rejected {
+		reasons[rd.DeploymentID] = rd.Reason
+	}
+	for depID, want := range map[string]string{
+		"dep_20200101T000000Z_00000001": "no manifest",
+		"dep_20200101T000000Z_00000002": "1 of 1 manifest files missing",
+		"dep_20200101T000000Z_00000003": `manifest names deployment "dep_20200101T000000Z_00000004"`,
+		"handwritten":                   "not a deployment ID",
+	} {
+		if reasons[depID] != want {
+			t.Errorf("%s: reason = %q, want %q", depID, reasons[depID], want)
+		}
+	}
+
+	// Once adopted, pruning manages the deployment.
+	pruned, err := eng.Prune(ctx, tgt, "my-skill", managed.DeploymentID, append(adoptable, managed.DeploymentID), 0)
+	if err != nil || !slices.Equal(pruned, []string{unmanaged.DeploymentID}) {
+		t.Errorf("Prune = %v, %v; want %v", pruned, err, []string{unmanaged.DeploymentID})
+	}
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// ErrNotYetVisible is matched by the error AwaitVisible returns when a
+// target still serves reads that predate a deploy once its read-after-write
+// period has elapsed.
+var ErrNotYetVisible = errors.New("write not yet visible")
+
+// AwaitVisible waits until reads from tgt, a target whose reads may trail
+// its writes such as an S3-compatible store with weaker consistency, show
+// deploymentID: its manifest exists and ACTIVE points at it. It checks
+// about ten times within timeout, and at least once.
+//
+// A target that still shows an older state when timeout elapses is
+// reported as an error wrapping ErrNotYetVisible that describes the last
+// check.
+func (e *Engine) AwaitVisible(ctx context.Context, tgt target.Target, skillName, deploymentID string, timeout time.Duration) error {
+	err := poll(ctx, timeout, func() error {
+		return e.checkServed(ctx, tgt, skillName, deploymentID, ErrNotYetVisible)
+	})
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return fmt.Errorf("engine: read after write on %s: %w", tgt.Name(), ctx.Err())
+	default:
+		return fmt.Errorf("engine: read after write on %s after %s: %w", tgt.Name(), timeout, err)
+	}
+}
+
+// RefreshSettled is Refresh for a target whose reads may trail its writes.
+// While the result does not show wantDeploymentID, the deployment the
+// caller last deployed or read, it refreshes again for up to timeout, so
+// that a deploy that is not visible yet is not mistaken for drift. It then
+// returns the last result, which may still differ. With a zero timeout or
+// an empty wantDeploymentID it is Refresh. Errors are returned at once.
+func (e *Engine) RefreshSettled(ctx context.Context, tgt target.Target, skillName, expectedBundleHash string, deepCheck bool, wantDeploymentID string, timeout time.Duration) (*RefreshResult, error) {
+	if timeout <= 0 || wantDeploymentID == "" {
+		return e.Refresh(ctx, tgt, skillName, expectedBundleHash, deepCheck)
+	}
+
+	var (
+		result     *RefreshResult
+		refreshErr error
+	)
+	_ = poll(ctx, timeout, func() error {
+		result, refreshErr = e.Refresh(ctx, tgt, skillName, expectedBundleHash, deepCheck)
+		if refreshErr != nil {
+			// Stop polling; the error is returned below.
+			return nil
+		}
+		if result.MissingManifest || result.ActiveDeploymentID != wantDeploymentID {
+			return ErrNotYetVisible
+		}
+		return nil
+	})
+	if refreshErr != nil {
+		return nil, refreshErr
+	}
+	if result == nil {
+		return nil, fmt.Errorf("refresh: %w", ctx.Err())
+	}
+	return result, nil
+}
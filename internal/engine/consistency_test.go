@@ -0,0 +1,178 @@
+package engine_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// laggingTarget serves reads of the keys written since the last catchUp as
+// they were before the first of those writes, like a store whose reads
+// trail its writes.
+type laggingTarget struct {
+	target.Target
+
+	mu    sync.Mutex
+	stale map[string]*staleObject // nil when the key did not exist
+}
+
+type staleObject struct {
+	body []byte
+	meta target.ObjectMeta
+}
+
+func newLaggingTarget(name string) *laggingTarget {
+	return &laggingTarget{Target: target.NewMemoryTarget(name), stale: map[string]*staleObject{}}
+}
+
+// catchUp makes every write visible.
+func (l *laggingTarget) catchUp() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.stale)
+}
+
+// snapshot records what key reads as before its first write since catchUp.
+func (l *laggingTarget) snapshot(ctx context.Context, key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.stale[key]; ok {
+		return
+	}
+	rc, meta, err := l.Target.Get(ctx, key)
+	if err != nil {
+		l.stale[key] = nil
+		return
+	}
+	defer rc.Close()
+	body, _ := io.ReadAll(rc)
+	l.stale[key] = &staleObject{body: body, meta: meta}
+}
+
+func (l *laggingTarget) read(key string) (obj *staleObject, lagging bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	obj, lagging = l.stale[key]
+	return obj, lagging
+}
+
+func (l *laggingTarget) Put(ctx context.Context, key string, body io.Reader, opts target.PutOptions) error {
+	l.snapshot(ctx, key)
+	return l.Target.Put(ctx, key, body, opts)
+}
+
+func (l *laggingTarget) ConditionalPut(ctx context.Context, key string, body io.Reader, condition target.WriteCondition, opts target.PutOptions) error {
+	l.snapshot(ctx, key)
+	return l.Target.ConditionalPut(ctx, key, body, condition, opts)
+}
+
+func (l *laggingTarget) Get(ctx context.Context, key string) (io.ReadCloser, target.ObjectMeta, error) {
+	if obj, lagging := l.read(key); lagging {
+		if obj == nil {
+			return nil, target.ObjectMeta{}, target.ErrNotFound
+		}
+		return io.NopCloser(bytes.NewReader(obj.body)), obj.meta, nil
+	}
+	return l.Target.Get(ctx, key)
+}
+
+func (l *laggingTarget) Head(ctx context.Context, key string) (target.ObjectMeta, error) {
+	if obj, lagging := l.read(key); lagging {
+		if obj == nil {
+			return target.ObjectMeta{}, target.ErrNotFound
+		}
+		return obj.meta, nil
+	}
+	return l.Target.Head(ctx, key)
+}
+
+func (l *laggingTarget) List(ctx context.Context, prefix string) ([]target.ObjectInfo, error) {
+	objects, err := l.Target.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	visible := objects[:0]
+	for _, obj := range objects {
+		if stale, lagging := l.read(obj.Key); !lagging || stale != nil {
+			visible = append(visible, obj)
+		}
+	}
+	return visible, nil
+}
+
+func TestDeploy_ReadAfterWrite(t *testing.T) {
+	eng := newTestEngine()
+	tgt := newLaggingTarget("store")
+
+	// Reads catch up within the read-after-write period: the deploy waits
+	// for them and records the metadata of the pointer it wrote.
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	input := defaultDeployInput(b1)
+	input.ReadAfterWrite = 5 * time.Second
+	time.AfterFunc(150*time.Millisecond, func() { tgt.catchUp() })
+	result1 := deployToTarget(t, eng, tgt, input)
+	if result1.ConsistencyErr != nil {
+		t.Fatalf("ConsistencyErr = %v", result1.ConsistencyErr)
+	}
+	meta, err := tgt.Target.Head(context.Background(), "my-skill/.agentctx/ACTIVE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result1.ActiveETag != meta.ETag {
+		t.Errorf("ActiveETag = %q, want %q", result1.ActiveETag, meta.ETag)
+	}
+
+	// Reads that still show the first deployment when the period elapses
+	// are reported, but do not fail the deploy.
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input = defaultDeployInput(b2)
+	input.PreviousDeployID = result1.DeploymentID
+	input.ActiveETag = result1.ActiveETag
+	input.ReadAfterWrite = 100 * time.Millisecond
+	result2 := deployToTarget(t, eng, tgt, input)
+	if !errors.Is(result2.ConsistencyErr, engine.ErrNotYetVisible) {
+		t.Fatalf("ConsistencyErr = %v, want ErrNotYetVisible", result2.ConsistencyErr)
+	}
+	if got := string(readObject(t, tgt.Target, "my-skill/.agentctx/ACTIVE")); got != result2.DeploymentID {
+		t.Errorf("ACTIVE = %q, want %q", got, result2.DeploymentID)
+	}
+}
+
+func TestRefreshSettled(t *testing.T) {
+	eng := newTestEngine()
+	ctx := context.Background()
+	tgt := newLaggingTarget("store")
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt.Target, defaultDeployInput(b1))
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input := defaultDeployInput(b2)
+	input.PreviousDeployID = result1.DeploymentID
+	result2 := deployToTarget(t, eng, tgt, input)
+
+	// Without a read-after-write period the stale read is returned as is.
+	refreshed, err := eng.RefreshSettled(ctx, tgt, "my-skill", b2.BundleHash, false, result2.DeploymentID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.ActiveDeploymentID != result1.DeploymentID {
+		t.Fatalf("ActiveDeploymentID = %q, want the stale %q", refreshed.ActiveDeploymentID, result1.DeploymentID)
+	}
+
+	time.AfterFunc(150*time.Millisecond, func() { tgt.catchUp() })
+	refreshed, err = eng.RefreshSettled(ctx, tgt, "my-skill", b2.BundleHash, false, result2.DeploymentID, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.ActiveDeploymentID != result2.DeploymentID || refreshed.Drifted {
+		t.Errorf("ActiveDeploymentID = %q, drifted = %v; want %q in sync", refreshed.ActiveDeploymentID, refreshed.Drifted, result2.DeploymentID)
+	}
+}
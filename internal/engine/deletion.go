@@ -5,10 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"sort"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
 	"github.com/agentctx/terraform-provider-agentctx/internal/target"
 )
 
@@ -25,10 +26,17 @@ import (
 //
 //   - ForceDestroy && ForceDestroySharedPrefix: delete ALL objects under
 //     <skill>/ (the entire skill prefix including any non-managed content).
+//
+// Without ForceDestroy, a skill that consumers hold reference markers on is
+// not touched and an error wrapping ErrReferenced is returned. ForceDestroy
+// deletes the markers along with everything else under <skill>/.agentctx/.
 func (e *Engine) Destroy(ctx context.Context, tgt target.Target, skillName string, opts DestroyOptions) error {
 	if opts.ForceDestroy {
 		return e.forceDestroy(ctx, tgt, skillName, opts.ForceDestroySharedPrefix)
 	}
+	if err := e.checkReferences(ctx, tgt, skillName); err != nil {
+		return fmt.Errorf("destroy: %w", err)
+	}
 	return e.gracefulDestroy(ctx, tgt, skillName, opts)
 }
 
@@ -59,15 +67,19 @@ func (e *Engine) gracefulDestroy(ctx context.Context, tgt target.Target, skillNa
 	}
 
 	// Delete each managed deployment.
-	for _, depID := range opts.ManagedDeployIDs {
+	for i, depID := range opts.ManagedDeployIDs {
 		if err := e.deleteDeployment(ctx, tgt, skillName, depID); err != nil {
+			_ = e.unindexDeployments(ctx, tgt, skillName, opts.ManagedDeployIDs[:i])
 			return fmt.Errorf("destroy: delete deployment %q: %w", depID, err)
 		}
 	}
+	if err := e.unindexDeployments(ctx, tgt, skillName, opts.ManagedDeployIDs); err != nil {
+		return fmt.Errorf("destroy: %w", err)
+	}
 
 	// Delete ACTIVE only if it points to a managed deployment.
 	activeKey := activePointerKey(skillName)
-	currentActiveID, err := readCurrentActive(ctx, tgt, activeKey)
+	entries, err := readCurrentActive(ctx, tgt, activeKey)
 	if err != nil {
 		if errors.Is(err, target.ErrNotFound) {
 			// ACTIVE doesn't exist — nothing to do.
@@ -76,7 +88,7 @@ func (e *Engine) gracefulDestroy(ctx context.Context, tgt target.Target, skillNa
 		return fmt.Errorf("destroy: read ACTIVE: %w", err)
 	}
 
-	if _, isManaged := managedSet[currentActiveID]; isManaged {
+	if pointsToManaged(entries, managedSet) {
 		if err := tgt.Delete(ctx, activeKey); err != nil {
 			return fmt.Errorf("destroy: delete ACTIVE: %w", err)
 		}
@@ -85,6 +97,73 @@ func (e *Engine) gracefulDestroy(ctx context.Context, tgt target.Target, skillNa
 	return nil
 }
 
+// PreviewDestroy returns, sorted, the object keys that Destroy would delete
+// from tgt with the same options. It only lists and reads; nothing is
+// modified. Objects written between the preview and the actual destroy are
+// not reflected. Like Destroy, it returns an error wrapping ErrReferenced
+// for a referenced skill without ForceDestroy.
+func (e *Engine) PreviewDestroy(ctx context.Context, tgt target.Target, skillName string, opts DestroyOptions) ([]string, error) {
+	var keys []string
+
+	if opts.ForceDestroy {
+		prefix := agentctxPrefix(skillName)
+		if opts.ForceDestroySharedPrefix {
+			prefix = skillPrefix(skillName)
+		}
+		objects, err := tgt.List(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("preview destroy: list %q: %w", prefix, err)
+		}
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+		sort.Strings(keys)
+		return keys, nil
+	}
+
+	if err := e.checkReferences(ctx, tgt, skillName); err != nil {
+		return nil, fmt.Errorf("preview destroy: %w", err)
+	}
+
+	managedSet := make(map[string]struct{}, len(opts.ManagedDeployIDs))
+	for _, depID := range opts.ManagedDeployIDs {
+		managedSet[depID] = struct{}{}
+
+		prefix := deploymentPrefix(skillName, depID)
+		objects, err := tgt.List(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("preview destroy: list deployment %q: %w", depID, err)
+		}
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+	}
+
+	// The index is deleted once no deployment is left in it.
+	idx, _, err := readIndex(ctx, tgt, skillName)
+	if err != nil {
+		return nil, fmt.Errorf("preview destroy: %w", err)
+	}
+	if idx != nil && len(removeEntries(idx.Deployments, opts.ManagedDeployIDs)) == 0 {
+		keys = append(keys, indexKey(skillName))
+	}
+
+	activeKey := activePointerKey(skillName)
+	entries, err := readCurrentActive(ctx, tgt, activeKey)
+	switch {
+	case errors.Is(err, target.ErrNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("preview destroy: read ACTIVE: %w", err)
+	default:
+		if pointsToManaged(entries, managedSet) {
+			keys = append(keys, activeKey)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
 // deleteObjects deletes a list of objects from a target in parallel,
 // bounded by the engine's semaphore.
 func (e *Engine) deleteObjects(ctx context.Context, tgt target.Target, objects []target.ObjectInfo) error {
@@ -93,10 +172,10 @@ func (e *Engine) deleteObjects(ctx context.Context, tgt target.Target, objects [
 	for _, obj := range objects {
 		obj := obj
 		g.Go(func() error {
-			if err := e.sem.Acquire(gctx, 1); err != nil {
+			if err := e.sem.Acquire(gctx, concurrency.Write, 1); err != nil {
 				return err
 			}
-			defer e.sem.Release(1)
+			defer e.sem.Release(concurrency.Write, 1)
 
 			if err := tgt.Delete(gctx, obj.Key); err != nil {
 				return fmt.Errorf("delete %q: %w", obj.Key, err)
@@ -108,19 +187,31 @@ func (e *Engine) deleteObjects(ctx context.Context, tgt target.Target, objects [
 	return g.Wait()
 }
 
-// readCurrentActive reads the ACTIVE pointer and returns the raw deployment
-// ID string. Returns target.ErrNotFound if the ACTIVE key does not exist.
-func readCurrentActive(ctx context.Context, tgt target.Target, activeKey string) (string, error) {
+// readCurrentActive reads and parses the ACTIVE pointer. Returns
+// target.ErrNotFound if the ACTIVE key does not exist.
+func readCurrentActive(ctx context.Context, tgt target.Target, activeKey string) ([]PointerEntry, error) {
 	rc, _, err := tgt.Get(ctx, activeKey)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer rc.Close()
 
 	data, err := io.ReadAll(rc)
 	if err != nil {
-		return "", fmt.Errorf("read ACTIVE body: %w", err)
+		return nil, fmt.Errorf("read ACTIVE body: %w", err)
 	}
 
-	return strings.TrimSpace(string(data)), nil
+	return ParsePointer(data)
+}
+
+// pointsToManaged reports whether any deployment of a parsed ACTIVE pointer
+// is in managedSet. Such a pointer would dangle once the managed
+// deployments are deleted.
+func pointsToManaged(entries []PointerEntry, managedSet map[string]struct{}) bool {
+	for _, e := range entries {
+		if _, ok := managedSet[e.DeploymentID]; ok {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// SkillDependencies are the dependencies a deployed skill declares.
+type SkillDependencies struct {
+	SkillName string
+	// DeploymentID is the deployment ACTIVE points at: the canary of a
+	// weighted pointer. It is empty when the skill is not deployed.
+	DeploymentID string
+	// DeploymentAlias is the alias of that deployment, if it has one.
+	DeploymentAlias string
+	// DependsOn are the depends_on_skills of every deployment ACTIVE points
+	// at, sorted and without duplicates, so that a canary and its stable
+	// deployment are both covered.
+	DependsOn []string
+}
+
+// Dependencies returns the dependencies recorded in the manifests of the
+// deployments ACTIVE points at for skillName on tgt. A skill without an
+// ACTIVE pointer is returned with no deployment and no dependencies.
+func (e *Engine) Dependencies(ctx context.Context, tgt target.Target, skillName string) (SkillDependencies, error) {
+	deps := SkillDependencies{SkillName: skillName}
+
+	entries, _, err := readActivePointer(ctx, tgt, skillName)
+	if err != nil {
+		return deps, fmt.Errorf("dependencies of %q: %w", skillName, err)
+	}
+	deps.DeploymentID = firstDeploymentID(entries)
+
+	for _, entry := range entries {
+		m, err := readManifest(ctx, tgt, skillName, entry.DeploymentID)
+		if err != nil {
+			return deps, fmt.Errorf("dependencies of %q: %w", skillName, err)
+		}
+		if entry.DeploymentID == deps.DeploymentID {
+			deps.DeploymentAlias = m.DeploymentAlias
+		}
+		deps.DependsOn = append(deps.DependsOn, m.DependsOnSkills...)
+	}
+	slices.Sort(deps.DependsOn)
+	deps.DependsOn = slices.Compact(deps.DependsOn)
+	return deps, nil
+}
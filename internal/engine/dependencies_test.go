@@ -0,0 +1,61 @@
+package engine_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestDependencies(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	deps, err := eng.Dependencies(ctx, tgt, "my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps.DeploymentID != "" || len(deps.DependsOn) != 0 {
+		t.Errorf("undeployed skill: Dependencies = %+v, want none", deps)
+	}
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	input1 := defaultDeployInput(b1)
+	input1.DependsOnSkills = []string{"style-guide", "code-review"}
+	result1 := deployToTarget(t, eng, tgt, input1)
+
+	manifestJSON := string(readObject(t, tgt, "my-skill/.agentctx/deployments/"+result1.DeploymentID+"/manifest.json"))
+	if !strings.Contains(manifestJSON, `"depends_on_skills": [
+    "code-review",
+    "style-guide"
+  ]`) {
+		t.Errorf("manifest does not list the sorted dependencies:\n%s", manifestJSON)
+	}
+
+	// While a canary rolls out, the dependencies of both deployments are
+	// needed.
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	input2.CanaryWeight = 10
+	input2.DependsOnSkills = []string{"code-review", "skill_01abc"}
+	input2.DeploymentAlias = "v2.0.0"
+	result2 := deployToTarget(t, eng, tgt, input2)
+
+	deps, err = eng.Dependencies(ctx, tgt, "my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps.DeploymentID != result2.DeploymentID {
+		t.Errorf("DeploymentID = %q, want the canary %q", deps.DeploymentID, result2.DeploymentID)
+	}
+	if deps.DeploymentAlias != "v2.0.0" {
+		t.Errorf("DeploymentAlias = %q, want the canary's v2.0.0", deps.DeploymentAlias)
+	}
+	if want := []string{"code-review", "skill_01abc", "style-guide"}; !slices.Equal(deps.DependsOn, want) {
+		t.Errorf("DependsOn = %v, want %v", deps.DependsOn, want)
+	}
+}
@@ -8,14 +8,16 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
+	"slices"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
 	"github.com/agentctx/terraform-provider-agentctx/internal/deployid"
 	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/provenance"
 	"github.com/agentctx/terraform-provider-agentctx/internal/target"
 )
 
@@ -24,65 +26,212 @@ import (
 //
 // Steps:
 //  1. Generate deployment_id
-//  2. Clean up any previously staged deployment
+//  2. Clean up any previously staged deployment, and orphaned ones if
+//     requested
 //  3. Upload all bundle files in parallel
-//  4. Build and upload manifest.json
-//  5. Write/overwrite the ACTIVE pointer
-//  6. Return DeployResult
-func (e *Engine) Deploy(ctx context.Context, tgt target.Target, input DeployInput) (*DeployResult, error) {
+//  4. Build and upload manifest.json, and provenance if requested
+//  5. Write/overwrite the ACTIVE pointer, and wait for reads to show it
+//     if input.ReadAfterWrite is set
+//  6. Run input.Verify, if set, and roll ACTIVE back if it fails
+//  7. Record the deployment in the skill's index
+//  8. Return DeployResult
+//
+// With input.StageOnly set, steps 5 and 6 are skipped and the result is
+// marked Staged.
+//
+// Deploy stops at the next step boundary once ctx is done, and never moves
+// ACTIVE after that. A failure after step 2 is returned as a *StagedError
+// naming the deployment whose objects were left behind, and a failed
+// verification as a *VerifyError.
+func (e *Engine) Deploy(ctx context.Context, tgt target.Target, input DeployInput) (result *DeployResult, err error) {
 	// Step 1: Generate deployment ID.
 	depID := deployid.New()
 
+	if len(e.listeners) > 0 {
+		start := time.Now()
+		ev := DeployStartEvent{
+			Target:       tgt.Name(),
+			SkillName:    input.SkillName,
+			DeploymentID: depID,
+			Files:        len(input.Bundle.Files),
+		}
+		for _, fe := range input.Bundle.Files {
+			size, _ := fileSize(input.SourceDir, fe)
+			ev.Bytes += size
+		}
+		e.emit(func(l Listener) { l.OnDeployStart(ctx, ev) })
+		defer func() {
+			ev := DeployFinishedEvent{
+				Target:       tgt.Name(),
+				SkillName:    input.SkillName,
+				DeploymentID: depID,
+				BundleHash:   input.Bundle.BundleHash,
+				Duration:     time.Since(start),
+				Err:          err,
+			}
+			e.emit(func(l Listener) { l.OnDeployFinished(ctx, ev) })
+		}()
+	}
+
 	// Step 2: Clean up any previously staged deployment from a prior failed run.
 	if input.StagedDeployID != "" {
-		if err := e.CleanupStaged(ctx, tgt, input.SkillName, input.StagedDeployID); err != nil {
+		if err := e.cleanupPriorStaged(ctx, tgt, input.SkillName, input.StagedDeployID); err != nil {
 			// Log but do not fail — staged cleanup is best-effort.
 			// The deployment can proceed even if cleanup fails.
 			_ = err
 		}
 	}
+	var orphansRemoved []string
+	var orphanErr error
+	if input.CleanupOrphans {
+		orphansRemoved, orphanErr = e.CleanupOrphans(ctx, tgt, input.SkillName, input.ManagedDeployIDs, input.OrphanGracePeriod)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("engine: %w", err)
+	}
 
 	// Build key prefixes.
 	deployPrefix := deploymentPrefix(input.SkillName, depID)
 
 	// Step 3: Upload all files in parallel, bounded by the semaphore.
-	if err := e.uploadFiles(ctx, tgt, input, deployPrefix); err != nil {
-		return nil, fmt.Errorf("engine: upload files: %w", err)
+	if err := e.uploadFiles(ctx, tgt, input, depID, deployPrefix); err != nil {
+		return nil, &StagedError{DeploymentID: depID, Err: fmt.Errorf("engine: upload files: %w", err)}
 	}
 
 	// Step 4: Build and upload manifest.json.
+	if err := ctx.Err(); err != nil {
+		return nil, &StagedError{DeploymentID: depID, Err: fmt.Errorf("engine: upload manifest: %w", err)}
+	}
 	manifestJSON, err := e.uploadManifest(ctx, tgt, input, depID, deployPrefix)
 	if err != nil {
-		return nil, fmt.Errorf("engine: upload manifest: %w", err)
+		return nil, &StagedError{DeploymentID: depID, Err: fmt.Errorf("engine: upload manifest: %w", err)}
+	}
+	if input.Provenance != nil {
+		if err := tgt.Put(ctx, deployPrefix+provenance.FileName, bytes.NewReader(input.Provenance), target.PutOptions{
+			ContentType: provenance.ContentType,
+			Metadata:    objectMetadata(input.Workspace, input.Environment),
+		}); err != nil {
+			return nil, &StagedError{DeploymentID: depID, Err: fmt.Errorf("engine: upload provenance: %w", err)}
+		}
+	}
+
+	if input.StageOnly {
+		indexErr := e.indexDeployment(ctx, tgt, input.SkillName, newIndexEntry(input, depID, manifestJSON))
+		return &DeployResult{
+			TargetName:   tgt.Name(),
+			DeploymentID: depID,
+			BundleHash:   input.Bundle.BundleHash,
+			ManifestJSON: manifestJSON,
+			Staged:       true,
+
+			OrphansRemoved: orphansRemoved,
+			OrphanErr:      orphanErr,
+			IndexErr:       indexErr,
+		}, nil
 	}
 
 	// Step 5: Write the ACTIVE pointer.
-	if err := e.writeActivePointer(ctx, tgt, input, depID); err != nil {
-		return nil, fmt.Errorf("engine: write ACTIVE: %w", err)
+	if err := ctx.Err(); err != nil {
+		return nil, &StagedError{DeploymentID: depID, Err: fmt.Errorf("engine: write ACTIVE: %w", err)}
+	}
+	body := []byte(depID)
+	var previous []PointerEntry
+	if input.CanaryWeight > 0 || input.Verify != nil {
+		// The stable side of a canary pointer, and what a failed
+		// verification rolls back to, is whatever ACTIVE holds now.
+		previous, _, err = readActivePointer(ctx, tgt, input.SkillName)
+		if err != nil {
+			return nil, &StagedError{DeploymentID: depID, Err: fmt.Errorf("engine: write ACTIVE: %w", err)}
+		}
+	}
+	var stableID string
+	if input.CanaryWeight > 0 {
+		entries := canaryPointer(depID, input.CanaryWeight, previous)
+		body = FormatPointer(entries)
+		stableID = stableDeploymentID(entries)
+	}
+	activeMeta, err := e.writeActivePointer(ctx, tgt, input, body)
+	if err != nil {
+		err = fmt.Errorf("engine: write ACTIVE: %w", err)
+		if errors.Is(err, ErrActiveModified) {
+			// The new deployment will never become active; remove it so it
+			// does not linger as an orphan. Best-effort, like step 2.
+			if e.CleanupStaged(ctx, tgt, input.SkillName, depID) == nil {
+				return nil, err
+			}
+		}
+		return nil, &StagedError{DeploymentID: depID, Err: err}
+	}
+	var consistencyErr error
+	if input.ReadAfterWrite > 0 {
+		// Not fatal: ACTIVE was written, reads are just slow to show it.
+		consistencyErr = e.AwaitVisible(ctx, tgt, input.SkillName, depID, input.ReadAfterWrite)
+		if consistencyErr == nil {
+			// The metadata read right after the write may have been stale;
+			// read it again now that reads show the new pointer.
+			if meta, err := tgt.Head(ctx, activePointerKey(input.SkillName)); err == nil {
+				activeMeta = meta
+			}
+		}
 	}
 
-	// Step 6: Return the result.
+	// Step 6: Verify the deployment, rolling ACTIVE back if it fails.
+	if input.Verify != nil {
+		if err := e.verify(ctx, tgt, input, depID, previous, activeMeta); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 7: Record the deployment in the index. Like orphan cleanup, a
+	// failure does not fail the deploy: the index is removed instead.
+	indexErr := e.indexDeployment(ctx, tgt, input.SkillName, newIndexEntry(input, depID, manifestJSON))
+
+	// Step 8: Return the result.
 	return &DeployResult{
 		TargetName:   tgt.Name(),
 		DeploymentID: depID,
 		BundleHash:   input.Bundle.BundleHash,
 		ManifestJSON: manifestJSON,
+
+		ActiveETag:         activeMeta.ETag,
+		ActiveGeneration:   activeMeta.Generation,
+		StableDeploymentID: stableID,
+
+		OrphansRemoved: orphansRemoved,
+		OrphanErr:      orphanErr,
+		IndexErr:       indexErr,
+		ConsistencyErr: consistencyErr,
 	}, nil
 }
 
+// cleanupPriorStaged removes the staged deployment of a prior failed run,
+// unless ACTIVE points at it: a failed ACTIVE write may still have landed,
+// in which case the deployment is live and must be kept.
+func (e *Engine) cleanupPriorStaged(ctx context.Context, tgt target.Target, skillName, stagedDeployID string) error {
+	entries, _, err := readActivePointer(ctx, tgt, skillName)
+	if err != nil {
+		return err
+	}
+	if _, live := pointerIDs(entries)[stagedDeployID]; live {
+		return nil
+	}
+	return e.CleanupStaged(ctx, tgt, skillName, stagedDeployID)
+}
+
 // uploadFiles uploads all bundle files to the target in parallel, bounded
 // by the engine's semaphore.
-func (e *Engine) uploadFiles(ctx context.Context, tgt target.Target, input DeployInput, deployPrefix string) error {
+func (e *Engine) uploadFiles(ctx context.Context, tgt target.Target, input DeployInput, depID, deployPrefix string) error {
 	g, gctx := errgroup.WithContext(ctx)
 
 	for _, fe := range input.Bundle.Files {
 		fe := fe // capture loop variable
 		g.Go(func() error {
-			// Acquire semaphore slot.
-			if err := e.sem.Acquire(gctx, 1); err != nil {
+			// Acquire slots, weighted by file size when configured.
+			weight := e.uploadWeight(input.SourceDir, fe)
+			if err := e.sem.Acquire(gctx, concurrency.Write, weight); err != nil {
 				return fmt.Errorf("acquire semaphore for %q: %w", fe.RelPath, err)
 			}
-			defer e.sem.Release(1)
+			defer e.sem.Release(concurrency.Write, weight)
 
 			// Read file content.
 			content, err := readFileContent(input, fe)
@@ -99,9 +248,18 @@ func (e *Engine) uploadFiles(ctx context.Context, tgt target.Target, input Deplo
 			// Upload.
 			if err := tgt.Put(gctx, key, bytes.NewReader(content), target.PutOptions{
 				ContentType: ct,
+				Metadata:    objectMetadata(input.Workspace, input.Environment),
 			}); err != nil {
 				return fmt.Errorf("put %q: %w", key, err)
 			}
+			ev := FileUploadedEvent{
+				Target:       tgt.Name(),
+				SkillName:    input.SkillName,
+				DeploymentID: depID,
+				RelPath:      fe.RelPath,
+				Size:         int64(len(content)),
+			}
+			e.emit(func(l Listener) { l.OnFileUploaded(ctx, ev) })
 
 			return nil
 		})
@@ -126,6 +284,37 @@ func readFileContent(input DeployInput, fe bundle.FileEntry) ([]byte, error) {
 	return nil, fmt.Errorf("no source path available for file %q", fe.RelPath)
 }
 
+// uploadWeight returns the scheduler weight of uploading fe. Sizes are only
+// looked up when the scheduler weights uploads; a file that cannot be
+// stat'ed weighs one slot and fails later when it is read.
+func (e *Engine) uploadWeight(sourceDir string, fe bundle.FileEntry) int64 {
+	if !e.sem.Weighted() {
+		return 1
+	}
+	size, ok := fileSize(sourceDir, fe)
+	if !ok {
+		return 1
+	}
+	return e.sem.Weight(size)
+}
+
+// fileSize returns the size of fe on disk, and false if it cannot be
+// stat'ed.
+func fileSize(sourceDir string, fe bundle.FileEntry) (int64, bool) {
+	p := fe.AbsPath
+	if p == "" {
+		if sourceDir == "" {
+			return 0, false
+		}
+		p = filepath.Join(sourceDir, filepath.FromSlash(fe.RelPath))
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return 0, false
+	}
+	return info.Size(), true
+}
+
 // uploadManifest builds and uploads the manifest.json for the deployment.
 func (e *Engine) uploadManifest(ctx context.Context, tgt target.Target, input DeployInput, depID string, deployPrefix string) ([]byte, error) {
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -141,21 +330,28 @@ func (e *Engine) uploadManifest(ctx context.Context, tgt target.Target, input De
 	}
 
 	m := &manifest.Manifest{
-		SchemaVersion:   2,
+		SchemaVersion:   manifest.SchemaVersion,
 		ProviderVersion: input.ProviderVersion,
 		ResourceType:    "skill",
 		ResourceName:    input.ResourceName,
 		CanonicalStore:  input.CanonicalStore,
+		Workspace:       input.Workspace,
+		Environment:     input.Environment,
 		DeploymentID:    depID,
+		DeploymentAlias: input.DeploymentAlias,
 		CreatedAt:       now,
+		ExpiresAt:       expiresAt(input.ExpiresAt),
 		SourceHash:      input.Bundle.BundleHash, // source_hash = bundle_hash for source-canonical
 		BundleHash:      input.Bundle.BundleHash,
 		Origin: &manifest.ManifestOrigin{
-			Type:      originType(input),
-			SourceDir: input.SourceDir,
+			Type:          originType(input),
+			SourceDir:     input.SourceDir,
+			SourceArchive: input.SourceArchive,
+			SourceURL:     input.SourceURL,
 		},
-		Registry: input.RegistryInfo,
-		Files:    files,
+		Registry:        input.RegistryInfo,
+		DependsOnSkills: slices.Sorted(slices.Values(input.DependsOnSkills)),
+		Files:           files,
 	}
 
 	manifestJSON, err := manifest.Marshal(m)
@@ -166,6 +362,7 @@ func (e *Engine) uploadManifest(ctx context.Context, tgt target.Target, input De
 	key := deployPrefix + "manifest.json"
 	if err := tgt.Put(ctx, key, bytes.NewReader(manifestJSON), target.PutOptions{
 		ContentType: bundle.ContentTypeManifest,
+		Metadata:    objectMetadata(input.Workspace, input.Environment),
 	}); err != nil {
 		return nil, fmt.Errorf("put manifest: %w", err)
 	}
@@ -174,39 +371,108 @@ func (e *Engine) uploadManifest(ctx context.Context, tgt target.Target, input De
 }
 
 // writeActivePointer writes (or conditionally overwrites) the ACTIVE pointer
-// file for the skill.
-func (e *Engine) writeActivePointer(ctx context.Context, tgt target.Target, input DeployInput, depID string) error {
+// file for the skill with body and returns the metadata of the written
+// object.
+//
+// When input.PreviousDeployID is set the write is conditional. If ACTIVE
+// metadata from the last refresh is available (input.ActiveETag or
+// input.ActiveGeneration) it is used as the write condition directly, so any
+// change made since that refresh fails the write. Otherwise the current
+// ACTIVE is read and must still point at PreviousDeployID, as its first
+// entry if it is weighted. Either way a mismatch is reported as
+// ErrActiveModified.
+func (e *Engine) writeActivePointer(ctx context.Context, tgt target.Target, input DeployInput, body []byte) (target.ObjectMeta, error) {
 	activeKey := activePointerKey(input.SkillName)
-	body := []byte(depID)
 	opts := target.PutOptions{
 		ContentType: bundle.ContentTypeACTIVE,
+		Metadata:    objectMetadata(input.Workspace, input.Environment),
 	}
 
-	if input.PreviousDeployID != "" {
-		// Conditional write: read current ACTIVE to get ETag/Generation,
-		// then use ConditionalPut to avoid clobbering concurrent updates.
-		_, meta, err := tgt.Get(ctx, activeKey)
+	if input.PreviousDeployID == "" {
+		// First deploy — unconditional write.
+		if err := tgt.Put(ctx, activeKey, bytes.NewReader(body), opts); err != nil {
+			return target.ObjectMeta{}, err
+		}
+		return headActivePointer(ctx, tgt, activeKey, input.ReadAfterWrite)
+	}
+
+	var condition target.WriteCondition
+	if input.ActiveETag != "" || input.ActiveGeneration != 0 {
+		condition = target.WriteCondition{
+			IfMatch:    input.ActiveETag,
+			Generation: input.ActiveGeneration,
+		}
+	} else {
+		// No metadata from a prior refresh: read current ACTIVE to get
+		// ETag/Generation and verify it still points where we left it.
+		rc, meta, err := tgt.Get(ctx, activeKey)
 		if err != nil {
 			if errors.Is(err, target.ErrNotFound) {
 				// ACTIVE disappeared — fall through to unconditional put.
-				return tgt.Put(ctx, activeKey, bytes.NewReader(body), opts)
+				if err := tgt.Put(ctx, activeKey, bytes.NewReader(body), opts); err != nil {
+					return target.ObjectMeta{}, err
+				}
+				return headActivePointer(ctx, tgt, activeKey, input.ReadAfterWrite)
 			}
-			return fmt.Errorf("read current ACTIVE: %w", err)
+			return target.ObjectMeta{}, fmt.Errorf("read current ACTIVE: %w", err)
+		}
+		current, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return target.ObjectMeta{}, fmt.Errorf("read current ACTIVE body: %w", err)
+		}
+		entries, err := ParsePointer(current)
+		if err != nil {
+			return target.ObjectMeta{}, fmt.Errorf("%w: %s", ErrActiveModified, err)
+		}
+		if got := firstDeploymentID(entries); got != input.PreviousDeployID {
+			return target.ObjectMeta{}, fmt.Errorf("%w: expected %q, found %q", ErrActiveModified, input.PreviousDeployID, got)
 		}
 
-		condition := target.WriteCondition{
+		condition = target.WriteCondition{
 			IfMatch:    meta.ETag,
 			Generation: meta.Generation,
 		}
+	}
+
+	if err := tgt.ConditionalPut(ctx, activeKey, bytes.NewReader(body), condition, opts); err != nil {
+		var cme *target.ConcurrentModificationError
+		if errors.Is(err, target.ErrPreconditionFailed) || errors.As(err, &cme) {
+			return target.ObjectMeta{}, fmt.Errorf("%w: %s", ErrActiveModified, err)
+		}
+		return target.ObjectMeta{}, fmt.Errorf("conditional put ACTIVE: %w", err)
+	}
+	return headActivePointer(ctx, tgt, activeKey, input.ReadAfterWrite)
+}
 
-		if err := tgt.ConditionalPut(ctx, activeKey, bytes.NewReader(body), condition, opts); err != nil {
-			return fmt.Errorf("conditional put ACTIVE: %w", err)
+// headActivePointer returns the metadata of the ACTIVE pointer just written.
+// On a target whose reads may trail its writes, a pointer not found yet is
+// looked up again for up to readAfterWrite.
+func headActivePointer(ctx context.Context, tgt target.Target, activeKey string, readAfterWrite time.Duration) (target.ObjectMeta, error) {
+	var (
+		meta    target.ObjectMeta
+		headErr error
+	)
+	_ = poll(ctx, readAfterWrite, func() error {
+		meta, headErr = tgt.Head(ctx, activeKey)
+		if errors.Is(headErr, target.ErrNotFound) {
+			return headErr
 		}
+		// Only a missing pointer may be a stale read; stop polling.
 		return nil
+	})
+	if headErr != nil {
+		return target.ObjectMeta{}, fmt.Errorf("head ACTIVE: %w", headErr)
 	}
+	return meta, nil
+}
 
-	// First deploy — unconditional write.
-	return tgt.Put(ctx, activeKey, bytes.NewReader(body), opts)
+// expiresAt formats t for the manifest, or returns "" for the zero time.
+func expiresAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
 }
 
 // originType determines the origin type string for the manifest.
@@ -217,6 +483,30 @@ func originType(input DeployInput) string {
 	return "source"
 }
 
+// Object metadata keys under which deployments record the Terraform
+// workspace and environment label that wrote them. Underscores rather than
+// hyphens keep the keys valid Azure metadata names.
+const (
+	MetadataWorkspace   = "agentctx_workspace"
+	MetadataEnvironment = "agentctx_environment"
+)
+
+// objectMetadata returns the metadata attached to every object a
+// deployment writes, or nil when neither workspace nor environment is set.
+func objectMetadata(workspace, environment string) map[string]string {
+	if workspace == "" && environment == "" {
+		return nil
+	}
+	md := make(map[string]string, 2)
+	if workspace != "" {
+		md[MetadataWorkspace] = workspace
+	}
+	if environment != "" {
+		md[MetadataEnvironment] = environment
+	}
+	return md
+}
+
 // activePointerKey returns the object key for the ACTIVE pointer.
 func activePointerKey(skillName string) string {
 	return skillName + "/.agentctx/ACTIVE"
@@ -237,23 +527,14 @@ func skillPrefix(skillName string) string {
 	return skillName + "/"
 }
 
-// readActiveDeploymentID reads the ACTIVE pointer and returns the deployment ID.
+// readActiveDeploymentID reads the ACTIVE pointer and returns the deployment ID,
+// the canary of a weighted pointer, together with the pointer's object
+// metadata.
 // Returns empty string and nil error if ACTIVE does not exist.
-func readActiveDeploymentID(ctx context.Context, tgt target.Target, skillName string) (string, error) {
-	activeKey := activePointerKey(skillName)
-	rc, _, err := tgt.Get(ctx, activeKey)
+func readActiveDeploymentID(ctx context.Context, tgt target.Target, skillName string) (string, target.ObjectMeta, error) {
+	entries, meta, err := readActivePointer(ctx, tgt, skillName)
 	if err != nil {
-		if errors.Is(err, target.ErrNotFound) {
-			return "", nil
-		}
-		return "", fmt.Errorf("read ACTIVE: %w", err)
+		return "", target.ObjectMeta{}, err
 	}
-	defer rc.Close()
-
-	data, err := io.ReadAll(rc)
-	if err != nil {
-		return "", fmt.Errorf("read ACTIVE body: %w", err)
-	}
-
-	return strings.TrimSpace(string(data)), nil
+	return firstDeploymentID(entries), meta, nil
 }
@@ -4,21 +4,56 @@
 package engine
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
 	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
-	"golang.org/x/sync/semaphore"
 )
 
+// ErrActiveModified is returned by Deploy when the ACTIVE pointer on a target
+// no longer matches what was observed at the last refresh, i.e. another
+// process (or a human) moved it since Terraform last read it.
+var ErrActiveModified = errors.New("ACTIVE pointer was modified outside Terraform")
+
+// StagedError is returned by Deploy when it fails, or is canceled, after
+// objects of the new deployment may have been written. ACTIVE was not moved
+// to the deployment, so its objects are orphans: callers should record
+// DeploymentID as staged and pass it as DeployInput.StagedDeployID on the
+// next deploy, which removes them.
+type StagedError struct {
+	DeploymentID string
+	Err          error
+}
+
+func (e *StagedError) Error() string {
+	return fmt.Sprintf("%s (deployment %s left staged)", e.Err, e.DeploymentID)
+}
+
+func (e *StagedError) Unwrap() error {
+	return e.Err
+}
+
 // Engine orchestrates deploy, refresh, prune, and destroy operations
-// against cloud storage targets. It uses a weighted semaphore to bound
-// concurrency across parallel file uploads.
+// against cloud storage targets. It uses a concurrency.Scheduler to bound
+// concurrency across parallel file uploads, deletes, and checks.
 type Engine struct {
-	sem *semaphore.Weighted
+	sem       *concurrency.Scheduler
+	listeners []Listener
 }
 
-// New creates a new Engine with the given concurrency semaphore.
-func New(sem *semaphore.Weighted) *Engine {
-	return &Engine{sem: sem}
+// Option configures an Engine.
+type Option func(*Engine)
+
+// New creates a new Engine with the given concurrency scheduler.
+func New(sem *concurrency.Scheduler, opts ...Option) *Engine {
+	e := &Engine{sem: sem}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // DeployResult holds the outcome of deploying to a single target.
@@ -27,17 +62,49 @@ type DeployResult struct {
 	DeploymentID string
 	BundleHash   string
 	ManifestJSON []byte
+
+	// ACTIVE object metadata after the pointer was written, for use as the
+	// write condition of the next deploy.
+	ActiveETag       string
+	ActiveGeneration int64
+
+	// StableDeploymentID is the deployment that receives the rest of the
+	// traffic when ACTIVE was written as a weighted pointer, and empty
+	// otherwise. See DeployInput.CanaryWeight.
+	StableDeploymentID string
+
+	// Staged is set when DeployInput.StageOnly left ACTIVE alone, in
+	// which case ActiveETag and ActiveGeneration are empty.
+	Staged bool
+
+	// Deployments removed by orphan cleanup before the upload, and the
+	// error that stopped it, if any. Cleanup failures do not fail the
+	// deploy.
+	OrphansRemoved []string
+	OrphanErr      error
+
+	// IndexErr is set when the deployment could not be recorded in the
+	// skill's index, nor the stale index removed. See Index.
+	IndexErr error
+
+	// ConsistencyErr is set when reads from the target did not show the
+	// new deployment within DeployInput.ReadAfterWrite. The deployment
+	// itself succeeded.
+	ConsistencyErr error
 }
 
 // RefreshResult holds the state read from a target.
 type RefreshResult struct {
 	TargetName         string
 	ActiveDeploymentID string
+	StableDeploymentID string // set when ACTIVE is a weighted pointer
 	Manifest           *manifest.Manifest
 	Healthy            bool     // all files present
 	Drifted            bool     // bundle_hash mismatch
 	MissingManifest    bool
 	MissingFiles       []string
+	ActiveETag         string // ETag of the ACTIVE pointer as read
+	ActiveGeneration   int64  // generation of the ACTIVE pointer as read
 }
 
 // DeployInput holds everything needed to deploy a skill bundle to a target.
@@ -48,9 +115,69 @@ type DeployInput struct {
 	ProviderVersion string
 	ResourceName    string
 	SourceDir       string
+	SourceArchive   string                     // set instead of SourceDir for source_archive
+	SourceURL       string                     // set instead of SourceDir for source_url and source_git
 	RegistryInfo    *manifest.ManifestRegistry // nil if no anthropic
 	PreviousDeployID string                    // for conditional ACTIVE write
 	StagedDeployID   string                    // from prior failed run, to clean up
+
+	// ACTIVE object metadata captured at the last refresh. When set, the
+	// ACTIVE write is conditioned on it so pointer changes made since then
+	// are detected instead of overwritten.
+	ActiveETag       string
+	ActiveGeneration int64
+
+	// When CleanupOrphans is set, deployments that neither ACTIVE nor
+	// ManagedDeployIDs refer to and that are at least OrphanGracePeriod old
+	// are deleted before uploading. See Engine.CleanupOrphans.
+	CleanupOrphans    bool
+	OrphanGracePeriod time.Duration
+	ManagedDeployIDs  []string
+
+	// Provenance, if set, is uploaded next to manifest.json as
+	// provenance.intoto.json before ACTIVE moves.
+	Provenance []byte
+
+	// Workspace and Environment are recorded in the manifest and as
+	// metadata on every object the deployment writes. Empty values are
+	// omitted.
+	Workspace   string
+	Environment string
+
+	// ExpiresAt, if set, is recorded in the manifest as the time after
+	// which CollectExpiredPreviews may delete the deployment's skill.
+	ExpiresAt time.Time
+
+	// Verify, if set, is called once ACTIVE points at the new deployment.
+	// If it fails, ACTIVE is rolled back to the pointer it replaced. See
+	// VerifyError.
+	Verify Verifier
+
+	// CanaryWeight, if set, writes ACTIVE as a weighted pointer that sends
+	// CanaryWeight percent of consumers to the new deployment and the rest
+	// to the stable deployment of the current pointer. Zero writes the
+	// plain pointer, promoting the new deployment. See ParsePointer.
+	CanaryWeight int
+
+	// StageOnly, if set, uploads the deployment and records it in the
+	// index but leaves ACTIVE, and so Verify and ReadAfterWrite, alone.
+	// The deployment goes live once Engine.Promote moves ACTIVE to it.
+	StageOnly bool
+
+	// DeploymentAlias, if set, is a human-readable name for the deployment,
+	// such as a release tag, recorded in the manifest and the index next
+	// to the generated deployment ID. See Engine.ResolveDeployment.
+	DeploymentAlias string
+
+	// DependsOnSkills are the names or registry IDs of the skills this one
+	// expects alongside it. They are recorded in the manifest, sorted. See
+	// Engine.Dependencies.
+	DependsOnSkills []string
+
+	// ReadAfterWrite, if set, is how long to wait after writing ACTIVE for
+	// reads from the target to show the new deployment, for targets whose
+	// reads may trail their writes. See Engine.AwaitVisible.
+	ReadAfterWrite time.Duration
 }
 
 // DestroyOptions controls how a skill is removed from a target during
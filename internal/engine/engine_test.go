@@ -3,24 +3,31 @@ package engine_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
-	"golang.org/x/sync/semaphore"
-
 	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/deployid"
 	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
 	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/progress"
+	"github.com/agentctx/terraform-provider-agentctx/internal/receipts"
 	"github.com/agentctx/terraform-provider-agentctx/internal/target"
 )
 
 // newTestEngine creates an Engine with a generous concurrency limit for tests.
 func newTestEngine() *engine.Engine {
-	return engine.New(semaphore.NewWeighted(10))
+	return engine.New(concurrency.NewUniform(10))
 }
 
 // createTempBundle creates a temporary directory with the given files and
@@ -215,6 +222,40 @@ func TestDeploy_ManifestResourceTypeIsSkill(t *testing.T) {
 	}
 }
 
+func TestDeploy_RecordsWorkspaceAndEnvironment(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n"})
+	input := defaultDeployInput(b)
+	input.Workspace = "prod-us"
+	input.Environment = "production"
+	result := deployToTarget(t, eng, tgt, input)
+
+	prefix := "my-skill/.agentctx/deployments/" + result.DeploymentID + "/"
+	m, err := manifest.Unmarshal(readObject(t, tgt, prefix+"manifest.json"))
+	if err != nil {
+		t.Fatalf("unmarshal manifest: %v", err)
+	}
+	if m.Workspace != "prod-us" || m.Environment != "production" {
+		t.Errorf("manifest workspace/environment = %q/%q, want prod-us/production", m.Workspace, m.Environment)
+	}
+
+	want := map[string]string{
+		engine.MetadataWorkspace:   "prod-us",
+		engine.MetadataEnvironment: "production",
+	}
+	for _, key := range []string{prefix + "files/SKILL.md", prefix + "manifest.json", "my-skill/.agentctx/ACTIVE"} {
+		got, err := tgt.Metadata(key)
+		if err != nil {
+			t.Fatalf("metadata of %s: %v", key, err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("metadata of %s = %v, want %v", key, got, want)
+		}
+	}
+}
+
 // TestDeploy_WithRegistryInfo verifies that when RegistryInfo is provided,
 // the manifest origin type is "registry" and the registry info is included.
 func TestDeploy_WithRegistryInfo(t *testing.T) {
@@ -263,6 +304,183 @@ func TestDeploy_WithRegistryInfo(t *testing.T) {
 	}
 }
 
+// recordingListener records the events it receives.
+type recordingListener struct {
+	engine.NopListener
+
+	mu       sync.Mutex
+	events   []string
+	uploaded []string
+	finished []engine.DeployFinishedEvent
+}
+
+func (r *recordingListener) OnDeployStart(_ context.Context, ev engine.DeployStartEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf("start %s files=%d bytes=%d", ev.Target, ev.Files, ev.Bytes))
+}
+
+func (r *recordingListener) OnFileUploaded(_ context.Context, ev engine.FileUploadedEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.uploaded = append(r.uploaded, fmt.Sprintf("%s=%d", ev.RelPath, ev.Size))
+}
+
+func (r *recordingListener) OnDeployFinished(_ context.Context, ev engine.DeployFinishedEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, "finished "+ev.Target)
+	r.finished = append(r.finished, ev)
+}
+
+func (r *recordingListener) OnPruneDeleted(_ context.Context, ev engine.PruneDeletedEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf("pruned %s (%s)", ev.DeploymentID, ev.Reason))
+}
+
+func TestListener_ReceivesEvents(t *testing.T) {
+	rec := &recordingListener{}
+	eng := engine.New(concurrency.NewUniform(10), engine.WithListeners(nil, rec))
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n", "a.txt": "aaaa"})
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	input := defaultDeployInput(b)
+	input.PreviousDeployID = first.DeploymentID
+	second := deployToTarget(t, eng, tgt, input)
+
+	pruned, err := eng.Prune(ctx, tgt, "my-skill", second.DeploymentID, []string{first.DeploymentID, second.DeploymentID}, 0)
+	if err != nil || len(pruned) != 1 {
+		t.Fatalf("Prune = %v, %v", pruned, err)
+	}
+
+	want := []string{
+		"start test files=2 bytes=12", "finished test",
+		"start test files=2 bytes=12", "finished test",
+		"pruned " + first.DeploymentID + " (retention)",
+	}
+	if strings.Join(rec.events, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events:\n%s\nwant:\n%s", strings.Join(rec.events, "\n"), strings.Join(want, "\n"))
+	}
+	sort.Strings(rec.uploaded)
+	if got := strings.Join(rec.uploaded, " "); got != "SKILL.md=8 SKILL.md=8 a.txt=4 a.txt=4" {
+		t.Errorf("uploaded = %s", got)
+	}
+	if ev := rec.finished[1]; ev.DeploymentID != second.DeploymentID || ev.Err != nil || ev.BundleHash != b.BundleHash {
+		t.Errorf("finished event = %+v", ev)
+	}
+
+	// A failed deploy still finishes, with its error.
+	tgt.SetFaults(target.FaultConfig{FailOnNthPut: 1})
+	if _, err := eng.Deploy(ctx, tgt, defaultDeployInput(b)); err == nil {
+		t.Fatal("deploy succeeded despite the injected fault")
+	}
+	if ev := rec.finished[len(rec.finished)-1]; ev.Err == nil {
+		t.Errorf("finished event of failed deploy has no error: %+v", ev)
+	}
+}
+
+func TestDeploy_ReportsProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+	eng := engine.New(concurrency.NewUniform(10), engine.WithListeners(engine.NewProgressListener(progress.NewReporter(0, path))))
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n", "a.txt": "aaaa"})
+	deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var last progress.Snapshot
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatal(err)
+	}
+	want := progress.Snapshot{
+		Operation:  "deploy",
+		Labels:     map[string]string{"skill_name": "my-skill", "target": "test"},
+		FilesDone:  2,
+		FilesTotal: 2,
+		BytesDone:  12,
+		BytesTotal: 12,
+		Done:       true,
+	}
+	last.Time, last.ElapsedSeconds, last.ETASeconds = time.Time{}, 0, 0
+	if fmt.Sprint(last) != fmt.Sprint(want) {
+		t.Errorf("final progress = %+v, want %+v", last, want)
+	}
+}
+
+func TestDeploy_WritesReceipts(t *testing.T) {
+	w := receipts.NewWriter(t.TempDir(), receipts.Run{ProviderVersion: "test"})
+	eng := engine.New(concurrency.NewUniform(10), engine.WithListeners(engine.NewReceiptListener(w)))
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n"})
+	result := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	tgt.SetFaults(target.FaultConfig{FailOnNthPut: 1})
+	if _, err := eng.Deploy(context.Background(), tgt, defaultDeployInput(b)); err == nil {
+		t.Fatal("deploy succeeded despite the injected fault")
+	}
+
+	data, err := os.ReadFile(w.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f receipts.File
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Deployments) != 2 {
+		t.Fatalf("got %d receipts, want 2: %s", len(f.Deployments), data)
+	}
+	ok, failed := f.Deployments[0], f.Deployments[1]
+	if ok.SkillName != "my-skill" || ok.Target != "test" || ok.DeploymentID != result.DeploymentID ||
+		ok.BundleHash != b.BundleHash || ok.Status != receipts.StatusSucceeded || ok.Error != "" {
+		t.Errorf("receipt = %+v, want the successful deployment", ok)
+	}
+	if failed.Status != receipts.StatusFailed || failed.Error == "" {
+		t.Errorf("receipt = %+v, want the failed deployment with its error", failed)
+	}
+}
+
+func TestDeploy_WithProvenance(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n"})
+	input := defaultDeployInput(b)
+	input.Provenance = []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+	result := deployToTarget(t, eng, tgt, input)
+
+	key := "my-skill/.agentctx/deployments/" + result.DeploymentID + "/provenance.intoto.json"
+	if got := readObject(t, tgt, key); !bytes.Equal(got, input.Provenance) {
+		t.Errorf("provenance = %s, want %s", got, input.Provenance)
+	}
+
+	// The statement is not a bundle file: refresh stays healthy, and
+	// destroy removes it with the deployment.
+	ref, err := eng.Refresh(ctx, tgt, "my-skill", b.BundleHash, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ref.Healthy || ref.Drifted {
+		t.Errorf("refresh after provenance upload: healthy=%v drifted=%v", ref.Healthy, ref.Drifted)
+	}
+	if err := eng.Destroy(ctx, tgt, "my-skill", engine.DestroyOptions{ForceDestroy: true}); err != nil {
+		t.Fatal(err)
+	}
+	if objectExists(t, tgt, key) {
+		t.Error("provenance left behind by destroy")
+	}
+}
+
 func TestDeploy_ConditionalUpdate(t *testing.T) {
 	eng := newTestEngine()
 	tgt := target.NewMemoryTarget("test")
@@ -305,6 +523,176 @@ func TestDeploy_ConditionalUpdate(t *testing.T) {
 	}
 }
 
+func TestDeploy_ConditionalUpdateWithActiveMetadata(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+	if result1.ActiveETag == "" {
+		t.Fatal("expected ActiveETag to be set after deploy")
+	}
+
+	refresh, err := eng.Refresh(context.Background(), tgt, "my-skill", "", false)
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if refresh.ActiveETag != result1.ActiveETag {
+		t.Errorf("refresh ActiveETag = %q, want %q", refresh.ActiveETag, result1.ActiveETag)
+	}
+	if refresh.ActiveGeneration != result1.ActiveGeneration {
+		t.Errorf("refresh ActiveGeneration = %d, want %d", refresh.ActiveGeneration, result1.ActiveGeneration)
+	}
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	input2.ActiveETag = refresh.ActiveETag
+	input2.ActiveGeneration = refresh.ActiveGeneration
+
+	result2 := deployToTarget(t, eng, tgt, input2)
+	activeData := readObject(t, tgt, "my-skill/.agentctx/ACTIVE")
+	if got := strings.TrimSpace(string(activeData)); got != result2.DeploymentID {
+		t.Errorf("ACTIVE = %q, want %q", got, result2.DeploymentID)
+	}
+	if result2.ActiveETag == result1.ActiveETag {
+		t.Error("expected ActiveETag to change after the second deploy")
+	}
+}
+
+func TestDeploy_ActiveModifiedSinceRefresh(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	// Another pipeline moves ACTIVE after Terraform's last read.
+	if err := tgt.Put(ctx, "my-skill/.agentctx/ACTIVE", strings.NewReader("other-pipeline"), target.PutOptions{}); err != nil {
+		t.Fatalf("external ACTIVE write: %v", err)
+	}
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	input2.ActiveETag = result1.ActiveETag
+	input2.ActiveGeneration = result1.ActiveGeneration
+
+	_, err := eng.Deploy(ctx, tgt, input2)
+	if !errors.Is(err, engine.ErrActiveModified) {
+		t.Fatalf("expected ErrActiveModified, got %v", err)
+	}
+
+	// The external pointer must be left alone.
+	activeData := readObject(t, tgt, "my-skill/.agentctx/ACTIVE")
+	if got := string(activeData); got != "other-pipeline" {
+		t.Errorf("ACTIVE = %q, want %q", got, "other-pipeline")
+	}
+
+	// The rejected deployment must not linger on the target.
+	objects, err := tgt.List(ctx, "my-skill/.agentctx/deployments/")
+	if err != nil {
+		t.Fatalf("list deployments: %v", err)
+	}
+	wantPrefix := "my-skill/.agentctx/deployments/" + result1.DeploymentID + "/"
+	for _, obj := range objects {
+		if !strings.HasPrefix(obj.Key, wantPrefix) {
+			t.Errorf("unexpected object %q left behind by rejected deploy", obj.Key)
+		}
+	}
+}
+
+func TestDeploy_InjectedConflictRollsBack(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	// The ACTIVE swap loses a race it never sees.
+	tgt.SetFaults(target.FaultConfig{ConditionalPutConflicts: 1})
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	input2.ActiveETag = result1.ActiveETag
+	input2.ActiveGeneration = result1.ActiveGeneration
+
+	if _, err := eng.Deploy(ctx, tgt, input2); !errors.Is(err, engine.ErrActiveModified) {
+		t.Fatalf("expected ErrActiveModified, got %v", err)
+	}
+
+	if got := string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE")); got != result1.DeploymentID {
+		t.Errorf("ACTIVE = %q, want %q", got, result1.DeploymentID)
+	}
+	objects, err := tgt.List(ctx, "my-skill/.agentctx/deployments/")
+	if err != nil {
+		t.Fatalf("list deployments: %v", err)
+	}
+	for _, obj := range objects {
+		if !strings.HasPrefix(obj.Key, "my-skill/.agentctx/deployments/"+result1.DeploymentID+"/") {
+			t.Errorf("unexpected object %q left behind by rolled-back deploy", obj.Key)
+		}
+	}
+}
+
+func TestDeploy_InjectedUploadFailureKeepsActive(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	// Without retries, the first file upload of the next deploy fails.
+	tgt.SetFaults(target.FaultConfig{FailOnNthPut: 1})
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2", "extra.txt": "new"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	input2.ActiveETag = result1.ActiveETag
+	input2.ActiveGeneration = result1.ActiveGeneration
+
+	if _, err := eng.Deploy(ctx, tgt, input2); !errors.Is(err, target.ErrInjectedFault) {
+		t.Fatalf("expected ErrInjectedFault, got %v", err)
+	}
+	if got := string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE")); got != result1.DeploymentID {
+		t.Errorf("ACTIVE = %q, want %q", got, result1.DeploymentID)
+	}
+
+	// The same deploy succeeds once wrapped in retries.
+	tgt.SetFaults(target.FaultConfig{FailOnNthPut: 1})
+	if _, err := eng.Deploy(ctx, target.NewRetryTarget(tgt, 2, "linear"), input2); err != nil {
+		t.Fatalf("deploy with retries: %v", err)
+	}
+}
+
+func TestDeploy_ActiveModifiedWithoutMetadata(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	if err := tgt.Put(ctx, "my-skill/.agentctx/ACTIVE", strings.NewReader("other-pipeline"), target.PutOptions{}); err != nil {
+		t.Fatalf("external ACTIVE write: %v", err)
+	}
+
+	// State written before ACTIVE metadata was tracked: only the previous
+	// deployment ID is known.
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+
+	_, err := eng.Deploy(ctx, tgt, input2)
+	if !errors.Is(err, engine.ErrActiveModified) {
+		t.Fatalf("expected ErrActiveModified, got %v", err)
+	}
+}
+
 func TestDeploy_WithStagedCleanup(t *testing.T) {
 	eng := newTestEngine()
 	tgt := target.NewMemoryTarget("test")
@@ -339,6 +727,340 @@ func TestDeploy_WithStagedCleanup(t *testing.T) {
 	}
 }
 
+func TestDeploy_CanceledMidUploadIsStaged(t *testing.T) {
+	eng := engine.New(concurrency.NewUniform(1))
+	tgt := target.NewMemoryTarget("test")
+
+	files := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		files[fmt.Sprintf("file%02d.txt", i)] = "content"
+	}
+	b := createTempBundle(t, files)
+
+	// Each upload takes long enough that the deploy is canceled part way.
+	tgt.SetFaults(target.FaultConfig{Latency: 20 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := eng.Deploy(ctx, tgt, defaultDeployInput(b))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Deploy took %s to notice cancellation", elapsed)
+	}
+	var stagedErr *engine.StagedError
+	if !errors.As(err, &stagedErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a StagedError wrapping DeadlineExceeded, got %v", err)
+	}
+	tgt.SetFaults(target.FaultConfig{})
+
+	if objectExists(t, tgt, "my-skill/.agentctx/ACTIVE") {
+		t.Error("ACTIVE written by a canceled deploy")
+	}
+	stagedPrefix := "my-skill/.agentctx/deployments/" + stagedErr.DeploymentID + "/"
+	objects, err := tgt.List(context.Background(), stagedPrefix)
+	if err != nil {
+		t.Fatalf("list staged deployment: %v", err)
+	}
+	if len(objects) == 0 {
+		t.Fatal("expected the canceled deploy to leave uploaded files behind")
+	}
+
+	// The next deploy removes the partial upload.
+	input := defaultDeployInput(b)
+	input.StagedDeployID = stagedErr.DeploymentID
+	deployToTarget(t, eng, tgt, input)
+	objects, err = tgt.List(context.Background(), stagedPrefix)
+	if err != nil {
+		t.Fatalf("list staged deployment: %v", err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("staged deployment not cleaned up: %d objects remain", len(objects))
+	}
+}
+
+func TestDeploy_CanceledBeforeStartWritesNothing(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	b := createTempBundle(t, map[string]string{"file.txt": "content"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := eng.Deploy(ctx, tgt, defaultDeployInput(b))
+	var stagedErr *engine.StagedError
+	if !errors.Is(err, context.Canceled) || errors.As(err, &stagedErr) {
+		t.Fatalf("expected a plain context.Canceled, got %v", err)
+	}
+	objects, err := tgt.List(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("canceled deploy wrote %d objects", len(objects))
+	}
+}
+
+func TestDeploy_StagedCleanupKeepsActiveDeployment(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	// A deploy whose ACTIVE write was reported as failed but landed.
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.StagedDeployID = result1.DeploymentID
+	deployToTarget(t, eng, tgt, input2)
+
+	if !objectExists(t, tgt, "my-skill/.agentctx/deployments/"+result1.DeploymentID+"/manifest.json") {
+		t.Error("staged cleanup removed the deployment ACTIVE pointed at")
+	}
+}
+
+func TestDeploy_VerifySeesActiveDeployment(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	var got engine.VerifyInfo
+	input2.Verify = func(ctx context.Context, info engine.VerifyInfo) error {
+		got = info
+		active := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE")))
+		if active != info.DeploymentID {
+			return fmt.Errorf("ACTIVE = %q during verify, want %q", active, info.DeploymentID)
+		}
+		return nil
+	}
+	result2 := deployToTarget(t, eng, tgt, input2)
+
+	want := engine.VerifyInfo{
+		Target:               "test",
+		SkillName:            "my-skill",
+		DeploymentID:         result2.DeploymentID,
+		PreviousDeploymentID: result1.DeploymentID,
+		BundleHash:           b2.BundleHash,
+	}
+	if got != want {
+		t.Errorf("VerifyInfo = %+v, want %+v", got, want)
+	}
+}
+
+func TestDeploy_VerifyFailureRollsBack(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	input2.ActiveETag = result1.ActiveETag
+	input2.ActiveGeneration = result1.ActiveGeneration
+	smokeErr := errors.New("smoke test returned 503")
+	input2.Verify = func(context.Context, engine.VerifyInfo) error { return smokeErr }
+
+	_, err := eng.Deploy(context.Background(), tgt, input2)
+	if !errors.Is(err, engine.ErrVerifyFailed) || !errors.Is(err, smokeErr) {
+		t.Fatalf("err = %v, want ErrVerifyFailed wrapping the verifier's error", err)
+	}
+	var verr *engine.VerifyError
+	if !errors.As(err, &verr) || !verr.RolledBack {
+		t.Fatalf("err = %#v, want a rolled back *VerifyError", err)
+	}
+
+	if got := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE"))); got != result1.DeploymentID {
+		t.Errorf("ACTIVE = %q, want it rolled back to %q", got, result1.DeploymentID)
+	}
+	meta, err := tgt.Head(context.Background(), "my-skill/.agentctx/ACTIVE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verr.ActiveETag != meta.ETag {
+		t.Errorf("VerifyError.ActiveETag = %q, want the restored pointer's %q", verr.ActiveETag, meta.ETag)
+	}
+	if !objectExists(t, tgt, "my-skill/.agentctx/deployments/"+verr.DeploymentID+"/manifest.json") {
+		t.Error("rejected deployment should be left staged")
+	}
+
+	// The next deploy, conditioned on the restored pointer, succeeds and
+	// removes the rejected deployment.
+	b3 := createTempBundle(t, map[string]string{"file.txt": "version 3"})
+	input3 := defaultDeployInput(b3)
+	input3.PreviousDeployID = result1.DeploymentID
+	input3.ActiveETag = verr.ActiveETag
+	input3.ActiveGeneration = verr.ActiveGeneration
+	input3.StagedDeployID = verr.DeploymentID
+	deployToTarget(t, eng, tgt, input3)
+
+	if objectExists(t, tgt, "my-skill/.agentctx/deployments/"+verr.DeploymentID+"/manifest.json") {
+		t.Error("rejected deployment not cleaned up by the next deploy")
+	}
+}
+
+func TestDeploy_VerifyFailureOnFirstDeployRemovesActive(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	input := defaultDeployInput(b)
+	input.Verify = func(context.Context, engine.VerifyInfo) error { return errors.New("unhealthy") }
+
+	_, err := eng.Deploy(context.Background(), tgt, input)
+	var verr *engine.VerifyError
+	if !errors.As(err, &verr) || !verr.RolledBack {
+		t.Fatalf("err = %v, want a rolled back *VerifyError", err)
+	}
+	if objectExists(t, tgt, "my-skill/.agentctx/ACTIVE") {
+		t.Error("ACTIVE should be removed when the first deployment fails verification")
+	}
+}
+
+func TestDeploy_CanaryWritesWeightedPointer(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	activeKey := "my-skill/.agentctx/ACTIVE"
+
+	// The first deploy has nothing to roll out against.
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	input1 := defaultDeployInput(b1)
+	input1.CanaryWeight = 10
+	result1 := deployToTarget(t, eng, tgt, input1)
+	if got := string(readObject(t, tgt, activeKey)); got != result1.DeploymentID {
+		t.Fatalf("ACTIVE = %q, want the plain pointer %q", got, result1.DeploymentID)
+	}
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	input2.CanaryWeight = 10
+	result2 := deployToTarget(t, eng, tgt, input2)
+
+	want := result2.DeploymentID + " 10\n" + result1.DeploymentID + " 90\n"
+	if got := string(readObject(t, tgt, activeKey)); got != want {
+		t.Errorf("ACTIVE = %q, want %q", got, want)
+	}
+	if result2.StableDeploymentID != result1.DeploymentID {
+		t.Errorf("StableDeploymentID = %q, want %q", result2.StableDeploymentID, result1.DeploymentID)
+	}
+
+	refreshed, err := eng.Refresh(ctx, tgt, "my-skill", b2.BundleHash, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.ActiveDeploymentID != result2.DeploymentID || refreshed.StableDeploymentID != result1.DeploymentID || refreshed.Drifted {
+		t.Errorf("Refresh = %+v, want canary %s against stable %s without drift", refreshed, result2.DeploymentID, result1.DeploymentID)
+	}
+
+	// The stable deployment is live too and must survive pruning.
+	pruned, err := eng.Prune(ctx, tgt, "my-skill", result2.DeploymentID, []string{result1.DeploymentID, result2.DeploymentID}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 0 {
+		t.Errorf("Prune removed %v, want the stable deployment kept", pruned)
+	}
+
+	// Changing the canary keeps the same stable deployment.
+	b3 := createTempBundle(t, map[string]string{"file.txt": "version 3"})
+	input3 := defaultDeployInput(b3)
+	input3.PreviousDeployID = result2.DeploymentID
+	input3.CanaryWeight = 25
+	result3 := deployToTarget(t, eng, tgt, input3)
+
+	want = result3.DeploymentID + " 25\n" + result1.DeploymentID + " 75\n"
+	if got := string(readObject(t, tgt, activeKey)); got != want {
+		t.Errorf("ACTIVE = %q, want %q", got, want)
+	}
+
+	// Deploying without a weight promotes to a plain pointer.
+	b4 := createTempBundle(t, map[string]string{"file.txt": "version 4"})
+	input4 := defaultDeployInput(b4)
+	input4.PreviousDeployID = result3.DeploymentID
+	result4 := deployToTarget(t, eng, tgt, input4)
+
+	if got := string(readObject(t, tgt, activeKey)); got != result4.DeploymentID {
+		t.Errorf("ACTIVE = %q, want the plain pointer %q", got, result4.DeploymentID)
+	}
+	if result4.StableDeploymentID != "" {
+		t.Errorf("StableDeploymentID = %q, want empty after promotion", result4.StableDeploymentID)
+	}
+}
+
+func TestDeploy_VerifyFailureRestoresWeightedPointer(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	activeKey := "my-skill/.agentctx/ACTIVE"
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input2 := defaultDeployInput(b2)
+	input2.PreviousDeployID = result1.DeploymentID
+	input2.CanaryWeight = 10
+	result2 := deployToTarget(t, eng, tgt, input2)
+	before := readObject(t, tgt, activeKey)
+
+	b3 := createTempBundle(t, map[string]string{"file.txt": "version 3"})
+	input3 := defaultDeployInput(b3)
+	input3.PreviousDeployID = result2.DeploymentID
+	input3.CanaryWeight = 50
+	input3.Verify = func(context.Context, engine.VerifyInfo) error { return errors.New("unhealthy") }
+
+	_, err := eng.Deploy(context.Background(), tgt, input3)
+	var verr *engine.VerifyError
+	if !errors.As(err, &verr) || !verr.RolledBack {
+		t.Fatalf("err = %v, want a rolled back *VerifyError", err)
+	}
+	if got := readObject(t, tgt, activeKey); !bytes.Equal(got, before) {
+		t.Errorf("ACTIVE = %q, want the weighted pointer %q restored", got, before)
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []engine.PointerEntry
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"plain", "20260101T000000Z-aaaa\n", []engine.PointerEntry{{DeploymentID: "20260101T000000Z-aaaa", Weight: 100}}, false},
+		{"weighted", "20260102T000000Z-bbbb 10\n20260101T000000Z-aaaa 90\n", []engine.PointerEntry{
+			{DeploymentID: "20260102T000000Z-bbbb", Weight: 10},
+			{DeploymentID: "20260101T000000Z-aaaa", Weight: 90},
+		}, false},
+		{"single weighted line", "20260101T000000Z-aaaa 100", []engine.PointerEntry{{DeploymentID: "20260101T000000Z-aaaa", Weight: 100}}, false},
+		{"weights not summing to 100", "a 10\nb 80\n", nil, true},
+		{"bad weight", "a ten\nb 90\n", nil, true},
+		{"missing weight", "a 10\nb\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.ParsePointer([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePointer(%q) error = %v, wantErr %v", tt.body, err, tt.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ParsePointer(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+			if err == nil && len(got) > 0 {
+				again, err := engine.ParsePointer(engine.FormatPointer(got))
+				if err != nil || fmt.Sprint(again) != fmt.Sprint(got) {
+					t.Errorf("FormatPointer round trip = %v, %v; want %v", again, err, got)
+				}
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Refresh tests
 // ---------------------------------------------------------------------------
@@ -731,6 +1453,88 @@ func TestDestroy_ForceWithSharedPrefix(t *testing.T) {
 	}
 }
 
+func TestDestroy_RefusesReferencedSkill(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b := createTempBundle(t, map[string]string{"main.py": "code"})
+	result := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	for _, consumer := range []string{"stack-b", "stack-a"} {
+		ref := engine.Reference{Consumer: consumer, DeploymentID: result.DeploymentID, CreatedAt: "2026-01-01T00:00:00Z"}
+		if err := eng.AddReference(ctx, tgt, "my-skill", ref); err != nil {
+			t.Fatalf("AddReference(%s): %v", consumer, err)
+		}
+	}
+
+	consumers, err := eng.References(ctx, tgt, "my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(consumers) != "[stack-a stack-b]" {
+		t.Errorf("References = %v, want [stack-a stack-b]", consumers)
+	}
+	ref, err := eng.ReadReference(ctx, tgt, "my-skill", "stack-a")
+	if err != nil || ref.DeploymentID != result.DeploymentID {
+		t.Errorf("ReadReference = %+v, %v", ref, err)
+	}
+
+	opts := engine.DestroyOptions{
+		ManagedDeployIDs: []string{result.DeploymentID},
+		ActiveDeployID:   result.DeploymentID,
+	}
+	if _, err := eng.PreviewDestroy(ctx, tgt, "my-skill", opts); !errors.Is(err, engine.ErrReferenced) {
+		t.Errorf("PreviewDestroy error = %v, want ErrReferenced", err)
+	}
+	err = eng.Destroy(ctx, tgt, "my-skill", opts)
+	if !errors.Is(err, engine.ErrReferenced) || !strings.Contains(err.Error(), "stack-a, stack-b") {
+		t.Fatalf("Destroy error = %v, want ErrReferenced naming both consumers", err)
+	}
+	if !objectExists(t, tgt, "my-skill/.agentctx/ACTIVE") {
+		t.Error("refused destroy removed ACTIVE")
+	}
+
+	// Once every consumer lets go, the destroy proceeds.
+	for _, consumer := range []string{"stack-a", "stack-b", "never-added"} {
+		if err := eng.RemoveReference(ctx, tgt, "my-skill", consumer); err != nil {
+			t.Fatalf("RemoveReference(%s): %v", consumer, err)
+		}
+	}
+	if err := eng.Destroy(ctx, tgt, "my-skill", opts); err != nil {
+		t.Fatalf("Destroy after removing references: %v", err)
+	}
+	if objectExists(t, tgt, "my-skill/.agentctx/ACTIVE") {
+		t.Error("ACTIVE still exists after destroy")
+	}
+}
+
+func TestDestroy_ForceIgnoresReferences(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b := createTempBundle(t, map[string]string{"main.py": "code"})
+	result := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	if err := eng.AddReference(ctx, tgt, "my-skill", engine.Reference{Consumer: "stack-a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := eng.Destroy(ctx, tgt, "my-skill", engine.DestroyOptions{
+		ForceDestroy:     true,
+		ManagedDeployIDs: []string{result.DeploymentID},
+	})
+	if err != nil {
+		t.Fatalf("force destroy: %v", err)
+	}
+	objects, err := tgt.List(ctx, "my-skill/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("expected 0 objects after force destroy, got %d", len(objects))
+	}
+}
+
 func TestDestroy_Graceful(t *testing.T) {
 	eng := newTestEngine()
 	tgt := target.NewMemoryTarget("test")
@@ -830,6 +1634,78 @@ func TestDestroy_GracefulPreservesUnmanaged(t *testing.T) {
 	}
 }
 
+func TestPreviewDestroy_MatchesDestroy(t *testing.T) {
+	cases := []struct {
+		name        string
+		force       bool
+		sharedPrefix bool
+	}{
+		{"graceful", false, false},
+		{"force", true, false},
+		{"force shared prefix", true, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := newTestEngine()
+			tgt := target.NewMemoryTarget("test")
+			ctx := context.Background()
+
+			b := createTempBundle(t, map[string]string{"file.txt": "managed"})
+			result := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+			// Unmanaged content that only some modes remove.
+			_ = tgt.Put(ctx, "my-skill/.agentctx/deployments/dep_20260101T000000Z_aabbccdd/manifest.json", bytes.NewReader([]byte("{}")), target.PutOptions{})
+			_ = tgt.Put(ctx, "my-skill/custom-file.txt", bytes.NewReader([]byte("custom")), target.PutOptions{})
+
+			opts := engine.DestroyOptions{
+				ForceDestroy:             tc.force,
+				ForceDestroySharedPrefix: tc.sharedPrefix,
+				ManagedDeployIDs:         []string{result.DeploymentID},
+				ActiveDeployID:           result.DeploymentID,
+			}
+
+			before, err := tgt.List(ctx, "my-skill/")
+			if err != nil {
+				t.Fatalf("listing before destroy: %v", err)
+			}
+
+			preview, err := eng.PreviewDestroy(ctx, tgt, "my-skill", opts)
+			if err != nil {
+				t.Fatalf("preview destroy failed: %v", err)
+			}
+			if len(preview) == 0 {
+				t.Fatal("expected preview to list keys")
+			}
+
+			if err := eng.Destroy(ctx, tgt, "my-skill", opts); err != nil {
+				t.Fatalf("destroy failed: %v", err)
+			}
+
+			after, err := tgt.List(ctx, "my-skill/")
+			if err != nil {
+				t.Fatalf("listing after destroy: %v", err)
+			}
+			remaining := make(map[string]bool, len(after))
+			for _, o := range after {
+				remaining[o.Key] = true
+			}
+
+			var deleted []string
+			for _, o := range before {
+				if !remaining[o.Key] {
+					deleted = append(deleted, o.Key)
+				}
+			}
+			sort.Strings(deleted)
+
+			if strings.Join(preview, "\n") != strings.Join(deleted, "\n") {
+				t.Errorf("preview does not match deleted keys\npreview: %v\ndeleted: %v", preview, deleted)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // CleanupStaged tests
 // ---------------------------------------------------------------------------
@@ -930,6 +1806,203 @@ func TestCleanupStaged_PreservesOtherDeployments(t *testing.T) {
 	}
 }
 
+func TestCleanupOrphans(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	const (
+		orphanID  = "dep_20200101T000000Z_11223344"
+		managedID = "dep_20200102T000000Z_55667788"
+		activeID  = "dep_20200103T000000Z_99aabbcc"
+	)
+	freshID := deployid.New()
+	deploymentsPrefix := "my-skill/.agentctx/deployments/"
+	for _, id := range []string{orphanID, managedID, activeID, freshID, "not-a-deployment"} {
+		_ = tgt.Put(ctx, deploymentsPrefix+id+"/files/a.txt", bytes.NewReader([]byte("x")), target.PutOptions{})
+		_ = tgt.Put(ctx, deploymentsPrefix+id+"/manifest.json", bytes.NewReader([]byte("{}")), target.PutOptions{})
+	}
+	_ = tgt.Put(ctx, "my-skill/.agentctx/ACTIVE", strings.NewReader(activeID), target.PutOptions{})
+
+	removed, err := eng.CleanupOrphans(ctx, tgt, "my-skill", []string{managedID}, time.Hour)
+	if err != nil {
+		t.Fatalf("cleanup orphans: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != orphanID {
+		t.Errorf("removed = %v, want [%s]", removed, orphanID)
+	}
+
+	if objectExists(t, tgt, deploymentsPrefix+orphanID+"/manifest.json") {
+		t.Error("orphaned deployment should have been removed")
+	}
+	for _, id := range []string{managedID, activeID, freshID, "not-a-deployment"} {
+		if !objectExists(t, tgt, deploymentsPrefix+id+"/manifest.json") {
+			t.Errorf("deployment %s should have been kept", id)
+		}
+	}
+}
+
+func TestCollectExpiredPreviews(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+	now := time.Now()
+
+	b := createTempBundle(t, map[string]string{"main.py": "code"})
+	deployPreview := func(id string, expiresAt time.Time) *engine.DeployResult {
+		input := defaultDeployInput(b)
+		input.SkillName = engine.PreviewSkillName(id, "my-skill")
+		input.ExpiresAt = expiresAt
+		return deployToTarget(t, eng, tgt, input)
+	}
+	deployPreview("pr-1", now.Add(-time.Hour))
+	live := deployPreview("pr-2", now.Add(time.Hour))
+	deployPreview("pr-3", now.Add(-time.Hour))
+	deployPreview("pr-4", time.Time{})
+	deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	if err := eng.AddReference(ctx, tgt, engine.PreviewSkillName("pr-3", "my-skill"), engine.Reference{Consumer: "stack-a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := manifest.Unmarshal(live.ManifestJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(time.Hour).UTC().Format(time.RFC3339); m.ExpiresAt != want {
+		t.Errorf("manifest expires_at = %q, want %q", m.ExpiresAt, want)
+	}
+
+	removed, err := eng.CollectExpiredPreviews(ctx, tgt, "my-skill", now)
+	if err != nil {
+		t.Fatalf("collect expired previews: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "pr-1" {
+		t.Errorf("removed = %v, want [pr-1]", removed)
+	}
+
+	for prefix, want := range map[string]bool{
+		"previews/pr-1/my-skill/": false,
+		"previews/pr-2/my-skill/": true,
+		"previews/pr-3/my-skill/": true,
+		"previews/pr-4/my-skill/": true,
+		"my-skill/":               true,
+	} {
+		objects, err := tgt.List(ctx, prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(objects) > 0; got != want {
+			t.Errorf("objects under %q present = %v, want %v", prefix, got, want)
+		}
+	}
+}
+
+func TestDeploy_CleanupOrphans(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	ctx := context.Background()
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	orphanPrefix := "my-skill/.agentctx/deployments/dep_20200101T000000Z_11223344/"
+	_ = tgt.Put(ctx, orphanPrefix+"files/file.txt", bytes.NewReader([]byte("partial")), target.PutOptions{})
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input := defaultDeployInput(b2)
+	input.PreviousDeployID = result1.DeploymentID
+	input.ManagedDeployIDs = []string{result1.DeploymentID}
+
+	// Orphans are left alone unless cleanup is requested.
+	result2 := deployToTarget(t, eng, tgt, input)
+	if len(result2.OrphansRemoved) != 0 || !objectExists(t, tgt, orphanPrefix+"files/file.txt") {
+		t.Fatal("orphan removed without CleanupOrphans")
+	}
+
+	input.PreviousDeployID = result2.DeploymentID
+	input.ManagedDeployIDs = []string{result1.DeploymentID, result2.DeploymentID}
+	input.CleanupOrphans = true
+	input.OrphanGracePeriod = time.Hour
+	result3 := deployToTarget(t, eng, tgt, input)
+	if result3.OrphanErr != nil {
+		t.Fatalf("orphan cleanup: %v", result3.OrphanErr)
+	}
+	if len(result3.OrphansRemoved) != 1 || objectExists(t, tgt, orphanPrefix+"files/file.txt") {
+		t.Errorf("orphan not removed: OrphansRemoved = %v", result3.OrphansRemoved)
+	}
+	for _, id := range []string{result1.DeploymentID, result2.DeploymentID, result3.DeploymentID} {
+		if !objectExists(t, tgt, "my-skill/.agentctx/deployments/"+id+"/manifest.json") {
+			t.Errorf("managed deployment %s removed", id)
+		}
+	}
+}
+
+// replicate copies every object under prefix from src to dst, as bucket
+// replication would.
+func replicate(t *testing.T, src, dst target.Target, prefix string) {
+	t.Helper()
+	ctx := context.Background()
+	objects, err := src.List(ctx, prefix)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, obj := range objects {
+		rc, _, err := src.Get(ctx, obj.Key)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err := dst.Put(ctx, obj.Key, bytes.NewReader(data), target.PutOptions{}); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+func TestVerifyReplica(t *testing.T) {
+	eng := newTestEngine()
+	primary := target.NewMemoryTarget("primary")
+	replica := target.NewMemoryTarget("replica")
+	ctx := context.Background()
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	result1 := deployToTarget(t, eng, primary, defaultDeployInput(b1))
+
+	err := eng.VerifyReplica(ctx, replica, "my-skill", result1.DeploymentID, 100*time.Millisecond)
+	if !errors.Is(err, engine.ErrReplicationLag) || !strings.Contains(err.Error(), "manifest") {
+		t.Fatalf("empty replica: err = %v, want ErrReplicationLag for the manifest", err)
+	}
+
+	replicate(t, primary, replica, "my-skill/")
+	if err := eng.VerifyReplica(ctx, replica, "my-skill", result1.DeploymentID, 0); err != nil {
+		t.Fatalf("replicated: %v", err)
+	}
+
+	// The replica still serves the first deployment until replication
+	// catches up with the second.
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input := defaultDeployInput(b2)
+	input.PreviousDeployID = result1.DeploymentID
+	result2 := deployToTarget(t, eng, primary, input)
+
+	err = eng.VerifyReplica(ctx, replica, "my-skill", result2.DeploymentID, 0)
+	if !errors.Is(err, engine.ErrReplicationLag) {
+		t.Fatalf("stale replica: err = %v, want ErrReplicationLag", err)
+	}
+
+	time.AfterFunc(150*time.Millisecond, func() { replicate(t, primary, replica, "my-skill/") })
+	if err := eng.VerifyReplica(ctx, replica, "my-skill", result2.DeploymentID, 5*time.Second); err != nil {
+		t.Fatalf("VerifyReplica did not wait for replication: %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Integration scenario tests
 // ---------------------------------------------------------------------------
@@ -0,0 +1,311 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/deployid"
+	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/provenance"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// IndexFormatVersion is the format_version of the deployment index. It
+// changes only when a field is removed or changes meaning.
+const IndexFormatVersion = 1
+
+// indexWriteAttempts is how often updateIndex retries a write that lost a
+// race with another writer.
+const indexWriteAttempts = 3
+
+// Index is the body of <skill>/.agentctx/index.json: a summary of every
+// deployment of the skill, so that callers listing a skill's history read
+// one small object instead of listing every object of every deployment.
+// Deploy adds to it; Prune, CleanupOrphans, and Destroy remove from it.
+//
+// The index is only an accelerator. A skill without one, e.g. deployed by
+// an older provider, is listed instead, and an index that cannot be
+// updated is deleted rather than left stale.
+type Index struct {
+	FormatVersion int `json:"format_version"`
+	// Deployments are sorted by deployment ID, which orders them by
+	// creation time to the second.
+	Deployments []IndexEntry `json:"deployments"`
+}
+
+// IndexEntry summarizes one deployment.
+type IndexEntry struct {
+	DeploymentID string `json:"deployment_id"`
+	// DeploymentAlias is the alias recorded in the deployment's manifest,
+	// if any.
+	DeploymentAlias string `json:"deployment_alias,omitempty"`
+	BundleHash      string `json:"bundle_hash"`
+	CreatedAt       string `json:"created_at"`
+	// Size is the total size in bytes of the deployment's bundle files.
+	Size int64 `json:"size"`
+	// IntegrityCheckedAt is when CheckIntegrity last read every file of
+	// the deployment, as recorded by RecordIntegrityCheck, in RFC 3339.
+	// IntegrityFailures lists the files that check found missing or
+	// modified.
+	IntegrityCheckedAt string   `json:"integrity_checked_at,omitempty"`
+	IntegrityFailures  []string `json:"integrity_failures,omitempty"`
+}
+
+// Deployments returns the deployments of skillName on tgt, sorted as in
+// Index, from the skill's index. Without an index it lists the deployments and
+// reads their manifests; deployments without a manifest, such as partial
+// uploads, are left out.
+func (e *Engine) Deployments(ctx context.Context, tgt target.Target, skillName string) ([]IndexEntry, error) {
+	idx, _, err := readIndex(ctx, tgt, skillName)
+	if err != nil {
+		return nil, fmt.Errorf("deployments: %w", err)
+	}
+	if idx == nil {
+		if idx, err = buildIndex(ctx, tgt, skillName); err != nil {
+			return nil, fmt.Errorf("deployments: %w", err)
+		}
+	}
+	return idx.Deployments, nil
+}
+
+// ResolveDeployment returns the ID of the deployment of skillName on tgt
+// that ref names: ref itself when it is a deployment ID, or else the newest
+// deployment whose alias is ref. An alias no deployment has yields an error
+// matching target.ErrNotFound.
+func (e *Engine) ResolveDeployment(ctx context.Context, tgt target.Target, skillName, ref string) (string, error) {
+	if _, err := deployid.Parse(ref); err == nil {
+		return ref, nil
+	}
+	entries, err := e.Deployments(ctx, tgt, skillName)
+	if err != nil {
+		return "", fmt.Errorf("resolve deployment: %w", err)
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].DeploymentAlias == ref {
+			return entries[i].DeploymentID, nil
+		}
+	}
+	return "", fmt.Errorf("resolve deployment: no deployment of %q has the alias %q: %w", skillName, ref, target.ErrNotFound)
+}
+
+// indexDeployment adds entry to the index of skillName, creating the index
+// from a listing of the existing deployments when there is none.
+func (e *Engine) indexDeployment(ctx context.Context, tgt target.Target, skillName string, entry IndexEntry) error {
+	return updateIndex(ctx, tgt, skillName, true, func(idx *Index) {
+		idx.Deployments = append(removeEntries(idx.Deployments, []string{entry.DeploymentID}), entry)
+	})
+}
+
+// unindexDeployments removes ids from the index of skillName, and the index
+// itself once it lists no deployments. Without an index there is nothing
+// to do: readers list the deployments instead.
+func (e *Engine) unindexDeployments(ctx context.Context, tgt target.Target, skillName string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return updateIndex(ctx, tgt, skillName, false, func(idx *Index) {
+		idx.Deployments = removeEntries(idx.Deployments, ids)
+	})
+}
+
+// updateIndex applies fn to the index of skillName and writes it back,
+// conditional on the index not having changed since it was read. A write
+// that loses a race with another writer is retried with the index that
+// writer left. When the index does not exist, create selects between
+// building it from a listing and leaving it absent. An index left without
+// deployments is deleted.
+//
+// If the index cannot be written it is deleted, so that readers list the
+// deployments instead of trusting a stale index, and nil is returned. An
+// error is returned only when the index could be neither written nor
+// deleted.
+func updateIndex(ctx context.Context, tgt target.Target, skillName string, create bool, fn func(*Index)) error {
+	key := indexKey(skillName)
+
+	var writeErr error
+	for attempt := 0; attempt < indexWriteAttempts; attempt++ {
+		idx, meta, err := readIndex(ctx, tgt, skillName)
+		if err != nil {
+			writeErr = err
+			break
+		}
+		condition := target.WriteCondition{IfMatch: meta.ETag, Generation: meta.Generation}
+		if idx == nil {
+			if !create {
+				return nil
+			}
+			if idx, err = buildIndex(ctx, tgt, skillName); err != nil {
+				writeErr = err
+				break
+			}
+			condition = target.WriteCondition{IfMatch: "*"}
+		}
+
+		fn(idx)
+		sort.Slice(idx.Deployments, func(i, j int) bool {
+			return idx.Deployments[i].DeploymentID < idx.Deployments[j].DeploymentID
+		})
+
+		if len(idx.Deployments) == 0 {
+			if err := tgt.Delete(ctx, key); err != nil && !errors.Is(err, target.ErrNotFound) {
+				return fmt.Errorf("delete index: %w", err)
+			}
+			return nil
+		}
+
+		body, err := json.MarshalIndent(idx, "", "  ")
+		if err != nil {
+			writeErr = err
+			break
+		}
+		writeErr = tgt.ConditionalPut(ctx, key, bytes.NewReader(body), condition, target.PutOptions{
+			ContentType: bundle.ContentTypeManifest,
+		})
+		var cme *target.ConcurrentModificationError
+		if writeErr == nil || !(errors.Is(writeErr, target.ErrPreconditionFailed) || errors.As(writeErr, &cme)) {
+			break
+		}
+	}
+	if writeErr == nil {
+		return nil
+	}
+
+	if err := tgt.Delete(ctx, key); err != nil && !errors.Is(err, target.ErrNotFound) {
+		return fmt.Errorf("update index: %w; delete stale index: %v", writeErr, err)
+	}
+	return nil
+}
+
+// readIndex returns the index of skillName and its object metadata, or a
+// nil index when there is none.
+func readIndex(ctx context.Context, tgt target.Target, skillName string) (*Index, target.ObjectMeta, error) {
+	rc, meta, err := tgt.Get(ctx, indexKey(skillName))
+	if err != nil {
+		if errors.Is(err, target.ErrNotFound) {
+			return nil, target.ObjectMeta{}, nil
+		}
+		return nil, target.ObjectMeta{}, fmt.Errorf("read index: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, target.ObjectMeta{}, fmt.Errorf("read index body: %w", err)
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, target.ObjectMeta{}, fmt.Errorf("parse index: %w", err)
+	}
+	if idx.FormatVersion > IndexFormatVersion {
+		return nil, target.ObjectMeta{}, fmt.Errorf("index format_version %d is newer than this provider supports (%d)", idx.FormatVersion, IndexFormatVersion)
+	}
+	return &idx, meta, nil
+}
+
+// buildIndex lists the deployments of skillName and reads their manifests
+// to build the index the skill would have had all along.
+func buildIndex(ctx context.Context, tgt target.Target, skillName string) (*Index, error) {
+	prefix := agentctxPrefix(skillName) + "deployments/"
+	objects, err := tgt.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list deployments: %w", err)
+	}
+
+	sizes := make(map[string]int64)
+	var ids []string
+	for _, obj := range objects {
+		depID, rel, _ := strings.Cut(strings.TrimPrefix(obj.Key, prefix), "/")
+		if _, ok := sizes[depID]; !ok {
+			ids = append(ids, depID)
+			sizes[depID] = 0
+		}
+		if rel != "manifest.json" && rel != provenance.FileName {
+			sizes[depID] += obj.Size
+		}
+	}
+	sort.Strings(ids)
+
+	idx := &Index{FormatVersion: IndexFormatVersion, Deployments: []IndexEntry{}}
+	for _, depID := range ids {
+		m, err := readManifest(ctx, tgt, skillName, depID)
+		if errors.Is(err, target.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		idx.Deployments = append(idx.Deployments, IndexEntry{
+			DeploymentID:    depID,
+			DeploymentAlias: m.DeploymentAlias,
+			BundleHash:      m.BundleHash,
+			CreatedAt:       m.CreatedAt,
+			Size:            sizes[depID],
+		})
+	}
+	return idx, nil
+}
+
+// readManifest reads the manifest of deployment depID. A missing manifest
+// yields an error matching target.ErrNotFound.
+func readManifest(ctx context.Context, tgt target.Target, skillName, depID string) (*manifest.Manifest, error) {
+	rc, _, err := tgt.Get(ctx, deploymentPrefix(skillName, depID)+"manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("read manifest of %q: %w", depID, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest of %q: %w", depID, err)
+	}
+	m, err := manifest.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal manifest of %q: %w", depID, err)
+	}
+	return m, nil
+}
+
+// newIndexEntry returns the index entry of the deployment depID of input,
+// whose manifest is manifestJSON.
+func newIndexEntry(input DeployInput, depID string, manifestJSON []byte) IndexEntry {
+	entry := IndexEntry{
+		DeploymentID:    depID,
+		DeploymentAlias: input.DeploymentAlias,
+		BundleHash:      input.Bundle.BundleHash,
+	}
+	if m, err := manifest.Unmarshal(manifestJSON); err == nil {
+		entry.CreatedAt = m.CreatedAt
+	}
+	for _, fe := range input.Bundle.Files {
+		size, _ := fileSize(input.SourceDir, fe)
+		entry.Size += size
+	}
+	return entry
+}
+
+// removeEntries returns entries without the deployments in ids.
+func removeEntries(entries []IndexEntry, ids []string) []IndexEntry {
+	drop := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		drop[id] = struct{}{}
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if _, ok := drop[e.DeploymentID]; !ok {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// indexKey returns the object key of the deployment index of a skill.
+func indexKey(skillName string) string {
+	return agentctxPrefix(skillName) + "index.json"
+}
@@ -0,0 +1,160 @@
+package engine_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+const indexKey = "my-skill/.agentctx/index.json"
+
+// deploymentIDs returns the sorted IDs of entries.
+func deploymentIDs(entries []engine.IndexEntry) []string {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.DeploymentID
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestDeploy_MaintainsIndex(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n", "main.py": "pass\n"})
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	second := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	var idx engine.Index
+	if err := json.Unmarshal(readObject(t, tgt, indexKey), &idx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{first.DeploymentID, second.DeploymentID}
+	slices.Sort(want)
+	if got := deploymentIDs(idx.Deployments); idx.FormatVersion != engine.IndexFormatVersion || !slices.Equal(got, want) {
+		t.Fatalf("index = %+v, want deployments %v", idx, want)
+	}
+	for _, e := range idx.Deployments {
+		if e.BundleHash != b.BundleHash || e.Size != int64(len("# Skill\n")+len("pass\n")) || e.CreatedAt == "" {
+			t.Errorf("entry = %+v, want bundle hash %s and size %d", e, b.BundleHash, len("# Skill\n")+len("pass\n"))
+		}
+	}
+
+	// Pruning and destroying remove entries, and the index with the last.
+	pruned, err := eng.Prune(ctx, tgt, "my-skill", second.DeploymentID, want, 0)
+	if err != nil || len(pruned) != 1 {
+		t.Fatalf("Prune = %v, %v", pruned, err)
+	}
+	entries, err := eng.Deployments(ctx, tgt, "my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := deploymentIDs(entries); !slices.Equal(got, []string{second.DeploymentID}) {
+		t.Errorf("after prune, deployments = %v, want %v", got, []string{second.DeploymentID})
+	}
+	if err := eng.Destroy(ctx, tgt, "my-skill", engine.DestroyOptions{ManagedDeployIDs: []string{second.DeploymentID}}); err != nil {
+		t.Fatal(err)
+	}
+	if objectExists(t, tgt, indexKey) {
+		t.Error("destroy left the index behind")
+	}
+}
+
+func TestDeployments_WithoutIndex(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n"})
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	// A skill deployed before the index existed.
+	if err := tgt.Delete(ctx, indexKey); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := eng.Deployments(ctx, tgt, "my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := deploymentIDs(entries); !slices.Equal(got, []string{first.DeploymentID}) || entries[0].Size != int64(len("# Skill\n")) {
+		t.Errorf("listed deployments = %+v, want %s", entries, first.DeploymentID)
+	}
+
+	// The next deploy builds the index from the listing.
+	second := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	var idx engine.Index
+	if err := json.Unmarshal(readObject(t, tgt, indexKey), &idx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{first.DeploymentID, second.DeploymentID}
+	slices.Sort(want)
+	if got := deploymentIDs(idx.Deployments); !slices.Equal(got, want) {
+		t.Errorf("rebuilt index lists %v, want %v", got, want)
+	}
+}
+
+func TestDeploy_IndexConflicts(t *testing.T) {
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n"})
+	deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	// A lost race is retried with the other writer's index.
+	tgt.SetFaults(target.FaultConfig{ConditionalPutConflicts: 1})
+	result := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	var idx engine.Index
+	if err := json.Unmarshal(readObject(t, tgt, indexKey), &idx); err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Deployments) != 2 || result.IndexErr != nil {
+		t.Errorf("after one conflict, index lists %d deployments, IndexErr %v; want 2, nil", len(idx.Deployments), result.IndexErr)
+	}
+
+	// An index that cannot be updated is removed rather than left stale.
+	tgt.SetFaults(target.FaultConfig{ConditionalPutConflicts: 10})
+	result = deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	if result.IndexErr != nil || objectExists(t, tgt, indexKey) {
+		t.Errorf("after repeated conflicts, IndexErr = %v, index exists = %v; want nil, false", result.IndexErr, objectExists(t, tgt, indexKey))
+	}
+}
+
+func TestResolveDeployment(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n"})
+	input := defaultDeployInput(b)
+	input.DeploymentAlias = "v1.0.0"
+	first := deployToTarget(t, eng, tgt, input)
+	input.DeploymentAlias = "v1.1.0"
+	second := deployToTarget(t, eng, tgt, input)
+
+	check := func(ref, want string) {
+		t.Helper()
+		got, err := eng.ResolveDeployment(ctx, tgt, "my-skill", ref)
+		if err != nil || got != want {
+			t.Errorf("ResolveDeployment(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	check("v1.0.0", first.DeploymentID)
+	check("v1.1.0", second.DeploymentID)
+	check(first.DeploymentID, first.DeploymentID)
+
+	// Aliases are read from the manifests without an index.
+	if err := tgt.Delete(ctx, indexKey); err != nil {
+		t.Fatal(err)
+	}
+	check("v1.0.0", first.DeploymentID)
+
+	if _, err := eng.ResolveDeployment(ctx, tgt, "my-skill", "v2.0.0"); !errors.Is(err, target.ErrNotFound) {
+		t.Errorf("ResolveDeployment of an unknown alias = %v, want target.ErrNotFound", err)
+	}
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// CheckIntegrity downloads every file of deployment depID and compares its
+// hash with the one recorded in the deployment's manifest. It returns the
+// relative paths of the files that are missing or whose content differs,
+// sorted. Unlike Refresh with deepCheck, which only checks that the files
+// exist, this reads the whole deployment.
+func (e *Engine) CheckIntegrity(ctx context.Context, tgt target.Target, skillName, depID string) ([]string, error) {
+	m, err := readManifest(ctx, tgt, skillName, depID)
+	if err != nil {
+		return nil, fmt.Errorf("check integrity: %w", err)
+	}
+
+	relPaths := make([]string, 0, len(m.Files))
+	for relPath := range m.Files {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+
+	failed := make([]bool, len(relPaths))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, relPath := range relPaths {
+		i, relPath := i, relPath
+		g.Go(func() error {
+			if err := e.sem.Acquire(gctx, concurrency.Read, 1); err != nil {
+				return err
+			}
+			defer e.sem.Release(concurrency.Read, 1)
+
+			key := deploymentPrefix(skillName, depID) + "files/" + relPath
+			rc, _, err := tgt.Get(gctx, key)
+			if err != nil {
+				if errors.Is(err, target.ErrNotFound) {
+					failed[i] = true
+					return nil
+				}
+				return fmt.Errorf("get %q: %w", key, err)
+			}
+			defer rc.Close()
+
+			hash, err := bundle.ComputeReaderHash(rc)
+			if err != nil {
+				return fmt.Errorf("hash %q: %w", key, err)
+			}
+			failed[i] = hash != m.Files[relPath]
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("check integrity of %q: %w", depID, err)
+	}
+
+	var bad []string
+	for i, relPath := range relPaths {
+		if failed[i] {
+			bad = append(bad, relPath)
+		}
+	}
+	return bad, nil
+}
+
+// IntegrityCheckedAt returns when the integrity of deployment depID was last
+// recorded as checked in the skill's index, or the zero time when it never
+// was or the skill has no index.
+func (e *Engine) IntegrityCheckedAt(ctx context.Context, tgt target.Target, skillName, depID string) (time.Time, error) {
+	idx, _, err := readIndex(ctx, tgt, skillName)
+	if err != nil || idx == nil {
+		return time.Time{}, err
+	}
+	for _, entry := range idx.Deployments {
+		if entry.DeploymentID != depID || entry.IntegrityCheckedAt == "" {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, entry.IntegrityCheckedAt)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse integrity_checked_at of %q: %w", depID, err)
+		}
+		return at, nil
+	}
+	return time.Time{}, nil
+}
+
+// RecordIntegrityCheck records in the skill's index that the integrity of
+// deployment depID was checked at at, and the files the check found
+// missing or modified. The index is created if the skill has none.
+func (e *Engine) RecordIntegrityCheck(ctx context.Context, tgt target.Target, skillName, depID string, at time.Time, failed []string) error {
+	return updateIndex(ctx, tgt, skillName, true, func(idx *Index) {
+		for i := range idx.Deployments {
+			if idx.Deployments[i].DeploymentID == depID {
+				idx.Deployments[i].IntegrityCheckedAt = at.UTC().Format(time.RFC3339)
+				idx.Deployments[i].IntegrityFailures = failed
+			}
+		}
+	})
+}
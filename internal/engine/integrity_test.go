@@ -0,0 +1,96 @@
+package engine_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{
+		"SKILL.md":  "# Skill\n",
+		"main.py":   "pass\n",
+		"sub/f3.md": "# Sub\n",
+	})
+	result := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	depID := result.DeploymentID
+
+	failed, err := eng.CheckIntegrity(ctx, tgt, "my-skill", depID)
+	if err != nil || len(failed) != 0 {
+		t.Fatalf("CheckIntegrity of an intact deployment = %v, %v; want none", failed, err)
+	}
+
+	// Same size, different content: a HEAD-based check would not notice.
+	filesPrefix := "my-skill/.agentctx/deployments/" + depID + "/files/"
+	if err := tgt.Put(ctx, filesPrefix+"main.py", strings.NewReader("PASS\n"), target.PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tgt.Delete(ctx, filesPrefix+"sub/f3.md"); err != nil {
+		t.Fatal(err)
+	}
+	failed, err = eng.CheckIntegrity(ctx, tgt, "my-skill", depID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"main.py", "sub/f3.md"}; !slices.Equal(failed, want) {
+		t.Errorf("CheckIntegrity = %v, want %v", failed, want)
+	}
+
+	if _, err := eng.CheckIntegrity(ctx, tgt, "my-skill", "dep_20260101T000000Z_00000000"); err == nil {
+		t.Error("CheckIntegrity of a missing deployment: expected an error")
+	}
+}
+
+func TestRecordIntegrityCheck(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n"})
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	second := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	at, err := eng.IntegrityCheckedAt(ctx, tgt, "my-skill", second.DeploymentID)
+	if err != nil || !at.IsZero() {
+		t.Fatalf("IntegrityCheckedAt before any check = %v, %v; want zero", at, err)
+	}
+
+	checked := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := eng.RecordIntegrityCheck(ctx, tgt, "my-skill", second.DeploymentID, checked, []string{"SKILL.md"}); err != nil {
+		t.Fatal(err)
+	}
+	at, err = eng.IntegrityCheckedAt(ctx, tgt, "my-skill", second.DeploymentID)
+	if err != nil || !at.Equal(checked) {
+		t.Errorf("IntegrityCheckedAt = %v, %v; want %v", at, err, checked)
+	}
+
+	entries, err := eng.Deployments(ctx, tgt, "my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		switch e.DeploymentID {
+		case first.DeploymentID:
+			if e.IntegrityCheckedAt != "" || e.IntegrityFailures != nil {
+				t.Errorf("unchecked entry = %+v, want no integrity fields", e)
+			}
+		case second.DeploymentID:
+			if e.IntegrityCheckedAt != "2026-03-01T12:00:00Z" || !slices.Equal(e.IntegrityFailures, []string{"SKILL.md"}) {
+				t.Errorf("checked entry = %+v", e)
+			}
+		}
+	}
+
+	// A skill without an index has never been checked.
+	if at, err := eng.IntegrityCheckedAt(ctx, tgt, "other-skill", second.DeploymentID); err != nil || !at.IsZero() {
+		t.Errorf("IntegrityCheckedAt without an index = %v, %v; want zero", at, err)
+	}
+}
@@ -0,0 +1,221 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/progress"
+	"github.com/agentctx/terraform-provider-agentctx/internal/receipts"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// Listener observes engine operations. Subsystems such as progress
+// reporting implement it and are registered with WithListeners instead of
+// being wired into Deploy and Prune.
+//
+// Methods are called synchronously by the goroutine doing the work, and
+// OnFileUploaded is called from many goroutines at once, so implementations
+// must be safe for concurrent use and return quickly. Embed NopListener to
+// handle only some events; events added later get no-op defaults there.
+type Listener interface {
+	// OnDeployStart is called once Deploy has chosen a deployment ID and
+	// before it uploads any file.
+	OnDeployStart(ctx context.Context, ev DeployStartEvent)
+	// OnFileUploaded is called after each bundle file is uploaded.
+	OnFileUploaded(ctx context.Context, ev FileUploadedEvent)
+	// OnDeployFinished is called when Deploy returns after OnDeployStart,
+	// whether it succeeded or not.
+	OnDeployFinished(ctx context.Context, ev DeployFinishedEvent)
+	// OnPruneDeleted is called after Prune, CleanupOrphans, or
+	// CollectExpiredPreviews deletes a deployment.
+	OnPruneDeleted(ctx context.Context, ev PruneDeletedEvent)
+}
+
+// DeployStartEvent describes a deployment about to be uploaded.
+type DeployStartEvent struct {
+	Target       string
+	SkillName    string
+	DeploymentID string
+	Files        int
+	// Bytes is the total size of the bundle files that could be stat'ed.
+	Bytes int64
+}
+
+// FileUploadedEvent describes one uploaded bundle file.
+type FileUploadedEvent struct {
+	Target       string
+	SkillName    string
+	DeploymentID string
+	RelPath      string
+	Size         int64
+}
+
+// DeployFinishedEvent describes the outcome of a deployment.
+type DeployFinishedEvent struct {
+	Target       string
+	SkillName    string
+	DeploymentID string
+	BundleHash   string
+	Duration     time.Duration
+	// Err is the error Deploy returned, or nil if ACTIVE now points at
+	// DeploymentID.
+	Err error
+}
+
+// PruneReason says why a deployment was deleted.
+type PruneReason string
+
+const (
+	// PruneRetention: the deployment was beyond the retention limit.
+	PruneRetention PruneReason = "retention"
+	// PruneOrphan: the deployment was an orphan no state referred to.
+	PruneOrphan PruneReason = "orphan"
+	// PruneExpired: the deployment was the active one of a preview whose
+	// expiry had passed.
+	PruneExpired PruneReason = "expired"
+)
+
+// PruneDeletedEvent describes a deleted deployment.
+type PruneDeletedEvent struct {
+	Target       string
+	SkillName    string
+	DeploymentID string
+	Reason       PruneReason
+}
+
+// NopListener implements Listener with methods that do nothing.
+type NopListener struct{}
+
+func (NopListener) OnDeployStart(context.Context, DeployStartEvent)       {}
+func (NopListener) OnFileUploaded(context.Context, FileUploadedEvent)     {}
+func (NopListener) OnDeployFinished(context.Context, DeployFinishedEvent) {}
+func (NopListener) OnPruneDeleted(context.Context, PruneDeletedEvent)     {}
+
+// WithListeners registers listeners for the Engine's events, in order.
+// Nil listeners are ignored.
+func WithListeners(ls ...Listener) Option {
+	return func(e *Engine) {
+		for _, l := range ls {
+			if l != nil {
+				e.listeners = append(e.listeners, l)
+			}
+		}
+	}
+}
+
+// emit calls fn for every registered listener.
+func (e *Engine) emit(fn func(Listener)) {
+	for _, l := range e.listeners {
+		fn(l)
+	}
+}
+
+// emitPruneDeleted reports a deployment deleted by Prune, CleanupOrphans,
+// or CollectExpiredPreviews.
+func (e *Engine) emitPruneDeleted(ctx context.Context, tgt target.Target, skillName, deploymentID string, reason PruneReason) {
+	ev := PruneDeletedEvent{
+		Target:       tgt.Name(),
+		SkillName:    skillName,
+		DeploymentID: deploymentID,
+		Reason:       reason,
+	}
+	e.emit(func(l Listener) { l.OnPruneDeleted(ctx, ev) })
+}
+
+// ---------------------------------------------------------------------------
+// Progress
+// ---------------------------------------------------------------------------
+
+// progressListener reports the upload progress of every deployment through
+// a progress.Reporter.
+type progressListener struct {
+	NopListener
+	r *progress.Reporter
+
+	mu       sync.Mutex
+	trackers map[string]*progress.Tracker // by target and deployment ID
+}
+
+// NewProgressListener returns a Listener that reports the upload progress
+// of deployments through r. It returns nil, which WithListeners ignores,
+// if r is nil.
+func NewProgressListener(r *progress.Reporter) Listener {
+	if r == nil {
+		return nil
+	}
+	return &progressListener{r: r, trackers: make(map[string]*progress.Tracker)}
+}
+
+func (p *progressListener) OnDeployStart(ctx context.Context, ev DeployStartEvent) {
+	t := p.r.Start(ctx, "deploy", map[string]string{
+		"skill_name": ev.SkillName,
+		"target":     ev.Target,
+	}, ev.Files, ev.Bytes)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.trackers[ev.Target+"/"+ev.DeploymentID] = t
+}
+
+func (p *progressListener) OnFileUploaded(_ context.Context, ev FileUploadedEvent) {
+	t := p.tracker(ev.Target, ev.DeploymentID, false)
+	t.AddBytes(ev.Size)
+	t.AddFiles(1)
+}
+
+func (p *progressListener) OnDeployFinished(_ context.Context, ev DeployFinishedEvent) {
+	p.tracker(ev.Target, ev.DeploymentID, true).Finish()
+}
+
+// tracker returns the tracker of a deployment, removing it if remove is
+// set. A missing tracker is returned as nil, which ignores every call.
+func (p *progressListener) tracker(targetName, deploymentID string, remove bool) *progress.Tracker {
+	key := targetName + "/" + deploymentID
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t := p.trackers[key]
+	if remove {
+		delete(p.trackers, key)
+	}
+	return t
+}
+
+// ---------------------------------------------------------------------------
+// Receipts
+// ---------------------------------------------------------------------------
+
+// receiptListener records the outcome of every deployment in a
+// receipts.Writer.
+type receiptListener struct {
+	NopListener
+	w *receipts.Writer
+}
+
+// NewReceiptListener returns a Listener that records a receipt in w for
+// every deployment, successful or not. It returns nil, which WithListeners
+// ignores, if w is nil.
+func NewReceiptListener(w *receipts.Writer) Listener {
+	if w == nil {
+		return nil
+	}
+	return &receiptListener{w: w}
+}
+
+func (l *receiptListener) OnDeployFinished(ctx context.Context, ev DeployFinishedEvent) {
+	r := receipts.Receipt{
+		Time:            time.Now().UTC(),
+		SkillName:       ev.SkillName,
+		Target:          ev.Target,
+		DeploymentID:    ev.DeploymentID,
+		BundleHash:      ev.BundleHash,
+		DurationSeconds: ev.Duration.Seconds(),
+		Status:          receipts.StatusSucceeded,
+	}
+	if ev.Err != nil {
+		r.Status = receipts.StatusFailed
+		r.Error = ev.Err.Error()
+	}
+	l.w.Record(ctx, r)
+}
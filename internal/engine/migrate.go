@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// Kinds of LayoutChange.
+const (
+	// LayoutManifestSchema is a manifest with a schema_version below
+	// manifest.SchemaVersion, or none. It is rewritten at the current
+	// version, with the fields older versions did not record filled in as
+	// Deploy would have.
+	LayoutManifestSchema = "manifest_schema"
+	// LayoutMissingIndex is a deployed skill without a deployment index. The
+	// index is built from the deployments' manifests.
+	LayoutMissingIndex = "missing_index"
+)
+
+// LayoutChange is an object of a skill stored in an older layout, and the
+// write that brings it to the current one.
+type LayoutChange struct {
+	SkillName string
+	// DeploymentID is empty for changes to the skill as a whole.
+	DeploymentID string
+	Kind         string
+	// Key is the object key the migration writes.
+	Key string
+}
+
+// LayoutSkills returns the names of the skills with objects under a
+// .agentctx/ prefix on tgt, sorted.
+func (e *Engine) LayoutSkills(ctx context.Context, tgt target.Target) ([]string, error) {
+	objects, err := tgt.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list skills: %w", err)
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, obj := range objects {
+		name, _, ok := strings.Cut(obj.Key, "/.agentctx/")
+		if !ok || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// PlanLayoutMigration returns the changes MigrateLayout would make to
+// skillName on tgt, without writing anything. Manifest changes come first,
+// sorted by deployment ID.
+func (e *Engine) PlanLayoutMigration(ctx context.Context, tgt target.Target, skillName string) ([]LayoutChange, error) {
+	ids, err := deploymentIDs(ctx, tgt, skillName)
+	if err != nil {
+		return nil, fmt.Errorf("plan layout migration: %w", err)
+	}
+
+	var changes []LayoutChange
+	var deployed bool
+	for _, depID := range ids {
+		m, err := readManifest(ctx, tgt, skillName, depID)
+		if errors.Is(err, target.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("plan layout migration: %w", err)
+		}
+		deployed = true
+		if m.SchemaVersion < manifest.SchemaVersion {
+			changes = append(changes, LayoutChange{
+				SkillName:    skillName,
+				DeploymentID: depID,
+				Kind:         LayoutManifestSchema,
+				Key:          deploymentPrefix(skillName, depID) + "manifest.json",
+			})
+		}
+	}
+
+	idx, _, err := readIndex(ctx, tgt, skillName)
+	if err != nil {
+		return nil, fmt.Errorf("plan layout migration: %w", err)
+	}
+	if idx == nil && deployed {
+		changes = append(changes, LayoutChange{
+			SkillName: skillName,
+			Kind:      LayoutMissingIndex,
+			Key:       indexKey(skillName),
+		})
+	}
+	return changes, nil
+}
+
+// MigrateLayout brings skillName on tgt to the current storage layout and
+// returns the changes it made, as PlanLayoutMigration reports them.
+// Manifests are rewritten conditional on not having changed since they
+// were read, so a migration racing a Deploy or Prune fails instead of
+// resurrecting a deleted deployment. Migrating a skill already in the
+// current layout writes nothing.
+func (e *Engine) MigrateLayout(ctx context.Context, tgt target.Target, skillName string) ([]LayoutChange, error) {
+	changes, err := e.PlanLayoutMigration(ctx, tgt, skillName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range changes {
+		switch c.Kind {
+		case LayoutManifestSchema:
+			err = migrateManifest(ctx, tgt, skillName, c.DeploymentID)
+		case LayoutMissingIndex:
+			err = migrateIndex(ctx, tgt, skillName)
+		}
+		if err != nil {
+			return changes[:i], fmt.Errorf("migrate layout of %q: %w", skillName, err)
+		}
+	}
+	return changes, nil
+}
+
+// migrateManifest rewrites the manifest of depID at manifest.SchemaVersion.
+// The deployment ID, resource type, and source hash were not recorded by
+// every older version; they are filled in with the values Deploy records.
+func migrateManifest(ctx context.Context, tgt target.Target, skillName, depID string) error {
+	key := deploymentPrefix(skillName, depID) + "manifest.json"
+	rc, meta, err := tgt.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("read manifest of %q: %w", depID, err)
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return fmt.Errorf("read manifest of %q: %w", depID, err)
+	}
+	m, err := manifest.Unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("unmarshal manifest of %q: %w", depID, err)
+	}
+	if m.SchemaVersion >= manifest.SchemaVersion {
+		return nil
+	}
+
+	m.SchemaVersion = manifest.SchemaVersion
+	if m.DeploymentID == "" {
+		m.DeploymentID = depID
+	}
+	if m.ResourceType == "" {
+		m.ResourceType = "skill"
+	}
+	if m.SourceHash == "" {
+		m.SourceHash = m.BundleHash
+	}
+	if m.Files == nil {
+		m.Files = map[string]string{}
+	}
+
+	body, err := manifest.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("marshal manifest of %q: %w", depID, err)
+	}
+	err = tgt.ConditionalPut(ctx, key, bytes.NewReader(body), target.WriteCondition{IfMatch: meta.ETag, Generation: meta.Generation}, target.PutOptions{
+		ContentType: bundle.ContentTypeManifest,
+		Metadata:    objectMetadata(m.Workspace, m.Environment),
+	})
+	if err != nil {
+		return fmt.Errorf("write manifest of %q: %w", depID, err)
+	}
+	return nil
+}
+
+// migrateIndex builds the deployment index of skillName. updateIndex
+// deletes an index it cannot write rather than fail, so the index is read
+// back to tell the migration apart from a no-op.
+func migrateIndex(ctx context.Context, tgt target.Target, skillName string) error {
+	if err := updateIndex(ctx, tgt, skillName, true, func(*Index) {}); err != nil {
+		return err
+	}
+	idx, _, err := readIndex(ctx, tgt, skillName)
+	if err != nil {
+		return err
+	}
+	if idx == nil {
+		return fmt.Errorf("index could not be written")
+	}
+	return nil
+}
+
+// deploymentIDs lists the IDs of the deployments of skillName, sorted,
+// including partial uploads without a manifest.
+func deploymentIDs(ctx context.Context, tgt target.Target, skillName string) ([]string, error) {
+	prefix := agentctxPrefix(skillName) + "deployments/"
+	objects, err := tgt.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list deployments: %w", err)
+	}
+	seen := make(map[string]bool)
+	var ids []string
+	for _, obj := range objects {
+		depID, _, _ := strings.Cut(strings.TrimPrefix(obj.Key, prefix), "/")
+		if !seen[depID] {
+			seen[depID] = true
+			ids = append(ids, depID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
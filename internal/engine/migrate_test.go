@@ -0,0 +1,96 @@
+package engine_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestMigrateLayout(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"SKILL.md": "# Skill\n"})
+	current := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	// A deployment with a v1 manifest, written before the index existed.
+	legacyKey := "my-skill/.agentctx/deployments/dep_20200101T000000Z_00000001/manifest.json"
+	legacy := `{"schema_version":1,"bundle_hash":"sha256:abc","created_at":"2020-01-01T00:00:00Z","files":{"SKILL.md":"sha256:def"}}`
+	if err := tgt.Put(ctx, legacyKey, strings.NewReader(legacy), target.PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tgt.Delete(ctx, indexKey); err != nil {
+		t.Fatal(err)
+	}
+
+	skills, err := eng.LayoutSkills(ctx, tgt)
+	if err != nil || !slices.Equal(skills, []string{"my-skill"}) {
+		t.Fatalf("LayoutSkills = %v, %v", skills, err)
+	}
+
+	planned, err := eng.PlanLayoutMigration(ctx, tgt, "my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []engine.LayoutChange{
+		{SkillName: "my-skill", DeploymentID: "dep_20200101T000000Z_00000001", Kind: engine.LayoutManifestSchema, Key: legacyKey},
+		{SkillName: "my-skill", Kind: engine.LayoutMissingIndex, Key: indexKey},
+	}
+	if !slices.Equal(planned, want) {
+		t.Fatalf("PlanLayoutMigration = %+v, want %+v", planned, want)
+	}
+	if objectExists(t, tgt, indexKey) {
+		t.Fatal("planning wrote the index")
+	}
+
+	migrated, err := eng.MigrateLayout(ctx, tgt, "my-skill")
+	if err != nil || !slices.Equal(migrated, want) {
+		t.Fatalf("MigrateLayout = %+v, %v", migrated, err)
+	}
+	m, err := manifest.Unmarshal(readObject(t, tgt, legacyKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SchemaVersion != manifest.SchemaVersion || m.DeploymentID != "dep_20200101T000000Z_00000001" || m.ResourceType != "skill" || m.SourceHash != "sha256:abc" || m.Files["SKILL.md"] != "sha256:def" {
+		t.Errorf("migrated manifest = %+v", m)
+	}
+	var idx engine.Index
+	if err := json.Unmarshal(readObject(t, tgt, indexKey), &idx); err != nil {
+		t.Fatal(err)
+	}
+	if got := deploymentIDs(idx.Deployments); !slices.Equal(got, []string{"dep_20200101T000000Z_00000001", current.DeploymentID}) {
+		t.Errorf("index deployments = %v", got)
+	}
+
+	// A skill in the current layout needs nothing.
+	if again, err := eng.MigrateLayout(ctx, tgt, "my-skill"); err != nil || len(again) != 0 {
+		t.Errorf("second MigrateLayout = %+v, %v", again, err)
+	}
+}
+
+func TestMigrateLayout_ManifestConflict(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	legacyKey := "my-skill/.agentctx/deployments/dep_20200101T000000Z_00000001/manifest.json"
+	if err := tgt.Put(ctx, legacyKey, strings.NewReader(`{"files":{}}`), target.PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	tgt.SetFaults(target.FaultConfig{ConditionalPutConflicts: 1})
+
+	migrated, err := eng.MigrateLayout(ctx, tgt, "my-skill")
+	if err == nil {
+		t.Fatal("expected a conflict error")
+	}
+	if len(migrated) != 0 {
+		t.Errorf("migrated = %+v, want none", migrated)
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// DeployPlan describes what Deploy would change on a target, as computed by
+// PlanDeploy. The file lists are bundle-relative paths, sorted, comparing
+// the bundle with the manifest of the deployment ACTIVE points to. Without
+// an ACTIVE deployment, or with its manifest missing, every file is added.
+type DeployPlan struct {
+	TargetName         string
+	ActiveDeploymentID string
+	Added              []string
+	Modified           []string
+	Removed            []string
+	Unchanged          []string
+
+	// Uploads and UploadBytes count the objects Deploy would write: every
+	// bundle file, since each deployment has its own prefix, plus the
+	// manifest, the provenance statement when requested, and ACTIVE
+	// unless the deployment is only staged.
+	Uploads     int
+	UploadBytes int64
+}
+
+// PlanDeploy returns what Deploy would change on tgt for input, without
+// writing anything: it only reads ACTIVE and the active manifest.
+func (e *Engine) PlanDeploy(ctx context.Context, tgt target.Target, input DeployInput) (*DeployPlan, error) {
+	current, err := e.Refresh(ctx, tgt, input.SkillName, "", false)
+	if err != nil {
+		return nil, fmt.Errorf("plan deploy: %w", err)
+	}
+
+	plan := &DeployPlan{
+		TargetName:         tgt.Name(),
+		ActiveDeploymentID: current.ActiveDeploymentID,
+		Uploads:            len(input.Bundle.Files) + 2,
+	}
+	if input.Provenance != nil {
+		plan.Uploads++
+	}
+	if input.StageOnly {
+		plan.Uploads--
+	}
+
+	var deployed map[string]string
+	if current.Manifest != nil {
+		deployed = current.Manifest.Files
+	}
+	for _, fe := range input.Bundle.Files {
+		size, _ := fileSize(input.SourceDir, fe)
+		plan.UploadBytes += size
+
+		hash, ok := deployed[fe.RelPath]
+		switch {
+		case !ok:
+			plan.Added = append(plan.Added, fe.RelPath)
+		case hash != input.Bundle.FileHashes[fe.RelPath]:
+			plan.Modified = append(plan.Modified, fe.RelPath)
+		default:
+			plan.Unchanged = append(plan.Unchanged, fe.RelPath)
+		}
+	}
+	for relPath := range deployed {
+		if _, ok := input.Bundle.FileHashes[relPath]; !ok {
+			plan.Removed = append(plan.Removed, relPath)
+		}
+	}
+
+	sort.Strings(plan.Added)
+	sort.Strings(plan.Modified)
+	sort.Strings(plan.Removed)
+	sort.Strings(plan.Unchanged)
+	return plan, nil
+}
@@ -0,0 +1,63 @@
+package engine_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestPlanDeploy(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	// Without a deployment, every file is added.
+	first := createTempBundle(t, map[string]string{
+		"SKILL.md":  "# Skill\n",
+		"main.py":   "print('v1')\n",
+		"legacy.py": "print('old')\n",
+	})
+	plan, err := eng.PlanDeploy(ctx, tgt, defaultDeployInput(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Added) != 3 || plan.ActiveDeploymentID != "" || plan.Uploads != 5 {
+		t.Errorf("plan = %+v, want 3 added files and 5 uploads", plan)
+	}
+	result := deployToTarget(t, eng, tgt, defaultDeployInput(first))
+
+	second := createTempBundle(t, map[string]string{
+		"SKILL.md": "# Skill\n",
+		"main.py":  "print('v2')\n",
+		"util.py":  "pass\n",
+	})
+	before, err := tgt.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err = eng.PlanDeploy(ctx, tgt, defaultDeployInput(second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := tgt.List(ctx, ""); len(after) != len(before) {
+		t.Error("PlanDeploy wrote to the target")
+	}
+	if plan.ActiveDeploymentID != result.DeploymentID {
+		t.Errorf("ActiveDeploymentID = %q, want %q", plan.ActiveDeploymentID, result.DeploymentID)
+	}
+	for name, c := range map[string]struct{ got, want []string }{
+		"added":     {plan.Added, []string{"util.py"}},
+		"modified":  {plan.Modified, []string{"main.py"}},
+		"removed":   {plan.Removed, []string{"legacy.py"}},
+		"unchanged": {plan.Unchanged, []string{"SKILL.md"}},
+	} {
+		if !slices.Equal(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", name, c.got, c.want)
+		}
+	}
+	if want := int64(len("# Skill\n") + len("print('v2')\n") + len("pass\n")); plan.UploadBytes != want {
+		t.Errorf("UploadBytes = %d, want %d", plan.UploadBytes, want)
+	}
+}
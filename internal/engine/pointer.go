@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// PointerEntry is one deployment an ACTIVE pointer refers to, with the
+// percentage of consumers that should load it.
+type PointerEntry struct {
+	DeploymentID string
+	Weight       int
+}
+
+// ParsePointer parses the body of an ACTIVE pointer. Two layouts exist:
+//
+//   - plain, the default: a single deployment ID, which parses as that
+//     deployment with weight 100;
+//   - weighted, written for canary rollouts: one "<deployment_id> <weight>"
+//     line per deployment, with integer weights summing to 100. The first
+//     line is the canary, the newest deployment; the last is the stable
+//     deployment it is rolled out against.
+//
+// An empty body parses as no entries.
+func ParsePointer(data []byte) ([]PointerEntry, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) == 1 {
+		fields := strings.Fields(lines[0])
+		switch len(fields) {
+		case 0:
+			return nil, nil
+		case 1:
+			return []PointerEntry{{DeploymentID: fields[0], Weight: 100}}, nil
+		}
+	}
+
+	entries := make([]PointerEntry, 0, len(lines))
+	total := 0
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("ACTIVE line %d: want \"<deployment_id> <weight>\", got %q", i+1, line)
+		}
+		weight, err := strconv.Atoi(fields[1])
+		if err != nil || weight < 0 || weight > 100 {
+			return nil, fmt.Errorf("ACTIVE line %d: weight %q is not an integer between 0 and 100", i+1, fields[1])
+		}
+		entries = append(entries, PointerEntry{DeploymentID: fields[0], Weight: weight})
+		total += weight
+	}
+	if total != 100 {
+		return nil, fmt.Errorf("ACTIVE weights sum to %d, want 100", total)
+	}
+	return entries, nil
+}
+
+// FormatPointer renders entries as an ACTIVE pointer body: the plain layout
+// for a single entry, the weighted layout otherwise. See ParsePointer.
+func FormatPointer(entries []PointerEntry) []byte {
+	if len(entries) == 1 {
+		return []byte(entries[0].DeploymentID)
+	}
+	var b bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s %d\n", e.DeploymentID, e.Weight)
+	}
+	return b.Bytes()
+}
+
+// canaryPointer returns the entries of a weighted pointer that sends weight
+// percent of consumers to canaryID and the rest to the stable deployment
+// of previous, the pointer it replaces: its last entry. Without a previous
+// pointer there is nothing to roll out against, so canaryID gets all of the
+// traffic.
+func canaryPointer(canaryID string, weight int, previous []PointerEntry) []PointerEntry {
+	if len(previous) == 0 {
+		return []PointerEntry{{DeploymentID: canaryID, Weight: 100}}
+	}
+	stable := previous[len(previous)-1].DeploymentID
+	if stable == canaryID {
+		return []PointerEntry{{DeploymentID: canaryID, Weight: 100}}
+	}
+	return []PointerEntry{
+		{DeploymentID: canaryID, Weight: weight},
+		{DeploymentID: stable, Weight: 100 - weight},
+	}
+}
+
+// firstDeploymentID returns the deployment of the first entry: the only
+// one of a plain pointer and the canary of a weighted one. It returns ""
+// for no entries.
+func firstDeploymentID(entries []PointerEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	return entries[0].DeploymentID
+}
+
+// stableDeploymentID returns the stable deployment of a weighted pointer,
+// or "" for a plain one.
+func stableDeploymentID(entries []PointerEntry) string {
+	if len(entries) < 2 {
+		return ""
+	}
+	return entries[len(entries)-1].DeploymentID
+}
+
+// readActivePointer reads and parses the ACTIVE pointer of skillName and
+// returns its entries together with the pointer's object metadata. It
+// returns no entries and a nil error if ACTIVE does not exist.
+func readActivePointer(ctx context.Context, tgt target.Target, skillName string) ([]PointerEntry, target.ObjectMeta, error) {
+	rc, meta, err := tgt.Get(ctx, activePointerKey(skillName))
+	if err != nil {
+		if errors.Is(err, target.ErrNotFound) {
+			return nil, target.ObjectMeta{}, nil
+		}
+		return nil, target.ObjectMeta{}, fmt.Errorf("read ACTIVE: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, target.ObjectMeta{}, fmt.Errorf("read ACTIVE body: %w", err)
+	}
+	entries, err := ParsePointer(data)
+	if err != nil {
+		return nil, target.ObjectMeta{}, err
+	}
+	return entries, meta, nil
+}
+
+// pointerIDs returns the set of deployment IDs entries refer to.
+func pointerIDs(entries []PointerEntry) map[string]struct{} {
+	ids := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		ids[e.DeploymentID] = struct{}{}
+	}
+	return ids
+}
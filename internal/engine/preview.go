@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// previewsPrefix is the top-level prefix under which preview deployments
+// are kept apart from the skills they preview.
+const previewsPrefix = "previews/"
+
+// PreviewSkillName returns the name under which the engine stores preview
+// previewID of skillName: previews/<previewID>/<skillName>. Every engine
+// method accepts it in place of a skill name, so a preview has its own
+// ACTIVE pointer, deployments, and reference markers.
+func PreviewSkillName(previewID, skillName string) string {
+	return previewsPrefix + previewID + "/" + skillName
+}
+
+// CollectExpiredPreviews deletes every preview of skillName on tgt whose
+// active manifest records an expiry before now, and returns the IDs of the
+// removed previews, sorted. The whole previews/<id>/<skillName>/ prefix of
+// an expired preview is deleted. Previews without an expiry, without a
+// readable manifest, or with reference markers are left alone.
+func (e *Engine) CollectExpiredPreviews(ctx context.Context, tgt target.Target, skillName string, now time.Time) ([]string, error) {
+	objects, err := tgt.List(ctx, previewsPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list previews: %w", err)
+	}
+
+	suffix := "/" + activePointerKey(skillName)
+	var previewIDs []string
+	for _, obj := range objects {
+		id, ok := strings.CutSuffix(strings.TrimPrefix(obj.Key, previewsPrefix), suffix)
+		if !ok || id == "" || strings.Contains(id, "/") {
+			continue
+		}
+		previewIDs = append(previewIDs, id)
+	}
+	sort.Strings(previewIDs)
+
+	var removed []string
+	for _, id := range previewIDs {
+		name := PreviewSkillName(id, skillName)
+		result, err := e.Refresh(ctx, tgt, name, "", false)
+		if err != nil {
+			return removed, fmt.Errorf("read preview %q: %w", id, err)
+		}
+		if result.Manifest == nil || result.Manifest.ExpiresAt == "" {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, result.Manifest.ExpiresAt)
+		if err != nil || expiresAt.After(now) {
+			continue
+		}
+		consumers, err := e.References(ctx, tgt, name)
+		if err != nil {
+			return removed, err
+		}
+		if len(consumers) > 0 {
+			continue
+		}
+		if err := e.forceDestroy(ctx, tgt, name, true); err != nil {
+			return removed, fmt.Errorf("delete preview %q: %w", id, err)
+		}
+		removed = append(removed, id)
+		e.emitPruneDeleted(ctx, tgt, name, result.ActiveDeploymentID, PruneExpired)
+	}
+	return removed, nil
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// PromoteResult describes the ACTIVE pointer Promote left in place.
+type PromoteResult struct {
+	// PreviousDeploymentID is the deployment ACTIVE selected before the
+	// promotion, or "" when the skill had no ACTIVE pointer.
+	PreviousDeploymentID string
+
+	// ACTIVE object metadata after the promotion, for use as the write
+	// condition of the next deploy.
+	ActiveETag       string
+	ActiveGeneration int64
+}
+
+// Promote moves the ACTIVE pointer of skillName on tgt to the deployment
+// depID, typically one uploaded by a Deploy with DeployInput.StageOnly set.
+// The deployment's manifest must exist. ACTIVE is replaced by a plain
+// pointer in a single write conditioned on the pointer Promote read, so a
+// pointer moved by someone else in the meantime is left alone and reported
+// as ErrActiveModified. Promoting the deployment ACTIVE already points at
+// alone writes nothing.
+func (e *Engine) Promote(ctx context.Context, tgt target.Target, skillName, depID string) (*PromoteResult, error) {
+	if _, err := readManifest(ctx, tgt, skillName, depID); err != nil {
+		return nil, fmt.Errorf("engine: promote: %w", err)
+	}
+
+	entries, meta, err := readActivePointer(ctx, tgt, skillName)
+	if err != nil {
+		return nil, fmt.Errorf("engine: promote: %w", err)
+	}
+	result := &PromoteResult{PreviousDeploymentID: firstDeploymentID(entries)}
+	if len(entries) == 1 && entries[0].DeploymentID == depID {
+		result.ActiveETag = meta.ETag
+		result.ActiveGeneration = meta.Generation
+		return result, nil
+	}
+
+	condition := target.WriteCondition{IfMatch: meta.ETag, Generation: meta.Generation}
+	if len(entries) == 0 {
+		// No pointer yet: create it only if it still does not exist.
+		condition = target.WriteCondition{IfMatch: "*"}
+	}
+	activeKey := activePointerKey(skillName)
+	opts := target.PutOptions{ContentType: bundle.ContentTypeACTIVE}
+	if err := tgt.ConditionalPut(ctx, activeKey, bytes.NewReader([]byte(depID)), condition, opts); err != nil {
+		var cme *target.ConcurrentModificationError
+		if errors.Is(err, target.ErrPreconditionFailed) || errors.As(err, &cme) {
+			return nil, fmt.Errorf("engine: promote: %w: %s", ErrActiveModified, err)
+		}
+		return nil, fmt.Errorf("engine: promote: conditional put ACTIVE: %w", err)
+	}
+
+	written, err := headActivePointer(ctx, tgt, activeKey, 0)
+	if err != nil {
+		return nil, fmt.Errorf("engine: promote: %w", err)
+	}
+	result.ActiveETag = written.ETag
+	result.ActiveGeneration = written.Generation
+	return result, nil
+}
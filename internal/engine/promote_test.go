@@ -0,0 +1,148 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestDeploy_StageOnlyLeavesActive(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input := defaultDeployInput(b2)
+	input.StageOnly = true
+	staged := deployToTarget(t, eng, tgt, input)
+
+	if !staged.Staged || staged.ActiveETag != "" {
+		t.Errorf("Staged = %v, ActiveETag = %q; want a staged result without ACTIVE metadata", staged.Staged, staged.ActiveETag)
+	}
+	if got := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE"))); got != first.DeploymentID {
+		t.Errorf("ACTIVE = %q, want it left at %q", got, first.DeploymentID)
+	}
+	if !objectExists(t, tgt, "my-skill/.agentctx/deployments/"+staged.DeploymentID+"/files/file.txt") {
+		t.Error("staged deployment files were not uploaded")
+	}
+
+	entries, err := eng.Deployments(ctx, tgt, "my-skill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("index has %d entries, want the active and the staged deployment", len(entries))
+	}
+}
+
+func TestPromote(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b1 := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(b1))
+
+	b2 := createTempBundle(t, map[string]string{"file.txt": "version 2"})
+	input := defaultDeployInput(b2)
+	input.StageOnly = true
+	staged := deployToTarget(t, eng, tgt, input)
+
+	result, err := eng.Promote(ctx, tgt, "my-skill", staged.DeploymentID)
+	if err != nil {
+		t.Fatalf("Promote: %v", err)
+	}
+	if result.PreviousDeploymentID != first.DeploymentID {
+		t.Errorf("PreviousDeploymentID = %q, want %q", result.PreviousDeploymentID, first.DeploymentID)
+	}
+	if got := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE"))); got != staged.DeploymentID {
+		t.Errorf("ACTIVE = %q, want %q", got, staged.DeploymentID)
+	}
+	refresh, err := eng.Refresh(ctx, tgt, "my-skill", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refresh.ActiveETag != result.ActiveETag {
+		t.Errorf("ActiveETag = %q, want the promoted pointer's %q", result.ActiveETag, refresh.ActiveETag)
+	}
+
+	// Promoting it again writes nothing.
+	again, err := eng.Promote(ctx, tgt, "my-skill", staged.DeploymentID)
+	if err != nil {
+		t.Fatalf("Promote again: %v", err)
+	}
+	if again.ActiveETag != result.ActiveETag || again.PreviousDeploymentID != staged.DeploymentID {
+		t.Errorf("second Promote = %+v, want ACTIVE unchanged", again)
+	}
+}
+
+func TestPromote_FirstDeployment(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	input := defaultDeployInput(b)
+	input.StageOnly = true
+	staged := deployToTarget(t, eng, tgt, input)
+	if objectExists(t, tgt, "my-skill/.agentctx/ACTIVE") {
+		t.Fatal("a staged first deployment must not create ACTIVE")
+	}
+
+	result, err := eng.Promote(ctx, tgt, "my-skill", staged.DeploymentID)
+	if err != nil {
+		t.Fatalf("Promote: %v", err)
+	}
+	if result.PreviousDeploymentID != "" {
+		t.Errorf("PreviousDeploymentID = %q, want none", result.PreviousDeploymentID)
+	}
+	if got := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE"))); got != staged.DeploymentID {
+		t.Errorf("ACTIVE = %q, want %q", got, staged.DeploymentID)
+	}
+}
+
+func TestPromote_MissingDeployment(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+
+	if _, err := eng.Promote(ctx, tgt, "my-skill", "dep_20260101T000000Z_00000000"); err == nil {
+		t.Fatal("Promote of a missing deployment: expected an error")
+	}
+	if got := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE"))); got != first.DeploymentID {
+		t.Errorf("ACTIVE = %q, want it left at %q", got, first.DeploymentID)
+	}
+}
+
+func TestPromote_ActiveModifiedConcurrently(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	b := createTempBundle(t, map[string]string{"file.txt": "version 1"})
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(b))
+	input := defaultDeployInput(b)
+	input.StageOnly = true
+	staged := deployToTarget(t, eng, tgt, input)
+
+	// The ACTIVE swap loses a race it never sees.
+	tgt.SetFaults(target.FaultConfig{ConditionalPutConflicts: 1})
+
+	_, err := eng.Promote(ctx, tgt, "my-skill", staged.DeploymentID)
+	if !errors.Is(err, engine.ErrActiveModified) {
+		t.Fatalf("Promote error = %v, want ErrActiveModified", err)
+	}
+	if got := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE"))); got != first.DeploymentID {
+		t.Errorf("ACTIVE = %q, want it left at %q", got, first.DeploymentID)
+	}
+}
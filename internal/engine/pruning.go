@@ -7,20 +7,30 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
 	"github.com/agentctx/terraform-provider-agentctx/internal/deployid"
 	"github.com/agentctx/terraform-provider-agentctx/internal/target"
 )
 
 // Prune removes old deployments beyond the retention limit per spec section 11.2.
 //
-// It filters managedDeployIDs to exclude activeDeployID, sorts the remainder
-// by timestamp (oldest first), and deletes those beyond the retain count.
+// It filters managedDeployIDs to exclude activeDeployID and every other
+// deployment the ACTIVE pointer refers to, such as the stable deployment of
+// a canary rollout, sorts the remainder by timestamp (oldest first), and
+// deletes those beyond the retain count.
 // Returns the list of deployment IDs that were pruned.
 func (e *Engine) Prune(ctx context.Context, tgt target.Target, skillName string, activeDeployID string, managedDeployIDs []string, retain int) (pruned []string, err error) {
-	// Step 1: Filter managedDeployIDs to exclude activeDeployID.
+	// Step 1: Filter managedDeployIDs to exclude the live deployments.
+	entries, _, err := readActivePointer(ctx, tgt, skillName)
+	if err != nil {
+		return nil, fmt.Errorf("prune: %w", err)
+	}
+	live := pointerIDs(entries)
+	live[activeDeployID] = struct{}{}
+
 	candidates := make([]string, 0, len(managedDeployIDs))
 	for _, id := range managedDeployIDs {
-		if id != activeDeployID {
+		if _, ok := live[id]; !ok {
 			candidates = append(candidates, id)
 		}
 	}
@@ -63,11 +73,18 @@ func (e *Engine) Prune(ctx context.Context, tgt target.Target, skillName string,
 	// Step 4: Delete each deployment to prune.
 	for _, dp := range toPrune {
 		if err := e.deleteDeployment(ctx, tgt, skillName, dp.id); err != nil {
+			// Drop what was already deleted from the index; the error to
+			// report is the failed delete.
+			_ = e.unindexDeployments(ctx, tgt, skillName, pruned)
 			return pruned, fmt.Errorf("prune deployment %q: %w", dp.id, err)
 		}
 		pruned = append(pruned, dp.id)
+		e.emitPruneDeleted(ctx, tgt, skillName, dp.id, PruneRetention)
 	}
 
+	if err := e.unindexDeployments(ctx, tgt, skillName, pruned); err != nil {
+		return pruned, fmt.Errorf("prune: %w", err)
+	}
 	return pruned, nil
 }
 
@@ -85,10 +102,10 @@ func (e *Engine) deleteDeployment(ctx context.Context, tgt target.Target, skillN
 	for _, obj := range objects {
 		obj := obj
 		g.Go(func() error {
-			if err := e.sem.Acquire(gctx, 1); err != nil {
+			if err := e.sem.Acquire(gctx, concurrency.Write, 1); err != nil {
 				return err
 			}
-			defer e.sem.Release(1)
+			defer e.sem.Release(concurrency.Write, 1)
 
 			if err := tgt.Delete(gctx, obj.Key); err != nil {
 				return fmt.Errorf("delete %q: %w", obj.Key, err)
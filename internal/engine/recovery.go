@@ -8,10 +8,15 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/deployid"
 	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
 	"github.com/agentctx/terraform-provider-agentctx/internal/target"
 )
@@ -31,10 +36,10 @@ func (e *Engine) CleanupStaged(ctx context.Context, tgt target.Target, skillName
 	for _, obj := range objects {
 		obj := obj
 		g.Go(func() error {
-			if err := e.sem.Acquire(gctx, 1); err != nil {
+			if err := e.sem.Acquire(gctx, concurrency.Write, 1); err != nil {
 				return err
 			}
-			defer e.sem.Release(1)
+			defer e.sem.Release(concurrency.Write, 1)
 
 			if err := tgt.Delete(gctx, obj.Key); err != nil {
 				return fmt.Errorf("delete staged object %q: %w", obj.Key, err)
@@ -46,6 +51,63 @@ func (e *Engine) CleanupStaged(ctx context.Context, tgt target.Target, skillName
 	return g.Wait()
 }
 
+// CleanupOrphans deletes deployments of skillName that neither ACTIVE nor
+// keep refers to and whose deployment ID is at least grace old, and returns
+// their IDs, sorted. These are typically partial uploads of runs whose
+// staged ID never reached state. Prefixes that are not deployment IDs are
+// left alone, and the grace period protects deploys still in flight in
+// other processes.
+func (e *Engine) CleanupOrphans(ctx context.Context, tgt target.Target, skillName string, keep []string, grace time.Duration) ([]string, error) {
+	prefix := agentctxPrefix(skillName) + "deployments/"
+	objects, err := tgt.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("list deployments: %w", err)
+	}
+
+	entries, _, err := readActivePointer(ctx, tgt, skillName)
+	if err != nil {
+		return nil, err
+	}
+	referenced := pointerIDs(entries)
+	for _, id := range keep {
+		referenced[id] = struct{}{}
+	}
+
+	now := time.Now()
+	seen := make(map[string]struct{})
+	var orphans []string
+	for _, obj := range objects {
+		depID, _, _ := strings.Cut(strings.TrimPrefix(obj.Key, prefix), "/")
+		if _, ok := seen[depID]; ok {
+			continue
+		}
+		seen[depID] = struct{}{}
+		if _, ok := referenced[depID]; ok {
+			continue
+		}
+		created, err := deployid.Parse(depID)
+		if err != nil || now.Sub(created) < grace {
+			continue
+		}
+		orphans = append(orphans, depID)
+	}
+	sort.Strings(orphans)
+
+	var removed []string
+	for _, depID := range orphans {
+		if err := e.deleteDeployment(ctx, tgt, skillName, depID); err != nil {
+			_ = e.unindexDeployments(ctx, tgt, skillName, removed)
+			return removed, fmt.Errorf("delete orphaned deployment %q: %w", depID, err)
+		}
+		removed = append(removed, depID)
+		e.emitPruneDeleted(ctx, tgt, skillName, depID, PruneOrphan)
+	}
+	if err := e.unindexDeployments(ctx, tgt, skillName, removed); err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
+
 // Refresh reads the current state of a skill from a target and returns
 // a RefreshResult describing its health and drift status.
 //
@@ -57,17 +119,21 @@ func (e *Engine) Refresh(ctx context.Context, tgt target.Target, skillName strin
 	}
 
 	// Step 1: Read ACTIVE to get the deployment ID.
-	activeDepID, err := readActiveDeploymentID(ctx, tgt, skillName)
+	entries, activeMeta, err := readActivePointer(ctx, tgt, skillName)
 	if err != nil {
 		return nil, fmt.Errorf("refresh: %w", err)
 	}
 
 	// Step 2: If ACTIVE doesn't exist, return empty result (no deployment).
-	if activeDepID == "" {
+	if len(entries) == 0 {
 		return result, nil
 	}
 
+	activeDepID := entries[0].DeploymentID
 	result.ActiveDeploymentID = activeDepID
+	result.StableDeploymentID = stableDeploymentID(entries)
+	result.ActiveETag = activeMeta.ETag
+	result.ActiveGeneration = activeMeta.Generation
 
 	// Step 3: Read the manifest at the expected path.
 	manifestKey := deploymentPrefix(skillName, activeDepID) + "manifest.json"
@@ -136,10 +202,10 @@ func (e *Engine) checkFiles(ctx context.Context, tgt target.Target, skillName st
 	for i := range results {
 		i := i
 		g.Go(func() error {
-			if err := e.sem.Acquire(gctx, 1); err != nil {
+			if err := e.sem.Acquire(gctx, concurrency.Read, 1); err != nil {
 				return err
 			}
-			defer e.sem.Release(1)
+			defer e.sem.Release(concurrency.Read, 1)
 
 			key := deploymentPrefix(skillName, deploymentID) + "files/" + results[i].relPath
 			_, err := tgt.Head(gctx, key)
@@ -195,10 +261,11 @@ func (e *Engine) Repair(ctx context.Context, tgt target.Target, skillName string
 		}
 
 		g.Go(func() error {
-			if err := e.sem.Acquire(gctx, 1); err != nil {
+			weight := e.uploadWeight(b.SourceDir, fe)
+			if err := e.sem.Acquire(gctx, concurrency.Write, weight); err != nil {
 				return err
 			}
-			defer e.sem.Release(1)
+			defer e.sem.Release(concurrency.Write, weight)
 
 			content, err := readRepairFileContent(b, fe)
 			if err != nil {
@@ -210,6 +277,7 @@ func (e *Engine) Repair(ctx context.Context, tgt target.Target, skillName string
 
 			if err := tgt.Put(gctx, key, bytes.NewReader(content), target.PutOptions{
 				ContentType: ct,
+				Metadata:    objectMetadata(m.Workspace, m.Environment),
 			}); err != nil {
 				return fmt.Errorf("repair: put %q: %w", key, err)
 			}
@@ -231,6 +299,7 @@ func (e *Engine) Repair(ctx context.Context, tgt target.Target, skillName string
 		}
 		if err := tgt.Put(ctx, manifestKey, bytes.NewReader(manifestJSON), target.PutOptions{
 			ContentType: bundle.ContentTypeManifest,
+			Metadata:    objectMetadata(m.Workspace, m.Environment),
 		}); err != nil {
 			return fmt.Errorf("repair: put manifest: %w", err)
 		}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// ErrReferenced is returned by Destroy when consumers still hold references
+// to the skill and ForceDestroy is not set.
+var ErrReferenced = errors.New("skill is referenced by other consumers")
+
+// Reference is the body of a reference marker. A consumer, typically
+// another Terraform stack, writes one under <skill>/.agentctx/refs/ to
+// declare that it depends on the skill, and removes it when it no longer
+// does. Destroy refuses to remove a skill that has reference markers unless
+// forced.
+type Reference struct {
+	Consumer string `json:"consumer"`
+	// DeploymentID is the deployment ACTIVE pointed at when the reference
+	// was written.
+	DeploymentID string `json:"deployment_id,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// AddReference writes the reference marker of ref.Consumer, replacing any
+// existing marker of the same consumer.
+func (e *Engine) AddReference(ctx context.Context, tgt target.Target, skillName string, ref Reference) error {
+	data, err := json.MarshalIndent(ref, "", "  ")
+	if err != nil {
+		return fmt.Errorf("engine: marshal reference: %w", err)
+	}
+	key := ReferenceKey(skillName, ref.Consumer)
+	if err := tgt.Put(ctx, key, bytes.NewReader(data), target.PutOptions{
+		ContentType: "application/json",
+	}); err != nil {
+		return fmt.Errorf("engine: put reference %q: %w", key, err)
+	}
+	return nil
+}
+
+// ReadReference reads the reference marker of consumer. A missing marker is
+// reported as an error wrapping target.ErrNotFound.
+func (e *Engine) ReadReference(ctx context.Context, tgt target.Target, skillName, consumer string) (*Reference, error) {
+	key := ReferenceKey(skillName, consumer)
+	rc, _, err := tgt.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("engine: get reference %q: %w", key, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("engine: read reference %q: %w", key, err)
+	}
+	var ref Reference
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return nil, fmt.Errorf("engine: parse reference %q: %w", key, err)
+	}
+	return &ref, nil
+}
+
+// RemoveReference deletes the reference marker of consumer. Removing a
+// marker that does not exist is not an error.
+func (e *Engine) RemoveReference(ctx context.Context, tgt target.Target, skillName, consumer string) error {
+	key := ReferenceKey(skillName, consumer)
+	if err := tgt.Delete(ctx, key); err != nil && !errors.Is(err, target.ErrNotFound) {
+		return fmt.Errorf("engine: delete reference %q: %w", key, err)
+	}
+	return nil
+}
+
+// References returns the sorted names of the consumers holding a reference
+// marker on skillName.
+func (e *Engine) References(ctx context.Context, tgt target.Target, skillName string) ([]string, error) {
+	prefix := referencesPrefix(skillName)
+	objects, err := tgt.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("engine: list references: %w", err)
+	}
+	consumers := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		consumers = append(consumers, strings.TrimPrefix(obj.Key, prefix))
+	}
+	sort.Strings(consumers)
+	return consumers, nil
+}
+
+// checkReferences returns an error wrapping ErrReferenced that names the
+// consumers if skillName has any reference markers on tgt.
+func (e *Engine) checkReferences(ctx context.Context, tgt target.Target, skillName string) error {
+	consumers, err := e.References(ctx, tgt, skillName)
+	if err != nil {
+		return err
+	}
+	if len(consumers) > 0 {
+		return fmt.Errorf("%w: %s", ErrReferenced, strings.Join(consumers, ", "))
+	}
+	return nil
+}
+
+// referencesPrefix returns the prefix of the reference markers of a skill.
+func referencesPrefix(skillName string) string {
+	return agentctxPrefix(skillName) + "refs/"
+}
+
+// ReferenceKey returns the object key of consumer's reference marker on
+// skillName.
+func ReferenceKey(skillName, consumer string) string {
+	return referencesPrefix(skillName) + consumer
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// ErrReplicationLag is returned by VerifyReplica when a replica has not
+// caught up with a deployment within the timeout.
+var ErrReplicationLag = errors.New("replica has not caught up")
+
+// Bounds of the interval between the checks of poll.
+const (
+	minPollInterval = 50 * time.Millisecond
+	maxPollInterval = 2 * time.Second
+)
+
+// VerifyReplica waits until replica, a target populated by bucket
+// replication from the target deploymentID was deployed to, serves the
+// deployment: its manifest exists and ACTIVE points at it. It checks about
+// ten times within timeout, and at least once. The provider never writes to
+// a replica.
+//
+// A replica that is still behind when timeout elapses is reported as an
+// error wrapping ErrReplicationLag that describes the last check. Errors
+// from the replica itself are retried until the timeout like lag, and
+// returned as is when it elapses.
+func (e *Engine) VerifyReplica(ctx context.Context, replica target.Target, skillName, deploymentID string, timeout time.Duration) error {
+	err := poll(ctx, timeout, func() error {
+		return e.checkServed(ctx, replica, skillName, deploymentID, ErrReplicationLag)
+	})
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return fmt.Errorf("engine: verify replica %s: %w", replica.Name(), ctx.Err())
+	default:
+		return fmt.Errorf("engine: verify replica %s after %s: %w", replica.Name(), timeout, err)
+	}
+}
+
+// poll calls check about ten times within timeout, and at least once,
+// until it succeeds, and returns its last error. It returns early with the
+// context's error once ctx is done.
+func poll(ctx context.Context, timeout time.Duration, check func() error) error {
+	interval := min(max(timeout/10, minPollInterval), maxPollInterval)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := check()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		wait := min(interval, time.Until(deadline))
+		if wait <= 0 {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// checkServed checks once that tgt serves deploymentID: its manifest
+// exists and ACTIVE points at it. A target that does not is reported as an
+// error wrapping lag.
+func (e *Engine) checkServed(ctx context.Context, tgt target.Target, skillName, deploymentID string, lag error) error {
+	if err := e.sem.Acquire(ctx, concurrency.Read, 1); err != nil {
+		return err
+	}
+	defer e.sem.Release(concurrency.Read, 1)
+
+	manifestKey := deploymentPrefix(skillName, deploymentID) + "manifest.json"
+	if _, err := tgt.Head(ctx, manifestKey); err != nil {
+		if errors.Is(err, target.ErrNotFound) {
+			return fmt.Errorf("%w: manifest of deployment %s not found", lag, deploymentID)
+		}
+		return fmt.Errorf("head manifest: %w", err)
+	}
+
+	activeID, _, err := readActiveDeploymentID(ctx, tgt, skillName)
+	if err != nil {
+		return err
+	}
+	if activeID != deploymentID {
+		if activeID == "" {
+			return fmt.Errorf("%w: ACTIVE not found, want %s", lag, deploymentID)
+		}
+		return fmt.Errorf("%w: ACTIVE points at %s, want %s", lag, activeID, deploymentID)
+	}
+	return nil
+}
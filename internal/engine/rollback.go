@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// ErrUnhealthyDeployment is returned by Rollback for a deployment whose
+// files are missing or no longer match its manifest.
+var ErrUnhealthyDeployment = errors.New("deployment failed its integrity check")
+
+// Rollback moves the ACTIVE pointer of skillName on tgt back to ref, an
+// earlier deployment of the skill named by its ID or its deployment alias,
+// without uploading anything. The deployment must still be listed in the
+// skill's index, and every file of it must match its manifest (see
+// CheckIntegrity); otherwise ACTIVE is left alone and an error matching
+// target.ErrNotFound or ErrUnhealthyDeployment is returned. ACTIVE is then
+// moved as by Promote, and the ID of the deployment it selects is returned
+// along with the result.
+func (e *Engine) Rollback(ctx context.Context, tgt target.Target, skillName, ref string) (string, *PromoteResult, error) {
+	depID, err := e.ResolveDeployment(ctx, tgt, skillName, ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("engine: rollback: %w", err)
+	}
+
+	entries, err := e.Deployments(ctx, tgt, skillName)
+	if err != nil {
+		return "", nil, fmt.Errorf("engine: rollback: %w", err)
+	}
+	indexed := false
+	for _, entry := range entries {
+		if entry.DeploymentID == depID {
+			indexed = true
+			break
+		}
+	}
+	if !indexed {
+		return "", nil, fmt.Errorf("engine: rollback: deployment %q of %q is not in the skill's index: %w", depID, skillName, target.ErrNotFound)
+	}
+
+	failed, err := e.CheckIntegrity(ctx, tgt, skillName, depID)
+	if err != nil {
+		return "", nil, fmt.Errorf("engine: rollback: %w", err)
+	}
+	if len(failed) > 0 {
+		return "", nil, fmt.Errorf("engine: rollback: %w: deployment %q has %d missing or modified file(s): %s",
+			ErrUnhealthyDeployment, depID, len(failed), strings.Join(failed, ", "))
+	}
+
+	result, err := e.Promote(ctx, tgt, skillName, depID)
+	if err != nil {
+		return "", nil, err
+	}
+	return depID, result, nil
+}
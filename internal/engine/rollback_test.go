@@ -0,0 +1,75 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	input := defaultDeployInput(createTempBundle(t, map[string]string{"file.txt": "version 1"}))
+	input.DeploymentAlias = "v1"
+	first := deployToTarget(t, eng, tgt, input)
+	second := deployToTarget(t, eng, tgt, defaultDeployInput(createTempBundle(t, map[string]string{"file.txt": "version 2"})))
+
+	depID, result, err := eng.Rollback(ctx, tgt, "my-skill", "v1")
+	if err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if depID != first.DeploymentID {
+		t.Errorf("deployment = %q, want %q resolved from its alias", depID, first.DeploymentID)
+	}
+	if result.PreviousDeploymentID != second.DeploymentID {
+		t.Errorf("PreviousDeploymentID = %q, want %q", result.PreviousDeploymentID, second.DeploymentID)
+	}
+	if got := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE"))); got != first.DeploymentID {
+		t.Errorf("ACTIVE = %q, want %q", got, first.DeploymentID)
+	}
+}
+
+func TestRollback_UnhealthyDeployment(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	first := deployToTarget(t, eng, tgt, defaultDeployInput(createTempBundle(t, map[string]string{"file.txt": "version 1"})))
+	second := deployToTarget(t, eng, tgt, defaultDeployInput(createTempBundle(t, map[string]string{"file.txt": "version 2"})))
+
+	key := "my-skill/.agentctx/deployments/" + first.DeploymentID + "/files/file.txt"
+	if err := tgt.Put(ctx, key, strings.NewReader("tampered"), target.PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := eng.Rollback(ctx, tgt, "my-skill", first.DeploymentID)
+	if !errors.Is(err, engine.ErrUnhealthyDeployment) {
+		t.Fatalf("Rollback error = %v, want ErrUnhealthyDeployment", err)
+	}
+	if !strings.Contains(err.Error(), "file.txt") {
+		t.Errorf("error %q does not name the modified file", err)
+	}
+	if got := strings.TrimSpace(string(readObject(t, tgt, "my-skill/.agentctx/ACTIVE"))); got != second.DeploymentID {
+		t.Errorf("ACTIVE = %q, want it left at %q", got, second.DeploymentID)
+	}
+}
+
+func TestRollback_UnknownDeployment(t *testing.T) {
+	ctx := context.Background()
+	eng := newTestEngine()
+	tgt := target.NewMemoryTarget("test")
+
+	deployToTarget(t, eng, tgt, defaultDeployInput(createTempBundle(t, map[string]string{"file.txt": "version 1"})))
+
+	for _, ref := range []string{"dep_20200101T000000Z_00000000", "no-such-alias"} {
+		if _, _, err := eng.Rollback(ctx, tgt, "my-skill", ref); !errors.Is(err, target.ErrNotFound) {
+			t.Errorf("Rollback(%q) error = %v, want ErrNotFound", ref, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// ErrVerifyFailed is matched by the *VerifyError Deploy returns when
+// DeployInput.Verify rejects a deployment.
+var ErrVerifyFailed = errors.New("post-deploy verification failed")
+
+// VerifyInfo describes a deployment that ACTIVE has just been moved to.
+type VerifyInfo struct {
+	Target               string
+	SkillName            string
+	DeploymentID         string
+	PreviousDeploymentID string // empty on the first deploy
+	BundleHash           string
+}
+
+// Verifier checks a deployment after ACTIVE moved to it, for example by
+// calling a service that serves the skill. A non-nil error rejects the
+// deployment.
+type Verifier func(ctx context.Context, info VerifyInfo) error
+
+// VerifyError is returned by Deploy when DeployInput.Verify rejects the new
+// deployment. Deploy restores the ACTIVE pointer it replaced, or removes
+// ACTIVE if there was none, and leaves the rejected deployment's
+// objects in place: callers should record DeploymentID as staged, like a
+// StagedError, so the next deploy removes them.
+type VerifyError struct {
+	// DeploymentID is the rejected deployment.
+	DeploymentID string
+	// Err is the verifier's error.
+	Err error
+
+	// RolledBack reports whether ACTIVE was restored. ActiveETag and
+	// ActiveGeneration describe the restored pointer; both are empty when
+	// it was removed.
+	RolledBack       bool
+	ActiveETag       string
+	ActiveGeneration int64
+
+	// RollbackErr is set when ACTIVE could not be restored, in which case
+	// it may still point at DeploymentID.
+	RollbackErr error
+}
+
+func (e *VerifyError) Error() string {
+	if !e.RolledBack {
+		return fmt.Sprintf("engine: verify deployment %s: %s; rolling back ACTIVE failed: %s", e.DeploymentID, e.Err, e.RollbackErr)
+	}
+	return fmt.Sprintf("engine: verify deployment %s: %s; ACTIVE was rolled back", e.DeploymentID, e.Err)
+}
+
+func (e *VerifyError) Unwrap() []error {
+	return []error{ErrVerifyFailed, e.Err}
+}
+
+// verify runs input.Verify against the deployment depID that ACTIVE was
+// just moved to, whose pointer has the metadata activeMeta. If the
+// verifier fails, ACTIVE is restored to previous, the pointer it replaced,
+// and a *VerifyError is returned.
+func (e *Engine) verify(ctx context.Context, tgt target.Target, input DeployInput, depID string, previous []PointerEntry, activeMeta target.ObjectMeta) error {
+	verr := input.Verify(ctx, VerifyInfo{
+		Target:               tgt.Name(),
+		SkillName:            input.SkillName,
+		DeploymentID:         depID,
+		PreviousDeploymentID: input.PreviousDeployID,
+		BundleHash:           input.Bundle.BundleHash,
+	})
+	if verr == nil {
+		return nil
+	}
+
+	// Roll back even if the apply is being canceled: leaving a rejected
+	// deployment active is worse than finishing one more request.
+	meta, err := e.rollbackActive(context.WithoutCancel(ctx), tgt, input, previous, activeMeta)
+	if err != nil {
+		return &VerifyError{DeploymentID: depID, Err: verr, RollbackErr: err}
+	}
+	return &VerifyError{
+		DeploymentID:     depID,
+		Err:              verr,
+		RolledBack:       true,
+		ActiveETag:       meta.ETag,
+		ActiveGeneration: meta.Generation,
+	}
+}
+
+// rollbackActive rewrites ACTIVE as previous, or removes it when there was
+// no previous pointer. The write is conditioned on activeMeta, the pointer
+// Deploy wrote, so a pointer moved by someone else in the meantime is left
+// alone and reported as ErrActiveModified.
+func (e *Engine) rollbackActive(ctx context.Context, tgt target.Target, input DeployInput, previous []PointerEntry, activeMeta target.ObjectMeta) (target.ObjectMeta, error) {
+	activeKey := activePointerKey(input.SkillName)
+
+	if len(previous) == 0 {
+		if err := tgt.Delete(ctx, activeKey); err != nil && !errors.Is(err, target.ErrNotFound) {
+			return target.ObjectMeta{}, fmt.Errorf("delete ACTIVE: %w", err)
+		}
+		return target.ObjectMeta{}, nil
+	}
+
+	condition := target.WriteCondition{
+		IfMatch:    activeMeta.ETag,
+		Generation: activeMeta.Generation,
+	}
+	opts := target.PutOptions{
+		ContentType: bundle.ContentTypeACTIVE,
+		Metadata:    objectMetadata(input.Workspace, input.Environment),
+	}
+	if err := tgt.ConditionalPut(ctx, activeKey, bytes.NewReader(FormatPointer(previous)), condition, opts); err != nil {
+		var cme *target.ConcurrentModificationError
+		if errors.Is(err, target.ErrPreconditionFailed) || errors.As(err, &cme) {
+			return target.ObjectMeta{}, fmt.Errorf("%w: %s", ErrActiveModified, err)
+		}
+		return target.ObjectMeta{}, fmt.Errorf("conditional put ACTIVE: %w", err)
+	}
+	return headActivePointer(ctx, tgt, activeKey, input.ReadAfterWrite)
+}
@@ -0,0 +1,133 @@
+// Package errcode defines the stable codes attached to every diagnostic the
+// provider emits. Codes appear at the start of the diagnostic summary, e.g.
+// "[AGX202] File Write Failed", so CI pipelines and support tooling can
+// route failures by code instead of matching free-form messages.
+//
+// Codes are grouped by hundreds:
+//
+//	AGX0xx  configuration and validation
+//	AGX1xx  state and drift
+//	AGX2xx  local filesystem and bundles
+//	AGX3xx  storage targets
+//	AGX4xx  Anthropic API
+//	AGX9xx  provider internals
+//
+// A code, once released, keeps its meaning. New failure kinds get new codes;
+// codes are never renumbered or reused.
+package errcode
+
+import "fmt"
+
+// Code is an AGX diagnostic code.
+type Code string
+
+// Configuration and validation.
+const (
+	// PathTraversal: a configured path is absolute or escapes its root.
+	PathTraversal Code = "AGX001"
+	// InvalidConfig: a combination of arguments is invalid.
+	InvalidConfig Code = "AGX002"
+	// InvalidJSON: a configured JSON value or file does not parse, or has
+	// the wrong shape.
+	InvalidJSON Code = "AGX003"
+	// InvalidImportID: an import ID is malformed, or names a plugin
+	// directory that cannot be imported.
+	InvalidImportID Code = "AGX004"
+	// UnknownTarget: a target name does not match a configured target.
+	UnknownTarget Code = "AGX005"
+	// DuplicateName: two blocks share a name that must be unique.
+	DuplicateName Code = "AGX006"
+	// UnknownEnvVar: a ${VAR} reference names a variable nothing sets.
+	UnknownEnvVar Code = "AGX007"
+	// MissingReferencedFile: a ${CLAUDE_PLUGIN_ROOT} path is not generated.
+	MissingReferencedFile Code = "AGX008"
+	// ClaudeVersion: a requires_claude_version or compatibility_level problem.
+	ClaudeVersion Code = "AGX009"
+	// ReadOnly: an operation would write while the provider is read-only.
+	ReadOnly Code = "AGX010"
+	// FIPS: fips_mode is set but the provider is not running in FIPS 140-3
+	// mode, or a setting is not allowed in it.
+	FIPS Code = "AGX011"
+	// DryRun: an operation was simulated and recorded in the dry-run report
+	// instead of being performed.
+	DryRun Code = "AGX012"
+)
+
+// State and drift.
+const (
+	// InvalidState: values stored in state cannot be parsed.
+	InvalidState Code = "AGX101"
+	// DriftDetected: managed content was changed outside Terraform.
+	DriftDetected Code = "AGX102"
+	// TargetUnreachable: a target could not be refreshed and prior state
+	// was kept.
+	TargetUnreachable Code = "AGX103"
+	// PlanNotice: informational plan-time output, such as destroy previews
+	// and validate-only mode.
+	PlanNotice Code = "AGX104"
+)
+
+// Local filesystem and bundles.
+const (
+	// FileRead: reading or inspecting a local file or directory failed.
+	FileRead Code = "AGX201"
+	// FileWrite: writing a local file or creating a directory failed.
+	FileWrite Code = "AGX202"
+	// FileDelete: removing a local file or directory failed.
+	FileDelete Code = "AGX203"
+	// PathResolution: a path could not be made absolute.
+	PathResolution Code = "AGX204"
+	// Encoding: rendering JSON or YAML output failed.
+	Encoding Code = "AGX205"
+	// InvalidBundle: a skill source or bundle descriptor is unusable or
+	// changed since it was planned.
+	InvalidBundle Code = "AGX206"
+	// SuspiciousContent: a content_scan block found patterns used to hide
+	// instructions in skill or plugin Markdown.
+	SuspiciousContent Code = "AGX207"
+)
+
+// Storage targets.
+const (
+	// TargetInit: a target could not be created or failed validation.
+	TargetInit Code = "AGX301"
+	// DeployFailed: uploading a deployment failed.
+	DeployFailed Code = "AGX302"
+	// RefreshFailed: reading deployment state from a target failed.
+	RefreshFailed Code = "AGX303"
+	// DestroyFailed: removing deployments from a target failed.
+	DestroyFailed Code = "AGX304"
+	// ReplicationLag: a replica target did not serve a deployment in time.
+	ReplicationLag Code = "AGX305"
+	// SkillReferenced: a destroy was refused because consumers hold
+	// reference markers on the skill.
+	SkillReferenced Code = "AGX306"
+	// SkillNotDeployed: a skill a consumer verifies has no active
+	// deployment on a target.
+	SkillNotDeployed Code = "AGX307"
+	// VerifyFailed: a skill's verify block rejected a deployment after
+	// ACTIVE moved to it.
+	VerifyFailed Code = "AGX308"
+)
+
+// Anthropic API.
+const (
+	// AnthropicNotConfigured: the resource needs an anthropic block.
+	AnthropicNotConfigured Code = "AGX401"
+	// AnthropicRequestFailed: a Skills API request failed.
+	AnthropicRequestFailed Code = "AGX402"
+	// RegistrySkipped: the registry was unavailable and registration was
+	// skipped under registry_failure_policy = "warn_and_skip".
+	RegistrySkipped Code = "AGX403"
+)
+
+// Provider internals.
+const (
+	// Internal: an unexpected provider state, usually a bug.
+	Internal Code = "AGX901"
+)
+
+// Summary prefixes title with the code, e.g. "[AGX202] File Write Failed".
+func (c Code) Summary(title string) string {
+	return fmt.Sprintf("[%s] %s", c, title)
+}
@@ -0,0 +1,117 @@
+package errcode
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	if got := FileWrite.Summary("File Write Failed"); got != "[AGX202] File Write Failed" {
+		t.Errorf("Summary = %q", got)
+	}
+}
+
+// summaryArg maps diagnostic constructors to the index of their summary
+// argument.
+var summaryArg = map[string]int{
+	"AddError":                      0,
+	"AddWarning":                    0,
+	"NewErrorDiagnostic":            0,
+	"NewWarningDiagnostic":          0,
+	"AddAttributeError":             1,
+	"AddAttributeWarning":           1,
+	"NewAttributeErrorDiagnostic":   1,
+	"NewAttributeWarningDiagnostic": 1,
+}
+
+// TestDiagnosticsCarryCodes fails when provider code builds a diagnostic
+// from a bare string literal summary instead of Code.Summary.
+func TestDiagnosticsCarryCodes(t *testing.T) {
+	root := ".."
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, p, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			idx, ok := summaryArg[sel.Sel.Name]
+			if !ok || idx >= len(call.Args) {
+				return true
+			}
+			if lit, ok := call.Args[idx].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				t.Errorf("%s: %s summary %s has no error code; use errcode.<Code>.Summary", fset.Position(lit.Pos()), sel.Sel.Name, lit.Value)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCodesDocumented keeps the error code guide in sync with this package.
+func TestCodesDocumented(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "errcode.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := readGuide()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codePattern := regexp.MustCompile(`^"AGX\d{3}"$`)
+	seen := map[string]bool{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, v := range spec.(*ast.ValueSpec).Values {
+				lit, ok := v.(*ast.BasicLit)
+				if !ok || !codePattern.MatchString(lit.Value) {
+					continue
+				}
+				code := strings.Trim(lit.Value, `"`)
+				if seen[code] {
+					t.Errorf("code %s is defined twice", code)
+				}
+				seen[code] = true
+				if !strings.Contains(doc, "`"+code+"`") {
+					t.Errorf("code %s is missing from docs/guides/error-codes.md", code)
+				}
+			}
+		}
+	}
+}
+
+func readGuide() (string, error) {
+	b, err := os.ReadFile(filepath.Join("..", "..", "docs", "guides", "error-codes.md"))
+	return string(b), err
+}
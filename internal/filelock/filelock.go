@@ -0,0 +1,73 @@
+// Package filelock takes advisory, exclusive locks on files so that
+// processes writing the same local directory do not interleave.
+//
+// Locks are flock(2) locks on Unix and LockFileEx locks on Windows. They are
+// advisory: they only exclude other processes that take the same lock, and
+// the operating system releases them when the holding process exits, so a
+// crashed apply never leaves a stale lock behind.
+package filelock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrTimeout is returned by Acquire when the lock is still held by another
+// process after the timeout.
+var ErrTimeout = errors.New("timed out waiting for lock")
+
+// pollInterval is how often Acquire retries a held lock.
+const pollInterval = 100 * time.Millisecond
+
+// Lock is an exclusive lock on a file, held until Release.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire creates name if needed and locks it exclusively, retrying until
+// timeout elapses or ctx is done. A timeout of 0 tries once. The directory
+// containing name must exist.
+func Acquire(ctx context.Context, name string, timeout time.Duration) (*Lock, error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %w", name, err)
+		}
+		if ok {
+			return &Lock{f: f}, nil
+		}
+
+		wait := min(pollInterval, time.Until(deadline))
+		if wait <= 0 {
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %w after %s", name, ErrTimeout, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %w", name, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Release unlocks the file. The lock file itself is left in place: removing
+// it would let a waiter lock the unlinked file while a newcomer locks a
+// fresh one.
+func (l *Lock) Release() error {
+	if err := unlock(l.f); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
@@ -0,0 +1,71 @@
+package filelock
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquire_Exclusive(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".lock")
+	ctx := context.Background()
+
+	l, err := Acquire(ctx, name, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Acquire(ctx, name, 0); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("second Acquire err = %v, want ErrTimeout", err)
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	l, err = Acquire(ctx, name, 0)
+	if err != nil {
+		t.Fatalf("Acquire after Release: %v", err)
+	}
+	l.Release()
+}
+
+func TestAcquire_WaitsForRelease(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".lock")
+	ctx := context.Background()
+
+	l, err := Acquire(ctx, name, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(150*time.Millisecond, func() { l.Release() })
+
+	l2, err := Acquire(ctx, name, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Acquire did not wait for the holder: %v", err)
+	}
+	l2.Release()
+}
+
+func TestAcquire_Canceled(t *testing.T) {
+	name := filepath.Join(t.TempDir(), ".lock")
+
+	l, err := Acquire(context.Background(), name, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Acquire(ctx, name, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestAcquire_MissingDirectory(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "missing", ".lock")
+	if _, err := Acquire(context.Background(), name, 0); err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+}
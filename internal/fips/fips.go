@@ -0,0 +1,110 @@
+// Package fips checks provider settings against the requirements of FIPS
+// 140-3 mode.
+//
+// The provider itself only uses SHA-256 and crypto/rand, both approved. What
+// FIPS mode adds is a guarantee that every TLS connection and hash goes
+// through the validated Go Cryptographic Module, which requires a binary
+// built with GOFIPS140 (see "make build-fips") or run with
+// GODEBUG=fips140=on, and that no setting steers a connection outside it.
+package fips
+
+import (
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/fips140"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// minRSABits is the smallest RSA modulus FIPS 186-5 allows for signatures.
+const minRSABits = 2048
+
+// CABundleEnvVars are the environment variables that point the TLS stacks
+// of the provider and its cloud SDKs at a custom CA bundle.
+var CABundleEnvVars = []string{"SSL_CERT_FILE", "AWS_CA_BUNDLE"}
+
+// Enabled reports whether the Go Cryptographic Module runs in FIPS 140-3
+// mode. It is a variable so tests can override it.
+var Enabled = fips140.Enabled
+
+// CheckURL returns an error unless raw is an https URL. Plain HTTP would
+// send credentials without any TLS, approved or not.
+func CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("scheme %q is not allowed in FIPS mode; use https", u.Scheme)
+	}
+	return nil
+}
+
+// CheckCABundle parses the PEM file at path and checks every certificate in
+// it with CheckCertificate. A file without certificates is an error.
+func CheckCABundle(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("certificate %d: %w", n+1, err)
+		}
+		if err := CheckCertificate(cert); err != nil {
+			return fmt.Errorf("certificate %d (%s): %w", n+1, cert.Subject, err)
+		}
+		n++
+	}
+	if n == 0 {
+		return fmt.Errorf("no PEM certificates found")
+	}
+	return nil
+}
+
+// CheckCertificate returns an error if cert has a key or signature
+// algorithm that FIPS 140-3 mode does not allow: RSA keys under 2048 bits,
+// DSA keys, ECDSA keys outside P-256, P-384, and P-521, or signatures using
+// MD5 or SHA-1.
+func CheckCertificate(cert *x509.Certificate) error {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := key.N.BitLen(); bits < minRSABits {
+			return fmt.Errorf("RSA key is %d bits; at least %d are required", bits, minRSABits)
+		}
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return fmt.Errorf("ECDSA curve %s is not approved", key.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+	case *dsa.PublicKey:
+		return fmt.Errorf("DSA keys are not approved")
+	default:
+		return fmt.Errorf("public key type %T is not approved", key)
+	}
+
+	switch cert.SignatureAlgorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.DSAWithSHA256, x509.ECDSAWithSHA1:
+		return fmt.Errorf("signature algorithm %s is not approved", cert.SignatureAlgorithm)
+	}
+	return nil
+}
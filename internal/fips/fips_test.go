@@ -0,0 +1,112 @@
+package fips
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckURL(t *testing.T) {
+	if err := CheckURL("https://api.anthropic.com"); err != nil {
+		t.Errorf("https URL rejected: %v", err)
+	}
+	if err := CheckURL("http://localhost:8080"); err == nil {
+		t.Error("http URL accepted")
+	}
+}
+
+func TestCheckCertificate(t *testing.T) {
+	rsa1024, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsa2048, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cert    *x509.Certificate
+		wantErr string
+	}{
+		{"rsa 2048 sha256", &x509.Certificate{PublicKey: &rsa2048.PublicKey, SignatureAlgorithm: x509.SHA256WithRSA}, ""},
+		{"p256 sha384", &x509.Certificate{PublicKey: &p256.PublicKey, SignatureAlgorithm: x509.ECDSAWithSHA384}, ""},
+		{"rsa 1024", &x509.Certificate{PublicKey: &rsa1024.PublicKey, SignatureAlgorithm: x509.SHA256WithRSA}, "1024 bits"},
+		{"p224", &x509.Certificate{PublicKey: &p224.PublicKey, SignatureAlgorithm: x509.ECDSAWithSHA256}, "P-224"},
+		{"sha1 signature", &x509.Certificate{PublicKey: &rsa2048.PublicKey, SignatureAlgorithm: x509.SHA1WithRSA}, "SHA1-RSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCertificate(tt.cert)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckCABundle(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(good, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckCABundle(good); err != nil {
+		t.Errorf("valid bundle rejected: %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckCABundle(empty); err == nil {
+		t.Error("bundle without certificates accepted")
+	}
+
+	if err := CheckCABundle(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("missing bundle accepted")
+	}
+}
@@ -0,0 +1,110 @@
+package contentversion
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Compile-time interface checks.
+var _ function.Function = &ContentVersionFunction{}
+
+// NewContentVersionFunction returns a new function.Function for the
+// content_version provider function.
+func NewContentVersionFunction() function.Function {
+	return &ContentVersionFunction{}
+}
+
+// ContentVersionFunction implements the content_version provider function.
+// It derives a semantic version from a content hash and the time the
+// content last changed, so versions increase with time and name the
+// content they were built from.
+type ContentVersionFunction struct{}
+
+// shortHashLen is the number of hex digits of the content hash kept in the
+// version's build metadata.
+const shortHashLen = 8
+
+// hashPattern matches a content hash with an optional "<algorithm>:" prefix,
+// such as the sha256:... bundle hashes the provider computes.
+var hashPattern = regexp.MustCompile(`^(?:[a-z0-9]+:)?([0-9a-fA-F]{8,})$`)
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (f *ContentVersionFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "content_version"
+}
+
+// --------------------------------------------------------------------------
+// Definition
+// --------------------------------------------------------------------------
+
+func (f *ContentVersionFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Returns a date-based semantic version for a content hash.",
+		MarkdownDescription: "Returns a semantic version built from `timestamp`, in UTC, and the first 8 hex digits of `content_hash`, " +
+			"such as `2026.1018.54819+1a2b3c4d` for `2026-10-18T05:48:19Z`: the year, then the month and two-digit day, then the time as `HMMSS`, " +
+			"all without leading zeros, and the short hash as build metadata. Later timestamps always give greater versions. " +
+			"The function is pure, so pass a timestamp that only changes with the content, such as the `rfc3339` of a `time_static` resource " +
+			"whose `triggers` hold the hash, or a commit date, rather than `timestamp()`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "content_hash",
+				MarkdownDescription: "Hash of the content, such as `agentctx_skill.bundle_hash` (`sha256:...`) or a git commit SHA. At least 8 hex digits, optionally prefixed with `<algorithm>:`.",
+			},
+			function.StringParameter{
+				Name:                "timestamp",
+				MarkdownDescription: "RFC 3339 time the content last changed.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Run
+// --------------------------------------------------------------------------
+
+func (f *ContentVersionFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var contentHash, timestamp string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &contentHash, &timestamp))
+	if resp.Error != nil {
+		return
+	}
+
+	version, funcErr := contentVersion(contentHash, timestamp)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, version))
+}
+
+// contentVersion returns the version for contentHash changed at timestamp.
+// Minor and patch are numbers without leading zeros, as semantic versions
+// require, that still order like the dates and times they encode: month
+// 1 to 12 followed by a two-digit day, and hour followed by two-digit
+// minutes and seconds.
+func contentVersion(contentHash, timestamp string) (string, *function.FuncError) {
+	m := hashPattern.FindStringSubmatch(contentHash)
+	if m == nil {
+		return "", function.NewArgumentFuncError(0, fmt.Sprintf("%q is not a content hash: want at least %d hex digits, optionally prefixed with \"<algorithm>:\"", contentHash, shortHashLen))
+	}
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return "", function.NewArgumentFuncError(1, fmt.Sprintf("%q is not an RFC 3339 timestamp: %s", timestamp, err))
+	}
+	t = t.UTC()
+
+	return fmt.Sprintf("%d.%d.%d+%s",
+		t.Year(),
+		int(t.Month())*100+t.Day(),
+		t.Hour()*10000+t.Minute()*100+t.Second(),
+		strings.ToLower(m[1][:shortHashLen])), nil
+}
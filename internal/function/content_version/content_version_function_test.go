@@ -0,0 +1,87 @@
+package contentversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+)
+
+func TestContentVersion(t *testing.T) {
+	tests := []struct {
+		hash, timestamp, want string
+	}{
+		{"sha256:1A2B3C4D5E6F", "2026-10-18T05:48:19Z", "2026.1018.54819+1a2b3c4d"},
+		{"0123abcd", "2026-01-05T00:00:07Z", "2026.105.7+0123abcd"},
+		{"0123abcdef", "2026-12-31T23:59:59Z", "2026.1231.235959+0123abcd"},
+		// Offsets are converted to UTC.
+		{"0123abcd", "2026-01-01T01:30:00+02:00", "2025.1231.233000+0123abcd"},
+	}
+	for _, tt := range tests {
+		got, funcErr := contentVersion(tt.hash, tt.timestamp)
+		if funcErr != nil {
+			t.Errorf("contentVersion(%q, %q): %v", tt.hash, tt.timestamp, funcErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("contentVersion(%q, %q) = %q, want %q", tt.hash, tt.timestamp, got, tt.want)
+		}
+		if _, err := version.NewSemver(got); err != nil {
+			t.Errorf("%q is not a semantic version: %v", got, err)
+		}
+	}
+}
+
+func TestContentVersion_IncreasesWithTime(t *testing.T) {
+	timestamps := []string{
+		"2025-12-31T23:59:59Z",
+		"2026-01-01T00:00:00Z",
+		"2026-01-01T00:00:59Z",
+		"2026-01-01T00:01:00Z",
+		"2026-01-01T09:59:59Z",
+		"2026-01-01T10:00:00Z",
+		"2026-01-31T23:59:59Z",
+		"2026-02-01T00:00:00Z",
+		"2026-10-01T00:00:00Z",
+	}
+	var prev *version.Version
+	for _, ts := range timestamps {
+		s, funcErr := contentVersion("0123abcd", ts)
+		if funcErr != nil {
+			t.Fatal(funcErr)
+		}
+		v, err := version.NewSemver(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev != nil && !v.GreaterThan(prev) {
+			t.Errorf("%s (%s) is not greater than %s", s, ts, prev.Original())
+		}
+		prev = v
+	}
+}
+
+func TestContentVersion_Invalid(t *testing.T) {
+	tests := []struct {
+		hash, timestamp string
+		argument        int64
+		wantErr         string
+	}{
+		{"abc", "2026-10-18T05:48:19Z", 0, "not a content hash"},
+		{"sha256:not-hex", "2026-10-18T05:48:19Z", 0, "not a content hash"},
+		{"0123abcd", "2026-10-18", 1, "not an RFC 3339 timestamp"},
+	}
+	for _, tt := range tests {
+		_, err := contentVersion(tt.hash, tt.timestamp)
+		if err == nil {
+			t.Errorf("contentVersion(%q, %q): expected error", tt.hash, tt.timestamp)
+			continue
+		}
+		if err.FunctionArgument == nil || *err.FunctionArgument != tt.argument {
+			t.Errorf("contentVersion(%q, %q): error argument = %v, want %d", tt.hash, tt.timestamp, err.FunctionArgument, tt.argument)
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("contentVersion(%q, %q): error = %q, want it to contain %q", tt.hash, tt.timestamp, err.Error(), tt.wantErr)
+		}
+	}
+}
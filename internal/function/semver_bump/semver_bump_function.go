@@ -0,0 +1,128 @@
+package semverbump
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Compile-time interface checks.
+var _ function.Function = &SemverBumpFunction{}
+
+// NewSemverBumpFunction returns a new function.Function for the
+// semver_bump provider function.
+func NewSemverBumpFunction() function.Function {
+	return &SemverBumpFunction{}
+}
+
+// SemverBumpFunction implements the semver_bump provider function. It
+// returns the version that follows a semantic version for a major, minor,
+// or patch change.
+type SemverBumpFunction struct{}
+
+// Change types accepted by semver_bump.
+const (
+	changeMajor = "major"
+	changeMinor = "minor"
+	changePatch = "patch"
+)
+
+// semverPattern matches a semantic version 2.0.0, with an optional "v"
+// prefix: major, minor, patch, pre-release, and build metadata.
+var semverPattern = regexp.MustCompile(`^(v?)(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (f *SemverBumpFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "semver_bump"
+}
+
+// --------------------------------------------------------------------------
+// Definition
+// --------------------------------------------------------------------------
+
+func (f *SemverBumpFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Returns the next semantic version for a change type.",
+		MarkdownDescription: "Returns the version that follows `version` for a `major`, `minor`, or `patch` change, as `npm version` does: " +
+			"the bumped part is incremented and the parts after it are reset to `0`, and pre-release and build metadata are dropped. " +
+			"A pre-release is released rather than bumped when it already is a pre-release of the requested change: " +
+			"`1.3.0-rc.1` becomes `1.3.0` for `minor`, not `1.4.0`. A `v` prefix is kept.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "version",
+				MarkdownDescription: "Semantic version to bump, such as `1.2.3` or `v1.2.3-rc.1`.",
+			},
+			function.StringParameter{
+				Name:                "change",
+				MarkdownDescription: "Change type: `major`, `minor`, or `patch`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Run
+// --------------------------------------------------------------------------
+
+func (f *SemverBumpFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var version, change string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &version, &change))
+	if resp.Error != nil {
+		return
+	}
+
+	bumped, funcErr := bump(version, change)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, bumped))
+}
+
+// bump returns the version that follows version for change. See the
+// function's description for the rules.
+func bump(version, change string) (string, *function.FuncError) {
+	m := semverPattern.FindStringSubmatch(version)
+	if m == nil {
+		return "", function.NewArgumentFuncError(0, fmt.Sprintf("%q is not a semantic version such as 1.2.3", version))
+	}
+	prefix, pre := m[1], m[5]
+	var parts [3]uint64
+	for i := range parts {
+		n, err := strconv.ParseUint(m[i+2], 10, 64)
+		if err != nil {
+			return "", function.NewArgumentFuncError(0, fmt.Sprintf("%q is not a semantic version: %s", version, err))
+		}
+		parts[i] = n
+	}
+	major, minor, patch := parts[0], parts[1], parts[2]
+
+	switch change {
+	case changeMajor:
+		if pre == "" || minor != 0 || patch != 0 {
+			major++
+		}
+		minor, patch = 0, 0
+	case changeMinor:
+		if pre == "" || patch != 0 {
+			minor++
+		}
+		patch = 0
+	case changePatch:
+		if pre == "" {
+			patch++
+		}
+	default:
+		return "", function.NewArgumentFuncError(1, fmt.Sprintf("change must be %q, %q, or %q, got %q", changeMajor, changeMinor, changePatch, change))
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch), nil
+}
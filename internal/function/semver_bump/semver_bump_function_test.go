@@ -0,0 +1,63 @@
+package semverbump
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		version, change, want string
+	}{
+		{"1.2.3", "patch", "1.2.4"},
+		{"1.2.3", "minor", "1.3.0"},
+		{"1.2.3", "major", "2.0.0"},
+		{"v0.9.9", "minor", "v0.10.0"},
+		{"1.2.3+build.5", "patch", "1.2.4"},
+
+		// A pre-release of the requested change is released.
+		{"1.2.3-rc.1", "patch", "1.2.3"},
+		{"1.3.0-rc.1", "minor", "1.3.0"},
+		{"2.0.0-beta", "major", "2.0.0"},
+		// Otherwise it is bumped.
+		{"1.2.3-rc.1", "minor", "1.3.0"},
+		{"1.3.0-rc.1", "major", "2.0.0"},
+	}
+	for _, tt := range tests {
+		got, err := bump(tt.version, tt.change)
+		if err != nil {
+			t.Errorf("bump(%q, %q): %v", tt.version, tt.change, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("bump(%q, %q) = %q, want %q", tt.version, tt.change, got, tt.want)
+		}
+	}
+}
+
+func TestBump_Invalid(t *testing.T) {
+	tests := []struct {
+		version, change string
+		argument        int64
+		wantErr         string
+	}{
+		{"1.2", "patch", 0, "not a semantic version"},
+		{"01.2.3", "patch", 0, "not a semantic version"},
+		{"1.2.3-", "patch", 0, "not a semantic version"},
+		{"latest", "patch", 0, "not a semantic version"},
+		{"1.2.3", "micro", 1, `change must be "major", "minor", or "patch"`},
+	}
+	for _, tt := range tests {
+		_, err := bump(tt.version, tt.change)
+		if err == nil {
+			t.Errorf("bump(%q, %q): expected error", tt.version, tt.change)
+			continue
+		}
+		if err.FunctionArgument == nil || *err.FunctionArgument != tt.argument {
+			t.Errorf("bump(%q, %q): error argument = %v, want %d", tt.version, tt.change, err.FunctionArgument, tt.argument)
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("bump(%q, %q): error = %q, want it to contain %q", tt.version, tt.change, err.Error(), tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package golden compares generated artifacts against golden files checked
+// in under a package's testdata directory. Rendering tests call AssertDir (or
+// AssertFile) with the files a resource wrote; any byte-level difference
+// fails the test, so rendering code can be refactored safely.
+//
+// To accept intentional output changes, re-run the tests with
+// AGENTCTX_UPDATE_GOLDEN=1 (or `make golden-update`) and review the diff of
+// the testdata directory.
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that, when set to "1", rewrites the
+// golden files from the current output instead of comparing against them.
+const UpdateEnv = "AGENTCTX_UPDATE_GOLDEN"
+
+func updating() bool {
+	return os.Getenv(UpdateEnv) == "1"
+}
+
+// AssertDir compares every regular file under actualDir with the file at the
+// same relative path under goldenDir. Files present on only one side are
+// reported as well. In update mode goldenDir is replaced with the contents
+// of actualDir.
+func AssertDir(t testing.TB, goldenDir, actualDir string) {
+	t.Helper()
+
+	actual, err := readTree(actualDir)
+	if err != nil {
+		t.Fatalf("golden: reading output %s: %v", actualDir, err)
+	}
+
+	if updating() {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatalf("golden: removing %s: %v", goldenDir, err)
+		}
+		for rel, data := range actual {
+			writeGolden(t, filepath.Join(goldenDir, filepath.FromSlash(rel)), data)
+		}
+		return
+	}
+
+	want, err := readTree(goldenDir)
+	if err != nil {
+		t.Fatalf("golden: reading %s: %v (run with %s=1 to create it)", goldenDir, err, UpdateEnv)
+	}
+
+	for _, rel := range sortedKeys(want) {
+		got, ok := actual[rel]
+		if !ok {
+			t.Errorf("golden: %s was not generated", rel)
+			continue
+		}
+		if msg := diff(want[rel], got); msg != "" {
+			t.Errorf("golden: %s differs from %s:\n%s", rel, goldenDir, msg)
+		}
+	}
+	for _, rel := range sortedKeys(actual) {
+		if _, ok := want[rel]; !ok {
+			t.Errorf("golden: unexpected generated file %s", rel)
+		}
+	}
+}
+
+// AssertFile compares content with the golden file at goldenPath. In update
+// mode the golden file is overwritten with content.
+func AssertFile(t testing.TB, goldenPath string, content []byte) {
+	t.Helper()
+
+	if updating() {
+		writeGolden(t, goldenPath, content)
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("golden: reading %s: %v (run with %s=1 to create it)", goldenPath, err, UpdateEnv)
+	}
+	if msg := diff(want, content); msg != "" {
+		t.Errorf("golden: output differs from %s:\n%s", goldenPath, msg)
+	}
+}
+
+func writeGolden(t testing.TB, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("golden: creating %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("golden: writing %s: %v", path, err)
+	}
+}
+
+// readTree returns the contents of every regular file under root, keyed by
+// slash-separated path relative to root.
+func readTree(root string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return files, err
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diff describes the first line at which want and got differ, or returns ""
+// when they are identical.
+func diff(want, got []byte) string {
+	if bytes.Equal(want, got) {
+		return ""
+	}
+
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g || i >= len(wantLines) || i >= len(gotLines) {
+			return fmt.Sprintf("  line %d:\n    want: %q\n    got:  %q", i+1, w, g)
+		}
+	}
+	return "  contents differ"
+}
@@ -0,0 +1,94 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder captures failures so the assertions themselves can be tested.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAssertDir(t *testing.T) {
+	t.Setenv(UpdateEnv, "")
+
+	goldenDir := t.TempDir()
+	writeFiles(t, goldenDir, map[string]string{"a.txt": "one\ntwo\n", "sub/b.txt": "b"})
+
+	matching := t.TempDir()
+	writeFiles(t, matching, map[string]string{"a.txt": "one\ntwo\n", "sub/b.txt": "b"})
+	rec := &recorder{TB: t}
+	AssertDir(rec, goldenDir, matching)
+	if len(rec.errors) != 0 {
+		t.Errorf("expected no failures, got %v", rec.errors)
+	}
+
+	changed := t.TempDir()
+	writeFiles(t, changed, map[string]string{"a.txt": "one\nTWO\n", "extra.txt": "x"})
+	rec = &recorder{TB: t}
+	AssertDir(rec, goldenDir, changed)
+	// a.txt differs, sub/b.txt is missing, extra.txt is unexpected.
+	if len(rec.errors) != 3 {
+		t.Errorf("expected 3 failures, got %v", rec.errors)
+	}
+}
+
+func TestAssertDir_Update(t *testing.T) {
+	t.Setenv(UpdateEnv, "1")
+
+	goldenDir := filepath.Join(t.TempDir(), "golden")
+	writeFiles(t, goldenDir, map[string]string{"stale.txt": "old"})
+
+	actual := t.TempDir()
+	writeFiles(t, actual, map[string]string{"new.txt": "new"})
+	AssertDir(t, goldenDir, actual)
+
+	if _, err := os.Stat(filepath.Join(goldenDir, "stale.txt")); !os.IsNotExist(err) {
+		t.Error("expected stale golden file to be removed")
+	}
+	data, err := os.ReadFile(filepath.Join(goldenDir, "new.txt"))
+	if err != nil || string(data) != "new" {
+		t.Errorf("expected new.txt to be written, got %q, %v", data, err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if got := diff([]byte("same"), []byte("same")); got != "" {
+		t.Errorf("expected no diff, got %q", got)
+	}
+	got := diff([]byte("a\nb\nc"), []byte("a\nx\nc"))
+	if !strings.Contains(got, "line 2") {
+		t.Errorf("expected line 2 in diff, got %q", got)
+	}
+	got = diff([]byte("a\n"), []byte("a\nb\n"))
+	if !strings.Contains(got, "line 2") {
+		t.Errorf("expected appended line to be reported, got %q", got)
+	}
+}
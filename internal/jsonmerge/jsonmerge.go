@@ -0,0 +1,155 @@
+// Package jsonmerge implements the three-way JSON object merge used by
+// resources that share a file with other writers, such as the settings.json
+// files Claude Code itself updates.
+//
+// Documents are the generic values produced by encoding/json: objects are
+// map[string]any and every other value (including arrays) is treated as an
+// opaque leaf that is replaced wholesale.
+package jsonmerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+)
+
+// ThreeWay merges desired into current. lastApplied is the document
+// Terraform wrote on the previous apply and decides who owns each key:
+//
+//   - keys in desired are set to their desired value, recursing into objects
+//     present on both sides so unmanaged nested keys survive;
+//   - keys in lastApplied but not in desired were removed from the
+//     configuration and are deleted from current;
+//   - every other key in current is not managed by Terraform and is kept.
+//
+// None of the inputs are modified; nil inputs are treated as empty objects.
+func ThreeWay(lastApplied, current, desired map[string]any) map[string]any {
+	out := make(map[string]any, len(current)+len(desired))
+	for k, v := range current {
+		out[k] = v
+	}
+
+	for k, last := range lastApplied {
+		if _, ok := desired[k]; ok {
+			continue
+		}
+		lastObj, lastIsObj := last.(map[string]any)
+		curObj, curIsObj := current[k].(map[string]any)
+		if lastIsObj && curIsObj {
+			// Only the nested keys Terraform wrote are removed.
+			if merged := ThreeWay(lastObj, curObj, nil); len(merged) > 0 {
+				out[k] = merged
+				continue
+			}
+		}
+		delete(out, k)
+	}
+
+	for k, want := range desired {
+		wantObj, wantIsObj := want.(map[string]any)
+		curObj, curIsObj := current[k].(map[string]any)
+		if wantIsObj && curIsObj {
+			lastObj, _ := lastApplied[k].(map[string]any)
+			out[k] = ThreeWay(lastObj, curObj, wantObj)
+			continue
+		}
+		out[k] = want
+	}
+
+	return out
+}
+
+// Project returns the parts of doc that live at the paths described by
+// shape: for each key in shape, nested objects are projected recursively
+// and any other value is copied from doc as-is. Keys missing from doc are
+// omitted. Comparing Project(current, desired) with desired tells whether
+// the managed portion of a document has drifted.
+func Project(doc, shape map[string]any) map[string]any {
+	out := make(map[string]any, len(shape))
+	for k, s := range shape {
+		v, ok := doc[k]
+		if !ok {
+			continue
+		}
+		shapeObj, shapeIsObj := s.(map[string]any)
+		docObj, docIsObj := v.(map[string]any)
+		if shapeIsObj && docIsObj {
+			out[k] = Project(docObj, shapeObj)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Equal reports whether two decoded documents are semantically equal.
+func Equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// DecodeObject parses data as a JSON document whose top-level value must be
+// an object.
+func DecodeObject(data []byte) (map[string]any, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("top-level JSON value must be an object")
+	}
+	return obj, nil
+}
+
+// Encode renders doc as two-space indented JSON with sorted keys and a
+// trailing newline, without HTML-escaping, matching how Claude Code writes
+// its own settings files.
+func Encode(doc map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadObjectFile reads and parses the JSON object stored at filePath. A
+// missing file is reported as an empty object with exists set to false.
+func ReadObjectFile(filePath string) (doc map[string]any, exists bool, err error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]any{}, false, nil
+		}
+		return nil, false, fmt.Errorf("reading %q: %w", filePath, err)
+	}
+	doc, err = DecodeObject(data)
+	if err != nil {
+		return nil, true, fmt.Errorf("%q is not a JSON object: %w", filePath, err)
+	}
+	return doc, true, nil
+}
+
+// WriteObjectFile encodes doc with Encode and atomically replaces filePath
+// with it, creating the parent directory if needed. It returns the bytes
+// written.
+func WriteObjectFile(filePath string, doc map[string]any) ([]byte, error) {
+	content, err := Encode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %q: %w", filePath, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return nil, fmt.Errorf("creating directory for %q: %w", filePath, err)
+	}
+	if err := atomicfile.WriteFile(filePath, content, 0o644); err != nil {
+		return nil, fmt.Errorf("writing %q: %w", filePath, err)
+	}
+	return content, nil
+}
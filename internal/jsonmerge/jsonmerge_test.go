@@ -0,0 +1,130 @@
+package jsonmerge
+
+import (
+	"testing"
+)
+
+func mustDecode(t *testing.T, s string) map[string]any {
+	t.Helper()
+	if s == "" {
+		return nil
+	}
+	v, err := DecodeObject([]byte(s))
+	if err != nil {
+		t.Fatalf("DecodeObject(%q): %v", s, err)
+	}
+	return v
+}
+
+func TestThreeWay(t *testing.T) {
+	cases := []struct {
+		name                          string
+		lastApplied, current, desired string
+		want                          string
+	}{
+		{
+			name:    "first apply keeps existing keys",
+			current: `{"theme": "dark"}`,
+			desired: `{"model": "opus"}`,
+			want:    `{"theme": "dark", "model": "opus"}`,
+		},
+		{
+			name:        "removed managed key is deleted",
+			lastApplied: `{"model": "opus", "cleanupPeriodDays": 30}`,
+			current:     `{"model": "opus", "cleanupPeriodDays": 30, "theme": "dark"}`,
+			desired:     `{"model": "opus"}`,
+			want:        `{"model": "opus", "theme": "dark"}`,
+		},
+		{
+			name:        "out-of-band change to managed key is overwritten",
+			lastApplied: `{"model": "opus"}`,
+			current:     `{"model": "haiku"}`,
+			desired:     `{"model": "opus"}`,
+			want:        `{"model": "opus"}`,
+		},
+		{
+			name:        "nested unmanaged keys survive",
+			lastApplied: `{"permissions": {"allow": ["Read"]}}`,
+			current:     `{"permissions": {"allow": ["Read"], "deny": ["Bash(rm:*)"]}}`,
+			desired:     `{"permissions": {"allow": ["Read", "Grep"]}}`,
+			want:        `{"permissions": {"allow": ["Read", "Grep"], "deny": ["Bash(rm:*)"]}}`,
+		},
+		{
+			name:        "removing a managed object keeps unmanaged children",
+			lastApplied: `{"env": {"FOO": "1"}}`,
+			current:     `{"env": {"FOO": "1", "BAR": "2"}}`,
+			desired:     `{}`,
+			want:        `{"env": {"BAR": "2"}}`,
+		},
+		{
+			name:        "removing a fully managed object deletes it",
+			lastApplied: `{"env": {"FOO": "1"}}`,
+			current:     `{"env": {"FOO": "1"}, "theme": "dark"}`,
+			desired:     `{}`,
+			want:        `{"theme": "dark"}`,
+		},
+		{
+			name:    "desired object replaces scalar",
+			current: `{"env": "oops"}`,
+			desired: `{"env": {"FOO": "1"}}`,
+			want:    `{"env": {"FOO": "1"}}`,
+		},
+		{
+			name:        "arrays are replaced wholesale",
+			lastApplied: `{"allow": ["A", "B"]}`,
+			current:     `{"allow": ["A", "B", "C"]}`,
+			desired:     `{"allow": ["A"]}`,
+			want:        `{"allow": ["A"]}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ThreeWay(mustDecode(t, tc.lastApplied), mustDecode(t, tc.current), mustDecode(t, tc.desired))
+			if want := mustDecode(t, tc.want); !Equal(got, want) {
+				t.Errorf("ThreeWay = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestThreeWay_DoesNotModifyInputs(t *testing.T) {
+	last := mustDecode(t, `{"env": {"FOO": "1"}}`)
+	current := mustDecode(t, `{"env": {"FOO": "1", "BAR": "2"}}`)
+	desired := mustDecode(t, `{"env": {"BAZ": "3"}}`)
+
+	ThreeWay(last, current, desired)
+
+	if want := mustDecode(t, `{"env": {"FOO": "1", "BAR": "2"}}`); !Equal(current, want) {
+		t.Errorf("current was modified: %v", current)
+	}
+}
+
+func TestProject(t *testing.T) {
+	doc := mustDecode(t, `{"model": "haiku", "theme": "dark", "permissions": {"allow": ["Read"], "deny": []}}`)
+	shape := mustDecode(t, `{"model": "opus", "permissions": {"allow": []}, "missing": 1}`)
+
+	got := Project(doc, shape)
+	want := mustDecode(t, `{"model": "haiku", "permissions": {"allow": ["Read"]}}`)
+	if !Equal(got, want) {
+		t.Errorf("Project = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeObject_RejectsNonObject(t *testing.T) {
+	for _, s := range []string{`[]`, `"x"`, `1`, `null`, `{`} {
+		if _, err := DecodeObject([]byte(s)); err == nil {
+			t.Errorf("DecodeObject(%q): expected error", s)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	got, err := Encode(mustDecode(t, `{"b": 1, "a": "<x>"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"a\": \"<x>\",\n  \"b\": 1\n}\n"
+	if string(got) != want {
+		t.Errorf("Encode = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+package jsonmerge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Path addresses a value inside nested JSON objects, one object key per
+// element. Array indexing is deliberately not supported: arrays are leaves.
+type Path []string
+
+// ParsePath parses the subset of JSONPath used to address object members:
+// dot-separated keys with an optional leading "$", plus bracket notation
+// with single or double quotes for keys that contain dots or brackets.
+//
+//	enabledPlugins
+//	$.permissions.allow
+//	$.enabledPlugins["formatter@tools"]
+func ParsePath(s string) (Path, error) {
+	rest := strings.TrimPrefix(s, "$")
+	var p Path
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in path %q", s)
+			}
+			p = append(p, rest[:end])
+			rest = rest[end:]
+		case '[':
+			if len(rest) < 2 || (rest[1] != '\'' && rest[1] != '"') {
+				return nil, fmt.Errorf("path %q: bracket keys must be quoted; array indexes are not supported", s)
+			}
+			quote := rest[1]
+			end := strings.IndexByte(rest[2:], quote)
+			if end == -1 || len(rest) < end+4 || rest[end+3] != ']' {
+				return nil, fmt.Errorf("unterminated bracket key in path %q", s)
+			}
+			p = append(p, rest[2:end+2])
+			rest = rest[end+4:]
+		default:
+			if len(p) > 0 || strings.HasPrefix(s, "$") {
+				return nil, fmt.Errorf("unexpected %q in path %q", rest[0], s)
+			}
+			// A bare leading key, as in "permissions.allow".
+			rest = "." + rest
+		}
+	}
+	if len(p) == 0 {
+		return nil, fmt.Errorf("path %q does not address any key", s)
+	}
+	return p, nil
+}
+
+// String renders p in the canonical form accepted by ParsePath.
+func (p Path) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, k := range p {
+		if k != "" && !strings.ContainsAny(k, ".[]'\"") {
+			b.WriteString(".")
+			b.WriteString(k)
+			continue
+		}
+		fmt.Fprintf(&b, "[%q]", k)
+	}
+	return b.String()
+}
+
+// HasPrefix reports whether p lies at or below prefix.
+func (p Path) HasPrefix(prefix Path) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the value at p in doc.
+func Get(doc map[string]any, p Path) (any, bool) {
+	var cur any = doc
+	for _, k := range p {
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = obj[k]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// Set stores v at p in doc, creating missing intermediate objects. It fails
+// rather than overwrite an intermediate value that is not an object.
+func Set(doc map[string]any, p Path, v any) error {
+	obj := doc
+	for i, k := range p[:len(p)-1] {
+		next, ok := obj[k]
+		if !ok {
+			child := map[string]any{}
+			obj[k] = child
+			obj = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is not an object", p[:i+1])
+		}
+		obj = child
+	}
+	obj[p[len(p)-1]] = v
+	return nil
+}
+
+// Delete removes the value at p from doc, if present. Parent objects are
+// left in place even when they become empty.
+func Delete(doc map[string]any, p Path) {
+	parent := doc
+	if len(p) > 1 {
+		v, ok := Get(doc, p[:len(p)-1])
+		if !ok {
+			return
+		}
+		if parent, ok = v.(map[string]any); !ok {
+			return
+		}
+	}
+	delete(parent, p[len(p)-1])
+}
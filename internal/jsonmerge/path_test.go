@@ -0,0 +1,77 @@
+package jsonmerge
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Path
+	}{
+		{"enabledPlugins", Path{"enabledPlugins"}},
+		{"permissions.allow", Path{"permissions", "allow"}},
+		{"$.permissions.allow", Path{"permissions", "allow"}},
+		{`$.enabledPlugins["formatter@tools"]`, Path{"enabledPlugins", "formatter@tools"}},
+		{`$['a.b'].c`, Path{"a.b", "c"}},
+		{`["x"]["y"]`, Path{"x", "y"}},
+	}
+	for _, tc := range cases {
+		got, err := ParsePath(tc.in)
+		if err != nil {
+			t.Errorf("ParsePath(%q): %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParsePath(%q) = %v, want %v", tc.in, []string(got), []string(tc.want))
+		}
+		// String must round-trip.
+		again, err := ParsePath(got.String())
+		if err != nil || !reflect.DeepEqual(again, got) {
+			t.Errorf("round trip of %q via %q = %v, %v", tc.in, got.String(), again, err)
+		}
+	}
+}
+
+func TestParsePath_Invalid(t *testing.T) {
+	for _, s := range []string{"", "$", "$.", "a..b", "$.a[0]", `$.a["b"`, `$.a["b"]c`, "$a"} {
+		if _, err := ParsePath(s); err == nil {
+			t.Errorf("ParsePath(%q): expected error", s)
+		}
+	}
+}
+
+func TestPath_HasPrefix(t *testing.T) {
+	p := Path{"a", "b", "c"}
+	if !p.HasPrefix(Path{"a", "b"}) || !p.HasPrefix(p) {
+		t.Error("expected prefix match")
+	}
+	if p.HasPrefix(Path{"a", "x"}) || (Path{"a"}).HasPrefix(p) {
+		t.Error("unexpected prefix match")
+	}
+}
+
+func TestGetSetDelete(t *testing.T) {
+	doc := map[string]any{"theme": "dark"}
+
+	if err := Set(doc, Path{"enabledPlugins", "fmt@tools"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := Get(doc, Path{"enabledPlugins", "fmt@tools"}); !ok || v != true {
+		t.Errorf("Get = %v, %v", v, ok)
+	}
+	if _, ok := Get(doc, Path{"theme", "x"}); ok {
+		t.Error("expected Get through a scalar to fail")
+	}
+	if err := Set(doc, Path{"theme", "x"}, 1); err == nil {
+		t.Error("expected Set through a scalar to fail")
+	}
+
+	Delete(doc, Path{"enabledPlugins", "fmt@tools"})
+	Delete(doc, Path{"missing", "key"})
+	want := map[string]any{"theme": "dark", "enabledPlugins": map[string]any{}}
+	if !Equal(doc, want) {
+		t.Errorf("after Delete: %v, want %v", doc, want)
+	}
+}
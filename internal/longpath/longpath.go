@@ -0,0 +1,44 @@
+// Package longpath lets file operations reach paths longer than MAX_PATH
+// (260 characters) on Windows, which nested plugin and skill layouts in
+// large repositories easily exceed.
+//
+// Path converts a path to the extended-length form Windows accepts beyond
+// MAX_PATH: C:\dir becomes \\?\C:\dir and the UNC path \\server\share\dir
+// becomes \\?\UNC\server\share\dir. Use the result only for calls into the
+// os package, and keep reporting the original path to users. On other
+// platforms Path returns its argument unchanged.
+package longpath
+
+import "strings"
+
+const (
+	extendedPrefix    = `\\?\`
+	extendedUNCPrefix = `\\?\UNC\`
+	devicePrefix      = `\\.\`
+)
+
+// extended returns the extended-length form of p, which must be an absolute,
+// cleaned Windows path with backslash separators. Paths already in
+// extended-length or device form, and anything that is not absolute, are
+// returned unchanged.
+func extended(p string) string {
+	switch {
+	case isExtended(p):
+		return p
+	case strings.HasPrefix(p, `\\`):
+		return extendedUNCPrefix + p[2:]
+	case len(p) >= 3 && isDriveLetter(p[0]) && p[1] == ':' && p[2] == '\\':
+		return extendedPrefix + p
+	}
+	return p
+}
+
+// isExtended reports whether p is already in extended-length or device
+// form, which Windows passes through without normalization.
+func isExtended(p string) bool {
+	return strings.HasPrefix(p, extendedPrefix) || strings.HasPrefix(p, devicePrefix)
+}
+
+func isDriveLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package longpath
+
+// Path returns p unchanged; only Windows limits path length to MAX_PATH.
+func Path(p string) string {
+	return p
+}
@@ -0,0 +1,23 @@
+package longpath
+
+import "testing"
+
+func TestExtended(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{`C:\repo\plugins\p`, `\\?\C:\repo\plugins\p`},
+		{`d:\x`, `\\?\d:\x`},
+		{`\\server\share\plugins\p`, `\\?\UNC\server\share\plugins\p`},
+		{`\\?\C:\repo`, `\\?\C:\repo`},
+		{`\\?\UNC\server\share`, `\\?\UNC\server\share`},
+		{`\\.\pipe\name`, `\\.\pipe\name`},
+		{`repo\plugins`, `repo\plugins`},
+		{`C:repo`, `C:repo`},
+		{`/tmp/plugins`, `/tmp/plugins`},
+	} {
+		if got := extended(tc.in); got != tc.want {
+			t.Errorf("extended(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
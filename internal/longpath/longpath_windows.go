@@ -0,0 +1,19 @@
+//go:build windows
+
+package longpath
+
+import "path/filepath"
+
+// Path returns p in extended-length form, resolved against the working
+// directory if it is relative. If p cannot be resolved it is returned
+// unchanged.
+func Path(p string) string {
+	if p == "" || isExtended(p) {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	return extended(abs)
+}
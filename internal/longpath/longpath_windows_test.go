@@ -0,0 +1,42 @@
+//go:build windows
+
+package longpath
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPath_BeyondMaxPath(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; len(dir) <= 300; i++ {
+		dir = filepath.Join(dir, strings.Repeat("d", 40))
+	}
+	file := filepath.Join(dir, "SKILL.md")
+
+	if err := os.MkdirAll(Path(dir), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(Path(file), []byte("# Skill\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := os.ReadFile(Path(file))
+	if err != nil || string(data) != "# Skill\n" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+}
+
+func TestPath_Relative(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Path(`sub\dir`), `\\?\`+filepath.Join(wd, "sub", "dir"); got != want {
+		t.Errorf("Path = %q, want %q", got, want)
+	}
+	if got := Path(`\\?\C:\x`); got != `\\?\C:\x` {
+		t.Errorf("Path changed an extended-length path: %q", got)
+	}
+}
@@ -6,28 +6,56 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 )
 
+// SchemaVersion is the schema_version of the manifests this provider
+// writes. Manifests of older versions are read as if they were current;
+// the engine's layout migration rewrites them.
+const SchemaVersion = 2
+
 // Manifest is the v2 manifest written alongside every deployment.
+//
+// Workspace and Environment identify the Terraform workspace and the
+// environment label of the run that wrote the deployment, so deployments
+// from several workspaces sharing a bucket can be told apart. Both are
+// omitted when not configured.
+//
+// ExpiresAt is set only on preview deployments: the RFC 3339 time after
+// which the preview may be garbage collected.
+//
+// DeploymentAlias is a human-readable name for the deployment, such as a
+// release tag, chosen by the user. Unlike DeploymentID it need not be
+// unique. It is omitted when not configured.
+//
+// DependsOnSkills lists, sorted, the names or registry IDs of the skills
+// this skill expects to be deployed alongside it. It is omitted when empty.
 type Manifest struct {
 	SchemaVersion   int               `json:"schema_version"`
 	ProviderVersion string            `json:"provider_version"`
 	ResourceType    string            `json:"resource_type"`
 	ResourceName    string            `json:"resource_name"`
 	CanonicalStore  string            `json:"canonical_store"`
+	Workspace       string            `json:"workspace,omitempty"`
+	Environment     string            `json:"environment,omitempty"`
 	DeploymentID    string            `json:"deployment_id"`
+	DeploymentAlias string            `json:"deployment_alias,omitempty"`
 	CreatedAt       string            `json:"created_at"`
+	ExpiresAt       string            `json:"expires_at,omitempty"`
 	SourceHash      string            `json:"source_hash"`
 	BundleHash      string            `json:"bundle_hash"`
 	Origin          *ManifestOrigin   `json:"origin,omitempty"`
 	Registry        *ManifestRegistry `json:"registry,omitempty"`
+	DependsOnSkills []string          `json:"depends_on_skills,omitempty"`
 	Files           map[string]string `json:"files"`
 }
 
 // ManifestOrigin describes how the source was provided.
 type ManifestOrigin struct {
-	Type      string `json:"type"`
-	SourceDir string `json:"source_dir,omitempty"`
+	Type          string `json:"type"`
+	SourceDir     string `json:"source_dir,omitempty"`
+	SourceArchive string `json:"source_archive,omitempty"`
+	SourceURL     string `json:"source_url,omitempty"`
 }
 
 // ManifestRegistry describes an Anthropic-registry source.
@@ -36,6 +64,10 @@ type ManifestRegistry struct {
 	SkillID    string `json:"skill_id,omitempty"`
 	Version    string `json:"version,omitempty"`
 	BundleHash string `json:"bundle_hash,omitempty"`
+	// Notes and Labels are the version_notes and version_labels sent when
+	// Version was created.
+	Notes  string            `json:"notes,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // deterministicFiles is a helper type that serializes a map[string]string
@@ -82,12 +114,17 @@ type marshalProxy struct {
 	ResourceType    string             `json:"resource_type"`
 	ResourceName    string             `json:"resource_name"`
 	CanonicalStore  string             `json:"canonical_store"`
+	Workspace       string             `json:"workspace,omitempty"`
+	Environment     string             `json:"environment,omitempty"`
 	DeploymentID    string             `json:"deployment_id"`
+	DeploymentAlias string             `json:"deployment_alias,omitempty"`
 	CreatedAt       string             `json:"created_at"`
+	ExpiresAt       string             `json:"expires_at,omitempty"`
 	SourceHash      string             `json:"source_hash"`
 	BundleHash      string             `json:"bundle_hash"`
 	Origin          *ManifestOrigin    `json:"origin,omitempty"`
 	Registry        *ManifestRegistry  `json:"registry,omitempty"`
+	DependsOnSkills []string           `json:"depends_on_skills,omitempty"`
 	Files           deterministicFiles `json:"files"`
 }
 
@@ -104,23 +141,41 @@ func Marshal(m *Manifest) ([]byte, error) {
 		ResourceType:    m.ResourceType,
 		ResourceName:    m.ResourceName,
 		CanonicalStore:  m.CanonicalStore,
+		Workspace:       m.Workspace,
+		Environment:     m.Environment,
 		DeploymentID:    m.DeploymentID,
+		DeploymentAlias: m.DeploymentAlias,
 		CreatedAt:       m.CreatedAt,
+		ExpiresAt:       m.ExpiresAt,
 		SourceHash:      m.SourceHash,
 		BundleHash:      m.BundleHash,
 		Origin:          m.Origin,
 		Registry:        m.Registry,
+		DependsOnSkills: m.DependsOnSkills,
 		Files:           deterministicFiles{m: m.Files},
 	}
 
 	return json.MarshalIndent(proxy, "", "  ")
 }
 
-// Unmarshal deserializes JSON bytes into a Manifest.
+// Unmarshal deserializes JSON bytes into a Manifest. Manifests are read back
+// from storage that other tools can write to, so file paths that are
+// absolute or climb out of the deployment with ".." are rejected; refresh
+// would otherwise probe keys outside the deployment prefix.
 func Unmarshal(data []byte) (*Manifest, error) {
 	var m Manifest
 	if err := json.Unmarshal(data, &m); err != nil {
 		return nil, fmt.Errorf("manifest: unmarshal failed: %w", err)
 	}
+	for rel := range m.Files {
+		if rel == "" || strings.HasPrefix(rel, "/") {
+			return nil, fmt.Errorf("manifest: file path %q must be relative", rel)
+		}
+		for _, part := range strings.Split(rel, "/") {
+			if part == ".." {
+				return nil, fmt.Errorf("manifest: file path %q must not contain '..'", rel)
+			}
+		}
+	}
 	return &m, nil
 }
@@ -14,7 +14,10 @@ func sampleManifest() *Manifest {
 		ResourceType:    "agentctx_skill",
 		ResourceName:    "my_skill",
 		CanonicalStore:  "s3://my-bucket/skills/",
+		Workspace:       "prod",
+		Environment:     "production",
 		DeploymentID:    "dep_20260213T200102Z_6f2c9a1b",
+		DeploymentAlias: "v1.4.0",
 		CreatedAt:       "2026-02-13T20:01:02Z",
 		SourceHash:      "sha256:abcdef0123456789",
 		BundleHash:      "sha256:9876543210fedcba",
@@ -28,6 +31,7 @@ func sampleManifest() *Manifest {
 			Version:    "1.0.0",
 			BundleHash: "sha256:aabbccdd",
 		},
+		DependsOnSkills: []string{"code-style", "skill_01abc"},
 		Files: map[string]string{
 			"main.py":         "sha256:1111111111111111",
 			"config.yaml":     "sha256:2222222222222222",
@@ -67,9 +71,18 @@ func TestMarshalUnmarshal(t *testing.T) {
 	if roundTripped.CanonicalStore != original.CanonicalStore {
 		t.Errorf("CanonicalStore = %q, want %q", roundTripped.CanonicalStore, original.CanonicalStore)
 	}
+	if roundTripped.Workspace != original.Workspace {
+		t.Errorf("Workspace = %q, want %q", roundTripped.Workspace, original.Workspace)
+	}
+	if roundTripped.Environment != original.Environment {
+		t.Errorf("Environment = %q, want %q", roundTripped.Environment, original.Environment)
+	}
 	if roundTripped.DeploymentID != original.DeploymentID {
 		t.Errorf("DeploymentID = %q, want %q", roundTripped.DeploymentID, original.DeploymentID)
 	}
+	if roundTripped.DeploymentAlias != original.DeploymentAlias {
+		t.Errorf("DeploymentAlias = %q, want %q", roundTripped.DeploymentAlias, original.DeploymentAlias)
+	}
 	if roundTripped.CreatedAt != original.CreatedAt {
 		t.Errorf("CreatedAt = %q, want %q", roundTripped.CreatedAt, original.CreatedAt)
 	}
@@ -108,6 +121,10 @@ func TestMarshalUnmarshal(t *testing.T) {
 		t.Errorf("Registry.BundleHash = %q, want %q", roundTripped.Registry.BundleHash, original.Registry.BundleHash)
 	}
 
+	if got, want := strings.Join(roundTripped.DependsOnSkills, ","), strings.Join(original.DependsOnSkills, ","); got != want {
+		t.Errorf("DependsOnSkills = %q, want %q", got, want)
+	}
+
 	// Check Files map.
 	if len(roundTripped.Files) != len(original.Files) {
 		t.Fatalf("Files length = %d, want %d", len(roundTripped.Files), len(original.Files))
@@ -196,6 +213,14 @@ func TestUnmarshalInvalid(t *testing.T) {
 			name:  "JSON array instead of object",
 			input: `[1, 2, 3]`,
 		},
+		{
+			name:  "absolute file path",
+			input: `{"files": {"/etc/passwd": "sha256:00"}}`,
+		},
+		{
+			name:  "escaping file path",
+			input: `{"files": {"../other-skill/SKILL.md": "sha256:00"}}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -214,3 +239,39 @@ func TestMarshalNil(t *testing.T) {
 		t.Fatal("Marshal(nil) expected error, got nil")
 	}
 }
+
+func FuzzUnmarshal(f *testing.F) {
+	seed, err := Marshal(sampleManifest())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(seed)
+	f.Add([]byte(`{}`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(`{"files":{"../escape":"sha256:00"}}`))
+	f.Add([]byte(`{"schema_version":"two"}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		m, err := Unmarshal(data)
+		if err != nil {
+			return
+		}
+
+		// Anything Unmarshal accepts must survive a round trip unchanged.
+		out, err := Marshal(m)
+		if err != nil {
+			t.Fatalf("Marshal after Unmarshal failed: %v", err)
+		}
+		again, err := Unmarshal(out)
+		if err != nil {
+			t.Fatalf("Unmarshal of re-marshaled manifest failed: %v\n%s", err, out)
+		}
+		out2, err := Marshal(again)
+		if err != nil {
+			t.Fatalf("second Marshal failed: %v", err)
+		}
+		if !bytes.Equal(out, out2) {
+			t.Fatalf("round trip is not stable:\n%s\n---\n%s", out, out2)
+		}
+	})
+}
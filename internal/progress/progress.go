@@ -0,0 +1,257 @@
+// Package progress reports the progress of long uploads, so users watching
+// a slow apply can tell it is not hung. A Reporter logs files and bytes
+// done, throughput, and an ETA through tflog at a fixed interval, and can
+// append the same figures as JSON lines to a file for CI wrappers to tail.
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// DefaultInterval is the reporting interval used when the provider does
+// not configure one.
+const DefaultInterval = 10 * time.Second
+
+// Reporter starts Trackers for uploads. The zero value and a nil Reporter
+// report nothing.
+type Reporter struct {
+	interval time.Duration
+	jsonPath string
+
+	mu sync.Mutex // serializes appends to jsonPath
+}
+
+// NewReporter returns a Reporter that logs every interval, if interval is
+// positive, and appends JSON lines to jsonPath, if it is not empty.
+func NewReporter(interval time.Duration, jsonPath string) *Reporter {
+	return &Reporter{interval: interval, jsonPath: jsonPath}
+}
+
+// Snapshot is the state of an upload at one point in time. It is also the
+// schema of the JSON lines a Reporter writes.
+type Snapshot struct {
+	Time           time.Time         `json:"time"`
+	Operation      string            `json:"operation"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	FilesDone      int64             `json:"files_done"`
+	FilesTotal     int64             `json:"files_total"`
+	BytesDone      int64             `json:"bytes_done"`
+	BytesTotal     int64             `json:"bytes_total"`
+	ElapsedSeconds float64           `json:"elapsed_seconds"`
+	// ETASeconds estimates the time left from the byte rate so far. It is
+	// -1 until the first bytes are done.
+	ETASeconds float64 `json:"eta_seconds"`
+	Done       bool    `json:"done"`
+}
+
+// Tracker counts the files and bytes of one upload. A nil Tracker ignores
+// every call, so callers need not check whether progress is enabled.
+type Tracker struct {
+	r          *Reporter
+	ctx        context.Context
+	operation  string
+	labels     map[string]string
+	filesTotal int64
+	bytesTotal int64
+	start      time.Time
+
+	filesDone atomic.Int64
+	bytesDone atomic.Int64
+	reported  atomic.Bool
+
+	stop     chan struct{}
+	finished sync.Once
+	wg       sync.WaitGroup
+}
+
+// Start begins tracking an upload of filesTotal files and bytesTotal bytes.
+// operation names what is uploaded, e.g. "deploy", and labels identify it
+// in reports, e.g. the skill and target. Call Finish when the upload ends.
+func (r *Reporter) Start(ctx context.Context, operation string, labels map[string]string, filesTotal int, bytesTotal int64) *Tracker {
+	if r == nil || (r.interval <= 0 && r.jsonPath == "") {
+		return nil
+	}
+	t := &Tracker{
+		r:          r,
+		ctx:        ctx,
+		operation:  operation,
+		labels:     labels,
+		filesTotal: int64(filesTotal),
+		bytesTotal: bytesTotal,
+		start:      time.Now(),
+		stop:       make(chan struct{}),
+	}
+	r.writeJSON(ctx, t.Snapshot())
+	if r.interval > 0 {
+		t.wg.Add(1)
+		go t.loop()
+	}
+	return t
+}
+
+// AddBytes records n more bytes done.
+func (t *Tracker) AddBytes(n int64) {
+	if t != nil {
+		t.bytesDone.Add(n)
+	}
+}
+
+// AddFiles records n more files done.
+func (t *Tracker) AddFiles(n int) {
+	if t != nil {
+		t.filesDone.Add(int64(n))
+	}
+}
+
+// Reader returns r wrapped to record every byte read from it as done. On a
+// nil Tracker it returns r. The wrapper has a Len method reporting the
+// unread length when r has one, as *bytes.Buffer does, so HTTP clients can
+// still send a Content-Length.
+func (t *Tracker) Reader(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	c := &countingReader{r: r, t: t}
+	if l, ok := r.(interface{ Len() int }); ok {
+		return &countingLenReader{countingReader: c, l: l}
+	}
+	return c
+}
+
+type countingReader struct {
+	r io.Reader
+	t *Tracker
+}
+
+type countingLenReader struct {
+	*countingReader
+	l interface{ Len() int }
+}
+
+func (c *countingLenReader) Len() int {
+	return c.l.Len()
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.t.AddBytes(int64(n))
+	return n, err
+}
+
+// Finish stops periodic reports and writes a final one. It is safe to call
+// more than once.
+func (t *Tracker) Finish() {
+	if t == nil {
+		return
+	}
+	t.finished.Do(func() {
+		close(t.stop)
+		t.wg.Wait()
+
+		s := t.Snapshot()
+		s.Done = true
+		// Uploads that finish before the first report stay quiet in the
+		// log; the JSON stream always records completion.
+		if t.reported.Load() {
+			t.log(s, "upload finished")
+		}
+		t.r.writeJSON(t.ctx, s)
+	})
+}
+
+// Snapshot returns the current state of the upload.
+func (t *Tracker) Snapshot() Snapshot {
+	elapsed := time.Since(t.start)
+	s := Snapshot{
+		Time:           time.Now().UTC(),
+		Operation:      t.operation,
+		Labels:         t.labels,
+		FilesDone:      t.filesDone.Load(),
+		FilesTotal:     t.filesTotal,
+		BytesDone:      t.bytesDone.Load(),
+		BytesTotal:     t.bytesTotal,
+		ElapsedSeconds: elapsed.Seconds(),
+		ETASeconds:     -1,
+	}
+	if s.BytesDone > 0 {
+		left := max(s.BytesTotal-s.BytesDone, 0)
+		s.ETASeconds = elapsed.Seconds() * float64(left) / float64(s.BytesDone)
+	}
+	return s
+}
+
+func (t *Tracker) loop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+			t.reported.Store(true)
+			s := t.Snapshot()
+			t.log(s, "upload progress")
+			t.r.writeJSON(t.ctx, s)
+		}
+	}
+}
+
+func (t *Tracker) log(s Snapshot, msg string) {
+	fields := map[string]interface{}{
+		"operation":       s.Operation,
+		"files_done":      s.FilesDone,
+		"files_total":     s.FilesTotal,
+		"bytes_done":      s.BytesDone,
+		"bytes_total":     s.BytesTotal,
+		"elapsed_seconds": int64(s.ElapsedSeconds),
+	}
+	if s.ETASeconds >= 0 && !s.Done {
+		fields["eta_seconds"] = int64(s.ETASeconds + 0.5)
+	}
+	for k, v := range s.Labels {
+		fields[k] = v
+	}
+	tflog.Info(t.ctx, msg, fields)
+}
+
+// writeJSON appends s to the JSON lines file, if configured. Failures are
+// logged and otherwise ignored: progress must never fail an apply.
+func (r *Reporter) writeJSON(ctx context.Context, s Snapshot) {
+	if r.jsonPath == "" {
+		return
+	}
+	line, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.jsonPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err == nil {
+		_, err = f.Write(line)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		tflog.Warn(ctx, "could not write progress line", map[string]interface{}{
+			"path":  r.jsonPath,
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package progress
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readLines(t *testing.T, path string) []Snapshot {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines []Snapshot
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var s Snapshot
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil {
+			t.Fatalf("invalid line %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, s)
+	}
+	return lines
+}
+
+func TestTracker_JSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.jsonl")
+	r := NewReporter(20*time.Millisecond, path)
+
+	tr := r.Start(context.Background(), "deploy", map[string]string{"target": "primary"}, 2, 100)
+	tr.AddBytes(40)
+	tr.AddFiles(1)
+	time.Sleep(50 * time.Millisecond)
+	tr.AddBytes(60)
+	tr.AddFiles(1)
+	tr.Finish()
+	tr.Finish()
+
+	lines := readLines(t, path)
+	if len(lines) < 3 {
+		t.Fatalf("got %d lines, want a start, a periodic, and a final line", len(lines))
+	}
+	first, last := lines[0], lines[len(lines)-1]
+	if first.FilesDone != 0 || first.ETASeconds != -1 || first.Done {
+		t.Errorf("first line = %+v, want nothing done", first)
+	}
+	if lines[1].BytesDone != 40 || lines[1].ETASeconds <= 0 {
+		t.Errorf("periodic line = %+v, want 40 bytes done with an ETA", lines[1])
+	}
+	if !last.Done || last.FilesDone != 2 || last.BytesDone != 100 || last.ETASeconds != 0 {
+		t.Errorf("last line = %+v, want everything done", last)
+	}
+	if last.Operation != "deploy" || last.Labels["target"] != "primary" {
+		t.Errorf("last line = %+v, want operation and labels", last)
+	}
+	for i, s := range lines[:len(lines)-1] {
+		if s.Done {
+			t.Errorf("line %d is marked done", i)
+		}
+	}
+}
+
+func TestReporter_Disabled(t *testing.T) {
+	var nilReporter *Reporter
+	for _, r := range []*Reporter{nilReporter, NewReporter(0, "")} {
+		tr := r.Start(context.Background(), "deploy", nil, 1, 1)
+		if tr != nil {
+			t.Fatalf("Start = %v, want nil", tr)
+		}
+		// A nil Tracker ignores every call.
+		tr.AddBytes(1)
+		tr.AddFiles(1)
+		tr.Finish()
+		body := bytes.NewBufferString("x")
+		if got := tr.Reader(body); got != io.Reader(body) {
+			t.Error("nil Tracker wrapped the reader")
+		}
+	}
+}
+
+func TestTracker_Reader(t *testing.T) {
+	tr := NewReporter(0, filepath.Join(t.TempDir(), "p.jsonl")).Start(context.Background(), "upload", nil, 1, 5)
+	defer tr.Finish()
+
+	r := tr.Reader(bytes.NewBufferString("hello"))
+	l, ok := r.(interface{ Len() int })
+	if !ok || l.Len() != 5 {
+		t.Fatalf("wrapped buffer does not report its length")
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+	if got := tr.Snapshot().BytesDone; got != 5 {
+		t.Errorf("BytesDone = %d, want 5", got)
+	}
+
+	if _, ok := tr.Reader(io.MultiReader()).(interface{ Len() int }); ok {
+		t.Error("wrapped MultiReader has a Len method")
+	}
+}
@@ -0,0 +1,213 @@
+package provenance
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitRepo describes the git checkout a source path belongs to. It is read
+// from the .git directory directly, so no git binary is needed.
+type GitRepo struct {
+	// Root is the top-level directory of the working tree.
+	Root string
+	// Origin is the URL of the "origin" remote with any credentials
+	// removed, or empty if there is none.
+	Origin string
+	// Ref is the checked-out branch, e.g. "refs/heads/main", or empty when
+	// HEAD is detached.
+	Ref string
+	// Commit is the commit HEAD points at, or empty for a repository
+	// without commits.
+	Commit string
+}
+
+// Descriptor returns the SLSA resource descriptor for the checkout, e.g.
+// "git+https://github.com/org/repo@refs/heads/main" with its commit.
+func (g *GitRepo) Descriptor() ResourceDescriptor {
+	uri := g.Origin
+	switch {
+	case uri == "":
+		uri = "git+file://" + filepath.ToSlash(g.Root)
+	case strings.Contains(uri, "://"):
+		uri = "git+" + uri
+	}
+	if g.Ref != "" {
+		uri += "@" + g.Ref
+	}
+	d := ResourceDescriptor{URI: uri}
+	if g.Commit != "" {
+		d.Digest = map[string]string{"gitCommit": g.Commit}
+	}
+	return d
+}
+
+// DetectGit returns the git checkout containing path, or nil if path is not
+// inside one.
+func DetectGit(path string) (*GitRepo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for dir := abs; ; {
+		gitPath := filepath.Join(dir, ".git")
+		info, err := os.Stat(gitPath)
+		if err == nil {
+			gitDir, err := resolveGitDir(dir, gitPath, info)
+			if err != nil {
+				return nil, err
+			}
+			return readRepo(dir, gitDir)
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// resolveGitDir follows the "gitdir:" file that worktrees and submodules
+// use in place of a .git directory.
+func resolveGitDir(root, gitPath string, info os.FileInfo) (string, error) {
+	if info.IsDir() {
+		return gitPath, nil
+	}
+	data, err := os.ReadFile(gitPath)
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("provenance: %s is not a gitdir file", gitPath)
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	return target, nil
+}
+
+func readRepo(root, gitDir string) (*GitRepo, error) {
+	// Linked worktrees keep refs and config in the common directory.
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	repo := &GitRepo{Root: root}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return nil, fmt.Errorf("provenance: read HEAD: %w", err)
+	}
+	if ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref:"); ok {
+		repo.Ref = strings.TrimSpace(ref)
+		repo.Commit, err = resolveRef(gitDir, commonDir, repo.Ref)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		repo.Commit = strings.TrimSpace(string(head))
+	}
+
+	repo.Origin, err = readOrigin(filepath.Join(commonDir, "config"))
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// resolveRef returns the commit ref points at, from a loose ref file or
+// packed-refs. An unborn branch resolves to "".
+func resolveRef(gitDir, commonDir, ref string) (string, error) {
+	for _, dir := range []string{gitDir, commonDir} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(commonDir, "packed-refs"))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		commit, name, ok := strings.Cut(sc.Text(), " ")
+		if ok && name == ref {
+			return commit, nil
+		}
+	}
+	return "", sc.Err()
+}
+
+// readOrigin returns the url of [remote "origin"] in a git config file.
+func readOrigin(configPath string) (string, error) {
+	data, err := os.ReadFile(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	inOrigin := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "url" {
+			return redactURL(strings.TrimSpace(value)), nil
+		}
+	}
+	return "", sc.Err()
+}
+
+// redactURL removes credentials from a remote URL, so a token embedded in
+// an HTTPS remote never ends up in an attestation. SCP-style remotes such
+// as git@github.com:org/repo.git carry no secret and are returned as is;
+// an unparseable URL is dropped.
+func redactURL(raw string) string {
+	if !strings.Contains(raw, "://") {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if u.User != nil {
+		// ssh://git@host/... names an account, not a secret; keep it.
+		if u.Scheme == "ssh" {
+			u.User = url.User(u.User.Username())
+		} else {
+			u.User = nil
+		}
+	}
+	return u.String()
+}
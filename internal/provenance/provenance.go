@@ -0,0 +1,170 @@
+// Package provenance renders SLSA provenance for generated artifacts as an
+// in-toto attestation statement.
+//
+// A statement lists every file of the artifact as a subject with its
+// SHA-256 digest, records the git repositories the inputs were read from as
+// resolved dependencies, and names the provider version as the builder.
+// Statements are unsigned; sign them with a tool such as cosign if
+// consumers need to verify who produced them. Output is deterministic: the
+// same inputs always yield the same bytes.
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// StatementType is the in-toto statement type.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType is the SLSA provenance predicate type.
+	PredicateType = "https://slsa.dev/provenance/v1"
+	// ContentType is the media type of an in-toto statement.
+	ContentType = "application/vnd.in-toto+json"
+	// FileName is the file name a statement is written or uploaded under.
+	FileName = "provenance.intoto.json"
+
+	// BuilderID identifies the provider as the builder.
+	BuilderID = "https://github.com/agentctx/terraform-provider-agentctx"
+	// BuildTypeSkill and BuildTypePlugin describe how agentctx_skill and
+	// agentctx_plugin produce their artifacts.
+	BuildTypeSkill  = BuilderID + "/provenance/skill/v1"
+	BuildTypePlugin = BuilderID + "/provenance/plugin/v1"
+)
+
+// Input describes an artifact and how it was built.
+type Input struct {
+	// BuildType is one of the BuildType constants.
+	BuildType string
+	// Files maps each artifact file, by slash-separated path, to its hash
+	// in "sha256:<hex>" form.
+	Files map[string]string
+	// Parameters are the resource arguments that shaped the artifact.
+	Parameters map[string]any
+	// SourcePaths are the local files and directories the artifact was
+	// read from. Each git repository among them becomes a resolved
+	// dependency; paths outside a repository are ignored.
+	SourcePaths []string
+	// ProviderVersion is the version of the provider that built it.
+	ProviderVersion string
+}
+
+// Statement is an in-toto v1 statement with a SLSA v1 provenance predicate.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an artifact file and its digest.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate is a SLSA v1 provenance predicate.
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition records what was built and from which inputs.
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   map[string]any       `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// ResourceDescriptor identifies an input, such as a git commit.
+type ResourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// RunDetails identifies the builder.
+type RunDetails struct {
+	Builder Builder `json:"builder"`
+}
+
+// Builder is the provider that built the artifact.
+type Builder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// Generate renders the provenance statement for in as indented JSON with a
+// trailing newline.
+func Generate(in Input) ([]byte, error) {
+	paths := make([]string, 0, len(in.Files))
+	for p := range in.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	subjects := make([]Subject, 0, len(paths))
+	for _, p := range paths {
+		hex, ok := strings.CutPrefix(in.Files[p], "sha256:")
+		if !ok {
+			return nil, fmt.Errorf("provenance: %s: unsupported hash %q", p, in.Files[p])
+		}
+		subjects = append(subjects, Subject{Name: p, Digest: map[string]string{"sha256": hex}})
+	}
+
+	deps, err := resolveDependencies(in.SourcePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	params := in.Parameters
+	if params == nil {
+		params = map[string]any{}
+	}
+	var version map[string]string
+	if in.ProviderVersion != "" {
+		version = map[string]string{"terraform-provider-agentctx": in.ProviderVersion}
+	}
+
+	st := Statement{
+		Type:          StatementType,
+		Subject:       subjects,
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: BuildDefinition{
+				BuildType:            in.BuildType,
+				ExternalParameters:   params,
+				ResolvedDependencies: deps,
+			},
+			RunDetails: RunDetails{
+				Builder: Builder{ID: BuilderID, Version: version},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("provenance: marshal: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// resolveDependencies returns one descriptor per git repository containing
+// a source path, sorted by URI.
+func resolveDependencies(sourcePaths []string) ([]ResourceDescriptor, error) {
+	seen := make(map[string]bool)
+	var deps []ResourceDescriptor
+	for _, p := range sourcePaths {
+		repo, err := DetectGit(p)
+		if err != nil {
+			return nil, err
+		}
+		if repo == nil || seen[repo.Root] {
+			continue
+		}
+		seen[repo.Root] = true
+		deps = append(deps, repo.Descriptor())
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].URI < deps[j].URI })
+	return deps, nil
+}
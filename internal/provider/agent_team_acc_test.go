@@ -0,0 +1,93 @@
+package provider_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccAgentTeam_BasicLifecycle(t *testing.T) {
+	acctest.SetupTest(t)
+
+	root := t.TempDir()
+	outputDir := filepath.Join(root, "agents")
+	coordFile := filepath.Join(root, "teams", "review.md")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			for _, fp := range []string{
+				filepath.Join(outputDir, "review-security.md"),
+				filepath.Join(outputDir, "review-docs.md"),
+				coordFile,
+			} {
+				if _, err := os.Stat(fp); !os.IsNotExist(err) {
+					return fmt.Errorf("team file still exists after destroy: %s", fp)
+				}
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_agent_team" "test" {
+  name              = "review"
+  output_dir        = %q
+  coordination_file = %q
+  model             = "sonnet"
+
+  agent {
+    name        = "security"
+    description = "Reviews for security issues"
+    prompt      = "You are a security reviewer."
+  }
+
+  agent {
+    name        = "docs"
+    description = "Reviews documentation"
+    prompt      = "You are a docs reviewer."
+  }
+}
+`, outputDir, coordFile),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_agent_team.test", "agent_files.%", "2"),
+					resource.TestCheckResourceAttr("agentctx_agent_team.test", "agent_files.security", filepath.Join(outputDir, "review-security.md")),
+					resource.TestMatchResourceAttr("agentctx_agent_team.test", "coordination_content", regexp.MustCompile("`review-docs`")),
+					resource.TestCheckResourceAttrSet("agentctx_agent_team.test", "content_hash"),
+				),
+			},
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_agent_team" "test" {
+  name              = "review"
+  output_dir        = %q
+  coordination_file = %q
+
+  agent {
+    name        = "security"
+    description = "Reviews for security issues"
+    prompt      = "You are a security reviewer."
+  }
+}
+`, outputDir, coordFile),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_agent_team.test", "agent_files.%", "1"),
+					func(_ *terraform.State) error {
+						fp := filepath.Join(outputDir, "review-docs.md")
+						if _, err := os.Stat(fp); !os.IsNotExist(err) {
+							return fmt.Errorf("removed member file still exists: %s", fp)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,122 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+)
+
+func TestAccAnthropicSkill_RegistryOnly(t *testing.T) {
+	acctest.SetupTest(t)
+
+	mock := acctest.NewMockAnthropicServer(t)
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md": "# Registry only",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigAnthropicOnly(mock.URL()) + fmt.Sprintf(`
+resource "agentctx_anthropic_skill" "test" {
+  source_dir    = %q
+  display_title = "Registry Only"
+}
+
+resource "agentctx_skill_version" "v1" {
+  skill_id   = agentctx_anthropic_skill.test.id
+  source_dir = %q
+}
+`, sourceDir, sourceDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("agentctx_anthropic_skill.test", "id"),
+					resource.TestCheckResourceAttr("agentctx_anthropic_skill.test", "display_title", "Registry Only"),
+					resource.TestCheckResourceAttrSet("agentctx_anthropic_skill.test", "created_at"),
+					resource.TestCheckResourceAttrSet("agentctx_skill_version.v1", "version"),
+				),
+			},
+			{
+				Config: acctest.ProviderConfigAnthropicOnly(mock.URL()) + fmt.Sprintf(`
+resource "agentctx_anthropic_skill" "test" {
+  source_dir    = %q
+  display_title = "Registry Only (renamed)"
+}
+`, sourceDir),
+				Check: resource.TestCheckResourceAttr("agentctx_anthropic_skill.test", "display_title", "Registry Only (renamed)"),
+			},
+			{
+				ResourceName:            "agentctx_anthropic_skill.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"source_dir"},
+			},
+		},
+	})
+}
+
+func TestAccAnthropicSkill_DetachKeepsRegistrySkill(t *testing.T) {
+	acctest.SetupTest(t)
+
+	mock := acctest.NewMockAnthropicServer(t)
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md": "# Handed over",
+	})
+
+	var skillID string
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		// The provider sets destroy_remote = true; on_destroy overrides it.
+		CheckDestroy: func(*terraform.State) error {
+			client := anthropic.NewClient(anthropic.ClientConfig{APIKey: "test-api-key", BaseURL: mock.URL()})
+			if _, err := client.GetSkill(context.Background(), skillID); err != nil {
+				return fmt.Errorf("detached skill %q was deleted: %w", skillID, err)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigAnthropicOnly(mock.URL()) + fmt.Sprintf(`
+resource "agentctx_anthropic_skill" "test" {
+  source_dir = %q
+  on_destroy = "detach"
+}
+`, sourceDir),
+				Check: func(s *terraform.State) error {
+					skillID = s.RootModule().Resources["agentctx_anthropic_skill.test"].Primary.ID
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccAnthropicSkill_SkillNeedsTargets(t *testing.T) {
+	acctest.SetupTest(t)
+
+	mock := acctest.NewMockAnthropicServer(t)
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "needs storage",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigAnthropicOnly(mock.URL()) + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir),
+				ExpectError: regexp.MustCompile("Missing Target Configuration"),
+			},
+		},
+	})
+}
@@ -0,0 +1,113 @@
+package provider_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccCatalog_OutputDir(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md": "---\nname: reports\ndescription: Summarises reports.\n---\n# Reports\n",
+	})
+	outputDir := filepath.Join(t.TempDir(), "catalog")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			for _, name := range []string{"catalog.json", "catalog.md"} {
+				fp := filepath.Join(outputDir, name)
+				if _, err := os.Stat(fp); !os.IsNotExist(err) {
+					return fmt.Errorf("catalog file still exists after destroy: %s", fp)
+				}
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "reports" {
+  source_dir = %q
+}
+
+resource "agentctx_catalog" "test" {
+  name       = "acme"
+  output_dir = %q
+
+  skill {
+    name        = agentctx_skill.reports.skill_name
+    description = agentctx_skill.reports.skill_description
+    owner       = "data"
+    locations   = keys(agentctx_skill.reports.target_states)
+  }
+}
+`, sourceDir, outputDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_catalog.test", "json_path", filepath.Join(outputDir, "catalog.json")),
+					resource.TestMatchResourceAttr("agentctx_catalog.test", "catalog_json", regexp.MustCompile(`"description": "Summarises reports."`)),
+					resource.TestMatchResourceAttr("agentctx_catalog.test", "catalog_markdown", regexp.MustCompile("\\| `primary` \\|")),
+					resource.TestCheckResourceAttrSet("agentctx_catalog.test", "content_hash"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCatalog_Target(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + `
+resource "agentctx_catalog" "test" {
+  name        = "acme"
+  description = "Everything Acme deploys."
+  target      = "primary"
+
+  plugin {
+    name    = "review-tools"
+    version = "1.2.0"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_catalog.test", "id", "primary:acme"),
+					resource.TestCheckResourceAttr("agentctx_catalog.test", "json_path", "catalogs/acme/catalog.json"),
+					resource.TestCheckResourceAttr("agentctx_catalog.test", "markdown_path", "catalogs/acme/catalog.md"),
+					resource.TestMatchResourceAttr("agentctx_catalog.test", "catalog_markdown", regexp.MustCompile("\\| `review-tools` \\| 1.2.0 \\|")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCatalog_OutputDirAndTarget_Error(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_catalog" "test" {
+  name       = "acme"
+  output_dir = %q
+  target     = "primary"
+}
+`, t.TempDir()),
+				ExpectError: regexp.MustCompile(`(?i)invalid attribute combination`),
+			},
+		},
+	})
+}
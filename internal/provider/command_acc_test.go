@@ -0,0 +1,124 @@
+package provider_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccCommand_BasicLifecycle(t *testing.T) {
+	acctest.SetupTest(t)
+
+	outputDir := t.TempDir()
+	commandPath := filepath.Join(outputDir, "fix-issue.md")
+
+	config := func(description string) string {
+		return acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_command" "test" {
+  name          = "fix-issue"
+  output_dir    = %q
+  description   = %q
+  argument_hint = "[issue-number]"
+  allowed_tools = ["Bash(gh issue view:*)"]
+  body          = "Fix issue #$1."
+}
+`, outputDir, description)
+	}
+	checkFile := func(want string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			data, err := os.ReadFile(commandPath)
+			if err != nil {
+				return err
+			}
+			if string(data) != want {
+				return fmt.Errorf("fix-issue.md = %q, want %q", data, want)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := os.Stat(commandPath); !os.IsNotExist(err) {
+				return fmt.Errorf("command file still exists after destroy: %s", commandPath)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("Fix an issue"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_command.test", "id", commandPath),
+					resource.TestCheckResourceAttr("agentctx_command.test", "file_path", commandPath),
+					resource.TestCheckResourceAttrSet("agentctx_command.test", "content_hash"),
+					checkFile("---\ndescription: Fix an issue\nargument-hint: '[issue-number]'\nallowed-tools: Bash(gh issue view:*)\n---\n\nFix issue #$1.\n"),
+				),
+			},
+			{
+				Config: config("Fix a GitHub issue"),
+				Check:  checkFile("---\ndescription: Fix a GitHub issue\nargument-hint: '[issue-number]'\nallowed-tools: Bash(gh issue view:*)\n---\n\nFix issue #$1.\n"),
+			},
+			{
+				// An edit outside Terraform plans an update that restores
+				// the configured content.
+				PreConfig: func() {
+					if err := os.WriteFile(commandPath, []byte("Edited by hand.\n"), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             config("Fix a GitHub issue"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccCommand_PluginSourceFile(t *testing.T) {
+	acctest.SetupTest(t)
+
+	commandDir := t.TempDir()
+	pluginDir := filepath.Join(t.TempDir(), "release-tools")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_command" "test" {
+  name       = "release-notes"
+  output_dir = %q
+  body       = "Summarize the commits since the last tag."
+}
+
+resource "agentctx_plugin" "test" {
+  name       = "release-tools"
+  output_dir = %q
+
+  command {
+    name        = "release-notes"
+    source_file = agentctx_command.test.file_path
+  }
+}
+`, commandDir, pluginDir),
+				Check: func(*terraform.State) error {
+					data, err := os.ReadFile(filepath.Join(pluginDir, "commands", "release-notes.md"))
+					if err != nil {
+						return err
+					}
+					if want := "Summarize the commits since the last tag.\n"; string(data) != want {
+						return fmt.Errorf("plugin command = %q, want %q", data, want)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
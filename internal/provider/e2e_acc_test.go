@@ -0,0 +1,160 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// TestAccE2E_SkillLifecycle runs a skill through create, two updates that
+// prune its oldest deployment, and destroy on a real S3 target and the
+// fake registry. Run it with make e2e.
+func TestAccE2E_SkillLifecycle(t *testing.T) {
+	acctest.PreCheckE2E(t)
+
+	mock := acctest.NewMockAnthropicServer(t)
+	sourceDir := acctest.CreateE2ESourceDir(t, map[string]string{
+		"SKILL.md":    "# E2E\n\nversion 1\n",
+		"lib/util.py": "print('util')",
+	})
+	skillName := filepath.Base(sourceDir)
+	tgt := acctest.E2ETarget(t)
+
+	config := acctest.ProviderConfigE2E("primary", mock.URL()) + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir         = %q
+  prune_deployments  = true
+  retain_deployments = 1
+
+  anthropic {
+    enabled      = true
+    auto_version = true
+  }
+}
+`, sourceDir)
+	edit := func(content string) func() {
+		return func() {
+			if err := os.WriteFile(filepath.Join(sourceDir, "SKILL.md"), []byte(content), 0o644); err != nil {
+				t.Fatalf("failed to update source file: %s", err)
+			}
+		}
+	}
+
+	var skillID string
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if left := e2eDeployments(t, tgt, skillName); len(left) > 0 {
+				return fmt.Errorf("destroy left deployments %v", left)
+			}
+			if _, err := tgt.Head(context.Background(), skillName+"/.agentctx/ACTIVE"); !errors.Is(err, target.ErrNotFound) {
+				return fmt.Errorf("ACTIVE not destroyed (err=%v)", err)
+			}
+			client := anthropic.NewClient(anthropic.ClientConfig{APIKey: "test-api-key", BaseURL: mock.URL()})
+			if _, err := client.GetSkill(context.Background(), skillID); err == nil {
+				return fmt.Errorf("registry skill %q survived destroy", skillID)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "skill_name", skillName),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.%", "1"),
+					resource.TestMatchResourceAttr("agentctx_skill.test", "registry_state.skill_id", regexp.MustCompile(`^skill_mock_`)),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.deployed_version", "v1"),
+					checkE2EActive(t, tgt, skillName, 1),
+					func(s *terraform.State) error {
+						skillID = s.RootModule().Resources["agentctx_skill.test"].Primary.Attributes["registry_state.skill_id"]
+						return nil
+					},
+				),
+			},
+			{
+				PreConfig: edit("# E2E\n\nversion 2\n"),
+				Config:    config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.deployed_version", "v2"),
+					checkE2EActive(t, tgt, skillName, 2),
+				),
+			},
+			{
+				// The third deployment prunes the first: ACTIVE and one
+				// retained deployment are left.
+				PreConfig: edit("# E2E\n\nversion 3\n"),
+				Config:    config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.deployed_version", "v3"),
+					checkE2EActive(t, tgt, skillName, 2),
+				),
+			},
+		},
+	})
+}
+
+// checkE2EActive checks that ACTIVE on tgt selects the deployment in the
+// state of agentctx_skill.test, whose files were uploaded, and that want
+// deployments of skillName are left on tgt.
+func checkE2EActive(t *testing.T, tgt target.Target, skillName string, want int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		ctx := context.Background()
+		depID := s.RootModule().Resources["agentctx_skill.test"].Primary.Attributes["target_states.primary.active_deployment_id"]
+
+		rc, _, err := tgt.Get(ctx, skillName+"/.agentctx/ACTIVE")
+		if err != nil {
+			return fmt.Errorf("reading ACTIVE: %w", err)
+		}
+		defer rc.Close()
+		var active string
+		if _, err := fmt.Fscan(rc, &active); err != nil {
+			return fmt.Errorf("reading ACTIVE: %w", err)
+		}
+		if active != depID {
+			return fmt.Errorf("ACTIVE = %q, want %q", active, depID)
+		}
+		if _, err := tgt.Head(ctx, skillName+"/.agentctx/deployments/"+depID+"/files/SKILL.md"); err != nil {
+			return fmt.Errorf("active deployment files: %w", err)
+		}
+
+		if got := e2eDeployments(t, tgt, skillName); len(got) != want {
+			return fmt.Errorf("deployments on target = %v, want %d", got, want)
+		}
+		return nil
+	}
+}
+
+// e2eDeployments returns the IDs of the deployments of skillName on tgt,
+// sorted.
+func e2eDeployments(t *testing.T, tgt target.Target, skillName string) []string {
+	t.Helper()
+	prefix := skillName + "/.agentctx/deployments/"
+	objects, err := tgt.List(context.Background(), prefix)
+	if err != nil {
+		t.Fatalf("listing deployments: %s", err)
+	}
+	seen := make(map[string]bool)
+	for _, obj := range objects {
+		id, _, _ := strings.Cut(strings.TrimPrefix(obj.Key, prefix), "/")
+		seen[id] = true
+	}
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,68 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccFunctions_SemverBump(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + `
+output "minor" {
+  value = provider::agentctx::semver_bump("1.2.3", "minor")
+}
+
+output "release" {
+  value = provider::agentctx::semver_bump("v2.0.0-rc.1", "major")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("minor", "1.3.0"),
+					resource.TestCheckOutput("release", "v2.0.0"),
+				),
+			},
+			{
+				Config: acctest.ProviderConfigMemory("primary") + `
+output "invalid" {
+  value = provider::agentctx::semver_bump("1.2.3", "micro")
+}
+`,
+				ExpectError: regexp.MustCompile(`change must be "major", "minor", or "patch"`),
+			},
+		},
+	})
+}
+
+func TestAccFunctions_ContentVersion(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + `
+output "version" {
+  value = provider::agentctx::content_version("sha256:1a2b3c4d5e6f", "2026-10-18T05:48:19Z")
+}
+`,
+				Check: resource.TestCheckOutput("version", "2026.1018.54819+1a2b3c4d"),
+			},
+		},
+	})
+}
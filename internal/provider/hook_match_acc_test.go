@@ -0,0 +1,80 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccHookMatchDataSource_Basic(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + `
+locals {
+  hooks = jsonencode({
+    hooks = {
+      PostToolUse = [
+        {
+          matcher = "Write|Edit"
+          hooks   = [{ type = "command", command = "format.sh" }]
+        },
+        {
+          matcher = "Bash"
+          hooks   = [{ type = "command", command = "audit.sh" }]
+        },
+      ]
+    }
+  })
+}
+
+data "agentctx_hook_match" "write" {
+  hooks_json = local.hooks
+  event      = "post_tool_use"
+  tool_name  = "Write"
+}
+
+data "agentctx_hook_match" "read" {
+  hooks_json = local.hooks
+  event      = "PostToolUse"
+  tool_name  = "Read"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.agentctx_hook_match.write", "matched", "true"),
+					resource.TestCheckResourceAttr("data.agentctx_hook_match.write", "matches.#", "1"),
+					resource.TestCheckResourceAttr("data.agentctx_hook_match.write", "matches.0.index", "0"),
+					resource.TestCheckResourceAttr("data.agentctx_hook_match.write", "matches.0.hooks.0.command", "format.sh"),
+					resource.TestCheckResourceAttr("data.agentctx_hook_match.read", "matched", "false"),
+					resource.TestCheckResourceAttr("data.agentctx_hook_match.read", "matches.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccHookMatchDataSource_InvalidMatcher(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + `
+data "agentctx_hook_match" "bad" {
+  hooks_json = jsonencode({ PreToolUse = [{ matcher = "Write|(Edit", hooks = [] }] })
+  event      = "PreToolUse"
+  tool_name  = "Write"
+}
+`,
+				ExpectError: regexp.MustCompile(`not a valid regular expression`),
+			},
+		},
+	})
+}
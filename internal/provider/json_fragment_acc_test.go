@@ -0,0 +1,94 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccJSONFragment_ScopedDrift(t *testing.T) {
+	acctest.SetupTest(t)
+
+	filePath := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(filePath, []byte(`{"theme": "dark"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_json_fragment" "test" {
+  path = %q
+  values = {
+    "$.enabledPlugins" = jsonencode({ "formatter@tools" = true })
+  }
+}
+`, filePath)
+
+	checkFile := func(want string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				return err
+			}
+			var got, wantDoc any
+			if err := json.Unmarshal(data, &got); err != nil {
+				return err
+			}
+			if err := json.Unmarshal([]byte(want), &wantDoc); err != nil {
+				return err
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(wantDoc)
+			if string(gotJSON) != string(wantJSON) {
+				return fmt.Errorf("file = %s, want %s", gotJSON, wantJSON)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy:             checkFile(`{"theme": "light"}`),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_json_fragment.test", "id", filePath),
+					checkFile(`{"theme": "dark", "enabledPlugins": {"formatter@tools": true}}`),
+				),
+			},
+			{
+				// Changes outside the managed paths are not drift.
+				PreConfig: func() {
+					data := []byte(`{"theme": "light", "enabledPlugins": {"formatter@tools": true}}`)
+					if err := os.WriteFile(filePath, data, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				PreConfig: func() {
+					data := []byte(`{"theme": "light", "enabledPlugins": {"formatter@tools": false}}`)
+					if err := os.WriteFile(filePath, data, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             config,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config,
+				Check:  checkFile(`{"theme": "light", "enabledPlugins": {"formatter@tools": true}}`),
+			},
+		},
+	})
+}
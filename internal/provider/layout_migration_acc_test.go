@@ -0,0 +1,80 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestAccLayoutMigration_BuildsMissingIndex(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	skillName := filepath.Base(sourceDir)
+	indexKey := skillName + "/.agentctx/index.json"
+	tgt := target.GetOrCreateMemoryTarget("primary")
+
+	skillConfig := fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir)
+	migrationConfig := func(dryRun bool) string {
+		return fmt.Sprintf(`
+resource "agentctx_layout_migration" "test" {
+  target      = "primary"
+  skill_names = [%q]
+  dry_run     = %t
+}
+`, skillName, dryRun)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig,
+			},
+			{
+				// Drop the index, as for a skill deployed by an older provider.
+				PreConfig: func() {
+					if err := tgt.Delete(context.Background(), indexKey); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig + migrationConfig(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_layout_migration.test", "id", "primary"),
+					resource.TestCheckResourceAttr("agentctx_layout_migration.test", "changes.#", "1"),
+					resource.TestCheckResourceAttr("agentctx_layout_migration.test", "changes.0.kind", "missing_index"),
+					resource.TestCheckResourceAttr("agentctx_layout_migration.test", "changes.0.key", indexKey),
+					func(*terraform.State) error {
+						if _, err := tgt.Head(context.Background(), indexKey); err == nil {
+							return fmt.Errorf("dry run wrote %s", indexKey)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig + migrationConfig(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_layout_migration.test", "changes.#", "1"),
+					func(*terraform.State) error {
+						_, err := tgt.Head(context.Background(), indexKey)
+						return err
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,108 @@
+package provider_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccMarketplace_ReferencesPluginDir(t *testing.T) {
+	acctest.SetupTest(t)
+
+	root := filepath.Join(t.TempDir(), "marketplace")
+	manifestPath := filepath.Join(root, ".claude-plugin", "marketplace.json")
+
+	config := func(version string) string {
+		return acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_plugin" "formatter" {
+  name       = "formatter"
+  output_dir = %q
+  version    = %q
+}
+
+resource "agentctx_marketplace" "test" {
+  name       = "acme-tools"
+  output_dir = %q
+
+  owner {
+    name = "DevTools"
+  }
+
+  plugin {
+    name    = agentctx_plugin.formatter.name
+    source  = agentctx_plugin.formatter.plugin_dir
+    version = agentctx_plugin.formatter.version
+  }
+
+  plugin {
+    name   = "linter"
+    source = "github:acme/linter-plugin"
+  }
+}
+`, filepath.Join(root, "plugins", "formatter"), version, root)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+				return fmt.Errorf("marketplace manifest still exists after destroy: %s", manifestPath)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("1.0.0"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_marketplace.test", "marketplace_file", manifestPath),
+					resource.TestMatchResourceAttr("agentctx_marketplace.test", "manifest_json", regexp.MustCompile(`"source": "\./plugins/formatter"`)),
+					resource.TestMatchResourceAttr("agentctx_marketplace.test", "manifest_json", regexp.MustCompile(`"repo": "acme/linter-plugin"`)),
+					resource.TestCheckResourceAttrSet("agentctx_marketplace.test", "content_hash"),
+				),
+			},
+			{
+				Config: config("1.1.0"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("agentctx_marketplace.test", "manifest_json", regexp.MustCompile(`"version": "1\.1\.0"`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccMarketplace_SourceOutsideRoot(t *testing.T) {
+	acctest.SetupTest(t)
+
+	dir := t.TempDir()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_marketplace" "test" {
+  name       = "acme-tools"
+  output_dir = %q
+
+  owner {
+    name = "DevTools"
+  }
+
+  plugin {
+    name   = "formatter"
+    source = %q
+  }
+}
+`, filepath.Join(dir, "marketplace"), filepath.Join(dir, "elsewhere")),
+				ExpectError: regexp.MustCompile(`outside the marketplace root`),
+			},
+		},
+	})
+}
@@ -0,0 +1,153 @@
+package provider_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccMemory_BasicLifecycle(t *testing.T) {
+	acctest.SetupTest(t)
+
+	projectDir := t.TempDir()
+	memoryPath := filepath.Join(projectDir, "CLAUDE.md")
+
+	config := func(rule string) string {
+		return acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_memory" "test" {
+  scope       = "project"
+  project_dir = %q
+  title       = "Payments Service"
+  imports     = ["README.md"]
+
+  section {
+    heading = "Conventions"
+    rules   = [%q]
+  }
+}
+`, projectDir, rule)
+	}
+	checkFile := func(want string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			data, err := os.ReadFile(memoryPath)
+			if err != nil {
+				return err
+			}
+			if string(data) != want {
+				return fmt.Errorf("CLAUDE.md = %q, want %q", data, want)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := os.Stat(memoryPath); !os.IsNotExist(err) {
+				return fmt.Errorf("memory file still exists after destroy: %s", memoryPath)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("Run make lint before committing."),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_memory.test", "id", memoryPath),
+					resource.TestCheckResourceAttr("agentctx_memory.test", "file_path", memoryPath),
+					resource.TestCheckResourceAttrSet("agentctx_memory.test", "content_hash"),
+					checkFile("# Payments Service\n\n@README.md\n\n## Conventions\n\n- Run make lint before committing.\n"),
+				),
+			},
+			{
+				Config: config("Keep commits small."),
+				Check:  checkFile("# Payments Service\n\n@README.md\n\n## Conventions\n\n- Keep commits small.\n"),
+			},
+			{
+				// An edit outside Terraform plans an update that restores
+				// the configured content.
+				PreConfig: func() {
+					if err := os.WriteFile(memoryPath, []byte("# Edited by hand\n"), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             config("Keep commits small."),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config("Keep commits small."),
+				Check:  checkFile("# Payments Service\n\n@README.md\n\n## Conventions\n\n- Keep commits small.\n"),
+			},
+		},
+	})
+}
+
+func TestAccMemory_LocalScope(t *testing.T) {
+	acctest.SetupTest(t)
+
+	projectDir := t.TempDir()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_memory" "test" {
+  scope       = "local"
+  project_dir = %q
+
+  section {
+    heading = "Sandbox"
+    content = "Use the staging database."
+  }
+}
+`, projectDir),
+				Check: resource.TestCheckResourceAttr("agentctx_memory.test", "file_path", filepath.Join(projectDir, "CLAUDE.local.md")),
+			},
+		},
+	})
+}
+
+func TestAccMemory_InvalidConfig(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + `
+resource "agentctx_memory" "test" {
+  scope       = "user"
+  project_dir = "."
+  title       = "Preferences"
+}
+`,
+				ExpectError: regexp.MustCompile(`project_dir is not valid`),
+			},
+			{
+				Config: acctest.ProviderConfigMemory("test") + `
+resource "agentctx_memory" "test" {
+  scope = "project"
+}
+`,
+				ExpectError: regexp.MustCompile(`At least one of title, imports`),
+			},
+			{
+				Config: acctest.ProviderConfigMemory("test") + `
+resource "agentctx_memory" "test" {
+  scope   = "project"
+  imports = ["@README.md"]
+}
+`,
+				ExpectError: regexp.MustCompile(`without the leading @`),
+			},
+		},
+	})
+}
@@ -3,24 +3,74 @@ package provider
 import (
 	"context"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"golang.org/x/sync/semaphore"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	hookmatch "github.com/agentctx/terraform-provider-agentctx/internal/datasource/hook_match"
+	renderpluginmanifest "github.com/agentctx/terraform-provider-agentctx/internal/datasource/render_plugin_manifest"
+	rendersubagent "github.com/agentctx/terraform-provider-agentctx/internal/datasource/render_subagent"
+	skilldependencies "github.com/agentctx/terraform-provider-agentctx/internal/datasource/skill_dependencies"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/fips"
+	contentversion "github.com/agentctx/terraform-provider-agentctx/internal/function/content_version"
+	semverbump "github.com/agentctx/terraform-provider-agentctx/internal/function/semver_bump"
+	"github.com/agentctx/terraform-provider-agentctx/internal/progress"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/receipts"
+	agentteam "github.com/agentctx/terraform-provider-agentctx/internal/resource/agent_team"
+	anthropicskill "github.com/agentctx/terraform-provider-agentctx/internal/resource/anthropic_skill"
+	catalogresource "github.com/agentctx/terraform-provider-agentctx/internal/resource/catalog"
+	commandresource "github.com/agentctx/terraform-provider-agentctx/internal/resource/command"
+	jsonfragment "github.com/agentctx/terraform-provider-agentctx/internal/resource/json_fragment"
+	layoutmigration "github.com/agentctx/terraform-provider-agentctx/internal/resource/layout_migration"
+	marketplaceresource "github.com/agentctx/terraform-provider-agentctx/internal/resource/marketplace"
+	memoryresource "github.com/agentctx/terraform-provider-agentctx/internal/resource/memory"
 	pluginresource "github.com/agentctx/terraform-provider-agentctx/internal/resource/plugin"
+	settingsresource "github.com/agentctx/terraform-provider-agentctx/internal/resource/settings"
 	skillresource "github.com/agentctx/terraform-provider-agentctx/internal/resource/skill"
+	skillpromotion "github.com/agentctx/terraform-provider-agentctx/internal/resource/skill_promotion"
+	skillrollback "github.com/agentctx/terraform-provider-agentctx/internal/resource/skill_rollback"
+	skillverification "github.com/agentctx/terraform-provider-agentctx/internal/resource/skill_verification"
 	skillversion "github.com/agentctx/terraform-provider-agentctx/internal/resource/skill_version"
 	subagentresource "github.com/agentctx/terraform-provider-agentctx/internal/resource/subagent"
 	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
 )
 
-// Ensure AgentCtxProvider satisfies the provider.Provider interface.
-var _ provider.Provider = &AgentCtxProvider{}
+// deploymentLabelPattern restricts workspace names and environment labels to
+// characters every storage backend accepts in object metadata values.
+var deploymentLabelPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Ensure AgentCtxProvider satisfies the provider.Provider and
+// provider.ProviderWithFunctions interfaces.
+var (
+	_ provider.Provider              = &AgentCtxProvider{}
+	_ provider.ProviderWithFunctions = &AgentCtxProvider{}
+)
 
 // AgentCtxProvider implements the agentctx Terraform provider.
 type AgentCtxProvider struct {
@@ -58,11 +108,110 @@ func (p *AgentCtxProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 				MarkdownDescription: "Maximum number of concurrent operations the provider will perform across all targets. Defaults to `16`.",
 				Optional:            true,
 			},
+			"refresh_concurrency": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of skill refreshes, each a read of one skill from one target, the provider runs at once across all resources. Targets of a single resource are refreshed in parallel within this limit. Independent of `max_concurrency`, which bounds uploads, deletes, and per-file checks. Defaults to `10`.",
+				Optional:            true,
+			},
 			"default_targets": schema.ListAttribute{
 				MarkdownDescription: "List of target names that resources will replicate to when their own `targets` argument is not set.",
 				Optional:            true,
 				ElementType:         types.StringType,
 			},
+			"max_requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "Maximum number of storage requests per second the provider issues across all targets combined, retries included. Use it to stay under bucket or account throttling limits on large applies. Unlimited when omitted.",
+				Optional:            true,
+			},
+			"max_upload_bandwidth": schema.Int64Attribute{
+				MarkdownDescription: "Maximum upload bandwidth in bytes per second, shared by every upload to every target, retries included. " +
+					"Keeps large deploys from developer laptops or constrained CI runners from saturating the link. Downloads and metadata requests are not limited. Unlimited when omitted.",
+				Optional: true,
+			},
+			"workspace": schema.StringAttribute{
+				MarkdownDescription: "Terraform workspace name recorded in each deployment's `manifest.json` and as object metadata. " +
+					"Defaults to the `TF_WORKSPACE` environment variable; set it to `terraform.workspace` to record the selected workspace in every run. " +
+					"Up to 64 letters, digits, `.`, `_`, and `-`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(deploymentLabelPattern, "must be 1-64 letters, digits, '.', '_', or '-'"),
+				},
+			},
+			"environment": schema.StringAttribute{
+				MarkdownDescription: "Environment label, e.g. `production`, recorded in each deployment's `manifest.json` and as object metadata, " +
+					"so it is obvious which environment stamped a deployment when several share a bucket. Up to 64 letters, digits, `.`, `_`, and `-`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(deploymentLabelPattern, "must be 1-64 letters, digits, '.', '_', or '-'"),
+				},
+			},
+			"read_only": schema.BoolAttribute{
+				MarkdownDescription: "Refuse every create, update, and delete with an error while reads keep working. " +
+					"Storage targets and the Anthropic client also reject writes, and the credential check only lists each target. " +
+					"Use it to run plans with production credentials in audit pipelines where writes must be impossible. Defaults to `false`.",
+				Optional: true,
+			},
+			"dry_run": schema.BoolAttribute{
+				MarkdownDescription: "Simulate every create, update, and delete instead of performing it. Resources compute hashes, render their files, and compare " +
+					"skill bundles with what each target serves, record the changes they would make in the `dry_run_report` file, and fail with an `AGX012` error, " +
+					"so that state is unchanged. Storage targets and the Anthropic client reject writes, as with `read_only`. Defaults to `false`.",
+				Optional: true,
+			},
+			"dry_run_report": schema.StringAttribute{
+				MarkdownDescription: "Path of the JSON report written when `dry_run` is set. Relative paths are resolved against the working directory. " +
+					"Defaults to `agentctx-dry-run.json`.",
+				Optional: true,
+			},
+			"receipts_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory to write a JSON receipts file to for every run of the provider that deploys a skill, listing each deployment to a target " +
+					"with its skill, target, deployment ID, bundle hash, duration, and outcome. The file is rewritten after every deployment. " +
+					"Relative paths are resolved against the working directory. Unset writes no receipts.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"fips_mode": schema.BoolAttribute{
+				MarkdownDescription: "Assert FIPS 140-3 compliance when the provider is configured. Configuration fails unless the provider runs in FIPS 140-3 mode " +
+					"(a binary built with `GOFIPS140`, or `GODEBUG=fips140=on`), the `anthropic` `base_url` uses `https`, and any CA bundle named by " +
+					"`SSL_CERT_FILE` or `AWS_CA_BUNDLE` contains only approved keys and signatures. S3 targets use FIPS endpoints. Defaults to `false`.",
+				Optional: true,
+			},
+			"state_content": schema.StringAttribute{
+				MarkdownDescription: "What computed attributes holding rendered file content keep in state: `\"full\"` stores the content, " +
+					"`\"hashes\"` stores null so that only the content hashes remain. Applies to `content` of `agentctx_subagent`, `agentctx_settings`, `agentctx_memory`, and `agentctx_command`, " +
+					"`coordination_content` of `agentctx_agent_team`, `manifest_json` of `agentctx_plugin`, and `catalog_json` and `catalog_markdown` of " +
+					"`agentctx_catalog`. Defaults to `\"full\"`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("full", "hashes"),
+				},
+			},
+			"compatibility_level": schema.StringAttribute{
+				MarkdownDescription: "Oldest Claude Code release, such as `2.0.30`, that the artifacts generated by `agentctx_plugin` and `agentctx_subagent` must load in. " +
+					"Hook events, manifest fields, and MCP server fields that need a newer release are left out of the generated files, with a warning naming each one. " +
+					"Resources can override it with their own `compatibility_level`. Unset, every configured feature is emitted.",
+				Optional: true,
+			},
+			"strict_drift": schema.BoolAttribute{
+				MarkdownDescription: "Fail refresh with an `AGX102` error when a skill on a target or a file generated by `agentctx_subagent`, `agentctx_agent_team`, " +
+					"`agentctx_plugin`, `agentctx_catalog`, `agentctx_settings`, `agentctx_json_fragment`, `agentctx_memory`, or `agentctx_command` was changed or removed outside Terraform, " +
+					"instead of recording the change in state and planning to reconcile it. Use it in pipelines that must halt on any out-of-band modification. Defaults to `false`.",
+				Optional: true,
+			},
+			"allowed_url_schemes": schema.ListAttribute{
+				MarkdownDescription: "URL schemes, in lowercase, that URL attributes of generated manifests may use: `homepage`, `repository`, `author.url`, and `mcp_server.url` of `agentctx_plugin`, " +
+					"`mcp_server.url` of `agentctx_subagent`, and the same attributes of their render data sources. URLs must also be absolute and must not contain a user name or password. " +
+					"Defaults to `[\"https\"]`; add `http` for, say, an MCP server on localhost.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(stringvalidator.RegexMatches(validators.URLSchemePattern, "must be a lowercase URL scheme such as https")),
+				},
+			},
+			"skip_target_validation": schema.BoolAttribute{
+				MarkdownDescription: "Skip the credential check performed when the provider is configured. By default the provider writes, reads, lists, and deletes a small probe object under each target's prefix and fails with a single error listing every target that lacks a permission. Defaults to `false`.",
+				Optional:            true,
+			},
 		},
 		Blocks: map[string]schema.Block{
 			"anthropic": schema.ListNestedBlock{
@@ -83,18 +232,101 @@ func (p *AgentCtxProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 							Optional:            true,
 						},
 						"timeout_seconds": schema.Int64Attribute{
-							MarkdownDescription: "Timeout in seconds for individual Anthropic API requests. Defaults to `60`.",
+							MarkdownDescription: "Timeout in seconds for each attempt of an Anthropic API request, including reading the response. Used for the request classes whose own timeout is not set. Defaults to `60`.",
+							Optional:            true,
+						},
+						"metadata_timeout_seconds": schema.Int64Attribute{
+							MarkdownDescription: "Timeout in seconds for each attempt of a small JSON request, such as reading, listing, or deleting skills and versions. Keep it short so a hung request fails over to a retry instead of stalling refresh. Defaults to `timeout_seconds`.",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"upload_timeout_seconds": schema.Int64Attribute{
+							MarkdownDescription: "Timeout in seconds for each attempt of a request that transfers a version's files: creating a skill or version, and downloading a version's content. Raise it for large bundles or slow links. Defaults to `timeout_seconds`.",
 							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
 						},
 						"base_url": schema.StringAttribute{
 							MarkdownDescription: "Override the Anthropic API base URL. Useful for testing with a mock server.",
 							Optional:            true,
 						},
+						"registry_failure_policy": schema.StringAttribute{
+							MarkdownDescription: "What an `agentctx_skill` with an enabled `anthropic` block does when the registry is unavailable, i.e. requests still fail with network errors, `429`, or `5xx` after all retries, or the circuit breaker is open: `\"fail\"` fails the apply; `\"warn_and_skip\"` deploys the skill to its storage targets only, with a warning, and retries the registration on the next apply. Defaults to `\"fail\"`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(anthropic.FailurePolicyFail, anthropic.FailurePolicyWarnAndSkip),
+							},
+						},
+						"circuit_breaker_threshold": schema.Int64Attribute{
+							MarkdownDescription: "Number of consecutive registry requests that fail as unavailable after which the provider stops sending requests to the registry for 30 seconds, failing them immediately instead. Defaults to `3`.",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"max_requests_per_minute": schema.Int64Attribute{
+							MarkdownDescription: "Maximum number of Anthropic API requests per minute, shared by every resource and data source of the provider. Requests, including retries, are spaced evenly at this rate, and a `429` response with a `Retry-After` header pauses every request until the deadline has passed. Set it below the rate limit of your API key when an apply registers many skills. Unset sends requests as soon as they are made.",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"list_page_size": schema.Int64Attribute{
+							MarkdownDescription: "Number of skills or versions requested per page when listing them, for example to find every version of a skill on destroy. Every page is read either way; larger pages take fewer requests. Unset uses the API's default page size.",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"debug_http": schema.BoolAttribute{
+							MarkdownDescription: "Log every Anthropic API request attempt at debug level (`TF_LOG=DEBUG`): method, path, status, duration, the response's `request-id`, and JSON payloads with credential and content fields redacted. The API key, request headers, and skill file contents are never logged. Defaults to `false`.",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"concurrency": schema.ListNestedBlock{
+				MarkdownDescription: "Tunes how the `max_concurrency` slots are shared between operations. At most one block may be specified.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"read_slots": schema.Int64Attribute{
+							MarkdownDescription: "Maximum number of slots refresh-time reads, such as the per-file checks of `deep_drift_check`, may hold at once. Leaves the remaining slots to uploads and deletes so a refresh of many resources does not stall an apply. Defaults to `max_concurrency`.",
+							Optional:            true,
+						},
+						"priority": schema.StringAttribute{
+							MarkdownDescription: "Which kind of operation is granted free slots first when both are waiting: `\"write\"` (uploads and deletes) or `\"read\"`. Defaults to `\"write\"`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("write", "read"),
+							},
+						},
+						"bytes_per_slot": schema.Int64Attribute{
+							MarkdownDescription: "Weight uploads by size: a file takes one slot per started `bytes_per_slot` bytes, up to `max_concurrency`, so a few large files cannot saturate a target alongside many small ones. Unset or `0` counts every operation as one slot.",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"progress": schema.ListNestedBlock{
+				MarkdownDescription: "Configures progress reports for long uploads: skill deploys to targets and Anthropic version uploads. At most one block may be specified. Without it, progress is logged every 10 seconds.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"interval_seconds": schema.Int64Attribute{
+							MarkdownDescription: "Seconds between progress log lines (files and bytes done, ETA) at the `INFO` level. `0` turns logging off. Defaults to `10`.",
+							Optional:            true,
+						},
+						"json_file": schema.StringAttribute{
+							MarkdownDescription: "Path of a file to append machine-readable progress to, one JSON object per line, written at the start and end of every upload and at every interval.",
+							Optional:            true,
+						},
 					},
 				},
 			},
 			"target": schema.ListNestedBlock{
-				MarkdownDescription: "Defines a storage target for skill artifacts. At least one target block must be configured.",
+				MarkdownDescription: "Defines a storage target for skill artifacts. At least one target block must be configured unless an `anthropic` block is configured for registry-only use.",
 				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
@@ -102,19 +334,25 @@ func (p *AgentCtxProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 							Required:            true,
 						},
 						"type": schema.StringAttribute{
-							MarkdownDescription: "Storage backend type. Supported values are `\"s3\"`, `\"azure\"`, and `\"gcs\"`.",
-							Required:            true,
+							MarkdownDescription: "Storage backend type. Supported values are `\"s3\"`, `\"azure\"`, and `\"gcs\"`, and the S3-compatible presets `\"r2\"` " +
+								"(Cloudflare R2) and `\"spaces\"` (DigitalOcean Spaces), which set the endpoint, addressing style, and signing region of the S3 backend.",
+							Required: true,
 						},
 						"bucket": schema.StringAttribute{
-							MarkdownDescription: "S3 or GCS bucket name. Required for `s3` and `gcs` target types.",
+							MarkdownDescription: "S3, R2, Spaces, or GCS bucket name. Required for `s3`, `r2`, `spaces`, and `gcs` target types.",
 							Optional:            true,
 						},
 						"region": schema.StringAttribute{
-							MarkdownDescription: "AWS region for the S3 bucket. Required for `s3` target type.",
-							Optional:            true,
+							MarkdownDescription: "AWS region for the S3 bucket. Required for `s3` target type. For `spaces` targets, the datacenter of the Space, such as `nyc3`. " +
+								"Not used by `r2` targets.",
+							Optional: true,
 						},
 						"kms_key_id": schema.StringAttribute{
-							MarkdownDescription: "AWS KMS key ID or ARN used for server-side encryption of S3 objects.",
+							MarkdownDescription: "AWS KMS key ID or ARN used for server-side encryption of S3 objects. Not supported by `r2` and `spaces` targets.",
+							Optional:            true,
+						},
+						"account_id": schema.StringAttribute{
+							MarkdownDescription: "Cloudflare account ID, which names the R2 endpoint `https://<account_id>.r2.cloudflarestorage.com`. Required for `r2` target type.",
 							Optional:            true,
 						},
 						"storage_account": schema.StringAttribute{
@@ -129,10 +367,27 @@ func (p *AgentCtxProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 							MarkdownDescription: "Azure encryption scope to apply when writing blobs.",
 							Optional:            true,
 						},
+						"sas_token": schema.StringAttribute{
+							MarkdownDescription: "Shared access signature for an `azure` target: the query string of a SAS URL, with or without the leading `?`. " +
+								"It needs read, add, create, write, delete, and list permissions on the container. Only valid for `azure` targets; conflicts with `managed_identity_client_id`.",
+							Optional:  true,
+							Sensitive: true,
+						},
+						"managed_identity_client_id": schema.StringAttribute{
+							MarkdownDescription: "Client ID of the user-assigned managed identity an `azure` target authenticates with. " +
+								"When neither it nor `sas_token` is set, `DefaultAzureCredential` is used, which includes a system-assigned managed identity. Only valid for `azure` targets.",
+							Optional: true,
+						},
 						"kms_key_name": schema.StringAttribute{
 							MarkdownDescription: "GCS Cloud KMS key resource name used for object encryption.",
 							Optional:            true,
 						},
+						"credentials": schema.StringAttribute{
+							MarkdownDescription: "Google Cloud service account key for a `gcs` target: the JSON key itself or the path of a key file. " +
+								"When omitted, Application Default Credentials are used. Only valid for `gcs` targets.",
+							Optional:  true,
+							Sensitive: true,
+						},
 						"prefix": schema.StringAttribute{
 							MarkdownDescription: "Key prefix prepended to all object paths within the target bucket or container.",
 							Optional:            true,
@@ -153,6 +408,23 @@ func (p *AgentCtxProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 							MarkdownDescription: "Retry backoff strategy for this target. Supported values are `\"exponential\"` and `\"linear\"`. Defaults to `\"exponential\"`.",
 							Optional:            true,
 						},
+						"replica_of": schema.StringAttribute{
+							MarkdownDescription: "Name of the target this bucket or container is replicated from, e.g. by S3 Cross-Region Replication. " +
+								"The provider never writes to a replica: after each deploy to the primary it checks that the replica serves the new " +
+								"manifest and `ACTIVE` pointer, and reports replication lag as a warning. A replica cannot be used in `targets` or `default_targets`.",
+							Optional: true,
+						},
+						"replication_timeout_seconds": schema.Int64Attribute{
+							MarkdownDescription: "How long to wait for a deploy to the primary to appear on this replica before warning about replication lag. " +
+								"Only valid with `replica_of`. Defaults to `300`.",
+							Optional: true,
+						},
+						"read_after_write_seconds": schema.Int64Attribute{
+							MarkdownDescription: "How long reads of this target may lag behind writes, for S3-compatible stores with weaker than read-after-write consistency. " +
+								"After each deploy the provider reads `ACTIVE` and the manifest back until they show the new deployment, for up to this long, " +
+								"and a refresh that finds `ACTIVE` differing from state retries for as long before reporting drift. Not valid with `replica_of`. Defaults to `0`, which reads once.",
+							Optional: true,
+						},
 					},
 				},
 			},
@@ -183,6 +455,55 @@ func (p *AgentCtxProvider) Configure(ctx context.Context, req provider.Configure
 		maxConcurrency = config.MaxConcurrency.ValueInt64()
 	}
 
+	if maxConcurrency < 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_concurrency"),
+			errcode.InvalidConfig.Summary("Invalid Concurrency"),
+			fmt.Sprintf("max_concurrency must be at least 1, got %d.", maxConcurrency),
+		)
+		return
+	}
+
+	refreshConcurrency := int64(10)
+	if !config.RefreshConcurrency.IsNull() && !config.RefreshConcurrency.IsUnknown() {
+		refreshConcurrency = config.RefreshConcurrency.ValueInt64()
+	}
+
+	if refreshConcurrency < 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("refresh_concurrency"),
+			errcode.InvalidConfig.Summary("Invalid Concurrency"),
+			fmt.Sprintf("refresh_concurrency must be at least 1, got %d.", refreshConcurrency),
+		)
+		return
+	}
+
+	var compatibilityLevel *claudeversion.Level
+	if !config.CompatibilityLevel.IsNull() && !config.CompatibilityLevel.IsUnknown() {
+		level, err := claudeversion.ParseLevel(config.CompatibilityLevel.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("compatibility_level"),
+				errcode.ClaudeVersion.Summary("Invalid Compatibility Level"),
+				fmt.Sprintf("compatibility_level %q is not a Claude Code version: %s", config.CompatibilityLevel.ValueString(), err),
+			)
+			return
+		}
+		compatibilityLevel = level
+	}
+
+	schedCfg, d := schedulerConfig(config.Concurrency, maxConcurrency)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	reporter, d := progressReporter(config.Progress)
+	resp.Diagnostics.Append(d...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var defaultTargets []string
 	if !config.DefaultTargets.IsNull() && !config.DefaultTargets.IsUnknown() {
 		resp.Diagnostics.Append(config.DefaultTargets.ElementsAs(ctx, &defaultTargets, false)...)
@@ -191,33 +512,119 @@ func (p *AgentCtxProvider) Configure(ctx context.Context, req provider.Configure
 		}
 	}
 
+	var allowedURLSchemes []string
+	if !config.AllowedURLSchemes.IsNull() && !config.AllowedURLSchemes.IsUnknown() {
+		resp.Diagnostics.Append(config.AllowedURLSchemes.ElementsAs(ctx, &allowedURLSchemes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// A single limiter shared by every target bounds the provider-wide
+	// request rate.
+	var limiter *rate.Limiter
+	if !config.MaxRequestsPerSecond.IsNull() && !config.MaxRequestsPerSecond.IsUnknown() {
+		qps := config.MaxRequestsPerSecond.ValueFloat64()
+		if qps <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_requests_per_second"),
+				errcode.InvalidConfig.Summary("Invalid Request Rate"),
+				fmt.Sprintf("max_requests_per_second must be greater than zero, got %v.", qps),
+			)
+			return
+		}
+		limiter = rate.NewLimiter(rate.Limit(qps), max(1, int(qps)))
+	}
+
+	// Likewise a single byte-counting limiter bounds the provider-wide
+	// upload bandwidth.
+	var uploadLimiter *rate.Limiter
+	if !config.MaxUploadBandwidth.IsNull() && !config.MaxUploadBandwidth.IsUnknown() {
+		bps := config.MaxUploadBandwidth.ValueInt64()
+		if bps <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_upload_bandwidth"),
+				errcode.InvalidConfig.Summary("Invalid Upload Bandwidth"),
+				fmt.Sprintf("max_upload_bandwidth must be greater than zero, got %d.", bps),
+			)
+			return
+		}
+		uploadLimiter = target.NewBandwidthLimiter(bps)
+	}
+
+	workspace, diags := workspaceName(config.Workspace)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readOnly := false
+	if !config.ReadOnly.IsNull() && !config.ReadOnly.IsUnknown() {
+		readOnly = config.ReadOnly.ValueBool()
+	}
+
+	var dryRunReport *dryrun.Report
+	if config.DryRun.ValueBool() {
+		reportPath := "agentctx-dry-run.json"
+		if !config.DryRunReport.IsNull() && !config.DryRunReport.IsUnknown() {
+			reportPath = config.DryRunReport.ValueString()
+		}
+		dryRunReport = dryrun.New(reportPath, p.version)
+	}
+
+	var receiptWriter *receipts.Writer
+	if dir := config.ReceiptsDir.ValueString(); dir != "" {
+		receiptWriter = receipts.NewWriter(dir, receipts.Run{
+			ProviderVersion: p.version,
+			Workspace:       workspace,
+			Environment:     config.Environment.ValueString(),
+		})
+	}
+
+	// Storage targets and the Anthropic client reject writes during a dry
+	// run too, in case a resource reaches them.
+	rejectWrites := readOnly || dryRunReport != nil
+
+	fipsMode := false
+	if !config.FIPSMode.IsNull() && !config.FIPSMode.IsUnknown() {
+		fipsMode = config.FIPSMode.ValueBool()
+	}
+	if fipsMode {
+		resp.Diagnostics.Append(checkFIPS(config)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// ----------------------------------------------------------------
 	// Validate and build targets
 	// ----------------------------------------------------------------
-	if len(config.Targets) == 0 {
+	// A provider with only an anthropic block is valid: registry-only
+	// resources such as agentctx_anthropic_skill need no storage.
+	if len(config.Targets) == 0 && len(config.Anthropic) == 0 {
 		resp.Diagnostics.AddError(
-			"Missing Target Configuration",
-			"At least one target block must be configured in the provider.",
+			errcode.InvalidConfig.Summary("Missing Target Configuration"),
+			"At least one target block must be configured in the provider, unless it only manages registry skills "+
+				"through an anthropic block.",
 		)
 		return
 	}
 
-	targets := make(map[string]target.Target, len(config.Targets))
-	targetConfigs := make(map[string]TargetConfigModel, len(config.Targets))
+	targets := providerdata.NewTargetRegistry()
 
 	for _, tc := range config.Targets {
 		name := tc.Name.ValueString()
 		if name == "" {
 			resp.Diagnostics.AddError(
-				"Invalid Target Configuration",
+				errcode.InvalidConfig.Summary("Invalid Target Configuration"),
 				"Every target block must have a non-empty name attribute.",
 			)
 			return
 		}
 
-		if _, exists := targets[name]; exists {
+		if _, exists := targets.Get(name); exists {
 			resp.Diagnostics.AddError(
-				"Duplicate Target Name",
+				errcode.DuplicateName.Summary("Duplicate Target Name"),
 				fmt.Sprintf("Target name %q is defined more than once.", name),
 			)
 			return
@@ -226,12 +633,45 @@ func (p *AgentCtxProvider) Configure(ctx context.Context, req provider.Configure
 		targetType := tc.Type.ValueString()
 		if targetType == "" {
 			resp.Diagnostics.AddError(
-				"Invalid Target Configuration",
+				errcode.InvalidConfig.Summary("Invalid Target Configuration"),
 				fmt.Sprintf("Target %q must have a non-empty type attribute.", name),
 			)
 			return
 		}
 
+		// Explicit credentials are specific to one backend.
+		for _, auth := range []struct {
+			attr, only string
+			set        bool
+		}{
+			{"credentials", "gcs", tc.Credentials.ValueString() != ""},
+			{"sas_token", "azure", tc.SASToken.ValueString() != ""},
+			{"managed_identity_client_id", "azure", tc.ManagedIdentityClientID.ValueString() != ""},
+		} {
+			if auth.set && targetType != auth.only {
+				resp.Diagnostics.AddError(
+					errcode.InvalidConfig.Summary("Invalid Target Configuration"),
+					fmt.Sprintf("Target %q sets %s, which is only supported for %s targets. "+
+						"%s targets use their SDK's default credential chain.", name, auth.attr, auth.only, targetType),
+				)
+				return
+			}
+		}
+		if tc.AccountID.ValueString() != "" && targetType != "r2" {
+			resp.Diagnostics.AddError(
+				errcode.InvalidConfig.Summary("Invalid Target Configuration"),
+				fmt.Sprintf("Target %q sets account_id, which is only supported for r2 targets.", name),
+			)
+			return
+		}
+		if tc.SASToken.ValueString() != "" && tc.ManagedIdentityClientID.ValueString() != "" {
+			resp.Diagnostics.AddError(
+				errcode.InvalidConfig.Summary("Invalid Target Configuration"),
+				fmt.Sprintf("Target %q sets both sas_token and managed_identity_client_id. Set at most one of them.", name),
+			)
+			return
+		}
+
 		// Resolve per-target defaults.
 		tMaxRetries := int64(3)
 		if !tc.MaxRetries.IsNull() && !tc.MaxRetries.IsUnknown() {
@@ -259,37 +699,86 @@ func (p *AgentCtxProvider) Configure(ctx context.Context, req provider.Configure
 			Bucket:          tc.Bucket.ValueString(),
 			Region:          tc.Region.ValueString(),
 			KMSKeyID:        tc.KMSKeyID.ValueString(),
+			AccountID:       tc.AccountID.ValueString(),
 			StorageAccount:  tc.StorageAccount.ValueString(),
 			ContainerName:   tc.ContainerName.ValueString(),
 			EncryptionScope: tc.EncryptionScope.ValueString(),
 			KMSKeyName:      tc.KMSKeyName.ValueString(),
+			GCSCredentials:  tc.Credentials.ValueString(),
+			AzureSASToken:   tc.SASToken.ValueString(),
+			AzureClientID:   tc.ManagedIdentityClientID.ValueString(),
 			Prefix:          tc.Prefix.ValueString(),
 			MaxConcurrency:  int(tMaxConcurrency),
 			MaxRetries:      int(tMaxRetries),
 			TimeoutSeconds:  int(tTimeoutSeconds),
 			RetryBackoff:    tRetryBackoff,
+			Limiter:         limiter,
+			UploadLimiter:   uploadLimiter,
+			ReadOnly:        rejectWrites,
+			FIPS:            fipsMode,
 		})
 		if err != nil {
 			resp.Diagnostics.AddError(
-				"Target Initialization Failed",
+				errcode.TargetInit.Summary("Target Initialization Failed"),
 				fmt.Sprintf("Failed to create target %q: %s", name, err),
 			)
 			return
 		}
 
-		targets[name] = t
-		targetConfigs[name] = tc
+		if err := targets.Register(name, t, tc); err != nil {
+			resp.Diagnostics.AddError(
+				errcode.DuplicateName.Summary("Duplicate Target Name"),
+				fmt.Sprintf("Target name %q is defined more than once.", name),
+			)
+			return
+		}
 	}
 
-	// Validate that every entry in default_targets references a defined target.
+	resp.Diagnostics.Append(validateReplicas(targets)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Validate that every entry in default_targets references a defined,
+	// writable target.
 	for _, dt := range defaultTargets {
-		if _, exists := targets[dt]; !exists {
+		cfg, exists := targets.Config(dt)
+		if !exists {
 			resp.Diagnostics.AddError(
-				"Invalid Default Target",
+				errcode.UnknownTarget.Summary("Invalid Default Target"),
 				fmt.Sprintf("default_targets references %q which is not defined as a target block.", dt),
 			)
 			return
 		}
+		if cfg.ReplicaOf.ValueString() != "" {
+			resp.Diagnostics.AddError(
+				errcode.InvalidConfig.Summary("Invalid Default Target"),
+				fmt.Sprintf("default_targets references %q, which is a replica of %q. Deploy to the primary instead; "+
+					"replicas are verified automatically.", dt, cfg.ReplicaOf.ValueString()),
+			)
+			return
+		}
+	}
+
+	// ----------------------------------------------------------------
+	// Verify target credentials
+	// ----------------------------------------------------------------
+	skipTargetValidation := false
+	if !config.SkipTargetValidation.IsNull() && !config.SkipTargetValidation.IsUnknown() {
+		skipTargetValidation = config.SkipTargetValidation.ValueBool()
+	}
+
+	if !skipTargetValidation {
+		if failures := probeTargets(ctx, targets, rejectWrites); len(failures) > 0 {
+			resp.Diagnostics.AddError(
+				errcode.TargetInit.Summary("Target Validation Failed"),
+				fmt.Sprintf("The provider could not use %d of %d configured targets:\n\n%s\n\n"+
+					"Check that the credentials for each target can put, get, list, and delete objects under its prefix, "+
+					"or set skip_target_validation = true to skip this check.",
+					len(failures), targets.Len(), strings.Join(failures, "\n")),
+			)
+			return
+		}
 	}
 
 	// ----------------------------------------------------------------
@@ -297,7 +786,7 @@ func (p *AgentCtxProvider) Configure(ctx context.Context, req provider.Configure
 	// ----------------------------------------------------------------
 	if len(config.Anthropic) > 1 {
 		resp.Diagnostics.AddError(
-			"Invalid Anthropic Configuration",
+			errcode.InvalidConfig.Summary("Invalid Anthropic Configuration"),
 			"At most one anthropic block may be specified.",
 		)
 		return
@@ -310,7 +799,7 @@ func (p *AgentCtxProvider) Configure(ctx context.Context, req provider.Configure
 		apiKey := ac.APIKey.ValueString()
 		if apiKey == "" {
 			resp.Diagnostics.AddError(
-				"Invalid Anthropic Configuration",
+				errcode.InvalidConfig.Summary("Invalid Anthropic Configuration"),
 				"The api_key attribute in the anthropic block must not be empty.",
 			)
 			return
@@ -337,11 +826,20 @@ func (p *AgentCtxProvider) Configure(ctx context.Context, req provider.Configure
 		}
 
 		anthropicClient = anthropic.NewClient(anthropic.ClientConfig{
-			APIKey:         apiKey,
-			BaseURL:        aBaseURL,
-			MaxRetries:     int(aMaxRetries),
-			DestroyRemote:  aDestroyRemote,
-			TimeoutSeconds: int(aTimeoutSeconds),
+			APIKey:                 apiKey,
+			BaseURL:                aBaseURL,
+			MaxRetries:             int(aMaxRetries),
+			DestroyRemote:          aDestroyRemote,
+			TimeoutSeconds:         int(aTimeoutSeconds),
+			MetadataTimeoutSeconds: int(ac.MetadataTimeoutSeconds.ValueInt64()),
+			UploadTimeoutSeconds:   int(ac.UploadTimeoutSeconds.ValueInt64()),
+			Progress:               reporter,
+			ReadOnly:               rejectWrites,
+			BreakerThreshold:       int(ac.CircuitBreakerThreshold.ValueInt64()),
+			FailurePolicy:          ac.RegistryFailurePolicy.ValueString(),
+			DebugHTTP:              ac.DebugHTTP.ValueBool(),
+			RequestsPerMinute:      int(ac.MaxRequestsPerMinute.ValueInt64()),
+			PageSize:               int(ac.ListPageSize.ValueInt64()),
 		})
 	}
 
@@ -349,23 +847,294 @@ func (p *AgentCtxProvider) Configure(ctx context.Context, req provider.Configure
 	// Build ProviderData and share with resources / data sources
 	// ----------------------------------------------------------------
 	pd := &ProviderData{
-		CanonicalStore: canonicalStore,
-		DefaultTargets: defaultTargets,
-		Targets:        targets,
-		TargetConfigs:  targetConfigs,
-		Anthropic:      anthropicClient,
-		Semaphore:      semaphore.NewWeighted(maxConcurrency),
+		CanonicalStore:     canonicalStore,
+		DefaultTargets:     defaultTargets,
+		Targets:            targets,
+		Anthropic:          anthropicClient,
+		Scheduler:          concurrency.New(schedCfg),
+		RefreshPool:        concurrency.NewPool(int(refreshConcurrency)),
+		Version:            p.version,
+		Listeners:          []engine.Listener{engine.NewProgressListener(reporter), engine.NewReceiptListener(receiptWriter)},
+		ReadOnly:           readOnly,
+		DryRun:             dryRunReport,
+		Workspace:          workspace,
+		Environment:        config.Environment.ValueString(),
+		OmitContent:        config.StateContent.ValueString() == "hashes",
+		CompatibilityLevel: compatibilityLevel,
+		StrictDrift:        config.StrictDrift.ValueBool(),
+		AllowedURLSchemes:  allowedURLSchemes,
 	}
 
 	resp.DataSourceData = pd
 	resp.ResourceData = pd
 }
 
+// workspaceName returns the configured workspace, falling back to the
+// TF_WORKSPACE environment variable. Terraform does not tell providers which
+// workspace is selected, so without either the workspace is not recorded.
+func workspaceName(configured types.String) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if !configured.IsNull() && !configured.IsUnknown() {
+		return configured.ValueString(), diags
+	}
+	ws := os.Getenv("TF_WORKSPACE")
+	if ws != "" && !deploymentLabelPattern.MatchString(ws) {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Workspace Name"),
+			fmt.Sprintf("TF_WORKSPACE is %q, which cannot be recorded in object metadata. "+
+				"Set the provider's workspace argument to 1-64 letters, digits, '.', '_', or '-'.", ws),
+		)
+		return "", diags
+	}
+	return ws, diags
+}
+
+// checkFIPS returns an error for every reason config cannot run in FIPS
+// 140-3 mode: a Go Cryptographic Module not in FIPS mode, a non-https
+// anthropic base_url, or a custom CA bundle with unapproved keys or
+// signatures.
+func checkFIPS(config ProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !fips.Enabled() {
+		diags.AddAttributeError(
+			path.Root("fips_mode"),
+			errcode.FIPS.Summary("FIPS Mode Unavailable"),
+			"fips_mode = true requires the provider to run in FIPS 140-3 mode, but the Go Cryptographic Module is not in FIPS mode. "+
+				"Use a provider binary built with GOFIPS140=v1.0.0 (make build-fips), or set GODEBUG=fips140=on in the environment Terraform runs in.",
+		)
+	}
+
+	for i, ac := range config.Anthropic {
+		if ac.BaseURL.IsNull() || ac.BaseURL.IsUnknown() || ac.BaseURL.ValueString() == "" {
+			continue
+		}
+		if err := fips.CheckURL(ac.BaseURL.ValueString()); err != nil {
+			diags.AddAttributeError(
+				path.Root("anthropic").AtListIndex(i).AtName("base_url"),
+				errcode.FIPS.Summary("Non-Compliant Base URL"),
+				fmt.Sprintf("base_url %q cannot be used with fips_mode = true: %s", ac.BaseURL.ValueString(), err),
+			)
+		}
+	}
+
+	for _, name := range fips.CABundleEnvVars {
+		bundle := os.Getenv(name)
+		if bundle == "" {
+			continue
+		}
+		if err := fips.CheckCABundle(bundle); err != nil {
+			diags.AddError(
+				errcode.FIPS.Summary("Non-Compliant CA Bundle"),
+				fmt.Sprintf("%s names the CA bundle %q, which cannot be used with fips_mode = true: %s", name, bundle, err),
+			)
+		}
+	}
+
+	return diags
+}
+
+// schedulerConfig resolves the optional concurrency block into the
+// configuration of the provider-wide scheduler.
+func schedulerConfig(blocks []ConcurrencyModel, maxConcurrency int64) (concurrency.Config, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	cfg := concurrency.Config{MaxSlots: maxConcurrency, Priority: concurrency.Write}
+
+	if len(blocks) > 1 {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Concurrency Configuration"),
+			"At most one concurrency block may be specified.",
+		)
+		return cfg, diags
+	}
+	if len(blocks) == 0 {
+		return cfg, diags
+	}
+
+	b := blocks[0]
+	blockPath := path.Root("concurrency").AtListIndex(0)
+	if !b.ReadSlots.IsNull() && !b.ReadSlots.IsUnknown() {
+		cfg.ReadSlots = b.ReadSlots.ValueInt64()
+		if cfg.ReadSlots < 1 || cfg.ReadSlots > maxConcurrency {
+			diags.AddAttributeError(
+				blockPath.AtName("read_slots"),
+				errcode.InvalidConfig.Summary("Invalid Concurrency Configuration"),
+				fmt.Sprintf("read_slots must be between 1 and max_concurrency (%d), got %d.", maxConcurrency, cfg.ReadSlots),
+			)
+		}
+	}
+	if b.Priority.ValueString() == "read" {
+		cfg.Priority = concurrency.Read
+	}
+	if !b.BytesPerSlot.IsNull() && !b.BytesPerSlot.IsUnknown() {
+		cfg.BytesPerSlot = b.BytesPerSlot.ValueInt64()
+		if cfg.BytesPerSlot < 0 {
+			diags.AddAttributeError(
+				blockPath.AtName("bytes_per_slot"),
+				errcode.InvalidConfig.Summary("Invalid Concurrency Configuration"),
+				fmt.Sprintf("bytes_per_slot must not be negative, got %d.", cfg.BytesPerSlot),
+			)
+		}
+	}
+	return cfg, diags
+}
+
+// progressReporter resolves the optional progress block into the reporter
+// shared by the engine and the Anthropic client.
+func progressReporter(blocks []ProgressModel) (*progress.Reporter, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if len(blocks) > 1 {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Progress Configuration"),
+			"At most one progress block may be specified.",
+		)
+		return nil, diags
+	}
+	if len(blocks) == 0 {
+		return progress.NewReporter(progress.DefaultInterval, ""), diags
+	}
+
+	b := blocks[0]
+	interval := progress.DefaultInterval
+	if !b.IntervalSeconds.IsNull() && !b.IntervalSeconds.IsUnknown() {
+		secs := b.IntervalSeconds.ValueInt64()
+		if secs < 0 {
+			diags.AddAttributeError(
+				path.Root("progress").AtListIndex(0).AtName("interval_seconds"),
+				errcode.InvalidConfig.Summary("Invalid Progress Configuration"),
+				fmt.Sprintf("interval_seconds must not be negative, got %d.", secs),
+			)
+			return nil, diags
+		}
+		interval = time.Duration(secs) * time.Second
+	}
+	return progress.NewReporter(interval, b.JSONFile.ValueString()), diags
+}
+
+// validateReplicas checks the replica_of, replication_timeout_seconds,
+// and read_after_write_seconds attributes of every target: a replica must
+// name another defined target that is not itself a replica, and is never
+// written to.
+func validateReplicas(targets providerdata.TargetRegistry) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, name := range targets.Names() {
+		cfg, _ := targets.Config(name)
+		primary := cfg.ReplicaOf.ValueString()
+
+		if !cfg.ReadAfterWriteSeconds.IsNull() && !cfg.ReadAfterWriteSeconds.IsUnknown() {
+			switch {
+			case primary != "":
+				diags.AddError(
+					errcode.InvalidConfig.Summary("Invalid Target Configuration"),
+					fmt.Sprintf("Target %q sets read_after_write_seconds, but it is a replica and never written to. Use replication_timeout_seconds instead.", name),
+				)
+			case cfg.ReadAfterWriteSeconds.ValueInt64() < 0:
+				diags.AddError(
+					errcode.InvalidConfig.Summary("Invalid Target Configuration"),
+					fmt.Sprintf("Target %q: read_after_write_seconds must not be negative, got %d.",
+						name, cfg.ReadAfterWriteSeconds.ValueInt64()),
+				)
+			}
+		}
+
+		if !cfg.ReplicationTimeoutSeconds.IsNull() && !cfg.ReplicationTimeoutSeconds.IsUnknown() {
+			switch {
+			case primary == "":
+				diags.AddError(
+					errcode.InvalidConfig.Summary("Invalid Target Configuration"),
+					fmt.Sprintf("Target %q sets replication_timeout_seconds without replica_of.", name),
+				)
+			case cfg.ReplicationTimeoutSeconds.ValueInt64() < 0:
+				diags.AddError(
+					errcode.InvalidConfig.Summary("Invalid Target Configuration"),
+					fmt.Sprintf("Target %q: replication_timeout_seconds must not be negative, got %d.",
+						name, cfg.ReplicationTimeoutSeconds.ValueInt64()),
+				)
+			}
+		}
+
+		if primary == "" {
+			continue
+		}
+		primaryCfg, ok := targets.Config(primary)
+		switch {
+		case primary == name:
+			diags.AddError(
+				errcode.InvalidConfig.Summary("Invalid Target Configuration"),
+				fmt.Sprintf("Target %q cannot be a replica of itself.", name),
+			)
+		case !ok:
+			diags.AddError(
+				errcode.UnknownTarget.Summary("Invalid Replica Target"),
+				fmt.Sprintf("Target %q sets replica_of = %q, which is not defined as a target block.", name, primary),
+			)
+		case primaryCfg.ReplicaOf.ValueString() != "":
+			diags.AddError(
+				errcode.InvalidConfig.Summary("Invalid Replica Target"),
+				fmt.Sprintf("Target %q sets replica_of = %q, which is itself a replica of %q. "+
+					"Point replica_of at the target the provider deploys to.", name, primary, primaryCfg.ReplicaOf.ValueString()),
+			)
+		}
+	}
+	return diags
+}
+
+// probeTargets runs target.Probe against every target in parallel and returns
+// one line per failing target, sorted by target name. Replica targets, and
+// every target when readOnly is set, are only checked for read access with
+// target.ProbeReadOnly.
+func probeTargets(ctx context.Context, targets providerdata.TargetRegistry, readOnly bool) []string {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		failures []string
+	)
+
+	for _, name := range targets.Names() {
+		t, _ := targets.Get(name)
+		wg.Add(1)
+		go func(name string, t target.Target) {
+			defer wg.Done()
+
+			tflog.Debug(ctx, "probing target credentials", map[string]interface{}{
+				"target": name,
+			})
+
+			probe := target.Probe
+			if cfg, _ := targets.Config(name); readOnly || cfg.ReplicaOf.ValueString() != "" {
+				probe = target.ProbeReadOnly
+			}
+			if err := probe(ctx, t); err != nil {
+				msg := strings.ReplaceAll(err.Error(), "\n", "; ")
+				mu.Lock()
+				failures = append(failures, fmt.Sprintf("  - target %q: %s", name, msg))
+				mu.Unlock()
+			}
+		}(name, t)
+	}
+	wg.Wait()
+
+	sort.Strings(failures)
+	return failures
+}
+
 // Resources returns the set of resource types supported by this provider.
 func (p *AgentCtxProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
+		agentteam.NewAgentTeamResource,
+		anthropicskill.NewAnthropicSkillResource,
+		catalogresource.NewCatalogResource,
+		commandresource.NewCommandResource,
+		jsonfragment.NewJSONFragmentResource,
+		layoutmigration.NewLayoutMigrationResource,
+		marketplaceresource.NewMarketplaceResource,
+		memoryresource.NewMemoryResource,
 		pluginresource.NewPluginResource,
+		settingsresource.NewSettingsResource,
 		skillresource.NewSkillResource,
+		skillpromotion.NewSkillPromotionResource,
+		skillrollback.NewSkillRollbackResource,
+		skillverification.NewSkillVerificationResource,
 		skillversion.NewSkillVersionResource,
 		subagentresource.NewSubagentResource,
 	}
@@ -373,5 +1142,19 @@ func (p *AgentCtxProvider) Resources(_ context.Context) []func() resource.Resour
 
 // DataSources returns the set of data source types supported by this provider.
 func (p *AgentCtxProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		hookmatch.NewHookMatchDataSource,
+		rendersubagent.NewRenderSubagentDataSource,
+		renderpluginmanifest.NewRenderPluginManifestDataSource,
+		skilldependencies.NewSkillDependenciesDataSource,
+	}
+}
+
+// Functions returns the provider-defined functions, which require Terraform
+// 1.8 or later.
+func (p *AgentCtxProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		semverbump.NewSemverBumpFunction,
+		contentversion.NewContentVersionFunction,
+	}
 }
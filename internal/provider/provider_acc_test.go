@@ -2,6 +2,7 @@ package provider_test
 
 import (
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -59,6 +60,111 @@ resource "agentctx_skill" "test" {
 	})
 }
 
+func TestAccProvider_CredentialsOnlyForGCS(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "agentctx" {
+  target {
+    name        = "primary"
+    type        = "memory"
+    credentials = "/tmp/key.json"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("only supported for gcs targets"),
+			},
+		},
+	})
+}
+
+func TestAccProvider_AzureAuth(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "agentctx" {
+  target {
+    name      = "primary"
+    type      = "memory"
+    sas_token = "sv=2022-11-02&sp=racwdl&sig=abc"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("only supported for azure targets"),
+			},
+			{
+				Config: `
+provider "agentctx" {
+  target {
+    name                       = "primary"
+    type                       = "azure"
+    storage_account            = "acmeskills"
+    container_name             = "skills"
+    sas_token                  = "sv=2022-11-02&sp=racwdl&sig=abc"
+    managed_identity_client_id = "00000000-0000-0000-0000-000000000000"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("sets both sas_token and managed_identity_client_id"),
+			},
+			{
+				Config: `
+provider "agentctx" {
+  target {
+    name       = "primary"
+    type       = "spaces"
+    region     = "nyc3"
+    bucket     = "skills"
+    account_id = "0123456789abcdef0123456789abcdef"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("account_id, which is only supported for r2 targets"),
+			},
+			{
+				Config: `
+provider "agentctx" {
+  target {
+    name   = "primary"
+    type   = "r2"
+    bucket = "skills"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("r2 targets require account_id"),
+			},
+		},
+	})
+}
+
 func TestAccProvider_InvalidDefaultTargets(t *testing.T) {
 	acctest.SetupTest(t)
 
@@ -112,3 +218,203 @@ resource "agentctx_skill" "test" {
 		},
 	})
 }
+
+func TestAccProvider_ConcurrencyBlock(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md":  "# Skill",
+		"large.bin": strings.Repeat("x", 4096),
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "agentctx" {
+  max_concurrency = 2
+
+  concurrency {
+    read_slots     = 1
+    priority       = "read"
+    bytes_per_slot = 1024
+  }
+
+  target {
+    name = "test"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "` + sourceDir + `"
+  deep_drift_check = true
+}
+`,
+				Check: resource.TestCheckResourceAttrSet("agentctx_skill.test", "bundle_hash"),
+			},
+		},
+	})
+}
+
+func TestAccProvider_InvalidReadSlots(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "agentctx" {
+  max_concurrency = 4
+
+  concurrency {
+    read_slots = 8
+  }
+
+  target {
+    name = "test"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("read_slots must be between 1 and max_concurrency"),
+			},
+		},
+	})
+}
+
+func TestAccProvider_RefreshConcurrency(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md": "# Skill",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "agentctx" {
+  refresh_concurrency = 2
+
+  target {
+    name = "a"
+    type = "memory"
+  }
+  target {
+    name = "b"
+    type = "memory"
+  }
+  target {
+    name = "c"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "` + sourceDir + `"
+  targets    = ["a", "b", "c"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("agentctx_skill.test", "target_states.a.active_deployment_id"),
+					resource.TestCheckResourceAttrSet("agentctx_skill.test", "target_states.b.active_deployment_id"),
+					resource.TestCheckResourceAttrSet("agentctx_skill.test", "target_states.c.active_deployment_id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccProvider_InvalidRefreshConcurrency(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "agentctx" {
+  refresh_concurrency = 0
+
+  target {
+    name = "test"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("refresh_concurrency must be at least 1"),
+			},
+		},
+	})
+}
+
+func TestAccProvider_FIPSModeNonCompliant(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "agentctx" {
+  fips_mode = true
+
+  anthropic {
+    api_key  = "test-api-key"
+    base_url = "http://localhost:8080"
+  }
+
+  target {
+    name = "test"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("Non-Compliant Base URL"),
+			},
+		},
+	})
+}
+
+func TestAccProvider_InvalidCompatibilityLevel(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "agentctx" {
+  compatibility_level = "latest"
+
+  target {
+    name = "test"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = "/tmp/nonexistent"
+}
+`,
+				ExpectError: regexp.MustCompile("is not a Claude Code version"),
+			},
+		},
+	})
+}
@@ -4,18 +4,55 @@ import "github.com/hashicorp/terraform-plugin-framework/types"
 
 // ProviderModel maps the provider schema to a Go struct.
 type ProviderModel struct {
-	CanonicalStore types.String           `tfsdk:"canonical_store"`
-	MaxConcurrency types.Int64            `tfsdk:"max_concurrency"`
-	DefaultTargets types.List             `tfsdk:"default_targets"` // List of strings
-	Anthropic      []AnthropicConfigModel `tfsdk:"anthropic"`
-	Targets        []TargetConfigModel    `tfsdk:"target"`
+	CanonicalStore       types.String           `tfsdk:"canonical_store"`
+	MaxConcurrency       types.Int64            `tfsdk:"max_concurrency"`
+	RefreshConcurrency   types.Int64            `tfsdk:"refresh_concurrency"`
+	DefaultTargets       types.List             `tfsdk:"default_targets"` // List of strings
+	SkipTargetValidation types.Bool             `tfsdk:"skip_target_validation"`
+	MaxRequestsPerSecond types.Float64          `tfsdk:"max_requests_per_second"`
+	MaxUploadBandwidth   types.Int64            `tfsdk:"max_upload_bandwidth"`
+	ReadOnly             types.Bool             `tfsdk:"read_only"`
+	DryRun               types.Bool             `tfsdk:"dry_run"`
+	DryRunReport         types.String           `tfsdk:"dry_run_report"`
+	ReceiptsDir          types.String           `tfsdk:"receipts_dir"`
+	FIPSMode             types.Bool             `tfsdk:"fips_mode"`
+	StateContent         types.String           `tfsdk:"state_content"`
+	CompatibilityLevel   types.String           `tfsdk:"compatibility_level"`
+	StrictDrift          types.Bool             `tfsdk:"strict_drift"`
+	AllowedURLSchemes    types.List             `tfsdk:"allowed_url_schemes"` // List of strings
+	Workspace            types.String           `tfsdk:"workspace"`
+	Environment          types.String           `tfsdk:"environment"`
+	Anthropic            []AnthropicConfigModel `tfsdk:"anthropic"`
+	Concurrency          []ConcurrencyModel     `tfsdk:"concurrency"`
+	Progress             []ProgressModel        `tfsdk:"progress"`
+	Targets              []TargetConfigModel    `tfsdk:"target"`
+}
+
+// ConcurrencyModel maps the concurrency {} block.
+type ConcurrencyModel struct {
+	ReadSlots    types.Int64  `tfsdk:"read_slots"`
+	Priority     types.String `tfsdk:"priority"`
+	BytesPerSlot types.Int64  `tfsdk:"bytes_per_slot"`
+}
+
+// ProgressModel maps the progress {} block.
+type ProgressModel struct {
+	IntervalSeconds types.Int64  `tfsdk:"interval_seconds"`
+	JSONFile        types.String `tfsdk:"json_file"`
 }
 
 // AnthropicConfigModel maps the anthropic {} block.
 type AnthropicConfigModel struct {
-	APIKey         types.String `tfsdk:"api_key"`
-	BaseURL        types.String `tfsdk:"base_url"`
-	MaxRetries     types.Int64  `tfsdk:"max_retries"`
-	DestroyRemote  types.Bool   `tfsdk:"destroy_remote"`
-	TimeoutSeconds types.Int64  `tfsdk:"timeout_seconds"`
+	APIKey                  types.String `tfsdk:"api_key"`
+	BaseURL                 types.String `tfsdk:"base_url"`
+	MaxRetries              types.Int64  `tfsdk:"max_retries"`
+	DestroyRemote           types.Bool   `tfsdk:"destroy_remote"`
+	TimeoutSeconds          types.Int64  `tfsdk:"timeout_seconds"`
+	MetadataTimeoutSeconds  types.Int64  `tfsdk:"metadata_timeout_seconds"`
+	UploadTimeoutSeconds    types.Int64  `tfsdk:"upload_timeout_seconds"`
+	RegistryFailurePolicy   types.String `tfsdk:"registry_failure_policy"`
+	CircuitBreakerThreshold types.Int64  `tfsdk:"circuit_breaker_threshold"`
+	DebugHTTP               types.Bool   `tfsdk:"debug_http"`
+	MaxRequestsPerMinute    types.Int64  `tfsdk:"max_requests_per_minute"`
+	ListPageSize            types.Int64  `tfsdk:"list_page_size"`
 }
@@ -0,0 +1,45 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccRenderPluginManifestDataSource_Basic(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + `
+data "agentctx_render_plugin_manifest" "tools" {
+  name    = "team-tools"
+  version = "1.2.0"
+  skills  = ["reports"]
+
+  hooks {
+    post_tool_use {
+      matcher = "Write|Edit"
+      hook {
+        type    = "command"
+        command = "format.sh"
+      }
+    }
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.agentctx_render_plugin_manifest.tools", "id", "team-tools"),
+					resource.TestMatchResourceAttr("data.agentctx_render_plugin_manifest.tools", "manifest_json", regexp.MustCompile(`"skills": \[\n\s+"\./skills/reports/"`)),
+					resource.TestMatchResourceAttr("data.agentctx_render_plugin_manifest.tools", "hooks_json", regexp.MustCompile(`"PostToolUse"`)),
+					resource.TestCheckNoResourceAttr("data.agentctx_render_plugin_manifest.tools", "mcp_json"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,36 @@
+package provider_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccRenderSubagentDataSource_Basic(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + `
+data "agentctx_render_subagent" "reviewer" {
+  name        = "code-reviewer"
+  description = "Reviews code"
+  prompt      = "Review the diff."
+  model       = "sonnet"
+  tools       = ["Read", "Grep"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.agentctx_render_subagent.reviewer", "id", "code-reviewer"),
+					resource.TestMatchResourceAttr("data.agentctx_render_subagent.reviewer", "content", regexp.MustCompile(`(?s)^---\n.*model: sonnet\n.*---\n\nReview the diff\.`)),
+					resource.TestMatchResourceAttr("data.agentctx_render_subagent.reviewer", "content_hash", regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)),
+				),
+			},
+		},
+	})
+}
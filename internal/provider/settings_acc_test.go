@@ -0,0 +1,115 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccSettings_PreservesUnmanagedKeys(t *testing.T) {
+	acctest.SetupTest(t)
+
+	settingsPath := filepath.Join(t.TempDir(), ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(`{"theme": "dark"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := func(settings string) string {
+		return acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_settings" "test" {
+  path          = %q
+  settings_json = jsonencode(%s)
+}
+`, settingsPath, settings)
+	}
+
+	checkFile := func(want map[string]any) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			data, err := os.ReadFile(settingsPath)
+			if err != nil {
+				return err
+			}
+			var got map[string]any
+			if err := json.Unmarshal(data, &got); err != nil {
+				return err
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(want)
+			if string(gotJSON) != string(wantJSON) {
+				return fmt.Errorf("settings file = %s, want %s", gotJSON, wantJSON)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy:             checkFile(map[string]any{"theme": "dark"}),
+		Steps: []resource.TestStep{
+			{
+				Config: config(`{ model = "opus", cleanupPeriodDays = 30 }`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_settings.test", "id", settingsPath),
+					resource.TestCheckResourceAttrSet("agentctx_settings.test", "content_hash"),
+					checkFile(map[string]any{"theme": "dark", "model": "opus", "cleanupPeriodDays": float64(30)}),
+				),
+			},
+			{
+				// Claude Code adds a key of its own; removing cleanupPeriodDays
+				// from the configuration must not clobber it.
+				PreConfig: func() {
+					data := []byte(`{"theme": "dark", "model": "opus", "cleanupPeriodDays": 30, "hasCompletedOnboarding": true}`)
+					if err := os.WriteFile(settingsPath, data, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config(`{ model = "opus" }`),
+				Check: checkFile(map[string]any{
+					"theme":                  "dark",
+					"model":                  "opus",
+					"hasCompletedOnboarding": true,
+				}),
+			},
+			{
+				// Out-of-band edits to a managed key are detected as drift.
+				PreConfig: func() {
+					data := []byte(`{"theme": "dark", "model": "haiku", "hasCompletedOnboarding": true}`)
+					if err := os.WriteFile(settingsPath, data, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             config(`{ model = "opus" }`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config(`{ model = "opus" }`),
+				Check: checkFile(map[string]any{
+					"theme":                  "dark",
+					"model":                  "opus",
+					"hasCompletedOnboarding": true,
+				}),
+			},
+			{
+				PreConfig: func() {
+					data := []byte(`{"theme": "dark", "model": "opus"}`)
+					if err := os.WriteFile(settingsPath, data, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:   config(`{ model = "opus" }`),
+				PlanOnly: true,
+			},
+		},
+	})
+}
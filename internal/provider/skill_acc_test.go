@@ -1,11 +1,15 @@
 package provider_test
 
 import (
+	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -43,6 +47,240 @@ resource "agentctx_skill" "test" {
 	})
 }
 
+func TestAccSkill_RetriesInjectedFaults(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt":    "hello world",
+		"lib/util.py": "print('util')",
+	})
+
+	// A fifth of all requests fail; the target's default retries absorb them.
+	mt := acctest.InjectFaults(t, "primary", target.FaultConfig{ErrorRate: 0.2, Seed: 7})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.%", "1"),
+					func(*terraform.State) error {
+						if mt.InjectedFaults() == 0 {
+							return fmt.Errorf("expected injected faults, got none")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_FailedDeployIsCleanedUpOnNextApply(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"a.txt": "a",
+		"b.txt": "b",
+		"c.txt": "c",
+	})
+	skillName := filepath.Base(sourceDir)
+	config := fmt.Sprintf(`
+provider "agentctx" {
+  skip_target_validation = true
+  target {
+    name        = "primary"
+    type        = "memory"
+    max_retries = 0
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				// The second upload of the update fails after the first
+				// one landed, leaving a partial deployment behind.
+				PreConfig: func() {
+					if err := os.WriteFile(filepath.Join(sourceDir, "a.txt"), []byte("a2"), 0o644); err != nil {
+						t.Fatal(err)
+					}
+					acctest.InjectFaults(t, "primary", target.FaultConfig{FailOnNthPut: 2})
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile("left staged"),
+			},
+			{
+				PreConfig: func() {
+					target.GetOrCreateMemoryTarget("primary").SetFaults(target.FaultConfig{})
+				},
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.primary.staged_deployment_id", ""),
+					func(s *terraform.State) error {
+						attrs := s.RootModule().Resources["agentctx_skill.test"].Primary.Attributes
+						managed := make(map[string]bool)
+						for k, v := range attrs {
+							if strings.HasPrefix(k, "target_states.primary.managed_deploy_ids.") && !strings.HasSuffix(k, ".#") {
+								managed[v] = true
+							}
+						}
+
+						prefix := skillName + "/.agentctx/deployments/"
+						objs, err := target.GetOrCreateMemoryTarget("primary").List(context.Background(), prefix)
+						if err != nil {
+							return err
+						}
+						for _, obj := range objs {
+							depID, _, _ := strings.Cut(strings.TrimPrefix(obj.Key, prefix), "/")
+							if !managed[depID] {
+								return fmt.Errorf("object %q belongs to deployment %q, which state does not track", obj.Key, depID)
+							}
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_CleanupOrphanedDeployments(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	orphanKey := filepath.Base(sourceDir) + "/.agentctx/deployments/dep_20200101T000000Z_11223344/files/main.txt"
+	if err := target.GetOrCreateMemoryTarget("primary").Put(context.Background(), orphanKey, strings.NewReader("partial"), target.PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir                   = %q
+  cleanup_orphaned_deployments = true
+}
+`, sourceDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "orphan_grace_period_seconds", "86400"),
+					func(*terraform.State) error {
+						_, err := target.GetOrCreateMemoryTarget("primary").Head(context.Background(), orphanKey)
+						if !errors.Is(err, target.ErrNotFound) {
+							return fmt.Errorf("orphaned deployment not removed (err=%v)", err)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_AdoptUnmanagedDeployments(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	// A complete deployment written by another tool.
+	prefix := filepath.Base(sourceDir) + "/.agentctx/deployments/dep_20200101T000000Z_55667788/"
+	for key, body := range map[string]string{
+		prefix + "files/main.txt": "hello world",
+		prefix + "manifest.json":  `{"schema_version":2,"deployment_id":"dep_20200101T000000Z_55667788","files":{"main.txt":"sha256:abc"}}`,
+	} {
+		if err := target.GetOrCreateMemoryTarget("primary").Put(context.Background(), key, strings.NewReader(body), target.PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir                  = %q
+  adopt_unmanaged_deployments = true
+}
+`, sourceDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.primary.managed_deploy_ids.#", "2"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.primary.managed_deploy_ids.0", "dep_20200101T000000Z_55667788"),
+				),
+			},
+		},
+		// Destroy removes the adopted deployment with the resource's own.
+		CheckDestroy: func(*terraform.State) error {
+			_, err := target.GetOrCreateMemoryTarget("primary").Head(context.Background(), prefix+"manifest.json")
+			if !errors.Is(err, target.ErrNotFound) {
+				return fmt.Errorf("adopted deployment not destroyed (err=%v)", err)
+			}
+			return nil
+		},
+	})
+}
+
+func TestAccSkill_Provenance(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+  provenance = true
+}
+`, sourceDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("agentctx_skill.test", "provenance_json"),
+					func(s *terraform.State) error {
+						rs := s.RootModule().Resources["agentctx_skill.test"]
+						depID := rs.Primary.Attributes["target_states.primary.active_deployment_id"]
+						key := filepath.Base(sourceDir) + "/.agentctx/deployments/" + depID + "/provenance.intoto.json"
+						rc, _, err := target.GetOrCreateMemoryTarget("primary").Get(context.Background(), key)
+						if err != nil {
+							return fmt.Errorf("provenance not uploaded: %w", err)
+						}
+						defer rc.Close()
+						data, err := io.ReadAll(rc)
+						if err != nil {
+							return err
+						}
+						if string(data) != rs.Primary.Attributes["provenance_json"] {
+							return fmt.Errorf("uploaded provenance differs from provenance_json")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
 func TestAccSkill_Update(t *testing.T) {
 	acctest.SetupTest(t)
 
@@ -298,6 +536,305 @@ resource "agentctx_skill" "test" {
 	})
 }
 
+func TestAccSkill_ReplicaTargets(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	providerConfig := `
+provider "agentctx" {
+  target {
+    name = "us"
+    type = "memory"
+  }
+  target {
+    name                        = "eu"
+    type                        = "memory"
+    replica_of                  = "us"
+    replication_timeout_seconds = 0
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+  targets    = ["eu"]
+}
+`, sourceDir),
+				ExpectError: regexp.MustCompile("Replica Target Not Writable"),
+			},
+			{
+				// The replica is never written to, so the deploy succeeds
+				// with a replication lag warning and the replica stays empty.
+				Config: providerConfig + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("agentctx_skill.test", "target_states.us.active_deployment_id"),
+					resource.TestCheckNoResourceAttr("agentctx_skill.test", "target_states.eu.active_deployment_id"),
+					func(*terraform.State) error {
+						objects, err := target.GetOrCreateMemoryTarget("eu").List(context.Background(), "")
+						if err != nil {
+							return err
+						}
+						if len(objects) != 0 {
+							return fmt.Errorf("replica target was written to: %d objects", len(objects))
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_ReadOnly(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "agentctx" {
+  read_only = true
+  target {
+    name = "primary"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir),
+				ExpectError: regexp.MustCompile("Provider Is Read-Only"),
+			},
+		},
+	})
+}
+
+func TestAccSkill_DryRun(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	reportPath := filepath.Join(t.TempDir(), "dry-run.json")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "agentctx" {
+  dry_run        = true
+  dry_run_report = %q
+  target {
+    name = "dryrun"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, reportPath, sourceDir),
+				ExpectError: regexp.MustCompile("Dry Run"),
+			},
+			{
+				// The failed step leaves nothing behind but the report.
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+				Config: fmt.Sprintf(`
+provider "agentctx" {
+  target {
+    name = "dryrun"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir),
+				Check: func(*terraform.State) error {
+					objects, err := target.GetOrCreateMemoryTarget("dryrun").List(context.Background(), "")
+					if err != nil {
+						return err
+					}
+					if len(objects) != 0 {
+						return fmt.Errorf("dry run wrote %d objects", len(objects))
+					}
+					data, err := os.ReadFile(reportPath)
+					if err != nil {
+						return err
+					}
+					if !strings.Contains(string(data), `"added": [`) || !strings.Contains(string(data), "main.txt") {
+						return fmt.Errorf("report does not list main.txt as added:\n%s", data)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccSkill_WorkspaceAndEnvironment(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "agentctx" {
+  workspace   = "prod-us"
+  environment = "production"
+  target {
+    name = "primary"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir),
+				Check: func(s *terraform.State) error {
+					rs := s.RootModule().Resources["agentctx_skill.test"]
+					depID := rs.Primary.Attributes["target_states.primary.active_deployment_id"]
+					skillName := rs.Primary.Attributes["skill_name"]
+					tgt := target.GetOrCreateMemoryTarget("primary")
+
+					rc, _, err := tgt.Get(context.Background(), skillName+"/.agentctx/deployments/"+depID+"/manifest.json")
+					if err != nil {
+						return err
+					}
+					defer rc.Close()
+					data, err := io.ReadAll(rc)
+					if err != nil {
+						return err
+					}
+					for _, want := range []string{`"workspace": "prod-us"`, `"environment": "production"`} {
+						if !strings.Contains(string(data), want) {
+							return fmt.Errorf("manifest does not contain %s:\n%s", want, data)
+						}
+					}
+
+					md, err := tgt.Metadata(skillName + "/.agentctx/ACTIVE")
+					if err != nil {
+						return err
+					}
+					if md["agentctx_environment"] != "production" {
+						return fmt.Errorf("ACTIVE metadata = %v, want agentctx_environment = production", md)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccSkill_Preview(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "agentctx" {
+  target {
+    name = "primary"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir  = %q
+  preview_id  = "pr-42"
+  preview_ttl = "72h"
+}
+`, sourceDir),
+				Check: func(s *terraform.State) error {
+					rs := s.RootModule().Resources["agentctx_skill.test"]
+					depID := rs.Primary.Attributes["target_states.primary.active_deployment_id"]
+					skillName := rs.Primary.Attributes["skill_name"]
+					tgt := target.GetOrCreateMemoryTarget("primary")
+
+					if _, err := tgt.Head(context.Background(), skillName+"/.agentctx/ACTIVE"); err == nil {
+						return fmt.Errorf("preview wrote the regular ACTIVE pointer of %q", skillName)
+					}
+					rc, _, err := tgt.Get(context.Background(), "previews/pr-42/"+skillName+"/.agentctx/deployments/"+depID+"/manifest.json")
+					if err != nil {
+						return err
+					}
+					defer rc.Close()
+					data, err := io.ReadAll(rc)
+					if err != nil {
+						return err
+					}
+					if !strings.Contains(string(data), `"expires_at": "`) {
+						return fmt.Errorf("manifest does not record expires_at:\n%s", data)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccSkill_PreviewTTLRequiresID_Error(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "agentctx" {
+  target {
+    name = "primary"
+    type = "memory"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir  = %q
+  preview_ttl = "72h"
+}
+`, sourceDir),
+				ExpectError: regexp.MustCompile(`preview_ttl requires preview_id`),
+			},
+		},
+	})
+}
+
 func TestAccSkill_AmbiguousTargets_Error(t *testing.T) {
 	acctest.SetupTest(t)
 
@@ -351,3 +888,322 @@ resource "agentctx_skill" "test" {
 		},
 	})
 }
+
+func TestAccSkill_SkillFrontmatter(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md": "---\nname: reports\ndescription: Summarises reports.\ntags: [finance]\n---\n# Reports\n",
+	})
+
+	config := acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "skill_description", "Summarises reports."),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "skill_metadata.%", "3"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "skill_metadata.name", "reports"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "skill_metadata.tags", `["finance"]`),
+				),
+			},
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filepath.Join(sourceDir, "SKILL.md"), []byte("# Reports\n"), 0o644); err != nil {
+						t.Fatalf("failed to update SKILL.md: %s", err)
+					}
+				},
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("agentctx_skill.test", "skill_description"),
+					resource.TestCheckNoResourceAttr("agentctx_skill.test", "skill_metadata.%"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_ReservedSkillName(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md": "---\nname: claude-reports\ndescription: Summarises reports.\n---\n# Reports\n",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir),
+				ExpectError: regexp.MustCompile(`reserved word "claude"`),
+			},
+		},
+	})
+}
+
+func TestAccSkill_DriftDetected(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	config := acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir       = %q
+  deep_drift_check = true
+}
+`, sourceDir)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "drift_detected", "false"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "drift_details.#", "0"),
+				),
+			},
+			{
+				PreConfig: func() {
+					primary := target.GetOrCreateMemoryTarget("primary")
+					prefix := filepath.Base(sourceDir) + "/.agentctx/deployments/"
+					objs, err := primary.List(context.Background(), prefix)
+					if err != nil {
+						t.Fatalf("list deployments: %v", err)
+					}
+					for _, obj := range objs {
+						if filepath.Base(obj.Key) == "main.txt" {
+							if err := primary.Delete(context.Background(), obj.Key); err != nil {
+								t.Fatalf("delete %q: %v", obj.Key, err)
+							}
+						}
+					}
+				},
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "drift_detected", "true"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "drift_details.#", "1"),
+					resource.TestMatchResourceAttr("agentctx_skill.test", "drift_details.0", regexp.MustCompile(`missing 1 file\(s\): main\.txt`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_VerifyFailureRollsBack(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md": "# Reports\n",
+	})
+
+	config := func(command string) string {
+		return acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+
+  verify {
+    command = %q
+  }
+}
+`, sourceDir, command)
+	}
+
+	var firstDeployID string
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(`test -n "$AGENTCTX_DEPLOYMENT_ID"`),
+				Check: func(s *terraform.State) error {
+					firstDeployID = s.RootModule().Resources["agentctx_skill.test"].Primary.Attributes["target_states.primary.active_deployment_id"]
+					return nil
+				},
+			},
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filepath.Join(sourceDir, "SKILL.md"), []byte("# Reports v2\n"), 0o644); err != nil {
+						t.Fatalf("failed to update SKILL.md: %s", err)
+					}
+				},
+				Config:      config("echo smoke test failed; exit 1"),
+				ExpectError: regexp.MustCompile(`AGX308`),
+			},
+			{
+				RefreshState: true,
+				Check: func(s *terraform.State) error {
+					attrs := s.RootModule().Resources["agentctx_skill.test"].Primary.Attributes
+					if got := attrs["target_states.primary.active_deployment_id"]; got != firstDeployID {
+						return fmt.Errorf("active_deployment_id = %q, want the rolled back %q", got, firstDeployID)
+					}
+					if attrs["target_states.primary.staged_deployment_id"] == "" {
+						return fmt.Errorf("rejected deployment not recorded as staged")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccSkill_CanaryRollout(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"SKILL.md": "# Reports\n",
+	})
+
+	config := func(canary string) string {
+		return acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+%s
+}
+`, sourceDir, canary)
+	}
+
+	var stableDeployID string
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.primary.stable_deployment_id", ""),
+					func(s *terraform.State) error {
+						stableDeployID = s.RootModule().Resources["agentctx_skill.test"].Primary.Attributes["target_states.primary.active_deployment_id"]
+						return nil
+					},
+				),
+			},
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filepath.Join(sourceDir, "SKILL.md"), []byte("# Reports v2\n"), 0o644); err != nil {
+						t.Fatalf("failed to update SKILL.md: %s", err)
+					}
+				},
+				Config: config("  canary {\n    weight = 10\n  }"),
+				Check: func(s *terraform.State) error {
+					attrs := s.RootModule().Resources["agentctx_skill.test"].Primary.Attributes
+					if got := attrs["target_states.primary.stable_deployment_id"]; got != stableDeployID {
+						return fmt.Errorf("stable_deployment_id = %q, want %q", got, stableDeployID)
+					}
+					if attrs["target_states.primary.active_deployment_id"] == stableDeployID {
+						return fmt.Errorf("active_deployment_id should be the new canary deployment")
+					}
+					return nil
+				},
+			},
+			{
+				Config: config(""),
+				Check:  resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.primary.stable_deployment_id", ""),
+			},
+		},
+	})
+}
+
+func TestAccSkill_SourceArchive(t *testing.T) {
+	acctest.SetupTest(t)
+
+	files := map[string]string{
+		"SKILL.md":       "---\nname: report-writer\ndescription: Writes reports.\n---\n# Reports\n",
+		"scripts/run.sh": "echo report\n",
+	}
+	sourceDir := acctest.CreateTempSourceDir(t, files)
+
+	archivePath := filepath.Join(t.TempDir(), "report-writer.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create("report-writer/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "dir" {
+  source_dir = %q
+}
+
+resource "agentctx_skill" "archive" {
+  source_archive = %q
+}
+`, sourceDir, archivePath),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.archive", "skill_name", "report-writer"),
+					resource.TestCheckResourceAttr("agentctx_skill.archive", "skill_description", "Writes reports."),
+					resource.TestCheckNoResourceAttr("agentctx_skill.archive", "bundle_json"),
+					resource.TestCheckResourceAttrPair("agentctx_skill.archive", "bundle_hash", "agentctx_skill.dir", "bundle_hash"),
+					resource.TestCheckResourceAttr("agentctx_skill.archive", "target_states.%", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_MultipleSources(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + `
+resource "agentctx_skill" "test" {
+  source_dir     = "skills/report-writer"
+  source_archive = "dist/report-writer.zip"
+}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of source_dir, source_archive`),
+			},
+		},
+	})
+}
+
+func TestAccSkill_UnpinnedGitSource(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + `
+resource "agentctx_skill" "test" {
+  source_git {
+    url = "https://example.com/acme/skills.git"
+    ref = "main"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Unpinned Git Source`),
+			},
+		},
+	})
+}
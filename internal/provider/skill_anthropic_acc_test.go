@@ -2,11 +2,14 @@ package provider_test
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
 )
@@ -149,3 +152,159 @@ resource "agentctx_skill" "test" {
 		},
 	})
 }
+
+func TestAccSkill_WithAnthropic_WarnAndSkip(t *testing.T) {
+	acctest.SetupTest(t)
+
+	mock := acctest.NewMockAnthropicServer(t)
+	outage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(outage.Close)
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "registry outage test",
+	})
+
+	config := func(baseURL string) string {
+		return fmt.Sprintf(`
+provider "agentctx" {
+  target {
+    name = "primary"
+    type = "memory"
+  }
+
+  anthropic {
+    api_key                 = "test-api-key"
+    base_url                = %q
+    max_retries             = 0
+    registry_failure_policy = "warn_and_skip"
+  }
+}
+
+resource "agentctx_skill" "test" {
+  source_dir = %q
+
+  anthropic {
+    enabled = true
+  }
+}
+`, baseURL, sourceDir)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(outage.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_skipped", "true"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.%", "1"),
+					resource.TestCheckNoResourceAttr("agentctx_skill.test", "registry_state.skill_id"),
+				),
+				// The skipped registration is retried by the next plan.
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config(mock.URL()),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_skipped", "false"),
+					resource.TestMatchResourceAttr("agentctx_skill.test", "registry_state.skill_id", regexp.MustCompile(`^skill_mock_`)),
+					resource.TestCheckResourceAttrSet("agentctx_skill.test", "registry_state.deployed_version"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_WithAnthropic_VersionDrift(t *testing.T) {
+	acctest.SetupTest(t)
+
+	mock := acctest.NewMockAnthropicServer(t)
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "version drift",
+	})
+	config := func(onDrift string) string {
+		return acctest.ProviderConfigWithAnthropic("primary", mock.URL()) + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+
+  anthropic {
+    enabled          = true
+    on_version_drift = %q
+  }
+}
+`, sourceDir, onDrift)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("warn"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.deployed_version", "v1"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.latest_version", "v1"),
+				),
+			},
+			{
+				// Another pipeline publishes v2; the refresh reports it.
+				PreConfig:    func() { mock.AddVersion("skill_mock_001") },
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.deployed_version", "v1"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.latest_version", "v2"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "drift_detected", "true"),
+				),
+			},
+			{
+				// Reconciling publishes the configured bundle as v3.
+				Config: config("reconcile"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.deployed_version", "v3"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.latest_version", "v3"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_WithAnthropic_DestroyManagedVersions(t *testing.T) {
+	acctest.SetupTest(t)
+
+	mock := acctest.NewMockAnthropicServer(t)
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "managed versions",
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			// Only v1 was created by the resource; v2 and the skill remain.
+			if got := mock.Versions("skill_mock_001"); len(got) != 1 || got[0] != "v2" {
+				return fmt.Errorf("versions after destroy = %v, want [v2]", got)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigWithAnthropic("primary", mock.URL()) + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+
+  anthropic {
+    enabled = true
+  }
+}
+`, sourceDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.managed_versions.#", "1"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "registry_state.managed_versions.0", "v1"),
+					func(*terraform.State) error {
+						mock.AddVersion("skill_mock_001")
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
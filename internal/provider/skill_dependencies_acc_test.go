@@ -0,0 +1,94 @@
+package provider_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+)
+
+func TestAccSkillDependencies_Dangling(t *testing.T) {
+	acctest.SetupTest(t)
+
+	styleDir := acctest.CreateTempSourceDir(t, map[string]string{"SKILL.md": "# Style guide\n"})
+	reviewDir := acctest.CreateTempSourceDir(t, map[string]string{"SKILL.md": "# Code review\n"})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "style" {
+  source_dir = %q
+}
+
+resource "agentctx_skill" "review" {
+  source_dir        = %q
+  depends_on_skills = [agentctx_skill.style.skill_name, "security-checklist"]
+}
+
+data "agentctx_skill_dependencies" "test" {
+  target = "primary"
+  skills = [agentctx_skill.review.skill_name]
+}
+`, styleDir, reviewDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.review", "depends_on_skills.#", "2"),
+					resource.TestCheckResourceAttr("data.agentctx_skill_dependencies.test", "dependencies.#", "1"),
+					resource.TestCheckResourceAttr("data.agentctx_skill_dependencies.test", "dependencies.0.depends_on_skills.#", "2"),
+					resource.TestCheckResourceAttr("data.agentctx_skill_dependencies.test", "dangling.#", "1"),
+					resource.TestCheckResourceAttr("data.agentctx_skill_dependencies.test", "dangling.0.depends_on", "security-checklist"),
+					resource.TestCheckResourceAttr("data.agentctx_skill_dependencies.test", "complete", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSkill_DependsOnItself(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{"SKILL.md": "# Skill\n"})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir        = %q
+  depends_on_skills = [basename(%q)]
+}
+`, sourceDir, sourceDir),
+				ExpectError: regexp.MustCompile(`Skill Depends On Itself`),
+			},
+		},
+	})
+}
+
+func TestAccCatalog_DanglingDependency(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + `
+resource "agentctx_catalog" "test" {
+  name   = "acme"
+  target = "primary"
+
+  skill {
+    name              = "code-review"
+    depends_on_skills = ["style-guide"]
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Dangling Skill Dependency`),
+			},
+		},
+	})
+}
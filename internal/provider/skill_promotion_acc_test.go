@@ -0,0 +1,86 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestAccSkillPromotion_StagedDeploy(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	skillName := filepath.Base(sourceDir)
+
+	skillConfig := fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir          = %q
+  deployment_strategy = "staged"
+}
+`, sourceDir)
+	promotionConfig := fmt.Sprintf(`
+resource "agentctx_skill_promotion" "test" {
+  skill_name     = %q
+  deployment_ids = { for name, ts in agentctx_skill.test.target_states : name => ts.staged_deployment_id }
+}
+`, skillName)
+
+	activeIs := func(attr string) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			rs := s.RootModule().Resources["agentctx_skill.test"]
+			want := rs.Primary.Attributes[attr]
+			rc, _, err := target.GetOrCreateMemoryTarget("primary").Get(context.Background(), skillName+"/.agentctx/ACTIVE")
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				return err
+			}
+			if string(got) != want {
+				return fmt.Errorf("ACTIVE = %q, want %s = %q", got, attr, want)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// Staging uploads the deployment without activating it.
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill.test", "target_states.primary.active_deployment_id", ""),
+					resource.TestCheckResourceAttrSet("agentctx_skill.test", "target_states.primary.staged_deployment_id"),
+					resource.TestCheckResourceAttr("agentctx_skill.test", "drift_detected", "false"),
+				),
+			},
+			{
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig + promotionConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					activeIs("target_states.primary.staged_deployment_id"),
+					resource.TestCheckResourceAttr("agentctx_skill_promotion.test", "previous_deployment_ids.primary", ""),
+					resource.TestCheckResourceAttrSet("agentctx_skill_promotion.test", "promoted_at"),
+				),
+			},
+			{
+				// A refresh sees the promoted deployment as active and
+				// the configuration stays unchanged.
+				Config:   acctest.ProviderConfigMemory("primary") + skillConfig + promotionConfig,
+				PlanOnly: true,
+			},
+		},
+	})
+}
@@ -0,0 +1,120 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestAccSkillRollback_ToEarlierDeployment(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "version 1",
+	})
+	skillName := filepath.Base(sourceDir)
+
+	skillConfig := func(alias string) string {
+		return fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir          = %q
+  deployment_strategy = "staged"
+  deployment_alias    = %q
+}
+`, sourceDir, alias)
+	}
+	promotionConfig := fmt.Sprintf(`
+resource "agentctx_skill_promotion" "test" {
+  skill_name     = %q
+  deployment_ids = { for name, ts in agentctx_skill.test.target_states : name => ts.staged_deployment_id }
+}
+`, skillName)
+	rollbackConfig := func(ref string) string {
+		return fmt.Sprintf(`
+resource "agentctx_skill_rollback" "test" {
+  skill_name     = %q
+  deployment_ids = { primary = %q }
+}
+`, skillName, ref)
+	}
+
+	var first, second string
+	record := func(id *string) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			*id = s.RootModule().Resources["agentctx_skill.test"].Primary.Attributes["target_states.primary.staged_deployment_id"]
+			return nil
+		}
+	}
+	activeIs := func(id *string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			rc, _, err := target.GetOrCreateMemoryTarget("primary").Get(context.Background(), skillName+"/.agentctx/ACTIVE")
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				return err
+			}
+			if string(got) != *id {
+				return fmt.Errorf("ACTIVE = %q, want %q", got, *id)
+			}
+			return nil
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig("v1") + promotionConfig,
+				Check:  record(&first),
+			},
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filepath.Join(sourceDir, "main.txt"), []byte("version 2"), 0o644); err != nil {
+						t.Fatalf("failed to update source file: %s", err)
+					}
+				},
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig("v2") + promotionConfig,
+				Check:  resource.ComposeAggregateTestCheckFunc(record(&second), activeIs(&second)),
+			},
+			{
+				// The promotion is replaced by a rollback to the first
+				// deployment, named by its alias. The skill sees its
+				// latest deployment as awaiting promotion, not as drift.
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig("v2") + rollbackConfig("v1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					activeIs(&first),
+					func(s *terraform.State) error {
+						attrs := s.RootModule().Resources["agentctx_skill_rollback.test"].Primary.Attributes
+						if attrs["active_deployment_ids.primary"] != first || attrs["previous_deployment_ids.primary"] != second {
+							return fmt.Errorf("active_deployment_ids.primary = %q, previous_deployment_ids.primary = %q; want %q, %q",
+								attrs["active_deployment_ids.primary"], attrs["previous_deployment_ids.primary"], first, second)
+						}
+						return nil
+					},
+					resource.TestCheckResourceAttr("agentctx_skill.test", "drift_detected", "false"),
+				),
+			},
+			{
+				Config:   acctest.ProviderConfigMemory("primary") + skillConfig("v2") + rollbackConfig("v1"),
+				PlanOnly: true,
+			},
+			{
+				Config:      acctest.ProviderConfigMemory("primary") + skillConfig("v2") + rollbackConfig("v9"),
+				ExpectError: regexp.MustCompile("Rollback Target Unavailable"),
+			},
+		},
+	})
+}
@@ -0,0 +1,140 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestAccSkillVerification_ReferenceBlocksDestroy(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	skillName := filepath.Base(sourceDir)
+	refKey := skillName + "/.agentctx/refs/stack-b"
+
+	skillConfig := fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+}
+`, sourceDir)
+	verificationConfig := fmt.Sprintf(`
+resource "agentctx_skill_verification" "test" {
+  skill_name = %q
+  targets    = ["primary"]
+  consumer   = "stack-b"
+}
+`, skillName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// The skill is not deployed yet.
+				Config:      acctest.ProviderConfigMemory("primary") + verificationConfig,
+				ExpectError: regexp.MustCompile("Skill Not Deployed"),
+			},
+			{
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig,
+			},
+			{
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig + verificationConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_skill_verification.test", "id", skillName+":stack-b"),
+					resource.TestCheckResourceAttrPair(
+						"agentctx_skill_verification.test", "active_deployment_ids.primary",
+						"agentctx_skill.test", "target_states.primary.active_deployment_id",
+					),
+					func(*terraform.State) error {
+						_, err := target.GetOrCreateMemoryTarget("primary").Head(context.Background(), refKey)
+						return err
+					},
+				),
+			},
+			{
+				// Removing the skill while stack-b references it fails.
+				Config:      acctest.ProviderConfigMemory("primary") + verificationConfig,
+				ExpectError: regexp.MustCompile("Skill Still Referenced"),
+			},
+			{
+				// Dropping the reference first lets the skill go.
+				Config: acctest.ProviderConfigMemory("primary") + skillConfig,
+				Check: func(*terraform.State) error {
+					if _, err := target.GetOrCreateMemoryTarget("primary").Head(context.Background(), refKey); err == nil {
+						return fmt.Errorf("reference marker %s still exists", refKey)
+					}
+					return nil
+				},
+			},
+			{
+				Config: acctest.ProviderConfigMemory("primary"),
+			},
+		},
+	})
+}
+
+func TestAccSkillVerification_ReferenceOnLaterTargetBlocksDestroy(t *testing.T) {
+	acctest.SetupTest(t)
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{
+		"main.txt": "hello world",
+	})
+	skillName := filepath.Base(sourceDir)
+	activeKey := skillName + "/.agentctx/ACTIVE"
+	providerConfig := acctest.ProviderConfigMemoryMulti([]string{"first", "second"}, nil)
+
+	skillConfig := fmt.Sprintf(`
+resource "agentctx_skill" "test" {
+  source_dir = %q
+  targets    = ["first", "second"]
+}
+`, sourceDir)
+	verificationConfig := fmt.Sprintf(`
+resource "agentctx_skill_verification" "test" {
+  skill_name = %q
+  targets    = ["second"]
+  consumer   = "stack-b"
+}
+`, skillName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + skillConfig,
+			},
+			{
+				Config: providerConfig + skillConfig + verificationConfig,
+			},
+			{
+				// Only the second target is referenced, so the destroy is
+				// refused before the first target is touched.
+				Config:      providerConfig + verificationConfig,
+				ExpectError: regexp.MustCompile("Skill Still Referenced"),
+			},
+			{
+				PreConfig: func() {
+					for _, name := range []string{"first", "second"} {
+						if _, err := target.GetOrCreateMemoryTarget(name).Head(context.Background(), activeKey); err != nil {
+							t.Errorf("refused destroy removed %s from target %s: %v", activeKey, name, err)
+						}
+					}
+				},
+				Config: providerConfig + skillConfig,
+			},
+			{
+				Config: providerConfig,
+			},
+		},
+	})
+}
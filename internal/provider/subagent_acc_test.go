@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -51,6 +52,82 @@ resource "agentctx_subagent" "test" {
 	})
 }
 
+func TestAccSubagent_StateContentHashes(t *testing.T) {
+	acctest.SetupTest(t)
+
+	outputDir := t.TempDir()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "agentctx" {
+  state_content = "hashes"
+
+  target {
+    name = "test"
+    type = "memory"
+  }
+}
+
+resource "agentctx_subagent" "test" {
+  name        = "code-reviewer"
+  description = "Reviews code for quality"
+  output_dir  = %q
+  prompt      = "You are a code reviewer."
+}
+`, outputDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("agentctx_subagent.test", "content"),
+					resource.TestCheckResourceAttrSet("agentctx_subagent.test", "content_hash"),
+					func(_ *terraform.State) error {
+						if _, err := os.Stat(filepath.Join(outputDir, "code-reviewer.md")); err != nil {
+							return fmt.Errorf("sub-agent file not written: %s", err)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubagent_ContentStorageHashOnly(t *testing.T) {
+	acctest.SetupTest(t)
+
+	outputDir := t.TempDir()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_subagent" "hashed" {
+  name            = "code-reviewer"
+  description     = "Reviews code for quality"
+  output_dir      = %q
+  prompt          = "You are a code reviewer."
+  content_storage = "hash_only"
+}
+
+resource "agentctx_subagent" "full" {
+  name        = "debugger"
+  description = "Debugs failures"
+  output_dir  = %q
+  prompt      = "You are a debugger."
+}
+`, outputDir, outputDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("agentctx_subagent.hashed", "content"),
+					resource.TestCheckResourceAttrSet("agentctx_subagent.hashed", "content_hash"),
+					resource.TestCheckResourceAttrSet("agentctx_subagent.full", "content"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccSubagent_AllFields(t *testing.T) {
 	acctest.SetupTest(t)
 
@@ -508,3 +585,183 @@ resource "agentctx_subagent" "test" {
 		},
 	})
 }
+
+func TestAccSubagent_MemoryScaffold(t *testing.T) {
+	acctest.SetupTest(t)
+
+	project := t.TempDir()
+	outputDir := filepath.Join(project, ".claude", "agents")
+	memoryFile := filepath.Join(project, ".claude", "agent-memory", "memory-test", "MEMORY.md")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_subagent" "test" {
+  name        = "memory-test"
+  description = "Test"
+  output_dir  = %q
+  prompt      = "Test prompt."
+  memory      = "project"
+
+  memory_scaffold {
+    initial_content = "# Review conventions\n"
+  }
+}
+`, outputDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_subagent.test", "memory_file", memoryFile),
+					func(s *terraform.State) error {
+						data, err := os.ReadFile(memoryFile)
+						if err != nil {
+							return fmt.Errorf("memory file not created: %w", err)
+						}
+						if string(data) != "# Review conventions\n" {
+							return fmt.Errorf("MEMORY.md = %q, want the initial content", data)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubagent_MemoryScaffoldRequiresMemory(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_subagent" "test" {
+  name        = "memory-test"
+  description = "Test"
+  output_dir  = %q
+  prompt      = "Test prompt."
+
+  memory_scaffold {}
+}
+`, t.TempDir()),
+				ExpectError: regexp.MustCompile("memory_scaffold requires memory"),
+			},
+		},
+	})
+}
+
+func TestAccSubagent_PluginDir(t *testing.T) {
+	acctest.SetupTest(t)
+
+	pluginDir := filepath.Join(t.TempDir(), "plugin")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		CheckDestroy: func(s *terraform.State) error {
+			if _, err := os.Stat(filepath.Join(pluginDir, "agents", "helper.md")); !os.IsNotExist(err) {
+				return fmt.Errorf("sub-agent file still exists after destroy")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_plugin" "test" {
+  name       = "team-tools"
+  output_dir = %q
+}
+
+resource "agentctx_subagent" "test" {
+  name        = "helper"
+  description = "Helps"
+  plugin_dir  = agentctx_plugin.test.plugin_dir
+  prompt      = "You help."
+}
+`, pluginDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("agentctx_subagent.test", "file_path", filepath.Join(pluginDir, "agents", "helper.md")),
+					func(s *terraform.State) error {
+						data, err := os.ReadFile(filepath.Join(pluginDir, ".claude-plugin", "plugin.json"))
+						if err != nil {
+							return err
+						}
+						if !regexp.MustCompile(`"\./agents/helper\.md"`).Match(data) {
+							return fmt.Errorf("plugin.json does not list the sub-agent: %s", data)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubagent_OutputDirOrPluginDir(t *testing.T) {
+	acctest.SetupTest(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: acctest.ProviderConfigMemory("test") + fmt.Sprintf(`
+resource "agentctx_subagent" "test" {
+  name        = "both"
+  description = "Test"
+  output_dir  = %q
+  plugin_dir  = %q
+  prompt      = "Test prompt."
+}
+`, t.TempDir(), t.TempDir()),
+				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
+			},
+		},
+	})
+}
+
+func TestAccSubagent_StrictDrift(t *testing.T) {
+	acctest.SetupTest(t)
+
+	outputDir := t.TempDir()
+	config := fmt.Sprintf(`
+provider "agentctx" {
+  strict_drift = true
+
+  target {
+    name = "test"
+    type = "memory"
+  }
+}
+
+resource "agentctx_subagent" "test" {
+  name        = "strict"
+  description = "Test"
+  output_dir  = %q
+  prompt      = "Test prompt."
+}
+`, outputDir)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filepath.Join(outputDir, "strict.md"), []byte("edited\n"), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile(`AGX102[\s\S]*modified outside Terraform`),
+			},
+			{
+				// Without strict_drift, refresh records the change and apply
+				// overwrites it, so the file matches state again.
+				Config: strings.Replace(config, "strict_drift = true", "strict_drift = false", 1),
+				Check:  resource.TestCheckResourceAttrSet("agentctx_subagent.test", "content_hash"),
+			},
+		},
+	})
+}
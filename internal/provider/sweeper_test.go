@@ -0,0 +1,164 @@
+package provider_test
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/acctest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// TestMain runs the sweepers when go test is given -sweep, e.g.
+//
+//	go test ./internal/provider -v -sweep=us-east-1
+//
+// and the tests otherwise.
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	resource.AddTestSweepers("agentctx_skill", &resource.Sweeper{
+		Name: "agentctx_skill",
+		F: func(region string) error {
+			cfg, ok := acctest.SweepTargetConfig(region)
+			if !ok {
+				log.Printf("[INFO] AGENTCTX_ACC_TARGET_TYPE not set, skipping agentctx_skill sweeper")
+				return nil
+			}
+			tgt, err := target.NewTarget(cfg)
+			if err != nil {
+				return err
+			}
+			swept, err := acctest.SweepTarget(context.Background(), tgt)
+			log.Printf("[INFO] swept %d skills from %s target: %v", len(swept), cfg.Type, swept)
+			return err
+		},
+	})
+
+	resource.AddTestSweepers("agentctx_anthropic_skill", &resource.Sweeper{
+		Name: "agentctx_anthropic_skill",
+		F: func(string) error {
+			client, ok := acctest.SweepRegistryClient()
+			if !ok {
+				log.Printf("[INFO] ANTHROPIC_API_KEY not set, skipping agentctx_anthropic_skill sweeper")
+				return nil
+			}
+			swept, err := acctest.SweepRegistry(context.Background(), client)
+			log.Printf("[INFO] swept %d skills from the Anthropic registry: %v", len(swept), swept)
+			return err
+		},
+	})
+}
+
+func TestSweepTarget_OnlyPrefixedSkills(t *testing.T) {
+	ctx := context.Background()
+	tgt := target.NewMemoryTarget("sweep")
+	for _, key := range []string{
+		"tf-acc-1a2b/.agentctx/ACTIVE",
+		"tf-acc-1a2b/.agentctx/deployments/dep_1/manifest.json",
+		"previews/42/tf-acc-3c4d/.agentctx/ACTIVE",
+		"previews/42/production/.agentctx/ACTIVE",
+		"production/.agentctx/ACTIVE",
+		"tf-accounting/.agentctx/ACTIVE",
+	} {
+		if err := tgt.Put(ctx, key, strings.NewReader("x"), target.PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	swept, err := acctest.SweepTarget(ctx, tgt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"previews/42/tf-acc-3c4d", "tf-acc-1a2b"}; !slices.Equal(swept, want) {
+		t.Errorf("swept = %v, want %v", swept, want)
+	}
+
+	objects, err := tgt.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, obj := range objects {
+		kept = append(kept, obj.Key)
+	}
+	slices.Sort(kept)
+	want := []string{
+		"previews/42/production/.agentctx/ACTIVE",
+		"production/.agentctx/ACTIVE",
+		"tf-accounting/.agentctx/ACTIVE",
+	}
+	if !slices.Equal(kept, want) {
+		t.Errorf("kept = %v, want %v", kept, want)
+	}
+}
+
+func TestSweepRegistry_OnlyPrefixedSkills(t *testing.T) {
+	ctx := context.Background()
+	mock := acctest.NewMockAnthropicServer(t)
+	client := anthropic.NewClient(anthropic.ClientConfig{APIKey: "test-api-key", BaseURL: mock.URL(), DestroyRemote: true})
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{"SKILL.md": "# Skill\n"})
+	leftover, err := client.CreateSkill(ctx, sourceDir, acctest.RandomName(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.AddVersion(leftover.ID)
+	production, err := client.CreateSkill(ctx, sourceDir, "production", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	swept, err := acctest.SweepRegistry(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{leftover.ID}; !slices.Equal(swept, want) {
+		t.Errorf("swept = %v, want %v", swept, want)
+	}
+	if _, err := client.GetSkill(ctx, production.ID); err != nil {
+		t.Errorf("skill without the prefix was removed: %v", err)
+	}
+}
+
+func TestSweepRegistry_FollowsVersionPages(t *testing.T) {
+	ctx := context.Background()
+	mock := acctest.NewMockAnthropicServer(t)
+	client := anthropic.NewClient(anthropic.ClientConfig{APIKey: "test-api-key", BaseURL: mock.URL(), DestroyRemote: true, PageSize: 2})
+
+	sourceDir := acctest.CreateTempSourceDir(t, map[string]string{"SKILL.md": "# Skill\n"})
+	leftover, err := client.CreateSkill(ctx, sourceDir, acctest.RandomName(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		mock.AddVersion(leftover.ID)
+	}
+
+	// Five versions take three pages of two.
+	versions, err := client.ListVersions(ctx, leftover.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.Version)
+	}
+	if want := mock.Versions(leftover.ID); !slices.Equal(got, want) {
+		t.Fatalf("ListVersions = %v, want %v", got, want)
+	}
+
+	if _, err := acctest.SweepRegistry(ctx, client); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetSkill(ctx, leftover.ID); err == nil {
+		t.Error("skill with more versions than fit on a page was not removed")
+	}
+}
@@ -4,10 +4,18 @@
 package providerdata
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
-	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"golang.org/x/sync/semaphore"
 )
 
 // ProviderData is configured during provider.Configure() and shared with
@@ -15,10 +23,162 @@ import (
 type ProviderData struct {
 	CanonicalStore string
 	DefaultTargets []string
-	Targets        map[string]target.Target
-	TargetConfigs  map[string]TargetConfigModel
+	Targets        TargetRegistry
 	Anthropic      *anthropic.Client
-	Semaphore      *semaphore.Weighted
+	Scheduler      *concurrency.Scheduler
+	// RefreshPool bounds the skill refreshes running at once across all
+	// resources, per the provider's refresh_concurrency. Nil refreshes
+	// one target at a time.
+	RefreshPool *concurrency.Pool
+	// Version is the provider version, recorded in deployment manifests
+	// and provenance.
+	Version string
+	// Listeners observe the operations of every engine the resources
+	// create, e.g. to report upload progress.
+	Listeners []engine.Listener
+	// ReadOnly is set by the provider's read_only argument. Resources
+	// refuse to create, update, or delete anything while it is set.
+	ReadOnly bool
+	// DryRun is set by the provider's dry_run argument. Resources record
+	// the changes they would make in it instead of making them. Nil when
+	// dry_run is not set.
+	DryRun *dryrun.Report
+	// Workspace and Environment are recorded in the manifest of every
+	// deployment and as object metadata. Empty when not configured.
+	Workspace   string
+	Environment string
+	// OmitContent is set by the provider's state_content = "hashes".
+	// Computed attributes that hold rendered file content are stored as
+	// null, leaving only the content hashes in state.
+	OmitContent bool
+	// CompatibilityLevel is set by the provider's compatibility_level.
+	// Resources that generate Claude Code artifacts leave out features
+	// it does not allow unless they set their own level. Nil allows
+	// every feature.
+	CompatibilityLevel *claudeversion.Level
+	// StrictDrift is set by the provider's strict_drift argument. Read
+	// fails when it finds managed content changed outside Terraform
+	// instead of recording the change in state.
+	StrictDrift bool
+	// AllowedURLSchemes is set by the provider's allowed_url_schemes: the
+	// schemes URL attributes of generated manifests may use. Nil allows
+	// validators.DefaultURLSchemes.
+	AllowedURLSchemes []string
+}
+
+// Compatibility returns the level a resource generates artifacts for: its
+// own compatibility_level if set, otherwise the provider's. The override
+// must already have been validated. A nil ProviderData, as in unit tests,
+// has no provider level.
+func (pd *ProviderData) Compatibility(override types.String) *claudeversion.Level {
+	if !override.IsNull() && !override.IsUnknown() {
+		if level, err := claudeversion.ParseLevel(override.ValueString()); err == nil {
+			return level
+		}
+	}
+	if pd == nil {
+		return nil
+	}
+	return pd.CompatibilityLevel
+}
+
+// URLSchemes returns the schemes URL attributes may use: the provider's
+// allowed_url_schemes if set, otherwise validators.DefaultURLSchemes. A nil
+// ProviderData, as in unit tests and during validation before the
+// provider is configured, allows the defaults.
+func (pd *ProviderData) URLSchemes() []string {
+	if pd == nil || pd.AllowedURLSchemes == nil {
+		return validators.DefaultURLSchemes
+	}
+	return pd.AllowedURLSchemes
+}
+
+// CheckWritable returns an error diagnostic if the provider is read-only.
+// Resources call it at the start of Create, Update, and Delete, so a plan
+// run with read_only = true fails before anything is written. operation is
+// a verb such as "create" and resourceType the resource's type name. A nil
+// ProviderData, as in unit tests, is writable.
+func (pd *ProviderData) CheckWritable(resourceType, operation string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if pd == nil || !pd.ReadOnly {
+		return diags
+	}
+	diags.AddError(
+		errcode.ReadOnly.Summary("Provider Is Read-Only"),
+		fmt.Sprintf("Refusing to %s %s: the provider is configured with read_only = true. "+
+			"Reads and plans still work; remove read_only to apply changes.", operation, resourceType),
+	)
+	return diags
+}
+
+// DryRunning reports whether the provider has dry_run set. Resources check
+// it in Create, Update, and Delete once they have computed what they would
+// write, and pass the change to RecordDryRun instead of writing it. A nil
+// ProviderData, as in unit tests, is not dry-running.
+func (pd *ProviderData) DryRunning() bool {
+	return pd != nil && pd.DryRun != nil
+}
+
+// RecordDryRun records change in the dry-run report and returns an error
+// diagnostic, so that Terraform keeps the resource's prior state and skips
+// the resources that depend on it. Nothing but the report is written.
+func (pd *ProviderData) RecordDryRun(change dryrun.Change) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if err := pd.DryRun.Record(change); err != nil {
+		diags.AddError(
+			errcode.DryRun.Summary("Dry-Run Report Failed"),
+			fmt.Sprintf("Could not record the %s of %s %q: %s", change.Operation, change.Resource, change.ID, err),
+		)
+		return diags
+	}
+	diags.AddError(
+		errcode.DryRun.Summary("Dry Run"),
+		fmt.Sprintf("Did not %s %s %q: the provider is configured with dry_run = true. "+
+			"The intended changes were recorded in %s; remove dry_run to apply them.", change.Operation, change.Resource, change.ID, pd.DryRun.Path()),
+	)
+	return diags
+}
+
+// CheckDrift returns an error diagnostic if the provider has strict_drift
+// set. Resources call it in Read when they find a target or generated file
+// changed or removed outside Terraform, before they reconcile state with
+// it; detail says what changed. A nil ProviderData, as in unit tests, is
+// not strict.
+func (pd *ProviderData) CheckDrift(resourceType, id, detail string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if pd == nil || !pd.StrictDrift {
+		return diags
+	}
+	diags.AddError(
+		errcode.DriftDetected.Summary("Drift Detected"),
+		fmt.Sprintf("%s %q was modified outside Terraform: %s. "+
+			"The provider is configured with strict_drift = true, so refresh stops instead of reconciling the change. "+
+			"Restore the content, or remove strict_drift and apply to overwrite it.", resourceType, id, detail),
+	)
+	return diags
+}
+
+// Content returns the value of a computed attribute holding the rendered
+// content s: s itself, or null when OmitContent is set. A nil ProviderData,
+// as in unit tests, keeps the content.
+func (pd *ProviderData) Content(s string) types.String {
+	if pd != nil && pd.OmitContent {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
+
+// ContentFor is Content for resources with their own content_storage
+// argument: "hash_only" stores null and "full" stores s, whatever the
+// provider's state_content; an unset argument defers to Content.
+func (pd *ProviderData) ContentFor(storage types.String, s string) types.String {
+	switch storage.ValueString() {
+	case "hash_only":
+		return types.StringNull()
+	case "full":
+		return types.StringValue(s)
+	}
+	return pd.Content(s)
 }
 
 // TargetConfigModel maps each target {} block in the provider configuration.
@@ -28,13 +188,36 @@ type TargetConfigModel struct {
 	Bucket          types.String `tfsdk:"bucket"`
 	Region          types.String `tfsdk:"region"`
 	KMSKeyID        types.String `tfsdk:"kms_key_id"`
+	AccountID       types.String `tfsdk:"account_id"` // r2 only
 	StorageAccount  types.String `tfsdk:"storage_account"`
 	ContainerName   types.String `tfsdk:"container_name"`
 	EncryptionScope types.String `tfsdk:"encryption_scope"`
 	KMSKeyName      types.String `tfsdk:"kms_key_name"`
+	Credentials     types.String `tfsdk:"credentials"` // gcs only; sensitive
 	Prefix          types.String `tfsdk:"prefix"`
 	MaxConcurrency  types.Int64  `tfsdk:"max_concurrency"`
 	MaxRetries      types.Int64  `tfsdk:"max_retries"`
 	TimeoutSeconds  types.Int64  `tfsdk:"timeout_seconds"`
 	RetryBackoff    types.String `tfsdk:"retry_backoff"`
+	// ReplicaOf names the target this one is replicated from. Replicas are
+	// never written to; they are only verified after deploys to the primary.
+	ReplicaOf                 types.String `tfsdk:"replica_of"`
+	ReplicationTimeoutSeconds types.Int64  `tfsdk:"replication_timeout_seconds"`
+	// ReadAfterWriteSeconds is how long reads may trail writes on a store
+	// with weaker consistency. See ReadAfterWrite.
+	ReadAfterWriteSeconds types.Int64 `tfsdk:"read_after_write_seconds"`
+	// SASToken and ManagedIdentityClientID authenticate azure targets
+	// instead of DefaultAzureCredential. At most one of them is set.
+	SASToken                types.String `tfsdk:"sas_token"` // sensitive
+	ManagedIdentityClientID types.String `tfsdk:"managed_identity_client_id"`
+}
+
+// ReadAfterWrite returns how long the provider retries reads of a skill on
+// the target named by cfg that do not yet reflect its last write: the
+// read_after_write_seconds of the target block, or zero when unset.
+func (cfg TargetConfigModel) ReadAfterWrite() time.Duration {
+	if cfg.ReadAfterWriteSeconds.IsNull() || cfg.ReadAfterWriteSeconds.IsUnknown() {
+		return 0
+	}
+	return time.Duration(cfg.ReadAfterWriteSeconds.ValueInt64()) * time.Second
 }
@@ -0,0 +1,116 @@
+package providerdata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+)
+
+func TestCheckWritable(t *testing.T) {
+	var nilData *ProviderData
+	if diags := nilData.CheckWritable("agentctx_skill", "create"); diags.HasError() {
+		t.Errorf("nil ProviderData: unexpected errors: %v", diags)
+	}
+	if diags := (&ProviderData{}).CheckWritable("agentctx_skill", "create"); diags.HasError() {
+		t.Errorf("writable provider: unexpected errors: %v", diags)
+	}
+
+	diags := (&ProviderData{ReadOnly: true}).CheckWritable("agentctx_skill", "delete")
+	if !diags.HasError() {
+		t.Fatal("read-only provider: expected an error")
+	}
+	d := diags.Errors()[0]
+	if !strings.HasPrefix(d.Summary(), "[AGX010]") {
+		t.Errorf("summary = %q, want an AGX010 code", d.Summary())
+	}
+	if !strings.Contains(d.Detail(), "Refusing to delete agentctx_skill") {
+		t.Errorf("detail = %q does not name the operation", d.Detail())
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	var nilData *ProviderData
+	if diags := nilData.CheckDrift("agentctx_subagent", "reviewer", "file changed"); diags.HasError() {
+		t.Errorf("nil ProviderData: unexpected errors: %v", diags)
+	}
+	if diags := (&ProviderData{}).CheckDrift("agentctx_subagent", "reviewer", "file changed"); len(diags) != 0 {
+		t.Errorf("lenient provider: unexpected diagnostics: %v", diags)
+	}
+
+	diags := (&ProviderData{StrictDrift: true}).CheckDrift("agentctx_subagent", "reviewer", "file \"a.md\" was deleted")
+	if !diags.HasError() {
+		t.Fatal("strict provider: expected an error")
+	}
+	d := diags.Errors()[0]
+	if !strings.HasPrefix(d.Summary(), "[AGX102]") {
+		t.Errorf("summary = %q, want an AGX102 code", d.Summary())
+	}
+	if !strings.Contains(d.Detail(), `agentctx_subagent "reviewer" was modified outside Terraform: file "a.md" was deleted`) {
+		t.Errorf("detail = %q does not name the resource and the change", d.Detail())
+	}
+}
+
+func TestContent(t *testing.T) {
+	var nilData *ProviderData
+	if got := nilData.Content("x"); got.ValueString() != "x" {
+		t.Errorf("nil ProviderData: Content = %v, want \"x\"", got)
+	}
+	if got := (&ProviderData{}).Content("x"); got.ValueString() != "x" {
+		t.Errorf("full content: Content = %v, want \"x\"", got)
+	}
+	if got := (&ProviderData{OmitContent: true}).Content("x"); !got.IsNull() {
+		t.Errorf("omitted content: Content = %v, want null", got)
+	}
+}
+
+func TestContentFor(t *testing.T) {
+	omit := &ProviderData{OmitContent: true}
+	if got := omit.ContentFor(types.StringNull(), "x"); !got.IsNull() {
+		t.Errorf("unset storage: ContentFor = %v, want provider default (null)", got)
+	}
+	if got := omit.ContentFor(types.StringValue("full"), "x"); got.ValueString() != "x" {
+		t.Errorf("full storage: ContentFor = %v, want \"x\"", got)
+	}
+	if got := (&ProviderData{}).ContentFor(types.StringValue("hash_only"), "x"); !got.IsNull() {
+		t.Errorf("hash_only storage: ContentFor = %v, want null", got)
+	}
+}
+
+func TestRecordDryRun(t *testing.T) {
+	var nilData *ProviderData
+	if nilData.DryRunning() || (&ProviderData{}).DryRunning() {
+		t.Error("DryRunning = true without dry_run")
+	}
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	pd := &ProviderData{DryRun: dryrun.New(reportPath, "test")}
+	if !pd.DryRunning() {
+		t.Fatal("DryRunning = false with dry_run")
+	}
+	diags := pd.RecordDryRun(dryrun.Change{Resource: "agentctx_settings", Operation: dryrun.OpCreate, ID: "/tmp/settings.json"})
+	if !diags.HasError() || !strings.HasPrefix(diags.Errors()[0].Summary(), "[AGX012]") {
+		t.Fatalf("diags = %v, want an AGX012 error", diags)
+	}
+	if !strings.Contains(diags.Errors()[0].Detail(), reportPath) {
+		t.Errorf("detail = %q does not name the report", diags.Errors()[0].Detail())
+	}
+	if _, err := os.Stat(reportPath); err != nil {
+		t.Errorf("report not written: %v", err)
+	}
+}
+
+func TestURLSchemes(t *testing.T) {
+	var nilData *ProviderData
+	if got := nilData.URLSchemes(); strings.Join(got, ",") != "https" {
+		t.Errorf("nil ProviderData: URLSchemes() = %v, want [https]", got)
+	}
+	pd := &ProviderData{AllowedURLSchemes: []string{"https", "http"}}
+	if got := pd.URLSchemes(); strings.Join(got, ",") != "https,http" {
+		t.Errorf("URLSchemes() = %v, want the configured schemes", got)
+	}
+}
@@ -0,0 +1,112 @@
+package providerdata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// ErrTargetExists is returned by TargetRegistry.Register when a target with
+// the same name is already registered.
+var ErrTargetExists = errors.New("target already registered")
+
+// TargetRegistry holds the provider's storage targets by name. Terraform
+// runs resource operations in parallel, so implementations must be safe for
+// concurrent use, including registrations made while other resources are
+// reading.
+type TargetRegistry interface {
+	// Get returns the target registered under name.
+	Get(name string) (target.Target, bool)
+	// Config returns the configuration the target under name was
+	// registered with.
+	Config(name string) (TargetConfigModel, bool)
+	// Names returns the registered target names in sorted order.
+	Names() []string
+	// Len returns the number of registered targets.
+	Len() int
+	// Register adds a target. It returns an error wrapping ErrTargetExists
+	// if name is taken, leaving the existing target in place.
+	Register(name string, t target.Target, cfg TargetConfigModel) error
+}
+
+// NewTargetRegistry returns an empty, concurrency-safe TargetRegistry.
+func NewTargetRegistry() TargetRegistry {
+	return &targetRegistry{
+		targets: make(map[string]target.Target),
+		configs: make(map[string]TargetConfigModel),
+	}
+}
+
+// targetRegistry is the TargetRegistry used by the provider. Lookups take
+// the read lock, so refreshes of many resources do not serialize on it.
+type targetRegistry struct {
+	mu      sync.RWMutex
+	targets map[string]target.Target
+	configs map[string]TargetConfigModel
+}
+
+func (r *targetRegistry) Get(name string) (target.Target, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.targets[name]
+	return t, ok
+}
+
+func (r *targetRegistry) Config(name string) (TargetConfigModel, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cfg, ok := r.configs[name]
+	return cfg, ok
+}
+
+func (r *targetRegistry) Names() []string {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.targets))
+	for name := range r.targets {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+func (r *targetRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.targets)
+}
+
+func (r *targetRegistry) Register(name string, t target.Target, cfg TargetConfigModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.targets[name]; exists {
+		return fmt.Errorf("%q: %w", name, ErrTargetExists)
+	}
+	r.targets[name] = t
+	r.configs[name] = cfg
+	return nil
+}
+
+// ReplicasOf returns the sorted names of the targets in reg whose
+// replica_of is primary.
+func ReplicasOf(reg TargetRegistry, primary string) []string {
+	if primary == "" {
+		return nil
+	}
+	var replicas []string
+	for _, name := range reg.Names() {
+		cfg, _ := reg.Config(name)
+		if cfg.ReplicaOf.ValueString() == primary {
+			replicas = append(replicas, name)
+		}
+	}
+	return replicas
+}
@@ -0,0 +1,97 @@
+package providerdata
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestTargetRegistry(t *testing.T) {
+	r := NewTargetRegistry()
+	for _, name := range []string{"b", "a"} {
+		cfg := TargetConfigModel{Name: types.StringValue(name), Type: types.StringValue("memory")}
+		if err := r.Register(name, target.NewMemoryTarget(name), cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := r.Names(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Names = %v, want [a b]", got)
+	}
+	if r.Len() != 2 {
+		t.Errorf("Len = %d, want 2", r.Len())
+	}
+	if tgt, ok := r.Get("a"); !ok || tgt.Name() != "a" {
+		t.Errorf("Get(a) = %v, %v", tgt, ok)
+	}
+	if cfg, ok := r.Config("b"); !ok || cfg.Name.ValueString() != "b" {
+		t.Errorf("Config(b) = %v, %v", cfg, ok)
+	}
+	if _, ok := r.Get("missing"); ok {
+		t.Error("Get(missing) found a target")
+	}
+
+	replacement := target.NewMemoryTarget("other")
+	err := r.Register("a", replacement, TargetConfigModel{})
+	if !errors.Is(err, ErrTargetExists) {
+		t.Fatalf("Register duplicate = %v, want ErrTargetExists", err)
+	}
+	if tgt, _ := r.Get("a"); tgt == replacement {
+		t.Error("duplicate Register replaced the existing target")
+	}
+}
+
+// TestTargetRegistry_ConcurrentRegistration is meant to be run with -race.
+func TestTargetRegistry_ConcurrentRegistration(t *testing.T) {
+	r := NewTargetRegistry()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("t%02d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := r.Register(name, target.NewMemoryTarget(name), TargetConfigModel{}); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			r.Get(name)
+			r.Names()
+			r.Len()
+		}()
+	}
+	wg.Wait()
+
+	if r.Len() != 50 {
+		t.Errorf("Len = %d, want 50", r.Len())
+	}
+}
+
+func TestReplicasOf(t *testing.T) {
+	r := NewTargetRegistry()
+	for name, primary := range map[string]string{"us": "", "eu": "us", "ap": "us", "other": ""} {
+		cfg := TargetConfigModel{Name: types.StringValue(name), Type: types.StringValue("memory")}
+		if primary != "" {
+			cfg.ReplicaOf = types.StringValue(primary)
+		} else {
+			cfg.ReplicaOf = types.StringNull()
+		}
+		if err := r.Register(name, target.NewMemoryTarget(name), cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := ReplicasOf(r, "us"); len(got) != 2 || got[0] != "ap" || got[1] != "eu" {
+		t.Errorf("ReplicasOf(us) = %v, want [ap eu]", got)
+	}
+	if got := ReplicasOf(r, "other"); len(got) != 0 {
+		t.Errorf("ReplicasOf(other) = %v, want none", got)
+	}
+}
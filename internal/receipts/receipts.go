@@ -0,0 +1,111 @@
+// Package receipts writes a local record of the skill deployments an apply
+// performed, so release tooling can attach it to change tickets without
+// parsing Terraform state. A Writer keeps one JSON file per provider run and
+// rewrites it after every deployment, so the file is complete whenever the
+// apply stops.
+package receipts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// Deployment statuses recorded in a Receipt.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Receipt records one deployment of a skill to a target.
+type Receipt struct {
+	Time            time.Time `json:"time"`
+	SkillName       string    `json:"skill_name"`
+	Target          string    `json:"target"`
+	DeploymentID    string    `json:"deployment_id"`
+	BundleHash      string    `json:"bundle_hash,omitempty"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// Run identifies the provider run the receipts of a file belong to.
+type Run struct {
+	ProviderVersion string `json:"provider_version"`
+	Workspace       string `json:"workspace,omitempty"`
+	Environment     string `json:"environment,omitempty"`
+}
+
+// File is the schema of a receipts file.
+type File struct {
+	Run
+	StartedAt   time.Time `json:"started_at"`
+	Deployments []Receipt `json:"deployments"`
+}
+
+// Writer records receipts in a file under a directory. A nil Writer
+// records nothing.
+type Writer struct {
+	path string
+
+	mu   sync.Mutex // serializes Record
+	file File
+}
+
+// NewWriter returns a Writer for run that writes to a file in dir named
+// after the current time and process, such as
+// receipts-20261018T120000Z-4242.json. Nothing is written, and dir need
+// not exist, until the first receipt is recorded, so runs that deploy
+// nothing, such as plans, leave no file behind.
+func NewWriter(dir string, run Run) *Writer {
+	now := time.Now().UTC()
+	name := fmt.Sprintf("receipts-%s-%d.json", now.Format("20060102T150405Z"), os.Getpid())
+	return &Writer{
+		path: filepath.Join(dir, name),
+		file: File{Run: run, StartedAt: now, Deployments: []Receipt{}},
+	}
+}
+
+// Path returns the path of the receipts file.
+func (w *Writer) Path() string {
+	if w == nil {
+		return ""
+	}
+	return w.path
+}
+
+// Record adds r to the receipts and rewrites the file. Failures are logged
+// and otherwise ignored: receipts must never fail an apply.
+func (w *Writer) Record(ctx context.Context, r Receipt) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.file.Deployments = append(w.file.Deployments, r)
+	data, err := json.MarshalIndent(w.file, "", "  ")
+	if err == nil {
+		data = append(data, '\n')
+		err = os.MkdirAll(longpath.Path(filepath.Dir(w.path)), 0o755)
+	}
+	if err == nil {
+		err = atomicfile.WriteFile(longpath.Path(w.path), data, 0o644)
+	}
+	if err != nil {
+		tflog.Warn(ctx, "could not write deploy receipts", map[string]interface{}{
+			"path":  w.path,
+			"error": err.Error(),
+		})
+	}
+}
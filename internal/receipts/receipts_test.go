@@ -0,0 +1,77 @@
+package receipts
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, path string) File {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatalf("invalid receipts file %q: %v", data, err)
+	}
+	return f
+}
+
+func TestWriter_Record(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "receipts")
+	w := NewWriter(dir, Run{ProviderVersion: "1.2.3", Environment: "prod"})
+
+	if got := filepath.Base(w.Path()); !regexp.MustCompile(`^receipts-\d{8}T\d{6}Z-\d+\.json$`).MatchString(got) {
+		t.Errorf("file name = %q, want receipts-<time>-<pid>.json", got)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("directory created before the first receipt: %v", err)
+	}
+
+	ctx := context.Background()
+	w.Record(ctx, Receipt{
+		Time:            time.Now().UTC(),
+		SkillName:       "analyzing-filings",
+		Target:          "primary",
+		DeploymentID:    "20261018T120000Z-abcd",
+		BundleHash:      "sha256:1234",
+		DurationSeconds: 1.5,
+		Status:          StatusSucceeded,
+	})
+	f := readFile(t, w.Path())
+	if f.ProviderVersion != "1.2.3" || f.Environment != "prod" || f.StartedAt.IsZero() {
+		t.Errorf("run = %+v, want provider version, environment, and start time", f)
+	}
+	if len(f.Deployments) != 1 || f.Deployments[0].DeploymentID != "20261018T120000Z-abcd" {
+		t.Fatalf("deployments = %+v, want the recorded one", f.Deployments)
+	}
+
+	w.Record(ctx, Receipt{SkillName: "analyzing-filings", Target: "replica", Status: StatusFailed, Error: "access denied"})
+	f = readFile(t, w.Path())
+	if len(f.Deployments) != 2 || f.Deployments[1].Status != StatusFailed || f.Deployments[1].Error != "access denied" {
+		t.Errorf("deployments = %+v, want the failure appended", f.Deployments)
+	}
+}
+
+func TestWriter_Nil(t *testing.T) {
+	var w *Writer
+	w.Record(context.Background(), Receipt{})
+	if w.Path() != "" {
+		t.Errorf("nil Writer Path = %q, want empty", w.Path())
+	}
+}
+
+func TestWriter_UnwritableDirIsIgnored(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := NewWriter(filepath.Join(file, "receipts"), Run{})
+	w.Record(context.Background(), Receipt{Status: StatusSucceeded})
+}
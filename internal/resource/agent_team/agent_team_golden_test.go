@@ -0,0 +1,29 @@
+package agentteam
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/golden"
+)
+
+// TestGolden_Team renders a representative team and compares the member and
+// coordination files with testdata/golden/team/. Run with
+// AGENTCTX_UPDATE_GOLDEN=1 to accept intentional output changes.
+func TestGolden_Team(t *testing.T) {
+	root := t.TempDir()
+	model := testTeam(filepath.Join(root, "agents"))
+	model.Description = types.StringValue("Reviews every pull request.")
+	model.CoordinationFile = types.StringValue(filepath.Join(root, "TEAM.md"))
+	model.Agents[1].Model = types.StringValue("haiku")
+
+	r := &AgentTeamResource{}
+	if diags := r.writeTeam(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	golden.AssertDir(t, filepath.Join("testdata", "golden", "team"), root)
+}
@@ -0,0 +1,729 @@
+package agentteam
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/subagent"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
+
+// defaultSelectionPolicy is written to the coordination file when
+// selection_policy is not set.
+const defaultSelectionPolicy = "Delegate each task to the single member whose \"Use when\" entry fits it best. " +
+	"When a task spans several members, run them one at a time in the order listed above and pass each member's findings to the next."
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource              = &AgentTeamResource{}
+	_ resource.ResourceWithConfigure = &AgentTeamResource{}
+)
+
+// NewAgentTeamResource returns a new resource.Resource for the
+// agentctx_agent_team type.
+func NewAgentTeamResource() resource.Resource {
+	return &AgentTeamResource{}
+}
+
+// AgentTeamResource implements the agentctx_agent_team Terraform resource.
+// It generates a set of related Claude Code sub-agent files, named
+// <team>-<agent>.md, plus an optional coordination Markdown file that lists
+// the members and the policy for choosing between them.
+type AgentTeamResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *AgentTeamResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_agent_team"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *AgentTeamResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	nameValidator := validators.Name()
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a team of related Claude Code sub-agents. Each `agent` block is written as a sub-agent file named `<team>-<agent>.md`, and an optional coordination file describes the members and how to choose between them.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Team name. Used as the prefix of every member's sub-agent name and file name (e.g. `review` gives `review-security`). Must use lowercase letters, numbers, and hyphens.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{nameValidator},
+			},
+			"output_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory where the member sub-agent files are written. Typically `.claude/agents` for project-level agents or a plugin `agents/` directory.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+
+			// ---- Optional ----
+			"description": schema.StringAttribute{
+				MarkdownDescription: "What the team as a whole is for. Written at the top of the coordination file.",
+				Optional:            true,
+			},
+			"model": schema.StringAttribute{
+				MarkdownDescription: "Default model for members that do not set their own. Valid values: `sonnet`, `opus`, `haiku`, `inherit`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("sonnet", "opus", "haiku", "inherit"),
+				},
+			},
+			"selection_policy": schema.StringAttribute{
+				MarkdownDescription: "Markdown describing how to choose between members, written under the coordination file's `Selection policy` heading. Defaults to delegating each task to the best-fitting member and running members one at a time when a task spans several.",
+				Optional:            true,
+			},
+			"coordination_file": schema.StringAttribute{
+				MarkdownDescription: "Path of the coordination Markdown file listing the members and the selection policy, for example `.claude/teams/review.md`. Reference it from `CLAUDE.md` with an `@` import so the main conversation knows about the team. Keep it outside `output_dir`, where every Markdown file is read as a sub-agent. When omitted, no coordination file is written.",
+				Optional:            true,
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Unique identifier for the resource, derived from the output directory and team name.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"agent_files": schema.MapAttribute{
+				MarkdownDescription: "Absolute path of each member's sub-agent file, keyed by the agent block name.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"coordination_content": schema.StringAttribute{
+				MarkdownDescription: "Rendered content of the coordination file. Computed even when `coordination_file` is not set, so it can be embedded elsewhere. Null when the provider's `state_content` is `\"hashes\"`.",
+				Computed:            true,
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash over every generated file, prefixed with `sha256:`.",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"agent": schema.ListNestedBlock{
+				MarkdownDescription: "A team member. At least one block is required. Members are listed in the coordination file in declaration order.",
+				Validators: []validator.List{
+					listvalidator.IsRequired(),
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Member name, unique within the team. The sub-agent is named `<team>-<name>`.",
+							Required:            true,
+							Validators:          []validator.String{nameValidator},
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Describes when Claude should delegate to this member. Also used as the member's `Use when` entry in the coordination file.",
+							Required:            true,
+						},
+						"prompt": schema.StringAttribute{
+							MarkdownDescription: "The system prompt for the member.",
+							Required:            true,
+						},
+						"model": schema.StringAttribute{
+							MarkdownDescription: "Model the member uses. Overrides the team-level `model`. Valid values: `sonnet`, `opus`, `haiku`, `inherit`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("sonnet", "opus", "haiku", "inherit"),
+							},
+						},
+						"tools": schema.ListAttribute{
+							MarkdownDescription: "Tools the member can use. Inherits all tools if omitted.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"disallowed_tools": schema.ListAttribute{
+							MarkdownDescription: "Tools to deny, removed from the inherited or specified tool list.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"permission_mode": schema.StringAttribute{
+							MarkdownDescription: "Controls how the member handles permission prompts. Valid values: `default`, `acceptEdits`, `delegate`, `dontAsk`, `bypassPermissions`, `plan`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("default", "acceptEdits", "delegate", "dontAsk", "bypassPermissions", "plan"),
+							},
+						},
+						"max_turns": schema.Int64Attribute{
+							MarkdownDescription: "Maximum number of agentic turns before the member stops.",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"skills": schema.ListAttribute{
+							MarkdownDescription: "Skills to preload into the member's context at startup.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"memory": schema.StringAttribute{
+							MarkdownDescription: "Persistent memory scope for the member. Valid values: `user`, `project`, `local`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("user", "project", "local"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *AgentTeamResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *AgentTeamResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_agent_team", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan AgentTeamResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRunTeam(ctx, dryrun.OpCreate, &plan, nil)...)
+		return
+	}
+
+	resp.Diagnostics.Append(r.writeTeam(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "created agent team", map[string]interface{}{
+		"name":   plan.Name.ValueString(),
+		"agents": len(plan.Agents),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *AgentTeamResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state AgentTeamResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agentFiles := make(map[string]string)
+	resp.Diagnostics.Append(state.AgentFiles.ElementsAs(ctx, &agentFiles, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agentContents := make(map[string]string, len(agentFiles))
+	found := 0
+	for name, filePath := range agentFiles {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read sub-agent file %q: %s", filePath, err))
+			return
+		}
+		agentContents[name] = string(data)
+		found++
+	}
+
+	if found < len(agentFiles) {
+		var missing []string
+		for name, filePath := range agentFiles {
+			if _, ok := agentContents[name]; !ok {
+				missing = append(missing, filePath)
+			}
+		}
+		sort.Strings(missing)
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_agent_team", state.ID.ValueString(), "deleted "+strings.Join(missing, ", "))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if found == 0 {
+		tflog.Info(ctx, "agent team files not found on disk, removing from state", map[string]interface{}{
+			"name": state.Name.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Without a coordination file the content exists only in state; when
+	// state_content = "hashes" keeps it out, render it again for the hash.
+	coordination := state.CoordinationContent.ValueString()
+	if state.CoordinationContent.IsNull() {
+		coordination = renderCoordination(&state)
+	}
+	if coordPath := coordinationPath(state); coordPath != "" {
+		data, err := os.ReadFile(coordPath)
+		switch {
+		case err == nil:
+			coordination = string(data)
+		case os.IsNotExist(err):
+			coordination = ""
+		default:
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read coordination file %q: %s", coordPath, err))
+			return
+		}
+	}
+
+	hash := computeTeamHash(agentContents, coordination)
+	if prior := state.ContentHash.ValueString(); prior != "" && hash != prior {
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_agent_team", state.ID.ValueString(), fmt.Sprintf("the team's files have hash %s, want %s", hash, prior))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	state.CoordinationContent = r.providerData.Content(coordination)
+	state.ContentHash = types.StringValue(hash)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *AgentTeamResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_agent_team", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan AgentTeamResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state AgentTeamResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRunTeam(ctx, dryrun.OpUpdate, &plan, &state)...)
+		return
+	}
+
+	resp.Diagnostics.Append(r.writeTeam(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Remove files that belonged to the prior configuration only.
+	oldFiles := make(map[string]string)
+	resp.Diagnostics.Append(state.AgentFiles.ElementsAs(ctx, &oldFiles, false)...)
+	newFiles := make(map[string]string)
+	resp.Diagnostics.Append(plan.AgentFiles.ElementsAs(ctx, &newFiles, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for name, filePath := range oldFiles {
+		if _, kept := newFiles[name]; kept {
+			continue
+		}
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete removed team member file %q: %s", filePath, err))
+			return
+		}
+	}
+
+	if oldCoord := coordinationPath(state); oldCoord != "" && oldCoord != coordinationPath(plan) {
+		if err := os.Remove(oldCoord); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete previous coordination file %q: %s", oldCoord, err))
+			return
+		}
+	}
+
+	tflog.Info(ctx, "updated agent team", map[string]interface{}{
+		"name":   plan.Name.ValueString(),
+		"agents": len(plan.Agents),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+func (r *AgentTeamResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_agent_team", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state AgentTeamResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agentFiles := make(map[string]string)
+	resp.Diagnostics.Append(state.AgentFiles.ElementsAs(ctx, &agentFiles, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		var files []dryrun.FileChange
+		for _, name := range sortedKeys(agentFiles) {
+			files = append(files, dryrun.RemoveFile(agentFiles[name]))
+		}
+		if coordPath := coordinationPath(state); coordPath != "" {
+			files = append(files, dryrun.RemoveFile(coordPath))
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_agent_team",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Files:     files,
+		})...)
+		return
+	}
+
+	for _, filePath := range agentFiles {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete sub-agent file %q: %s", filePath, err))
+			return
+		}
+	}
+
+	if coordPath := coordinationPath(state); coordPath != "" {
+		if err := os.Remove(coordPath); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete coordination file %q: %s", coordPath, err))
+			return
+		}
+	}
+
+	tflog.Info(ctx, "deleted agent team", map[string]interface{}{
+		"name": state.Name.ValueString(),
+	})
+}
+
+// --------------------------------------------------------------------------
+// Rendering
+// --------------------------------------------------------------------------
+
+// memberName returns the sub-agent name of a team member.
+func memberName(team, agent string) string {
+	return team + "-" + agent
+}
+
+// renderAgents renders the sub-agent file content for every member, keyed by
+// agent block name. Member names must be unique within the team.
+func renderAgents(ctx context.Context, model *AgentTeamResourceModel) (map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	team := model.Name.ValueString()
+	contents := make(map[string]string, len(model.Agents))
+
+	for i, a := range model.Agents {
+		name := a.Name.ValueString()
+		if _, dup := contents[name]; dup {
+			diags.AddAttributeError(
+				path.Root("agent").AtListIndex(i).AtName("name"),
+				errcode.DuplicateName.Summary("Duplicate Team Member"),
+				fmt.Sprintf("Agent name %q is used more than once in team %q.", name, team),
+			)
+			continue
+		}
+		// Each part is a valid name, but together they may be too long.
+		if err := validators.CheckName(memberName(team, name)); err != nil {
+			diags.AddAttributeError(
+				path.Root("agent").AtListIndex(i).AtName("name"),
+				errcode.InvalidConfig.Summary("Invalid Name"),
+				fmt.Sprintf("The sub-agent name of team member %q is invalid: %s", name, err),
+			)
+			continue
+		}
+
+		agentModel := a.Model
+		if agentModel.IsNull() || agentModel.IsUnknown() {
+			agentModel = model.Model
+		}
+
+		content, d := subagent.Render(ctx, &subagent.SubagentResourceModel{
+			Name:            types.StringValue(memberName(team, name)),
+			Description:     a.Description,
+			Prompt:          a.Prompt,
+			Model:           agentModel,
+			Tools:           a.Tools,
+			DisallowedTools: a.DisallowedTools,
+			PermissionMode:  a.PermissionMode,
+			MaxTurns:        a.MaxTurns,
+			Skills:          a.Skills,
+			Memory:          a.Memory,
+		})
+		diags.Append(d...)
+		if d.HasError() {
+			continue
+		}
+		contents[name] = content
+	}
+
+	return contents, diags
+}
+
+// renderCoordination builds the coordination Markdown file: a heading, the
+// team description, a member table in declaration order, and the selection
+// policy.
+func renderCoordination(model *AgentTeamResourceModel) string {
+	team := model.Name.ValueString()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s agent team\n\n", team)
+
+	if !model.Description.IsNull() && !model.Description.IsUnknown() && model.Description.ValueString() != "" {
+		sb.WriteString(strings.TrimSpace(model.Description.ValueString()))
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("## Members\n\n")
+	sb.WriteString("| Sub-agent | Use when |\n")
+	sb.WriteString("|-----------|----------|\n")
+	for _, a := range model.Agents {
+		desc := strings.Join(strings.Fields(a.Description.ValueString()), " ")
+		desc = strings.ReplaceAll(desc, "|", `\|`)
+		fmt.Fprintf(&sb, "| `%s` | %s |\n", memberName(team, a.Name.ValueString()), desc)
+	}
+
+	policy := defaultSelectionPolicy
+	if !model.SelectionPolicy.IsNull() && !model.SelectionPolicy.IsUnknown() && strings.TrimSpace(model.SelectionPolicy.ValueString()) != "" {
+		policy = strings.TrimSpace(model.SelectionPolicy.ValueString())
+	}
+	sb.WriteString("\n## Selection policy\n\n")
+	sb.WriteString(policy)
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// --------------------------------------------------------------------------
+// File operations
+// --------------------------------------------------------------------------
+
+// coordinationPath returns the absolute path of the model's coordination
+// file, or "" when none is configured.
+func coordinationPath(model AgentTeamResourceModel) string {
+	if model.CoordinationFile.IsNull() || model.CoordinationFile.IsUnknown() || model.CoordinationFile.ValueString() == "" {
+		return ""
+	}
+	p := model.CoordinationFile.ValueString()
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// writeTeam renders and writes every member file and the coordination file,
+// then populates the computed attributes of model.
+func (r *AgentTeamResource) writeTeam(ctx context.Context, model *AgentTeamResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	contents, d := renderAgents(ctx, model)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+	coordination := renderCoordination(model)
+
+	outputDir, err := filepath.Abs(model.OutputDir.ValueString())
+	if err != nil {
+		diags.AddError(errcode.PathResolution.Summary("Invalid Output Directory"), fmt.Sprintf("Failed to resolve output_dir %q: %s", model.OutputDir.ValueString(), err))
+		return diags
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("Directory Creation Failed"), fmt.Sprintf("Failed to create output directory %q: %s", outputDir, err))
+		return diags
+	}
+
+	team := model.Name.ValueString()
+	agentFiles := make(map[string]string, len(contents))
+	for name, content := range contents {
+		filePath := filepath.Join(outputDir, memberName(team, name)+".md")
+		if err := atomicfile.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write sub-agent file %q: %s", filePath, err))
+			return diags
+		}
+		agentFiles[name] = filePath
+	}
+
+	if coordPath := coordinationPath(*model); coordPath != "" {
+		if err := os.MkdirAll(filepath.Dir(coordPath), 0o755); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("Directory Creation Failed"), fmt.Sprintf("Failed to create directory for coordination file %q: %s", coordPath, err))
+			return diags
+		}
+		if err := atomicfile.WriteFile(coordPath, []byte(coordination), 0o644); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write coordination file %q: %s", coordPath, err))
+			return diags
+		}
+	}
+
+	filesMap, d := types.MapValueFrom(ctx, types.StringType, agentFiles)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	model.ID = types.StringValue(filepath.Join(outputDir, team))
+	model.AgentFiles = filesMap
+	model.CoordinationContent = r.providerData.Content(coordination)
+	model.ContentHash = types.StringValue(computeTeamHash(contents, coordination))
+
+	return diags
+}
+
+// dryRunTeam records the files writeTeam would write for model in the
+// dry-run report, and for an update the files of prior it would remove.
+func (r *AgentTeamResource) dryRunTeam(ctx context.Context, operation string, model, prior *AgentTeamResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	contents, d := renderAgents(ctx, model)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+	outputDir, err := filepath.Abs(model.OutputDir.ValueString())
+	if err != nil {
+		diags.AddError(errcode.PathResolution.Summary("Invalid Output Directory"), fmt.Sprintf("Failed to resolve output_dir %q: %s", model.OutputDir.ValueString(), err))
+		return diags
+	}
+
+	team := model.Name.ValueString()
+	id := filepath.Join(outputDir, team)
+	var files []dryrun.FileChange
+	for _, name := range sortedKeys(contents) {
+		files = append(files, dryrun.WriteFile(filepath.Join(outputDir, memberName(team, name)+".md"), []byte(contents[name])))
+	}
+	coordPath := coordinationPath(*model)
+	if coordPath != "" {
+		files = append(files, dryrun.WriteFile(coordPath, []byte(renderCoordination(model))))
+	}
+
+	if prior != nil {
+		id = prior.ID.ValueString()
+		oldFiles := make(map[string]string)
+		diags.Append(prior.AgentFiles.ElementsAs(ctx, &oldFiles, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		for _, name := range sortedKeys(oldFiles) {
+			if _, kept := contents[name]; !kept {
+				files = append(files, dryrun.RemoveFile(oldFiles[name]))
+			}
+		}
+		if oldCoord := coordinationPath(*prior); oldCoord != "" && oldCoord != coordPath {
+			files = append(files, dryrun.RemoveFile(oldCoord))
+		}
+	}
+
+	return r.providerData.RecordDryRun(dryrun.Change{
+		Resource:  "agentctx_agent_team",
+		Operation: operation,
+		ID:        id,
+		Files:     files,
+	})
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// computeTeamHash returns the SHA-256 hash over every member file (in agent
+// name order) and the coordination content, prefixed with "sha256:".
+func computeTeamHash(agentContents map[string]string, coordination string) string {
+	names := make([]string, 0, len(agentContents))
+	for name := range agentContents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%s\x00", name, agentContents[name])
+	}
+	fmt.Fprintf(h, "coordination\x00%s", coordination)
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
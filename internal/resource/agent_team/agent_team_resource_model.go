@@ -0,0 +1,41 @@
+package agentteam
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// AgentTeamResourceModel maps the agentctx_agent_team resource schema to a Go
+// struct.
+type AgentTeamResourceModel struct {
+	// Required
+	Name      types.String `tfsdk:"name"`
+	OutputDir types.String `tfsdk:"output_dir"`
+
+	// Optional
+	Description      types.String `tfsdk:"description"`
+	Model            types.String `tfsdk:"model"`
+	SelectionPolicy  types.String `tfsdk:"selection_policy"`
+	CoordinationFile types.String `tfsdk:"coordination_file"`
+
+	// Blocks
+	Agents []TeamAgentModel `tfsdk:"agent"`
+
+	// Computed
+	ID                  types.String `tfsdk:"id"`
+	AgentFiles          types.Map    `tfsdk:"agent_files"` // agent name -> absolute file path
+	CoordinationContent types.String `tfsdk:"coordination_content"`
+	ContentHash         types.String `tfsdk:"content_hash"`
+}
+
+// TeamAgentModel maps a single agent {} block. Each block becomes a sub-agent
+// file named <team>-<name>.md.
+type TeamAgentModel struct {
+	Name            types.String `tfsdk:"name"`
+	Description     types.String `tfsdk:"description"`
+	Prompt          types.String `tfsdk:"prompt"`
+	Model           types.String `tfsdk:"model"`
+	Tools           types.List   `tfsdk:"tools"`
+	DisallowedTools types.List   `tfsdk:"disallowed_tools"`
+	PermissionMode  types.String `tfsdk:"permission_mode"`
+	MaxTurns        types.Int64  `tfsdk:"max_turns"`
+	Skills          types.List   `tfsdk:"skills"`
+	Memory          types.String `tfsdk:"memory"`
+}
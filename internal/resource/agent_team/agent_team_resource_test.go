@@ -0,0 +1,169 @@
+package agentteam
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+func testTeam(outputDir string) *AgentTeamResourceModel {
+	return &AgentTeamResourceModel{
+		Name:             types.StringValue("review"),
+		OutputDir:        types.StringValue(outputDir),
+		Description:      types.StringNull(),
+		Model:            types.StringValue("sonnet"),
+		SelectionPolicy:  types.StringNull(),
+		CoordinationFile: types.StringNull(),
+		Agents: []TeamAgentModel{
+			testAgent("security", "Reviews changes for security issues", "You are a security reviewer."),
+			testAgent("docs", "Checks documentation | style", "You are a docs reviewer."),
+		},
+	}
+}
+
+func testAgent(name, description, prompt string) TeamAgentModel {
+	return TeamAgentModel{
+		Name:            types.StringValue(name),
+		Description:     types.StringValue(description),
+		Prompt:          types.StringValue(prompt),
+		Model:           types.StringNull(),
+		Tools:           types.ListNull(types.StringType),
+		DisallowedTools: types.ListNull(types.StringType),
+		PermissionMode:  types.StringNull(),
+		MaxTurns:        types.Int64Null(),
+		Skills:          types.ListNull(types.StringType),
+		Memory:          types.StringNull(),
+	}
+}
+
+func TestRenderAgents_PrefixesNamesAndAppliesTeamModel(t *testing.T) {
+	model := testTeam(t.TempDir())
+	model.Agents[1].Model = types.StringValue("haiku")
+
+	contents, diags := renderAgents(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	security := contents["security"]
+	if !strings.Contains(security, "name: review-security\n") {
+		t.Errorf("expected prefixed name in frontmatter, got:\n%s", security)
+	}
+	if !strings.Contains(security, "model: sonnet\n") {
+		t.Errorf("expected team default model, got:\n%s", security)
+	}
+
+	docs := contents["docs"]
+	if !strings.Contains(docs, "model: haiku\n") {
+		t.Errorf("expected member model to override team model, got:\n%s", docs)
+	}
+}
+
+func TestRenderAgents_DuplicateName(t *testing.T) {
+	model := testTeam(t.TempDir())
+	model.Agents = append(model.Agents, testAgent("security", "Again", "Duplicate."))
+
+	_, diags := renderAgents(context.Background(), model)
+	if !diags.HasError() {
+		t.Fatal("expected error for duplicate member name")
+	}
+	if got, want := diags.Errors()[0].Summary(), errcode.DuplicateName.Summary("Duplicate Team Member"); got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestRenderAgents_MemberNameTooLong(t *testing.T) {
+	model := testTeam(t.TempDir())
+	model.Agents = append(model.Agents, testAgent(strings.Repeat("a", 60), "Long", "Long."))
+
+	_, diags := renderAgents(context.Background(), model)
+	if !diags.HasError() {
+		t.Fatal("expected error for a <team>-<agent> name over 64 characters")
+	}
+	if got, want := diags.Errors()[0].Summary(), errcode.InvalidConfig.Summary("Invalid Name"); got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestRenderCoordination(t *testing.T) {
+	model := testTeam(t.TempDir())
+	model.Description = types.StringValue("Reviews every pull request.")
+
+	got := renderCoordination(model)
+
+	for _, want := range []string{
+		"# review agent team\n",
+		"Reviews every pull request.\n",
+		"| `review-security` | Reviews changes for security issues |\n",
+		"| `review-docs` | Checks documentation \\| style |\n",
+		"## Selection policy\n\n" + defaultSelectionPolicy + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("coordination content missing %q, got:\n%s", want, got)
+		}
+	}
+
+	// Members are listed in declaration order.
+	if strings.Index(got, "review-security") > strings.Index(got, "review-docs") {
+		t.Error("expected members in declaration order")
+	}
+
+	model.SelectionPolicy = types.StringValue("Always run security first.")
+	if got := renderCoordination(model); !strings.Contains(got, "## Selection policy\n\nAlways run security first.\n") {
+		t.Errorf("expected custom selection policy, got:\n%s", got)
+	}
+}
+
+func TestWriteTeam_WritesMemberAndCoordinationFiles(t *testing.T) {
+	root := t.TempDir()
+	model := testTeam(filepath.Join(root, "agents"))
+	model.CoordinationFile = types.StringValue(filepath.Join(root, "teams", "review.md"))
+
+	r := &AgentTeamResource{}
+	if diags := r.writeTeam(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	for _, name := range []string{"review-security.md", "review-docs.md"} {
+		if _, err := os.Stat(filepath.Join(root, "agents", name)); err != nil {
+			t.Errorf("expected member file %s: %v", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "teams", "review.md"))
+	if err != nil {
+		t.Fatalf("reading coordination file: %v", err)
+	}
+	if string(data) != model.CoordinationContent.ValueString() {
+		t.Error("coordination file content does not match coordination_content")
+	}
+
+	files := make(map[string]string)
+	model.AgentFiles.ElementsAs(context.Background(), &files, false)
+	if len(files) != 2 || files["security"] != filepath.Join(root, "agents", "review-security.md") {
+		t.Errorf("unexpected agent_files: %v", files)
+	}
+	if !strings.HasPrefix(model.ContentHash.ValueString(), "sha256:") {
+		t.Errorf("content_hash = %q, want sha256: prefix", model.ContentHash.ValueString())
+	}
+}
+
+func TestComputeTeamHash(t *testing.T) {
+	a := computeTeamHash(map[string]string{"x": "1", "y": "2"}, "coord")
+	b := computeTeamHash(map[string]string{"y": "2", "x": "1"}, "coord")
+	if a != b {
+		t.Error("expected hash to be independent of map order")
+	}
+	if a == computeTeamHash(map[string]string{"x": "1", "y": "2"}, "other") {
+		t.Error("expected coordination content to affect the hash")
+	}
+	if a == computeTeamHash(map[string]string{"x": "1"}, "coord") {
+		t.Error("expected a missing member to affect the hash")
+	}
+}
@@ -0,0 +1,411 @@
+package anthropicskill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource                = &AnthropicSkillResource{}
+	_ resource.ResourceWithConfigure   = &AnthropicSkillResource{}
+	_ resource.ResourceWithImportState = &AnthropicSkillResource{}
+)
+
+// NewAnthropicSkillResource returns a new resource.Resource for the
+// agentctx_anthropic_skill type.
+func NewAnthropicSkillResource() resource.Resource {
+	return &AnthropicSkillResource{}
+}
+
+// AnthropicSkillResource implements the agentctx_anthropic_skill Terraform
+// resource. It manages only the Skill object in the Anthropic registry; no
+// bundle is deployed to storage targets, so the provider needs no target
+// blocks. Versions are published with agentctx_skill_version.
+type AnthropicSkillResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *AnthropicSkillResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_anthropic_skill"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *AnthropicSkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a skill in the Anthropic registry without deploying it to storage targets. Use `agentctx_skill_version` to publish versions.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"source_dir": schema.StringAttribute{
+				MarkdownDescription: "Path to the local directory uploaded as the skill's initial files when the skill is created. Later changes to the directory are not uploaded; publish them with `agentctx_skill_version`.",
+				Required:            true,
+			},
+
+			// ---- Optional ----
+			"display_title": schema.StringAttribute{
+				MarkdownDescription: "Human-readable display title for the skill. Defaults to the base name of `source_dir`. Changes are applied in place.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"on_destroy": schema.StringAttribute{
+				MarkdownDescription: "What happens to the skill when the resource is destroyed: `\"delete\"` deletes the skill and its versions, `\"detach\"` leaves them in the registry for another team or pipeline to adopt. Defaults to the provider's `destroy_remote` setting.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(anthropic.OnDestroyDelete, anthropic.OnDestroyDetach),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Anthropic skill ID.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"latest_version": schema.StringAttribute{
+				MarkdownDescription: "Latest version of the skill reported by the registry.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "Source of the skill reported by the registry, e.g. `\"custom\"`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"created_at": schema.StringAttribute{
+				MarkdownDescription: "RFC 3339 timestamp when the skill was created.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"updated_at": schema.StringAttribute{
+				MarkdownDescription: "RFC 3339 timestamp when the skill was last updated.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *AnthropicSkillResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *AnthropicSkillResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_anthropic_skill", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan AnthropicSkillResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.Anthropic == nil {
+		resp.Diagnostics.AddError(
+			errcode.AnthropicNotConfigured.Summary("Anthropic Client Not Configured"),
+			"The agentctx_anthropic_skill resource requires the provider anthropic block to be configured.",
+		)
+		return
+	}
+
+	// 1. Scan the source bundle. The hash keys the create request so a
+	// retried upload cannot register the skill twice.
+	sourceDir := plan.SourceDir.ValueString()
+	b, err := bundle.ScanBundle(sourceDir, nil, false)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidBundle.Summary("Bundle Scan Failed"), fmt.Sprintf("Failed to scan source directory %q: %s", sourceDir, err))
+		return
+	}
+
+	displayTitle := filepath.Base(sourceDir)
+	if !plan.DisplayTitle.IsNull() && !plan.DisplayTitle.IsUnknown() {
+		displayTitle = plan.DisplayTitle.ValueString()
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_anthropic_skill",
+			Operation: dryrun.OpCreate,
+			ID:        displayTitle,
+			Registry:  []dryrun.RegistryChange{{Action: dryrun.RegistryCreateSkill}},
+		})...)
+		return
+	}
+
+	// 2. Create the skill in the Anthropic registry.
+	tflog.Info(ctx, "creating skill in Anthropic registry", map[string]interface{}{
+		"display_title": displayTitle,
+		"bundle_hash":   b.BundleHash,
+	})
+
+	skill, createErr := r.providerData.Anthropic.CreateSkill(ctx, sourceDir, displayTitle, anthropic.IdempotencyKey(sourceDir, displayTitle, b.BundleHash))
+	if createErr != nil {
+		resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Anthropic Create Skill Failed"), fmt.Sprintf("Failed to create skill: %s", createErr))
+		return
+	}
+
+	// 3. Save state.
+	setSkill(&plan, skill)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *AnthropicSkillResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state AnthropicSkillResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.Anthropic == nil {
+		// Without a client the skill cannot be read. Leave state as-is; the
+		// next apply reports the missing anthropic block.
+		return
+	}
+
+	skillID := state.ID.ValueString()
+	skill, err := r.providerData.Anthropic.GetSkill(ctx, skillID)
+	if err != nil {
+		if isAPINotFound(err) {
+			tflog.Info(ctx, "skill not found in Anthropic registry, removing from state", map[string]interface{}{
+				"skill_id": skillID,
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Read Skill Failed"), fmt.Sprintf("Failed to read skill %q: %s", skillID, err))
+		return
+	}
+
+	setSkill(&state, skill)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *AnthropicSkillResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_anthropic_skill", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state AnthropicSkillResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.Anthropic == nil {
+		resp.Diagnostics.AddError(
+			errcode.AnthropicNotConfigured.Summary("Anthropic Client Not Configured"),
+			"The agentctx_anthropic_skill resource requires the provider anthropic block to be configured.",
+		)
+		return
+	}
+
+	skillID := state.ID.ValueString()
+
+	// Only the display title is mutable; source_dir is read on create.
+	displayTitle := state.DisplayTitle.ValueString()
+	if !plan.DisplayTitle.IsNull() && !plan.DisplayTitle.IsUnknown() {
+		displayTitle = plan.DisplayTitle.ValueString()
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_anthropic_skill",
+			Operation: dryrun.OpUpdate,
+			ID:        skillID,
+			Registry:  []dryrun.RegistryChange{{Action: dryrun.RegistryUpdateSkill, SkillID: skillID}},
+		})...)
+		return
+	}
+
+	skill, err := r.providerData.Anthropic.UpdateSkill(ctx, skillID, anthropic.UpdateSkillRequest{
+		DisplayTitle: displayTitle,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Anthropic Update Skill Failed"), fmt.Sprintf("Failed to update skill %q: %s", skillID, err))
+		return
+	}
+
+	setSkill(&plan, skill)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+func (r *AnthropicSkillResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_anthropic_skill", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state AnthropicSkillResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A detached skill (on_destroy, or destroy_remote when on_destroy is
+	// unset) stays in the registry with all of its versions.
+	if r.providerData.Anthropic == nil || !r.providerData.Anthropic.DeleteOnDestroy(state.OnDestroy.ValueString()) {
+		if r.providerData.DryRunning() {
+			resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+				Resource:  "agentctx_anthropic_skill",
+				Operation: dryrun.OpDelete,
+				ID:        state.ID.ValueString(),
+			})...)
+			return
+		}
+		tflog.Info(ctx, "detaching skill, leaving it in the Anthropic registry", map[string]interface{}{
+			"skill_id": state.ID.ValueString(),
+		})
+		return
+	}
+
+	// The API requires every version to be deleted before the skill.
+	skillID := state.ID.ValueString()
+	versions, err := r.providerData.Anthropic.ListVersions(ctx, skillID)
+	if err != nil {
+		if isAPINotFound(err) {
+			return
+		}
+		resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Anthropic Delete Skill Failed"), fmt.Sprintf("Failed to list versions of skill %q: %s", skillID, err))
+		return
+	}
+	if r.providerData.DryRunning() {
+		var changes []dryrun.RegistryChange
+		for _, v := range versions {
+			changes = append(changes, dryrun.RegistryChange{Action: dryrun.RegistryDeleteVersion, SkillID: skillID, Version: v.Version})
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_anthropic_skill",
+			Operation: dryrun.OpDelete,
+			ID:        skillID,
+			Registry:  append(changes, dryrun.RegistryChange{Action: dryrun.RegistryDeleteSkill, SkillID: skillID}),
+		})...)
+		return
+	}
+	for _, v := range versions {
+		tflog.Info(ctx, "deleting skill version from Anthropic registry", map[string]interface{}{
+			"skill_id": skillID,
+			"version":  v.Version,
+		})
+		if err := r.providerData.Anthropic.DeleteVersion(ctx, skillID, v.Version); err != nil && !isAPINotFound(err) {
+			resp.Diagnostics.AddError(
+				errcode.AnthropicRequestFailed.Summary("Delete Version Failed"),
+				fmt.Sprintf("Failed to delete version %q for skill %q: %s", v.Version, skillID, err),
+			)
+			return
+		}
+	}
+
+	tflog.Info(ctx, "deleting skill from Anthropic registry", map[string]interface{}{
+		"skill_id": skillID,
+	})
+	if err := r.providerData.Anthropic.DeleteSkill(ctx, skillID); err != nil && !isAPINotFound(err) {
+		resp.Diagnostics.AddError(
+			errcode.AnthropicRequestFailed.Summary("Anthropic Delete Skill Failed"),
+			fmt.Sprintf("Failed to delete skill %q from Anthropic registry: %s", skillID, err),
+		)
+	}
+}
+
+// --------------------------------------------------------------------------
+// ImportState
+// --------------------------------------------------------------------------
+
+// ImportState imports a skill by its Anthropic skill ID. source_dir is taken
+// from configuration on the next apply; it is not uploaded again.
+func (r *AnthropicSkillResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// setSkill copies the registry's view of skill into model.
+func setSkill(model *AnthropicSkillResourceModel, skill *anthropic.Skill) {
+	model.ID = types.StringValue(skill.ID)
+	model.DisplayTitle = types.StringValue(skill.DisplayTitle)
+	model.LatestVersion = types.StringValue(skill.LatestVersion)
+	model.Source = types.StringValue(skill.Source)
+	model.CreatedAt = types.StringValue(skill.CreatedAt)
+	model.UpdatedAt = types.StringValue(skill.UpdatedAt)
+}
+
+// isAPINotFound reports whether err wraps an anthropic.APIError with a 404
+// status code.
+func isAPINotFound(err error) bool {
+	var apiErr *anthropic.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == 404
+}
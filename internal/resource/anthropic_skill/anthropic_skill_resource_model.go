@@ -0,0 +1,21 @@
+package anthropicskill
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// AnthropicSkillResourceModel maps the agentctx_anthropic_skill resource
+// schema to a Go struct.
+type AnthropicSkillResourceModel struct {
+	// Required
+	SourceDir types.String `tfsdk:"source_dir"`
+
+	// Optional
+	DisplayTitle types.String `tfsdk:"display_title"`
+	OnDestroy    types.String `tfsdk:"on_destroy"`
+
+	// Computed
+	ID            types.String `tfsdk:"id"`
+	LatestVersion types.String `tfsdk:"latest_version"`
+	Source        types.String `tfsdk:"source"`
+	CreatedAt     types.String `tfsdk:"created_at"`
+	UpdatedAt     types.String `tfsdk:"updated_at"`
+}
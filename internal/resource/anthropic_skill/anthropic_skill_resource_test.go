@@ -0,0 +1,45 @@
+package anthropicskill
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+)
+
+func TestIsAPINotFound(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"direct 404", &anthropic.APIError{StatusCode: 404}, true},
+		{"wrapped 404", fmt.Errorf("get skill: %w", &anthropic.APIError{StatusCode: 404}), true},
+		{"500", &anthropic.APIError{StatusCode: 500}, false},
+		{"non-API error", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+	for _, tc := range cases {
+		if got := isAPINotFound(tc.err); got != tc.want {
+			t.Errorf("%s: isAPINotFound = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSetSkill(t *testing.T) {
+	var model AnthropicSkillResourceModel
+	setSkill(&model, &anthropic.Skill{
+		ID:            "skill_01",
+		DisplayTitle:  "Reviewer",
+		LatestVersion: "1759178010641129",
+		Source:        "custom",
+		CreatedAt:     "2026-01-01T00:00:00Z",
+		UpdatedAt:     "2026-01-02T00:00:00Z",
+	})
+	if model.ID.ValueString() != "skill_01" || model.DisplayTitle.ValueString() != "Reviewer" ||
+		model.LatestVersion.ValueString() != "1759178010641129" || model.Source.ValueString() != "custom" ||
+		model.UpdatedAt.ValueString() != "2026-01-02T00:00:00Z" {
+		t.Errorf("unexpected model: %+v", model)
+	}
+}
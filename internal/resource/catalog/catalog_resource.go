@@ -0,0 +1,823 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
+
+const (
+	// jsonFile and markdownFile are the names of the generated catalog
+	// files, in output_dir or under the catalog's key prefix.
+	jsonFile     = "catalog.json"
+	markdownFile = "catalog.md"
+
+	// keyPrefix is the target key prefix under which catalogs are written,
+	// as catalogs/<name>/catalog.json and catalogs/<name>/catalog.md.
+	keyPrefix = "catalogs/"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource              = &CatalogResource{}
+	_ resource.ResourceWithConfigure = &CatalogResource{}
+)
+
+// NewCatalogResource returns a new resource.Resource for the
+// agentctx_catalog type.
+func NewCatalogResource() resource.Resource {
+	return &CatalogResource{}
+}
+
+// CatalogResource implements the agentctx_catalog Terraform resource. It
+// aggregates metadata about skills and plugins into a catalog.json and a
+// catalog.md index, written either to a local directory or to a provider
+// target.
+type CatalogResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *CatalogResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_catalog"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *CatalogResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Generates an index of the skills and plugins an organisation deploys. Each `skill` and `plugin` block becomes an entry in " +
+			"`catalog.json` and in the Markdown index `catalog.md`, written to a local `output_dir` or to a provider `target`.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Catalog name. Used as the heading of `catalog.md` and, with `target`, in the object key prefix `catalogs/<name>/`. " +
+					"Must use lowercase letters, numbers, and hyphens.",
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					// The name is a single object key segment on a target. Catalogs
+					// are not loaded by Claude Code, so reserved words are allowed.
+					stringvalidator.RegexMatches(validators.NamePattern, "must contain only lowercase letters, numbers, and hyphens, and must start and end with a letter or number"),
+				},
+			},
+
+			// ---- Optional ----
+			"description": schema.StringAttribute{
+				MarkdownDescription: "What the catalog covers. Written at the top of `catalog.md` and as `description` in `catalog.json`.",
+				Optional:            true,
+			},
+			"output_dir": schema.StringAttribute{
+				MarkdownDescription: "Local directory the catalog files are written to. Exactly one of `output_dir` and `target` is required.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("output_dir"), path.MatchRoot("target")),
+				},
+			},
+			"target": schema.StringAttribute{
+				MarkdownDescription: "Name of the provider target the catalog files are written to, as `catalogs/<name>/catalog.json` and `catalogs/<name>/catalog.md`. " +
+					"Replica targets are not allowed.",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Unique identifier for the resource, derived from the output directory or target and the catalog name.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"json_path": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of `catalog.json`, or its object key on `target`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"markdown_path": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of `catalog.md`, or its object key on `target`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"catalog_json": schema.StringAttribute{
+				MarkdownDescription: "Content of `catalog.json`. Null when the provider's `state_content` is `\"hashes\"`.",
+				Computed:            true,
+			},
+			"catalog_markdown": schema.StringAttribute{
+				MarkdownDescription: "Content of `catalog.md`. Null when the provider's `state_content` is `\"hashes\"`.",
+				Computed:            true,
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash over both catalog files, prefixed with `sha256:`.",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"skill": schema.ListNestedBlock{
+				MarkdownDescription: "A skill listed in the catalog, typically built from the attributes of an `agentctx_skill`. Entries are sorted by name.",
+				NestedObject:        entryBlock("skill"),
+			},
+			"plugin": schema.ListNestedBlock{
+				MarkdownDescription: "A plugin listed in the catalog, typically built from the attributes of an `agentctx_plugin`. Entries are sorted by name.",
+				NestedObject:        entryBlock("plugin"),
+			},
+		},
+	}
+}
+
+// entryBlock returns the nested object shared by the skill and plugin
+// blocks. kind names the entry in attribute descriptions.
+func entryBlock(kind string) schema.NestedBlockObject {
+	return schema.NestedBlockObject{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Name of the %s, unique among the catalog's %s entries.", kind, kind),
+				Required:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("What the %s does.", kind),
+				Optional:            true,
+			},
+			"version": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Version of the %s.", kind),
+				Optional:            true,
+			},
+			"owner": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Team or person that owns the %s.", kind),
+				Optional:            true,
+			},
+			"locations": schema.ListAttribute{
+				MarkdownDescription: fmt.Sprintf("Where the %s is deployed, such as target names or directories.", kind),
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"tags": schema.MapAttribute{
+				MarkdownDescription: fmt.Sprintf("Free-form labels for the %s. Written to `catalog.json` only.", kind),
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"depends_on_skills": schema.ListAttribute{
+				MarkdownDescription: fmt.Sprintf("Skills the %s expects to be deployed alongside it, typically the `depends_on_skills` of an `agentctx_skill`. ", kind) +
+					"Each name must be the name of a `skill` block of the catalog; Anthropic skill IDs refer to the registry and are not checked. Written to `catalog.json` only.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(validators.SkillReference()),
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *CatalogResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *CatalogResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_catalog", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan CatalogResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRunCatalog(ctx, dryrun.OpCreate, &plan, "")...)
+		return
+	}
+
+	resp.Diagnostics.Append(r.writeCatalog(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "created catalog", map[string]interface{}{
+		"name":    plan.Name.ValueString(),
+		"skills":  len(plan.Skills),
+		"plugins": len(plan.Plugins),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *CatalogResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state CatalogResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	s, diags := r.store(state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	contents := make(map[string]string, 2)
+	for _, file := range []string{jsonFile, markdownFile} {
+		data, err := s.read(ctx, file)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, target.ErrNotFound) {
+				resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_catalog", state.ID.ValueString(), fmt.Sprintf("file %q was deleted", s.path(file)))...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				tflog.Info(ctx, "catalog file not found, removing from state", map[string]interface{}{
+					"path": s.path(file),
+				})
+				resp.State.RemoveResource(ctx)
+				return
+			}
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read catalog file %q: %s", s.path(file), err))
+			return
+		}
+		contents[file] = string(data)
+	}
+
+	hash := computeHash(contents[jsonFile], contents[markdownFile])
+	if prior := state.ContentHash.ValueString(); prior != "" && hash != prior {
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_catalog", state.ID.ValueString(), fmt.Sprintf("the catalog files have hash %s, want %s", hash, prior))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	state.CatalogJSON = r.providerData.Content(contents[jsonFile])
+	state.CatalogMarkdown = r.providerData.Content(contents[markdownFile])
+	state.ContentHash = types.StringValue(hash)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *CatalogResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_catalog", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan CatalogResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The location cannot change in place (output_dir, target, and name
+	// force replacement), so rewriting both files is all an update does.
+	if r.providerData.DryRunning() {
+		var state CatalogResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(r.dryRunCatalog(ctx, dryrun.OpUpdate, &plan, state.ID.ValueString())...)
+		return
+	}
+	resp.Diagnostics.Append(r.writeCatalog(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "updated catalog", map[string]interface{}{
+		"name":    plan.Name.ValueString(),
+		"skills":  len(plan.Skills),
+		"plugins": len(plan.Plugins),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+func (r *CatalogResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_catalog", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state CatalogResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if tName := state.Target.ValueString(); tName != "" {
+		if _, ok := r.providerData.Targets.Get(tName); !ok {
+			tflog.Warn(ctx, "target no longer configured, skipping catalog removal", map[string]interface{}{
+				"target": tName,
+			})
+			if r.providerData.DryRunning() {
+				resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+					Resource:  "agentctx_catalog",
+					Operation: dryrun.OpDelete,
+					ID:        state.ID.ValueString(),
+				})...)
+			}
+			return
+		}
+	}
+
+	s, diags := r.store(state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_catalog",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Files:     []dryrun.FileChange{dryrun.RemoveFile(s.path(jsonFile)), dryrun.RemoveFile(s.path(markdownFile))},
+		})...)
+		return
+	}
+
+	for _, file := range []string{jsonFile, markdownFile} {
+		if err := s.remove(ctx, file); err != nil {
+			resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete catalog file %q: %s", s.path(file), err))
+			return
+		}
+	}
+
+	tflog.Info(ctx, "deleted catalog", map[string]interface{}{
+		"name": state.Name.ValueString(),
+	})
+}
+
+// --------------------------------------------------------------------------
+// Rendering
+// --------------------------------------------------------------------------
+
+// catalogDocument is the content of catalog.json.
+type catalogDocument struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Skills      []catalogEntry `json:"skills"`
+	Plugins     []catalogEntry `json:"plugins"`
+}
+
+// catalogEntry is a single skill or plugin in catalog.json.
+type catalogEntry struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Locations   []string          `json:"locations,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+
+	DependsOnSkills []string `json:"depends_on_skills,omitempty"`
+}
+
+// buildDocument converts model into a catalogDocument with entries sorted by
+// name. Entry names must be unique within the skill and plugin blocks, and
+// every skill name an entry depends on must be a skill of the catalog.
+func buildDocument(ctx context.Context, model *CatalogResourceModel) (*catalogDocument, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	skills, d := buildEntries(ctx, "skill", model.Skills)
+	diags.Append(d...)
+	plugins, d := buildEntries(ctx, "plugin", model.Plugins)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	known := make(map[string]bool, len(skills))
+	for _, e := range skills {
+		known[e.Name] = true
+	}
+	diags.Append(checkDependencies(ctx, "skill", model.Skills, known)...)
+	diags.Append(checkDependencies(ctx, "plugin", model.Plugins, known)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &catalogDocument{
+		Name:        model.Name.ValueString(),
+		Description: strings.TrimSpace(model.Description.ValueString()),
+		Skills:      skills,
+		Plugins:     plugins,
+	}, diags
+}
+
+// buildEntries converts the blocks named block into catalog entries sorted
+// by name.
+func buildEntries(ctx context.Context, block string, blocks []CatalogEntryModel) ([]catalogEntry, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	entries := make([]catalogEntry, 0, len(blocks))
+	seen := make(map[string]bool, len(blocks))
+
+	for i, b := range blocks {
+		name := b.Name.ValueString()
+		if seen[name] {
+			diags.AddAttributeError(
+				path.Root(block).AtListIndex(i).AtName("name"),
+				errcode.DuplicateName.Summary("Duplicate Catalog Entry"),
+				fmt.Sprintf("The %s name %q is used by more than one %s block.", block, name, block),
+			)
+			continue
+		}
+		seen[name] = true
+
+		e := catalogEntry{
+			Name:        name,
+			Description: strings.TrimSpace(b.Description.ValueString()),
+			Version:     b.Version.ValueString(),
+			Owner:       b.Owner.ValueString(),
+		}
+		if !b.Locations.IsNull() && !b.Locations.IsUnknown() {
+			diags.Append(b.Locations.ElementsAs(ctx, &e.Locations, false)...)
+		}
+		if !b.Tags.IsNull() && !b.Tags.IsUnknown() {
+			diags.Append(b.Tags.ElementsAs(ctx, &e.Tags, false)...)
+		}
+		if !b.DependsOnSkills.IsNull() && !b.DependsOnSkills.IsUnknown() {
+			diags.Append(b.DependsOnSkills.ElementsAs(ctx, &e.DependsOnSkills, false)...)
+			sort.Strings(e.DependsOnSkills)
+		}
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, diags
+}
+
+// checkDependencies reports the skill names in the depends_on_skills of the
+// blocks named block that are not in known, the names of the catalog's
+// skills. Registry skill IDs are not checked: the skills they refer to are
+// not part of any catalog. A skill that depends on itself is reported too.
+func checkDependencies(ctx context.Context, block string, blocks []CatalogEntryModel, known map[string]bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i, b := range blocks {
+		if b.DependsOnSkills.IsNull() || b.DependsOnSkills.IsUnknown() {
+			continue
+		}
+		var refs []string
+		diags.Append(b.DependsOnSkills.ElementsAs(ctx, &refs, false)...)
+		for j, ref := range refs {
+			attrPath := path.Root(block).AtListIndex(i).AtName("depends_on_skills").AtListIndex(j)
+			switch {
+			case validators.IsSkillID(ref):
+			case block == "skill" && ref == b.Name.ValueString():
+				diags.AddAttributeError(
+					attrPath,
+					errcode.InvalidConfig.Summary("Skill Depends On Itself"),
+					fmt.Sprintf("The skill %q lists itself in depends_on_skills.", ref),
+				)
+			case !known[ref]:
+				diags.AddAttributeError(
+					attrPath,
+					errcode.InvalidConfig.Summary("Dangling Skill Dependency"),
+					fmt.Sprintf("The %s %q depends on the skill %q, which is not a skill block of the catalog. "+
+						"Add the skill to the catalog or remove it from depends_on_skills.", block, b.Name.ValueString(), ref),
+				)
+			}
+		}
+	}
+	return diags
+}
+
+// renderJSON returns the catalog.json content for doc: indented with two
+// spaces and terminated by a newline.
+func renderJSON(doc *catalogDocument) (string, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// renderMarkdown returns the catalog.md content for doc: a heading, the
+// description, and a table each for skills and plugins.
+func renderMarkdown(doc *catalogDocument) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s catalog\n\n", doc.Name)
+	if doc.Description != "" {
+		sb.WriteString(doc.Description)
+		sb.WriteString("\n\n")
+	}
+
+	writeTable(&sb, "Skills", doc.Skills)
+	sb.WriteString("\n")
+	writeTable(&sb, "Plugins", doc.Plugins)
+	return sb.String()
+}
+
+// writeTable writes a Markdown section listing entries under heading.
+func writeTable(sb *strings.Builder, heading string, entries []catalogEntry) {
+	fmt.Fprintf(sb, "## %s\n\n", heading)
+	if len(entries) == 0 {
+		sb.WriteString("None.\n")
+		return
+	}
+
+	sb.WriteString("| Name | Version | Owner | Description | Locations |\n")
+	sb.WriteString("|------|---------|-------|-------------|-----------|\n")
+	for _, e := range entries {
+		locations := make([]string, len(e.Locations))
+		for i, l := range e.Locations {
+			locations[i] = "`" + cell(l) + "`"
+		}
+		fmt.Fprintf(sb, "| `%s` | %s | %s | %s | %s |\n",
+			cell(e.Name), cell(e.Version), cell(e.Owner), cell(e.Description), strings.Join(locations, ", "))
+	}
+}
+
+// cell collapses whitespace in s and escapes pipes so it fits in a single
+// Markdown table cell.
+func cell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", `\|`)
+}
+
+// --------------------------------------------------------------------------
+// Storage
+// --------------------------------------------------------------------------
+
+// catalogStore reads and writes the catalog files of one catalog, either in
+// a local directory or under the catalog's key prefix on a target.
+type catalogStore struct {
+	dir    string        // absolute output_dir; empty when tgt is set
+	tgt    target.Target // nil when dir is set
+	prefix string        // catalogs/<name>/
+}
+
+// store returns the catalogStore for model, resolving output_dir to an
+// absolute path or target to a configured, writable provider target.
+func (r *CatalogResource) store(model CatalogResourceModel) (catalogStore, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	tName := model.Target.ValueString()
+	if tName == "" {
+		dir, err := filepath.Abs(model.OutputDir.ValueString())
+		if err != nil {
+			diags.AddError(errcode.PathResolution.Summary("Invalid Output Directory"), fmt.Sprintf("Failed to resolve output_dir %q: %s", model.OutputDir.ValueString(), err))
+		}
+		return catalogStore{dir: dir}, diags
+	}
+
+	if r.providerData == nil {
+		diags.AddError(
+			errcode.Internal.Summary("Provider Not Configured"),
+			"agentctx_catalog with target requires a configured provider.",
+		)
+		return catalogStore{}, diags
+	}
+	tgt, ok := r.providerData.Targets.Get(tName)
+	if !ok {
+		diags.AddAttributeError(
+			path.Root("target"),
+			errcode.UnknownTarget.Summary("Target Not Found"),
+			fmt.Sprintf("Target %q is not defined in the provider.", tName),
+		)
+		return catalogStore{}, diags
+	}
+	if cfg, _ := r.providerData.Targets.Config(tName); cfg.ReplicaOf.ValueString() != "" {
+		diags.AddAttributeError(
+			path.Root("target"),
+			errcode.InvalidConfig.Summary("Replica Target Not Writable"),
+			fmt.Sprintf("Target %q is a replica of %q and is never written to. Use %q instead.",
+				tName, cfg.ReplicaOf.ValueString(), cfg.ReplicaOf.ValueString()),
+		)
+		return catalogStore{}, diags
+	}
+	return catalogStore{tgt: tgt, prefix: keyPrefix + model.Name.ValueString() + "/"}, diags
+}
+
+// path returns the absolute path or object key of file.
+func (s catalogStore) path(file string) string {
+	if s.tgt != nil {
+		return s.prefix + file
+	}
+	return filepath.Join(s.dir, file)
+}
+
+// write writes content to file.
+func (s catalogStore) write(ctx context.Context, file, content string) error {
+	if s.tgt != nil {
+		return s.tgt.Put(ctx, s.path(file), strings.NewReader(content), target.PutOptions{
+			ContentType: bundle.ContentTypeForFile(file),
+		})
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.path(file), []byte(content), 0o644)
+}
+
+// read returns the content of file. A missing file yields an error matching
+// os.ErrNotExist or target.ErrNotFound.
+func (s catalogStore) read(ctx context.Context, file string) ([]byte, error) {
+	if s.tgt == nil {
+		return os.ReadFile(s.path(file))
+	}
+	rc, _, err := s.tgt.Get(ctx, s.path(file))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, rc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// remove deletes file. A file that no longer exists is not an error.
+func (s catalogStore) remove(ctx context.Context, file string) error {
+	var err error
+	if s.tgt != nil {
+		err = s.tgt.Delete(ctx, s.path(file))
+	} else {
+		err = os.Remove(s.path(file))
+	}
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, target.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// dryRun returns the change to file writing content to it would make.
+func (s catalogStore) dryRun(ctx context.Context, file, content string) dryrun.FileChange {
+	if s.tgt == nil {
+		return dryrun.WriteFile(s.path(file), []byte(content))
+	}
+	existing, err := s.read(ctx, file)
+	return dryrun.CompareFile(s.path(file), []byte(content), existing, err == nil)
+}
+
+// renderCatalog renders the content of catalog.json and catalog.md for
+// model.
+func renderCatalog(ctx context.Context, model *CatalogResourceModel) (*catalogDocument, string, string, diag.Diagnostics) {
+	doc, diags := buildDocument(ctx, model)
+	if diags.HasError() {
+		return doc, "", "", diags
+	}
+	jsonContent, err := renderJSON(doc)
+	if err != nil {
+		diags.AddError(errcode.Encoding.Summary("Catalog Encoding Failed"), fmt.Sprintf("Failed to encode catalog %q: %s", doc.Name, err))
+		return doc, "", "", diags
+	}
+	return doc, jsonContent, renderMarkdown(doc), diags
+}
+
+// dryRunCatalog records the catalog files writeCatalog would write for
+// model in the dry-run report.
+func (r *CatalogResource) dryRunCatalog(ctx context.Context, operation string, model *CatalogResourceModel, id string) diag.Diagnostics {
+	doc, jsonContent, markdownContent, diags := renderCatalog(ctx, model)
+	if diags.HasError() {
+		return diags
+	}
+	s, d := r.store(*model)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+	if id == "" {
+		id = catalogID(*model, s, doc.Name)
+	}
+	diags.Append(r.providerData.RecordDryRun(dryrun.Change{
+		Resource:  "agentctx_catalog",
+		Operation: operation,
+		ID:        id,
+		Files: []dryrun.FileChange{
+			s.dryRun(ctx, jsonFile, jsonContent),
+			s.dryRun(ctx, markdownFile, markdownContent),
+		},
+	})...)
+	return diags
+}
+
+// writeCatalog renders and writes both catalog files, then populates the
+// computed attributes of model.
+func (r *CatalogResource) writeCatalog(ctx context.Context, model *CatalogResourceModel) diag.Diagnostics {
+	doc, jsonContent, markdownContent, diags := renderCatalog(ctx, model)
+	if diags.HasError() {
+		return diags
+	}
+
+	s, d := r.store(*model)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for file, content := range map[string]string{jsonFile: jsonContent, markdownFile: markdownContent} {
+		if err := s.write(ctx, file, content); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write catalog file %q: %s", s.path(file), err))
+			return diags
+		}
+	}
+
+	model.ID = types.StringValue(catalogID(*model, s, doc.Name))
+	model.JSONPath = types.StringValue(s.path(jsonFile))
+	model.MarkdownPath = types.StringValue(s.path(markdownFile))
+	model.CatalogJSON = r.providerData.Content(jsonContent)
+	model.CatalogMarkdown = r.providerData.Content(markdownContent)
+	model.ContentHash = types.StringValue(computeHash(jsonContent, markdownContent))
+
+	return diags
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// computeHash returns the SHA-256 hash over the catalog.json and catalog.md
+// content, prefixed with "sha256:".
+func computeHash(jsonContent, markdownContent string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s", jsonFile, jsonContent, markdownFile, markdownContent)
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
+
+// catalogID returns the ID of the catalog named name stored in s: the
+// target and name, or the path the name would have in output_dir.
+func catalogID(model CatalogResourceModel, s catalogStore, name string) string {
+	if s.tgt != nil {
+		return model.Target.ValueString() + ":" + name
+	}
+	return filepath.Join(s.dir, name)
+}
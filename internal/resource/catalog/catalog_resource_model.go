@@ -0,0 +1,39 @@
+package catalog
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// CatalogResourceModel maps the agentctx_catalog resource schema to a Go
+// struct.
+type CatalogResourceModel struct {
+	// Required
+	Name types.String `tfsdk:"name"`
+
+	// Optional
+	Description types.String `tfsdk:"description"`
+	OutputDir   types.String `tfsdk:"output_dir"` // exactly one of output_dir and target
+	Target      types.String `tfsdk:"target"`
+
+	// Optional – entry blocks
+	Skills  []CatalogEntryModel `tfsdk:"skill"`
+	Plugins []CatalogEntryModel `tfsdk:"plugin"`
+
+	// Computed
+	ID              types.String `tfsdk:"id"`
+	JSONPath        types.String `tfsdk:"json_path"`
+	MarkdownPath    types.String `tfsdk:"markdown_path"`
+	CatalogJSON     types.String `tfsdk:"catalog_json"`
+	CatalogMarkdown types.String `tfsdk:"catalog_markdown"`
+	ContentHash     types.String `tfsdk:"content_hash"`
+}
+
+// CatalogEntryModel maps a skill {} or plugin {} block of agentctx_catalog.
+type CatalogEntryModel struct {
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"` // optional
+	Version     types.String `tfsdk:"version"`     // optional
+	Owner       types.String `tfsdk:"owner"`       // optional
+	Locations   types.List   `tfsdk:"locations"`   // optional list of strings
+	Tags        types.Map    `tfsdk:"tags"`        // optional map of strings
+
+	DependsOnSkills types.List `tfsdk:"depends_on_skills"` // optional list of strings
+}
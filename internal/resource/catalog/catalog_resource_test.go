@@ -0,0 +1,229 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func testEntry(name, description, version string) CatalogEntryModel {
+	return CatalogEntryModel{
+		Name:        types.StringValue(name),
+		Description: types.StringValue(description),
+		Version:     types.StringValue(version),
+		Owner:       types.StringNull(),
+		Locations:   types.ListNull(types.StringType),
+		Tags:        types.MapNull(types.StringType),
+
+		DependsOnSkills: types.ListNull(types.StringType),
+	}
+}
+
+func testCatalog() *CatalogResourceModel {
+	reports := testEntry("reports", "Summarises | reports.", "3")
+	reports.Owner = types.StringValue("data")
+	reports.Locations = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("primary"), types.StringValue("eu")})
+	reports.Tags = types.MapValueMust(types.StringType, map[string]attr.Value{"tier": types.StringValue("gold")})
+
+	return &CatalogResourceModel{
+		Name:        types.StringValue("acme"),
+		Description: types.StringValue("Everything Acme deploys."),
+		OutputDir:   types.StringNull(),
+		Target:      types.StringNull(),
+		Skills: []CatalogEntryModel{
+			reports,
+			testEntry("alerts", "Triage\n  alerts.", ""),
+		},
+	}
+}
+
+func TestBuildDocument_SortsEntries(t *testing.T) {
+	doc, diags := buildDocument(context.Background(), testCatalog())
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if len(doc.Skills) != 2 || doc.Skills[0].Name != "alerts" || doc.Skills[1].Name != "reports" {
+		t.Fatalf("skills not sorted by name: %+v", doc.Skills)
+	}
+	if got := doc.Skills[1].Locations; len(got) != 2 || got[0] != "primary" || got[1] != "eu" {
+		t.Errorf("locations = %v, want declaration order", got)
+	}
+	if doc.Plugins == nil {
+		t.Error("plugins should be an empty list, not null")
+	}
+}
+
+func TestBuildDocument_DuplicateName(t *testing.T) {
+	model := testCatalog()
+	model.Plugins = []CatalogEntryModel{testEntry("tools", "", ""), testEntry("tools", "", "")}
+
+	_, diags := buildDocument(context.Background(), model)
+	if !diags.HasError() {
+		t.Fatal("expected duplicate plugin name error")
+	}
+	if !strings.Contains(diags.Errors()[0].Summary(), string(errcode.DuplicateName)) {
+		t.Errorf("summary = %q, want %s", diags.Errors()[0].Summary(), errcode.DuplicateName)
+	}
+}
+
+func TestBuildDocument_Dependencies(t *testing.T) {
+	deps := func(refs ...string) types.List {
+		values := make([]attr.Value, len(refs))
+		for i, ref := range refs {
+			values[i] = types.StringValue(ref)
+		}
+		return types.ListValueMust(types.StringType, values)
+	}
+
+	model := testCatalog()
+	model.Skills[0].DependsOnSkills = deps("alerts", "skill_01abc")
+	tools := testEntry("tools", "", "")
+	tools.DependsOnSkills = deps("reports", "alerts")
+	model.Plugins = []CatalogEntryModel{tools}
+
+	doc, diags := buildDocument(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if got := doc.Plugins[0].DependsOnSkills; strings.Join(got, ",") != "alerts,reports" {
+		t.Errorf("plugin depends_on_skills = %v, want sorted", got)
+	}
+
+	for name, refs := range map[string][]string{
+		"dangling": {"alerts", "exports"},
+		"self":     {"reports"},
+	} {
+		model := testCatalog()
+		model.Skills[0].DependsOnSkills = deps(refs...)
+		_, diags := buildDocument(context.Background(), model)
+		if !diags.HasError() {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		if n := len(diags.Errors()); n != 1 {
+			t.Errorf("%s: %d errors, want 1: %v", name, n, diags)
+		}
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	doc, _ := buildDocument(context.Background(), testCatalog())
+	out, err := renderJSON(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out, "}\n") {
+		t.Errorf("expected trailing newline, got:\n%s", out)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if plugins, ok := decoded["plugins"].([]interface{}); !ok || len(plugins) != 0 {
+		t.Errorf("plugins = %v, want []", decoded["plugins"])
+	}
+	if !strings.Contains(out, `"tier": "gold"`) {
+		t.Errorf("expected tags in JSON, got:\n%s", out)
+	}
+	if strings.Contains(out, `"version": ""`) {
+		t.Errorf("empty fields should be omitted, got:\n%s", out)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	doc, _ := buildDocument(context.Background(), testCatalog())
+	out := renderMarkdown(doc)
+
+	for _, want := range []string{
+		"# acme catalog\n\nEverything Acme deploys.\n\n",
+		"| `alerts` |  |  | Triage alerts. |  |\n",
+		"| `reports` | 3 | data | Summarises \\| reports. | `primary`, `eu` |\n",
+		"## Plugins\n\nNone.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteCatalog_OutputDir(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "catalog")
+	model := testCatalog()
+	model.OutputDir = types.StringValue(dir)
+
+	r := &CatalogResource{}
+	if diags := r.writeCatalog(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	if model.JSONPath.ValueString() != filepath.Join(dir, jsonFile) {
+		t.Errorf("json_path = %q", model.JSONPath.ValueString())
+	}
+	data, err := os.ReadFile(filepath.Join(dir, markdownFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != model.CatalogMarkdown.ValueString() {
+		t.Error("catalog.md on disk differs from catalog_markdown")
+	}
+	if got := computeHash(model.CatalogJSON.ValueString(), string(data)); got != model.ContentHash.ValueString() {
+		t.Errorf("content_hash = %q, want %q", model.ContentHash.ValueString(), got)
+	}
+}
+
+func TestWriteCatalog_Target(t *testing.T) {
+	ctx := context.Background()
+	tgt := target.NewMemoryTarget("primary")
+	reg := providerdata.NewTargetRegistry()
+	if err := reg.Register("primary", tgt, providerdata.TargetConfigModel{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("replica", target.NewMemoryTarget("replica"), providerdata.TargetConfigModel{ReplicaOf: types.StringValue("primary")}); err != nil {
+		t.Fatal(err)
+	}
+	r := &CatalogResource{providerData: &providerdata.ProviderData{Targets: reg}}
+
+	model := testCatalog()
+	model.Target = types.StringValue("primary")
+	if diags := r.writeCatalog(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if model.ID.ValueString() != "primary:acme" || model.JSONPath.ValueString() != "catalogs/acme/catalog.json" {
+		t.Errorf("id = %q, json_path = %q", model.ID.ValueString(), model.JSONPath.ValueString())
+	}
+
+	s, _ := r.store(*model)
+	data, err := s.read(ctx, jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != model.CatalogJSON.ValueString() {
+		t.Error("catalog.json on target differs from catalog_json")
+	}
+	for _, file := range []string{jsonFile, markdownFile} {
+		if err := s.remove(ctx, file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.read(ctx, markdownFile); err == nil {
+		t.Error("catalog.md still readable after remove")
+	}
+
+	model.Target = types.StringValue("replica")
+	if diags := r.writeCatalog(ctx, model); !diags.HasError() {
+		t.Error("expected error writing to a replica target")
+	}
+}
@@ -0,0 +1,477 @@
+package command
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"gopkg.in/yaml.v3"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource               = &CommandResource{}
+	_ resource.ResourceWithConfigure  = &CommandResource{}
+	_ resource.ResourceWithModifyPlan = &CommandResource{}
+)
+
+// NewCommandResource returns a new resource.Resource for the
+// agentctx_command type.
+func NewCommandResource() resource.Resource {
+	return &CommandResource{}
+}
+
+// CommandResource implements the agentctx_command Terraform resource. It
+// generates a Claude Code slash command markdown file (YAML frontmatter +
+// body) outside any plugin, such as in .claude/commands, or as the
+// source_file of a command block of agentctx_plugin.
+type CommandResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *CommandResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_command"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *CommandResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a Claude Code slash command file. Generates `<output_dir>/<name>.md` with YAML frontmatter and a Markdown body, for project commands in `.claude/commands` or as the `source_file` of a `command` block of `agentctx_plugin`.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Command name, invoked as `/<name>` and used as the file name `<name>.md`. Must use lowercase letters, numbers, and hyphens (e.g. `fix-issue`). Changing this forces a new resource to be created.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					validators.Name(),
+				},
+			},
+			"output_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory where the command file will be written, typically `.claude/commands` for project commands. Changing this forces a new resource to be created.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"body": schema.StringAttribute{
+				MarkdownDescription: "The prompt of the command, written as the Markdown body after the frontmatter. Use `$ARGUMENTS`, or `$1`, `$2`, and so on, for the arguments the command is invoked with.",
+				Required:            true,
+			},
+
+			// ---- Optional – frontmatter ----
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Brief description of the command, shown in the `/help` listing. Written as `description`.",
+				Optional:            true,
+			},
+			"argument_hint": schema.StringAttribute{
+				MarkdownDescription: "Arguments the command expects, shown when completing it, e.g. `[issue-number] [priority]`. Written as `argument-hint`.",
+				Optional:            true,
+			},
+			"allowed_tools": schema.ListAttribute{
+				MarkdownDescription: "Tools the command may use without asking for permission, e.g. `Bash(git status:*)`. Written as a comma-separated `allowed-tools`. Inherits the conversation's permissions if omitted.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"model": schema.StringAttribute{
+				MarkdownDescription: "Model the command runs with, such as `claude-haiku-4-5`. Uses the conversation's model if omitted.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+
+			// ---- Optional ----
+			"content_storage": schema.StringAttribute{
+				MarkdownDescription: "What is kept in state for the rendered file: `\"full\"` stores it in `content`; `\"hash_only\"` stores `content` as null and keeps only `content_hash`, which refresh recomputes from the file on disk. Overrides the provider's `state_content` for this resource; unset follows it.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("full", "hash_only"),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of the command file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"file_path": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of the command file. Pass it to `source_file` of a `command` block of `agentctx_plugin` to bundle the command in a plugin.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "The rendered Markdown content of the command file. Null when `content_storage` is `\"hash_only\"`, or when it is unset and the provider's `state_content` is `\"hashes\"`.",
+				Computed:            true,
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of the command file content, prefixed with `sha256:`. Refresh recomputes it from the file on disk, so an edited file plans an update that writes the configured content again.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *CommandResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// ModifyPlan
+// --------------------------------------------------------------------------
+
+// ModifyPlan sets the path, content, and hash the apply will write once the
+// configuration is known, so file_path can be passed to other resources at
+// plan time. Read records the hash of the file on disk, so a file edited
+// outside Terraform differs from the planned hash and plans an update.
+func (r *CommandResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan CommandResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	filePath, err := outputFilePath(&plan)
+	if err != nil {
+		// Create reports the error.
+		return
+	}
+	content, diags := Render(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(filePath)
+	plan.FilePath = types.StringValue(filePath)
+	plan.Content = r.providerData.ContentFor(plan.ContentStorage, content)
+	plan.ContentHash = types.StringValue(computeHash(content))
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *CommandResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_command", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan CommandResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &plan, dryrun.OpCreate)...)
+	if resp.Diagnostics.HasError() || r.providerData.DryRunning() {
+		return
+	}
+
+	tflog.Info(ctx, "created command file", map[string]interface{}{
+		"name":      plan.Name.ValueString(),
+		"file_path": plan.FilePath.ValueString(),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *CommandResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state CommandResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.FilePath.ValueString()
+
+	data, err := os.ReadFile(longpath.Path(filePath))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read command file %q: %s", filePath, err))
+			return
+		}
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_command", state.ID.ValueString(), fmt.Sprintf("file %q was deleted", filePath))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		tflog.Info(ctx, "command file not found on disk, removing from state", map[string]interface{}{
+			"file_path": filePath,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	diskContent := string(data)
+	diskHash := computeHash(diskContent)
+	if prior := state.ContentHash.ValueString(); prior != "" && diskHash != prior {
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_command", state.ID.ValueString(), fmt.Sprintf("file %q has hash %s, want %s", filePath, diskHash, prior))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	state.Content = r.providerData.ContentFor(state.ContentStorage, diskContent)
+	state.ContentHash = types.StringValue(diskHash)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *CommandResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_command", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan CommandResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &plan, dryrun.OpUpdate)...)
+	if resp.Diagnostics.HasError() || r.providerData.DryRunning() {
+		return
+	}
+
+	tflog.Info(ctx, "updated command file", map[string]interface{}{
+		"name":      plan.Name.ValueString(),
+		"file_path": plan.FilePath.ValueString(),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+func (r *CommandResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_command", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state CommandResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.FilePath.ValueString()
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_command",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Files:     []dryrun.FileChange{dryrun.RemoveFile(filePath)},
+		})...)
+		return
+	}
+
+	if err := os.Remove(longpath.Path(filePath)); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete command file %q: %s", filePath, err))
+		return
+	}
+
+	tflog.Info(ctx, "deleted command file", map[string]interface{}{
+		"name":      state.Name.ValueString(),
+		"file_path": filePath,
+	})
+}
+
+// --------------------------------------------------------------------------
+// Rendering
+// --------------------------------------------------------------------------
+
+// Frontmatter represents the YAML frontmatter of a command markdown file.
+// Field names use yaml tags matching the Claude Code slash command
+// specification. It is shared with the command blocks of agentctx_plugin.
+type Frontmatter struct {
+	Description            string `yaml:"description,omitempty"`
+	ArgumentHint           string `yaml:"argument-hint,omitempty"`
+	AllowedTools           string `yaml:"allowed-tools,omitempty"`
+	Model                  string `yaml:"model,omitempty"`
+	DisableModelInvocation bool   `yaml:"disable-model-invocation,omitempty"`
+}
+
+// Render returns the Markdown content of the command described by model.
+// See RenderFile.
+func Render(ctx context.Context, model *CommandResourceModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	fm := Frontmatter{
+		Description:  model.Description.ValueString(),
+		ArgumentHint: model.ArgumentHint.ValueString(),
+		Model:        model.Model.ValueString(),
+	}
+
+	// AllowedTools – comma-separated string
+	if !model.AllowedTools.IsNull() && !model.AllowedTools.IsUnknown() {
+		var tools []string
+		diags.Append(model.AllowedTools.ElementsAs(ctx, &tools, false)...)
+		if diags.HasError() {
+			return "", diags
+		}
+		fm.AllowedTools = strings.Join(tools, ", ")
+	}
+
+	content, err := RenderFile(fm, model.Body.ValueString())
+	if err != nil {
+		diags.AddError(errcode.Encoding.Summary("YAML Marshal Failed"), fmt.Sprintf("Failed to marshal command frontmatter: %s", err))
+		return "", diags
+	}
+	return content, diags
+}
+
+// RenderFile returns a command file of fm followed by body, trimmed of
+// leading and trailing whitespace. The frontmatter is left out when fm is
+// empty.
+func RenderFile(fm Frontmatter, body string) (string, error) {
+	var sb strings.Builder
+	if fm != (Frontmatter{}) {
+		yamlBytes, err := yaml.Marshal(&fm)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("---\n")
+		sb.Write(yamlBytes)
+		sb.WriteString("---\n\n")
+	}
+	sb.WriteString(strings.TrimSpace(body))
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
+
+// --------------------------------------------------------------------------
+// File operations
+// --------------------------------------------------------------------------
+
+// write renders model and writes it to its command file, or records the
+// write in the dry-run report, and sets the computed attributes.
+func (r *CommandResource) write(ctx context.Context, model *CommandResourceModel, operation string) diag.Diagnostics {
+	content, diags := Render(ctx, model)
+	if diags.HasError() {
+		return diags
+	}
+	filePath, err := outputFilePath(model)
+	if err != nil {
+		diags.AddError(errcode.PathResolution.Summary("Path Resolution Failed"), err.Error())
+		return diags
+	}
+
+	if r.providerData.DryRunning() {
+		diags.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_command",
+			Operation: operation,
+			ID:        filePath,
+			Files:     []dryrun.FileChange{dryrun.WriteFile(filePath, []byte(content))},
+		})...)
+		return diags
+	}
+
+	// Long paths and UNC shares need the extended-length form on Windows.
+	if err := os.MkdirAll(longpath.Path(filepath.Dir(filePath)), 0o755); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to create output directory %q: %s", model.OutputDir.ValueString(), err))
+		return diags
+	}
+	if err := atomicfile.WriteFile(longpath.Path(filePath), []byte(content), 0o644); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write command file %q: %s", filePath, err))
+		return diags
+	}
+
+	model.ID = types.StringValue(filePath)
+	model.FilePath = types.StringValue(filePath)
+	model.Content = r.providerData.ContentFor(model.ContentStorage, content)
+	model.ContentHash = types.StringValue(computeHash(content))
+	return diags
+}
+
+// outputFilePath returns the absolute path of the command file of model.
+func outputFilePath(model *CommandResourceModel) (string, error) {
+	filePath := filepath.Join(model.OutputDir.ValueString(), model.Name.ValueString()+".md")
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("resolving absolute path for %q: %w", filePath, err)
+	}
+	return absPath, nil
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// computeHash returns the SHA-256 hash of the given content, prefixed with
+// "sha256:" to match the convention used elsewhere in the provider.
+func computeHash(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return fmt.Sprintf("sha256:%x", h)
+}
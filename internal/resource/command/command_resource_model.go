@@ -0,0 +1,27 @@
+package command
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// CommandResourceModel maps the agentctx_command resource schema to a Go
+// struct.
+type CommandResourceModel struct {
+	// Required
+	Name      types.String `tfsdk:"name"`
+	OutputDir types.String `tfsdk:"output_dir"`
+	Body      types.String `tfsdk:"body"`
+
+	// Optional – frontmatter
+	Description  types.String `tfsdk:"description"`
+	ArgumentHint types.String `tfsdk:"argument_hint"`
+	AllowedTools types.List   `tfsdk:"allowed_tools"`
+	Model        types.String `tfsdk:"model"`
+
+	// Optional
+	ContentStorage types.String `tfsdk:"content_storage"`
+
+	// Computed
+	ID          types.String `tfsdk:"id"`
+	FilePath    types.String `tfsdk:"file_path"`
+	Content     types.String `tfsdk:"content"`
+	ContentHash types.String `tfsdk:"content_hash"`
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		model CommandResourceModel
+		want  string
+	}{
+		{
+			name: "body only",
+			model: CommandResourceModel{
+				Body:         types.StringValue("\nReview the staged changes.\n\n"),
+				AllowedTools: types.ListNull(types.StringType),
+			},
+			want: "Review the staged changes.\n",
+		},
+		{
+			name: "all frontmatter",
+			model: CommandResourceModel{
+				Body:         types.StringValue("Fix issue #$1 with priority $2."),
+				Description:  types.StringValue("Fix a GitHub issue"),
+				ArgumentHint: types.StringValue("[issue-number] [priority]"),
+				AllowedTools: types.ListValueMust(types.StringType, []attr.Value{
+					types.StringValue("Bash(git status:*)"),
+					types.StringValue("Bash(gh issue view:*)"),
+				}),
+				Model: types.StringValue("claude-haiku-4-5"),
+			},
+			want: "---\n" +
+				"description: Fix a GitHub issue\n" +
+				"argument-hint: '[issue-number] [priority]'\n" +
+				"allowed-tools: Bash(git status:*), Bash(gh issue view:*)\n" +
+				"model: claude-haiku-4-5\n" +
+				"---\n\n" +
+				"Fix issue #$1 with priority $2.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags := Render(context.Background(), &tt.model)
+			if diags.HasError() {
+				t.Fatalf("Render: %v", diags)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputFilePath(t *testing.T) {
+	dir := t.TempDir()
+	got, err := outputFilePath(&CommandResourceModel{
+		Name:      types.StringValue("fix-issue"),
+		OutputDir: types.StringValue(dir),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "fix-issue.md"); got != want {
+		t.Errorf("outputFilePath = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,517 @@
+package jsonfragment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/jsonmerge"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource                   = &JSONFragmentResource{}
+	_ resource.ResourceWithConfigure      = &JSONFragmentResource{}
+	_ resource.ResourceWithValidateConfig = &JSONFragmentResource{}
+)
+
+// NewJSONFragmentResource returns a new resource.Resource for the
+// agentctx_json_fragment type.
+func NewJSONFragmentResource() resource.Resource {
+	return &JSONFragmentResource{}
+}
+
+// JSONFragmentResource implements the agentctx_json_fragment Terraform
+// resource. It manages individual values, addressed by JSON path, inside a
+// JSON file that is otherwise owned by something else.
+type JSONFragmentResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *JSONFragmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_json_fragment"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *JSONFragmentResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages specific values inside a JSON file, addressed by JSON path (e.g. `$.enabledPlugins` in `settings.json`). Everything outside the managed paths is left untouched and drift detection is scoped to those paths.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Path of the JSON file. The file (containing `{}`) and its parent directories are created if they do not exist.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"values": schema.MapAttribute{
+				MarkdownDescription: "Managed values, keyed by JSON path (`$.a.b` or `$.a[\"key.with.dots\"]`). Each value is JSON-encoded, typically with `jsonencode()`. Paths may not overlap.",
+				Required:            true,
+				ElementType:         types.StringType,
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of the JSON file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of the file content after the last apply or refresh.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// ValidateConfig
+// --------------------------------------------------------------------------
+
+// ValidateConfig checks that every key is a supported JSON path, that every
+// value is valid JSON, and that no managed path contains another.
+func (r *JSONFragmentResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var values types.Map
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("values"), &values)...)
+	if resp.Diagnostics.HasError() || values.IsNull() || values.IsUnknown() {
+		return
+	}
+
+	// Unknown elements are skipped; they are validated again at apply time.
+	known := make(map[string]string, len(values.Elements()))
+	for k, v := range values.Elements() {
+		s, ok := v.(types.String)
+		if !ok || s.IsUnknown() || s.IsNull() {
+			continue
+		}
+		known[k] = s.ValueString()
+	}
+
+	if _, err := parseFragments(known); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("values"), errcode.InvalidJSON.Summary("Invalid JSON Fragment"), err.Error())
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *JSONFragmentResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *JSONFragmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_json_fragment", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan JSONFragmentResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, dryrun.OpCreate, &plan, nil)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "wrote JSON fragment", map[string]interface{}{
+		"file_path": plan.ID.ValueString(),
+		"paths":     len(plan.Values.Elements()),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *JSONFragmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state JSONFragmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.ID.ValueString()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_json_fragment", filePath, fmt.Sprintf("file %q was deleted", filePath))...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			tflog.Info(ctx, "JSON file not found on disk, removing from state", map[string]interface{}{
+				"file_path": filePath,
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read JSON file %q: %s", filePath, err))
+		return
+	}
+
+	doc, err := jsonmerge.DecodeObject(data)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidJSON.Summary("Invalid JSON File"), fmt.Sprintf("File %q is not a JSON object: %s", filePath, err))
+		return
+	}
+
+	values := make(map[string]string)
+	resp.Diagnostics.Append(state.Values.ElementsAs(ctx, &values, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	refreshed, err := refreshValues(doc, values)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read managed values from %q: %s", filePath, err))
+		return
+	}
+
+	var drifted []string
+	for k, v := range values {
+		if got, ok := refreshed[k]; !ok || got != v {
+			drifted = append(drifted, k)
+		}
+	}
+	if len(drifted) > 0 {
+		sort.Strings(drifted)
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_json_fragment", filePath, fmt.Sprintf("the values at %s in %q changed", strings.Join(drifted, ", "), filePath))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	valuesMap, diags := types.MapValueFrom(ctx, types.StringType, refreshed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Values = valuesMap
+	state.ContentHash = types.StringValue(computeHash(data))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *JSONFragmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_json_fragment", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state JSONFragmentResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.apply(ctx, dryrun.OpUpdate, &plan, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "updated JSON fragment", map[string]interface{}{
+		"file_path": plan.ID.ValueString(),
+		"paths":     len(plan.Values.Elements()),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+// Delete removes the managed paths from the file. The file itself is kept,
+// since it is assumed to be owned by something else.
+func (r *JSONFragmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_json_fragment", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state JSONFragmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.ID.ValueString()
+
+	values := make(map[string]string)
+	resp.Diagnostics.Append(state.Values.ElementsAs(ctx, &values, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	doc, exists, err := jsonmerge.ReadObjectFile(filePath)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), err.Error())
+		return
+	}
+	if !exists {
+		return
+	}
+
+	for _, p := range managedPaths(values) {
+		jsonmerge.Delete(doc, p)
+	}
+	if r.providerData.DryRunning() {
+		content, err := jsonmerge.Encode(doc)
+		if err != nil {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to encode %q: %s", filePath, err))
+			return
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_json_fragment",
+			Operation: dryrun.OpDelete,
+			ID:        filePath,
+			Files:     []dryrun.FileChange{dryrun.WriteFile(filePath, content)},
+		})...)
+		return
+	}
+	if _, err := jsonmerge.WriteObjectFile(filePath, doc); err != nil {
+		resp.Diagnostics.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to remove managed values: %s", err))
+		return
+	}
+
+	tflog.Info(ctx, "removed JSON fragment", map[string]interface{}{
+		"file_path": filePath,
+	})
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// fragment is one parsed entry of the values map.
+type fragment struct {
+	key   string
+	path  jsonmerge.Path
+	value any
+}
+
+// parseFragments parses the values map, sorted by key, and rejects entries
+// whose paths overlap, since the outcome of writing both would depend on
+// the order they are applied in.
+func parseFragments(values map[string]string) ([]fragment, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fragments := make([]fragment, 0, len(keys))
+	for _, k := range keys {
+		p, err := jsonmerge.ParsePath(k)
+		if err != nil {
+			return nil, err
+		}
+		var v any
+		if err := json.Unmarshal([]byte(values[k]), &v); err != nil {
+			return nil, fmt.Errorf("value for %q is not valid JSON: %w", k, err)
+		}
+		for _, other := range fragments {
+			if p.HasPrefix(other.path) || other.path.HasPrefix(p) {
+				return nil, fmt.Errorf("paths %q and %q overlap", other.key, k)
+			}
+		}
+		fragments = append(fragments, fragment{key: k, path: p, value: v})
+	}
+	return fragments, nil
+}
+
+// managedPaths returns the parsed paths of the values map, skipping any that
+// no longer parse.
+func managedPaths(values map[string]string) []jsonmerge.Path {
+	paths := make([]jsonmerge.Path, 0, len(values))
+	for k := range values {
+		if p, err := jsonmerge.ParsePath(k); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// apply writes the planned values into the file. When prior is non-nil,
+// paths it managed that are no longer planned are deleted first. During a
+// dry run, the change is recorded as operation instead.
+func (r *JSONFragmentResource) apply(ctx context.Context, operation string, plan, prior *JSONFragmentResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	values := make(map[string]string)
+	diags.Append(plan.Values.ElementsAs(ctx, &values, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	fragments, err := parseFragments(values)
+	if err != nil {
+		diags.AddAttributeError(path.Root("values"), errcode.InvalidJSON.Summary("Invalid JSON Fragment"), err.Error())
+		return diags
+	}
+
+	var removed []jsonmerge.Path
+	if prior != nil {
+		priorValues := make(map[string]string)
+		diags.Append(prior.Values.ElementsAs(ctx, &priorValues, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		for k := range values {
+			delete(priorValues, k)
+		}
+		removed = managedPaths(priorValues)
+	}
+
+	absPath, err := filepath.Abs(plan.Path.ValueString())
+	if err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to resolve absolute path for %q: %s", plan.Path.ValueString(), err))
+		return diags
+	}
+
+	if r.providerData.DryRunning() {
+		doc, err := mergeFragments(absPath, fragments, removed)
+		var content []byte
+		if err == nil {
+			content, err = jsonmerge.Encode(doc)
+		}
+		if err != nil {
+			diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to compute JSON fragment: %s", err))
+			return diags
+		}
+		return r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_json_fragment",
+			Operation: operation,
+			ID:        absPath,
+			Files:     []dryrun.FileChange{dryrun.WriteFile(absPath, content)},
+		})
+	}
+
+	content, err := writeFragments(absPath, fragments, removed)
+	if err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write JSON fragment: %s", err))
+		return diags
+	}
+
+	plan.ID = types.StringValue(absPath)
+	plan.ContentHash = types.StringValue(computeHash(content))
+	return diags
+}
+
+// writeFragments deletes removed and sets every fragment in the JSON file at
+// filePath, returning the bytes written.
+func writeFragments(filePath string, fragments []fragment, removed []jsonmerge.Path) ([]byte, error) {
+	doc, err := mergeFragments(filePath, fragments, removed)
+	if err != nil {
+		return nil, err
+	}
+	return jsonmerge.WriteObjectFile(filePath, doc)
+}
+
+// mergeFragments returns the JSON document at filePath with removed deleted
+// and every fragment set.
+func mergeFragments(filePath string, fragments []fragment, removed []jsonmerge.Path) (map[string]any, error) {
+	doc, _, err := jsonmerge.ReadObjectFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range removed {
+		jsonmerge.Delete(doc, p)
+	}
+	for _, f := range fragments {
+		if err := jsonmerge.Set(doc, f.path, f.value); err != nil {
+			return nil, fmt.Errorf("setting %q: %w", f.key, err)
+		}
+	}
+	return doc, nil
+}
+
+// refreshValues returns values updated from doc. Values that still match
+// semantically keep their configured encoding so the plan stays empty;
+// changed values are re-encoded from the file and missing paths are dropped,
+// both of which surface as a diff on the next plan.
+func refreshValues(doc map[string]any, values map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(values))
+	for k, encoded := range values {
+		p, err := jsonmerge.ParsePath(k)
+		if err != nil {
+			return nil, err
+		}
+		actual, ok := jsonmerge.Get(doc, p)
+		if !ok {
+			continue
+		}
+		var want any
+		if err := json.Unmarshal([]byte(encoded), &want); err == nil && jsonmerge.Equal(actual, want) {
+			out[k] = encoded
+			continue
+		}
+		data, err := json.Marshal(actual)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = string(data)
+	}
+	return out, nil
+}
+
+func computeHash(content []byte) string {
+	h := sha256.Sum256(content)
+	return fmt.Sprintf("sha256:%x", h)
+}
@@ -0,0 +1,15 @@
+package jsonfragment
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// JSONFragmentResourceModel maps the agentctx_json_fragment resource schema
+// to a Go struct.
+type JSONFragmentResourceModel struct {
+	// Required
+	Path   types.String `tfsdk:"path"`
+	Values types.Map    `tfsdk:"values"` // JSON path -> JSON-encoded value
+
+	// Computed
+	ID          types.String `tfsdk:"id"`
+	ContentHash types.String `tfsdk:"content_hash"`
+}
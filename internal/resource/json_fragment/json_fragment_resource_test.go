@@ -0,0 +1,96 @@
+package jsonfragment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/jsonmerge"
+)
+
+func TestParseFragments(t *testing.T) {
+	fragments, err := parseFragments(map[string]string{
+		"$.permissions.allow": `["Read"]`,
+		"enabledPlugins":      `{"fmt@tools": true}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fragments) != 2 || fragments[0].key != "$.permissions.allow" {
+		t.Errorf("unexpected fragments: %+v", fragments)
+	}
+
+	cases := map[string]map[string]string{
+		"overlap":      {"$.permissions": `{}`, "$.permissions.allow": `[]`},
+		"invalid json": {"$.model": `opus`},
+		"invalid path": {"$.a[0]": `1`},
+	}
+	for name, values := range cases {
+		if _, err := parseFragments(values); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestWriteFragments(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(filePath, []byte(`{"theme": "dark", "enabledPlugins": {"old@tools": true}, "model": "opus"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fragments, err := parseFragments(map[string]string{
+		`$.enabledPlugins["fmt@tools"]`: `true`,
+		"$.permissions.allow":           `["Read"]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeFragments(filePath, fragments, []jsonmerge.Path{{"model"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _, err := jsonmerge.ReadObjectFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := jsonmerge.DecodeObject([]byte(`{
+		"theme": "dark",
+		"enabledPlugins": {"old@tools": true, "fmt@tools": true},
+		"permissions": {"allow": ["Read"]}
+	}`))
+	if !jsonmerge.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	bad, _ := parseFragments(map[string]string{"$.theme.x": `1`})
+	if _, err := writeFragments(filePath, bad, nil); err == nil || !strings.Contains(err.Error(), "not an object") {
+		t.Errorf("expected error writing through a scalar, got %v", err)
+	}
+}
+
+func TestRefreshValues(t *testing.T) {
+	doc, _ := jsonmerge.DecodeObject([]byte(`{"enabledPlugins": {"fmt@tools": false}, "model": "opus", "theme": "dark"}`))
+	values := map[string]string{
+		"$.model":          `"opus"`,
+		"$.enabledPlugins": "{\n  \"fmt@tools\": true\n}",
+		"$.cleanupPeriod":  `30`,
+	}
+
+	got, err := refreshValues(doc, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["$.model"] != `"opus"` {
+		t.Errorf("unchanged value should keep its encoding, got %q", got["$.model"])
+	}
+	if got["$.enabledPlugins"] != `{"fmt@tools":false}` {
+		t.Errorf("drifted value = %q", got["$.enabledPlugins"])
+	}
+	if _, ok := got["$.cleanupPeriod"]; ok {
+		t.Error("missing path should be dropped")
+	}
+	if _, ok := got["$.theme"]; ok {
+		t.Error("unmanaged path should not be reported")
+	}
+}
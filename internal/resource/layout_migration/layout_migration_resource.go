@@ -0,0 +1,389 @@
+package layoutmigration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource              = &LayoutMigrationResource{}
+	_ resource.ResourceWithConfigure = &LayoutMigrationResource{}
+)
+
+// NewLayoutMigrationResource returns a new resource.Resource for the
+// agentctx_layout_migration type.
+func NewLayoutMigrationResource() resource.Resource {
+	return &LayoutMigrationResource{}
+}
+
+// LayoutMigrationResource implements the agentctx_layout_migration
+// Terraform resource. It brings the skills on a target written by older
+// provider versions to the current storage layout when created, or with
+// dry_run only reports what it would change. Destroying it changes nothing
+// on the target.
+type LayoutMigrationResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// layoutChangeAttrTypes returns the attribute type map for each element of
+// the changes list.
+func layoutChangeAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"skill_name":    types.StringType,
+		"deployment_id": types.StringType,
+		"kind":          types.StringType,
+		"key":           types.StringType,
+	}
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *LayoutMigrationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_layout_migration"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *LayoutMigrationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Migrates the skills on a storage target written by older provider versions to the current storage layout. " +
+			"The migration runs when the resource is created; with `dry_run = true` it only reports what it would change. " +
+			"Changing any argument forces recreation, which runs the migration again.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"target": schema.StringAttribute{
+				MarkdownDescription: "Name of the provider target to migrate.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+
+			// ---- Optional ----
+			"skill_names": schema.ListAttribute{
+				MarkdownDescription: "Storage names of the skills to migrate. Defaults to every skill on the target.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"dry_run": schema.BoolAttribute{
+				MarkdownDescription: "Only detect the objects in an older layout and report them in `changes`, without writing anything. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource identifier, the target name.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"changes": schema.ListNestedAttribute{
+				MarkdownDescription: "Objects found in an older layout when the resource was created, migrated unless `dry_run` is set.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"skill_name": schema.StringAttribute{
+							MarkdownDescription: "Storage name of the skill.",
+							Computed:            true,
+						},
+						"deployment_id": schema.StringAttribute{
+							MarkdownDescription: "Deployment the object belongs to. Empty for objects of the skill as a whole.",
+							Computed:            true,
+						},
+						"kind": schema.StringAttribute{
+							MarkdownDescription: "What is migrated: `manifest_schema` or `missing_index`.",
+							Computed:            true,
+						},
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Object key the migration writes.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *LayoutMigrationResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *LayoutMigrationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_layout_migration", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan LayoutMigrationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tName := plan.Target.ValueString()
+	if r.providerData.DryRunning() {
+		changes, diags := r.migrate(ctx, plan, true)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// With dry_run set, the resource itself writes nothing either.
+		change := dryrun.TargetChange{Target: tName}
+		for _, c := range changes {
+			if !plan.DryRun.ValueBool() {
+				change.Rewrites = append(change.Rewrites, c.Key)
+			}
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_layout_migration",
+			Operation: dryrun.OpCreate,
+			ID:        tName,
+			Targets:   []dryrun.TargetChange{change},
+		})...)
+		return
+	}
+
+	changes, diags := r.migrate(ctx, plan, plan.DryRun.ValueBool())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(tName)
+	changeList, diags := changeValues(ctx, changes)
+	resp.Diagnostics.Append(diags...)
+	plan.Changes = changeList
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+// Read keeps the state as it is: changes records what the migration found
+// when it ran, not the current layout of the target.
+func (r *LayoutMigrationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state LayoutMigrationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *LayoutMigrationResource) Update(_ context.Context, _ resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.AddError(
+		errcode.Internal.Summary("Update Not Supported"),
+		"agentctx_layout_migration does not support in-place updates. Changes to any argument force replacement.",
+	)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+// Delete removes the resource from state only; a migration is not undone.
+func (r *LayoutMigrationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_layout_migration", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state LayoutMigrationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_layout_migration",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+		})...)
+	}
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// migrate migrates the skills of model on its target, or with dryRun only
+// plans their migration, and returns the changes in skill order.
+func (r *LayoutMigrationResource) migrate(ctx context.Context, model LayoutMigrationResourceModel, dryRun bool) ([]engine.LayoutChange, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	tgt, d := r.lookupTarget(model.Target.ValueString())
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	var skillNames []string
+	if model.SkillNames.IsNull() {
+		names, err := eng.LayoutSkills(ctx, tgt)
+		if err != nil {
+			diags.AddError(
+				errcode.RefreshFailed.Summary("Layout Detection Failed"),
+				fmt.Sprintf("Failed to list the skills on target %q: %s", tgt.Name(), err),
+			)
+			return nil, diags
+		}
+		skillNames = names
+	} else {
+		diags.Append(model.SkillNames.ElementsAs(ctx, &skillNames, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	var changes []engine.LayoutChange
+	for _, skillName := range skillNames {
+		if dryRun {
+			planned, err := eng.PlanLayoutMigration(ctx, tgt, skillName)
+			if err != nil {
+				diags.AddError(
+					errcode.RefreshFailed.Summary("Layout Detection Failed"),
+					fmt.Sprintf("Failed to read the layout of skill %q on target %q: %s", skillName, tgt.Name(), err),
+				)
+				return nil, diags
+			}
+			changes = append(changes, planned...)
+			continue
+		}
+
+		migrated, err := eng.MigrateLayout(ctx, tgt, skillName)
+		for _, c := range migrated {
+			tflog.Info(ctx, "migrated storage layout", map[string]interface{}{
+				"target":     tgt.Name(),
+				"skill_name": skillName,
+				"kind":       c.Kind,
+				"key":        c.Key,
+			})
+		}
+		if err != nil {
+			diags.AddError(
+				errcode.DeployFailed.Summary("Layout Migration Failed"),
+				fmt.Sprintf("Failed to migrate skill %q on target %q: %s\n\n"+
+					"Objects migrated before the failure keep the current layout. Applying again resumes the migration.", skillName, tgt.Name(), err),
+			)
+			return nil, diags
+		}
+		changes = append(changes, migrated...)
+	}
+	return changes, diags
+}
+
+// lookupTarget resolves tName against the provider's target registry.
+// Replica targets are rejected: they receive the layout of their primary.
+func (r *LayoutMigrationResource) lookupTarget(tName string) (target.Target, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if r.providerData == nil {
+		diags.AddError(
+			errcode.Internal.Summary("Provider Not Configured"),
+			"agentctx_layout_migration requires a configured provider.",
+		)
+		return nil, diags
+	}
+
+	tgt, ok := r.providerData.Targets.Get(tName)
+	if !ok {
+		diags.AddError(
+			errcode.UnknownTarget.Summary("Target Not Found"),
+			fmt.Sprintf("Target %q is not defined in the provider.", tName),
+		)
+		return nil, diags
+	}
+	if cfg, _ := r.providerData.Targets.Config(tName); cfg.ReplicaOf.ValueString() != "" {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Replica Target Not Writable"),
+			fmt.Sprintf("Target %q is a replica of %q and is never written to. Migrate %q instead.",
+				tName, cfg.ReplicaOf.ValueString(), cfg.ReplicaOf.ValueString()),
+		)
+		return nil, diags
+	}
+	return tgt, diags
+}
+
+// changeValues converts changes to the value of the changes attribute.
+func changeValues(ctx context.Context, changes []engine.LayoutChange) (types.List, diag.Diagnostics) {
+	values := make([]LayoutChangeValue, 0, len(changes))
+	for _, c := range changes {
+		values = append(values, LayoutChangeValue{
+			SkillName:    types.StringValue(c.SkillName),
+			DeploymentID: types.StringValue(c.DeploymentID),
+			Kind:         types.StringValue(c.Kind),
+			Key:          types.StringValue(c.Key),
+		})
+	}
+	return types.ListValueFrom(ctx, types.ObjectType{AttrTypes: layoutChangeAttrTypes()}, values)
+}
@@ -0,0 +1,26 @@
+package layoutmigration
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// LayoutMigrationResourceModel maps the agentctx_layout_migration resource
+// schema to a Go struct.
+type LayoutMigrationResourceModel struct {
+	// Required
+	Target types.String `tfsdk:"target"`
+
+	// Optional
+	SkillNames types.List `tfsdk:"skill_names"` // list of strings
+	DryRun     types.Bool `tfsdk:"dry_run"`
+
+	// Computed
+	ID      types.String `tfsdk:"id"`
+	Changes types.List   `tfsdk:"changes"` // list of LayoutChangeValue
+}
+
+// LayoutChangeValue is one element of the changes list.
+type LayoutChangeValue struct {
+	SkillName    types.String `tfsdk:"skill_name"`
+	DeploymentID types.String `tfsdk:"deployment_id"`
+	Kind         types.String `tfsdk:"kind"`
+	Key          types.String `tfsdk:"key"`
+}
@@ -0,0 +1,66 @@
+package layoutmigration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	tgt := target.NewMemoryTarget("primary")
+	reg := providerdata.NewTargetRegistry()
+	if err := reg.Register("primary", tgt, providerdata.TargetConfigModel{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("replica", target.NewMemoryTarget("replica"), providerdata.TargetConfigModel{ReplicaOf: types.StringValue("primary")}); err != nil {
+		t.Fatal(err)
+	}
+	r := &LayoutMigrationResource{providerData: &providerdata.ProviderData{Targets: reg, Scheduler: concurrency.NewUniform(4)}}
+
+	manifestKey := "my-skill/.agentctx/deployments/dep_20200101T000000Z_00000001/manifest.json"
+	if err := tgt.Put(ctx, manifestKey, strings.NewReader(`{"schema_version":1,"files":{}}`), target.PutOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	model := LayoutMigrationResourceModel{
+		Target:     types.StringValue("primary"),
+		SkillNames: types.ListNull(types.StringType),
+		DryRun:     types.BoolValue(true),
+	}
+	planned, diags := r.migrate(ctx, model, true)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if len(planned) != 2 || planned[0].Kind != engine.LayoutManifestSchema || planned[1].Kind != engine.LayoutMissingIndex {
+		t.Fatalf("planned = %+v", planned)
+	}
+	if _, err := tgt.Head(ctx, "my-skill/.agentctx/index.json"); err == nil {
+		t.Fatal("dry run wrote the index")
+	}
+
+	migrated, diags := r.migrate(ctx, model, false)
+	if diags.HasError() || len(migrated) != 2 {
+		t.Fatalf("migrate = %+v, %v", migrated, diags)
+	}
+	if again, _ := r.migrate(ctx, model, true); len(again) != 0 {
+		t.Errorf("after migration, planned = %+v", again)
+	}
+
+	list, diags := changeValues(ctx, migrated)
+	if diags.HasError() || len(list.Elements()) != 2 {
+		t.Errorf("changeValues = %v, %v", list, diags)
+	}
+
+	model.Target = types.StringValue("replica")
+	if _, diags := r.migrate(ctx, model, true); !diags.HasError() {
+		t.Error("expected error migrating a replica target")
+	}
+}
@@ -0,0 +1,547 @@
+package marketplace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
+
+// manifestRelPath is the path of the marketplace manifest relative to the
+// marketplace root.
+const manifestRelPath = ".claude-plugin/marketplace.json"
+
+// githubRepoPattern matches the owner/repo part of a "github:" source.
+var githubRepoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource              = &MarketplaceResource{}
+	_ resource.ResourceWithConfigure = &MarketplaceResource{}
+)
+
+// NewMarketplaceResource returns a new resource.Resource for the
+// agentctx_marketplace type.
+func NewMarketplaceResource() resource.Resource {
+	return &MarketplaceResource{}
+}
+
+// MarketplaceResource implements the agentctx_marketplace Terraform
+// resource. It generates the .claude-plugin/marketplace.json manifest of a
+// Claude Code plugin marketplace, listing plugins with their sources.
+type MarketplaceResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *MarketplaceResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_marketplace"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *MarketplaceResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a Claude Code plugin marketplace. Generates the `.claude-plugin/marketplace.json` manifest listing each `plugin` block with its source, which can be the `plugin_dir` of an `agentctx_plugin` resource, a GitHub repository, or a Git URL.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Marketplace identifier (kebab-case). Users refer to plugins as `<plugin>@<name>` when installing them.",
+				Required:            true,
+				Validators: []validator.String{
+					validators.Name(),
+				},
+			},
+			"output_dir": schema.StringAttribute{
+				MarkdownDescription: "Root directory of the marketplace. The manifest is written to `output_dir/.claude-plugin/marketplace.json`, and local plugin sources must lie within this directory.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+
+			// ---- Optional ----
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Brief description of the marketplace. Written to the manifest as `metadata.description`.",
+				Optional:            true,
+			},
+			"version": schema.StringAttribute{
+				MarkdownDescription: "Version of the marketplace (e.g. `1.0.0`). Written to the manifest as `metadata.version`.",
+				Optional:            true,
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Unique identifier for the resource: the absolute path of the marketplace manifest.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"marketplace_file": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of the generated `marketplace.json`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"manifest_json": schema.StringAttribute{
+				MarkdownDescription: "The rendered marketplace manifest. Null when the provider's `state_content` is `\"hashes\"`.",
+				Computed:            true,
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of the manifest content, prefixed with `sha256:`.",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"owner": schema.ListNestedBlock{
+				MarkdownDescription: "Maintainer of the marketplace. Exactly one block is required.",
+				Validators: []validator.List{
+					listvalidator.IsRequired(),
+					listvalidator.SizeBetween(1, 1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Name of the maintainer or team.",
+							Required:            true,
+						},
+						"email": schema.StringAttribute{
+							MarkdownDescription: "Contact email address.",
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"plugin": schema.ListNestedBlock{
+				MarkdownDescription: "A plugin listed in the marketplace. Plugins are written in declaration order.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Plugin name, unique within the marketplace. Should match the `name` in the plugin's own manifest.",
+							Required:            true,
+							Validators: []validator.String{
+								validators.Name(),
+							},
+						},
+						"source": schema.StringAttribute{
+							MarkdownDescription: "Where to fetch the plugin from: a directory within `output_dir`, such as the `plugin_dir` of an `agentctx_plugin` resource, written as a `./` path relative to the marketplace root; `github:<owner>/<repo>` for a GitHub repository; or a Git URL such as `https://gitlab.com/team/plugin.git`.",
+							Required:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Brief description of the plugin shown when browsing the marketplace.",
+							Optional:            true,
+						},
+						"version": schema.StringAttribute{
+							MarkdownDescription: "Version of the plugin (e.g. `1.2.0`).",
+							Optional:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *MarketplaceResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *MarketplaceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_marketplace", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan MarketplaceResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRunMarketplace(dryrun.OpCreate, &plan)...)
+		return
+	}
+
+	resp.Diagnostics.Append(r.writeMarketplace(&plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "created marketplace", map[string]interface{}{
+		"name":    plan.Name.ValueString(),
+		"plugins": len(plan.Plugins),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *MarketplaceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state MarketplaceResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.MarketplaceFile.ValueString()
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_marketplace", state.ID.ValueString(), fmt.Sprintf("file %q was deleted", filePath))...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			tflog.Info(ctx, "marketplace manifest not found on disk, removing from state", map[string]interface{}{
+				"file_path": filePath,
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read marketplace manifest %q: %s", filePath, err))
+		return
+	}
+
+	hash := computeHash(data)
+	if prior := state.ContentHash.ValueString(); prior != "" && hash != prior {
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_marketplace", state.ID.ValueString(), fmt.Sprintf("file %q has hash %s, want %s", filePath, hash, prior))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	state.ManifestJSON = r.providerData.Content(string(data))
+	state.ContentHash = types.StringValue(hash)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *MarketplaceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_marketplace", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan MarketplaceResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRunMarketplace(dryrun.OpUpdate, &plan)...)
+		return
+	}
+
+	resp.Diagnostics.Append(r.writeMarketplace(&plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "updated marketplace", map[string]interface{}{
+		"name":    plan.Name.ValueString(),
+		"plugins": len(plan.Plugins),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+func (r *MarketplaceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_marketplace", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state MarketplaceResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.MarketplaceFile.ValueString()
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_marketplace",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Files:     []dryrun.FileChange{dryrun.RemoveFile(filePath)},
+		})...)
+		return
+	}
+
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete marketplace manifest %q: %s", filePath, err))
+		return
+	}
+	// The .claude-plugin directory may also hold a plugin manifest; remove
+	// it only when the marketplace manifest was all it held.
+	_ = os.Remove(filepath.Dir(filePath))
+
+	tflog.Info(ctx, "deleted marketplace", map[string]interface{}{
+		"name": state.Name.ValueString(),
+	})
+}
+
+// --------------------------------------------------------------------------
+// Rendering
+// --------------------------------------------------------------------------
+
+// marketplaceManifest is the JSON structure of marketplace.json. Fields are
+// written in declaration order.
+type marketplaceManifest struct {
+	Name     string               `json:"name"`
+	Owner    marketplaceOwner     `json:"owner"`
+	Metadata *marketplaceMetadata `json:"metadata,omitempty"`
+	Plugins  []marketplacePlugin  `json:"plugins"`
+}
+
+type marketplaceOwner struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+type marketplaceMetadata struct {
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version,omitempty"`
+}
+
+type marketplacePlugin struct {
+	Name string `json:"name"`
+	// Source is a "./" path string for a local plugin, or an object
+	// naming a GitHub repository or Git URL.
+	Source      interface{} `json:"source"`
+	Description string      `json:"description,omitempty"`
+	Version     string      `json:"version,omitempty"`
+}
+
+// renderManifest builds marketplace.json for model, whose marketplace root
+// is the absolute directory root. Plugin names must be unique and local
+// sources must lie within root.
+func renderManifest(model *MarketplaceResourceModel, root string) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	m := marketplaceManifest{
+		Name:    model.Name.ValueString(),
+		Plugins: make([]marketplacePlugin, 0, len(model.Plugins)),
+	}
+	if len(model.Owner) == 1 {
+		m.Owner = marketplaceOwner{
+			Name:  model.Owner[0].Name.ValueString(),
+			Email: model.Owner[0].Email.ValueString(),
+		}
+	}
+	if description, version := model.Description.ValueString(), model.Version.ValueString(); description != "" || version != "" {
+		m.Metadata = &marketplaceMetadata{Description: description, Version: version}
+	}
+
+	seen := make(map[string]bool, len(model.Plugins))
+	for i, p := range model.Plugins {
+		name := p.Name.ValueString()
+		if seen[name] {
+			diags.AddAttributeError(
+				path.Root("plugin").AtListIndex(i).AtName("name"),
+				errcode.DuplicateName.Summary("Duplicate Marketplace Plugin"),
+				fmt.Sprintf("Plugin name %q is used more than once in marketplace %q.", name, m.Name),
+			)
+			continue
+		}
+		seen[name] = true
+
+		source, err := resolveSource(p.Source.ValueString(), root)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("plugin").AtListIndex(i).AtName("source"),
+				errcode.InvalidConfig.Summary("Invalid Plugin Source"),
+				fmt.Sprintf("The source of plugin %q is invalid: %s", name, err),
+			)
+			continue
+		}
+		m.Plugins = append(m.Plugins, marketplacePlugin{
+			Name:        name,
+			Source:      source,
+			Description: p.Description.ValueString(),
+			Version:     p.Version.ValueString(),
+		})
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		diags.AddError(errcode.Internal.Summary("Marketplace Rendering Failed"), fmt.Sprintf("Failed to encode marketplace manifest: %s", err))
+		return nil, diags
+	}
+	return append(data, '\n'), diags
+}
+
+// resolveSource converts a plugin block's source into its manifest form:
+// {"source": "github", "repo": ...} for "github:<owner>/<repo>",
+// {"source": "url", "url": ...} for a Git URL, and otherwise a "./" path
+// relative to root for a local directory, which must lie within root.
+// Relative local paths are resolved against the working directory, like
+// every other path the provider takes.
+func resolveSource(source, root string) (interface{}, error) {
+	switch {
+	case source == "":
+		return nil, fmt.Errorf("source must not be empty")
+	case strings.HasPrefix(source, "github:"):
+		repo := strings.TrimPrefix(source, "github:")
+		if !githubRepoPattern.MatchString(repo) {
+			return nil, fmt.Errorf("%q must have the form github:<owner>/<repo>", source)
+		}
+		return map[string]string{"source": "github", "repo": repo}, nil
+	case strings.Contains(source, "://") || strings.HasPrefix(source, "git@"):
+		return map[string]string{"source": "url", "url": source}, nil
+	}
+
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %w", source, err)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("local plugin directory %q is outside the marketplace root %q; Claude Code resolves local sources relative to the marketplace root", abs, root)
+	}
+	if rel == "." {
+		return "./", nil
+	}
+	return "./" + filepath.ToSlash(rel), nil
+}
+
+// --------------------------------------------------------------------------
+// File operations
+// --------------------------------------------------------------------------
+
+// marketplaceRoot returns the absolute path of the model's output_dir.
+func marketplaceRoot(model *MarketplaceResourceModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	root, err := filepath.Abs(model.OutputDir.ValueString())
+	if err != nil {
+		diags.AddError(errcode.PathResolution.Summary("Invalid Output Directory"), fmt.Sprintf("Failed to resolve output_dir %q: %s", model.OutputDir.ValueString(), err))
+	}
+	return root, diags
+}
+
+// writeMarketplace renders and writes the marketplace manifest, then
+// populates the computed attributes of model.
+func (r *MarketplaceResource) writeMarketplace(model *MarketplaceResourceModel) diag.Diagnostics {
+	root, diags := marketplaceRoot(model)
+	if diags.HasError() {
+		return diags
+	}
+	data, d := renderManifest(model, root)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	filePath := filepath.Join(root, filepath.FromSlash(manifestRelPath))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("Directory Creation Failed"), fmt.Sprintf("Failed to create directory %q: %s", filepath.Dir(filePath), err))
+		return diags
+	}
+	if err := atomicfile.WriteFile(filePath, data, 0o644); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write marketplace manifest %q: %s", filePath, err))
+		return diags
+	}
+
+	model.ID = types.StringValue(filePath)
+	model.MarketplaceFile = types.StringValue(filePath)
+	model.ManifestJSON = r.providerData.Content(string(data))
+	model.ContentHash = types.StringValue(computeHash(data))
+	return diags
+}
+
+// dryRunMarketplace records the manifest writeMarketplace would write for
+// model in the dry-run report.
+func (r *MarketplaceResource) dryRunMarketplace(operation string, model *MarketplaceResourceModel) diag.Diagnostics {
+	root, diags := marketplaceRoot(model)
+	if diags.HasError() {
+		return diags
+	}
+	data, d := renderManifest(model, root)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	filePath := filepath.Join(root, filepath.FromSlash(manifestRelPath))
+	return r.providerData.RecordDryRun(dryrun.Change{
+		Resource:  "agentctx_marketplace",
+		Operation: operation,
+		ID:        filePath,
+		Files:     []dryrun.FileChange{dryrun.WriteFile(filePath, data)},
+	})
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+func computeHash(content []byte) string {
+	h := sha256.Sum256(content)
+	return fmt.Sprintf("sha256:%x", h)
+}
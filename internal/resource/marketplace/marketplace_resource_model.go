@@ -0,0 +1,40 @@
+package marketplace
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// MarketplaceResourceModel maps the agentctx_marketplace resource schema to a
+// Go struct.
+type MarketplaceResourceModel struct {
+	// Required
+	Name      types.String `tfsdk:"name"`
+	OutputDir types.String `tfsdk:"output_dir"`
+
+	// Optional
+	Description types.String `tfsdk:"description"`
+	Version     types.String `tfsdk:"version"`
+
+	// Blocks
+	Owner   []OwnerModel             `tfsdk:"owner"`
+	Plugins []MarketplacePluginModel `tfsdk:"plugin"`
+
+	// Computed
+	ID              types.String `tfsdk:"id"`
+	MarketplaceFile types.String `tfsdk:"marketplace_file"`
+	ManifestJSON    types.String `tfsdk:"manifest_json"`
+	ContentHash     types.String `tfsdk:"content_hash"`
+}
+
+// OwnerModel maps the owner {} block. Exactly one block is required.
+type OwnerModel struct {
+	Name  types.String `tfsdk:"name"`
+	Email types.String `tfsdk:"email"`
+}
+
+// MarketplacePluginModel maps a single plugin {} block, one entry of the
+// manifest's plugins list.
+type MarketplacePluginModel struct {
+	Name        types.String `tfsdk:"name"`
+	Source      types.String `tfsdk:"source"`
+	Description types.String `tfsdk:"description"`
+	Version     types.String `tfsdk:"version"`
+}
@@ -0,0 +1,139 @@
+package marketplace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+func testMarketplace(outputDir string) *MarketplaceResourceModel {
+	return &MarketplaceResourceModel{
+		Name:        types.StringValue("acme-tools"),
+		OutputDir:   types.StringValue(outputDir),
+		Description: types.StringValue("Internal plugins."),
+		Version:     types.StringNull(),
+		Owner: []OwnerModel{
+			{Name: types.StringValue("DevTools"), Email: types.StringValue("devtools@example.com")},
+		},
+		Plugins: []MarketplacePluginModel{
+			testPlugin("formatter", filepath.Join(outputDir, "plugins", "formatter")),
+			testPlugin("linter", "github:acme/linter-plugin"),
+		},
+	}
+}
+
+func testPlugin(name, source string) MarketplacePluginModel {
+	return MarketplacePluginModel{
+		Name:        types.StringValue(name),
+		Source:      types.StringValue(source),
+		Description: types.StringNull(),
+		Version:     types.StringNull(),
+	}
+}
+
+func TestRenderManifest(t *testing.T) {
+	root := t.TempDir()
+	model := testMarketplace(root)
+	model.Plugins[0].Version = types.StringValue("1.2.0")
+	model.Plugins[0].Description = types.StringValue("Formats code.")
+
+	data, diags := renderManifest(model, root)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("manifest is not JSON: %v\n%s", err, data)
+	}
+	want := map[string]interface{}{
+		"name":     "acme-tools",
+		"owner":    map[string]interface{}{"name": "DevTools", "email": "devtools@example.com"},
+		"metadata": map[string]interface{}{"description": "Internal plugins."},
+		"plugins": []interface{}{
+			map[string]interface{}{"name": "formatter", "source": "./plugins/formatter", "description": "Formats code.", "version": "1.2.0"},
+			map[string]interface{}{"name": "linter", "source": map[string]interface{}{"source": "github", "repo": "acme/linter-plugin"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("manifest = %s", data)
+	}
+	if !strings.HasSuffix(string(data), "}\n") {
+		t.Error("expected a trailing newline")
+	}
+}
+
+func TestRenderManifest_DuplicateName(t *testing.T) {
+	root := t.TempDir()
+	model := testMarketplace(root)
+	model.Plugins = append(model.Plugins, testPlugin("linter", "github:acme/other"))
+
+	_, diags := renderManifest(model, root)
+	if !diags.HasError() {
+		t.Fatal("expected error for duplicate plugin name")
+	}
+	if got, want := diags.Errors()[0].Summary(), errcode.DuplicateName.Summary("Duplicate Marketplace Plugin"); got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestResolveSource(t *testing.T) {
+	root := t.TempDir()
+	tests := []struct {
+		source  string
+		want    interface{}
+		wantErr bool
+	}{
+		{root, "./", false},
+		{filepath.Join(root, "plugins", "a"), "./plugins/a", false},
+		{"github:acme/plugin", map[string]string{"source": "github", "repo": "acme/plugin"}, false},
+		{"https://gitlab.com/acme/plugin.git", map[string]string{"source": "url", "url": "https://gitlab.com/acme/plugin.git"}, false},
+		{"git@github.com:acme/plugin.git", map[string]string{"source": "url", "url": "git@github.com:acme/plugin.git"}, false},
+		{"", nil, true},
+		{"github:acme", nil, true},
+		{"github:acme/plugin/extra", nil, true},
+		{filepath.Join(root, "..", "elsewhere"), nil, true},
+	}
+	for _, tt := range tests {
+		got, err := resolveSource(tt.source, root)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveSource(%q) error = %v, wantErr %v", tt.source, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolveSource(%q) = %#v, want %#v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestWriteMarketplace(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "marketplace")
+	model := testMarketplace(root)
+
+	r := &MarketplaceResource{}
+	if diags := r.writeMarketplace(model); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	filePath := filepath.Join(root, ".claude-plugin", "marketplace.json")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	if string(data) != model.ManifestJSON.ValueString() {
+		t.Error("manifest content does not match manifest_json")
+	}
+	if model.MarketplaceFile.ValueString() != filePath || model.ID.ValueString() != filePath {
+		t.Errorf("marketplace_file = %q, id = %q, want %q", model.MarketplaceFile.ValueString(), model.ID.ValueString(), filePath)
+	}
+	if model.ContentHash.ValueString() != computeHash(data) {
+		t.Errorf("content_hash = %q, want %q", model.ContentHash.ValueString(), computeHash(data))
+	}
+}
@@ -0,0 +1,553 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource                   = &MemoryResource{}
+	_ resource.ResourceWithConfigure      = &MemoryResource{}
+	_ resource.ResourceWithValidateConfig = &MemoryResource{}
+	_ resource.ResourceWithModifyPlan     = &MemoryResource{}
+)
+
+// NewMemoryResource returns a new resource.Resource for the agentctx_memory
+// type.
+func NewMemoryResource() resource.Resource {
+	return &MemoryResource{}
+}
+
+// MemoryResource implements the agentctx_memory Terraform resource. It
+// generates a Claude Code memory file, CLAUDE.md or CLAUDE.local.md, from
+// a title, @path imports, and sections of prose and rules, and writes it
+// to the project or the user's home directory.
+type MemoryResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// scopeFiles maps a memory scope to the memory file Claude Code loads for
+// it, relative to the project (project and local scopes) or the home
+// directory (user scope).
+var scopeFiles = map[string]string{
+	"project": "CLAUDE.md",
+	"local":   "CLAUDE.local.md",
+	"user":    filepath.Join(".claude", "CLAUDE.md"),
+}
+
+// importPattern matches an import path as written after @: Claude Code
+// ends the path at the first whitespace.
+var importPattern = regexp.MustCompile(`^[^\s@]\S*$`)
+
+// importValidators check the elements of an imports list.
+var importValidators = []validator.List{
+	listvalidator.ValueStringsAre(stringvalidator.RegexMatches(importPattern, "must be a path without whitespace and without the leading @, such as docs/architecture.md or ~/.claude/my-rules.md")),
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *MemoryResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_memory"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *MemoryResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Generates a Claude Code memory file, `CLAUDE.md` or `CLAUDE.local.md`, from a title, `@path` imports, and sections of prose and rules. Edits made to the file outside Terraform are detected and overwritten on the next apply.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"scope": schema.StringAttribute{
+				MarkdownDescription: "Which memory file to write: `project` writes `<project_dir>/CLAUDE.md`, shared with the team through version control; `local` writes `<project_dir>/CLAUDE.local.md`, for personal project preferences; `user` writes `~/.claude/CLAUDE.md`, loaded in every project. Changing this forces a new resource to be created.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf("project", "local", "user"),
+				},
+			},
+
+			// ---- Optional ----
+			"project_dir": schema.StringAttribute{
+				MarkdownDescription: "Root of the project the `project` and `local` memory files are written to. Defaults to the working directory. Not valid with the `user` scope. Changing this forces a new resource to be created.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"title": schema.StringAttribute{
+				MarkdownDescription: "Written as the `#` heading at the top of the file.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[^\n]+$`), "must be a single line"),
+				},
+			},
+			"imports": schema.ListAttribute{
+				MarkdownDescription: "Files to import into the memory, written after the title as one `@path` line each, such as `docs/architecture.md` or `~/.claude/my-rules.md`. Paths are written without the leading `@` and must not contain whitespace.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators:          importValidators,
+			},
+			"content_storage": schema.StringAttribute{
+				MarkdownDescription: "What is kept in state for the rendered file: `\"full\"` stores it in `content`; `\"hash_only\"` stores `content` as null and keeps only `content_hash`, which refresh recomputes from the file on disk. Overrides the provider's `state_content` for this resource; unset follows it.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("full", "hash_only"),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of the memory file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"file_path": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of the memory file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "The rendered Markdown content of the memory file. Null when `content_storage` is `\"hash_only\"`, or when it is unset and the provider's `state_content` is `\"hashes\"`.",
+				Computed:            true,
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of the memory file content, prefixed with `sha256:`. Refresh recomputes it from the file on disk, so an edited file plans an update that writes the configured content again.",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"section": schema.ListNestedBlock{
+				MarkdownDescription: "Sections of the memory file, written in order after the title and imports, each as a `##` heading followed by its content, rules, and imports.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"heading": schema.StringAttribute{
+							MarkdownDescription: "Section heading, written as `## <heading>`.",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.RegexMatches(regexp.MustCompile(`^[^\n]+$`), "must be a single line"),
+							},
+						},
+						"content": schema.StringAttribute{
+							MarkdownDescription: "Markdown prose written below the heading. Leading and trailing blank lines are removed.",
+							Optional:            true,
+						},
+						"rules": schema.ListAttribute{
+							MarkdownDescription: "Rules written as a bulleted list after `content`, one `- ` item each.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"imports": schema.ListAttribute{
+							MarkdownDescription: "Files to import in this section, written last as one `@path` line each. Same rules as the top-level `imports`.",
+							Optional:            true,
+							ElementType:         types.StringType,
+							Validators:          importValidators,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *MemoryResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// ValidateConfig
+// --------------------------------------------------------------------------
+
+// ValidateConfig checks that project_dir is only set for the project and
+// local scopes and that the memory file has some content.
+func (r *MemoryResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config MemoryResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	scope, projectDir := config.Scope, config.ProjectDir
+	if config.Title.IsNull() && config.Imports.IsNull() && len(config.Sections) == 0 {
+		resp.Diagnostics.AddError(
+			errcode.InvalidConfig.Summary("Invalid Memory Configuration"),
+			"At least one of title, imports, or a section block must be set.",
+		)
+	}
+	if scope.ValueString() == "user" && !projectDir.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("project_dir"),
+			errcode.InvalidConfig.Summary("Invalid Memory Configuration"),
+			"project_dir is not valid with scope = \"user\": the user memory file is always ~/.claude/CLAUDE.md.",
+		)
+	}
+}
+
+// --------------------------------------------------------------------------
+// ModifyPlan
+// --------------------------------------------------------------------------
+
+// ModifyPlan sets the path, content, and hash the apply will write once the
+// configuration is known. Read records the hash of the file on disk, so a
+// file edited outside Terraform differs from the planned hash and plans an
+// update.
+func (r *MemoryResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.Config.Raw.IsFullyKnown() {
+		return
+	}
+
+	var plan MemoryResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	filePath, err := memoryFilePath(&plan)
+	if err != nil {
+		// Create reports the error.
+		return
+	}
+
+	content := Render(&plan)
+	plan.ID = types.StringValue(filePath)
+	plan.FilePath = types.StringValue(filePath)
+	plan.Content = r.providerData.ContentFor(plan.ContentStorage, content)
+	plan.ContentHash = types.StringValue(computeHash(content))
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *MemoryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_memory", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan MemoryResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &plan, dryrun.OpCreate)...)
+	if resp.Diagnostics.HasError() || r.providerData.DryRunning() {
+		return
+	}
+
+	tflog.Info(ctx, "created memory file", map[string]interface{}{
+		"scope":     plan.Scope.ValueString(),
+		"file_path": plan.FilePath.ValueString(),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *MemoryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state MemoryResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.FilePath.ValueString()
+
+	data, err := os.ReadFile(longpath.Path(filePath))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read memory file %q: %s", filePath, err))
+			return
+		}
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_memory", state.ID.ValueString(), fmt.Sprintf("file %q was deleted", filePath))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		tflog.Info(ctx, "memory file not found on disk, removing from state", map[string]interface{}{
+			"file_path": filePath,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	diskContent := string(data)
+	diskHash := computeHash(diskContent)
+	if prior := state.ContentHash.ValueString(); prior != "" && diskHash != prior {
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_memory", state.ID.ValueString(), fmt.Sprintf("file %q has hash %s, want %s", filePath, diskHash, prior))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		tflog.Info(ctx, "memory file modified outside Terraform", map[string]interface{}{
+			"file_path": filePath,
+		})
+	}
+
+	state.Content = r.providerData.ContentFor(state.ContentStorage, diskContent)
+	state.ContentHash = types.StringValue(diskHash)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *MemoryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_memory", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan MemoryResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &plan, dryrun.OpUpdate)...)
+	if resp.Diagnostics.HasError() || r.providerData.DryRunning() {
+		return
+	}
+
+	tflog.Info(ctx, "updated memory file", map[string]interface{}{
+		"scope":     plan.Scope.ValueString(),
+		"file_path": plan.FilePath.ValueString(),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+func (r *MemoryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_memory", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state MemoryResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.FilePath.ValueString()
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_memory",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Files:     []dryrun.FileChange{dryrun.RemoveFile(filePath)},
+		})...)
+		return
+	}
+
+	if err := os.Remove(longpath.Path(filePath)); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete memory file %q: %s", filePath, err))
+		return
+	}
+
+	tflog.Info(ctx, "deleted memory file", map[string]interface{}{
+		"file_path": filePath,
+	})
+}
+
+// --------------------------------------------------------------------------
+// Rendering
+// --------------------------------------------------------------------------
+
+// Render returns the Markdown content of the memory file described by
+// model: the title, the imports, and each section, separated by blank
+// lines.
+func Render(model *MemoryResourceModel) string {
+	var blocks []string
+	if title := model.Title.ValueString(); title != "" {
+		blocks = append(blocks, "# "+title)
+	}
+	if imports := importLines(model.Imports); imports != "" {
+		blocks = append(blocks, imports)
+	}
+	for _, s := range model.Sections {
+		blocks = append(blocks, "## "+s.Heading.ValueString())
+		if content := strings.Trim(s.Content.ValueString(), "\n"); strings.TrimSpace(content) != "" {
+			blocks = append(blocks, content)
+		}
+		if rules := listStrings(s.Rules); len(rules) > 0 {
+			items := make([]string, len(rules))
+			for i, rule := range rules {
+				// Continuation lines are indented to stay in the item.
+				items[i] = "- " + strings.ReplaceAll(strings.TrimSpace(rule), "\n", "\n  ")
+			}
+			blocks = append(blocks, strings.Join(items, "\n"))
+		}
+		if imports := importLines(s.Imports); imports != "" {
+			blocks = append(blocks, imports)
+		}
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// importLines returns the @path lines of an imports list.
+func importLines(list types.List) string {
+	paths := listStrings(list)
+	lines := make([]string, len(paths))
+	for i, p := range paths {
+		lines[i] = "@" + p
+	}
+	return strings.Join(lines, "\n")
+}
+
+// listStrings returns the known string elements of list.
+func listStrings(list types.List) []string {
+	if list.IsNull() || list.IsUnknown() {
+		return nil
+	}
+	var out []string
+	for _, v := range list.Elements() {
+		if s, ok := v.(types.String); ok && !s.IsNull() && !s.IsUnknown() {
+			out = append(out, s.ValueString())
+		}
+	}
+	return out
+}
+
+// --------------------------------------------------------------------------
+// File operations
+// --------------------------------------------------------------------------
+
+// write renders model and writes it to its memory file, or records the
+// write in the dry-run report, and sets the computed attributes.
+func (r *MemoryResource) write(ctx context.Context, model *MemoryResourceModel, operation string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	filePath, err := memoryFilePath(model)
+	if err != nil {
+		diags.AddError(errcode.PathResolution.Summary("Path Resolution Failed"), err.Error())
+		return diags
+	}
+	content := Render(model)
+
+	if r.providerData.DryRunning() {
+		return r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_memory",
+			Operation: operation,
+			ID:        filePath,
+			Files:     []dryrun.FileChange{dryrun.WriteFile(filePath, []byte(content))},
+		})
+	}
+
+	if err := os.MkdirAll(longpath.Path(filepath.Dir(filePath)), 0o755); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create directory for memory file %q: %s", filePath, err))
+		return diags
+	}
+	if err := atomicfile.WriteFile(longpath.Path(filePath), []byte(content), 0o644); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write memory file %q: %s", filePath, err))
+		return diags
+	}
+
+	model.ID = types.StringValue(filePath)
+	model.FilePath = types.StringValue(filePath)
+	model.Content = r.providerData.ContentFor(model.ContentStorage, content)
+	model.ContentHash = types.StringValue(computeHash(content))
+	return diags
+}
+
+// memoryFilePath returns the absolute path of the memory file of model's
+// scope.
+func memoryFilePath(model *MemoryResourceModel) (string, error) {
+	scope := model.Scope.ValueString()
+	name, ok := scopeFiles[scope]
+	if !ok {
+		return "", fmt.Errorf("unknown memory scope %q", scope)
+	}
+
+	root := model.ProjectDir.ValueString()
+	if scope == "user" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("finding the home directory for the user memory file: %w", err)
+		}
+		root = home
+	} else if root == "" {
+		root = "."
+	}
+
+	filePath := filepath.Join(root, name)
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("resolving absolute path for %q: %w", filePath, err)
+	}
+	return absPath, nil
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// computeHash returns the SHA-256 hash of the given content, prefixed with
+// "sha256:" to match the convention used elsewhere in the provider.
+func computeHash(content string) string {
+	h := sha256.Sum256([]byte(content))
+	return fmt.Sprintf("sha256:%x", h)
+}
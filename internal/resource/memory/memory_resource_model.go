@@ -0,0 +1,33 @@
+package memory
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// MemoryResourceModel maps the agentctx_memory resource schema to a Go
+// struct.
+type MemoryResourceModel struct {
+	// Required
+	Scope types.String `tfsdk:"scope"`
+
+	// Optional
+	ProjectDir     types.String `tfsdk:"project_dir"`
+	Title          types.String `tfsdk:"title"`
+	Imports        types.List   `tfsdk:"imports"`
+	ContentStorage types.String `tfsdk:"content_storage"`
+
+	// Optional – blocks
+	Sections []SectionModel `tfsdk:"section"`
+
+	// Computed
+	ID          types.String `tfsdk:"id"`
+	FilePath    types.String `tfsdk:"file_path"`
+	Content     types.String `tfsdk:"content"`
+	ContentHash types.String `tfsdk:"content_hash"`
+}
+
+// SectionModel maps a section {} block.
+type SectionModel struct {
+	Heading types.String `tfsdk:"heading"`
+	Content types.String `tfsdk:"content"`
+	Rules   types.List   `tfsdk:"rules"`
+	Imports types.List   `tfsdk:"imports"`
+}
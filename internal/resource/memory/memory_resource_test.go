@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func stringList(values ...string) types.List {
+	elems := make([]attr.Value, len(values))
+	for i, v := range values {
+		elems[i] = types.StringValue(v)
+	}
+	return types.ListValueMust(types.StringType, elems)
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		model MemoryResourceModel
+		want  string
+	}{
+		{
+			name:  "title only",
+			model: MemoryResourceModel{Title: types.StringValue("Project"), Imports: types.ListNull(types.StringType)},
+			want:  "# Project\n",
+		},
+		{
+			name: "title, imports, and sections",
+			model: MemoryResourceModel{
+				Title:   types.StringValue("Project"),
+				Imports: stringList("README.md", "~/.claude/my-rules.md"),
+				Sections: []SectionModel{
+					{
+						Heading: types.StringValue("Build"),
+						Content: types.StringValue("\nRun `make` to build.\n\n"),
+						Rules:   stringList("Run tests before committing", "Keep\ncommits small"),
+						Imports: types.ListNull(types.StringType),
+					},
+					{
+						Heading: types.StringValue("Architecture"),
+						Content: types.StringNull(),
+						Rules:   types.ListNull(types.StringType),
+						Imports: stringList("docs/architecture.md"),
+					},
+				},
+			},
+			want: "# Project\n\n@README.md\n@~/.claude/my-rules.md\n\n" +
+				"## Build\n\nRun `make` to build.\n\n- Run tests before committing\n- Keep\n  commits small\n\n" +
+				"## Architecture\n\n@docs/architecture.md\n",
+		},
+		{
+			name: "blank section content is skipped",
+			model: MemoryResourceModel{
+				Title:   types.StringNull(),
+				Imports: types.ListNull(types.StringType),
+				Sections: []SectionModel{{
+					Heading: types.StringValue("Notes"),
+					Content: types.StringValue("\n  \n"),
+					Rules:   stringList("Be brief"),
+					Imports: types.ListNull(types.StringType),
+				}},
+			},
+			want: "## Notes\n\n- Be brief\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(&tt.model); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportPattern(t *testing.T) {
+	for _, p := range []string{"README.md", "docs/architecture.md", "~/.claude/my-rules.md", "/abs/path.md"} {
+		if !importPattern.MatchString(p) {
+			t.Errorf("importPattern rejected %q", p)
+		}
+	}
+	for _, p := range []string{"", "@README.md", "docs/my notes.md", " README.md"} {
+		if importPattern.MatchString(p) {
+			t.Errorf("importPattern accepted %q", p)
+		}
+	}
+}
+
+func TestMemoryFilePath(t *testing.T) {
+	dir := t.TempDir()
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %s", err)
+	}
+
+	tests := []struct {
+		scope      string
+		projectDir types.String
+		want       string
+	}{
+		{"project", types.StringValue(dir), filepath.Join(dir, "CLAUDE.md")},
+		{"local", types.StringValue(dir), filepath.Join(dir, "CLAUDE.local.md")},
+		{"user", types.StringNull(), filepath.Join(home, ".claude", "CLAUDE.md")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			got, err := memoryFilePath(&MemoryResourceModel{Scope: types.StringValue(tt.scope), ProjectDir: tt.projectDir})
+			if err != nil {
+				t.Fatalf("memoryFilePath: %s", err)
+			}
+			if got != tt.want {
+				t.Errorf("memoryFilePath = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// project_dir defaults to the working directory.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := memoryFilePath(&MemoryResourceModel{Scope: types.StringValue("project"), ProjectDir: types.StringNull()})
+	if err != nil {
+		t.Fatalf("memoryFilePath: %s", err)
+	}
+	if want := filepath.Join(wd, "CLAUDE.md"); got != want {
+		t.Errorf("memoryFilePath with no project_dir = %q, want %q", got, want)
+	}
+
+	if _, err := memoryFilePath(&MemoryResourceModel{Scope: types.StringValue("team")}); err == nil {
+		t.Error("memoryFilePath accepted an unknown scope")
+	}
+}
@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/subagent/agentfile"
+)
+
+// agentFrontmatterAttributes are the attributes of an agent block that are
+// written to the YAML frontmatter of its file.
+var agentFrontmatterAttributes = []string{"description", "tools", "model", "permission_mode", "skills"}
+
+// agentFrontmatterBlock returns a as a frontmatterBlock. Its content is the
+// system prompt; description is required because Claude Code uses it to
+// decide when to delegate to the agent.
+func agentFrontmatterBlock(ctx context.Context, a PluginAgentModel) frontmatterBlock {
+	b := frontmatterBlock{
+		kind:              "agent",
+		name:              a.Name.ValueString(),
+		content:           a.Content,
+		set:               setAttributes(agentFrontmatterAttributes, a.Description, a.Tools, a.Model, a.PermissionMode, a.Skills),
+		sourceFrontmatter: "the source file",
+		required:          "description",
+		requiredReason:    "Claude Code requires a description in agent frontmatter to decide when to delegate to the agent.",
+		render: func(body string) (string, error) {
+			return renderAgentFile(ctx, a, body)
+		},
+	}
+	if !a.SourceFile.IsNull() {
+		b.source = "source_file"
+	}
+	return b
+}
+
+// renderAgentFile returns the agent file of a with body as its prompt,
+// rendered by the same code as the files of agentctx_subagent.
+func renderAgentFile(ctx context.Context, a PluginAgentModel, body string) (string, error) {
+	fm := agentfile.Frontmatter{
+		Name:           a.Name.ValueString(),
+		Description:    a.Description.ValueString(),
+		Model:          a.Model.ValueString(),
+		PermissionMode: a.PermissionMode.ValueString(),
+	}
+	if !a.Tools.IsNull() && !a.Tools.IsUnknown() {
+		var tools []string
+		if d := a.Tools.ElementsAs(ctx, &tools, false); d.HasError() {
+			return "", fmt.Errorf("reading tools of agent %q", a.Name.ValueString())
+		}
+		fm.Tools = strings.Join(tools, ", ")
+	}
+	if !a.Skills.IsNull() && !a.Skills.IsUnknown() {
+		if d := a.Skills.ElementsAs(ctx, &fm.Skills, false); d.HasError() {
+			return "", fmt.Errorf("reading skills of agent %q", a.Name.ValueString())
+		}
+	}
+
+	content, err := agentfile.RenderFile(fm, body)
+	if err != nil {
+		return "", fmt.Errorf("marshaling frontmatter: %w", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestWritePlugin_AgentFrontmatter(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+
+	model := scaffoldModel(dir)
+	model.VarsFile = stringValue(writeVarsFile(t, "prod.yaml", "env: prod\n"))
+	model.Agents = []PluginAgentModel{
+		{
+			Name:        stringValue("reviewer"),
+			SourceFile:  types.StringNull(),
+			Content:     stringValue("\nReview changes bound for {{ .env }}.\n"),
+			Description: stringValue("Reviews code changes"),
+			Tools: types.ListValueMust(types.StringType, []attr.Value{
+				types.StringValue("Read"),
+				types.StringValue("Grep"),
+			}),
+			Model:          stringValue("sonnet"),
+			PermissionMode: stringValue("plan"),
+			Skills:         types.ListValueMust(types.StringType, []attr.Value{types.StringValue("style-guide")}),
+		},
+		{
+			// An agent file written out in full keeps its own frontmatter.
+			Name:       stringValue("helper"),
+			SourceFile: types.StringNull(),
+			Content:    stringValue("---\nname: helper\ndescription: Helps\n---\n\nHelp.\n"),
+		},
+	}
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	for rel, want := range map[string]string{
+		"agents/reviewer.md": "---\n" +
+			"name: reviewer\n" +
+			"description: Reviews code changes\n" +
+			"tools: Read, Grep\n" +
+			"model: sonnet\n" +
+			"permissionMode: plan\n" +
+			"skills:\n" +
+			"    - style-guide\n" +
+			"---\n\n" +
+			"Review changes bound for prod.\n",
+		"agents/helper.md": "---\nname: helper\ndescription: Helps\n---\n\nHelp.\n",
+	} {
+		if data, err := os.ReadFile(filepath.Join(dir, rel)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", rel, data, err, want)
+		}
+	}
+}
+
+func TestValidateAgentFrontmatter(t *testing.T) {
+	model := scaffoldModel(filepath.Join(t.TempDir(), "plugin"))
+	model.Agents = []PluginAgentModel{
+		{Name: stringValue("inline"), SourceFile: types.StringNull(), Content: stringValue("Hi."), Description: stringValue("ok"), Model: stringValue("haiku")},
+		{Name: stringValue("plain"), SourceFile: types.StringNull(), Content: stringValue("Hi.")},
+		{
+			Name:        stringValue("copied"),
+			SourceFile:  stringValue("copied.md"),
+			Content:     types.StringNull(),
+			Description: stringValue("not ok"),
+			Tools:       types.ListValueMust(types.StringType, []attr.Value{types.StringValue("Read")}),
+		},
+		{Name: stringValue("undescribed"), SourceFile: types.StringNull(), Content: stringValue("Hi."), Model: stringValue("opus")},
+	}
+
+	diags := validateFrontmatter(context.Background(), model)
+	if diags.ErrorsCount() != 3 {
+		t.Fatalf("got %d errors, want one each for description and tools of copied and one for undescribed: %v", diags.ErrorsCount(), diags)
+	}
+}
@@ -0,0 +1,277 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/filelock"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// registeredAgentsPath lists, relative to output_dir, the sub-agents that
+// agentctx_subagent wrote into the plugin with plugin_dir. writePlugin
+// keeps these agents, and lists them in the manifest, when it regenerates
+// the plugin directory.
+const registeredAgentsPath = ".claude-plugin/agentctx-agents.json"
+
+// registeredAgents is the content of registeredAgentsPath.
+type registeredAgents struct {
+	Agents []string `json:"agents"`
+}
+
+// registeredAgent is a sub-agent agentctx_subagent wrote into the plugin.
+type registeredAgent struct {
+	name    string
+	content []byte
+}
+
+// agentFilePath returns the path of the agent file name relative to the
+// plugin root.
+func agentFilePath(name string) string {
+	return "agents/" + name + ".md"
+}
+
+// readRegisteredAgentNames returns the sorted names of the sub-agents
+// registered in fsDir. A plugin without registry has none.
+func readRegisteredAgentNames(fsDir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(fsDir, filepath.FromSlash(registeredAgentsPath)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var reg registeredAgents
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", registeredAgentsPath, err)
+	}
+	slices.Sort(reg.Agents)
+	return slices.Compact(reg.Agents), nil
+}
+
+// writeRegisteredAgentNames records names as the sub-agents registered in
+// fsDir. The registry is removed when names is empty.
+func writeRegisteredAgentNames(fsDir string, names []string) error {
+	regPath := filepath.Join(fsDir, filepath.FromSlash(registeredAgentsPath))
+	if len(names) == 0 {
+		if err := os.Remove(regPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(registeredAgents{Agents: names}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(regPath, append(data, '\n'), 0o644)
+}
+
+// loadRegisteredAgents reads the sub-agents registered in fsDir with their
+// content, so writePlugin can put them back after it cleans the managed
+// paths. A registered agent whose file is gone is dropped.
+func loadRegisteredAgents(fsDir string) ([]registeredAgent, error) {
+	names, err := readRegisteredAgentNames(fsDir)
+	if err != nil {
+		return nil, err
+	}
+	var agents []registeredAgent
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(fsDir, filepath.FromSlash(agentFilePath(name))))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		agents = append(agents, registeredAgent{name: name, content: content})
+	}
+	return agents, nil
+}
+
+// restoreRegisteredAgents writes agents and their registry into fsDir.
+func restoreRegisteredAgents(fsDir string, agents []registeredAgent) error {
+	if len(agents) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Join(fsDir, "agents"), 0o755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(fsDir, ".claude-plugin"), 0o755); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(agents))
+	for _, a := range agents {
+		if err := atomicfile.WriteFile(filepath.Join(fsDir, filepath.FromSlash(agentFilePath(a.name))), a.content, 0o644); err != nil {
+			return err
+		}
+		names = append(names, a.name)
+	}
+	return writeRegisteredAgentNames(fsDir, names)
+}
+
+// editManifestAgents returns manifestJSON, a plugin.json as render encodes
+// it, with the agent files of the sub-agents named in remove dropped from
+// its agents list and those named in add appended to it. Its "$schema"
+// property and formatting are kept.
+func editManifestAgents(manifestJSON []byte, add, remove []string) ([]byte, error) {
+	var doc struct {
+		Schema string `json:"$schema,omitempty"`
+		pluginManifest
+	}
+	if err := json.Unmarshal(manifestJSON, &doc); err != nil {
+		return nil, fmt.Errorf("decoding plugin manifest: %w", err)
+	}
+
+	manifest := doc.pluginManifest
+	var agents []string
+	for _, p := range manifest.Agents {
+		if !slices.ContainsFunc(remove, func(name string) bool { return p == "./"+agentFilePath(name) }) {
+			agents = append(agents, p)
+		}
+	}
+	for _, name := range add {
+		if p := "./" + agentFilePath(name); !slices.Contains(agents, p) {
+			agents = append(agents, p)
+		}
+	}
+	manifest.Agents = agents
+
+	opts := jsonOptions{compact: !bytes.Contains(bytes.TrimSpace(manifestJSON), []byte("\n"))}
+	if doc.Schema != "" {
+		opts.schemas = map[string]string{pluginJSONPath: doc.Schema}
+	}
+	return opts.marshal(pluginJSONPath, manifest)
+}
+
+// lockPluginDir locks the plugin in absDir as agentctx_plugin does while it
+// writes it.
+func lockPluginDir(ctx context.Context, absDir string) (*filelock.Lock, error) {
+	lock, err := filelock.Acquire(ctx, longpath.Path(filepath.Join(absDir, lockFileName)), defaultLockTimeoutSeconds*time.Second)
+	if err != nil {
+		if errors.Is(err, filelock.ErrTimeout) {
+			return nil, fmt.Errorf("another process is writing plugin %q: %w", absDir, err)
+		}
+		return nil, err
+	}
+	return lock, nil
+}
+
+// RegisterAgent writes content as the sub-agent name of the plugin that
+// agentctx_plugin generated in pluginDir, lists it in the plugin's manifest,
+// and records it so agentctx_plugin keeps it when it regenerates the
+// plugin. The plugin's lock is held throughout. It returns the absolute path
+// of the agent file. It fails when pluginDir holds no plugin, or when an
+// agent block of the plugin itself writes the same file.
+func RegisterAgent(ctx context.Context, pluginDir, name string, content []byte) (string, error) {
+	absDir, err := filepath.Abs(pluginDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving absolute path for %q: %w", pluginDir, err)
+	}
+	fsDir := longpath.Path(absDir)
+	manifestPath := filepath.Join(fsDir, filepath.FromSlash(pluginJSONPath))
+	if _, err := os.Stat(manifestPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%q holds no plugin: %s does not exist; point plugin_dir at the plugin_dir of an agentctx_plugin", absDir, pluginJSONPath)
+		}
+		return "", err
+	}
+
+	lock, err := lockPluginDir(ctx, absDir)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	manifestJSON, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return "", err
+	}
+	names, err := readRegisteredAgentNames(fsDir)
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(names, name) && bytes.Contains(manifestJSON, []byte(`"./`+agentFilePath(name)+`"`)) {
+		return "", fmt.Errorf("the plugin in %q already has an agent named %q from an agent block of its agentctx_plugin", absDir, name)
+	}
+
+	agentPath := filepath.Join(absDir, "agents", name+".md")
+	if err := os.MkdirAll(filepath.Join(fsDir, "agents"), 0o755); err != nil {
+		return "", err
+	}
+	if err := atomicfile.WriteFile(longpath.Path(agentPath), content, 0o644); err != nil {
+		return "", fmt.Errorf("writing file %q: %w", agentPath, err)
+	}
+
+	if !slices.Contains(names, name) {
+		names = append(names, name)
+		slices.Sort(names)
+		if err := writeRegisteredAgentNames(fsDir, names); err != nil {
+			return "", err
+		}
+	}
+	manifestJSON, err = editManifestAgents(manifestJSON, []string{name}, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := atomicfile.WriteFile(manifestPath, manifestJSON, 0o644); err != nil {
+		return "", err
+	}
+	return agentPath, nil
+}
+
+// UnregisterAgent removes the sub-agent name that RegisterAgent wrote into
+// the plugin in pluginDir, and drops it from the manifest and the registry.
+// A plugin that no longer exists is not an error.
+func UnregisterAgent(ctx context.Context, pluginDir, name string) error {
+	absDir, err := filepath.Abs(pluginDir)
+	if err != nil {
+		return fmt.Errorf("resolving absolute path for %q: %w", pluginDir, err)
+	}
+	fsDir := longpath.Path(absDir)
+	if _, err := os.Stat(fsDir); os.IsNotExist(err) {
+		return nil
+	}
+
+	lock, err := lockPluginDir(ctx, absDir)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	names, err := readRegisteredAgentNames(fsDir)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(names, name) {
+		return nil
+	}
+
+	if err := os.Remove(filepath.Join(fsDir, filepath.FromSlash(agentFilePath(name)))); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	names = slices.DeleteFunc(names, func(n string) bool { return n == name })
+	if err := writeRegisteredAgentNames(fsDir, names); err != nil {
+		return err
+	}
+
+	manifestPath := filepath.Join(fsDir, filepath.FromSlash(pluginJSONPath))
+	manifestJSON, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if manifestJSON, err = editManifestAgents(manifestJSON, nil, []string{name}); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(manifestPath, manifestJSON, 0o644)
+}
@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func manifestAgents(t *testing.T, dir string) []string {
+	t.Helper()
+	var agents []string
+	if list, ok := readJSONFile(t, filepath.Join(dir, ".claude-plugin", "plugin.json"))["agents"].([]interface{}); ok {
+		for _, a := range list {
+			agents = append(agents, a.(string))
+		}
+	}
+	return agents
+}
+
+func TestRegisterAgent_KeptAcrossRegeneration(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	agentPath, err := RegisterAgent(ctx, dir, "helper", []byte("---\nname: helper\n---\nHelp.\n"))
+	if err != nil {
+		t.Fatalf("RegisterAgent: %v", err)
+	}
+	if want := filepath.Join(dir, "agents", "helper.md"); agentPath != want {
+		t.Errorf("agent path = %q, want %q", agentPath, want)
+	}
+	if got := manifestAgents(t, dir); !slices.Contains(got, "./agents/helper.md") || !slices.Contains(got, "./agents/reviewer.md") {
+		t.Errorf("manifest agents after RegisterAgent = %v", got)
+	}
+
+	// The plugin regenerates its directory and keeps the registered agent.
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if data, err := os.ReadFile(agentPath); err != nil || !strings.Contains(string(data), "Help.") {
+		t.Errorf("registered agent after regeneration = %q, %v", data, err)
+	}
+	if got := manifestAgents(t, dir); !slices.Contains(got, "./agents/helper.md") {
+		t.Errorf("manifest agents after regeneration = %v, want ./agents/helper.md listed", got)
+	}
+	inventory := decodeInventory(t, model.InventoryJSON.ValueString())
+	if e, ok := inventory["agents/helper.md"]; !ok || e.Source != "agentctx_subagent" {
+		t.Errorf("inventory entry for agents/helper.md = %+v, %v", e, ok)
+	}
+
+	if err := UnregisterAgent(ctx, dir, "helper"); err != nil {
+		t.Fatalf("UnregisterAgent: %v", err)
+	}
+	if _, err := os.Stat(agentPath); !os.IsNotExist(err) {
+		t.Errorf("agent file still present after UnregisterAgent: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(registeredAgentsPath))); !os.IsNotExist(err) {
+		t.Errorf("registry still present after the last agent was removed: %v", err)
+	}
+	if got := manifestAgents(t, dir); slices.Contains(got, "./agents/helper.md") || !slices.Contains(got, "./agents/reviewer.md") {
+		t.Errorf("manifest agents after UnregisterAgent = %v", got)
+	}
+
+	// Removing it again, or from a plugin that is gone, is not an error.
+	if err := UnregisterAgent(ctx, dir, "helper"); err != nil {
+		t.Errorf("second UnregisterAgent: %v", err)
+	}
+	if err := UnregisterAgent(ctx, filepath.Join(t.TempDir(), "gone"), "helper"); err != nil {
+		t.Errorf("UnregisterAgent on a missing plugin: %v", err)
+	}
+}
+
+func TestRegisterAgent_Errors(t *testing.T) {
+	ctx := context.Background()
+	if _, err := RegisterAgent(ctx, t.TempDir(), "helper", []byte("x")); err == nil || !strings.Contains(err.Error(), "holds no plugin") {
+		t.Errorf("RegisterAgent without a plugin = %v, want a holds no plugin error", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "plugin")
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, scaffoldModel(dir)); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if _, err := RegisterAgent(ctx, dir, "reviewer", []byte("x")); err == nil || !strings.Contains(err.Error(), "already has an agent") {
+		t.Errorf("RegisterAgent over a plugin agent = %v, want an already has an agent error", err)
+	}
+}
+
+func TestWritePlugin_AgentCollidesWithRegisteredAgent(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if _, err := RegisterAgent(ctx, dir, "helper", []byte("x")); err != nil {
+		t.Fatalf("RegisterAgent: %v", err)
+	}
+
+	model.Agents = append(model.Agents, PluginAgentModel{Name: stringValue("helper"), SourceFile: model.Agents[0].SourceFile, Content: stringValue("Mine.\n")})
+	diags := r.writePlugin(ctx, model)
+	if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), "helper") {
+		t.Errorf("writePlugin with an agent colliding with a registered one = %v, want an error", diags)
+	}
+}
+
+func TestEditManifestAgents_KeepsFormatting(t *testing.T) {
+	compact := []byte(`{"$schema":"https://example.com/plugin.json","name":"p","agents":["./agents/a.md"]}`)
+	got, err := editManifestAgents(compact, []string{"b"}, []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"$schema":"https://example.com/plugin.json","name":"p","agents":["./agents/b.md"]}`; strings.TrimSpace(string(got)) != want {
+		t.Errorf("editManifestAgents = %s, want %s", got, want)
+	}
+}
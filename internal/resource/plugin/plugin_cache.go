@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/contentcache"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// cachePluginFiles stores every file listed in the model's inventory_json in
+// its cache_dir, if set. The cache only speeds up regeneration, so a failure
+// is a warning.
+func cachePluginFiles(fsDir string, model *PluginResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cacheDir := model.CacheDir.ValueString()
+	if cacheDir == "" {
+		return diags
+	}
+
+	err := func() error {
+		var inventory []inventoryEntry
+		if err := json.Unmarshal([]byte(model.InventoryJSON.ValueString()), &inventory); err != nil {
+			return err
+		}
+		for _, e := range inventory {
+			data, err := os.ReadFile(filepath.Join(fsDir, filepath.FromSlash(e.Path)))
+			if err != nil {
+				return err
+			}
+			if _, err := contentcache.Put(cacheDir, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		diags.AddAttributeWarning(path.Root("cache_dir"), errcode.FileWrite.Summary("Content Cache Write Failed"), fmt.Sprintf("Failed to store the plugin files in the content cache: %s", err))
+	}
+	return diags
+}
+
+// restorePluginFiles writes every file listed in inventoryJSON to fsDir from
+// cacheDir, with the recorded executable bit. It writes nothing and returns
+// false if the inventory is empty or any file is not cached.
+func restorePluginFiles(fsDir, cacheDir, inventoryJSON string) (bool, error) {
+	var inventory []inventoryEntry
+	if err := json.Unmarshal([]byte(inventoryJSON), &inventory); err != nil || len(inventory) == 0 {
+		return false, nil
+	}
+
+	contents := make([][]byte, len(inventory))
+	for i, e := range inventory {
+		data, err := contentcache.Get(cacheDir, e.Hash)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		contents[i] = data
+	}
+
+	for i, e := range inventory {
+		dest := filepath.Join(fsDir, filepath.FromSlash(e.Path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return false, err
+		}
+		perm := os.FileMode(0o644)
+		if e.Executable {
+			perm = 0o755
+		}
+		if err := atomicfile.WriteFile(dest, contents[i], perm); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// regenerate writes the missing plugin of state again. Its files are
+// restored from cache_dir when every file in inventory_json is cached, and
+// generated from the arguments in state otherwise.
+func (r *PluginResource) regenerate(ctx context.Context, state *PluginResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	unlock, d := lockOutputDir(ctx, state.OutputDir.ValueString(), state.LockTimeoutSeconds)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+	defer unlock()
+
+	pluginDir := state.PluginDir.ValueString()
+	source := "generated"
+	if cacheDir := state.CacheDir.ValueString(); cacheDir != "" {
+		restored, err := restorePluginFiles(longpath.Path(pluginDir), cacheDir, state.InventoryJSON.ValueString())
+		if err != nil {
+			tflog.Warn(ctx, "could not restore plugin from the content cache", map[string]interface{}{
+				"cache_dir": cacheDir,
+				"error":     err.Error(),
+			})
+		}
+		if restored {
+			source = "cache"
+		}
+	}
+	if source == "generated" {
+		diags.Append(r.writePlugin(ctx, state)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	tflog.Info(ctx, "regenerated missing plugin", map[string]interface{}{
+		"plugin_dir": pluginDir,
+		"source":     source,
+	})
+	return diags
+}
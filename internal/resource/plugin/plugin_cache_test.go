@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRegenerate_FromCache(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	model.CacheDir = stringValue(t.TempDir())
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	want := decodeInventory(t, model.InventoryJSON.ValueString())
+
+	// A clean workspace: the plugin directory is gone, the cache is not.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := restorePluginFiles(dir, model.CacheDir.ValueString(), model.InventoryJSON.ValueString())
+	if err != nil || !restored {
+		t.Fatalf("restorePluginFiles = %v, %v; want true, nil", restored, err)
+	}
+
+	got, err := buildInventory(dir, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, e := range decodeInventory(t, got) {
+		w := want[p]
+		if e.Hash != w.Hash || e.Executable != w.Executable {
+			t.Errorf("%s restored as %+v, want %+v", p, e, w)
+		}
+	}
+	if len(decodeInventory(t, got)) != len(want) {
+		t.Errorf("restored %s, want %d files", got, len(want))
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "scripts", "format.sh"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()&0o111 == 0 {
+			t.Error("scripts/format.sh restored without its executable bit")
+		}
+	}
+}
+
+func TestRegenerate_GeneratesOnCacheMiss(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cache was configured after the last apply, so it is empty.
+	model.CacheDir = stringValue(t.TempDir())
+	if restored, err := restorePluginFiles(dir, model.CacheDir.ValueString(), model.InventoryJSON.ValueString()); err != nil || restored {
+		t.Fatalf("restorePluginFiles = %v, %v; want false, nil", restored, err)
+	}
+
+	if diags := r.regenerate(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".claude-plugin", "plugin.json")); err != nil {
+		t.Errorf("plugin.json not regenerated: %s", err)
+	}
+	// Regeneration fills the cache for the next clean workspace.
+	restored, err := restorePluginFiles(t.TempDir(), model.CacheDir.ValueString(), model.InventoryJSON.ValueString())
+	if err != nil || !restored {
+		t.Errorf("cache not filled by regeneration: %v, %v", restored, err)
+	}
+}
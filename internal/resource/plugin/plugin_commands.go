@@ -0,0 +1,59 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/command"
+)
+
+// commandFrontmatterAttributes are the attributes of a command block that
+// are written to the YAML frontmatter of its file.
+var commandFrontmatterAttributes = []string{"description", "argument_hint", "allowed_tools", "model", "disable_model_invocation"}
+
+// commandFrontmatterBlock returns c as a frontmatterBlock. Its content is
+// the command prompt; every frontmatter attribute is optional.
+func commandFrontmatterBlock(ctx context.Context, c PluginCommandModel) frontmatterBlock {
+	b := frontmatterBlock{
+		kind:              "command",
+		name:              c.Name.ValueString(),
+		content:           c.Content,
+		set:               setAttributes(commandFrontmatterAttributes, c.Description, c.ArgumentHint, c.AllowedTools, c.Model, c.DisableModelInvocation),
+		sourceFrontmatter: "the source file",
+		render: func(body string) (string, error) {
+			fm, err := commandFrontmatter(ctx, c)
+			if err != nil {
+				return "", err
+			}
+			content, err := command.RenderFile(fm, body)
+			if err != nil {
+				return "", fmt.Errorf("marshaling frontmatter: %w", err)
+			}
+			return content, nil
+		},
+	}
+	if !c.SourceFile.IsNull() {
+		b.source = "source_file"
+	}
+	return b
+}
+
+// commandFrontmatter returns the frontmatter of c, which is empty when c
+// sets none of the frontmatter attributes.
+func commandFrontmatter(ctx context.Context, c PluginCommandModel) (command.Frontmatter, error) {
+	fm := command.Frontmatter{
+		Description:            c.Description.ValueString(),
+		ArgumentHint:           c.ArgumentHint.ValueString(),
+		Model:                  c.Model.ValueString(),
+		DisableModelInvocation: c.DisableModelInvocation.ValueBool(),
+	}
+	if !c.AllowedTools.IsNull() && !c.AllowedTools.IsUnknown() {
+		var tools []string
+		if d := c.AllowedTools.ElementsAs(ctx, &tools, false); d.HasError() {
+			return fm, fmt.Errorf("reading allowed_tools of command %q", c.Name.ValueString())
+		}
+		fm.AllowedTools = strings.Join(tools, ", ")
+	}
+	return fm, nil
+}
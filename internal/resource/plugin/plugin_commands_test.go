@@ -0,0 +1,80 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestWritePlugin_CommandFrontmatter(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+
+	model := scaffoldModel(dir)
+	model.VarsFile = stringValue(writeVarsFile(t, "prod.yaml", "env: prod\n"))
+	model.Commands = []PluginCommandModel{
+		{
+			Name:         stringValue("deploy"),
+			SourceFile:   types.StringNull(),
+			Content:      stringValue("\nDeploy $1 to {{ .env }}.\n"),
+			Description:  stringValue("Deploy a service"),
+			ArgumentHint: stringValue("[service]"),
+			AllowedTools: types.ListValueMust(types.StringType, []attr.Value{
+				types.StringValue("Bash(kubectl apply:*)"),
+				types.StringValue("Read"),
+			}),
+			Model:                  stringValue("claude-haiku-4-5"),
+			DisableModelInvocation: types.BoolValue(true),
+		},
+		{
+			// No description or other attribute: the file is content verbatim.
+			Name:       stringValue("status"),
+			SourceFile: types.StringNull(),
+			Content:    stringValue("---\ndescription: Show status\n---\n\nShow the status.\n"),
+		},
+	}
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	for rel, want := range map[string]string{
+		"commands/deploy.md": "---\n" +
+			"description: Deploy a service\n" +
+			"argument-hint: '[service]'\n" +
+			"allowed-tools: Bash(kubectl apply:*), Read\n" +
+			"model: claude-haiku-4-5\n" +
+			"disable-model-invocation: true\n" +
+			"---\n\n" +
+			"Deploy $1 to prod.\n",
+		"commands/status.md": "---\ndescription: Show status\n---\n\nShow the status.\n",
+	} {
+		if data, err := os.ReadFile(filepath.Join(dir, rel)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", rel, data, err, want)
+		}
+	}
+}
+
+func TestValidateCommandFrontmatter(t *testing.T) {
+	model := scaffoldModel(filepath.Join(t.TempDir(), "plugin"))
+	model.Commands = []PluginCommandModel{
+		{Name: stringValue("inline"), SourceFile: types.StringNull(), Content: stringValue("Hi."), Description: stringValue("ok")},
+		{
+			Name:                   stringValue("copied"),
+			SourceFile:             stringValue("copied.md"),
+			Content:                types.StringNull(),
+			Description:            stringValue("not ok"),
+			DisableModelInvocation: types.BoolValue(false),
+		},
+	}
+
+	diags := validateFrontmatter(context.Background(), model)
+	if diags.ErrorsCount() != 2 {
+		t.Fatalf("got %d errors, want one each for description and disable_model_invocation: %v", diags.ErrorsCount(), diags)
+	}
+}
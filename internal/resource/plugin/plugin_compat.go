@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// compatFilter decides which generated features the effective
+// compatibility_level allows, and records the ones it leaves out so that
+// writePlugin can name them in a single warning.
+type compatFilter struct {
+	level   *claudeversion.Level
+	omitted []claudeversion.Feature
+}
+
+// omits reports whether feature must be left out, recording it if so. known
+// is false for features with no known minimum version, which are always
+// emitted; it lets callers pass the result of a claudeversion lookup as is.
+func (f *compatFilter) omits(feature claudeversion.Feature, known bool) bool {
+	if !known || f.level.Allows(feature) {
+		return false
+	}
+	f.omitted = append(f.omitted, feature)
+	return true
+}
+
+// filterHookEvents removes the events the level does not allow from a
+// buildHooksJSON result.
+func (f *compatFilter) filterHookEvents(hooksConfig map[string]interface{}) {
+	events := make([]string, 0, len(hooksConfig))
+	for event := range hooksConfig {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	for _, event := range events {
+		if f.omits(claudeversion.HookEventFeature(event)) {
+			delete(hooksConfig, event)
+		}
+	}
+}
+
+// filterMcpServers removes the servers using url transport from a
+// buildMcpJSON result when the level predates it. A remote server without
+// its url would fail to start, so the whole entry goes.
+func (f *compatFilter) filterMcpServers(mcpConfig map[string]interface{}) {
+	urlFeature, known := claudeversion.McpFieldFeature("url")
+	if !known {
+		return
+	}
+	names := make([]string, 0, len(mcpConfig))
+	for name := range mcpConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entry, _ := mcpConfig[name].(map[string]interface{})
+		if _, ok := entry["url"]; !ok {
+			continue
+		}
+		feature := claudeversion.Feature{
+			Name:       fmt.Sprintf("MCP server %q (url transport)", name),
+			MinVersion: urlFeature.MinVersion,
+		}
+		if f.omits(feature, true) {
+			delete(mcpConfig, name)
+		}
+	}
+}
+
+// diagnostics returns a warning naming the omitted features, if any. It has
+// no attribute path: the level may be the provider's.
+func (f *compatFilter) diagnostics() diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(f.omitted) == 0 {
+		return diags
+	}
+	diags.AddWarning(
+		errcode.ClaudeVersion.Summary("Features Omitted For Compatibility Level"),
+		fmt.Sprintf("compatibility_level %s predates %s. These were left out of the generated plugin; "+
+			"raise compatibility_level once every Claude Code installation is at least %s to emit them.",
+			f.level, describeFeatures(f.omitted), claudeversion.MinVersion(f.omitted)),
+	)
+	return diags
+}
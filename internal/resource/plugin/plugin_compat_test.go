@@ -0,0 +1,139 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func compatTestModel(dir string) *PluginResourceModel {
+	extMap, _ := types.MapValueFrom(context.Background(), types.StringType, map[string]string{".go": "go"})
+	hook := []PluginHookMatcherModel{{
+		Matcher: types.StringNull(),
+		Hooks:   []PluginHookEntryModel{{Type: stringValue("command"), Command: stringValue("echo hi")}},
+	}}
+	return &PluginResourceModel{
+		Name:      stringValue("compat-plugin"),
+		OutputDir: stringValue(dir),
+		Keywords:  types.ListNull(types.StringType),
+		Hooks: []PluginHooksModel{{
+			PreToolUse:        hook,
+			PermissionRequest: hook,
+		}},
+		McpServers: []PluginMcpModel{
+			{Name: stringValue("local"), Command: stringValue("db-server"), Args: types.ListNull(types.StringType), Env: types.MapNull(types.StringType), URL: types.StringNull(), Cwd: types.StringNull()},
+			{Name: stringValue("remote"), Command: types.StringNull(), Args: types.ListNull(types.StringType), Env: types.MapNull(types.StringType), URL: stringValue("https://mcp.example.com"), Cwd: types.StringNull()},
+		},
+		LspServers: []PluginLspModel{{
+			Name:                  stringValue("go"),
+			Command:               stringValue("gopls"),
+			Args:                  types.ListNull(types.StringType),
+			Env:                   types.MapNull(types.StringType),
+			InitializationOptions: types.MapNull(types.StringType),
+			Settings:              types.MapNull(types.StringType),
+			ExtensionToLanguage:   extMap,
+		}},
+	}
+}
+
+func readJSONFile(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON in %s: %v", path, err)
+	}
+	return v
+}
+
+func TestWritePlugin_CompatibilityLevelOmitsNewerFeatures(t *testing.T) {
+	level, err := claudeversion.ParseLevel("1.0.20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &PluginResource{providerData: &providerdata.ProviderData{CompatibilityLevel: level}}
+	dir := filepath.Join(t.TempDir(), "compat-plugin")
+	model := compatTestModel(dir)
+
+	diags := r.writePlugin(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("warnings = %d, want 1: %v", diags.WarningsCount(), diags)
+	}
+	detail := diags.Warnings()[0].Detail()
+	for _, want := range []string{"PermissionRequest hook event", "lspServers plugin manifest field", `MCP server "remote"`} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("warning does not name %s: %s", want, detail)
+		}
+	}
+
+	hooks := readJSONFile(t, filepath.Join(dir, "hooks", "hooks.json"))["hooks"].(map[string]interface{})
+	if _, ok := hooks["PermissionRequest"]; ok {
+		t.Error("PermissionRequest emitted below its minimum version")
+	}
+	if _, ok := hooks["PreToolUse"]; !ok {
+		t.Error("PreToolUse dropped")
+	}
+
+	servers := readJSONFile(t, filepath.Join(dir, ".mcp.json"))["mcpServers"].(map[string]interface{})
+	if _, ok := servers["remote"]; ok {
+		t.Error("url MCP server emitted at a level that predates it, want it omitted")
+	}
+	if _, ok := servers["local"]; !ok {
+		t.Error("command MCP server dropped")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".lsp.json")); !os.IsNotExist(err) {
+		t.Errorf(".lsp.json written at a level that predates it (stat err %v)", err)
+	}
+	manifest := readJSONFile(t, filepath.Join(dir, ".claude-plugin", "plugin.json"))
+	if _, ok := manifest["lspServers"]; ok {
+		t.Error("manifest references lspServers")
+	}
+}
+
+func TestWritePlugin_CompatibilityLevelOverride(t *testing.T) {
+	old, _ := claudeversion.ParseLevel("1.0.0")
+	r := &PluginResource{providerData: &providerdata.ProviderData{CompatibilityLevel: old}}
+	dir := filepath.Join(t.TempDir(), "compat-plugin")
+	model := compatTestModel(dir)
+	model.CompatibilityLevel = stringValue("2.1.0")
+
+	diags := r.writePlugin(context.Background(), model)
+	if diags.HasError() || diags.WarningsCount() != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	hooks := readJSONFile(t, filepath.Join(dir, "hooks", "hooks.json"))["hooks"].(map[string]interface{})
+	if _, ok := hooks["PermissionRequest"]; !ok {
+		t.Error("PermissionRequest omitted despite the resource's newer compatibility_level")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".lsp.json")); err != nil {
+		t.Errorf(".lsp.json not written: %v", err)
+	}
+}
+
+func TestWritePlugin_NoCompatibilityLevelEmitsEverything(t *testing.T) {
+	r := &PluginResource{}
+	dir := filepath.Join(t.TempDir(), "compat-plugin")
+
+	diags := r.writePlugin(context.Background(), compatTestModel(dir))
+	if diags.HasError() || diags.WarningsCount() != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	servers := readJSONFile(t, filepath.Join(dir, ".mcp.json"))["mcpServers"].(map[string]interface{})
+	if len(servers) != 2 {
+		t.Errorf("mcpServers = %v, want both servers", servers)
+	}
+}
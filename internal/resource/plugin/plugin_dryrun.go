@@ -0,0 +1,184 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// dryRunPlugin records the files writing model would change in the dry-run
+// report. The plugin is generated into a temporary directory and compared
+// with output_dir: generated files are created, modified, or unchanged, and
+// files under the managed paths that are not generated again, or file
+// blocks of prior that were removed, are deleted. prior is nil for creates.
+func (r *PluginResource) dryRunPlugin(ctx context.Context, operation string, model PluginResourceModel, prior *PluginResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	absDir, err := filepath.Abs(model.OutputDir.ValueString())
+	if err != nil {
+		diags.AddError(errcode.PathResolution.Summary("Path Resolution Failed"), fmt.Sprintf("Failed to resolve output_dir %q: %s", model.OutputDir.ValueString(), err))
+		return diags
+	}
+
+	stageDir, err := os.MkdirTemp("", "agentctx-plugin-dry-run-")
+	if err != nil {
+		diags.AddError(errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create a directory to stage the plugin in: %s", err))
+		return diags
+	}
+	defer os.RemoveAll(stageDir)
+
+	// Registered sub-agents are carried over as writePlugin carries them
+	// over in output_dir.
+	registered, err := loadRegisteredAgents(longpath.Path(absDir))
+	if err == nil {
+		err = restoreRegisteredAgents(stageDir, registered)
+	}
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read the sub-agents registered in %q: %s", absDir, err))
+		return diags
+	}
+
+	staged := model
+	staged.OutputDir = types.StringValue(stageDir)
+	staged.CacheDir = types.StringNull()
+	diags.Append(r.writePlugin(ctx, &staged)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	var stale []string
+	if prior != nil {
+		stale = removedFilePaths(prior.Files, model.Files)
+	}
+	files, err := dryRunFiles(absDir, stageDir, stale)
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to compare the plugin with %q: %s", absDir, err))
+		return diags
+	}
+
+	id := absDir
+	if prior != nil {
+		id = prior.ID.ValueString()
+	}
+	diags.Append(r.providerData.RecordDryRun(dryrun.Change{
+		Resource:  "agentctx_plugin",
+		Operation: operation,
+		ID:        id,
+		Files:     files,
+	})...)
+	return diags
+}
+
+// dryRunFiles compares the plugin generated in stageDir with pluginDir. It
+// returns a write for every generated file, then a delete for every file in
+// pluginDir under the managed paths, or listed in stale, that is not
+// generated again, sorted by path.
+func dryRunFiles(pluginDir, stageDir string, stale []string) ([]dryrun.FileChange, error) {
+	generated, err := listFiles(stageDir, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []dryrun.FileChange
+	written := make(map[string]bool, len(generated))
+	for _, relPath := range generated {
+		content, err := os.ReadFile(filepath.Join(stageDir, filepath.FromSlash(relPath)))
+		if err != nil {
+			return nil, err
+		}
+		written[relPath] = true
+		changes = append(changes, dryrun.WriteFile(filepath.Join(pluginDir, filepath.FromSlash(relPath)), content))
+	}
+
+	existing := append([]string{}, stale...)
+	for _, p := range []string{".claude-plugin", "skills", "agents", "commands", "hooks", ".mcp.json", ".lsp.json", testScriptPath} {
+		files, err := listFiles(pluginDir, p)
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, files...)
+	}
+	sort.Strings(existing)
+	for i, relPath := range existing {
+		if written[relPath] || (i > 0 && existing[i-1] == relPath) {
+			continue
+		}
+		if _, err := os.Lstat(longpath.Path(filepath.Join(pluginDir, filepath.FromSlash(relPath)))); err != nil {
+			continue
+		}
+		changes = append(changes, dryrun.RemoveFile(filepath.Join(pluginDir, filepath.FromSlash(relPath))))
+	}
+	return changes, nil
+}
+
+// dryRunDelete returns a delete for every file in pluginDir, which Delete
+// removes with the directory.
+func dryRunDelete(pluginDir string) ([]dryrun.FileChange, error) {
+	files, err := listFiles(pluginDir, ".")
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]dryrun.FileChange, 0, len(files))
+	for _, relPath := range files {
+		if relPath == lockFileName {
+			continue
+		}
+		changes = append(changes, dryrun.RemoveFile(filepath.Join(pluginDir, filepath.FromSlash(relPath))))
+	}
+	return changes, nil
+}
+
+// listFiles returns the slash-separated paths, relative to root, of the
+// files at rel under root, sorted. A rel that does not exist yields none.
+func listFiles(root, rel string) ([]string, error) {
+	var files []string
+	fsRoot := longpath.Path(root)
+	err := filepath.WalkDir(filepath.Join(fsRoot, filepath.FromSlash(rel)), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(fsRoot, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// removedFilePaths returns the paths of the file blocks in previous that
+// are not in next, as Update removes them.
+func removedFilePaths(previous, next []PluginFileModel) []string {
+	nextPaths := make(map[string]bool, len(next))
+	for _, f := range next {
+		nextPaths[filepath.ToSlash(f.Path.ValueString())] = true
+	}
+	var removed []string
+	for _, f := range previous {
+		relPath := filepath.ToSlash(f.Path.ValueString())
+		if f.Path.IsNull() || f.Path.IsUnknown() || nextPaths[relPath] || filepath.IsAbs(relPath) || strings.Contains(relPath, "..") {
+			continue
+		}
+		removed = append(removed, relPath)
+	}
+	return removed
+}
@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+)
+
+func TestDryRunFiles(t *testing.T) {
+	ctx := context.Background()
+	pluginDir := filepath.Join(t.TempDir(), "plugin")
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, scaffoldModel(pluginDir)); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	// Change an agent, drop the skill, and stage the result.
+	model := scaffoldModel("")
+	model.Agents[0].Content = stringValue("You review code carefully.\n")
+	model.Skills = nil
+	stageDir := t.TempDir()
+	model.OutputDir = stringValue(stageDir)
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	changes, err := dryRunFiles(pluginDir, stageDir, []string{"scripts/old.sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := make(map[string]string, len(changes))
+	for _, c := range changes {
+		rel, _ := filepath.Rel(pluginDir, c.Path)
+		actions[filepath.ToSlash(rel)] = c.Action
+	}
+	for relPath, want := range map[string]string{
+		"agents/reviewer.md":   dryrun.FileModify,
+		"scripts/format.sh":    dryrun.FileUnchanged,
+		"skills/lint/SKILL.md": dryrun.FileDelete,
+	} {
+		if actions[relPath] != want {
+			t.Errorf("%s: action = %q, want %q", relPath, actions[relPath], want)
+		}
+	}
+	// A removed file block that is already gone is not reported.
+	if _, ok := actions["scripts/old.sh"]; ok {
+		t.Error("missing stale file reported as deleted")
+	}
+
+	if _, err := os.Stat(filepath.Join(pluginDir, "skills", "lint", "SKILL.md")); err != nil {
+		t.Errorf("dry run changed the plugin directory: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// frontmatterBlock is a skill, agent, or command block seen through the
+// attributes it writes to the YAML frontmatter of its Markdown file. The
+// kinds differ only in these fields; see skillFrontmatterBlock,
+// agentFrontmatterBlock, and commandFrontmatterBlock.
+type frontmatterBlock struct {
+	// kind is the block type, e.g. "command".
+	kind    string
+	name    string
+	content types.String
+	// set lists the frontmatter attributes the block sets, in schema
+	// order. Unknown values count as set.
+	set []string
+	// source is the source attribute the block copies its file from
+	// instead of setting content, or "" when it sets none.
+	source string
+	// sourceFrontmatter says where a copied file keeps its frontmatter.
+	sourceFrontmatter string
+	// required is the attribute every other frontmatter attribute needs,
+	// or "" when there is none, and requiredReason says why.
+	required       string
+	requiredReason string
+	// render returns the file of body after the generated frontmatter.
+	render func(body string) (string, error)
+}
+
+// setAttributes returns the names whose values are not null. names and
+// values are in the same order.
+func setAttributes(names []string, values ...attr.Value) []string {
+	var set []string
+	for i, v := range values {
+		if !v.IsNull() {
+			set = append(set, names[i])
+		}
+	}
+	return set
+}
+
+// writeFrontmatterBlock renders the content of b with the variables and
+// writes it to dst, under generated frontmatter when b sets any of its
+// frontmatter attributes.
+func (v *pluginVars) writeFrontmatterBlock(b frontmatterBlock, dst string) error {
+	content, err := v.render(b.kind+" "+b.name, b.content.ValueString())
+	if err != nil {
+		return err
+	}
+	if len(b.set) > 0 {
+		if content, err = b.render(content); err != nil {
+			return err
+		}
+	}
+	return atomicfile.WriteFile(dst, []byte(content), 0o644)
+}
+
+// validate checks the frontmatter attributes of b, the block at blockPath:
+// they may not be combined with a copied source, they need b.required, and
+// content given with them may not start with frontmatter of its own, which
+// would end up in the body below the generated one.
+func (b frontmatterBlock) validate(blockPath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(b.set) == 0 {
+		return diags
+	}
+	kind := strings.ToUpper(b.kind[:1]) + b.kind[1:]
+	summary := errcode.InvalidConfig.Summary("Invalid " + kind + " Configuration")
+
+	if b.source != "" {
+		for _, attr := range b.set {
+			diags.AddAttributeError(blockPath.AtName(attr), summary,
+				fmt.Sprintf("%s %q sets %s with %s. Frontmatter attributes require content; write the frontmatter into %s instead.",
+					kind, b.name, attr, b.source, b.sourceFrontmatter))
+		}
+		return diags
+	}
+	if b.required != "" && !slices.Contains(b.set, b.required) {
+		diags.AddAttributeError(blockPath.AtName(b.required), summary,
+			fmt.Sprintf("%s %q sets %s without %s. %s", kind, b.name, strings.Join(b.set, ", "), b.required, b.requiredReason))
+	}
+	if hasNonEmptyString(b.content) {
+		if fm, err := bundle.ParseSkillFrontmatter([]byte(b.content.ValueString())); fm != nil || err != nil {
+			diags.AddAttributeError(blockPath.AtName("content"), summary,
+				fmt.Sprintf("%s %q sets frontmatter attributes, but its content starts with frontmatter of its own. "+
+					"Remove the frontmatter from content, or the frontmatter attributes from the %s block.", kind, b.name, b.kind))
+		}
+	}
+	return diags
+}
+
+// validateFrontmatter validates the frontmatter attributes of every skill,
+// agent, and command block of model.
+func validateFrontmatter(ctx context.Context, model *PluginResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i, s := range model.Skills {
+		diags.Append(skillFrontmatterBlock(ctx, s).validate(path.Root("skill").AtListIndex(i))...)
+	}
+	for i, a := range model.Agents {
+		diags.Append(agentFrontmatterBlock(ctx, a).validate(path.Root("agent").AtListIndex(i))...)
+	}
+	for i, c := range model.Commands {
+		diags.Append(commandFrontmatterBlock(ctx, c).validate(path.Root("command").AtListIndex(i))...)
+	}
+	return diags
+}
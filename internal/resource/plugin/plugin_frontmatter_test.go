@@ -0,0 +1,33 @@
+package plugin
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestSetAttributes(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	got := setAttributes(names, types.StringValue("x"), types.StringNull(), types.BoolUnknown())
+	if strings.Join(got, ",") != "a,c" {
+		t.Errorf("setAttributes = %v, want [a c]", got)
+	}
+}
+
+func TestValidateFrontmatter_ContentWithFrontmatter(t *testing.T) {
+	model := scaffoldModel(filepath.Join(t.TempDir(), "plugin"))
+	model.Commands = []PluginCommandModel{{
+		Name:        stringValue("deploy"),
+		SourceFile:  types.StringNull(),
+		Content:     stringValue("---\nmodel: claude-haiku-4-5\n---\n\nDeploy.\n"),
+		Description: stringValue("Deploy a service"),
+	}}
+
+	diags := validateFrontmatter(context.Background(), model)
+	if diags.ErrorsCount() != 1 || !strings.Contains(diags.Errors()[0].Detail(), "starts with frontmatter of its own") {
+		t.Fatalf("diags = %v, want one error for the frontmatter in content", diags)
+	}
+}
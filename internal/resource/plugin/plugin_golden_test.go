@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/golden"
+)
+
+// Golden tests render representative plugin configurations and compare every
+// generated file with testdata/golden/<case>/. Run with
+// AGENTCTX_UPDATE_GOLDEN=1 to accept intentional output changes.
+
+func TestGolden_MinimalPlugin(t *testing.T) {
+	assertGoldenPlugin(t, "minimal", func(dir string) *PluginResourceModel {
+		return &PluginResourceModel{
+			Name:      stringValue("minimal"),
+			OutputDir: stringValue(dir),
+			Keywords:  types.ListNull(types.StringType),
+		}
+	})
+}
+
+func TestGolden_FullPlugin(t *testing.T) {
+	ctx := context.Background()
+	keywords, _ := types.ListValueFrom(ctx, types.StringType, []string{"deployment", "ci-cd"})
+	mcpArgs, _ := types.ListValueFrom(ctx, types.StringType, []string{"--port", "8080"})
+	mcpEnv, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{"REGION": "us-east-1", "LOG_LEVEL": "info"})
+	lspArgs, _ := types.ListValueFrom(ctx, types.StringType, []string{"serve"})
+	extMap, _ := types.MapValueFrom(ctx, types.StringType, map[string]string{".go": "go", ".mod": "go.mod"})
+
+	hook := func(command string) []PluginHookEntryModel {
+		return []PluginHookEntryModel{{Type: stringValue("command"), Command: stringValue(command)}}
+	}
+
+	assertGoldenPlugin(t, "full", func(dir string) *PluginResourceModel {
+		return &PluginResourceModel{
+			Name:                  stringValue("enterprise-tools"),
+			OutputDir:             stringValue(dir),
+			Version:               stringValue("2.1.0"),
+			Description:           stringValue("Enterprise deployment automation tools"),
+			Homepage:              stringValue("https://docs.example.com"),
+			Repository:            stringValue("https://github.com/example/enterprise-tools"),
+			License:               stringValue("MIT"),
+			Keywords:              keywords,
+			RequiresClaudeVersion: stringValue(">= 2.0.45"),
+			Author: []AuthorModel{
+				{Name: stringValue("Dev Team"), Email: stringValue("dev@example.com"), URL: types.StringNull()},
+			},
+			OutputStyles: []PluginOutputStyleModel{{Path: stringValue("styles/terse.md")}},
+			Skills: []PluginSkillModel{
+				{
+					Name:      stringValue("code-reviewer"),
+					SourceDir: types.StringNull(),
+					Content:   stringValue("# Code Reviewer\n\nReview code for best practices.\n"),
+				},
+			},
+			Agents: []PluginAgentModel{
+				{
+					Name:       stringValue("security-checker"),
+					SourceFile: types.StringNull(),
+					Content:    stringValue("---\nname: security-checker\ndescription: Reviews code for security\n---\n\nYou are a security specialist.\n"),
+				},
+			},
+			Commands: []PluginCommandModel{
+				{Name: stringValue("status"), SourceFile: types.StringNull(), Content: stringValue("Show deployment status.\n")},
+			},
+			Hooks: []PluginHooksModel{
+				{
+					PreToolUse: []PluginHookMatcherModel{
+						{Matcher: stringValue("Bash"), Order: types.Int64Value(2), Hooks: hook("./scripts/audit.sh")},
+						{Matcher: stringValue("Bash"), Order: types.Int64Value(1), Hooks: hook("./scripts/guard.sh")},
+					},
+					PostToolUse: []PluginHookMatcherModel{
+						{Matcher: stringValue("Write|Edit"), Order: types.Int64Null(), Hooks: hook("${CLAUDE_PLUGIN_ROOT}/scripts/lint.sh")},
+					},
+					SessionStart: []PluginHookMatcherModel{
+						{Matcher: types.StringNull(), Order: types.Int64Null(), Hooks: hook("./scripts/setup.sh")},
+					},
+				},
+			},
+			McpServers: []PluginMcpModel{
+				{
+					Name:    stringValue("deploy-server"),
+					Command: stringValue("${CLAUDE_PLUGIN_ROOT}/servers/deploy"),
+					Args:    mcpArgs,
+					Env:     mcpEnv,
+					URL:     types.StringNull(),
+					Cwd:     types.StringNull(),
+				},
+				{
+					Name:    stringValue("docs"),
+					Command: types.StringNull(),
+					Args:    types.ListNull(types.StringType),
+					Env:     types.MapNull(types.StringType),
+					URL:     stringValue("https://mcp.example.com/docs"),
+					Cwd:     types.StringNull(),
+				},
+			},
+			LspServers: []PluginLspModel{
+				{
+					Name:                  stringValue("go"),
+					Command:               stringValue("gopls"),
+					Args:                  lspArgs,
+					Transport:             types.StringNull(),
+					Env:                   types.MapNull(types.StringType),
+					InitializationOptions: types.MapNull(types.StringType),
+					Settings:              types.MapNull(types.StringType),
+					ExtensionToLanguage:   extMap,
+					WorkspaceFolder:       stringValue("/workspace"),
+					StartupTimeout:        types.Int64Value(5000),
+					ShutdownTimeout:       types.Int64Value(3000),
+					RestartOnCrash:        types.BoolValue(true),
+					MaxRestarts:           types.Int64Value(5),
+				},
+			},
+			Files: []PluginFileModel{
+				{
+					Path:       stringValue("scripts/lint.sh"),
+					Content:    stringValue("#!/bin/bash\necho 'linting'\n"),
+					SourceFile: types.StringNull(),
+					Executable: types.BoolValue(true),
+				},
+				{
+					Path:       stringValue("styles/terse.md"),
+					Content:    stringValue("Answer in one sentence.\n"),
+					SourceFile: types.StringNull(),
+					Executable: types.BoolValue(false),
+				},
+			},
+		}
+	})
+}
+
+func assertGoldenPlugin(t *testing.T, name string, build func(dir string) *PluginResourceModel) {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "plugin")
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), build(dir)); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	golden.AssertDir(t, filepath.Join("testdata", "golden", name), dir)
+}
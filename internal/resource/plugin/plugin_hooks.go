@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// ValidateHookAgents checks the hooks that refer to a plugin agent with
+// agent: they must be of type "agent" and name an agent {} block of the
+// plugin, so that hooks.json never runs an agent the plugin does not ship.
+// Names are only checked once every agent block name is known. It is also
+// used by the agentctx_render_plugin_manifest data source.
+func ValidateHookAgents(model *PluginResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	agents := make(map[string]bool, len(model.Agents))
+	namesKnown := true
+	for _, a := range model.Agents {
+		if a.Name.IsUnknown() {
+			namesKnown = false
+			continue
+		}
+		agents[a.Name.ValueString()] = true
+	}
+
+	for hi, hooks := range model.Hooks {
+		for _, event := range hookEventBlocks(hooks) {
+			for mi, m := range event.matchers {
+				for ei, h := range m.Hooks {
+					if h.Agent.IsNull() || h.Agent.IsUnknown() {
+						continue
+					}
+					p := path.Root("hooks").AtListIndex(hi).AtName(event.attr).AtListIndex(mi).AtName("hook").AtListIndex(ei)
+					name := h.Agent.ValueString()
+					if !h.Type.IsUnknown() && h.Type.ValueString() != "agent" {
+						diags.AddAttributeError(p.AtName("agent"), errcode.InvalidConfig.Summary("Invalid Hook Configuration"),
+							fmt.Sprintf("agent is only allowed on hooks of type \"agent\", not %q. Use command for %s hooks.", h.Type.ValueString(), h.Type.ValueString()))
+						continue
+					}
+					if namesKnown && !agents[name] {
+						diags.AddAttributeError(p.AtName("agent"), errcode.InvalidConfig.Summary("Unknown Hook Agent"),
+							fmt.Sprintf("Hook agent %q does not match an agent block of this plugin. %s", name, agentNamesHint(agents)))
+					}
+				}
+			}
+		}
+	}
+	return diags
+}
+
+// agentNamesHint lists the agent block names a hook may refer to.
+func agentNamesHint(agents map[string]bool) string {
+	if len(agents) == 0 {
+		return "The plugin defines no agent blocks."
+	}
+	names := make([]string, 0, len(agents))
+	for name := range agents {
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	sort.Strings(names)
+	return "Defined agents: " + strings.Join(names, ", ") + "."
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func agentHooks(hookType, agent string) []PluginHooksModel {
+	return []PluginHooksModel{{
+		SubagentStop: []PluginHookMatcherModel{{
+			Matcher: stringValue(".*"),
+			Hooks:   []PluginHookEntryModel{{Type: stringValue(hookType), Command: types.StringNull(), Agent: stringValue(agent)}},
+		}},
+	}}
+}
+
+func TestValidateHookAgents(t *testing.T) {
+	agents := []PluginAgentModel{{Name: stringValue("reviewer")}, {Name: stringValue("fixer")}}
+
+	cases := []struct {
+		name    string
+		model   PluginResourceModel
+		wantErr string
+	}{
+		{
+			name:  "known agent",
+			model: PluginResourceModel{Agents: agents, Hooks: agentHooks("agent", "reviewer")},
+		},
+		{
+			name:  "command hooks only",
+			model: PluginResourceModel{Hooks: commandHooks("echo hi")},
+		},
+		{
+			name:    "unknown agent",
+			model:   PluginResourceModel{Agents: agents, Hooks: agentHooks("agent", "linter")},
+			wantErr: `"fixer", "reviewer"`,
+		},
+		{
+			name:    "no agent blocks",
+			model:   PluginResourceModel{Hooks: agentHooks("agent", "reviewer")},
+			wantErr: "no agent blocks",
+		},
+		{
+			name:    "agent on a command hook",
+			model:   PluginResourceModel{Agents: agents, Hooks: agentHooks("command", "reviewer")},
+			wantErr: `type "agent"`,
+		},
+		{
+			name: "unknown agent block name",
+			model: PluginResourceModel{
+				Agents: []PluginAgentModel{{Name: types.StringUnknown()}},
+				Hooks:  agentHooks("agent", "reviewer"),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := ValidateHookAgents(&tc.model)
+			if tc.wantErr == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected errors: %v", diags)
+				}
+				return
+			}
+			if diags.ErrorsCount() != 1 {
+				t.Fatalf("got %d errors, want 1: %v", diags.ErrorsCount(), diags)
+			}
+			if detail := diags.Errors()[0].Detail(); !strings.Contains(detail, tc.wantErr) {
+				t.Errorf("error detail %q does not contain %q", detail, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildHooksJSON_AgentHook(t *testing.T) {
+	r := &PluginResource{}
+	result := r.buildHooksJSON(agentHooks("agent", "reviewer")[0])
+
+	entries := result["SubagentStop"].([]map[string]interface{})
+	hook := entries[0]["hooks"].([]map[string]interface{})[0]
+	if hook["type"] != "agent" || hook["agent"] != "reviewer" {
+		t.Errorf("hook = %v, want type agent referencing reviewer", hook)
+	}
+	if _, ok := hook["command"]; ok {
+		t.Errorf("agent hook must not have a command: %v", hook)
+	}
+}
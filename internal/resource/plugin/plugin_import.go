@@ -0,0 +1,658 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+var _ resource.ResourceWithImportState = &PluginResource{}
+
+// ImportState adopts an existing plugin directory. The import ID is the
+// plugin's output_dir, written as it appears in configuration. The
+// arguments are reconstructed from the files on disk by importPlugin; the
+// Read that follows the import fills in the computed attributes.
+func (r *PluginResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	model, diags := importPlugin(ctx, req.ID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "imported plugin", map[string]interface{}{
+		"name":       model.Name.ValueString(),
+		"plugin_dir": model.PluginDir.ValueString(),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
+}
+
+// importManifestKeys are the plugin.json properties importPlugin turns into
+// arguments. Others are reported, since the next apply drops them.
+var importManifestKeys = map[string]bool{
+	"$schema": true, "name": true, "version": true, "description": true, "author": true,
+	"homepage": true, "repository": true, "license": true, "keywords": true,
+	"requiresClaudeVersion": true, "outputStyles": true, "commands": true, "agents": true,
+	"skills": true, "hooks": true, "mcpServers": true, "lspServers": true,
+}
+
+// importer accumulates the state of one importPlugin call.
+type importer struct {
+	fsDir string
+	// claimed holds the slash-separated paths that an argument other than
+	// a file block reproduces.
+	claimed map[string]bool
+	// dropped lists the settings no argument can hold.
+	dropped []string
+	// schemas collects the "$schema" property of each generated JSON file.
+	schemas map[string]string
+}
+
+// importPlugin reconstructs the arguments of the agentctx_plugin that would
+// generate the plugin in outputDir. The manifest provides the metadata and
+// the component lists, falling back to the skills/, agents/, and commands/
+// directories for the lists it leaves out. Hooks, MCP servers, and LSP
+// servers are read from the files the manifest names or holds inline, and
+// every other file becomes a file block, so the first apply after the
+// import rewrites the plugin in place instead of removing files. Sub-agents
+// written into the plugin by agentctx_subagent are left to those resources.
+func importPlugin(ctx context.Context, outputDir string) (*PluginResourceModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	absDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		diags.AddError(errcode.PathResolution.Summary("Path Resolution Failed"), fmt.Sprintf("Failed to resolve absolute path for %q: %s", outputDir, err))
+		return nil, diags
+	}
+	im := &importer{
+		fsDir:   longpath.Path(absDir),
+		claimed: map[string]bool{pluginJSONPath: true, lockFileName: true},
+		schemas: map[string]string{},
+	}
+
+	data, err := os.ReadFile(filepath.Join(im.fsDir, filepath.FromSlash(pluginJSONPath)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			diags.AddError(errcode.InvalidImportID.Summary("Invalid Import ID"),
+				fmt.Sprintf("%q holds no plugin: %s does not exist. The import ID is the plugin's output_dir.", outputDir, pluginJSONPath))
+			return nil, diags
+		}
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read plugin manifest: %s", err))
+		return nil, diags
+	}
+	var manifest struct {
+		Schema string `json:"$schema"`
+		pluginManifest
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		diags.AddError(errcode.InvalidJSON.Summary("Invalid Plugin Manifest"), fmt.Sprintf("Failed to parse %s: %s", pluginJSONPath, err))
+		return nil, diags
+	}
+	if manifest.Name == "" {
+		diags.AddError(errcode.InvalidJSON.Summary("Invalid Plugin Manifest"), fmt.Sprintf("%s does not set a name.", pluginJSONPath))
+		return nil, diags
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err == nil {
+		for key := range raw {
+			if !importManifestKeys[key] {
+				im.dropped = append(im.dropped, fmt.Sprintf("%s property %q", pluginJSONPath, key))
+			}
+		}
+	}
+	im.noteSchema(pluginJSONPath, manifest.Schema)
+
+	model := &PluginResourceModel{
+		Name:                   types.StringValue(manifest.Name),
+		OutputDir:              types.StringValue(outputDir),
+		Version:                optionalString(manifest.Version),
+		Description:            optionalString(manifest.Description),
+		Homepage:               optionalString(manifest.Homepage),
+		Repository:             optionalString(manifest.Repository),
+		License:                optionalString(manifest.License),
+		Keywords:               types.ListNull(types.StringType),
+		RequiresClaudeVersion:  optionalString(manifest.RequiresClaudeVersion),
+		CompatibilityLevel:     types.StringNull(),
+		EnvVarPolicy:           types.StringValue("warn"),
+		KnownEnvVars:           types.ListNull(types.StringType),
+		VerifyScriptReferences: types.BoolValue(true),
+		GenerateTests:          types.BoolValue(im.claimIfExists(testScriptPath)),
+		Provenance:             types.BoolValue(im.claimIfExists(provenancePath)),
+		JSONFormat:             types.StringValue(jsonFormatIndented),
+		JSONSchemas:            types.MapNull(types.StringType),
+		LockTimeoutSeconds:     types.Int64Value(defaultLockTimeoutSeconds),
+		ContentStorage:         types.StringNull(),
+		RegenerateIfMissing:    types.BoolValue(false),
+		CacheDir:               types.StringNull(),
+		ID:                     types.StringValue(absDir),
+		PluginDir:              types.StringValue(absDir),
+		ManifestJSON:           types.StringNull(),
+		ContentHash:            types.StringNull(),
+		InventoryJSON:          types.StringNull(),
+		FileHashes:             types.MapNull(types.StringType),
+		UnmanagedFiles:         types.ListNull(types.StringType),
+	}
+	if !bytes.Contains(bytes.TrimSpace(data), []byte("\n")) {
+		model.JSONFormat = types.StringValue(jsonFormatCompact)
+	}
+	if len(manifest.Keywords) > 0 {
+		model.Keywords, _ = types.ListValueFrom(ctx, types.StringType, manifest.Keywords)
+	}
+	if a := manifest.Author; a != nil {
+		model.Author = []AuthorModel{{Name: types.StringValue(a.Name), Email: optionalString(a.Email), URL: optionalString(a.URL)}}
+	}
+	for _, p := range manifest.OutputStyles {
+		model.OutputStyles = append(model.OutputStyles, PluginOutputStyleModel{Path: types.StringValue(strings.TrimPrefix(p, "./"))})
+	}
+
+	// Sub-agents registered by agentctx_subagent stay with those resources.
+	registered, err := readRegisteredAgentNames(im.fsDir)
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read the sub-agents registered in %q: %s", absDir, err))
+		return nil, diags
+	}
+	if len(registered) > 0 {
+		im.claimed[registeredAgentsPath] = true
+		for _, name := range registered {
+			im.claimed[agentFilePath(name)] = true
+		}
+	}
+
+	skills, err := im.componentNames(manifest.Skills, "skills", "/")
+	if err == nil {
+		for _, name := range skills {
+			var content string
+			if content, err = im.readText("skills/" + name + "/SKILL.md"); err != nil {
+				break
+			}
+			model.Skills = append(model.Skills, PluginSkillModel{
+				Name: types.StringValue(name), SourceDir: types.StringNull(), SourceBundle: types.StringNull(), Content: types.StringValue(content),
+				Description: types.StringNull(), AllowedTools: types.ListNull(types.StringType), Metadata: types.MapNull(types.StringType), License: types.StringNull(),
+			})
+		}
+	}
+	var agents, commands []string
+	if err == nil {
+		agents, err = im.componentNames(manifest.Agents, "agents", ".md")
+	}
+	if err == nil {
+		for _, name := range agents {
+			if im.claimed[agentFilePath(name)] {
+				continue
+			}
+			var content string
+			if content, err = im.readText(agentFilePath(name)); err != nil {
+				break
+			}
+			model.Agents = append(model.Agents, PluginAgentModel{
+				Name:           types.StringValue(name),
+				SourceFile:     types.StringNull(),
+				Content:        types.StringValue(content),
+				Description:    types.StringNull(),
+				Tools:          types.ListNull(types.StringType),
+				Model:          types.StringNull(),
+				PermissionMode: types.StringNull(),
+				Skills:         types.ListNull(types.StringType),
+			})
+		}
+	}
+	if err == nil {
+		commands, err = im.componentNames(manifest.Commands, "commands", ".md")
+	}
+	if err == nil {
+		for _, name := range commands {
+			var content string
+			if content, err = im.readText("commands/" + name + ".md"); err != nil {
+				break
+			}
+			model.Commands = append(model.Commands, PluginCommandModel{
+				Name:                   types.StringValue(name),
+				SourceFile:             types.StringNull(),
+				Content:                types.StringValue(content),
+				Description:            types.StringNull(),
+				ArgumentHint:           types.StringNull(),
+				AllowedTools:           types.ListNull(types.StringType),
+				Model:                  types.StringNull(),
+				DisableModelInvocation: types.BoolNull(),
+			})
+		}
+	}
+	if err == nil {
+		model.Hooks, err = im.importHooks(manifest.Hooks)
+	}
+	if err == nil {
+		model.McpServers, err = im.importMcpServers(ctx, manifest.McpServers)
+	}
+	if err == nil {
+		model.LspServers, err = im.importLspServers(ctx, manifest.LspServers)
+	}
+	if err == nil {
+		model.Files, err = im.importFiles()
+	}
+	if err != nil {
+		diags.AddError(errcode.InvalidImportID.Summary("Plugin Import Failed"), fmt.Sprintf("Cannot import the plugin in %q: %s", absDir, err))
+		return nil, diags
+	}
+
+	if len(im.schemas) > 0 {
+		model.JSONSchemas, _ = types.MapValueFrom(ctx, types.StringType, im.schemas)
+	}
+	if len(im.dropped) > 0 {
+		sort.Strings(im.dropped)
+		diags.AddWarning(errcode.InvalidConfig.Summary("Plugin Settings Not Imported"),
+			fmt.Sprintf("agentctx_plugin has no argument for the following settings, so the next apply leaves them out: %s.", strings.Join(im.dropped, ", ")))
+	}
+	return model, diags
+}
+
+// optionalString returns s, or null when it is empty.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
+
+// claimIfExists claims rel and reports true when it exists.
+func (im *importer) claimIfExists(rel string) bool {
+	if _, err := os.Stat(filepath.Join(im.fsDir, filepath.FromSlash(rel))); err != nil {
+		return false
+	}
+	im.claimed[rel] = true
+	return true
+}
+
+// readText claims rel and returns its content, which must be UTF-8.
+func (im *importer) readText(rel string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(im.fsDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("%s is not UTF-8 text", rel)
+	}
+	im.claimed[rel] = true
+	return string(data), nil
+}
+
+// noteSchema records the "$schema" property of the generated file rel.
+func (im *importer) noteSchema(rel, url string) {
+	if url != "" {
+		im.schemas[rel] = url
+	}
+}
+
+// componentNames returns the names of the skills, agents, or commands
+// (dir) of the plugin: those listed in the manifest, each of which must be
+// "./<dir>/<name><suffix>", or else the entries of dir ending in suffix,
+// sorted. Skills are directories, listed with the "/" suffix.
+func (im *importer) componentNames(listed []string, dir, suffix string) ([]string, error) {
+	var names []string
+	if len(listed) > 0 {
+		for _, p := range listed {
+			name, ok := strings.CutPrefix(p, "./"+dir+"/")
+			if ok && suffix == "/" {
+				name = strings.TrimSuffix(name, "/")
+			} else if ok {
+				name, ok = strings.CutSuffix(name, suffix)
+			}
+			if !ok || name == "" || strings.Contains(name, "/") {
+				return nil, fmt.Errorf("%s lists %q, which is not in the standard plugin layout, %s/<name>%s", pluginJSONPath, p, dir, suffix)
+			}
+			names = append(names, name)
+		}
+		return names, nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(im.fsDir, dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, e := range entries {
+		switch {
+		case suffix == "/" && e.IsDir():
+			names = append(names, e.Name())
+		case suffix != "/" && !e.IsDir() && strings.HasSuffix(e.Name(), suffix):
+			names = append(names, strings.TrimSuffix(e.Name(), suffix))
+		}
+	}
+	return names, nil
+}
+
+// configSource returns the content of the hooks, MCP, or LSP configuration
+// the manifest property value refers to: the file it names, the object it
+// holds inline, or the default file defaultPath when it is unset. The
+// default file is claimed; a file elsewhere stays a file block. It returns
+// nil when there is no configuration.
+func (im *importer) configSource(value interface{}, defaultPath string) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		if !im.claimIfExists(defaultPath) {
+			return nil, nil
+		}
+		return os.ReadFile(filepath.Join(im.fsDir, filepath.FromSlash(defaultPath)))
+	case string:
+		rel := strings.TrimPrefix(v, "./")
+		if filepath.IsAbs(rel) || strings.Contains(rel, "..") {
+			return nil, fmt.Errorf("%s refers to %q outside the plugin", pluginJSONPath, v)
+		}
+		if rel == defaultPath {
+			im.claimed[rel] = true
+		}
+		return os.ReadFile(filepath.Join(im.fsDir, filepath.FromSlash(rel)))
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// decodeObject decodes data, a JSON object, into its properties, records
+// its "$schema" property for rel, and returns the properties under key, or
+// all of them when key is empty or absent, as with configuration held
+// inline in the manifest.
+func (im *importer) decodeObject(rel string, data []byte, key string) (map[string]json.RawMessage, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", rel, err)
+	}
+	if s, ok := doc["$schema"]; ok {
+		var url string
+		if json.Unmarshal(s, &url) == nil {
+			im.noteSchema(rel, url)
+		}
+		delete(doc, "$schema")
+	}
+	if inner, ok := doc[key]; ok && key != "" {
+		for k := range doc {
+			if k != key {
+				im.dropped = append(im.dropped, fmt.Sprintf("%s property %q", rel, k))
+			}
+		}
+		doc = nil
+		if err := json.Unmarshal(inner, &doc); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", rel, err)
+		}
+	}
+	return doc, nil
+}
+
+// importHooks reconstructs the hooks block.
+func (im *importer) importHooks(value interface{}) ([]PluginHooksModel, error) {
+	data, err := im.configSource(value, hooksJSONPath)
+	if data == nil || err != nil {
+		return nil, err
+	}
+	events, err := im.decodeObject(hooksJSONPath, data, "hooks")
+	if err != nil {
+		return nil, err
+	}
+
+	var hooks PluginHooksModel
+	fields := map[string]*[]PluginHookMatcherModel{
+		"PreToolUse":         &hooks.PreToolUse,
+		"PostToolUse":        &hooks.PostToolUse,
+		"PostToolUseFailure": &hooks.PostToolUseFail,
+		"PermissionRequest":  &hooks.PermissionRequest,
+		"UserPromptSubmit":   &hooks.UserPromptSubmit,
+		"Notification":       &hooks.Notification,
+		"Stop":               &hooks.Stop,
+		"SubagentStart":      &hooks.SubagentStart,
+		"SubagentStop":       &hooks.SubagentStop,
+		"SessionStart":       &hooks.SessionStart,
+		"SessionEnd":         &hooks.SessionEnd,
+		"TeammateIdle":       &hooks.TeammateIdle,
+		"TaskCompleted":      &hooks.TaskCompleted,
+		"PreCompact":         &hooks.PreCompact,
+	}
+	found := false
+	for event, rawMatchers := range events {
+		field, ok := fields[event]
+		if !ok {
+			im.dropped = append(im.dropped, fmt.Sprintf("%s event %q", hooksJSONPath, event))
+			continue
+		}
+		var matchers []struct {
+			Matcher *string `json:"matcher"`
+			Hooks   []struct {
+				Type    string `json:"type"`
+				Command string `json:"command"`
+				Agent   string `json:"agent"`
+			} `json:"hooks"`
+		}
+		if err := json.Unmarshal(rawMatchers, &matchers); err != nil {
+			return nil, fmt.Errorf("parsing %s event %q: %w", hooksJSONPath, event, err)
+		}
+		for _, m := range matchers {
+			matcher := PluginHookMatcherModel{Matcher: types.StringNull(), Tools: types.ListNull(types.StringType), Order: types.Int64Null()}
+			if m.Matcher != nil {
+				matcher.Matcher = types.StringValue(*m.Matcher)
+			}
+			for _, h := range m.Hooks {
+				entry := PluginHookEntryModel{Type: types.StringValue(h.Type), Command: types.StringValue(h.Command), Agent: types.StringNull()}
+				if h.Agent != "" {
+					entry.Command, entry.Agent = types.StringNull(), types.StringValue(h.Agent)
+				}
+				matcher.Hooks = append(matcher.Hooks, entry)
+			}
+			*field = append(*field, matcher)
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	return []PluginHooksModel{hooks}, nil
+}
+
+// serverEntries decodes the servers of an MCP or LSP configuration, sorted
+// by name, each as its properties.
+func (im *importer) serverEntries(value interface{}, rel, key string) ([]string, map[string]map[string]json.RawMessage, error) {
+	data, err := im.configSource(value, rel)
+	if data == nil || err != nil {
+		return nil, nil, err
+	}
+	doc, err := im.decodeObject(rel, data, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0, len(doc))
+	servers := make(map[string]map[string]json.RawMessage, len(doc))
+	for name, raw := range doc {
+		var props map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &props); err != nil {
+			return nil, nil, fmt.Errorf("parsing server %q in %s: %w", name, rel, err)
+		}
+		names = append(names, name)
+		servers[name] = props
+	}
+	sort.Strings(names)
+	return names, servers, nil
+}
+
+// serverProps decodes the properties of one MCP or LSP server into
+// argument values. The first error sticks, and done reports the properties
+// no argument took as dropped.
+type serverProps struct {
+	im    *importer
+	where string
+	props map[string]json.RawMessage
+	err   error
+}
+
+func (s *serverProps) get(key string, v interface{}) bool {
+	raw, ok := s.props[key]
+	if !ok || s.err != nil {
+		return false
+	}
+	delete(s.props, key)
+	if err := json.Unmarshal(raw, v); err != nil {
+		s.err = fmt.Errorf("parsing %q of %s: %w", key, s.where, err)
+		return false
+	}
+	return true
+}
+
+func (s *serverProps) str(key string) types.String {
+	var v string
+	if !s.get(key, &v) {
+		return types.StringNull()
+	}
+	return types.StringValue(v)
+}
+
+func (s *serverProps) int64(key string) types.Int64 {
+	var v int64
+	if !s.get(key, &v) {
+		return types.Int64Null()
+	}
+	return types.Int64Value(v)
+}
+
+func (s *serverProps) list(ctx context.Context, key string) types.List {
+	var v []string
+	if !s.get(key, &v) {
+		return types.ListNull(types.StringType)
+	}
+	l, _ := types.ListValueFrom(ctx, types.StringType, v)
+	return l
+}
+
+func (s *serverProps) strMap(ctx context.Context, key string) types.Map {
+	var v map[string]string
+	if !s.get(key, &v) {
+		return types.MapNull(types.StringType)
+	}
+	m, _ := types.MapValueFrom(ctx, types.StringType, v)
+	return m
+}
+
+// done reports the properties left over and returns the first error.
+func (s *serverProps) done() error {
+	for key := range s.props {
+		s.im.dropped = append(s.im.dropped, fmt.Sprintf("%s property %q", s.where, key))
+	}
+	return s.err
+}
+
+// importMcpServers reconstructs the mcp_server blocks.
+func (im *importer) importMcpServers(ctx context.Context, value interface{}) ([]PluginMcpModel, error) {
+	names, servers, err := im.serverEntries(value, mcpJSONPath, "mcpServers")
+	if err != nil {
+		return nil, err
+	}
+	var out []PluginMcpModel
+	for _, name := range names {
+		s := &serverProps{im: im, where: fmt.Sprintf("MCP server %q", name), props: servers[name]}
+		out = append(out, PluginMcpModel{
+			Name:    types.StringValue(name),
+			Command: s.str("command"),
+			Args:    s.list(ctx, "args"),
+			Env:     s.strMap(ctx, "env"),
+			URL:     s.str("url"),
+			Cwd:     s.str("cwd"),
+		})
+		if err := s.done(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// importLspServers reconstructs the lsp_server blocks.
+func (im *importer) importLspServers(ctx context.Context, value interface{}) ([]PluginLspModel, error) {
+	names, servers, err := im.serverEntries(value, lspJSONPath, "")
+	if err != nil {
+		return nil, err
+	}
+	var out []PluginLspModel
+	for _, name := range names {
+		s := &serverProps{im: im, where: fmt.Sprintf("LSP server %q", name), props: servers[name]}
+		server := PluginLspModel{
+			Name:                  types.StringValue(name),
+			Command:               s.str("command"),
+			Args:                  s.list(ctx, "args"),
+			Transport:             s.str("transport"),
+			Env:                   s.strMap(ctx, "env"),
+			InitializationOptions: s.strMap(ctx, "initializationOptions"),
+			Settings:              s.strMap(ctx, "settings"),
+			ExtensionToLanguage:   s.strMap(ctx, "extensionToLanguage"),
+			WorkspaceFolder:       s.str("workspaceFolder"),
+			StartupTimeout:        s.int64("startupTimeout"),
+			ShutdownTimeout:       s.int64("shutdownTimeout"),
+			RestartOnCrash:        types.BoolValue(false),
+			MaxRestarts:           s.int64("maxRestarts"),
+		}
+		var restart bool
+		if s.get("restartOnCrash", &restart) {
+			server.RestartOnCrash = types.BoolValue(restart)
+		}
+		if err := s.done(); err != nil {
+			return nil, err
+		}
+		out = append(out, server)
+	}
+	return out, nil
+}
+
+// importFiles returns a file block for every file no other argument
+// reproduces, sorted by path, keeping its executable bit.
+func (im *importer) importFiles() ([]PluginFileModel, error) {
+	var files []PluginFileModel
+	var binary []string
+	err := filepath.WalkDir(im.fsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(im.fsDir, p)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); im.claimed[rel] {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if !utf8.Valid(data) {
+			binary = append(binary, rel)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, PluginFileModel{
+			Path:       types.StringValue(rel),
+			Content:    types.StringValue(string(data)),
+			SourceFile: types.StringNull(),
+			Executable: types.BoolValue(runtime.GOOS != "windows" && info.Mode().Perm()&0o111 != 0),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(binary) > 0 {
+		return nil, fmt.Errorf("file blocks hold text, and %s are binary; move them out of the plugin and add them back with source_file after the import", strings.Join(binary, ", "))
+	}
+	return files, nil
+}
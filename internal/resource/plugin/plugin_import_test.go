@@ -0,0 +1,133 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestImportPlugin_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	model.Version = stringValue("1.2.0")
+	model.Commands = []PluginCommandModel{
+		{Name: stringValue("deploy"), SourceFile: types.StringNull(), Content: stringValue("Deploy it.\n")},
+	}
+	args, _ := types.ListValueFrom(ctx, types.StringType, []string{"--stdio"})
+	model.McpServers = []PluginMcpModel{
+		{Name: stringValue("docs"), Command: stringValue("docs-server"), Args: args, Env: types.MapNull(types.StringType), URL: types.StringNull(), Cwd: types.StringNull()},
+	}
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	wantHash := model.ContentHash.ValueString()
+
+	imported, diags := importPlugin(ctx, dir)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if len(diags) != 0 {
+		t.Errorf("unexpected warnings: %v", diags)
+	}
+	if got := imported.Name.ValueString(); got != "scaffold" {
+		t.Errorf("name = %q, want scaffold", got)
+	}
+	if got := imported.Version.ValueString(); got != "1.2.0" {
+		t.Errorf("version = %q, want 1.2.0", got)
+	}
+	if !imported.GenerateTests.ValueBool() {
+		t.Error("generate_tests = false, want true")
+	}
+	if len(imported.Skills) != 1 || imported.Skills[0].Content.ValueString() != "# Lint\n" {
+		t.Errorf("skills = %+v", imported.Skills)
+	}
+	if len(imported.Agents) != 1 || imported.Agents[0].Name.ValueString() != "reviewer" {
+		t.Errorf("agents = %+v", imported.Agents)
+	}
+	if len(imported.Commands) != 1 || imported.Commands[0].Content.ValueString() != "Deploy it.\n" {
+		t.Errorf("commands = %+v", imported.Commands)
+	}
+	if len(imported.Hooks) != 1 || len(imported.Hooks[0].PostToolUse) != 1 || imported.Hooks[0].PostToolUse[0].Matcher.ValueString() != "Write|Edit" {
+		t.Errorf("hooks = %+v", imported.Hooks)
+	}
+	if len(imported.McpServers) != 1 || imported.McpServers[0].Command.ValueString() != "docs-server" {
+		t.Errorf("mcp servers = %+v", imported.McpServers)
+	}
+	if len(imported.Files) != 1 || imported.Files[0].Path.ValueString() != "scripts/format.sh" {
+		t.Fatalf("files = %+v", imported.Files)
+	}
+
+	// Applying the imported arguments reproduces the plugin.
+	if diags := r.writePlugin(ctx, imported); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if got := imported.ContentHash.ValueString(); got != wantHash {
+		t.Errorf("content hash after re-apply = %q, want %q", got, wantHash)
+	}
+}
+
+func TestImportPlugin_FallsBackToLayout(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".claude-plugin/plugin.json", `{"name": "hand-written", "category": "tools"}`)
+	write("skills/b/SKILL.md", "# B\n")
+	write("skills/a/SKILL.md", "# A\n")
+	write("agents/helper.md", "Help.\n")
+	write("README.md", "# Hand-written\n")
+
+	model, diags := importPlugin(ctx, dir)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if len(model.Skills) != 2 || model.Skills[0].Name.ValueString() != "a" || model.Skills[1].Name.ValueString() != "b" {
+		t.Errorf("skills = %+v", model.Skills)
+	}
+	if len(model.Agents) != 1 || model.Agents[0].Content.ValueString() != "Help.\n" {
+		t.Errorf("agents = %+v", model.Agents)
+	}
+	if len(model.Files) != 1 || model.Files[0].Path.ValueString() != "README.md" {
+		t.Errorf("files = %+v", model.Files)
+	}
+	if got := model.JSONFormat.ValueString(); got != jsonFormatCompact {
+		t.Errorf("json_format = %q, want %q", got, jsonFormatCompact)
+	}
+	if len(diags.Warnings()) != 1 || !strings.Contains(diags.Warnings()[0].Detail(), `"category"`) {
+		t.Errorf("warnings = %v, want one naming the category property", diags.Warnings())
+	}
+}
+
+func TestImportPlugin_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	if _, diags := importPlugin(ctx, t.TempDir()); !diags.HasError() {
+		t.Error("expected an error for a directory without a manifest")
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".claude-plugin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".claude-plugin", "plugin.json"), []byte(`{"name": "p", "skills": ["./vendor/skill"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, diags := importPlugin(ctx, dir); !diags.HasError() {
+		t.Error("expected an error for a skill outside the standard layout")
+	}
+}
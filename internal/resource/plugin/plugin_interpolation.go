@@ -0,0 +1,347 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// Values accepted by env_var_policy.
+const (
+	envVarPolicyWarn   = "warn"
+	envVarPolicyError  = "error"
+	envVarPolicyIgnore = "ignore"
+)
+
+// envVarRefPattern matches ${VAR} and ${VAR:-default} references. The first
+// group captures an optional preceding backslash, which marks the reference
+// as escaped in hook commands (they run through a shell).
+var envVarRefPattern = regexp.MustCompile(`(\\?)\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// pluginRootRefPattern matches ${CLAUDE_PLUGIN_ROOT}/<relative path>, stopping
+// at whitespace, quotes, and shell metacharacters.
+var pluginRootRefPattern = regexp.MustCompile("\\$\\{CLAUDE_PLUGIN_ROOT\\}/([^\\s\"'`;|&<>()]+)")
+
+// builtinEnvVars are variables Claude Code sets (or that any POSIX
+// environment provides) when it runs hook, MCP, and LSP commands.
+var builtinEnvVars = map[string]bool{
+	"CLAUDE_PLUGIN_ROOT": true,
+	"CLAUDE_PROJECT_DIR": true,
+	"CLAUDE_ENV_FILE":    true,
+	"HOME":               true,
+	"PATH":               true,
+	"PWD":                true,
+	"SHELL":              true,
+	"TMPDIR":             true,
+	"USER":               true,
+}
+
+// interpolatedValue is a configured string that Claude Code expands
+// ${VAR} references in.
+type interpolatedValue struct {
+	path  path.Path
+	value string
+	// shell is true for hook commands, where \${VAR} escapes a reference.
+	shell bool
+}
+
+// validateInterpolation checks ${VAR} references in hook, MCP, and LSP
+// commands against the variables Claude Code provides plus known_env_vars,
+// and, unless verify_script_references is false, checks that
+// ${CLAUDE_PLUGIN_ROOT}/... paths point at files the plugin generates.
+func (r *PluginResource) validateInterpolation(ctx context.Context, model *PluginResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	policy := envVarPolicyWarn
+	if !model.EnvVarPolicy.IsNull() && !model.EnvVarPolicy.IsUnknown() {
+		policy = model.EnvVarPolicy.ValueString()
+	}
+
+	known := make(map[string]bool, len(builtinEnvVars))
+	for name := range builtinEnvVars {
+		known[name] = true
+	}
+	for _, name := range knownStrings(model.KnownEnvVars.Elements()) {
+		known[name] = true
+	}
+
+	values := collectInterpolatedValues(ctx, model)
+
+	if policy != envVarPolicyIgnore {
+		for _, v := range values {
+			for _, m := range envVarRefPattern.FindAllStringSubmatch(v.value, -1) {
+				escaped, name, hasDefault := m[1] != "", m[2], m[3] != ""
+				if (escaped && v.shell) || hasDefault || known[name] {
+					continue
+				}
+				summary := errcode.UnknownEnvVar.Summary("Unknown Environment Variable")
+				detail := fmt.Sprintf("%q references ${%s}, which Claude Code does not set. "+
+					"If users are expected to provide it, add %q to known_env_vars or give it a default with ${%s:-value}.",
+					v.value, name, name, name)
+				if v.shell {
+					detail += fmt.Sprintf(" To pass the reference through to the shell unexpanded, escape it as \\${%s}.", name)
+				}
+				if policy == envVarPolicyError {
+					diags.AddAttributeError(v.path, summary, detail)
+				} else {
+					diags.AddAttributeWarning(v.path, summary, detail)
+				}
+			}
+		}
+	}
+
+	if model.VerifyScriptReferences.IsNull() || model.VerifyScriptReferences.ValueBool() {
+		diags.Append(validateScriptReferences(model, values)...)
+	}
+
+	return diags
+}
+
+// collectInterpolatedValues returns every known string in the hook, MCP,
+// and LSP configuration that Claude Code expands variables in.
+func collectInterpolatedValues(_ context.Context, model *PluginResourceModel) []interpolatedValue {
+	var values []interpolatedValue
+	add := func(p path.Path, v types.String, shell bool) {
+		if v.IsNull() || v.IsUnknown() {
+			return
+		}
+		values = append(values, interpolatedValue{path: p, value: v.ValueString(), shell: shell})
+	}
+	addList := func(p path.Path, l types.List) {
+		for i, e := range l.Elements() {
+			if s, ok := e.(types.String); ok {
+				add(p.AtListIndex(i), s, false)
+			}
+		}
+	}
+	addMap := func(p path.Path, m types.Map) {
+		for k, e := range m.Elements() {
+			if s, ok := e.(types.String); ok {
+				add(p.AtMapKey(k), s, false)
+			}
+		}
+	}
+
+	for hi, hooks := range model.Hooks {
+		for _, event := range hookEventBlocks(hooks) {
+			for mi, m := range event.matchers {
+				for ei, h := range m.Hooks {
+					p := path.Root("hooks").AtListIndex(hi).AtName(event.attr).AtListIndex(mi).AtName("hook").AtListIndex(ei).AtName("command")
+					add(p, h.Command, true)
+				}
+			}
+		}
+	}
+
+	for i, s := range model.McpServers {
+		p := path.Root("mcp_server").AtListIndex(i)
+		add(p.AtName("command"), s.Command, false)
+		addList(p.AtName("args"), s.Args)
+		addMap(p.AtName("env"), s.Env)
+		add(p.AtName("url"), s.URL, false)
+		add(p.AtName("cwd"), s.Cwd, false)
+	}
+
+	for i, s := range model.LspServers {
+		p := path.Root("lsp_server").AtListIndex(i)
+		add(p.AtName("command"), s.Command, false)
+		addList(p.AtName("args"), s.Args)
+		addMap(p.AtName("env"), s.Env)
+		add(p.AtName("workspace_folder"), s.WorkspaceFolder, false)
+	}
+
+	return values
+}
+
+// hookEventBlock pairs a hooks {} sub-block attribute name with its matchers.
+type hookEventBlock struct {
+	attr     string
+	matchers []PluginHookMatcherModel
+}
+
+// hookEventBlocks lists the event blocks of a hooks {} block in schema order.
+func hookEventBlocks(h PluginHooksModel) []hookEventBlock {
+	return []hookEventBlock{
+		{"pre_tool_use", h.PreToolUse},
+		{"post_tool_use", h.PostToolUse},
+		{"post_tool_use_failure", h.PostToolUseFail},
+		{"permission_request", h.PermissionRequest},
+		{"user_prompt_submit", h.UserPromptSubmit},
+		{"notification", h.Notification},
+		{"stop", h.Stop},
+		{"subagent_start", h.SubagentStart},
+		{"subagent_stop", h.SubagentStop},
+		{"session_start", h.SessionStart},
+		{"session_end", h.SessionEnd},
+		{"teammate_idle", h.TeammateIdle},
+		{"task_completed", h.TaskCompleted},
+		{"pre_compact", h.PreCompact},
+	}
+}
+
+// validateScriptReferences fails validation for ${CLAUDE_PLUGIN_ROOT}/...
+// paths that do not point at a file the plugin generates or copies from a
+// source. The check is skipped while any generated path is still unknown.
+func validateScriptReferences(model *PluginResourceModel, values []interpolatedValue) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	layout := newPluginLayout(model)
+	if !layout.complete {
+		return diags
+	}
+
+	for _, v := range values {
+		for _, m := range pluginRootRefPattern.FindAllStringSubmatch(v.value, -1) {
+			rel := filepath.ToSlash(filepath.Clean(m[1]))
+			if layout.contains(rel) {
+				continue
+			}
+			diags.AddAttributeError(
+				v.path,
+				errcode.MissingReferencedFile.Summary("Referenced Plugin File Not Generated"),
+				fmt.Sprintf("%q references ${CLAUDE_PLUGIN_ROOT}/%s, but the plugin does not generate that path. "+
+					"Add a file block for it, fix the reference, or set verify_script_references = false "+
+					"if the path is created at runtime.", v.value, rel),
+			)
+		}
+	}
+	return diags
+}
+
+// pluginLayout describes the paths, relative to the plugin root, that
+// writePlugin produces for a configuration.
+type pluginLayout struct {
+	// files holds slash-separated paths of generated files.
+	files map[string]bool
+	// sourceDirs maps skill directories copied from source_dir to the local
+	// directory they are copied from.
+	sourceDirs map[string]string
+	// opaqueDirs are skill directories whose contents are not known until
+	// apply; every path inside them is accepted.
+	opaqueDirs []string
+	// complete is false when a generated path is not yet known.
+	complete bool
+}
+
+func newPluginLayout(model *PluginResourceModel) *pluginLayout {
+	l := &pluginLayout{
+		files:      map[string]bool{".claude-plugin/plugin.json": true},
+		sourceDirs: make(map[string]string),
+		complete:   true,
+	}
+
+	for _, f := range model.Files {
+		if f.Path.IsUnknown() {
+			l.complete = false
+		}
+		if hasNonEmptyString(f.Path) {
+			l.files[filepath.ToSlash(filepath.Clean(f.Path.ValueString()))] = true
+		}
+	}
+	for _, s := range model.Skills {
+		if s.Name.IsUnknown() {
+			l.complete = false
+		}
+		if !hasNonEmptyString(s.Name) {
+			continue
+		}
+		dir := "skills/" + s.Name.ValueString()
+		switch {
+		case hasNonEmptyString(s.Content):
+			l.files[dir+"/SKILL.md"] = true
+		case hasNonEmptyString(s.SourceDir):
+			l.sourceDirs[dir] = s.SourceDir.ValueString()
+		case hasNonEmptyString(s.SourceBundle):
+			desc, err := bundle.ParseDescriptor(s.SourceBundle.ValueString())
+			if err != nil {
+				// writePlugin reports the invalid descriptor.
+				l.opaqueDirs = append(l.opaqueDirs, dir)
+				continue
+			}
+			for rel := range desc.Files {
+				l.files[dir+"/"+rel] = true
+			}
+		default:
+			l.opaqueDirs = append(l.opaqueDirs, dir)
+		}
+	}
+	for _, a := range model.Agents {
+		if a.Name.IsUnknown() {
+			l.complete = false
+		}
+		if hasNonEmptyString(a.Name) {
+			l.files["agents/"+a.Name.ValueString()+".md"] = true
+		}
+	}
+	for _, c := range model.Commands {
+		if c.Name.IsUnknown() {
+			l.complete = false
+		}
+		if hasNonEmptyString(c.Name) {
+			l.files["commands/"+c.Name.ValueString()+".md"] = true
+		}
+	}
+	if len(model.Hooks) > 0 {
+		l.files["hooks/hooks.json"] = true
+	}
+	if len(model.McpServers) > 0 {
+		l.files[".mcp.json"] = true
+	}
+	if len(model.LspServers) > 0 {
+		l.files[".lsp.json"] = true
+	}
+	sort.Strings(l.opaqueDirs)
+	return l
+}
+
+// contains reports whether rel is a generated file, a directory containing
+// one, or a path inside a skill directory that exists in its source.
+func (l *pluginLayout) contains(rel string) bool {
+	if l.files[rel] {
+		return true
+	}
+	for f := range l.files {
+		if strings.HasPrefix(f, rel+"/") {
+			return true
+		}
+	}
+	for dir, src := range l.sourceDirs {
+		if rel == dir {
+			return true
+		}
+		if rest, ok := strings.CutPrefix(rel, dir+"/"); ok {
+			if _, err := os.Stat(filepath.Join(src, filepath.FromSlash(rest))); err == nil {
+				return true
+			}
+		}
+	}
+	for _, d := range l.opaqueDirs {
+		if rel == d || strings.HasPrefix(rel, d+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// knownStrings returns the known, non-null string values among elems.
+func knownStrings(elems []attr.Value) []string {
+	var out []string
+	for _, e := range elems {
+		if s, ok := e.(types.String); ok && !s.IsNull() && !s.IsUnknown() {
+			out = append(out, s.ValueString())
+		}
+	}
+	return out
+}
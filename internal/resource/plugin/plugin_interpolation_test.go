@@ -0,0 +1,201 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+)
+
+func commandHooks(commands ...string) []PluginHooksModel {
+	var entries []PluginHookEntryModel
+	for _, c := range commands {
+		entries = append(entries, PluginHookEntryModel{Type: stringValue("command"), Command: stringValue(c)})
+	}
+	return []PluginHooksModel{{
+		PreToolUse: []PluginHookMatcherModel{{Matcher: stringValue("Bash"), Hooks: entries}},
+	}}
+}
+
+func stringList(values ...string) types.List {
+	elems := make([]attr.Value, len(values))
+	for i, v := range values {
+		elems[i] = types.StringValue(v)
+	}
+	return types.ListValueMust(types.StringType, elems)
+}
+
+func TestValidateInterpolation_EnvVars(t *testing.T) {
+	r := &PluginResource{}
+
+	cases := []struct {
+		name     string
+		model    PluginResourceModel
+		errors   int
+		warnings int
+	}{
+		{
+			name:  "builtin variables",
+			model: PluginResourceModel{Hooks: commandHooks("cd ${CLAUDE_PLUGIN_ROOT} && ${HOME}/bin/check ${CLAUDE_PROJECT_DIR}")},
+		},
+		{
+			name:     "unknown variable warns by default",
+			model:    PluginResourceModel{Hooks: commandHooks("run.sh ${LINT_CONFIG}")},
+			warnings: 1,
+		},
+		{
+			name: "unknown variable errors under error policy",
+			model: PluginResourceModel{
+				EnvVarPolicy: stringValue("error"),
+				Hooks:        commandHooks("run ${LINT_CONFIG}"),
+			},
+			errors: 1,
+		},
+		{
+			name: "ignore policy",
+			model: PluginResourceModel{
+				EnvVarPolicy: stringValue("ignore"),
+				Hooks:        commandHooks("run ${LINT_CONFIG}"),
+			},
+		},
+		{
+			name: "known_env_vars",
+			model: PluginResourceModel{
+				KnownEnvVars: stringList("LINT_CONFIG"),
+				Hooks:        commandHooks("run ${LINT_CONFIG}"),
+			},
+		},
+		{
+			name:  "default and escaped references",
+			model: PluginResourceModel{Hooks: commandHooks(`run ${LINT_CONFIG:-.lintrc} \${LOCAL_ONLY}`)},
+		},
+		{
+			name: "escaping only applies to hook commands",
+			model: PluginResourceModel{McpServers: []PluginMcpModel{{
+				Name:    stringValue("db"),
+				Command: stringValue("node"),
+				Args:    stringList(`\${DB_URL}`),
+				Env:     types.MapValueMust(types.StringType, map[string]attr.Value{"TOKEN": types.StringValue("${API_TOKEN}")}),
+			}}},
+			warnings: 2,
+		},
+		{
+			name: "lsp server",
+			model: PluginResourceModel{LspServers: []PluginLspModel{{
+				Name:    stringValue("go"),
+				Command: stringValue("${GOPLS_BIN}"),
+			}}},
+			warnings: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := r.validateInterpolation(context.Background(), &tc.model)
+			if got := diags.ErrorsCount(); got != tc.errors {
+				t.Errorf("errors = %d, want %d: %v", got, tc.errors, diags)
+			}
+			if got := diags.WarningsCount(); got != tc.warnings {
+				t.Errorf("warnings = %d, want %d: %v", got, tc.warnings, diags)
+			}
+		})
+	}
+}
+
+func TestValidateInterpolation_AttributePath(t *testing.T) {
+	model := PluginResourceModel{Hooks: commandHooks("ok", "run ${MISSING}")}
+	diags := (&PluginResource{}).validateInterpolation(context.Background(), &model)
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", diags)
+	}
+	want := path.Root("hooks").AtListIndex(0).AtName("pre_tool_use").AtListIndex(0).AtName("hook").AtListIndex(1).AtName("command")
+	withPath, ok := diags[0].(interface{ Path() path.Path })
+	if !ok || !withPath.Path().Equal(want) {
+		t.Errorf("diagnostic path = %v, want %s", diags[0], want)
+	}
+}
+
+func TestValidateInterpolation_ScriptReferences(t *testing.T) {
+	r := &PluginResource{}
+
+	sourceDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(sourceDir, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "bin", "run"), []byte("#!/bin/sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	bundleFiles := map[string]string{"tools/render.py": "sha256:00"}
+	descriptor, err := json.Marshal(bundle.Descriptor{
+		SourceDir:  sourceDir,
+		BundleHash: bundle.ComputeBundleHash(bundleFiles),
+		Files:      bundleFiles,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := PluginResourceModel{
+		Files: []PluginFileModel{{Path: stringValue("scripts/check.sh"), Content: stringValue("#!/bin/sh")}},
+		Skills: []PluginSkillModel{
+			{Name: stringValue("lint"), SourceDir: stringValue(sourceDir)},
+			{Name: stringValue("notes"), SourceBundle: stringValue(string(descriptor))},
+			{Name: stringValue("pending"), SourceBundle: types.StringUnknown()},
+		},
+	}
+
+	cases := []struct {
+		command string
+		errors  int
+	}{
+		{"${CLAUDE_PLUGIN_ROOT}/scripts/check.sh", 0},
+		{"cd ${CLAUDE_PLUGIN_ROOT}/scripts && ./check.sh", 0},
+		{"${CLAUDE_PLUGIN_ROOT}/./scripts/check.sh --fast", 0},
+		{"${CLAUDE_PLUGIN_ROOT}/skills/lint/bin/run", 0},
+		{"${CLAUDE_PLUGIN_ROOT}/skills/notes/tools/render.py", 0},
+		{"${CLAUDE_PLUGIN_ROOT}/skills/pending/anything.sh", 0},
+		{"${CLAUDE_PLUGIN_ROOT}/hooks/hooks.json", 0},
+		{"${CLAUDE_PLUGIN_ROOT}/scripts/chekc.sh", 1},
+		{"${CLAUDE_PLUGIN_ROOT}/skills/lint/bin/missing", 1},
+		{"${CLAUDE_PLUGIN_ROOT}/skills/notes/tools/missing.py", 1},
+		{"\"${CLAUDE_PLUGIN_ROOT}/scripts/missing.sh\"; ${CLAUDE_PLUGIN_ROOT}/other.sh", 2},
+	}
+	for _, tc := range cases {
+		model := base
+		model.Hooks = commandHooks(tc.command)
+		diags := r.validateInterpolation(context.Background(), &model)
+		if got := diags.ErrorsCount(); got != tc.errors {
+			t.Errorf("%q: errors = %d, want %d: %v", tc.command, got, tc.errors, diags)
+		}
+		for _, d := range diags {
+			if !strings.Contains(d.Summary(), "Not Generated") {
+				t.Errorf("%q: unexpected diagnostic %q", tc.command, d.Summary())
+			}
+		}
+	}
+
+	// Opting out.
+	model := base
+	model.VerifyScriptReferences = types.BoolValue(false)
+	model.Hooks = commandHooks("${CLAUDE_PLUGIN_ROOT}/scripts/chekc.sh")
+	if diags := r.validateInterpolation(context.Background(), &model); len(diags) != 0 {
+		t.Errorf("expected no diagnostics with verification disabled, got %v", diags)
+	}
+
+	// Skipped while a generated path is unknown.
+	model = base
+	model.Files = append(model.Files, PluginFileModel{Path: types.StringUnknown()})
+	model.Hooks = commandHooks("${CLAUDE_PLUGIN_ROOT}/scripts/chekc.sh")
+	if diags := r.validateInterpolation(context.Background(), &model); len(diags) != 0 {
+		t.Errorf("expected no diagnostics with unknown file paths, got %v", diags)
+	}
+}
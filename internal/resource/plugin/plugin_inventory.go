@@ -0,0 +1,362 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+)
+
+// inventoryEntry is one element of inventory_json: a file in the plugin
+// directory, the component that produced it, and where its content came
+// from.
+type inventoryEntry struct {
+	// Path is slash-separated and relative to the plugin directory.
+	Path string `json:"path"`
+	// Type is the kind of component: manifest, skill, agent, command,
+	// hooks, mcp_servers, lsp_servers, file, test, provenance, or
+	// agent_registry.
+	Type string `json:"type"`
+	// Component is the skill, agent, or command name, if any.
+	Component string `json:"component,omitempty"`
+	// Source is inline, generated, source_dir, source_bundle,
+	// source_file, or agentctx_subagent for sub-agents written into the
+	// plugin with plugin_dir.
+	Source string `json:"source"`
+	// SourcePath is the local file the content was copied from, if any.
+	SourcePath string `json:"source_path,omitempty"`
+	Hash       string `json:"hash"`
+	Size       int64  `json:"size"`
+	Executable bool   `json:"executable"`
+}
+
+// buildInventory renders inventory_json for the files the model generates
+// in fsDir.
+func buildInventory(fsDir string, model *PluginResourceModel) (string, error) {
+	list, err := collectInventory(fsDir, model)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// collectInventory lists every file the model generates in fsDir, hashed as
+// it exists on disk, sorted by path. Files that are missing on disk are
+// left out, so a refresh after an external deletion shows up as a diff.
+func collectInventory(fsDir string, model *PluginResourceModel) ([]inventoryEntry, error) {
+	entries := make(map[string]inventoryEntry)
+	add := func(relPath, typ, component, source, sourcePath string) {
+		entries[relPath] = inventoryEntry{
+			Path:       relPath,
+			Type:       typ,
+			Component:  component,
+			Source:     source,
+			SourcePath: sourcePath,
+		}
+	}
+	isSet := func(v types.String) bool { return !v.IsNull() && !v.IsUnknown() }
+
+	// Components are listed in the order writePlugin writes them, so a
+	// file block that overwrites another component's file wins here too.
+	for _, s := range model.Skills {
+		name := s.Name.ValueString()
+		prefix := "skills/" + name + "/"
+		switch {
+		case isSet(s.SourceBundle):
+			desc, err := bundle.ParseDescriptor(s.SourceBundle.ValueString())
+			if err != nil {
+				return nil, err
+			}
+			for rel := range desc.Files {
+				add(prefix+rel, "skill", name, "source_bundle", filepath.Join(desc.SourceDir, filepath.FromSlash(rel)))
+			}
+		case isSet(s.SourceDir):
+			srcDir, err := filepath.Abs(s.SourceDir.ValueString())
+			if err != nil {
+				return nil, err
+			}
+			skillDir := filepath.Join(fsDir, "skills", name)
+			err = filepath.WalkDir(skillDir, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					if os.IsNotExist(err) {
+						return nil
+					}
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+				rel, err := filepath.Rel(skillDir, p)
+				if err != nil {
+					return err
+				}
+				add(prefix+filepath.ToSlash(rel), "skill", name, "source_dir", filepath.Join(srcDir, rel))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		default:
+			add(prefix+"SKILL.md", "skill", name, "inline", "")
+		}
+	}
+
+	for _, a := range model.Agents {
+		name := a.Name.ValueString()
+		if isSet(a.SourceFile) {
+			add("agents/"+name+".md", "agent", name, "source_file", a.SourceFile.ValueString())
+		} else {
+			add("agents/"+name+".md", "agent", name, "inline", "")
+		}
+	}
+	for _, c := range model.Commands {
+		name := c.Name.ValueString()
+		if isSet(c.SourceFile) {
+			add("commands/"+name+".md", "command", name, "source_file", c.SourceFile.ValueString())
+		} else {
+			add("commands/"+name+".md", "command", name, "inline", "")
+		}
+	}
+
+	if len(model.Hooks) > 0 {
+		add("hooks/hooks.json", "hooks", "", "generated", "")
+	}
+	if len(model.McpServers) > 0 {
+		add(".mcp.json", "mcp_servers", "", "generated", "")
+	}
+	if len(model.LspServers) > 0 {
+		add(".lsp.json", "lsp_servers", "", "generated", "")
+	}
+	for _, f := range model.Files {
+		relPath := filepath.ToSlash(filepath.Clean(f.Path.ValueString()))
+		if isSet(f.SourceFile) {
+			add(relPath, "file", "", "source_file", f.SourceFile.ValueString())
+		} else {
+			add(relPath, "file", "", "inline", "")
+		}
+	}
+	if model.GenerateTests.ValueBool() {
+		add(testScriptPath, "test", "", "generated", "")
+	}
+	names, err := readRegisteredAgentNames(fsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		add(agentFilePath(name), "agent", name, "agentctx_subagent", "")
+	}
+	if len(names) > 0 {
+		add(registeredAgentsPath, "agent_registry", "", "generated", "")
+	}
+	add(".claude-plugin/plugin.json", "manifest", "", "generated", "")
+	if model.Provenance.ValueBool() {
+		add(provenancePath, "provenance", "", "generated", "")
+	}
+
+	list := make([]inventoryEntry, 0, len(entries))
+	for relPath, e := range entries {
+		fullPath := filepath.Join(fsDir, filepath.FromSlash(relPath))
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if e.Hash, err = bundle.ComputeFileHash(fullPath); err != nil {
+			return nil, err
+		}
+		e.Size = info.Size()
+		e.Executable = info.Mode().Perm()&0o111 != 0
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list, nil
+}
+
+// unmanagedFiles lists the files in fsDir that are not in inventoryJSON,
+// slash-separated, relative to fsDir, and sorted: files that other tools or
+// people put in the output directory. The output directory lock is left
+// out. Delete removes these files along with the managed ones.
+func unmanagedFiles(fsDir, inventoryJSON string) ([]string, error) {
+	var inventory []inventoryEntry
+	if err := json.Unmarshal([]byte(inventoryJSON), &inventory); err != nil {
+		return nil, err
+	}
+	managed := make(map[string]bool, len(inventory)+1)
+	for _, e := range inventory {
+		managed[e.Path] = true
+	}
+	managed[lockFileName] = true
+
+	var foreign []string
+	err := filepath.WalkDir(fsDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(fsDir, p)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); !managed[rel] {
+			foreign = append(foreign, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(foreign)
+	return foreign, nil
+}
+
+// inventoryDrift compares the inventory recorded in state, prior, with the
+// one just built from disk, current, and describes each file that was
+// changed, deleted, or had its executable bit flipped since, sorted by path.
+// Sub-agents that agentctx_subagent wrote into the plugin, and their
+// registry, belong to those resources and are skipped. So is the manifest
+// when the set of such sub-agents changed, since registering one rewrites
+// it. An empty prior, as in state written before inventory_json existed,
+// reports nothing.
+func inventoryDrift(prior, current string) ([]string, error) {
+	if prior == "" {
+		return nil, nil
+	}
+	var before, after []inventoryEntry
+	if err := json.Unmarshal([]byte(prior), &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(current), &after); err != nil {
+		return nil, err
+	}
+
+	registrationChanged := registeredAgentList(before) != registeredAgentList(after)
+
+	now := make(map[string]inventoryEntry, len(after))
+	for _, e := range after {
+		now[e.Path] = e
+	}
+	var drift []string
+	for _, e := range before {
+		if e.Source == "agentctx_subagent" || e.Type == "agent_registry" || (e.Type == "manifest" && registrationChanged) {
+			continue
+		}
+		got, ok := now[e.Path]
+		switch {
+		case !ok:
+			drift = append(drift, e.Path+" was deleted")
+		case got.Hash != e.Hash:
+			drift = append(drift, fmt.Sprintf("%s has hash %s, want %s", e.Path, got.Hash, e.Hash))
+		case got.Executable != e.Executable:
+			drift = append(drift, fmt.Sprintf("%s has executable = %t, want %t", e.Path, got.Executable, e.Executable))
+		}
+	}
+	return drift, nil
+}
+
+// registeredAgentList returns the names of the sub-agents that
+// agentctx_subagent wrote into the plugin, as listed in list, joined by
+// commas.
+func registeredAgentList(list []inventoryEntry) string {
+	var names []string
+	for _, e := range list {
+		if e.Source == "agentctx_subagent" {
+			names = append(names, e.Component)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// generatedFileHashes returns file_hashes for inventoryJSON: the hash of
+// every file the resource itself generates, keyed by path. Sub-agents that
+// agentctx_subagent wrote into the plugin, and their registry, are left out.
+func generatedFileHashes(inventoryJSON string) (map[string]string, error) {
+	var inventory []inventoryEntry
+	if err := json.Unmarshal([]byte(inventoryJSON), &inventory); err != nil {
+		return nil, err
+	}
+	hashes := make(map[string]string, len(inventory))
+	for _, e := range inventory {
+		if e.Source != "agentctx_subagent" && e.Type != "agent_registry" {
+			hashes[e.Path] = e.Hash
+		}
+	}
+	return hashes, nil
+}
+
+// refreshFileHashes returns the file_hashes to keep in state after a
+// refresh. hashes, the file_hashes of the last apply, stay as they are, so
+// the next plan still sees files that were changed outside Terraform, with
+// two exceptions: without hashes, as in state written before file_hashes
+// existed or right after an import, the files on disk become the baseline;
+// and when the set of sub-agents written into the plugin changed between
+// the prior and current inventory, the manifest they rewrote is taken as
+// it is on disk.
+func refreshFileHashes(hashes map[string]string, prior, current string) (map[string]string, error) {
+	currentHashes, err := generatedFileHashes(current)
+	if err != nil || hashes == nil || prior == "" {
+		return currentHashes, err
+	}
+	var before, after []inventoryEntry
+	if err := json.Unmarshal([]byte(prior), &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(current), &after); err != nil {
+		return nil, err
+	}
+	if registeredAgentList(before) == registeredAgentList(after) {
+		return hashes, nil
+	}
+	kept := make(map[string]string, len(hashes))
+	for p, h := range hashes {
+		kept[p] = h
+	}
+	if h, ok := currentHashes[pluginJSONPath]; ok {
+		kept[pluginJSONPath] = h
+	}
+	return kept, nil
+}
+
+// treeDrift rehashes the files in hashes, the file_hashes of the last
+// apply, in fsDir and describes each one that was changed or deleted
+// since, sorted by path.
+func treeDrift(fsDir string, hashes map[string]string) ([]string, error) {
+	paths := make([]string, 0, len(hashes))
+	for p := range hashes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var drift []string
+	for _, p := range paths {
+		got, err := bundle.ComputeFileHash(filepath.Join(fsDir, filepath.FromSlash(p)))
+		if err != nil {
+			if _, statErr := os.Stat(filepath.Join(fsDir, filepath.FromSlash(p))); os.IsNotExist(statErr) {
+				drift = append(drift, p+" was deleted")
+				continue
+			}
+			return nil, err
+		}
+		if got != hashes[p] {
+			drift = append(drift, fmt.Sprintf("%s has hash %s, want %s", p, got, hashes[p]))
+		}
+	}
+	return drift, nil
+}
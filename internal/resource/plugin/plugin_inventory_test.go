@@ -0,0 +1,288 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+)
+
+func decodeInventory(t *testing.T, raw string) map[string]inventoryEntry {
+	t.Helper()
+	var list []inventoryEntry
+	if err := json.Unmarshal([]byte(raw), &list); err != nil {
+		t.Fatalf("inventory_json is not valid JSON: %v", err)
+	}
+	byPath := make(map[string]inventoryEntry, len(list))
+	for i, e := range list {
+		if i > 0 && list[i-1].Path >= e.Path {
+			t.Errorf("inventory not sorted: %q before %q", list[i-1].Path, e.Path)
+		}
+		byPath[e.Path] = e
+	}
+	return byPath
+}
+
+func TestWritePlugin_Inventory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	srcFile := filepath.Join(t.TempDir(), "deploy.md")
+	if err := os.WriteFile(srcFile, []byte("Deploy.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	model := scaffoldModel(dir)
+	model.Commands = []PluginCommandModel{
+		{Name: stringValue("deploy"), SourceFile: stringValue(srcFile), Content: types.StringNull()},
+	}
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	got := decodeInventory(t, model.InventoryJSON.ValueString())
+	want := map[string]inventoryEntry{
+		".claude-plugin/plugin.json": {Type: "manifest", Source: "generated"},
+		"agents/reviewer.md":         {Type: "agent", Component: "reviewer", Source: "inline"},
+		"commands/deploy.md":         {Type: "command", Component: "deploy", Source: "source_file", SourcePath: srcFile},
+		"hooks/hooks.json":           {Type: "hooks", Source: "generated"},
+		"scripts/format.sh":          {Type: "file", Source: "inline", Executable: true},
+		"skills/lint/SKILL.md":       {Type: "skill", Component: "lint", Source: "inline"},
+		"tests/validate_plugin.py":   {Type: "test", Source: "generated", Executable: true},
+	}
+	if len(got) != len(want) {
+		t.Errorf("inventory has %d entries, want %d: %v", len(got), len(want), got)
+	}
+	for p, w := range want {
+		e, ok := got[p]
+		if !ok {
+			t.Errorf("inventory is missing %s", p)
+			continue
+		}
+		if runtime.GOOS == "windows" {
+			w.Executable = false
+		}
+		if e.Type != w.Type || e.Component != w.Component || e.Source != w.Source || e.SourcePath != w.SourcePath || e.Executable != w.Executable {
+			t.Errorf("%s = %+v, want %+v", p, e, w)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Hash != bundle.ComputeFileHashBytes(data) || e.Size != int64(len(data)) {
+			t.Errorf("%s hash/size = %s/%d, do not match disk", p, e.Hash, e.Size)
+		}
+	}
+}
+
+func TestBuildInventory_SourceDirSkillAndMissingFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	srcDir := t.TempDir()
+	for rel, content := range map[string]string{"SKILL.md": "# S\n", "scripts/run.sh": "echo\n"} {
+		p := filepath.Join(srcDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := &PluginResourceModel{
+		Name:      stringValue("inv"),
+		OutputDir: stringValue(dir),
+		Keywords:  types.ListNull(types.StringType),
+		Skills: []PluginSkillModel{
+			{Name: stringValue("s"), SourceDir: stringValue(srcDir), SourceBundle: types.StringNull(), Content: types.StringNull()},
+		},
+	}
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	got := decodeInventory(t, model.InventoryJSON.ValueString())
+	run, ok := got["skills/s/scripts/run.sh"]
+	if !ok || run.Source != "source_dir" || run.SourcePath != filepath.Join(srcDir, "scripts", "run.sh") {
+		t.Errorf("skills/s/scripts/run.sh = %+v", run)
+	}
+
+	// A refresh after an external deletion drops the file.
+	if err := os.Remove(filepath.Join(dir, "skills", "s", "SKILL.md")); err != nil {
+		t.Fatal(err)
+	}
+	inventory, err := buildInventory(dir, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decodeInventory(t, inventory)["skills/s/SKILL.md"]; ok {
+		t.Error("deleted file still listed")
+	}
+}
+
+func TestUnmanagedFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if n := len(model.UnmanagedFiles.Elements()); n != 0 {
+		t.Errorf("freshly written plugin has %d unmanaged files: %v", n, model.UnmanagedFiles)
+	}
+
+	for _, rel := range []string{"notes/todo.md", "skills/lint/extra.txt", "README.md", lockFileName} {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := unmanagedFiles(dir, model.InventoryJSON.ValueString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"README.md", "notes/todo.md", "skills/lint/extra.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("unmanagedFiles = %v, want %v", got, want)
+	}
+
+	// A missing directory has no unmanaged files.
+	got, err = unmanagedFiles(filepath.Join(t.TempDir(), "missing"), "[]")
+	if err != nil || len(got) != 0 {
+		t.Errorf("unmanagedFiles(missing) = %v, %v; want none", got, err)
+	}
+}
+
+func TestInventoryDrift(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	prior := model.InventoryJSON.ValueString()
+
+	current := func() string {
+		t.Helper()
+		inventory, err := buildInventory(dir, model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return inventory
+	}
+	if drift, err := inventoryDrift(prior, current()); err != nil || len(drift) != 0 {
+		t.Fatalf("inventoryDrift of an unchanged plugin = %v, %v; want none", drift, err)
+	}
+
+	// Registering a sub-agent rewrites the manifest, which is not drift.
+	if _, err := RegisterAgent(ctx, dir, "helper", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if drift, err := inventoryDrift(prior, current()); err != nil || len(drift) != 0 {
+		t.Errorf("inventoryDrift after RegisterAgent = %v, %v; want none", drift, err)
+	}
+	prior = current()
+
+	if err := os.WriteFile(filepath.Join(dir, "agents", "reviewer.md"), []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, ".claude-plugin", "plugin.json")); err != nil {
+		t.Fatal(err)
+	}
+	drift, err := inventoryDrift(prior, current())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 2 || drift[0] != ".claude-plugin/plugin.json was deleted" || !strings.HasPrefix(drift[1], "agents/reviewer.md has hash ") {
+		t.Errorf("inventoryDrift = %q, want the deleted manifest and the edited agent", drift)
+	}
+
+	if drift, err := inventoryDrift("", current()); err != nil || len(drift) != 0 {
+		t.Errorf("inventoryDrift without a prior inventory = %v, %v; want none", drift, err)
+	}
+}
+
+func TestTreeDrift(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	var hashes map[string]string
+	if diags := model.FileHashes.ElementsAs(ctx, &hashes, false); diags.HasError() {
+		t.Fatalf("file_hashes: %v", diags)
+	}
+	for _, p := range []string{pluginJSONPath, "hooks/hooks.json", "scripts/format.sh", "skills/lint/SKILL.md"} {
+		if _, ok := hashes[p]; !ok {
+			t.Errorf("file_hashes lacks %s: %v", p, hashes)
+		}
+	}
+	if drift, err := treeDrift(dir, hashes); err != nil || len(drift) != 0 {
+		t.Fatalf("treeDrift of an unchanged plugin = %v, %v; want none", drift, err)
+	}
+
+	// A registered sub-agent is not in file_hashes, and a refresh accepts
+	// the manifest it rewrote.
+	prior := model.InventoryJSON.ValueString()
+	if _, err := RegisterAgent(ctx, dir, "helper", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	current, err := buildInventory(dir, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes, err = refreshFileHashes(hashes, prior, current); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hashes["agents/helper.md"]; ok {
+		t.Error("file_hashes lists the registered sub-agent")
+	}
+	if drift, err := treeDrift(dir, hashes); err != nil || len(drift) != 0 {
+		t.Errorf("treeDrift after RegisterAgent = %v, %v; want none", drift, err)
+	}
+
+	// Other changes are kept as drift across refreshes.
+	if err := os.WriteFile(filepath.Join(dir, "hooks", "hooks.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "scripts", "format.sh")); err != nil {
+		t.Fatal(err)
+	}
+	prior = current
+	if current, err = buildInventory(dir, model); err != nil {
+		t.Fatal(err)
+	}
+	if hashes, err = refreshFileHashes(hashes, prior, current); err != nil {
+		t.Fatal(err)
+	}
+	drift, err := treeDrift(dir, hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 2 || !strings.HasPrefix(drift[0], "hooks/hooks.json has hash ") || drift[1] != "scripts/format.sh was deleted" {
+		t.Errorf("treeDrift = %q, want the edited hooks and the deleted script", drift)
+	}
+
+	// Without file_hashes, the files on disk become the baseline.
+	if hashes, err = refreshFileHashes(nil, prior, current); err != nil {
+		t.Fatal(err)
+	}
+	if drift, err := treeDrift(dir, hashes); err != nil || len(drift) != 0 {
+		t.Errorf("treeDrift against a new baseline = %v, %v; want none", drift, err)
+	}
+}
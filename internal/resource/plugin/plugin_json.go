@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Values of the json_format attribute.
+const (
+	jsonFormatIndented = "indented"
+	jsonFormatCompact  = "compact"
+)
+
+// JSON files the provider generates, relative to output_dir. These are the
+// keys json_schemas accepts.
+const (
+	pluginJSONPath = ".claude-plugin/plugin.json"
+	hooksJSONPath  = "hooks/hooks.json"
+	mcpJSONPath    = ".mcp.json"
+	lspJSONPath    = ".lsp.json"
+)
+
+var jsonSchemaPaths = []string{pluginJSONPath, hooksJSONPath, mcpJSONPath, lspJSONPath}
+
+// jsonOptions controls how generated JSON files are formatted.
+type jsonOptions struct {
+	compact bool
+	// schemas maps a generated file's relative path to the URL written as
+	// its "$schema" property.
+	schemas map[string]string
+}
+
+// jsonOptionsFromModel reads json_format and json_schemas. Unset attributes
+// select indented output without "$schema" properties.
+func jsonOptionsFromModel(ctx context.Context, model *PluginResourceModel) (jsonOptions, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	opts := jsonOptions{compact: model.JSONFormat.ValueString() == jsonFormatCompact}
+	if !model.JSONSchemas.IsNull() && !model.JSONSchemas.IsUnknown() {
+		diags.Append(model.JSONSchemas.ElementsAs(ctx, &opts.schemas, false)...)
+	}
+	return opts, diags
+}
+
+// marshal encodes v as the generated file relPath: with a leading "$schema"
+// property if one is configured for it, indented with two spaces unless
+// compact output is selected, and with a trailing newline. Map keys are
+// sorted and struct fields keep their declaration order, so the output is
+// deterministic.
+func (o jsonOptions) marshal(relPath string, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if url := o.schemas[relPath]; url != "" {
+		if data, err = withSchema(data, url); err != nil {
+			return nil, err
+		}
+	}
+	if !o.compact {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+	}
+	return append(data, '\n'), nil
+}
+
+// withSchema inserts "$schema": url as the first property of the compact
+// JSON object obj.
+func withSchema(obj []byte, url string) ([]byte, error) {
+	if len(obj) < 2 || obj[0] != '{' {
+		return nil, fmt.Errorf("$schema can only be added to a JSON object")
+	}
+	value, err := json.Marshal(url)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(`{"$schema":`), value...)
+	if string(obj) != "{}" {
+		out = append(out, ',')
+	}
+	return append(out, obj[1:]...), nil
+}
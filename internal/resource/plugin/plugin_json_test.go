@@ -0,0 +1,95 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestJSONOptions_Marshal(t *testing.T) {
+	v := map[string]interface{}{"b": 1, "a": []int{1, 2}}
+	for _, tc := range []struct {
+		name string
+		opts jsonOptions
+		want string
+	}{
+		{"indented", jsonOptions{}, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": 1\n}\n"},
+		{"compact", jsonOptions{compact: true}, "{\"a\":[1,2],\"b\":1}\n"},
+		{
+			"schema",
+			jsonOptions{compact: true, schemas: map[string]string{mcpJSONPath: "https://example.com/mcp.json"}},
+			"{\"$schema\":\"https://example.com/mcp.json\",\"a\":[1,2],\"b\":1}\n",
+		},
+		{
+			"schema for another file",
+			jsonOptions{compact: true, schemas: map[string]string{lspJSONPath: "https://example.com/lsp.json"}},
+			"{\"a\":[1,2],\"b\":1}\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.opts.marshal(mcpJSONPath, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("marshal = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	got, err := jsonOptions{schemas: map[string]string{mcpJSONPath: "s"}}.marshal(mcpJSONPath, map[string]string{})
+	if err != nil || string(got) != "{\n  \"$schema\": \"s\"\n}\n" {
+		t.Errorf("marshal of empty object = %q, %v", got, err)
+	}
+	if _, err := (jsonOptions{schemas: map[string]string{mcpJSONPath: "s"}}).marshal(mcpJSONPath, []int{1}); err == nil {
+		t.Error("expected an error adding $schema to an array")
+	}
+}
+
+func TestWritePlugin_JSONFormatAndSchemas(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	model.JSONFormat = types.StringValue(jsonFormatCompact)
+	model.JSONSchemas = types.MapValueMust(types.StringType, map[string]attr.Value{
+		pluginJSONPath: types.StringValue("https://example.com/plugin.schema.json"),
+	})
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(pluginJSONPath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), `{"$schema":"https://example.com/plugin.schema.json","name":"scaffold"`) {
+		t.Errorf("plugin.json = %s, want a compact manifest starting with $schema", data)
+	}
+	if strings.Count(string(data), "\n") != 1 {
+		t.Errorf("plugin.json has %d lines, want 1", strings.Count(string(data), "\n"))
+	}
+
+	hooks, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(hooksJSONPath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(hooks, &parsed); err != nil {
+		t.Fatalf("hooks.json: %v", err)
+	}
+	if _, ok := parsed["$schema"]; ok || strings.Count(string(hooks), "\n") != 1 {
+		t.Errorf("hooks.json = %s, want compact output without $schema", hooks)
+	}
+
+	// The generated validation script still accepts the plugin.
+	if out, ok := runValidateScript(t, dir); !ok {
+		t.Errorf("script failed on a compact plugin with $schema:\n%s", out)
+	}
+}
@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/filelock"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// lockFileName is the advisory lock the provider holds in output_dir while
+// it writes the plugin. Other tools that generate into the same directory
+// can take the same lock (flock on Unix, LockFileEx on Windows) to avoid
+// interleaving with an apply.
+const lockFileName = ".agentctx.lock"
+
+// defaultLockTimeoutSeconds is how long to wait for the lock when
+// lock_timeout_seconds is not set, e.g. in state written by older versions.
+const defaultLockTimeoutSeconds = 60
+
+// lockOutputDir creates outputDir if needed and locks it, waiting up to the
+// configured timeout for other writers to finish. The returned function
+// releases the lock.
+func lockOutputDir(ctx context.Context, outputDir string, timeoutSeconds types.Int64) (func(), diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	absDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		diags.AddAttributeError(path.Root("output_dir"), errcode.PathResolution.Summary("Path Resolution Failed"), fmt.Sprintf("Failed to resolve absolute path for %q: %s", outputDir, err))
+		return nil, diags
+	}
+	if err := os.MkdirAll(longpath.Path(absDir), 0o755); err != nil {
+		diags.AddAttributeError(path.Root("output_dir"), errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create plugin directory: %s", err))
+		return nil, diags
+	}
+
+	seconds := int64(defaultLockTimeoutSeconds)
+	if !timeoutSeconds.IsNull() && !timeoutSeconds.IsUnknown() {
+		seconds = timeoutSeconds.ValueInt64()
+	}
+	timeout := time.Duration(seconds) * time.Second
+
+	lockPath := filepath.Join(absDir, lockFileName)
+	lock, err := filelock.Acquire(ctx, longpath.Path(lockPath), timeout)
+	if err != nil {
+		detail := fmt.Sprintf("Failed to lock %q: %s", lockPath, err)
+		if errors.Is(err, filelock.ErrTimeout) {
+			detail = fmt.Sprintf("Another process is writing %q and still held %s after %s. Wait for it to finish and apply again, or raise lock_timeout_seconds.", absDir, lockFileName, timeout)
+		}
+		diags.AddAttributeError(path.Root("output_dir"), errcode.FileWrite.Summary("Output Directory Locked"), detail)
+		return nil, diags
+	}
+
+	return func() {
+		if err := lock.Release(); err != nil {
+			tflog.Warn(ctx, "failed to release plugin directory lock", map[string]interface{}{
+				"lock_file": lockPath,
+				"error":     err.Error(),
+			})
+		}
+	}, diags
+}
+
+// removeAllExcept removes every entry of dir except the one named keep.
+func removeAllExcept(dir, keep string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Name() == keep {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestLockOutputDir_ExcludesConcurrentWriters(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	ctx := context.Background()
+
+	unlock, diags := lockOutputDir(ctx, dir, types.Int64Value(0))
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if _, err := os.Stat(filepath.Join(dir, lockFileName)); err != nil {
+		t.Fatalf("lock file not created: %v", err)
+	}
+
+	_, diags = lockOutputDir(ctx, dir, types.Int64Value(0))
+	if !diags.HasError() || !strings.Contains(diags.Errors()[0].Summary(), "Output Directory Locked") {
+		t.Fatalf("expected a locked directory error, got %v", diags)
+	}
+	if !strings.Contains(diags.Errors()[0].Detail(), "lock_timeout_seconds") {
+		t.Errorf("detail does not mention lock_timeout_seconds: %s", diags.Errors()[0].Detail())
+	}
+
+	unlock()
+	unlock, diags = lockOutputDir(ctx, dir, types.Int64Null())
+	if diags.HasError() {
+		t.Fatalf("lock not released: %v", diags.Errors())
+	}
+	unlock()
+}
+
+func TestWritePlugin_KeepsLockFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	ctx := context.Background()
+
+	unlock, diags := lockOutputDir(ctx, dir, types.Int64Value(0))
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	defer unlock()
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, scaffoldModel(dir)); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if _, err := os.Stat(filepath.Join(dir, lockFileName)); err != nil {
+		t.Errorf("regeneration removed the lock file: %v", err)
+	}
+
+	if err := removeAllExcept(dir, lockFileName); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != lockFileName {
+		t.Errorf("entries after removeAllExcept = %v, want only the lock file", entries)
+	}
+}
@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/provenance"
+)
+
+// provenancePath is where provenance = true writes the plugin's provenance
+// statement. It lives under .claude-plugin, so regenerating the plugin
+// removes it once provenance is turned off.
+const provenancePath = ".claude-plugin/" + provenance.FileName
+
+// writeProvenance renders a provenance statement for the files already
+// written to fsDir and writes it to provenancePath. Inline content comes
+// from the Terraform configuration, so the working directory is recorded as
+// a source alongside every copied file.
+func writeProvenance(fsDir, absDir string, model *PluginResourceModel, providerVersion string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	entries, err := collectInventory(fsDir, model)
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to hash the generated plugin files: %s", err))
+		return diags
+	}
+
+	files := make(map[string]string, len(entries))
+	sourceDirs := make(map[string]bool)
+	if wd, err := os.Getwd(); err == nil {
+		sourceDirs[wd] = true
+	}
+	for _, e := range entries {
+		if e.Path == provenancePath {
+			continue
+		}
+		files[e.Path] = e.Hash
+		if e.SourcePath != "" {
+			sourceDirs[filepath.Dir(e.SourcePath)] = true
+		}
+	}
+	sourcePaths := make([]string, 0, len(sourceDirs))
+	for dir := range sourceDirs {
+		sourcePaths = append(sourcePaths, dir)
+	}
+	sort.Strings(sourcePaths)
+
+	params := map[string]any{
+		"name":       model.Name.ValueString(),
+		"output_dir": absDir,
+	}
+	if !model.Version.IsNull() && !model.Version.IsUnknown() {
+		params["version"] = model.Version.ValueString()
+	}
+
+	data, err := provenance.Generate(provenance.Input{
+		BuildType:       provenance.BuildTypePlugin,
+		Files:           files,
+		Parameters:      params,
+		SourcePaths:     sourcePaths,
+		ProviderVersion: providerVersion,
+	})
+	if err != nil {
+		diags.AddAttributeError(path.Root("provenance"), errcode.Encoding.Summary("Provenance Generation Failed"), fmt.Sprintf("Failed to generate plugin provenance: %s", err))
+		return diags
+	}
+	if err := atomicfile.WriteFile(filepath.Join(fsDir, filepath.FromSlash(provenancePath)), data, 0o644); err != nil {
+		diags.AddAttributeError(path.Root("provenance"), errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write %s: %s", provenancePath, err))
+		return diags
+	}
+	return diags
+}
@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/provenance"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+)
+
+func TestWritePlugin_Provenance(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	model.Provenance = types.BoolValue(true)
+
+	r := &PluginResource{providerData: &providerdata.ProviderData{Version: "1.2.3"}}
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(provenancePath)))
+	if err != nil {
+		t.Fatalf("provenance not written: %v", err)
+	}
+	var st provenance.Statement
+	if err := json.Unmarshal(data, &st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Predicate.BuildDefinition.BuildType != provenance.BuildTypePlugin {
+		t.Errorf("buildType = %q", st.Predicate.BuildDefinition.BuildType)
+	}
+	if v := st.Predicate.RunDetails.Builder.Version["terraform-provider-agentctx"]; v != "1.2.3" {
+		t.Errorf("builder version = %q", v)
+	}
+
+	// Every other inventoried file is a subject with a matching digest.
+	inventory := decodeInventory(t, model.InventoryJSON.ValueString())
+	if e, ok := inventory[provenancePath]; !ok || e.Type != "provenance" {
+		t.Errorf("inventory entry for %s = %+v", provenancePath, e)
+	}
+	subjects := make(map[string]string, len(st.Subject))
+	for _, s := range st.Subject {
+		subjects[s.Name] = "sha256:" + s.Digest["sha256"]
+	}
+	if _, ok := subjects[provenancePath]; ok {
+		t.Error("statement lists itself as a subject")
+	}
+	if len(subjects) != len(inventory)-1 {
+		t.Errorf("statement has %d subjects, want %d", len(subjects), len(inventory)-1)
+	}
+	for p, e := range inventory {
+		if p != provenancePath && subjects[p] != e.Hash {
+			t.Errorf("subject %s = %q, want %q", p, subjects[p], e.Hash)
+		}
+	}
+
+	// Turning provenance off removes the statement.
+	model.Provenance = types.BoolValue(false)
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(provenancePath))); !os.IsNotExist(err) {
+		t.Errorf("provenance left behind: %v", err)
+	}
+}
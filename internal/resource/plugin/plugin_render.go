@@ -0,0 +1,203 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// renderedPlugin holds the generated JSON files of a plugin, encoded as
+// written to disk. A nil file is not generated.
+type renderedPlugin struct {
+	manifestJSON []byte
+	hooksJSON    []byte
+	mcpJSON      []byte
+	lspJSON      []byte
+}
+
+// RenderedPlugin is the exported form of a rendered plugin, for data
+// sources that preview agentctx_plugin output. Files that would not be
+// generated are empty.
+type RenderedPlugin struct {
+	ManifestJSON string
+	HooksJSON    string
+	McpJSON      string
+	LspJSON      string
+}
+
+// Render returns the JSON files of the plugin described by model, exactly
+// as agentctx_plugin writes them, without touching disk. Component blocks
+// only contribute their names; their sources are not read. level is the
+// compatibility level to render for, nil for none.
+func Render(ctx context.Context, model *PluginResourceModel, level *claudeversion.Level) (RenderedPlugin, diag.Diagnostics) {
+	rendered, diags := (&PluginResource{}).render(ctx, model, level)
+	return RenderedPlugin{
+		ManifestJSON: string(rendered.manifestJSON),
+		HooksJSON:    string(rendered.hooksJSON),
+		McpJSON:      string(rendered.mcpJSON),
+		LspJSON:      string(rendered.lspJSON),
+	}, diags
+}
+
+// render builds the manifest and the hooks, MCP, and LSP configuration of
+// model from its arguments alone. writePlugin writes the result after
+// copying the components the manifest refers to. Features level does not
+// allow are left out, with a warning.
+func (r *PluginResource) render(ctx context.Context, model *PluginResourceModel, level *claudeversion.Level) (renderedPlugin, diag.Diagnostics) {
+	var rendered renderedPlugin
+	var diags diag.Diagnostics
+
+	jsonOpts, d := jsonOptionsFromModel(ctx, model)
+	diags.Append(d...)
+	if diags.HasError() {
+		return rendered, diags
+	}
+
+	compat := &compatFilter{level: level}
+
+	// Build the manifest.
+	manifest := pluginManifest{
+		Name: model.Name.ValueString(),
+	}
+
+	if !model.Version.IsNull() && !model.Version.IsUnknown() {
+		manifest.Version = model.Version.ValueString()
+	}
+	if !model.Description.IsNull() && !model.Description.IsUnknown() {
+		manifest.Description = model.Description.ValueString()
+	}
+	if !model.Homepage.IsNull() && !model.Homepage.IsUnknown() {
+		manifest.Homepage = validators.NormalizeURL(model.Homepage.ValueString())
+	}
+	if !model.Repository.IsNull() && !model.Repository.IsUnknown() {
+		manifest.Repository = validators.NormalizeURL(model.Repository.ValueString())
+	}
+	if !model.License.IsNull() && !model.License.IsUnknown() {
+		manifest.License = model.License.ValueString()
+	}
+	if !model.Keywords.IsNull() && !model.Keywords.IsUnknown() {
+		var keywords []string
+		d := model.Keywords.ElementsAs(ctx, &keywords, false)
+		diags.Append(withAttributePath(path.Root("keywords"), d)...)
+		if diags.HasError() {
+			return rendered, diags
+		}
+		manifest.Keywords = keywords
+	}
+	if !model.RequiresClaudeVersion.IsNull() && !model.RequiresClaudeVersion.IsUnknown() {
+		manifest.RequiresClaudeVersion = model.RequiresClaudeVersion.ValueString()
+	}
+
+	// Output styles
+	if len(model.OutputStyles) > 0 {
+		paths := make([]string, 0, len(model.OutputStyles))
+		for i, s := range model.OutputStyles {
+			relPath := s.Path.ValueString()
+			if filepath.IsAbs(relPath) || strings.Contains(relPath, "..") {
+				diags.AddAttributeError(
+					path.Root("output_style").AtListIndex(i).AtName("path"),
+					errcode.PathTraversal.Summary("Invalid Output Style Path"),
+					fmt.Sprintf("Output style path %q must be relative and must not contain '..'.", relPath),
+				)
+				return rendered, diags
+			}
+
+			paths = append(paths, withDotSlash(relPath))
+		}
+		manifest.OutputStyles = paths
+	}
+
+	// Author
+	if len(model.Author) > 0 {
+		a := model.Author[0]
+		ma := &manifestAuthor{Name: a.Name.ValueString()}
+		if !a.Email.IsNull() && !a.Email.IsUnknown() {
+			ma.Email = a.Email.ValueString()
+		}
+		if !a.URL.IsNull() && !a.URL.IsUnknown() {
+			ma.URL = validators.NormalizeURL(a.URL.ValueString())
+		}
+		manifest.Author = ma
+	}
+
+	// Components are listed by name; writePlugin copies their files.
+	for _, s := range model.Skills {
+		manifest.Skills = append(manifest.Skills, fmt.Sprintf("./skills/%s/", s.Name.ValueString()))
+	}
+	for _, a := range model.Agents {
+		manifest.Agents = append(manifest.Agents, fmt.Sprintf("./agents/%s.md", a.Name.ValueString()))
+	}
+	for _, c := range model.Commands {
+		manifest.Commands = append(manifest.Commands, fmt.Sprintf("./commands/%s.md", c.Name.ValueString()))
+	}
+
+	// Hooks
+	if len(model.Hooks) > 0 {
+		hooksConfig := r.buildHooksJSON(model.Hooks[0])
+		compat.filterHookEvents(hooksConfig)
+		if len(hooksConfig) > 0 {
+			hooksJSON, err := jsonOpts.marshal(hooksJSONPath, map[string]interface{}{"hooks": hooksConfig})
+			if err != nil {
+				diags.AddAttributeError(path.Root("hooks").AtListIndex(0), errcode.Encoding.Summary("JSON Marshal Failed"), fmt.Sprintf("Failed to marshal hooks configuration: %s", err))
+				return rendered, diags
+			}
+			rendered.hooksJSON = hooksJSON
+			manifest.Hooks = "./hooks/hooks.json"
+		}
+	}
+
+	// MCP Servers
+	if len(model.McpServers) > 0 {
+		diags.Append(r.validateMcpServers(ctx, model.McpServers)...)
+		if diags.HasError() {
+			return rendered, diags
+		}
+
+		mcpConfig := r.buildMcpJSON(ctx, model.McpServers, &diags)
+		if diags.HasError() {
+			return rendered, diags
+		}
+		compat.filterMcpServers(mcpConfig)
+		if len(mcpConfig) > 0 {
+			mcpJSON, err := jsonOpts.marshal(mcpJSONPath, map[string]interface{}{"mcpServers": mcpConfig})
+			if err != nil {
+				diags.AddError(errcode.Encoding.Summary("JSON Marshal Failed"), fmt.Sprintf("Failed to marshal MCP configuration: %s", err))
+				return rendered, diags
+			}
+			rendered.mcpJSON = mcpJSON
+			manifest.McpServers = "./.mcp.json"
+		}
+	}
+
+	// LSP Servers
+	if len(model.LspServers) > 0 && !compat.omits(claudeversion.ManifestFieldFeature("lspServers")) {
+		lspConfig := r.buildLspJSON(ctx, model.LspServers, &diags)
+		if diags.HasError() {
+			return rendered, diags
+		}
+		lspJSON, err := jsonOpts.marshal(lspJSONPath, lspConfig)
+		if err != nil {
+			diags.AddError(errcode.Encoding.Summary("JSON Marshal Failed"), fmt.Sprintf("Failed to marshal LSP configuration: %s", err))
+			return rendered, diags
+		}
+		rendered.lspJSON = lspJSON
+		manifest.LspServers = "./.lsp.json"
+	}
+
+	manifestJSON, err := jsonOpts.marshal(pluginJSONPath, manifest)
+	if err != nil {
+		diags.AddError(errcode.Encoding.Summary("JSON Marshal Failed"), fmt.Sprintf("Failed to marshal plugin manifest: %s", err))
+		return rendered, diags
+	}
+	rendered.manifestJSON = manifestJSON
+
+	diags.Append(compat.diagnostics()...)
+	return rendered, diags
+}
@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRender_MatchesWrittenFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "compat-plugin")
+	model := compatTestModel(dir)
+
+	rendered, diags := Render(context.Background(), model, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("Render touched disk (stat err %v)", err)
+	}
+
+	if diags := (&PluginResource{}).writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags)
+	}
+	for file, want := range map[string]string{
+		filepath.Join(".claude-plugin", "plugin.json"): rendered.ManifestJSON,
+		filepath.Join("hooks", "hooks.json"):           rendered.HooksJSON,
+		".mcp.json":                                    rendered.McpJSON,
+		".lsp.json":                                    rendered.LspJSON,
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s differs from Render:\nwritten: %s\nrendered: %s", file, got, want)
+		}
+	}
+}
@@ -3,33 +3,50 @@ package plugin
 import (
 	"context"
 	"crypto/sha256"
-	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-)
 
-// namePattern validates plugin names: lowercase letters, numbers, and hyphens,
-// starting and ending with a letter or number.
-var namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/contentscan"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
 
 // Compile-time interface checks.
-var _ resource.Resource = &PluginResource{}
+var (
+	_ resource.Resource                   = &PluginResource{}
+	_ resource.ResourceWithConfigure      = &PluginResource{}
+	_ resource.ResourceWithValidateConfig = &PluginResource{}
+	_ resource.ResourceWithModifyPlan     = &PluginResource{}
+)
 
 // NewPluginResource returns a new resource.Resource for the agentctx_plugin type.
 func NewPluginResource() resource.Resource {
@@ -40,7 +57,9 @@ func NewPluginResource() resource.Resource {
 // generates a complete Claude Code plugin directory structure including the
 // plugin.json manifest, skills, agents, commands, hooks, MCP servers, LSP
 // servers, and bundled files.
-type PluginResource struct{}
+type PluginResource struct {
+	providerData *providerdata.ProviderData
+}
 
 // --------------------------------------------------------------------------
 // Metadata
@@ -67,10 +86,7 @@ func (r *PluginResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 					stringplanmodifier.RequiresReplace(),
 				},
 				Validators: []validator.String{
-					stringvalidator.RegexMatches(
-						namePattern,
-						"must contain only lowercase letters, numbers, and hyphens, and must start and end with a letter or number",
-					),
+					validators.Name(),
 				},
 			},
 			"output_dir": schema.StringAttribute{
@@ -107,6 +123,100 @@ func (r *PluginResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Optional:            true,
 				ElementType:         types.StringType,
 			},
+			"requires_claude_version": schema.StringAttribute{
+				MarkdownDescription: "Version constraint on the Claude Code releases that can load the plugin (e.g. `>= 2.0.45`). Written to the manifest as `requiresClaudeVersion`. The provider warns when the plugin uses features, such as newer hook events, that the constraint does not guarantee.",
+				Optional:            true,
+			},
+			"compatibility_level": schema.StringAttribute{
+				MarkdownDescription: "Oldest Claude Code release, such as `2.0.30`, the generated plugin must load in. Overrides the provider's `compatibility_level`. Hook events, manifest fields, and MCP servers that need a newer release are left out of the generated files, with a warning.",
+				Optional:            true,
+			},
+			"env_var_policy": schema.StringAttribute{
+				MarkdownDescription: "How to report `${VAR}` references in hook, MCP, and LSP commands to variables Claude Code does not set and that are not listed in `known_env_vars`. Valid values: `warn` (default), `error`, `ignore`. References with a default (`${VAR:-value}`) and, in hook commands, escaped references (`\\${VAR}`) are never reported.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("warn"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("warn", "error", "ignore"),
+				},
+			},
+			"known_env_vars": schema.ListAttribute{
+				MarkdownDescription: "Additional environment variable names that users are expected to provide, accepted in `${VAR}` references alongside the variables Claude Code sets (such as `CLAUDE_PLUGIN_ROOT` and `CLAUDE_PROJECT_DIR`).",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"verify_script_references": schema.BoolAttribute{
+				MarkdownDescription: "When `true` (the default), `${CLAUDE_PLUGIN_ROOT}/...` paths in hook, MCP, and LSP commands must name a file the plugin generates or copies from a skill source; validation fails otherwise. Set to `false` for paths created at runtime.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"generate_tests": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, also writes `tests/validate_plugin.py`, a standalone Python 3 script that checks the generated plugin: the manifest parses, every file it and its hook, MCP, and LSP configuration reference exists, and every hook matcher compiles. Run it in CI when the plugin is consumed outside Terraform. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"provenance": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, also writes `.claude-plugin/provenance.intoto.json`, a SLSA provenance statement (an in-toto attestation) listing every generated file with its hash, the git repositories and commits the content was read from, and the provider version. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"json_format": schema.StringAttribute{
+				MarkdownDescription: "Formatting of the generated JSON files (`plugin.json`, `hooks/hooks.json`, `.mcp.json`, and `.lsp.json`): `\"indented\"` (two spaces) or `\"compact\"` (a single line). Both end with a newline and list keys in a stable order. Defaults to `\"indented\"`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(jsonFormatIndented),
+				Validators: []validator.String{
+					stringvalidator.OneOf(jsonFormatIndented, jsonFormatCompact),
+				},
+			},
+			"json_schemas": schema.MapAttribute{
+				MarkdownDescription: "JSON Schema URLs to reference from generated JSON files, keyed by the file's path relative to `output_dir`: `\".claude-plugin/plugin.json\"`, `\"hooks/hooks.json\"`, `\".mcp.json\"`, or `\".lsp.json\"`. Each URL is written as the file's first property, `\"$schema\"`, for editors and validators that use it.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.OneOf(jsonSchemaPaths...)),
+					mapvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"lock_timeout_seconds": schema.Int64Attribute{
+				MarkdownDescription: "How long to wait, in seconds, for another process to release the lock on `output_dir` before failing. The provider holds an advisory lock on `output_dir/.agentctx.lock` while it writes the plugin, so concurrent applies, or other tools that take the same lock, do not interleave. `0` fails immediately if the directory is locked. Defaults to `60`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(defaultLockTimeoutSeconds),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"content_storage": schema.StringAttribute{
+				MarkdownDescription: "What is kept in state for the rendered manifest: `\"full\"` stores it in `manifest_json`; `\"hash_only\"` stores `manifest_json` as null and keeps only `content_hash`, which refresh recomputes from `plugin.json` on disk. Overrides the provider's `state_content` for this resource; unset follows it.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("full", "hash_only"),
+				},
+			},
+			"regenerate_if_missing": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, a refresh that finds `plugin.json` missing writes the plugin again, from `cache_dir` if it holds every file or else by generating it from the last applied arguments, instead of removing the resource from state. For scratch output directories such as clean CI workspaces. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"vars_file": schema.StringAttribute{
+				MarkdownDescription: "Path of a YAML (`.yaml`, `.yml`) or JSON (`.json`) file whose top-level object holds variables for the plugin's content. When set, the `content` of `skill`, `agent`, `command`, and `file` blocks and the `source_file` of `agent` and `command` blocks are rendered as Go templates with the variables as data, e.g. `{{ .api_base_url }}`, so one configuration can generate dev, stage, and prod variants from different files. A reference to a variable the file does not define fails the plan.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"cache_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory of a content-addressed cache that every generated file is also written to, keyed by its hash in `inventory_json`, and that `regenerate_if_missing` restores the plugin from. Can be shared between workspaces.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
 
 			// ---- Computed ----
 			"id": schema.StringAttribute{
@@ -124,13 +234,31 @@ func (r *PluginResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				},
 			},
 			"manifest_json": schema.StringAttribute{
-				MarkdownDescription: "The rendered plugin.json manifest content.",
+				MarkdownDescription: "The rendered plugin.json manifest content. Null when `content_storage` is `\"hash_only\"`, or when it is unset and the provider's `state_content` is `\"hashes\"`.",
 				Computed:            true,
 			},
 			"content_hash": schema.StringAttribute{
 				MarkdownDescription: "SHA-256 hash of the manifest content, prefixed with `sha256:`.",
 				Computed:            true,
 			},
+			"vars_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of the `vars_file` the plugin was last generated with, prefixed with `sha256:`. Every plan rehashes the file and plans an update when it changed. Null without `vars_file`.",
+				Computed:            true,
+			},
+			"inventory_json": schema.StringAttribute{
+				MarkdownDescription: "JSON array describing every file the resource generates, sorted by path: `path`, `type`, `component`, `source`, `source_path`, `hash`, `size`, and `executable`. Intended for packagers, signers, and SBOM generators; decode it with `jsondecode`.",
+				Computed:            true,
+			},
+			"file_hashes": schema.MapAttribute{
+				MarkdownDescription: "SHA-256 hash of every file the resource generates, prefixed with `sha256:` and keyed by path relative to the plugin root, as written by the last apply. Sub-agents written into the plugin by `agentctx_subagent` are left out. Every plan rehashes these files, and plans an update that rewrites the plugin when any of them was changed or deleted outside Terraform.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"unmanaged_files": schema.ListAttribute{
+				MarkdownDescription: "Files in the plugin directory that the resource does not generate, such as files other tools or people added, as sorted paths relative to the plugin root. Recomputed on every refresh. Destroy deletes them along with the generated files.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
 		},
 
 		Blocks: map[string]schema.Block{
@@ -168,42 +296,61 @@ func (r *PluginResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				},
 			},
 			"skill": schema.ListNestedBlock{
-				MarkdownDescription: "Skills to include in the plugin. Each skill is placed in the `skills/<name>/` directory. Provide either `source_dir` to copy an existing skill directory (e.g. from an `agentctx_skill` resource's source) or `content` to write a `SKILL.md` inline.",
+				MarkdownDescription: "Skills to include in the plugin. Each skill is placed in the `skills/<name>/` directory. Provide exactly one of `source_dir` to copy an existing skill directory, `source_bundle` to copy the validated file set of an `agentctx_skill` resource, or `content` to write a `SKILL.md` inline. With `description` set, the resource renders the `SKILL.md` frontmatter from the structured attributes and `content` is the body.",
 				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
 							MarkdownDescription: "Skill name (used as directory name under `skills/`).",
 							Required:            true,
 							Validators: []validator.String{
-								stringvalidator.RegexMatches(
-									namePattern,
-									"must contain only lowercase letters, numbers, and hyphens",
-								),
+								validators.Name(),
 							},
 						},
 						"source_dir": schema.StringAttribute{
-							MarkdownDescription: "Path to an existing skill directory to copy. The entire directory contents are copied into `skills/<name>/`. Use this to reference skills managed by `agentctx_skill` resources.",
+							MarkdownDescription: "Path to an existing skill directory to copy. The entire directory contents are copied into `skills/<name>/`.",
+							Optional:            true,
+						},
+						"source_bundle": schema.StringAttribute{
+							MarkdownDescription: "Bundle descriptor from an `agentctx_skill` resource's `bundle_json` output. Only the files listed in the descriptor are copied into `skills/<name>/`, so the plugin receives the same file set (after `exclude` and symlink validation) that the skill resource deployed. Each file's hash is verified while copying.",
 							Optional:            true,
 						},
 						"content": schema.StringAttribute{
-							MarkdownDescription: "Inline SKILL.md content. Written to `skills/<name>/SKILL.md`.",
+							MarkdownDescription: "Inline SKILL.md content, written to `skills/<name>/SKILL.md`. When `description` and the other attributes below are used, this is only the body under the generated frontmatter, so it must not open with a `---` block of its own.",
+							Optional:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "What the skill does and when Claude should use it. Written to the frontmatter as `description`, with the skill name as `name`. At most 1024 characters, without XML tags. Requires `content`, and is required by the other frontmatter attributes.",
+							Optional:            true,
+							Validators: []validator.String{
+								validators.SkillDescription(),
+							},
+						},
+						"allowed_tools": schema.ListAttribute{
+							MarkdownDescription: "Tools Claude may use without asking for permission while the skill is active, e.g. `Read`. Written to the frontmatter as a comma-separated `allowed-tools`. Requires `description`.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"metadata": schema.MapAttribute{
+							MarkdownDescription: "Additional string properties of the skill, such as `version`. Written to the frontmatter as the `metadata` mapping. Requires `description`.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"license": schema.StringAttribute{
+							MarkdownDescription: "License of the skill, as a name or the path of a bundled license file. Written to the frontmatter as `license`. Requires `description`.",
 							Optional:            true,
 						},
 					},
 				},
 			},
 			"agent": schema.ListNestedBlock{
-				MarkdownDescription: "Agents to include in the plugin. Each agent is placed in the `agents/` directory. Provide either `source_file` to copy from an existing file (e.g. an `agentctx_subagent` resource's `file_path`) or `content` to write the agent markdown inline.",
+				MarkdownDescription: "Agents to include in the plugin. Each agent is placed in the `agents/` directory. Provide either `source_file` to copy from an existing file (e.g. an `agentctx_subagent` resource's `file_path`) or `content` to write the agent markdown inline. With `description` set, the resource renders the agent frontmatter from the structured attributes and `content` is the system prompt.",
 				NestedObject: schema.NestedBlockObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
 							MarkdownDescription: "Agent name (used as filename: `agents/<name>.md`).",
 							Required:            true,
 							Validators: []validator.String{
-								stringvalidator.RegexMatches(
-									namePattern,
-									"must contain only lowercase letters, numbers, and hyphens",
-								),
+								validators.Name(),
 							},
 						},
 						"source_file": schema.StringAttribute{
@@ -211,8 +358,36 @@ func (r *PluginResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 							Optional:            true,
 						},
 						"content": schema.StringAttribute{
-							MarkdownDescription: "Inline agent markdown content.",
+							MarkdownDescription: "Inline agent markdown content. Once a structured attribute below is set, this holds just the system prompt: the resource renders the frontmatter above it, and content may not bring its own.",
+							Optional:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Describes when Claude should delegate to the agent. Written to the frontmatter as `description`, with the agent name as `name`. Requires `content`, and is required by the other frontmatter attributes.",
+							Optional:            true,
+						},
+						"tools": schema.ListAttribute{
+							MarkdownDescription: "Tools the agent can use. Written to the frontmatter as a comma-separated `tools`; the agent inherits all tools when omitted. Requires `description`.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"model": schema.StringAttribute{
+							MarkdownDescription: "Model the agent uses. Valid values: `sonnet`, `opus`, `haiku`, `inherit`. Written to the frontmatter as `model`. Requires `description`.",
 							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("sonnet", "opus", "haiku", "inherit"),
+							},
+						},
+						"permission_mode": schema.StringAttribute{
+							MarkdownDescription: "Controls how the agent handles permission prompts. Valid values: `default`, `acceptEdits`, `delegate`, `dontAsk`, `bypassPermissions`, `plan`. Written to the frontmatter as `permissionMode`. Requires `description`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("default", "acceptEdits", "delegate", "dontAsk", "bypassPermissions", "plan"),
+							},
+						},
+						"skills": schema.ListAttribute{
+							MarkdownDescription: "Skills to preload into the agent's context at startup. Written to the frontmatter as `skills`. Requires `description`.",
+							Optional:            true,
+							ElementType:         types.StringType,
 						},
 					},
 				},
@@ -225,10 +400,7 @@ func (r *PluginResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 							MarkdownDescription: "Command name (used as filename: `commands/<name>.md`).",
 							Required:            true,
 							Validators: []validator.String{
-								stringvalidator.RegexMatches(
-									namePattern,
-									"must contain only lowercase letters, numbers, and hyphens",
-								),
+								validators.Name(),
 							},
 						},
 						"source_file": schema.StringAttribute{
@@ -236,7 +408,28 @@ func (r *PluginResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 							Optional:            true,
 						},
 						"content": schema.StringAttribute{
-							MarkdownDescription: "Inline command markdown content.",
+							MarkdownDescription: "Inline command markdown content. Setting a frontmatter attribute below turns this into the prompt under a generated header, which rules out a header of its own.",
+							Optional:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "Brief description of the command, shown in the `/help` listing. Written to the frontmatter as `description`. Requires `content`.",
+							Optional:            true,
+						},
+						"argument_hint": schema.StringAttribute{
+							MarkdownDescription: "Arguments the command expects, e.g. `[issue-number] [priority]`. Written to the frontmatter as `argument-hint`. Requires `content`.",
+							Optional:            true,
+						},
+						"allowed_tools": schema.ListAttribute{
+							MarkdownDescription: "Tools the command may use without asking for permission, e.g. `Bash(git status:*)`. Written to the frontmatter as a comma-separated `allowed-tools`. Requires `content`.",
+							Optional:            true,
+							ElementType:         types.StringType,
+						},
+						"model": schema.StringAttribute{
+							MarkdownDescription: "Model the command runs with, such as `claude-haiku-4-5`. Written to the frontmatter as `model`. Requires `content`.",
+							Optional:            true,
+						},
+						"disable_model_invocation": schema.BoolAttribute{
+							MarkdownDescription: "Stop Claude from running the command on its own through the SlashCommand tool, so only users can invoke it. Written to the frontmatter as `disable-model-invocation` when `true`. Requires `content`.",
 							Optional:            true,
 						},
 					},
@@ -393,6 +586,7 @@ func (r *PluginResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 					},
 				},
 			},
+			"content_scan": contentscan.Block("the Markdown files of the generated plugin, at apply time and before `plugin.json` is written,"),
 		},
 	}
 }
@@ -404,7 +598,22 @@ func hookEventBlockSchema(description string) schema.ListNestedBlock {
 		NestedObject: schema.NestedBlockObject{
 			Attributes: map[string]schema.Attribute{
 				"matcher": schema.StringAttribute{
-					MarkdownDescription: "Regex pattern to match tool names. If omitted, the hook matches all tools.",
+					MarkdownDescription: "Regex pattern to match tool names. If neither `matcher` nor `tools` is set, the hook matches all tools.",
+					Optional:            true,
+				},
+				"tools": schema.ListAttribute{
+					MarkdownDescription: "Tool names to match, such as `[\"Write\", \"Edit\"]`, written to `hooks.json` as an anchored regular expression matching exactly those tools (`^(Write|Edit)$`). Names are escaped, so they cannot be patterns. Conflicts with `matcher`.",
+					ElementType:         types.StringType,
+					Optional:            true,
+					Validators: []validator.List{
+						listvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("matcher")),
+						listvalidator.SizeAtLeast(1),
+						listvalidator.UniqueValues(),
+						listvalidator.ValueStringsAre(stringvalidator.RegexMatches(ToolNamePattern, "must be a tool name such as \"Write\" or \"mcp__github__create_issue\", not a pattern; use matcher for patterns")),
+					},
+				},
+				"order": schema.Int64Attribute{
+					MarkdownDescription: "Position of this matcher within the event in `hooks.json`. Matchers are written in ascending `order`; matchers without `order` are treated as `0`, and ties keep declaration order.",
 					Optional:            true,
 				},
 			},
@@ -421,8 +630,15 @@ func hookEventBlockSchema(description string) schema.ListNestedBlock {
 								},
 							},
 							"command": schema.StringAttribute{
-								MarkdownDescription: "Shell command to execute, prompt text, or agent description.",
-								Required:            true,
+								MarkdownDescription: "Shell command to execute, prompt text, or agent description. Exactly one of `command` and `agent` must be set.",
+								Optional:            true,
+							},
+							"agent": schema.StringAttribute{
+								MarkdownDescription: "Name of an `agent` block of this plugin to run, for hooks of type `agent`, instead of a free-form description in `command`. Exactly one of `command` and `agent` must be set.",
+								Optional:            true,
+								Validators: []validator.String{
+									stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("command")),
+								},
 							},
 						},
 					},
@@ -433,10 +649,162 @@ func hookEventBlockSchema(description string) schema.ListNestedBlock {
 }
 
 // --------------------------------------------------------------------------
-// Configure (no-op – plugin resource doesn't need provider data)
+// ValidateConfig
 // --------------------------------------------------------------------------
 
-func (r *PluginResource) Configure(_ context.Context, _ resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+// ValidateConfig checks the requires_claude_version and compatibility_level
+// syntax and warns when generated features need a newer Claude Code release
+// than the constraint (or, when it is unset, any release) guarantees. It
+// also checks ${VAR} references in commands, see validateInterpolation, the
+// agents hooks refer to, see ValidateHookAgents, URLs, see ValidateURLs, and
+// content rendered with vars_file, see validateVars.
+func (r *PluginResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var requires types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("requires_claude_version"), &requires)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Hook blocks may still be unknown (e.g. dynamic blocks over unknown
+	// values); feature detection is skipped for anything not yet known.
+	var hooksList types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("hooks"), &hooksList)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var hooks []PluginHooksModel
+	if !hooksList.IsNull() && !hooksList.IsUnknown() {
+		if d := hooksList.ElementsAs(ctx, &hooks, false); d.HasError() {
+			hooks = nil
+		}
+	}
+
+	var compatibilityLevel types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("compatibility_level"), &compatibilityLevel)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !compatibilityLevel.IsNull() && !compatibilityLevel.IsUnknown() {
+		if _, err := claudeversion.ParseLevel(compatibilityLevel.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("compatibility_level"),
+				errcode.ClaudeVersion.Summary("Invalid Compatibility Level"),
+				fmt.Sprintf("compatibility_level %q is not a Claude Code version: %s", compatibilityLevel.ValueString(), err),
+			)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.validateClaudeVersion(requires, hooks, r.providerData.Compatibility(compatibilityLevel))...)
+
+	// Reading the whole configuration fails while any block is still
+	// unknown; the interpolation checks then run again once it is known.
+	var model PluginResourceModel
+	if d := req.Config.Get(ctx, &model); !d.HasError() {
+		resp.Diagnostics.Append(r.validateInterpolation(ctx, &model)...)
+		resp.Diagnostics.Append(ValidateHookAgents(&model)...)
+		resp.Diagnostics.Append(validateFrontmatter(ctx, &model)...)
+		resp.Diagnostics.Append(ValidateURLs(&model, r.providerData.URLSchemes())...)
+		resp.Diagnostics.Append(validateVars(&model)...)
+	}
+}
+
+// validateClaudeVersion implements the requires_claude_version checks for
+// ValidateConfig. Features that level leaves out of the generated plugin
+// are not checked.
+func (r *PluginResource) validateClaudeVersion(requires types.String, hooks []PluginHooksModel, level *claudeversion.Level) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if requires.IsUnknown() {
+		return diags
+	}
+
+	var events []string
+	if len(hooks) > 0 {
+		for event := range r.buildHooksJSON(hooks[0]) {
+			events = append(events, event)
+		}
+	}
+	var features []claudeversion.Feature
+	for _, f := range claudeversion.HookEventFeatures(events) {
+		if level.Allows(f) {
+			features = append(features, f)
+		}
+	}
+
+	if requires.IsNull() {
+		if len(features) > 0 {
+			diags.AddAttributeWarning(
+				path.Root("requires_claude_version"),
+				errcode.ClaudeVersion.Summary("Feature Requires Newer Claude Code"),
+				fmt.Sprintf("The plugin uses %s, which older Claude Code releases ignore. "+
+					"Set requires_claude_version = \">= %s\" to declare the minimum supported release.",
+					describeFeatures(features), claudeversion.MinVersion(features)),
+			)
+		}
+		return diags
+	}
+
+	constraint, err := claudeversion.ParseConstraint(requires.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("requires_claude_version"),
+			errcode.ClaudeVersion.Summary("Invalid Claude Version Constraint"),
+			fmt.Sprintf("requires_claude_version %q is not a valid version constraint: %s", requires.ValueString(), err),
+		)
+		return diags
+	}
+
+	if unsatisfied := constraint.Unsatisfied(features); len(unsatisfied) > 0 {
+		diags.AddAttributeWarning(
+			path.Root("requires_claude_version"),
+			errcode.ClaudeVersion.Summary("Feature Requires Newer Claude Code"),
+			fmt.Sprintf("requires_claude_version %q admits Claude Code releases older than %s, but the plugin uses %s. "+
+				"Those releases ignore the feature; consider raising the constraint.",
+				requires.ValueString(), claudeversion.MinVersion(unsatisfied), describeFeatures(unsatisfied)),
+		)
+	}
+
+	return diags
+}
+
+// describeFeatures renders features as "X (requires Claude Code >= v), ...".
+func describeFeatures(features []claudeversion.Feature) string {
+	parts := make([]string, len(features))
+	for i, f := range features {
+		parts[i] = fmt.Sprintf("%s (requires Claude Code >= %s)", f.Name, f.MinVersion)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *PluginResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// providerVersion returns the provider version recorded in provenance, or
+// "" when the resource is used without a configured provider.
+func (r *PluginResource) providerVersion() string {
+	if r.providerData == nil {
+		return ""
+	}
+	return r.providerData.Version
 }
 
 // --------------------------------------------------------------------------
@@ -444,13 +812,30 @@ func (r *PluginResource) Configure(_ context.Context, _ resource.ConfigureReques
 // --------------------------------------------------------------------------
 
 func (r *PluginResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_plugin", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan PluginResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	diags := r.writePlugin(ctx, &plan)
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRunPlugin(ctx, dryrun.OpCreate, plan, nil)...)
+		return
+	}
+
+	unlock, diags := lockOutputDir(ctx, plan.OutputDir.ValueString(), plan.LockTimeoutSeconds)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer unlock()
+
+	diags = r.writePlugin(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -478,33 +863,218 @@ func (r *PluginResource) Read(ctx context.Context, req resource.ReadRequest, res
 	pluginDir := state.PluginDir.ValueString()
 	manifestPath := filepath.Join(pluginDir, ".claude-plugin", "plugin.json")
 
-	data, err := os.ReadFile(manifestPath)
+	data, err := os.ReadFile(longpath.Path(manifestPath))
 	if err != nil {
-		if os.IsNotExist(err) {
+		if !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read plugin manifest %q: %s", manifestPath, err))
+			return
+		}
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_plugin", state.ID.ValueString(), fmt.Sprintf("manifest %q was deleted", manifestPath))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !state.RegenerateIfMissing.ValueBool() || r.providerData.CheckWritable("agentctx_plugin", "regenerate").HasError() || r.providerData.DryRunning() {
 			tflog.Info(ctx, "plugin manifest not found on disk, removing from state", map[string]interface{}{
 				"plugin_dir": pluginDir,
 			})
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("File Read Failed", fmt.Sprintf("Failed to read plugin manifest %q: %s", manifestPath, err))
-		return
+
+		resp.Diagnostics.Append(r.regenerate(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if data, err = os.ReadFile(longpath.Path(manifestPath)); err != nil {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read regenerated plugin manifest %q: %s", manifestPath, err))
+			return
+		}
 	}
 
 	diskContent := string(data)
 	diskHash := computeHash(diskContent)
 
-	state.ManifestJSON = types.StringValue(diskContent)
+	state.ManifestJSON = r.providerData.ContentFor(state.ContentStorage, diskContent)
 	state.ContentHash = types.StringValue(diskHash)
 
+	resp.Diagnostics.Append(refreshFileModes(ctx, pluginDir, state.Files)...)
+
+	inventory, err := buildInventory(longpath.Path(pluginDir), &state)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to build the plugin file inventory: %s", err))
+		return
+	}
+	drift, err := inventoryDrift(state.InventoryJSON.ValueString(), inventory)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidState.Summary("Invalid State"), fmt.Sprintf("Failed to parse inventory_json: %s", err))
+		return
+	}
+	if len(drift) > 0 {
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_plugin", state.ID.ValueString(), strings.Join(drift, "; "))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	var hashes map[string]string
+	if !state.FileHashes.IsNull() {
+		resp.Diagnostics.Append(state.FileHashes.ElementsAs(ctx, &hashes, false)...)
+	}
+	hashes, err = refreshFileHashes(hashes, state.InventoryJSON.ValueString(), inventory)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidState.Summary("Invalid State"), fmt.Sprintf("Failed to refresh file_hashes: %s", err))
+		return
+	}
+	var hashDiags diag.Diagnostics
+	state.FileHashes, hashDiags = types.MapValueFrom(ctx, types.StringType, hashes)
+	resp.Diagnostics.Append(hashDiags...)
+	state.InventoryJSON = types.StringValue(inventory)
+	resp.Diagnostics.Append(setUnmanagedFiles(ctx, longpath.Path(pluginDir), &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
+// setUnmanagedFiles sets unmanaged_files on model from the files in fsDir
+// that its inventory_json does not list.
+func setUnmanagedFiles(ctx context.Context, fsDir string, model *PluginResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	foreign, err := unmanagedFiles(fsDir, model.InventoryJSON.ValueString())
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to list the unmanaged files in the plugin directory: %s", err))
+		return diags
+	}
+	if len(foreign) > 0 {
+		tflog.Debug(ctx, "plugin directory holds unmanaged files", map[string]interface{}{
+			"count": len(foreign),
+		})
+	}
+	model.UnmanagedFiles, diags = types.ListValueFrom(ctx, types.StringType, append([]string{}, foreign...))
+	return diags
+}
+
+// refreshFileModes records the on-disk executable bit of each file block in
+// files, so a chmod by another tool shows up as a diff on executable and the
+// next apply restores the configured mode. Files that no longer exist are
+// left alone. Windows has no executable bit, so the check is skipped there.
+func refreshFileModes(ctx context.Context, pluginDir string, files []PluginFileModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if runtime.GOOS == "windows" {
+		return diags
+	}
+
+	for i := range files {
+		relPath := files[i].Path.ValueString()
+		info, err := os.Stat(longpath.Path(filepath.Join(pluginDir, relPath)))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				diags.AddAttributeWarning(path.Root("file").AtListIndex(i), errcode.FileRead.Summary("File Stat Failed"),
+					fmt.Sprintf("Failed to check the mode of %q: %s", relPath, err))
+			}
+			continue
+		}
+
+		executable := info.Mode().Perm()&0o111 != 0
+		if executable == files[i].Executable.ValueBool() {
+			continue
+		}
+
+		tflog.Info(ctx, "plugin file mode drifted", map[string]interface{}{
+			"path": relPath,
+			"mode": fmt.Sprintf("%04o", info.Mode().Perm()),
+		})
+		want := "not executable"
+		if files[i].Executable.ValueBool() {
+			want = "executable"
+		}
+		diags.AddAttributeWarning(
+			path.Root("file").AtListIndex(i).AtName("executable"),
+			errcode.DriftDetected.Summary("Plugin File Mode Drift"),
+			fmt.Sprintf("%q has mode %04o on disk but is configured to be %s. The next apply restores the configured mode.",
+				relPath, info.Mode().Perm(), want),
+		)
+		files[i].Executable = types.BoolValue(executable)
+	}
+	return diags
+}
+
+// --------------------------------------------------------------------------
+// ModifyPlan
+// --------------------------------------------------------------------------
+
+// ModifyPlan rehashes the files in file_hashes and, when any of them was
+// changed or deleted outside Terraform, plans an update that writes the
+// plugin again, so drift in any generated file is repaired on apply and
+// not only drift in plugin.json.
+func (r *PluginResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var state, plan PluginResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || state.FileHashes.IsNull() {
+		return
+	}
+
+	// Errors reading vars_file are reported by ValidateConfig.
+	if vars, d := loadVars(&plan); !d.HasError() && vars != nil && vars.hash != state.VarsHash.ValueString() {
+		tflog.Info(ctx, "plugin vars_file changed", map[string]interface{}{
+			"vars_file": plan.VarsFile.ValueString(),
+		})
+		resp.Diagnostics.Append(planRegenerate(ctx, &plan, &resp.Plan)...)
+		return
+	}
+
+	var hashes map[string]string
+	resp.Diagnostics.Append(state.FileHashes.ElementsAs(ctx, &hashes, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	drift, err := treeDrift(longpath.Path(state.PluginDir.ValueString()), hashes)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to hash the generated plugin files: %s", err))
+		return
+	}
+	if len(drift) == 0 {
+		return
+	}
+
+	tflog.Info(ctx, "plugin files drifted", map[string]interface{}{
+		"plugin_dir": state.PluginDir.ValueString(),
+		"count":      len(drift),
+	})
+	resp.Diagnostics.AddWarning(
+		errcode.DriftDetected.Summary("Plugin Files Modified Outside Terraform"),
+		fmt.Sprintf("The following generated files no longer match the last apply, which the next apply writes again: %s.", strings.Join(drift, "; ")),
+	)
+	resp.Diagnostics.Append(planRegenerate(ctx, &plan, &resp.Plan)...)
+}
+
+// planRegenerate sets the computed attributes of plan that writing the
+// plugin again recomputes to unknown, planning an update that does so.
+func planRegenerate(ctx context.Context, plan *PluginResourceModel, out *tfsdk.Plan) diag.Diagnostics {
+	plan.ManifestJSON = types.StringUnknown()
+	plan.ContentHash = types.StringUnknown()
+	plan.VarsHash = types.StringUnknown()
+	plan.InventoryJSON = types.StringUnknown()
+	plan.FileHashes = types.MapUnknown(types.StringType)
+	plan.UnmanagedFiles = types.ListUnknown(types.StringType)
+	return out.Set(ctx, plan)
+}
+
 // --------------------------------------------------------------------------
 // Update
 // --------------------------------------------------------------------------
 
 func (r *PluginResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_plugin", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var state PluginResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -517,12 +1087,24 @@ func (r *PluginResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRunPlugin(ctx, dryrun.OpUpdate, plan, &state)...)
+		return
+	}
+
+	unlock, diags := lockOutputDir(ctx, plan.OutputDir.ValueString(), plan.LockTimeoutSeconds)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defer unlock()
+
 	resp.Diagnostics.Append(cleanupRemovedExtraFiles(plan.OutputDir.ValueString(), state.Files, plan.Files)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	diags := r.writePlugin(ctx, &plan)
+	diags = r.writePlugin(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -541,6 +1123,11 @@ func (r *PluginResource) Update(ctx context.Context, req resource.UpdateRequest,
 // --------------------------------------------------------------------------
 
 func (r *PluginResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_plugin", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var state PluginResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -549,8 +1136,40 @@ func (r *PluginResource) Delete(ctx context.Context, req resource.DeleteRequest,
 
 	pluginDir := state.PluginDir.ValueString()
 
-	if err := os.RemoveAll(pluginDir); err != nil && !os.IsNotExist(err) {
-		resp.Diagnostics.AddError("Directory Delete Failed", fmt.Sprintf("Failed to delete plugin directory %q: %s", pluginDir, err))
+	if r.providerData.DryRunning() {
+		files, err := dryRunDelete(pluginDir)
+		if err != nil {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to list plugin directory %q: %s", pluginDir, err))
+			return
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_plugin",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Files:     files,
+		})...)
+		return
+	}
+
+	// Wait for other writers and empty the directory under the lock. The lock
+	// file is removed last, after it is closed: Windows cannot delete a file
+	// that is still open.
+	if _, err := os.Stat(longpath.Path(pluginDir)); err == nil {
+		unlock, diags := lockOutputDir(ctx, pluginDir, state.LockTimeoutSeconds)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		err := removeAllExcept(longpath.Path(pluginDir), lockFileName)
+		unlock()
+		if err != nil {
+			resp.Diagnostics.AddError(errcode.FileDelete.Summary("Directory Delete Failed"), fmt.Sprintf("Failed to delete plugin directory %q: %s", pluginDir, err))
+			return
+		}
+	}
+
+	if err := os.RemoveAll(longpath.Path(pluginDir)); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError(errcode.FileDelete.Summary("Directory Delete Failed"), fmt.Sprintf("Failed to delete plugin directory %q: %s", pluginDir, err))
 		return
 	}
 
@@ -566,21 +1185,22 @@ func (r *PluginResource) Delete(ctx context.Context, req resource.DeleteRequest,
 
 // pluginManifest represents the .claude-plugin/plugin.json structure.
 type pluginManifest struct {
-	Name         string          `json:"name"`
-	Version      string          `json:"version,omitempty"`
-	Description  string          `json:"description,omitempty"`
-	Author       *manifestAuthor `json:"author,omitempty"`
-	Homepage     string          `json:"homepage,omitempty"`
-	Repository   string          `json:"repository,omitempty"`
-	License      string          `json:"license,omitempty"`
-	Keywords     []string        `json:"keywords,omitempty"`
-	OutputStyles []string        `json:"outputStyles,omitempty"`
-	Commands     []string        `json:"commands,omitempty"`
-	Agents       []string        `json:"agents,omitempty"`
-	Skills       []string        `json:"skills,omitempty"`
-	Hooks        interface{}     `json:"hooks,omitempty"`
-	McpServers   interface{}     `json:"mcpServers,omitempty"`
-	LspServers   interface{}     `json:"lspServers,omitempty"`
+	Name                  string          `json:"name"`
+	Version               string          `json:"version,omitempty"`
+	Description           string          `json:"description,omitempty"`
+	Author                *manifestAuthor `json:"author,omitempty"`
+	Homepage              string          `json:"homepage,omitempty"`
+	Repository            string          `json:"repository,omitempty"`
+	License               string          `json:"license,omitempty"`
+	Keywords              []string        `json:"keywords,omitempty"`
+	RequiresClaudeVersion string          `json:"requiresClaudeVersion,omitempty"`
+	OutputStyles          []string        `json:"outputStyles,omitempty"`
+	Commands              []string        `json:"commands,omitempty"`
+	Agents                []string        `json:"agents,omitempty"`
+	Skills                []string        `json:"skills,omitempty"`
+	Hooks                 interface{}     `json:"hooks,omitempty"`
+	McpServers            interface{}     `json:"mcpServers,omitempty"`
+	LspServers            interface{}     `json:"lspServers,omitempty"`
 }
 
 type manifestAuthor struct {
@@ -599,302 +1219,243 @@ func (r *PluginResource) writePlugin(ctx context.Context, model *PluginResourceM
 	// Resolve to absolute path.
 	absDir, err := filepath.Abs(outputDir)
 	if err != nil {
-		diags.AddError("Path Resolution Failed", fmt.Sprintf("Failed to resolve absolute path for %q: %s", outputDir, err))
+		diags.AddAttributeError(path.Root("output_dir"), errcode.PathResolution.Summary("Path Resolution Failed"), fmt.Sprintf("Failed to resolve absolute path for %q: %s", outputDir, err))
 		return diags
 	}
-
-	// Clean managed artifacts before regenerating so removed blocks don't leave
-	// stale files behind across updates.
-	if err := cleanupManagedArtifacts(absDir); err != nil {
-		diags.AddError("Cleanup Failed", fmt.Sprintf("Failed to clean managed plugin artifacts in %q: %s", absDir, err))
+	// Long paths and UNC shares need the extended-length form on Windows.
+	fsDir := longpath.Path(absDir)
+
+	// Render the JSON files first, so that invalid arguments fail before
+	// anything on disk changes.
+	rendered, d := r.render(ctx, model, r.providerData.Compatibility(model.CompatibilityLevel))
+	diags.Append(d...)
+	vars, d := loadVars(model)
+	diags.Append(d...)
+	if diags.HasError() {
 		return diags
 	}
 
-	// Create the plugin directory structure.
-	if err := os.MkdirAll(filepath.Join(absDir, ".claude-plugin"), 0o755); err != nil {
-		diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create plugin directory: %s", err))
+	// Sub-agents that agentctx_subagent wrote into the plugin survive the
+	// cleanup below.
+	registered, err := loadRegisteredAgents(fsDir)
+	if err != nil {
+		diags.AddAttributeError(path.Root("output_dir"), errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read the sub-agents registered in %q: %s", absDir, err))
 		return diags
 	}
-
-	// Build the manifest.
-	manifest := pluginManifest{
-		Name: model.Name.ValueString(),
-	}
-
-	if !model.Version.IsNull() && !model.Version.IsUnknown() {
-		manifest.Version = model.Version.ValueString()
-	}
-	if !model.Description.IsNull() && !model.Description.IsUnknown() {
-		manifest.Description = model.Description.ValueString()
-	}
-	if !model.Homepage.IsNull() && !model.Homepage.IsUnknown() {
-		manifest.Homepage = model.Homepage.ValueString()
-	}
-	if !model.Repository.IsNull() && !model.Repository.IsUnknown() {
-		manifest.Repository = model.Repository.ValueString()
-	}
-	if !model.License.IsNull() && !model.License.IsUnknown() {
-		manifest.License = model.License.ValueString()
-	}
-	if !model.Keywords.IsNull() && !model.Keywords.IsUnknown() {
-		var keywords []string
-		d := model.Keywords.ElementsAs(ctx, &keywords, false)
-		diags.Append(d...)
-		if diags.HasError() {
-			return diags
-		}
-		manifest.Keywords = keywords
-	}
-
-	// Output styles
-	if len(model.OutputStyles) > 0 {
-		paths := make([]string, 0, len(model.OutputStyles))
-		for _, s := range model.OutputStyles {
-			relPath := s.Path.ValueString()
-			if filepath.IsAbs(relPath) || strings.Contains(relPath, "..") {
-				diags.AddError(
-					"Invalid Output Style Path",
-					fmt.Sprintf("Output style path %q must be relative and must not contain '..'.", relPath),
-				)
+	for i, a := range model.Agents {
+		for _, reg := range registered {
+			if a.Name.ValueString() == reg.name {
+				diags.AddAttributeError(path.Root("agent").AtListIndex(i).AtName("name"), errcode.DuplicateName.Summary("Duplicate Agent Name"),
+					fmt.Sprintf("Agent %q is also written into this plugin by an agentctx_subagent with plugin_dir. Rename one of them.", reg.name))
 				return diags
 			}
-
-			paths = append(paths, withDotSlash(relPath))
 		}
-		manifest.OutputStyles = paths
 	}
 
-	// Author
-	if len(model.Author) > 0 {
-		a := model.Author[0]
-		ma := &manifestAuthor{Name: a.Name.ValueString()}
-		if !a.Email.IsNull() && !a.Email.IsUnknown() {
-			ma.Email = a.Email.ValueString()
-		}
-		if !a.URL.IsNull() && !a.URL.IsUnknown() {
-			ma.URL = a.URL.ValueString()
-		}
-		manifest.Author = ma
+	// Clean managed artifacts before regenerating so removed blocks don't leave
+	// stale files behind across updates.
+	if err := cleanupManagedArtifacts(fsDir); err != nil {
+		diags.AddAttributeError(path.Root("output_dir"), errcode.FileDelete.Summary("Cleanup Failed"), fmt.Sprintf("Failed to clean managed plugin artifacts in %q: %s", absDir, err))
+		return diags
+	}
+
+	// Create the plugin directory structure.
+	if err := os.MkdirAll(filepath.Join(fsDir, ".claude-plugin"), 0o755); err != nil {
+		diags.AddAttributeError(path.Root("output_dir"), errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create plugin directory: %s", err))
+		return diags
 	}
 
 	// Skills
 	if len(model.Skills) > 0 {
-		skillsDir := filepath.Join(absDir, "skills")
+		skillsDir := filepath.Join(fsDir, "skills")
 		if err := os.MkdirAll(skillsDir, 0o755); err != nil {
-			diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create skills directory: %s", err))
+			diags.AddError(errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create skills directory: %s", err))
 			return diags
 		}
 
-		var skillPaths []string
-		for _, s := range model.Skills {
+		for i, s := range model.Skills {
 			name := s.Name.ValueString()
 			skillDir := filepath.Join(skillsDir, name)
+			skillPath := path.Root("skill").AtListIndex(i)
 
 			hasSource := !s.SourceDir.IsNull() && !s.SourceDir.IsUnknown()
+			hasBundle := !s.SourceBundle.IsNull() && !s.SourceBundle.IsUnknown()
 			hasContent := !s.Content.IsNull() && !s.Content.IsUnknown()
 
 			if hasSource && hasContent {
-				diags.AddError("Invalid Skill Configuration",
+				diags.AddAttributeError(skillPath.AtName("content"), errcode.InvalidConfig.Summary("Invalid Skill Configuration"),
 					fmt.Sprintf("Skill %q must have either source_dir or content set, not both.", name))
 				return diags
 			}
+			if hasBundle && (hasSource || hasContent) {
+				diags.AddAttributeError(skillPath.AtName("source_bundle"), errcode.InvalidConfig.Summary("Invalid Skill Configuration"),
+					fmt.Sprintf("Skill %q must not set source_bundle together with source_dir or content.", name))
+				return diags
+			}
 
-			if hasSource {
+			if hasBundle {
+				// Copy exactly the file set recorded by agentctx_skill.
+				d := copyBundle(s.SourceBundle.ValueString(), skillDir)
+				diags.Append(withAttributePath(skillPath.AtName("source_bundle"), d)...)
+				if diags.HasError() {
+					return diags
+				}
+			} else if hasSource {
 				// Copy the entire source directory.
 				srcDir := s.SourceDir.ValueString()
 				d := copyDirectory(srcDir, skillDir)
-				diags.Append(d...)
+				diags.Append(withAttributePath(skillPath.AtName("source_dir"), d)...)
 				if diags.HasError() {
 					return diags
 				}
 			} else if hasContent {
 				// Write SKILL.md inline.
 				if err := os.MkdirAll(skillDir, 0o755); err != nil {
-					diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create skill directory %q: %s", skillDir, err))
+					diags.AddAttributeError(skillPath.AtName("content"), errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create skill directory %q: %s", skillDir, err))
 					return diags
 				}
-				if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(s.Content.ValueString()), 0o644); err != nil {
-					diags.AddError("File Write Failed", fmt.Sprintf("Failed to write SKILL.md for %q: %s", name, err))
+				if err := vars.writeFrontmatterBlock(skillFrontmatterBlock(ctx, s), filepath.Join(skillDir, "SKILL.md")); err != nil {
+					diags.AddAttributeError(skillPath.AtName("content"), errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write SKILL.md for %q: %s", name, err))
 					return diags
 				}
 			} else {
-				diags.AddError("Invalid Skill Configuration",
-					fmt.Sprintf("Skill %q must have either source_dir or content set.", name))
+				diags.AddAttributeError(skillPath, errcode.InvalidConfig.Summary("Invalid Skill Configuration"),
+					fmt.Sprintf("Skill %q must have one of source_dir, source_bundle, or content set.", name))
 				return diags
 			}
 
-			skillPaths = append(skillPaths, fmt.Sprintf("./skills/%s/", name))
 		}
-		manifest.Skills = skillPaths
 	}
 
 	// Agents
 	if len(model.Agents) > 0 {
-		agentsDir := filepath.Join(absDir, "agents")
+		agentsDir := filepath.Join(fsDir, "agents")
 		if err := os.MkdirAll(agentsDir, 0o755); err != nil {
-			diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create agents directory: %s", err))
+			diags.AddError(errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create agents directory: %s", err))
 			return diags
 		}
 
-		var agentPaths []string
-		for _, a := range model.Agents {
+		for i, a := range model.Agents {
 			name := a.Name.ValueString()
+			agentPath := path.Root("agent").AtListIndex(i)
 			destPath := filepath.Join(agentsDir, name+".md")
 
 			hasSource := !a.SourceFile.IsNull() && !a.SourceFile.IsUnknown()
 			hasContent := !a.Content.IsNull() && !a.Content.IsUnknown()
 
 			if hasSource && hasContent {
-				diags.AddError("Invalid Agent Configuration",
+				diags.AddAttributeError(agentPath.AtName("content"), errcode.InvalidConfig.Summary("Invalid Agent Configuration"),
 					fmt.Sprintf("Agent %q must have either source_file or content set, not both.", name))
 				return diags
 			}
 
 			if hasSource {
-				d := copyFile(a.SourceFile.ValueString(), destPath)
-				diags.Append(d...)
+				d := vars.copyFile(a.SourceFile.ValueString(), destPath)
+				diags.Append(withAttributePath(agentPath.AtName("source_file"), d)...)
 				if diags.HasError() {
 					return diags
 				}
 			} else if hasContent {
-				if err := os.WriteFile(destPath, []byte(a.Content.ValueString()), 0o644); err != nil {
-					diags.AddError("File Write Failed", fmt.Sprintf("Failed to write agent file for %q: %s", name, err))
+				if err := vars.writeFrontmatterBlock(agentFrontmatterBlock(ctx, a), destPath); err != nil {
+					diags.AddAttributeError(agentPath.AtName("content"), errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write agent file for %q: %s", name, err))
 					return diags
 				}
 			} else {
-				diags.AddError("Invalid Agent Configuration",
+				diags.AddAttributeError(agentPath, errcode.InvalidConfig.Summary("Invalid Agent Configuration"),
 					fmt.Sprintf("Agent %q must have either source_file or content set.", name))
 				return diags
 			}
 
-			agentPaths = append(agentPaths, fmt.Sprintf("./agents/%s.md", name))
 		}
-		manifest.Agents = agentPaths
 	}
 
 	// Commands
 	if len(model.Commands) > 0 {
-		commandsDir := filepath.Join(absDir, "commands")
+		commandsDir := filepath.Join(fsDir, "commands")
 		if err := os.MkdirAll(commandsDir, 0o755); err != nil {
-			diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create commands directory: %s", err))
+			diags.AddError(errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create commands directory: %s", err))
 			return diags
 		}
 
-		var cmdPaths []string
-		for _, c := range model.Commands {
+		for i, c := range model.Commands {
 			name := c.Name.ValueString()
+			commandPath := path.Root("command").AtListIndex(i)
 			destPath := filepath.Join(commandsDir, name+".md")
 
 			hasSource := !c.SourceFile.IsNull() && !c.SourceFile.IsUnknown()
 			hasContent := !c.Content.IsNull() && !c.Content.IsUnknown()
 
 			if hasSource && hasContent {
-				diags.AddError("Invalid Command Configuration",
+				diags.AddAttributeError(commandPath.AtName("content"), errcode.InvalidConfig.Summary("Invalid Command Configuration"),
 					fmt.Sprintf("Command %q must have either source_file or content set, not both.", name))
 				return diags
 			}
 
 			if hasSource {
-				d := copyFile(c.SourceFile.ValueString(), destPath)
-				diags.Append(d...)
+				d := vars.copyFile(c.SourceFile.ValueString(), destPath)
+				diags.Append(withAttributePath(commandPath.AtName("source_file"), d)...)
 				if diags.HasError() {
 					return diags
 				}
 			} else if hasContent {
-				if err := os.WriteFile(destPath, []byte(c.Content.ValueString()), 0o644); err != nil {
-					diags.AddError("File Write Failed", fmt.Sprintf("Failed to write command file for %q: %s", name, err))
+				if err := vars.writeFrontmatterBlock(commandFrontmatterBlock(ctx, c), destPath); err != nil {
+					diags.AddAttributeError(commandPath.AtName("content"), errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write command file for %q: %s", name, err))
 					return diags
 				}
 			} else {
-				diags.AddError("Invalid Command Configuration",
+				diags.AddAttributeError(commandPath, errcode.InvalidConfig.Summary("Invalid Command Configuration"),
 					fmt.Sprintf("Command %q must have either source_file or content set.", name))
 				return diags
 			}
 
-			cmdPaths = append(cmdPaths, fmt.Sprintf("./commands/%s.md", name))
 		}
-		manifest.Commands = cmdPaths
 	}
 
 	// Hooks
 	if len(model.Hooks) > 0 {
-		hooksDir := filepath.Join(absDir, "hooks")
+		hooksDir := filepath.Join(fsDir, "hooks")
 		if err := os.MkdirAll(hooksDir, 0o755); err != nil {
-			diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create hooks directory: %s", err))
+			diags.AddAttributeError(path.Root("hooks").AtListIndex(0), errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create hooks directory: %s", err))
 			return diags
 		}
-
-		hooksConfig := r.buildHooksJSON(model.Hooks[0])
-		if len(hooksConfig) > 0 {
-			hooksJSON, err := marshalDeterministic(map[string]interface{}{"hooks": hooksConfig})
-			if err != nil {
-				diags.AddError("JSON Marshal Failed", fmt.Sprintf("Failed to marshal hooks configuration: %s", err))
+		if rendered.hooksJSON != nil {
+			if err := atomicfile.WriteFile(filepath.Join(hooksDir, "hooks.json"), rendered.hooksJSON, 0o644); err != nil {
+				diags.AddAttributeError(path.Root("hooks").AtListIndex(0), errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write hooks.json: %s", err))
 				return diags
 			}
-			if err := os.WriteFile(filepath.Join(hooksDir, "hooks.json"), hooksJSON, 0o644); err != nil {
-				diags.AddError("File Write Failed", fmt.Sprintf("Failed to write hooks.json: %s", err))
-				return diags
-			}
-			manifest.Hooks = "./hooks/hooks.json"
 		}
 	}
 
 	// MCP Servers
-	if len(model.McpServers) > 0 {
-		d := r.validateMcpServers(ctx, model.McpServers)
-		diags.Append(d...)
-		if diags.HasError() {
+	if rendered.mcpJSON != nil {
+		if err := atomicfile.WriteFile(filepath.Join(fsDir, ".mcp.json"), rendered.mcpJSON, 0o644); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write .mcp.json: %s", err))
 			return diags
 		}
-
-		mcpConfig := r.buildMcpJSON(ctx, model.McpServers, &diags)
-		if diags.HasError() {
-			return diags
-		}
-		mcpJSON, err := marshalDeterministic(map[string]interface{}{"mcpServers": mcpConfig})
-		if err != nil {
-			diags.AddError("JSON Marshal Failed", fmt.Sprintf("Failed to marshal MCP configuration: %s", err))
-			return diags
-		}
-		if err := os.WriteFile(filepath.Join(absDir, ".mcp.json"), mcpJSON, 0o644); err != nil {
-			diags.AddError("File Write Failed", fmt.Sprintf("Failed to write .mcp.json: %s", err))
-			return diags
-		}
-		manifest.McpServers = "./.mcp.json"
 	}
 
 	// LSP Servers
-	if len(model.LspServers) > 0 {
-		lspConfig := r.buildLspJSON(ctx, model.LspServers, &diags)
-		if diags.HasError() {
-			return diags
-		}
-		lspJSON, err := marshalDeterministic(lspConfig)
-		if err != nil {
-			diags.AddError("JSON Marshal Failed", fmt.Sprintf("Failed to marshal LSP configuration: %s", err))
-			return diags
-		}
-		if err := os.WriteFile(filepath.Join(absDir, ".lsp.json"), lspJSON, 0o644); err != nil {
-			diags.AddError("File Write Failed", fmt.Sprintf("Failed to write .lsp.json: %s", err))
+	if rendered.lspJSON != nil {
+		if err := atomicfile.WriteFile(filepath.Join(fsDir, ".lsp.json"), rendered.lspJSON, 0o644); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write .lsp.json: %s", err))
 			return diags
 		}
-		manifest.LspServers = "./.lsp.json"
 	}
-
 	// Extra files
-	for _, f := range model.Files {
+	for i, f := range model.Files {
 		relPath := f.Path.ValueString()
+		filePath := path.Root("file").AtListIndex(i)
 
 		// Validate the path is relative and doesn't escape.
 		if filepath.IsAbs(relPath) || strings.Contains(relPath, "..") {
-			diags.AddError("Invalid File Path",
+			diags.AddAttributeError(filePath.AtName("path"), errcode.PathTraversal.Summary("Invalid File Path"),
 				fmt.Sprintf("File path %q must be relative and not contain '..'.", relPath))
 			return diags
 		}
 
-		destPath := filepath.Join(absDir, relPath)
+		destPath := filepath.Join(fsDir, relPath)
 		if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
-			diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create parent directory for %q: %s", relPath, err))
+			diags.AddAttributeError(filePath.AtName("path"), errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create parent directory for %q: %s", relPath, err))
 			return diags
 		}
 
@@ -907,53 +1468,115 @@ func (r *PluginResource) writePlugin(ctx context.Context, model *PluginResourceM
 		hasContent := !f.Content.IsNull() && !f.Content.IsUnknown()
 
 		if hasSource && hasContent {
-			diags.AddError("Invalid File Configuration",
+			diags.AddAttributeError(filePath.AtName("content"), errcode.InvalidConfig.Summary("Invalid File Configuration"),
 				fmt.Sprintf("File %q must have either content or source_file set, not both.", relPath))
 			return diags
 		}
 
 		if hasSource {
-			data, err := os.ReadFile(f.SourceFile.ValueString())
+			data, err := os.ReadFile(longpath.Path(f.SourceFile.ValueString()))
 			if err != nil {
-				diags.AddError("File Read Failed", fmt.Sprintf("Failed to read source file %q: %s", f.SourceFile.ValueString(), err))
+				diags.AddAttributeError(filePath.AtName("source_file"), errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read source file %q: %s", f.SourceFile.ValueString(), err))
 				return diags
 			}
-			if err := os.WriteFile(destPath, data, perm); err != nil {
-				diags.AddError("File Write Failed", fmt.Sprintf("Failed to write file %q: %s", relPath, err))
+			if err := atomicfile.WriteFile(destPath, data, perm); err != nil {
+				diags.AddAttributeError(filePath.AtName("path"), errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write file %q: %s", relPath, err))
 				return diags
 			}
 		} else if hasContent {
-			if err := os.WriteFile(destPath, []byte(f.Content.ValueString()), perm); err != nil {
-				diags.AddError("File Write Failed", fmt.Sprintf("Failed to write file %q: %s", relPath, err))
+			if err := vars.writeFile("file "+relPath, f.Content.ValueString(), destPath, perm); err != nil {
+				diags.AddAttributeError(filePath.AtName("content"), errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write file %q: %s", relPath, err))
 				return diags
 			}
 		} else {
-			diags.AddError("Invalid File Configuration",
+			diags.AddAttributeError(filePath, errcode.InvalidConfig.Summary("Invalid File Configuration"),
 				fmt.Sprintf("File %q must have either content or source_file set.", relPath))
 			return diags
 		}
 	}
 
-	// Write the manifest.
-	manifestJSON, err := marshalDeterministic(manifest)
-	if err != nil {
-		diags.AddError("JSON Marshal Failed", fmt.Sprintf("Failed to marshal plugin manifest: %s", err))
+	// Test scaffolding
+	if model.GenerateTests.ValueBool() {
+		for i, f := range model.Files {
+			if filepath.ToSlash(filepath.Clean(f.Path.ValueString())) == testScriptPath {
+				diags.AddAttributeError(path.Root("file").AtListIndex(i).AtName("path"), errcode.DuplicateName.Summary("Duplicate File Path"),
+					fmt.Sprintf("%q is written by generate_tests. Remove the file block or set generate_tests = false.", testScriptPath))
+				return diags
+			}
+		}
+		diags.Append(writeTestScaffold(fsDir, model.VerifyScriptReferences.IsNull() || model.VerifyScriptReferences.ValueBool())...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	// Scan the content before writing the manifest, so a plugin the scan
+	// fails is not loadable.
+	diags.Append(scanContent(ctx, model, fsDir)...)
+	if diags.HasError() {
 		return diags
 	}
 
-	manifestPath := filepath.Join(absDir, ".claude-plugin", "plugin.json")
-	if err := os.WriteFile(manifestPath, manifestJSON, 0o644); err != nil {
-		diags.AddError("File Write Failed", fmt.Sprintf("Failed to write plugin.json: %s", err))
+	// Write the manifest.
+	manifestJSON := rendered.manifestJSON
+	if len(registered) > 0 {
+		if err := restoreRegisteredAgents(fsDir, registered); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to restore the sub-agents registered in the plugin: %s", err))
+			return diags
+		}
+		names := make([]string, len(registered))
+		for i, a := range registered {
+			names[i] = a.name
+		}
+		if manifestJSON, err = editManifestAgents(manifestJSON, names, nil); err != nil {
+			diags.AddError(errcode.Encoding.Summary("JSON Marshal Failed"), fmt.Sprintf("Failed to list the registered sub-agents in plugin.json: %s", err))
+			return diags
+		}
+	}
+	manifestPath := filepath.Join(fsDir, ".claude-plugin", "plugin.json")
+	if err := atomicfile.WriteFile(manifestPath, manifestJSON, 0o644); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write plugin.json: %s", err))
 		return diags
 	}
 
+	if model.Provenance.ValueBool() {
+		diags.Append(writeProvenance(fsDir, absDir, model, r.providerVersion())...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
 	manifestStr := string(manifestJSON)
 	hash := computeHash(manifestStr)
 
 	model.ID = types.StringValue(absDir)
 	model.PluginDir = types.StringValue(absDir)
-	model.ManifestJSON = types.StringValue(manifestStr)
+	model.ManifestJSON = r.providerData.ContentFor(model.ContentStorage, manifestStr)
 	model.ContentHash = types.StringValue(hash)
+	model.VarsHash = types.StringNull()
+	if vars != nil {
+		model.VarsHash = types.StringValue(vars.hash)
+	}
+
+	inventory, err := buildInventory(fsDir, model)
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to build the plugin file inventory: %s", err))
+		return diags
+	}
+	model.InventoryJSON = types.StringValue(inventory)
+	hashes, err := generatedFileHashes(inventory)
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to build the plugin file hashes: %s", err))
+		return diags
+	}
+	var hashDiags diag.Diagnostics
+	model.FileHashes, hashDiags = types.MapValueFrom(ctx, types.StringType, hashes)
+	diags.Append(hashDiags...)
+	diags.Append(setUnmanagedFiles(ctx, fsDir, model)...)
+	if diags.HasError() {
+		return diags
+	}
+	diags.Append(cachePluginFiles(fsDir, model)...)
 
 	return diags
 }
@@ -972,17 +1595,22 @@ func (r *PluginResource) buildHooksJSON(hooks PluginHooksModel) map[string]inter
 			return
 		}
 		var entries []map[string]interface{}
-		for _, m := range matchers {
+		for _, m := range orderedMatchers(matchers) {
 			entry := make(map[string]interface{})
 			if !m.Matcher.IsNull() && !m.Matcher.IsUnknown() {
 				entry["matcher"] = m.Matcher.ValueString()
+			} else if tools := listStrings(m.Tools); len(tools) > 0 {
+				entry["matcher"] = ToolsMatcher(tools)
 			}
 			var hookList []map[string]interface{}
 			for _, h := range m.Hooks {
-				hookList = append(hookList, map[string]interface{}{
-					"type":    h.Type.ValueString(),
-					"command": h.Command.ValueString(),
-				})
+				hook := map[string]interface{}{"type": h.Type.ValueString()}
+				if hasNonEmptyString(h.Agent) {
+					hook["agent"] = h.Agent.ValueString()
+				} else {
+					hook["command"] = h.Command.ValueString()
+				}
+				hookList = append(hookList, hook)
 			}
 			entry["hooks"] = hookList
 			entries = append(entries, entry)
@@ -1008,17 +1636,58 @@ func (r *PluginResource) buildHooksJSON(hooks PluginHooksModel) map[string]inter
 	return result
 }
 
+// ToolNamePattern matches the names that the tools attribute of a hook
+// matcher accepts: built-in tools such as "Write" and MCP tools such as
+// "mcp__github__create_issue", but nothing with regex syntax.
+var ToolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ToolsMatcher returns the hooks.json matcher for the tools attribute: an
+// anchored alternation of the escaped names, such as "^(Write|Edit)$", so
+// it matches exactly those tools.
+func ToolsMatcher(tools []string) string {
+	quoted := make([]string, len(tools))
+	for i, t := range tools {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+// listStrings returns the known string elements of l.
+func listStrings(l types.List) []string {
+	var out []string
+	for _, e := range l.Elements() {
+		if s, ok := e.(types.String); ok && !s.IsNull() && !s.IsUnknown() {
+			out = append(out, s.ValueString())
+		}
+	}
+	return out
+}
+
+// orderedMatchers returns matchers sorted by their order attribute. The sort
+// is stable, so matchers with equal (or unset) order keep the order in which
+// they were declared.
+func orderedMatchers(matchers []PluginHookMatcherModel) []PluginHookMatcherModel {
+	sorted := make([]PluginHookMatcherModel, len(matchers))
+	copy(sorted, matchers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order.ValueInt64() < sorted[j].Order.ValueInt64()
+	})
+	return sorted
+}
+
 func (r *PluginResource) validateMcpServers(ctx context.Context, servers []PluginMcpModel) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	for _, s := range servers {
+	for i, s := range servers {
 		name := s.Name.ValueString()
+		serverPath := path.Root("mcp_server").AtListIndex(i)
 		hasCommand := hasNonEmptyString(s.Command)
 		hasURL := hasNonEmptyString(s.URL)
 
 		if hasCommand == hasURL {
-			diags.AddError(
-				"Invalid MCP Server Configuration",
+			diags.AddAttributeError(
+				serverPath,
+				errcode.InvalidConfig.Summary("Invalid MCP Server Configuration"),
 				fmt.Sprintf("MCP server %q must set exactly one of command or url.", name),
 			)
 			continue
@@ -1030,8 +1699,9 @@ func (r *PluginResource) validateMcpServers(ctx context.Context, servers []Plugi
 			hasCwd := hasNonEmptyString(s.Cwd)
 
 			if hasArgs || hasEnv || hasCwd {
-				diags.AddError(
-					"Invalid MCP Server Configuration",
+				diags.AddAttributeError(
+					serverPath.AtName("url"),
+					errcode.InvalidConfig.Summary("Invalid MCP Server Configuration"),
 					fmt.Sprintf("MCP server %q uses url transport and cannot set args, env, or cwd.", name),
 				)
 			}
@@ -1041,7 +1711,7 @@ func (r *PluginResource) validateMcpServers(ctx context.Context, servers []Plugi
 		if !s.Args.IsNull() && !s.Args.IsUnknown() {
 			var args []string
 			d := s.Args.ElementsAs(ctx, &args, false)
-			diags.Append(d...)
+			diags.Append(withAttributePath(serverPath.AtName("args"), d)...)
 			if diags.HasError() {
 				continue
 			}
@@ -1050,7 +1720,7 @@ func (r *PluginResource) validateMcpServers(ctx context.Context, servers []Plugi
 		if !s.Env.IsNull() && !s.Env.IsUnknown() {
 			env := make(map[string]string)
 			d := s.Env.ElementsAs(ctx, &env, false)
-			diags.Append(d...)
+			diags.Append(withAttributePath(serverPath.AtName("env"), d)...)
 			if diags.HasError() {
 				continue
 			}
@@ -1063,14 +1733,15 @@ func (r *PluginResource) validateMcpServers(ctx context.Context, servers []Plugi
 // buildMcpJSON converts PluginMcpModel entries into an ordered map for .mcp.json.
 func (r *PluginResource) buildMcpJSON(ctx context.Context, servers []PluginMcpModel, diags *diag.Diagnostics) map[string]interface{} {
 	result := make(map[string]interface{})
-	for _, s := range servers {
+	for i, s := range servers {
+		serverPath := path.Root("mcp_server").AtListIndex(i)
 		entry := make(map[string]interface{})
 
 		if !s.Command.IsNull() && !s.Command.IsUnknown() {
 			entry["command"] = s.Command.ValueString()
 		}
 		if !s.URL.IsNull() && !s.URL.IsUnknown() {
-			entry["url"] = s.URL.ValueString()
+			entry["url"] = validators.NormalizeURL(s.URL.ValueString())
 		}
 		if !s.Cwd.IsNull() && !s.Cwd.IsUnknown() {
 			entry["cwd"] = s.Cwd.ValueString()
@@ -1078,7 +1749,7 @@ func (r *PluginResource) buildMcpJSON(ctx context.Context, servers []PluginMcpMo
 		if !s.Args.IsNull() && !s.Args.IsUnknown() {
 			var args []string
 			d := s.Args.ElementsAs(ctx, &args, false)
-			diags.Append(d...)
+			diags.Append(withAttributePath(serverPath.AtName("args"), d)...)
 			if diags.HasError() {
 				return nil
 			}
@@ -1087,7 +1758,7 @@ func (r *PluginResource) buildMcpJSON(ctx context.Context, servers []PluginMcpMo
 		if !s.Env.IsNull() && !s.Env.IsUnknown() {
 			env := make(map[string]string)
 			d := s.Env.ElementsAs(ctx, &env, false)
-			diags.Append(d...)
+			diags.Append(withAttributePath(serverPath.AtName("env"), d)...)
 			if diags.HasError() {
 				return nil
 			}
@@ -1102,7 +1773,8 @@ func (r *PluginResource) buildMcpJSON(ctx context.Context, servers []PluginMcpMo
 // buildLspJSON converts PluginLspModel entries into a map for .lsp.json.
 func (r *PluginResource) buildLspJSON(ctx context.Context, servers []PluginLspModel, diags *diag.Diagnostics) map[string]interface{} {
 	result := make(map[string]interface{})
-	for _, s := range servers {
+	for i, s := range servers {
+		serverPath := path.Root("lsp_server").AtListIndex(i)
 		entry := make(map[string]interface{})
 
 		entry["command"] = s.Command.ValueString()
@@ -1110,7 +1782,7 @@ func (r *PluginResource) buildLspJSON(ctx context.Context, servers []PluginLspMo
 		if !s.Args.IsNull() && !s.Args.IsUnknown() {
 			var args []string
 			d := s.Args.ElementsAs(ctx, &args, false)
-			diags.Append(d...)
+			diags.Append(withAttributePath(serverPath.AtName("args"), d)...)
 			if diags.HasError() {
 				return nil
 			}
@@ -1122,7 +1794,7 @@ func (r *PluginResource) buildLspJSON(ctx context.Context, servers []PluginLspMo
 		if !s.Env.IsNull() && !s.Env.IsUnknown() {
 			env := make(map[string]string)
 			d := s.Env.ElementsAs(ctx, &env, false)
-			diags.Append(d...)
+			diags.Append(withAttributePath(serverPath.AtName("env"), d)...)
 			if diags.HasError() {
 				return nil
 			}
@@ -1131,7 +1803,7 @@ func (r *PluginResource) buildLspJSON(ctx context.Context, servers []PluginLspMo
 		if !s.InitializationOptions.IsNull() && !s.InitializationOptions.IsUnknown() {
 			opts := make(map[string]string)
 			d := s.InitializationOptions.ElementsAs(ctx, &opts, false)
-			diags.Append(d...)
+			diags.Append(withAttributePath(serverPath.AtName("initialization_options"), d)...)
 			if diags.HasError() {
 				return nil
 			}
@@ -1140,7 +1812,7 @@ func (r *PluginResource) buildLspJSON(ctx context.Context, servers []PluginLspMo
 		if !s.Settings.IsNull() && !s.Settings.IsUnknown() {
 			settings := make(map[string]string)
 			d := s.Settings.ElementsAs(ctx, &settings, false)
-			diags.Append(d...)
+			diags.Append(withAttributePath(serverPath.AtName("settings"), d)...)
 			if diags.HasError() {
 				return nil
 			}
@@ -1150,7 +1822,7 @@ func (r *PluginResource) buildLspJSON(ctx context.Context, servers []PluginLspMo
 		// extension_to_language is required
 		extMap := make(map[string]string)
 		d := s.ExtensionToLanguage.ElementsAs(ctx, &extMap, false)
-		diags.Append(d...)
+		diags.Append(withAttributePath(serverPath.AtName("extension_to_language"), d)...)
 		if diags.HasError() {
 			return nil
 		}
@@ -1187,29 +1859,31 @@ func copyDirectory(src, dst string) diag.Diagnostics {
 
 	src, err := filepath.Abs(src)
 	if err != nil {
-		diags.AddError("Path Resolution Failed", fmt.Sprintf("Failed to resolve source path %q: %s", src, err))
+		diags.AddError(errcode.PathResolution.Summary("Path Resolution Failed"), fmt.Sprintf("Failed to resolve source path %q: %s", src, err))
 		return diags
 	}
+	src = longpath.Path(src)
+	dst = longpath.Path(dst)
 
 	// Ensure the source exists and is a directory.
 	info, err := os.Stat(src)
 	if err != nil {
-		diags.AddError("Source Directory Error", fmt.Sprintf("Failed to stat source directory %q: %s", src, err))
+		diags.AddError(errcode.FileRead.Summary("Source Directory Error"), fmt.Sprintf("Failed to stat source directory %q: %s", src, err))
 		return diags
 	}
 	if !info.IsDir() {
-		diags.AddError("Source Not a Directory", fmt.Sprintf("Source path %q is not a directory.", src))
+		diags.AddError(errcode.InvalidConfig.Summary("Source Not a Directory"), fmt.Sprintf("Source path %q is not a directory.", src))
 		return diags
 	}
 
 	if err := os.MkdirAll(dst, 0o755); err != nil {
-		diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create destination directory %q: %s", dst, err))
+		diags.AddError(errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create destination directory %q: %s", dst, err))
 		return diags
 	}
 
 	entries, err := os.ReadDir(src)
 	if err != nil {
-		diags.AddError("Directory Read Failed", fmt.Sprintf("Failed to read source directory %q: %s", src, err))
+		diags.AddError(errcode.FileRead.Summary("Directory Read Failed"), fmt.Sprintf("Failed to read source directory %q: %s", src, err))
 		return diags
 	}
 
@@ -1235,37 +1909,84 @@ func copyDirectory(src, dst string) diag.Diagnostics {
 	return diags
 }
 
+// copyBundle copies the files listed in an agentctx_skill bundle descriptor
+// into dst. Each source file is re-hashed and compared against the
+// descriptor so a source change between the skill's scan and the plugin
+// write is reported rather than silently shipped.
+func copyBundle(descriptorJSON, dst string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	desc, err := bundle.ParseDescriptor(descriptorJSON)
+	if err != nil {
+		diags.AddError(errcode.InvalidBundle.Summary("Invalid Skill Bundle"), fmt.Sprintf("Failed to parse source_bundle: %s", err))
+		return diags
+	}
+
+	relPaths := make([]string, 0, len(desc.Files))
+	for rel := range desc.Files {
+		relPaths = append(relPaths, rel)
+	}
+	sort.Strings(relPaths)
+
+	for _, rel := range relPaths {
+		src := longpath.Path(filepath.Join(desc.SourceDir, filepath.FromSlash(rel)))
+		dstPath := longpath.Path(filepath.Join(dst, filepath.FromSlash(rel)))
+
+		data, err := os.ReadFile(src)
+		if err != nil {
+			diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read bundled file %q: %s", src, err))
+			return diags
+		}
+
+		if got := bundle.ComputeFileHashBytes(data); got != desc.Files[rel] {
+			diags.AddError(errcode.InvalidBundle.Summary("Skill Bundle Changed"),
+				fmt.Sprintf("File %q in %q no longer matches source_bundle (expected %s, found %s). Apply the agentctx_skill resource that produced the bundle first.", rel, desc.SourceDir, desc.Files[rel], got))
+			return diags
+		}
+
+		info, err := os.Stat(src)
+		if err != nil {
+			diags.AddError(errcode.FileRead.Summary("File Stat Failed"), fmt.Sprintf("Failed to stat bundled file %q: %s", src, err))
+			return diags
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create parent directory for %q: %s", dstPath, err))
+			return diags
+		}
+		if err := atomicfile.WriteFile(dstPath, data, info.Mode().Perm()); err != nil {
+			diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write bundled file %q: %s", dstPath, err))
+			return diags
+		}
+	}
+
+	return diags
+}
+
 // copyFile copies a single file from src to dst, preserving permissions.
 func copyFile(src, dst string) diag.Diagnostics {
 	var diags diag.Diagnostics
 
-	srcFile, err := os.Open(src)
+	srcFile, err := os.Open(longpath.Path(src))
 	if err != nil {
-		diags.AddError("File Read Failed", fmt.Sprintf("Failed to open source file %q: %s", src, err))
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to open source file %q: %s", src, err))
 		return diags
 	}
 	defer srcFile.Close()
 
 	srcInfo, err := srcFile.Stat()
 	if err != nil {
-		diags.AddError("File Stat Failed", fmt.Sprintf("Failed to stat source file %q: %s", src, err))
+		diags.AddError(errcode.FileRead.Summary("File Stat Failed"), fmt.Sprintf("Failed to stat source file %q: %s", src, err))
 		return diags
 	}
 
-	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
-		diags.AddError("Directory Create Failed", fmt.Sprintf("Failed to create parent directory for %q: %s", dst, err))
+	if err := os.MkdirAll(longpath.Path(filepath.Dir(dst)), 0o755); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create parent directory for %q: %s", dst, err))
 		return diags
 	}
 
-	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, srcInfo.Mode())
-	if err != nil {
-		diags.AddError("File Write Failed", fmt.Sprintf("Failed to create destination file %q: %s", dst, err))
-		return diags
-	}
-	defer dstFile.Close()
-
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		diags.AddError("File Copy Failed", fmt.Sprintf("Failed to copy %q to %q: %s", src, dst, err))
+	if err := atomicfile.WriteReader(longpath.Path(dst), srcFile, srcInfo.Mode().Perm()); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Copy Failed"), fmt.Sprintf("Failed to copy %q to %q: %s", src, dst, err))
 		return diags
 	}
 
@@ -1281,15 +2002,19 @@ func cleanupManagedArtifacts(root string) error {
 		"hooks",
 		".mcp.json",
 		".lsp.json",
+		testScriptPath,
 	}
 
 	for _, p := range managedPaths {
-		target := filepath.Join(root, p)
+		target := filepath.Join(root, filepath.FromSlash(p))
 		if err := os.RemoveAll(target); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("remove %q: %w", target, err)
 		}
 	}
 
+	// tests/ may hold files from file blocks; only drop it once empty.
+	cleanupEmptyParents(filepath.Dir(filepath.Join(root, filepath.FromSlash(testScriptPath))), root)
+
 	return nil
 }
 
@@ -1298,9 +2023,10 @@ func cleanupRemovedExtraFiles(outputDir string, previous, next []PluginFileModel
 
 	absRoot, err := filepath.Abs(outputDir)
 	if err != nil {
-		diags.AddError("Path Resolution Failed", fmt.Sprintf("Failed to resolve output_dir %q: %s", outputDir, err))
+		diags.AddError(errcode.PathResolution.Summary("Path Resolution Failed"), fmt.Sprintf("Failed to resolve output_dir %q: %s", outputDir, err))
 		return diags
 	}
+	absRoot = longpath.Path(absRoot)
 
 	nextPaths := make(map[string]struct{}, len(next))
 	for _, f := range next {
@@ -1321,14 +2047,14 @@ func cleanupRemovedExtraFiles(outputDir string, previous, next []PluginFileModel
 		}
 
 		if filepath.IsAbs(relPath) || strings.Contains(relPath, "..") {
-			diags.AddError("Invalid File Path", fmt.Sprintf("File path %q must be relative and not contain '..'.", relPath))
+			diags.AddError(errcode.PathTraversal.Summary("Invalid File Path"), fmt.Sprintf("File path %q must be relative and not contain '..'.", relPath))
 			return diags
 		}
 
 		target := filepath.Join(absRoot, relPath)
 		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
 			if rmErr := os.RemoveAll(target); rmErr != nil && !os.IsNotExist(rmErr) {
-				diags.AddError("File Delete Failed", fmt.Sprintf("Failed to delete removed file %q: %s", relPath, rmErr))
+				diags.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete removed file %q: %s", relPath, rmErr))
 				return diags
 			}
 		}
@@ -1364,6 +2090,29 @@ func cleanupEmptyParents(startDir, stopDir string) {
 // Helpers
 // --------------------------------------------------------------------------
 
+// withAttributePath returns diags with every diagnostic attached to p, so
+// failures reported by shared helpers (copyFile, copyBundle, ElementsAs, ...)
+// point at the block and attribute that caused them. Diagnostics that already
+// carry a path are kept as-is.
+func withAttributePath(p path.Path, diags diag.Diagnostics) diag.Diagnostics {
+	out := make(diag.Diagnostics, 0, len(diags))
+	for _, d := range diags {
+		if _, ok := d.(diag.DiagnosticWithPath); ok {
+			out = append(out, d)
+			continue
+		}
+		switch d.Severity() {
+		case diag.SeverityError:
+			out = append(out, diag.NewAttributeErrorDiagnostic(p, d.Summary(), d.Detail()))
+		case diag.SeverityWarning:
+			out = append(out, diag.NewAttributeWarningDiagnostic(p, d.Summary(), d.Detail()))
+		default:
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
 // computeHash returns the SHA-256 hash of the given content, prefixed with
 // "sha256:" to match the convention used elsewhere in the provider.
 func computeHash(content string) string {
@@ -1371,18 +2120,6 @@ func computeHash(content string) string {
 	return fmt.Sprintf("sha256:%x", h)
 }
 
-// marshalDeterministic marshals a value to indented JSON with sorted map keys
-// and a trailing newline. Go's encoding/json sorts map[string]* keys
-// lexicographically by default, and struct fields are serialized in
-// declaration order, so the output is deterministic.
-func marshalDeterministic(v interface{}) ([]byte, error) {
-	data, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
-		return nil, err
-	}
-	return append(data, '\n'), nil
-}
-
 func hasNonEmptyString(v types.String) bool {
 	return !v.IsNull() && !v.IsUnknown() && strings.TrimSpace(v.ValueString()) != ""
 }
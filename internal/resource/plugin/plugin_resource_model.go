@@ -1,6 +1,10 @@
 package plugin
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/contentscan"
+)
 
 // PluginResourceModel maps the agentctx_plugin resource schema to a Go struct.
 type PluginResourceModel struct {
@@ -16,6 +20,38 @@ type PluginResourceModel struct {
 	License     types.String `tfsdk:"license"`
 	Keywords    types.List   `tfsdk:"keywords"`
 
+	// Optional – compatibility
+	RequiresClaudeVersion types.String `tfsdk:"requires_claude_version"`
+	CompatibilityLevel    types.String `tfsdk:"compatibility_level"`
+
+	// Optional – variable interpolation checks
+	EnvVarPolicy           types.String `tfsdk:"env_var_policy"`
+	KnownEnvVars           types.List   `tfsdk:"known_env_vars"`
+	VerifyScriptReferences types.Bool   `tfsdk:"verify_script_references"`
+
+	// Optional – test scaffolding
+	GenerateTests types.Bool `tfsdk:"generate_tests"`
+
+	// Optional – provenance
+	Provenance types.Bool `tfsdk:"provenance"`
+
+	// Optional – JSON output formatting
+	JSONFormat  types.String `tfsdk:"json_format"`
+	JSONSchemas types.Map    `tfsdk:"json_schemas"`
+
+	// Optional – concurrent writers
+	LockTimeoutSeconds types.Int64 `tfsdk:"lock_timeout_seconds"`
+
+	// Optional – state size
+	ContentStorage types.String `tfsdk:"content_storage"`
+
+	// Optional – template variables
+	VarsFile types.String `tfsdk:"vars_file"`
+
+	// Optional – scratch output directories
+	RegenerateIfMissing types.Bool   `tfsdk:"regenerate_if_missing"`
+	CacheDir            types.String `tfsdk:"cache_dir"`
+
 	// Optional – author block
 	Author []AuthorModel `tfsdk:"author"`
 
@@ -29,11 +65,18 @@ type PluginResourceModel struct {
 	Hooks        []PluginHooksModel       `tfsdk:"hooks"`
 	Files        []PluginFileModel        `tfsdk:"file"`
 
+	// Optional – content_scan block
+	ContentScan []contentscan.BlockModel `tfsdk:"content_scan"`
+
 	// Computed
-	ID           types.String `tfsdk:"id"`
-	PluginDir    types.String `tfsdk:"plugin_dir"`
-	ManifestJSON types.String `tfsdk:"manifest_json"`
-	ContentHash  types.String `tfsdk:"content_hash"`
+	ID             types.String `tfsdk:"id"`
+	PluginDir      types.String `tfsdk:"plugin_dir"`
+	ManifestJSON   types.String `tfsdk:"manifest_json"`
+	ContentHash    types.String `tfsdk:"content_hash"`
+	VarsHash       types.String `tfsdk:"vars_hash"`
+	InventoryJSON  types.String `tfsdk:"inventory_json"`
+	FileHashes     types.Map    `tfsdk:"file_hashes"`
+	UnmanagedFiles types.List   `tfsdk:"unmanaged_files"`
 }
 
 // AuthorModel maps the author {} block.
@@ -49,13 +92,22 @@ type PluginOutputStyleModel struct {
 }
 
 // PluginSkillModel maps a skill {} block. Skills can be sourced from a local
-// directory (source_dir) or defined inline (content). When source_dir is set,
-// the entire directory is copied into skills/<name>/. When content is set,
+// directory (source_dir), from an agentctx_skill bundle descriptor
+// (source_bundle), or defined inline (content). When source_dir is set, the
+// entire directory is copied into skills/<name>/. When source_bundle is set,
+// only the files listed in the descriptor are copied. When content is set,
 // a SKILL.md file is written to skills/<name>/SKILL.md.
 type PluginSkillModel struct {
-	Name      types.String `tfsdk:"name"`
-	SourceDir types.String `tfsdk:"source_dir"`
-	Content   types.String `tfsdk:"content"`
+	Name         types.String `tfsdk:"name"`
+	SourceDir    types.String `tfsdk:"source_dir"`
+	SourceBundle types.String `tfsdk:"source_bundle"`
+	Content      types.String `tfsdk:"content"`
+
+	// Frontmatter, written above content
+	Description  types.String `tfsdk:"description"`
+	AllowedTools types.List   `tfsdk:"allowed_tools"`
+	Metadata     types.Map    `tfsdk:"metadata"`
+	License      types.String `tfsdk:"license"`
 }
 
 // PluginAgentModel maps an agent {} block. Agents can be sourced from an
@@ -64,6 +116,13 @@ type PluginAgentModel struct {
 	Name       types.String `tfsdk:"name"`
 	SourceFile types.String `tfsdk:"source_file"`
 	Content    types.String `tfsdk:"content"`
+
+	// Frontmatter, written above content
+	Description    types.String `tfsdk:"description"`
+	Tools          types.List   `tfsdk:"tools"`
+	Model          types.String `tfsdk:"model"`
+	PermissionMode types.String `tfsdk:"permission_mode"`
+	Skills         types.List   `tfsdk:"skills"`
 }
 
 // PluginCommandModel maps a command {} block.
@@ -71,6 +130,13 @@ type PluginCommandModel struct {
 	Name       types.String `tfsdk:"name"`
 	SourceFile types.String `tfsdk:"source_file"`
 	Content    types.String `tfsdk:"content"`
+
+	// Frontmatter, written above content
+	Description            types.String `tfsdk:"description"`
+	ArgumentHint           types.String `tfsdk:"argument_hint"`
+	AllowedTools           types.List   `tfsdk:"allowed_tools"`
+	Model                  types.String `tfsdk:"model"`
+	DisableModelInvocation types.Bool   `tfsdk:"disable_model_invocation"`
 }
 
 // PluginMcpModel maps an mcp_server {} block for the plugin's .mcp.json.
@@ -121,6 +187,8 @@ type PluginHooksModel struct {
 // PluginHookMatcherModel maps a single hook matcher entry.
 type PluginHookMatcherModel struct {
 	Matcher types.String           `tfsdk:"matcher"`
+	Tools   types.List             `tfsdk:"tools"` // list of strings; see ToolsMatcher
+	Order   types.Int64            `tfsdk:"order"`
 	Hooks   []PluginHookEntryModel `tfsdk:"hook"`
 }
 
@@ -128,6 +196,7 @@ type PluginHookMatcherModel struct {
 type PluginHookEntryModel struct {
 	Type    types.String `tfsdk:"type"`
 	Command types.String `tfsdk:"command"`
+	Agent   types.String `tfsdk:"agent"` // name of an agent {} block; type "agent" only
 }
 
 // PluginFileModel maps a file {} block for bundling extra files into the plugin.
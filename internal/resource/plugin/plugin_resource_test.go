@@ -5,10 +5,19 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
 )
 
 // --------------------------------------------------------------------------
@@ -48,19 +57,19 @@ func TestNamePattern(t *testing.T) {
 	invalid := []string{"", "-plugin", "plugin-", "Plugin", "my_plugin", "my plugin", "UPPER", "with.dot"}
 
 	for _, name := range valid {
-		if !namePattern.MatchString(name) {
+		if validators.CheckName(name) != nil {
 			t.Errorf("expected %q to be valid", name)
 		}
 	}
 	for _, name := range invalid {
-		if namePattern.MatchString(name) {
+		if validators.CheckName(name) == nil {
 			t.Errorf("expected %q to be invalid", name)
 		}
 	}
 }
 
 // --------------------------------------------------------------------------
-// marshalDeterministic tests
+// JSON marshaling tests
 // --------------------------------------------------------------------------
 
 func TestMarshalDeterministic(t *testing.T) {
@@ -69,7 +78,7 @@ func TestMarshalDeterministic(t *testing.T) {
 		"a": "first",
 	}
 
-	result, err := marshalDeterministic(data)
+	result, err := jsonOptions{}.marshal(mcpJSONPath, data)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -391,6 +400,174 @@ func TestWritePlugin_WithSourceDirSkill(t *testing.T) {
 	assertFileContent(t, filepath.Join(skillDir, "reference.md"), "# Reference")
 }
 
+func TestWritePlugin_WithSourceBundleSkill(t *testing.T) {
+	r := &PluginResource{}
+
+	srcDir := filepath.Join(t.TempDir(), "bundled-skill")
+	if err := os.MkdirAll(filepath.Join(srcDir, "scripts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "SKILL.md"), []byte("# Bundled Skill"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "scripts", "run.sh"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "draft.tmp"), []byte("scratch"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := bundle.ScanBundle(srcDir, []string{"*.tmp"}, false)
+	if err != nil {
+		t.Fatalf("ScanBundle: %v", err)
+	}
+	descriptor, err := b.MarshalDescriptor()
+	if err != nil {
+		t.Fatalf("MarshalDescriptor: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "bundle-plugin")
+	model := &PluginResourceModel{
+		Name:        stringValue("bundle-plugin"),
+		OutputDir:   stringValue(dir),
+		Version:     types.StringNull(),
+		Description: types.StringNull(),
+		Homepage:    types.StringNull(),
+		Repository:  types.StringNull(),
+		License:     types.StringNull(),
+		Keywords:    types.ListNull(types.StringType),
+		Skills: []PluginSkillModel{
+			{
+				Name:         stringValue("bundled-skill"),
+				SourceDir:    types.StringNull(),
+				SourceBundle: stringValue(descriptor),
+				Content:      types.StringNull(),
+			},
+		},
+	}
+
+	diags := r.writePlugin(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	skillDir := filepath.Join(dir, "skills", "bundled-skill")
+	assertFileContent(t, filepath.Join(skillDir, "SKILL.md"), "# Bundled Skill")
+	assertFileContent(t, filepath.Join(skillDir, "scripts", "run.sh"), "#!/bin/sh\n")
+
+	// Files excluded from the skill bundle must not be copied.
+	if _, err := os.Stat(filepath.Join(skillDir, "draft.tmp")); !os.IsNotExist(err) {
+		t.Errorf("expected excluded file draft.tmp to be absent, stat err = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(skillDir, "scripts", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("expected run.sh to keep its executable bit, got %v", info.Mode().Perm())
+	}
+}
+
+func TestWritePlugin_SourceBundleDetectsChangedFile(t *testing.T) {
+	r := &PluginResource{}
+
+	srcDir := filepath.Join(t.TempDir(), "changing-skill")
+	if err := os.MkdirAll(srcDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "SKILL.md"), []byte("# Original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := bundle.ScanBundle(srcDir, nil, false)
+	if err != nil {
+		t.Fatalf("ScanBundle: %v", err)
+	}
+	descriptor, err := b.MarshalDescriptor()
+	if err != nil {
+		t.Fatalf("MarshalDescriptor: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(srcDir, "SKILL.md"), []byte("# Edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "stale-plugin")
+	model := &PluginResourceModel{
+		Name:        stringValue("stale-plugin"),
+		OutputDir:   stringValue(dir),
+		Version:     types.StringNull(),
+		Description: types.StringNull(),
+		Homepage:    types.StringNull(),
+		Repository:  types.StringNull(),
+		License:     types.StringNull(),
+		Keywords:    types.ListNull(types.StringType),
+		Skills: []PluginSkillModel{
+			{
+				Name:         stringValue("changing-skill"),
+				SourceDir:    types.StringNull(),
+				SourceBundle: stringValue(descriptor),
+				Content:      types.StringNull(),
+			},
+		},
+	}
+
+	diags := r.writePlugin(context.Background(), model)
+	if !diags.HasError() {
+		t.Fatal("expected error when a bundled file changed after the scan")
+	}
+	found := false
+	for _, d := range diags.Errors() {
+		if strings.Contains(d.Detail(), "no longer matches source_bundle") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected hash mismatch error, got %v", diags.Errors())
+	}
+}
+
+func TestWritePlugin_SourceBundleWithSourceDir(t *testing.T) {
+	r := &PluginResource{}
+	srcDir := t.TempDir()
+	os.WriteFile(filepath.Join(srcDir, "SKILL.md"), []byte("# Skill"), 0o644)
+
+	b, err := bundle.ScanBundle(srcDir, nil, false)
+	if err != nil {
+		t.Fatalf("ScanBundle: %v", err)
+	}
+	descriptor, err := b.MarshalDescriptor()
+	if err != nil {
+		t.Fatalf("MarshalDescriptor: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "conflict-plugin")
+	model := &PluginResourceModel{
+		Name:        stringValue("conflict-plugin"),
+		OutputDir:   stringValue(dir),
+		Version:     types.StringNull(),
+		Description: types.StringNull(),
+		Homepage:    types.StringNull(),
+		Repository:  types.StringNull(),
+		License:     types.StringNull(),
+		Keywords:    types.ListNull(types.StringType),
+		Skills: []PluginSkillModel{
+			{
+				Name:         stringValue("conflicting-skill"),
+				SourceDir:    stringValue(srcDir),
+				SourceBundle: stringValue(descriptor),
+				Content:      types.StringNull(),
+			},
+		},
+	}
+
+	diags := r.writePlugin(context.Background(), model)
+	if !diags.HasError() {
+		t.Error("expected error when both source_dir and source_bundle are set")
+	}
+}
+
 func TestWritePlugin_WithInlineAgent(t *testing.T) {
 	r := &PluginResource{}
 	dir := filepath.Join(t.TempDir(), "agent-plugin")
@@ -770,6 +947,61 @@ func TestWritePlugin_WithExtraFiles(t *testing.T) {
 	}
 }
 
+func TestRefreshFileModes_DetectsAndRepairsDrift(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not tracked on Windows")
+	}
+
+	r := &PluginResource{}
+	dir := filepath.Join(t.TempDir(), "modes-plugin")
+	model := &PluginResourceModel{
+		Name:      stringValue("modes-plugin"),
+		OutputDir: stringValue(dir),
+		Keywords:  types.ListNull(types.StringType),
+		Files: []PluginFileModel{
+			{Path: stringValue("scripts/lint.sh"), Content: stringValue("#!/bin/sh"), SourceFile: types.StringNull(), Executable: types.BoolValue(true)},
+			{Path: stringValue("README.md"), Content: stringValue("# readme"), SourceFile: types.StringNull(), Executable: types.BoolValue(false)},
+		},
+	}
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	// No drift right after apply.
+	files := append([]PluginFileModel(nil), model.Files...)
+	if diags := refreshFileModes(context.Background(), dir, files); len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	// Another tool strips the executable bit.
+	scriptPath := filepath.Join(dir, "scripts", "lint.sh")
+	if err := os.Chmod(scriptPath, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diags := refreshFileModes(context.Background(), dir, files)
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("expected 1 drift warning, got %v", diags)
+	}
+	if files[0].Executable.ValueBool() {
+		t.Error("expected refreshed state to record the file as not executable")
+	}
+	if !files[1].Executable.Equal(types.BoolValue(false)) {
+		t.Error("expected unchanged file to keep its state")
+	}
+
+	// Re-applying the configuration restores the mode.
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	info, err := os.Stat(scriptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("mode = %04o, want 0755", info.Mode().Perm())
+	}
+}
+
 func TestWritePlugin_WithExtraFileFromSource(t *testing.T) {
 	r := &PluginResource{}
 
@@ -836,6 +1068,110 @@ func TestWritePlugin_InvalidFilePath(t *testing.T) {
 	}
 }
 
+func TestWritePlugin_DiagnosticsCarryAttributePaths(t *testing.T) {
+	r := &PluginResource{}
+
+	inlineSkill := func(name string) PluginSkillModel {
+		return PluginSkillModel{
+			Name:      stringValue(name),
+			SourceDir: types.StringNull(),
+			Content:   stringValue("# " + name),
+		}
+	}
+
+	cases := []struct {
+		name  string
+		model func(dir string) *PluginResourceModel
+		want  path.Path
+	}{
+		{
+			name: "skill without source",
+			model: func(dir string) *PluginResourceModel {
+				return &PluginResourceModel{
+					Name:      stringValue("paths-plugin"),
+					OutputDir: stringValue(dir),
+					Keywords:  types.ListNull(types.StringType),
+					Skills: []PluginSkillModel{
+						inlineSkill("one"),
+						inlineSkill("two"),
+						{Name: stringValue("three"), SourceDir: types.StringNull(), Content: types.StringNull()},
+					},
+				}
+			},
+			want: path.Root("skill").AtListIndex(2),
+		},
+		{
+			name: "skill with missing source_dir",
+			model: func(dir string) *PluginResourceModel {
+				return &PluginResourceModel{
+					Name:      stringValue("paths-plugin"),
+					OutputDir: stringValue(dir),
+					Keywords:  types.ListNull(types.StringType),
+					Skills: []PluginSkillModel{
+						inlineSkill("one"),
+						{Name: stringValue("two"), SourceDir: stringValue(filepath.Join(dir, "does-not-exist")), Content: types.StringNull()},
+					},
+				}
+			},
+			want: path.Root("skill").AtListIndex(1).AtName("source_dir"),
+		},
+		{
+			name: "file escaping output_dir",
+			model: func(dir string) *PluginResourceModel {
+				return &PluginResourceModel{
+					Name:      stringValue("paths-plugin"),
+					OutputDir: stringValue(dir),
+					Keywords:  types.ListNull(types.StringType),
+					Files: []PluginFileModel{
+						{Path: stringValue("ok.txt"), Content: stringValue("ok"), SourceFile: types.StringNull()},
+						{Path: stringValue("../escape.txt"), Content: stringValue("no"), SourceFile: types.StringNull()},
+					},
+				}
+			},
+			want: path.Root("file").AtListIndex(1).AtName("path"),
+		},
+		{
+			name: "mcp server with both transports",
+			model: func(dir string) *PluginResourceModel {
+				return &PluginResourceModel{
+					Name:      stringValue("paths-plugin"),
+					OutputDir: stringValue(dir),
+					Keywords:  types.ListNull(types.StringType),
+					McpServers: []PluginMcpModel{
+						{
+							Name:    stringValue("both"),
+							Command: stringValue("server"),
+							URL:     stringValue("https://example.com/mcp"),
+							Args:    types.ListNull(types.StringType),
+							Env:     types.MapNull(types.StringType),
+							Cwd:     types.StringNull(),
+						},
+					},
+				}
+			},
+			want: path.Root("mcp_server").AtListIndex(0),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "paths-plugin")
+			diags := r.writePlugin(context.Background(), tc.model(dir))
+			if !diags.HasError() {
+				t.Fatal("expected an error")
+			}
+
+			withPath, ok := diags.Errors()[0].(diag.DiagnosticWithPath)
+			if !ok {
+				t.Fatalf("expected an attribute diagnostic, got %v", diags.Errors()[0])
+			}
+			if !withPath.Path().Equal(tc.want) {
+				t.Errorf("path = %s, want %s", withPath.Path(), tc.want)
+			}
+		})
+	}
+}
+
 func TestWritePlugin_SkillMissingSourceAndContent(t *testing.T) {
 	r := &PluginResource{}
 	dir := filepath.Join(t.TempDir(), "missing-plugin")
@@ -1077,6 +1413,81 @@ func TestBuildHooksJSON_AllEvents(t *testing.T) {
 	}
 }
 
+func TestBuildHooksJSON_MatcherOrder(t *testing.T) {
+	r := &PluginResource{}
+
+	entry := func(matcher string, order *int64) PluginHookMatcherModel {
+		m := PluginHookMatcherModel{
+			Matcher: stringValue(matcher),
+			Order:   types.Int64Null(),
+			Hooks: []PluginHookEntryModel{
+				{Type: stringValue("command"), Command: stringValue("run " + matcher)},
+			},
+		}
+		if order != nil {
+			m.Order = types.Int64Value(*order)
+		}
+		return m
+	}
+	ptr := func(v int64) *int64 { return &v }
+
+	hooks := PluginHooksModel{
+		PostToolUse: []PluginHookMatcherModel{
+			entry("Lint", ptr(20)),
+			entry("Format", ptr(10)),
+			entry("Audit", nil),
+			entry("Notify", ptr(20)),
+			entry("Early", ptr(-5)),
+		},
+	}
+
+	result := r.buildHooksJSON(hooks)
+	entries, ok := result["PostToolUse"].([]map[string]interface{})
+	if !ok {
+		t.Fatalf("unexpected PostToolUse type %T", result["PostToolUse"])
+	}
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e["matcher"].(string))
+	}
+	want := []string{"Early", "Audit", "Format", "Lint", "Notify"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("matcher order = %v, want %v", got, want)
+	}
+
+	// The order attribute is not part of the hooks.json schema.
+	if _, ok := entries[0]["order"]; ok {
+		t.Error("order must not be written to hooks.json")
+	}
+}
+
+func TestBuildHooksJSON_Tools(t *testing.T) {
+	r := &PluginResource{}
+	hooks := PluginHooksModel{
+		PreToolUse: []PluginHookMatcherModel{
+			{
+				Matcher: types.StringNull(),
+				Tools:   stringList("Write", "Edit", "mcp__docs.site__fetch"),
+				Hooks:   []PluginHookEntryModel{{Type: stringValue("command"), Command: stringValue("./lint.sh")}},
+			},
+		},
+	}
+
+	entries := r.buildHooksJSON(hooks)["PreToolUse"].([]map[string]interface{})
+	want := `^(Write|Edit|mcp__docs\.site__fetch)$`
+	if got := entries[0]["matcher"]; got != want {
+		t.Errorf("matcher = %v, want %s", got, want)
+	}
+
+	re := regexp.MustCompile(want)
+	for tool, match := range map[string]bool{"Write": true, "Edit": true, "WriteFile": false, "NotebookEdit": false, "mcp__docs_site__fetch": false} {
+		if re.MatchString(tool) != match {
+			t.Errorf("matcher matches %q = %v, want %v", tool, !match, match)
+		}
+	}
+}
+
 // --------------------------------------------------------------------------
 // copyDirectory tests
 // --------------------------------------------------------------------------
@@ -1099,6 +1510,61 @@ func TestCopyDirectory(t *testing.T) {
 	assertFileContent(t, filepath.Join(dstDir, "sub", "file2.txt"), "content2")
 }
 
+func TestWritePlugin_BeyondMaxPath(t *testing.T) {
+	r := &PluginResource{}
+
+	// Nest both the source and the output well past Windows' MAX_PATH.
+	deep := func(root string) string {
+		for len(root) <= 300 {
+			root = filepath.Join(root, strings.Repeat("n", 40))
+		}
+		return root
+	}
+	srcDir := deep(t.TempDir())
+	if err := os.MkdirAll(longpath.Path(filepath.Join(srcDir, "scripts")), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(longpath.Path(filepath.Join(srcDir, "SKILL.md")), []byte("# Deep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(longpath.Path(filepath.Join(srcDir, "scripts", "run.sh")), []byte("echo deep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := deep(t.TempDir())
+	model := &PluginResourceModel{
+		Name:      stringValue("deep-plugin"),
+		OutputDir: stringValue(dir),
+		Keywords:  types.ListNull(types.StringType),
+		Skills: []PluginSkillModel{
+			{Name: stringValue("deep-skill"), SourceDir: stringValue(srcDir), SourceBundle: types.StringNull(), Content: types.StringNull()},
+		},
+		Agents: []PluginAgentModel{
+			{Name: stringValue("reviewer"), SourceFile: types.StringNull(), Content: stringValue("You review code.")},
+		},
+	}
+
+	diags := r.writePlugin(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	// State keeps the path as the user would write it.
+	if got := model.PluginDir.ValueString(); got != dir {
+		t.Errorf("plugin_dir = %q, want %q", got, dir)
+	}
+	for rel, want := range map[string]string{
+		"skills/deep-skill/SKILL.md":       "# Deep",
+		"skills/deep-skill/scripts/run.sh": "echo deep",
+		"agents/reviewer.md":               "You review code.",
+	} {
+		data, err := os.ReadFile(longpath.Path(filepath.Join(dir, filepath.FromSlash(rel))))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", rel, data, err, want)
+		}
+	}
+}
+
 func TestCopyDirectory_NonExistent(t *testing.T) {
 	diags := copyDirectory("/nonexistent/path", t.TempDir())
 	if !diags.HasError() {
@@ -1872,3 +2338,76 @@ func assertFileContent(t *testing.T, path, expected string) {
 		t.Errorf("expected content %q, got %q", expected, string(data))
 	}
 }
+
+// --------------------------------------------------------------------------
+// requires_claude_version tests
+// --------------------------------------------------------------------------
+
+func TestWritePlugin_RequiresClaudeVersion(t *testing.T) {
+	r := &PluginResource{}
+	dir := filepath.Join(t.TempDir(), "test-plugin")
+
+	model := &PluginResourceModel{
+		Name:                  stringValue("test-plugin"),
+		OutputDir:             stringValue(dir),
+		Keywords:              types.ListNull(types.StringType),
+		RequiresClaudeVersion: stringValue(">= 2.0.45"),
+	}
+
+	diags := r.writePlugin(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	var manifest map[string]interface{}
+	if err := json.Unmarshal([]byte(model.ManifestJSON.ValueString()), &manifest); err != nil {
+		t.Fatalf("invalid JSON manifest: %v", err)
+	}
+	if manifest["requiresClaudeVersion"] != ">= 2.0.45" {
+		t.Errorf("expected requiresClaudeVersion '>= 2.0.45', got %v", manifest["requiresClaudeVersion"])
+	}
+}
+
+func TestValidateClaudeVersion(t *testing.T) {
+	r := &PluginResource{}
+	sessionEnd := []PluginHooksModel{{
+		SessionEnd: []PluginHookMatcherModel{{
+			Matcher: types.StringNull(),
+			Hooks:   []PluginHookEntryModel{{Type: stringValue("command"), Command: stringValue("echo bye")}},
+		}},
+	}}
+
+	oldLevel, err := claudeversion.ParseLevel("1.0.80")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		requires types.String
+		hooks    []PluginHooksModel
+		level    *claudeversion.Level
+		errors   int
+		warnings int
+	}{
+		{"unset without versioned features", types.StringNull(), nil, nil, 0, 0},
+		{"unset with versioned features", types.StringNull(), sessionEnd, nil, 0, 1},
+		{"invalid syntax", stringValue("newest"), nil, nil, 1, 0},
+		{"constraint too old", stringValue(">= 1.0.0"), sessionEnd, nil, 0, 1},
+		{"constraint satisfied", stringValue(">= 1.0.85"), sessionEnd, nil, 0, 0},
+		{"unknown", types.StringUnknown(), sessionEnd, nil, 0, 0},
+		{"feature omitted by compatibility level", stringValue(">= 1.0.0"), sessionEnd, oldLevel, 0, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := r.validateClaudeVersion(tc.requires, tc.hooks, tc.level)
+			if got := diags.ErrorsCount(); got != tc.errors {
+				t.Errorf("errors = %d, want %d: %v", got, tc.errors, diags)
+			}
+			if got := diags.WarningsCount(); got != tc.warnings {
+				t.Errorf("warnings = %d, want %d: %v", got, tc.warnings, diags)
+			}
+		})
+	}
+}
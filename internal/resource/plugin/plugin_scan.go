@@ -0,0 +1,28 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/contentscan"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// scanContent runs the content_scan block of model over the Markdown files
+// written to pluginDir, whatever block wrote them or copied them from a
+// source. It reports nothing when there is no block.
+func scanContent(ctx context.Context, model *PluginResourceModel, pluginDir string) diag.Diagnostics {
+	opts, policy, ok, diags := contentscan.Config(ctx, model.ContentScan)
+	if !ok {
+		return diags
+	}
+	findings, err := opts.Dir(pluginDir)
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read the plugin files for content_scan: %s", err))
+		return diags
+	}
+	diags.Append(contentscan.Report(findings, policy, fmt.Sprintf("plugin %q", model.Name.ValueString()))...)
+	return diags
+}
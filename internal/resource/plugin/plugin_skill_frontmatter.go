@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+)
+
+// skillFrontmatterAttributes are the attributes of a skill block that are
+// written to the YAML frontmatter of its SKILL.md.
+var skillFrontmatterAttributes = []string{"description", "allowed_tools", "metadata", "license"}
+
+// skillFrontmatter is the YAML frontmatter of a SKILL.md, with the fields
+// of the Claude skill specification that skill blocks set.
+type skillFrontmatter struct {
+	Name         string            `yaml:"name"`
+	Description  string            `yaml:"description"`
+	License      string            `yaml:"license,omitempty"`
+	AllowedTools string            `yaml:"allowed-tools,omitempty"`
+	Metadata     map[string]string `yaml:"metadata,omitempty"`
+}
+
+// skillFrontmatterBlock returns s as a frontmatterBlock. Its content is the
+// SKILL.md body; description is required because the skill specification
+// makes it mandatory.
+func skillFrontmatterBlock(ctx context.Context, s PluginSkillModel) frontmatterBlock {
+	b := frontmatterBlock{
+		kind:              "skill",
+		name:              s.Name.ValueString(),
+		content:           s.Content,
+		set:               setAttributes(skillFrontmatterAttributes, s.Description, s.AllowedTools, s.Metadata, s.License),
+		sourceFrontmatter: "the skill's " + bundle.SkillFile,
+		required:          "description",
+		requiredReason:    fmt.Sprintf("The skill specification requires a description in %s frontmatter for Claude to decide when to use the skill.", bundle.SkillFile),
+		render: func(body string) (string, error) {
+			return renderSkillFile(ctx, s, body)
+		},
+	}
+	switch {
+	case !s.SourceDir.IsNull():
+		b.source = "source_dir"
+	case !s.SourceBundle.IsNull():
+		b.source = "source_bundle"
+	}
+	return b
+}
+
+// renderSkillFile returns the SKILL.md of s: its frontmatter, then body
+// trimmed of leading and trailing whitespace.
+func renderSkillFile(ctx context.Context, s PluginSkillModel, body string) (string, error) {
+	fm := skillFrontmatter{
+		Name:        s.Name.ValueString(),
+		Description: s.Description.ValueString(),
+		License:     s.License.ValueString(),
+	}
+	if !s.AllowedTools.IsNull() && !s.AllowedTools.IsUnknown() {
+		var tools []string
+		if d := s.AllowedTools.ElementsAs(ctx, &tools, false); d.HasError() {
+			return "", fmt.Errorf("reading allowed_tools of skill %q", s.Name.ValueString())
+		}
+		fm.AllowedTools = strings.Join(tools, ", ")
+	}
+	if !s.Metadata.IsNull() && !s.Metadata.IsUnknown() {
+		if d := s.Metadata.ElementsAs(ctx, &fm.Metadata, false); d.HasError() {
+			return "", fmt.Errorf("reading metadata of skill %q", s.Name.ValueString())
+		}
+	}
+
+	yamlBytes, err := yaml.Marshal(&fm)
+	if err != nil {
+		return "", fmt.Errorf("marshaling frontmatter: %w", err)
+	}
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.Write(yamlBytes)
+	sb.WriteString("---\n\n")
+	sb.WriteString(strings.TrimSpace(body))
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
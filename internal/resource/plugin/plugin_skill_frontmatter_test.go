@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestWritePlugin_SkillFrontmatter(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+
+	model := scaffoldModel(dir)
+	model.VarsFile = stringValue(writeVarsFile(t, "prod.yaml", "env: prod\n"))
+	model.Skills = []PluginSkillModel{
+		{
+			Name:         stringValue("release-notes"),
+			SourceDir:    types.StringNull(),
+			SourceBundle: types.StringNull(),
+			Content:      stringValue("\n# Release Notes\n\nSummarize changes shipped to {{ .env }}.\n"),
+			Description:  stringValue("Writes release notes. Use when preparing a release."),
+			AllowedTools: types.ListValueMust(types.StringType, []attr.Value{
+				types.StringValue("Read"),
+				types.StringValue("Bash(git log:*)"),
+			}),
+			Metadata: types.MapValueMust(types.StringType, map[string]attr.Value{
+				"version": types.StringValue("1.2.0"),
+				"owner":   types.StringValue("platform"),
+			}),
+			License: stringValue("Apache-2.0"),
+		},
+		{
+			// A complete SKILL.md in content is not given a second header.
+			Name:         stringValue("plain"),
+			SourceDir:    types.StringNull(),
+			SourceBundle: types.StringNull(),
+			Content:      stringValue("---\nname: plain\ndescription: Plain\n---\n\nBody.\n"),
+		},
+	}
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	for rel, want := range map[string]string{
+		"skills/release-notes/SKILL.md": "---\n" +
+			"name: release-notes\n" +
+			"description: Writes release notes. Use when preparing a release.\n" +
+			"license: Apache-2.0\n" +
+			"allowed-tools: Read, Bash(git log:*)\n" +
+			"metadata:\n" +
+			"    owner: platform\n" +
+			"    version: 1.2.0\n" +
+			"---\n\n" +
+			"# Release Notes\n\nSummarize changes shipped to prod.\n",
+		"skills/plain/SKILL.md": "---\nname: plain\ndescription: Plain\n---\n\nBody.\n",
+	} {
+		if data, err := os.ReadFile(filepath.Join(dir, rel)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", rel, data, err, want)
+		}
+	}
+}
+
+func TestValidateSkillFrontmatter(t *testing.T) {
+	model := scaffoldModel(filepath.Join(t.TempDir(), "plugin"))
+	model.Skills = []PluginSkillModel{
+		{Name: stringValue("inline"), SourceDir: types.StringNull(), SourceBundle: types.StringNull(), Content: stringValue("Hi."), Description: stringValue("ok"), License: stringValue("MIT")},
+		{Name: stringValue("plain"), SourceDir: types.StringNull(), SourceBundle: types.StringNull(), Content: stringValue("---\nname: plain\n---\nHi.")},
+		{
+			Name:         stringValue("copied"),
+			SourceDir:    stringValue("skills/copied"),
+			SourceBundle: types.StringNull(),
+			Content:      types.StringNull(),
+			Description:  stringValue("not ok"),
+			License:      stringValue("MIT"),
+		},
+		{Name: stringValue("undescribed"), SourceDir: types.StringNull(), SourceBundle: types.StringNull(), Content: stringValue("Hi."), License: stringValue("MIT")},
+		{Name: stringValue("doubled"), SourceDir: types.StringNull(), SourceBundle: types.StringNull(), Content: stringValue("---\nname: doubled\n---\nHi."), Description: stringValue("ok")},
+	}
+
+	diags := validateFrontmatter(context.Background(), model)
+	if diags.ErrorsCount() != 4 {
+		t.Fatalf("got %d errors, want two for copied and one each for undescribed and doubled: %v", diags.ErrorsCount(), diags)
+	}
+}
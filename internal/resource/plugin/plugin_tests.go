@@ -0,0 +1,197 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// testScriptPath is where generate_tests writes the plugin validation
+// script, relative to the output directory.
+const testScriptPath = "tests/validate_plugin.py"
+
+// writeTestScaffold writes the validation script into absDir/tests. The
+// script re-checks the generated artifact on its own, so teams that consume
+// the plugin outside Terraform can run it in CI after copying or editing the
+// plugin. verifyRefs mirrors verify_script_references.
+func writeTestScaffold(absDir string, verifyRefs bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	script := validatePluginScript
+	if !verifyRefs {
+		script = strings.Replace(script, "CHECK_PLUGIN_ROOT_REFS = True", "CHECK_PLUGIN_ROOT_REFS = False", 1)
+	}
+
+	dest := filepath.Join(absDir, filepath.FromSlash(testScriptPath))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		diags.AddAttributeError(path.Root("generate_tests"), errcode.FileWrite.Summary("Directory Create Failed"), fmt.Sprintf("Failed to create tests directory: %s", err))
+		return diags
+	}
+	if err := atomicfile.WriteFile(dest, []byte(script), 0o755); err != nil {
+		diags.AddAttributeError(path.Root("generate_tests"), errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write %s: %s", testScriptPath, err))
+	}
+	return diags
+}
+
+// validatePluginScript is the script written by generate_tests. It only uses
+// the Python standard library so it runs on stock CI images.
+const validatePluginScript = `#!/usr/bin/env python3
+"""Validate this Claude Code plugin.
+
+Generated by the agentctx Terraform provider (generate_tests = true). Run it
+in CI against the generated plugin, from any working directory:
+
+    python3 tests/validate_plugin.py
+
+It checks that .claude-plugin/plugin.json parses, that every path the
+manifest and its hook, MCP, and LSP configuration reference exists, and that
+every hook matcher compiles as a regular expression. It exits with status 1
+and lists every problem found when a check fails.
+"""
+
+import json
+import os
+import re
+import sys
+
+ROOT = os.path.dirname(os.path.dirname(os.path.abspath(__file__)))
+
+# Matches ${CLAUDE_PLUGIN_ROOT}/<path>, stopping at whitespace, quotes, and
+# shell metacharacters.
+PLUGIN_ROOT_REF = re.compile(r"\$\{CLAUDE_PLUGIN_ROOT\}/([^\s\"'\x60;|&<>()]+)")
+
+# False when the plugin was generated with verify_script_references = false,
+# whose ${CLAUDE_PLUGIN_ROOT} paths may be created at runtime.
+CHECK_PLUGIN_ROOT_REFS = True
+
+problems = []
+
+
+def problem(msg):
+    problems.append(msg)
+
+
+def resolve(rel, where):
+    """Returns the absolute path of rel, or None if it escapes the plugin."""
+    if os.path.isabs(rel) or ".." in rel.replace("\\", "/").split("/"):
+        problem(f"{where}: path {rel!r} must be relative to the plugin root")
+        return None
+    return os.path.join(ROOT, rel)
+
+
+def check_exists(rel, where):
+    path = resolve(rel, where)
+    if path is not None and not os.path.exists(path):
+        problem(f"{where}: {rel} does not exist")
+        return False
+    return path is not None
+
+
+def load_json(rel, where):
+    path = resolve(rel, where)
+    if path is None:
+        return None
+    try:
+        with open(path, encoding="utf-8") as f:
+            return json.load(f)
+    except FileNotFoundError:
+        problem(f"{where}: {rel} does not exist")
+    except (OSError, ValueError) as e:
+        problem(f"{rel}: invalid JSON: {e}")
+    return None
+
+
+def strings(value):
+    if isinstance(value, str):
+        yield value
+    elif isinstance(value, dict):
+        for v in value.values():
+            yield from strings(v)
+    elif isinstance(value, list):
+        for v in value:
+            yield from strings(v)
+
+
+def check_plugin_root_refs(config, where):
+    if not CHECK_PLUGIN_ROOT_REFS:
+        return
+    for s in strings(config):
+        for m in PLUGIN_ROOT_REF.finditer(s):
+            check_exists(m.group(1), where)
+
+
+def check_hooks(config, where):
+    hooks = config.get("hooks") if isinstance(config, dict) else None
+    if not isinstance(hooks, dict):
+        problem(f"{where}: expected an object with a \"hooks\" object")
+        return
+    for event, matchers in hooks.items():
+        if not isinstance(matchers, list):
+            problem(f"{where}: {event} must be a list of matchers")
+            continue
+        for i, entry in enumerate(matchers):
+            matcher = entry.get("matcher", "") if isinstance(entry, dict) else ""
+            # An empty matcher and "*" match every tool.
+            if matcher and matcher != "*":
+                try:
+                    re.compile(matcher)
+                except re.error as e:
+                    problem(f"{where}: {event}[{i}] matcher {matcher!r} does not compile: {e}")
+            for j, hook in enumerate(entry.get("hooks") or [] if isinstance(entry, dict) else []):
+                if hook.get("type") == "command" and not hook.get("command"):
+                    problem(f"{where}: {event}[{i}].hooks[{j}] has no command")
+    check_plugin_root_refs(config, where)
+
+
+def check_config(manifest, key, check):
+    value = manifest.get(key)
+    if value is None:
+        return
+    if isinstance(value, str):
+        config = load_json(value, f"plugin.json {key}")
+        if config is not None:
+            check(config, value)
+    else:
+        check(value, f"plugin.json {key}")
+
+
+def check_plugin():
+    manifest = load_json(".claude-plugin/plugin.json", "manifest")
+    if manifest is None:
+        return
+    if not isinstance(manifest, dict) or not manifest.get("name"):
+        problem("plugin.json: \"name\" is required")
+        manifest = manifest if isinstance(manifest, dict) else {}
+
+    for key in ("outputStyles", "commands", "agents"):
+        for rel in manifest.get(key) or []:
+            check_exists(rel, f"plugin.json {key}")
+    for rel in manifest.get("skills") or []:
+        if check_exists(rel, "plugin.json skills"):
+            check_exists(os.path.join(rel, "SKILL.md"), "plugin.json skills")
+
+    check_config(manifest, "hooks", check_hooks)
+    check_config(manifest, "mcpServers", check_plugin_root_refs)
+    check_config(manifest, "lspServers", check_plugin_root_refs)
+
+
+def main():
+    check_plugin()
+    for p in problems:
+        print(f"FAIL {p}", file=sys.stderr)
+    if problems:
+        return 1
+    print(f"ok   {ROOT}")
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
+`
@@ -0,0 +1,192 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func scaffoldModel(dir string) *PluginResourceModel {
+	return &PluginResourceModel{
+		Name:          stringValue("scaffold"),
+		OutputDir:     stringValue(dir),
+		Keywords:      types.ListNull(types.StringType),
+		GenerateTests: types.BoolValue(true),
+		Agents: []PluginAgentModel{
+			{Name: stringValue("reviewer"), SourceFile: types.StringNull(), Content: stringValue("You review code.\n")},
+		},
+		Skills: []PluginSkillModel{
+			{Name: stringValue("lint"), SourceDir: types.StringNull(), SourceBundle: types.StringNull(), Content: stringValue("# Lint\n")},
+		},
+		Hooks: []PluginHooksModel{
+			{
+				PostToolUse: []PluginHookMatcherModel{
+					{
+						Matcher: stringValue("Write|Edit"),
+						Hooks: []PluginHookEntryModel{
+							{Type: stringValue("command"), Command: stringValue("${CLAUDE_PLUGIN_ROOT}/scripts/format.sh")},
+						},
+					},
+				},
+			},
+		},
+		Files: []PluginFileModel{
+			{Path: stringValue("scripts/format.sh"), Content: stringValue("#!/bin/sh\n"), SourceFile: types.StringNull(), Executable: types.BoolValue(true)},
+		},
+	}
+}
+
+// runValidateScript runs the generated script and returns its combined
+// output and whether it succeeded. It skips the test without python3.
+func runValidateScript(t *testing.T, dir string) (string, bool) {
+	t.Helper()
+
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not installed")
+	}
+	// Run from elsewhere to check the script locates the plugin itself.
+	cmd := exec.Command(python, filepath.Join(dir, filepath.FromSlash(testScriptPath)))
+	cmd.Dir = t.TempDir()
+	out, err := cmd.CombinedOutput()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		t.Fatalf("run script: %v", err)
+	}
+	return string(out), err == nil
+}
+
+func TestWritePlugin_GenerateTests(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), scaffoldModel(dir)); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "tests", "validate_plugin.py"))
+	if err != nil {
+		t.Fatalf("script not written: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("script mode = %04o, want executable", info.Mode().Perm())
+	}
+
+	if out, ok := runValidateScript(t, dir); !ok {
+		t.Fatalf("script failed on a valid plugin:\n%s", out)
+	}
+
+	// Break the plugin the way hand edits outside Terraform would.
+	if err := os.Remove(filepath.Join(dir, "agents", "reviewer.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "scripts", "format.sh")); err != nil {
+		t.Fatal(err)
+	}
+	hooksPath := filepath.Join(dir, "hooks", "hooks.json")
+	data, err := os.ReadFile(hooksPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hooksPath, []byte(strings.Replace(string(data), "Write|Edit", "Write(", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, ok := runValidateScript(t, dir)
+	if ok {
+		t.Fatalf("script passed on a broken plugin:\n%s", out)
+	}
+	for _, want := range []string{"agents/reviewer.md does not exist", "scripts/format.sh does not exist", "'Write(' does not compile"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not mention %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWritePlugin_GenerateTestsInvalidManifest(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), scaffoldModel(dir)); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".claude-plugin", "plugin.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, ok := runValidateScript(t, dir)
+	if ok || !strings.Contains(out, "invalid JSON") {
+		t.Errorf("script output = %q, want an invalid JSON failure", out)
+	}
+}
+
+func TestWritePlugin_GenerateTestsHonorsVerifyScriptReferences(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	model.VerifyScriptReferences = types.BoolValue(false)
+
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "tests", "validate_plugin.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "CHECK_PLUGIN_ROOT_REFS = False") {
+		t.Error("script still checks ${CLAUDE_PLUGIN_ROOT} references")
+	}
+}
+
+func TestWritePlugin_GenerateTestsDisabledRemovesScript(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	r := &PluginResource{}
+	if diags := r.writePlugin(context.Background(), scaffoldModel(dir)); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	model := scaffoldModel(dir)
+	model.GenerateTests = types.BoolValue(false)
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tests")); !os.IsNotExist(err) {
+		t.Errorf("tests directory left behind: %v", err)
+	}
+
+	// A tests/ directory that still holds user files is kept.
+	model.Files = append(model.Files, PluginFileModel{
+		Path: stringValue("tests/smoke.sh"), Content: stringValue("#!/bin/sh\n"), SourceFile: types.StringNull(), Executable: types.BoolValue(true),
+	})
+	model.GenerateTests = types.BoolValue(true)
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	model.GenerateTests = types.BoolValue(false)
+	if diags := r.writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tests", "smoke.sh")); err != nil {
+		t.Errorf("user file removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tests", "validate_plugin.py")); !os.IsNotExist(err) {
+		t.Errorf("script left behind: %v", err)
+	}
+}
+
+func TestWritePlugin_GenerateTestsConflictsWithFileBlock(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	model.Files = append(model.Files, PluginFileModel{
+		Path: stringValue("tests/validate_plugin.py"), Content: stringValue("print('mine')\n"), SourceFile: types.StringNull(), Executable: types.BoolValue(false),
+	})
+
+	r := &PluginResource{}
+	diags := r.writePlugin(context.Background(), model)
+	if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), "generate_tests") {
+		t.Fatalf("expected a generate_tests conflict, got %v", diags)
+	}
+}
@@ -0,0 +1,36 @@
+package plugin
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
+
+// ValidateURLs checks the URL attributes that end up in the generated
+// manifests, homepage, repository, author.url, and mcp_server.url, with
+// validators.CheckURL against schemes, the provider's allowed_url_schemes.
+// Unknown values are skipped.
+func ValidateURLs(model *PluginResourceModel, schemes []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	check := func(p path.Path, v types.String) {
+		if v.IsNull() || v.IsUnknown() {
+			return
+		}
+		if err := validators.CheckURL(v.ValueString(), schemes); err != nil {
+			diags.AddAttributeError(p, errcode.InvalidConfig.Summary("Invalid URL"), err.Error())
+		}
+	}
+
+	check(path.Root("homepage"), model.Homepage)
+	check(path.Root("repository"), model.Repository)
+	for i, a := range model.Author {
+		check(path.Root("author").AtListIndex(i).AtName("url"), a.URL)
+	}
+	for i, s := range model.McpServers {
+		check(path.Root("mcp_server").AtListIndex(i).AtName("url"), s.URL)
+	}
+	return diags
+}
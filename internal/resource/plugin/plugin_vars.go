@@ -0,0 +1,164 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"gopkg.in/yaml.v3"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// pluginVars holds the variables of vars_file that the content of skill,
+// agent, command, and file blocks is rendered with. A nil *pluginVars
+// renders nothing, so plugins without vars_file are written verbatim.
+type pluginVars struct {
+	values map[string]interface{}
+	// hash is the SHA-256 of the variables file, prefixed with sha256:,
+	// recorded as vars_hash so a changed file plans an update.
+	hash string
+}
+
+// loadVars reads the variables file named by vars_file: a YAML (.yaml or
+// .yml) or JSON (.json) document whose top level is an object. It returns
+// nil for a null or unknown vars_file.
+func loadVars(model *PluginResourceModel) (*pluginVars, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if !hasNonEmptyString(model.VarsFile) {
+		return nil, diags
+	}
+	file := model.VarsFile.ValueString()
+
+	data, err := os.ReadFile(longpath.Path(file))
+	if err != nil {
+		diags.AddAttributeError(path.Root("vars_file"), errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read variables file %q: %s", file, err))
+		return nil, diags
+	}
+
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		diags.AddAttributeError(path.Root("vars_file"), errcode.InvalidConfig.Summary("Invalid Variables File"),
+			fmt.Sprintf("Variables file %q must end in .yaml, .yml, or .json.", file))
+		return nil, diags
+	}
+	if err != nil {
+		diags.AddAttributeError(path.Root("vars_file"), errcode.InvalidConfig.Summary("Invalid Variables File"),
+			fmt.Sprintf("Variables file %q must contain an object of variables: %s", file, err))
+		return nil, diags
+	}
+
+	return &pluginVars{values: values, hash: fmt.Sprintf("sha256:%x", sha256.Sum256(data))}, diags
+}
+
+// render executes content, named name in errors, as a Go template with the
+// variables as its data. A reference to a variable the file does not
+// define is an error rather than an empty string.
+func (v *pluginVars) render(name, content string) (string, error) {
+	if v == nil {
+		return content, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, v.values); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// writeFile writes content, rendered with the variables, to dst.
+func (v *pluginVars) writeFile(name, content, dst string, perm os.FileMode) error {
+	rendered, err := v.render(name, content)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(dst, []byte(rendered), perm)
+}
+
+// copyFile copies src to dst as the package-level copyFile does, rendering
+// its content with the variables when there are any.
+func (v *pluginVars) copyFile(src, dst string) diag.Diagnostics {
+	if v == nil {
+		return copyFile(src, dst)
+	}
+	var diags diag.Diagnostics
+	data, err := os.ReadFile(longpath.Path(src))
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read source file %q: %s", src, err))
+		return diags
+	}
+	if err := v.writeFile(filepath.Base(src), string(data), longpath.Path(dst), 0o644); err != nil {
+		diags.AddError(errcode.InvalidConfig.Summary("Template Rendering Failed"), fmt.Sprintf("Failed to render %q with vars_file: %s", src, err))
+	}
+	return diags
+}
+
+// validateVars renders the content of every skill, agent, command, and file
+// block that is already known with the variables of vars_file, so that a
+// reference to a missing variable or a template syntax error fails the
+// plan. source_file content is only checked when the file exists yet.
+func validateVars(model *PluginResourceModel) diag.Diagnostics {
+	vars, diags := loadVars(model)
+	if vars == nil {
+		return diags
+	}
+
+	check := func(p path.Path, name, content string) {
+		if _, err := vars.render(name, content); err != nil {
+			diags.AddAttributeError(p, errcode.InvalidConfig.Summary("Template Rendering Failed"),
+				fmt.Sprintf("Failed to render %s with vars_file %q: %s", name, model.VarsFile.ValueString(), err))
+		}
+	}
+	checkSource := func(p path.Path, src string) {
+		if data, err := os.ReadFile(longpath.Path(src)); err == nil {
+			check(p, filepath.Base(src), string(data))
+		}
+	}
+
+	for i, s := range model.Skills {
+		if hasNonEmptyString(s.Content) {
+			check(path.Root("skill").AtListIndex(i).AtName("content"), "skill "+s.Name.ValueString(), s.Content.ValueString())
+		}
+	}
+	for i, a := range model.Agents {
+		p := path.Root("agent").AtListIndex(i)
+		switch {
+		case hasNonEmptyString(a.Content):
+			check(p.AtName("content"), "agent "+a.Name.ValueString(), a.Content.ValueString())
+		case hasNonEmptyString(a.SourceFile):
+			checkSource(p.AtName("source_file"), a.SourceFile.ValueString())
+		}
+	}
+	for i, c := range model.Commands {
+		p := path.Root("command").AtListIndex(i)
+		switch {
+		case hasNonEmptyString(c.Content):
+			check(p.AtName("content"), "command "+c.Name.ValueString(), c.Content.ValueString())
+		case hasNonEmptyString(c.SourceFile):
+			checkSource(p.AtName("source_file"), c.SourceFile.ValueString())
+		}
+	}
+	for i, f := range model.Files {
+		if hasNonEmptyString(f.Content) {
+			check(path.Root("file").AtListIndex(i).AtName("content"), "file "+f.Path.ValueString(), f.Content.ValueString())
+		}
+	}
+	return diags
+}
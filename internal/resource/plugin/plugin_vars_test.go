@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func writeVarsFile(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadVars(t *testing.T) {
+	for name, content := range map[string]string{
+		"prod.yaml": "env: prod\napi:\n  url: https://api.example.com\n",
+		"prod.yml":  "env: prod\napi:\n  url: https://api.example.com\n",
+		"prod.json": `{"env": "prod", "api": {"url": "https://api.example.com"}}`,
+	} {
+		model := &PluginResourceModel{VarsFile: stringValue(writeVarsFile(t, name, content))}
+		vars, diags := loadVars(model)
+		if diags.HasError() {
+			t.Fatalf("%s: unexpected errors: %v", name, diags)
+		}
+		got, err := vars.render("test", "{{ .env }} at {{ .api.url }}")
+		if err != nil || got != "prod at https://api.example.com" {
+			t.Errorf("%s: render = %q, %v", name, got, err)
+		}
+		if !strings.HasPrefix(vars.hash, "sha256:") {
+			t.Errorf("%s: hash = %q", name, vars.hash)
+		}
+	}
+
+	for name, content := range map[string]string{
+		"vars.toml":  "env = \"prod\"\n",
+		"list.yaml":  "- prod\n",
+		"bad.json":   "{",
+		"scalar.yml": "prod\n",
+	} {
+		model := &PluginResourceModel{VarsFile: stringValue(writeVarsFile(t, name, content))}
+		if _, diags := loadVars(model); !diags.HasError() {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if vars, diags := loadVars(&PluginResourceModel{VarsFile: types.StringNull()}); vars != nil || diags.HasError() {
+		t.Errorf("null vars_file: vars = %v, diags = %v", vars, diags)
+	}
+}
+
+func TestWritePlugin_RendersVars(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "plugin")
+	source := filepath.Join(t.TempDir(), "deploy.md")
+	if err := os.WriteFile(source, []byte("Deploy to {{ .env }}.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	model := scaffoldModel(dir)
+	model.VarsFile = stringValue(writeVarsFile(t, "stage.yaml", "env: stage\n"))
+	model.Agents[0].Content = stringValue("You review {{ .env }} code.\n")
+	model.Commands = []PluginCommandModel{{Name: stringValue("deploy"), SourceFile: stringValue(source), Content: types.StringNull()}}
+
+	if diags := validateVars(model); diags.HasError() {
+		t.Fatalf("validateVars: %v", diags)
+	}
+	r := &PluginResource{}
+	if diags := r.writePlugin(ctx, model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+
+	for rel, want := range map[string]string{
+		"agents/reviewer.md": "You review stage code.\n",
+		"commands/deploy.md": "Deploy to stage.\n",
+	} {
+		if data, err := os.ReadFile(filepath.Join(dir, rel)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", rel, data, err, want)
+		}
+	}
+	if !strings.HasPrefix(model.VarsHash.ValueString(), "sha256:") {
+		t.Errorf("vars_hash = %v", model.VarsHash)
+	}
+}
+
+func TestValidateVars_MissingVariable(t *testing.T) {
+	model := scaffoldModel(filepath.Join(t.TempDir(), "plugin"))
+	model.VarsFile = stringValue(writeVarsFile(t, "dev.json", `{"env": "dev"}`))
+	model.Skills[0].Content = stringValue("# Lint for {{ .team }}\n")
+
+	diags := validateVars(model)
+	if diags.ErrorsCount() != 1 {
+		t.Fatalf("got %d errors, want 1: %v", diags.ErrorsCount(), diags)
+	}
+	if detail := diags.Errors()[0].Detail(); !strings.Contains(detail, "team") {
+		t.Errorf("error %q does not name the missing variable", detail)
+	}
+}
+
+func TestWritePlugin_WithoutVarsFileKeepsBraces(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugin")
+	model := scaffoldModel(dir)
+	model.Agents[0].Content = stringValue("Write {{ .Name }} literally.\n")
+
+	if diags := (&PluginResource{}).writePlugin(context.Background(), model); diags.HasError() {
+		t.Fatalf("unexpected errors: %v", diags.Errors())
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "agents", "reviewer.md")); string(data) != "Write {{ .Name }} literally.\n" {
+		t.Errorf("agent = %q, want the content unchanged", data)
+	}
+	if !model.VarsHash.IsNull() {
+		t.Errorf("vars_hash = %v, want null", model.VarsHash)
+	}
+}
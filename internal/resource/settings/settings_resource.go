@@ -0,0 +1,470 @@
+package settings
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/jsonmerge"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource                   = &SettingsResource{}
+	_ resource.ResourceWithConfigure      = &SettingsResource{}
+	_ resource.ResourceWithValidateConfig = &SettingsResource{}
+)
+
+// NewSettingsResource returns a new resource.Resource for the
+// agentctx_settings type.
+func NewSettingsResource() resource.Resource {
+	return &SettingsResource{}
+}
+
+// SettingsResource implements the agentctx_settings Terraform resource. It
+// manages a subset of the keys in a Claude Code settings.json file and
+// three-way merges them with the file on disk, so keys written by Claude
+// Code or by hand are preserved across applies.
+type SettingsResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *SettingsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_settings"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *SettingsResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages keys in a Claude Code `settings.json` file. Desired settings are three-way merged with the file on disk (last applied vs. current file vs. desired), so keys Terraform does not manage are preserved instead of being clobbered.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Path of the settings file, e.g. `.claude/settings.json` or `.claude/settings.local.json`. The file and its parent directories are created if they do not exist.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"settings_json": schema.StringAttribute{
+				MarkdownDescription: "JSON object of the settings Terraform manages, typically built with `jsonencode()`. Nested objects are merged key by key; arrays and scalar values are replaced wholesale.",
+				Required:            true,
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Absolute path of the settings file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_applied_json": schema.StringAttribute{
+				MarkdownDescription: "Normalized JSON of the settings written by the last apply. Used as the merge base to tell keys removed from `settings_json` apart from keys Terraform never managed.",
+				Computed:            true,
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "Full content of the settings file, including keys Terraform does not manage. Null when the provider's `state_content` is `\"hashes\"`.",
+				Computed:            true,
+			},
+			"content_hash": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 hash of the settings file content.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// ValidateConfig
+// --------------------------------------------------------------------------
+
+// ValidateConfig checks that settings_json is a JSON object.
+func (r *SettingsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var settingsJSON types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("settings_json"), &settingsJSON)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if settingsJSON.IsNull() || settingsJSON.IsUnknown() {
+		return
+	}
+	if _, err := jsonmerge.DecodeObject([]byte(settingsJSON.ValueString())); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("settings_json"),
+			errcode.InvalidJSON.Summary("Invalid Settings JSON"),
+			fmt.Sprintf("settings_json must be a JSON object: %s", err),
+		)
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *SettingsResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *SettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_settings", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan SettingsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRun(&plan, dryrun.OpCreate, nil)...)
+		return
+	}
+
+	if err := r.apply(ctx, &plan, nil); err != nil {
+		resp.Diagnostics.AddError(errcode.FileWrite.Summary("Settings Write Failed"), fmt.Sprintf("Failed to write settings file: %s", err))
+		return
+	}
+
+	tflog.Info(ctx, "created settings file", map[string]interface{}{
+		"file_path": plan.ID.ValueString(),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *SettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state SettingsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.ID.ValueString()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_settings", filePath, fmt.Sprintf("file %q was deleted", filePath))...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			tflog.Info(ctx, "settings file not found on disk, removing from state", map[string]interface{}{
+				"file_path": filePath,
+			})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(errcode.FileRead.Summary("Settings Read Failed"), fmt.Sprintf("Failed to read settings file %q: %s", filePath, err))
+		return
+	}
+
+	current, err := jsonmerge.DecodeObject(data)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidJSON.Summary("Invalid Settings File"), fmt.Sprintf("Settings file %q is not a JSON object: %s", filePath, err))
+		return
+	}
+
+	lastApplied, err := decodeOptional(state.LastAppliedJSON)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidState.Summary("Invalid State"), fmt.Sprintf("Failed to parse last_applied_json: %s", err))
+		return
+	}
+
+	// Drift is only reported for the keys Terraform wrote. When they still
+	// match, settings_json keeps its configured formatting so the plan stays
+	// empty.
+	managed := jsonmerge.Project(current, lastApplied)
+	if !jsonmerge.Equal(managed, lastApplied) {
+		encoded, err := jsonmerge.Encode(managed)
+		if err != nil {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("Settings Read Failed"), fmt.Sprintf("Failed to encode managed settings: %s", err))
+			return
+		}
+		tflog.Info(ctx, "managed settings drifted", map[string]interface{}{
+			"file_path": filePath,
+		})
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_settings", filePath, fmt.Sprintf("the managed settings in %q changed", filePath))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.SettingsJSON = types.StringValue(string(encoded))
+	}
+
+	state.Content = r.providerData.Content(string(data))
+	state.ContentHash = types.StringValue(computeHash(data))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *SettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_settings", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state SettingsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	lastApplied, err := decodeOptional(state.LastAppliedJSON)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidState.Summary("Invalid State"), fmt.Sprintf("Failed to parse last_applied_json: %s", err))
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRun(&plan, dryrun.OpUpdate, lastApplied)...)
+		return
+	}
+
+	if err := r.apply(ctx, &plan, lastApplied); err != nil {
+		resp.Diagnostics.AddError(errcode.FileWrite.Summary("Settings Write Failed"), fmt.Sprintf("Failed to write settings file: %s", err))
+		return
+	}
+
+	tflog.Info(ctx, "updated settings file", map[string]interface{}{
+		"file_path": plan.ID.ValueString(),
+	})
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+// Delete removes only the keys Terraform wrote. The file itself is removed
+// when nothing else is left in it.
+func (r *SettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_settings", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state SettingsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filePath := state.ID.ValueString()
+
+	lastApplied, err := decodeOptional(state.LastAppliedJSON)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidState.Summary("Invalid State"), fmt.Sprintf("Failed to parse last_applied_json: %s", err))
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		files, err := dryRunRemove(filePath, lastApplied)
+		if err != nil {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("Settings Read Failed"), fmt.Sprintf("Failed to read settings file %q: %s", filePath, err))
+			return
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_settings",
+			Operation: dryrun.OpDelete,
+			ID:        filePath,
+			Files:     files,
+		})...)
+		return
+	}
+
+	remaining, err := removeManaged(filePath, lastApplied)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.FileDelete.Summary("Settings Delete Failed"), fmt.Sprintf("Failed to remove managed settings: %s", err))
+		return
+	}
+
+	tflog.Info(ctx, "removed managed settings", map[string]interface{}{
+		"file_path":      filePath,
+		"remaining_keys": remaining,
+	})
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// dryRun records the change Create or Update would make to the settings
+// file in the dry-run report.
+func (r *SettingsResource) dryRun(model *SettingsResourceModel, operation string, lastApplied map[string]any) diag.Diagnostics {
+	var diags diag.Diagnostics
+	change, err := dryRunApply(model, lastApplied)
+	if err != nil {
+		diags.AddError(errcode.FileRead.Summary("Settings Read Failed"), fmt.Sprintf("Failed to compute the settings file: %s", err))
+		return diags
+	}
+	return r.providerData.RecordDryRun(dryrun.Change{
+		Resource:  "agentctx_settings",
+		Operation: operation,
+		ID:        change.Path,
+		Files:     []dryrun.FileChange{change},
+	})
+}
+
+// merge returns the absolute path of the settings file of model, its desired
+// settings, and the document that merging them into the file on disk, with
+// lastApplied as the merge base, yields.
+func merge(model *SettingsResourceModel, lastApplied map[string]any) (string, map[string]any, map[string]any, error) {
+	desired, err := jsonmerge.DecodeObject([]byte(model.SettingsJSON.ValueString()))
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("parsing settings_json: %w", err)
+	}
+
+	absPath, err := filepath.Abs(model.Path.ValueString())
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("resolving absolute path for %q: %w", model.Path.ValueString(), err)
+	}
+
+	current, _, err := jsonmerge.ReadObjectFile(absPath)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return absPath, desired, jsonmerge.ThreeWay(lastApplied, current, desired), nil
+}
+
+// apply merges the desired settings in model into the file on disk, using
+// lastApplied as the merge base, and fills in the computed attributes.
+func (r *SettingsResource) apply(_ context.Context, model *SettingsResourceModel, lastApplied map[string]any) error {
+	absPath, desired, merged, err := merge(model, lastApplied)
+	if err != nil {
+		return err
+	}
+
+	content, err := jsonmerge.WriteObjectFile(absPath, merged)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := jsonmerge.Encode(desired)
+	if err != nil {
+		return fmt.Errorf("encoding settings_json: %w", err)
+	}
+
+	model.ID = types.StringValue(absPath)
+	model.LastAppliedJSON = types.StringValue(string(normalized))
+	model.Content = r.providerData.Content(string(content))
+	model.ContentHash = types.StringValue(computeHash(content))
+	return nil
+}
+
+// dryRunApply returns the change apply would make to the settings file.
+func dryRunApply(model *SettingsResourceModel, lastApplied map[string]any) (dryrun.FileChange, error) {
+	absPath, _, merged, err := merge(model, lastApplied)
+	if err != nil {
+		return dryrun.FileChange{}, err
+	}
+	content, err := jsonmerge.Encode(merged)
+	if err != nil {
+		return dryrun.FileChange{}, fmt.Errorf("encoding %q: %w", absPath, err)
+	}
+	return dryrun.WriteFile(absPath, content), nil
+}
+
+// dryRunRemove returns the changes removeManaged would make to the settings
+// file: none when it does not exist.
+func dryRunRemove(filePath string, lastApplied map[string]any) ([]dryrun.FileChange, error) {
+	current, exists, err := jsonmerge.ReadObjectFile(filePath)
+	if err != nil || !exists {
+		return nil, err
+	}
+	remaining := jsonmerge.ThreeWay(lastApplied, current, nil)
+	if len(remaining) == 0 {
+		return []dryrun.FileChange{dryrun.RemoveFile(filePath)}, nil
+	}
+	content, err := jsonmerge.Encode(remaining)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %q: %w", filePath, err)
+	}
+	return []dryrun.FileChange{dryrun.WriteFile(filePath, content)}, nil
+}
+
+// removeManaged deletes the keys recorded in lastApplied from the settings
+// file, removing the file entirely when no other keys remain. It returns the
+// number of top-level keys left behind.
+func removeManaged(filePath string, lastApplied map[string]any) (int, error) {
+	current, exists, err := jsonmerge.ReadObjectFile(filePath)
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	remaining := jsonmerge.ThreeWay(lastApplied, current, nil)
+	if len(remaining) == 0 {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf("deleting settings file %q: %w", filePath, err)
+		}
+		return 0, nil
+	}
+	if _, err := jsonmerge.WriteObjectFile(filePath, remaining); err != nil {
+		return 0, err
+	}
+	return len(remaining), nil
+}
+
+// decodeOptional parses a JSON object stored in state, treating null and
+// empty values as an empty object.
+func decodeOptional(v types.String) (map[string]any, error) {
+	if v.IsNull() || v.IsUnknown() || v.ValueString() == "" {
+		return map[string]any{}, nil
+	}
+	return jsonmerge.DecodeObject([]byte(v.ValueString()))
+}
+
+func computeHash(content []byte) string {
+	h := sha256.Sum256(content)
+	return fmt.Sprintf("sha256:%x", h)
+}
@@ -0,0 +1,17 @@
+package settings
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// SettingsResourceModel maps the agentctx_settings resource schema to a Go
+// struct.
+type SettingsResourceModel struct {
+	// Required
+	Path         types.String `tfsdk:"path"`
+	SettingsJSON types.String `tfsdk:"settings_json"`
+
+	// Computed
+	ID              types.String `tfsdk:"id"`
+	LastAppliedJSON types.String `tfsdk:"last_applied_json"`
+	Content         types.String `tfsdk:"content"`
+	ContentHash     types.String `tfsdk:"content_hash"`
+}
@@ -0,0 +1,143 @@
+package settings
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/jsonmerge"
+)
+
+func readJSON(t *testing.T, filePath string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := jsonmerge.DecodeObject(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func decode(t *testing.T, s string) map[string]any {
+	t.Helper()
+	v, err := jsonmerge.DecodeObject([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestApply_PreservesUnmanagedKeys(t *testing.T) {
+	ctx := context.Background()
+	r := &SettingsResource{}
+	filePath := filepath.Join(t.TempDir(), ".claude", "settings.json")
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte(`{"theme": "dark", "permissions": {"deny": ["Bash(rm:*)"]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// First apply: no merge base yet.
+	model := &SettingsResourceModel{
+		Path:         types.StringValue(filePath),
+		SettingsJSON: types.StringValue(`{"model":"opus","cleanupPeriodDays":30,"permissions":{"allow":["Read"]}}`),
+	}
+	if err := r.apply(ctx, model, nil); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	want := decode(t, `{"theme": "dark", "model": "opus", "cleanupPeriodDays": 30, "permissions": {"allow": ["Read"], "deny": ["Bash(rm:*)"]}}`)
+	if got := readJSON(t, filePath); !jsonmerge.Equal(got, want) {
+		t.Errorf("after create: got %v, want %v", got, want)
+	}
+	if model.ID.ValueString() != filePath {
+		t.Errorf("id = %q, want %q", model.ID.ValueString(), filePath)
+	}
+
+	// Claude Code writes a key of its own between applies.
+	current := readJSON(t, filePath)
+	current["feedbackSurveyState"] = map[string]any{"lastShownTime": float64(1)}
+	data, _ := jsonmerge.Encode(current)
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Second apply drops cleanupPeriodDays from the configuration.
+	lastApplied := decode(t, model.LastAppliedJSON.ValueString())
+	model.SettingsJSON = types.StringValue(`{"model":"opus","permissions":{"allow":["Read","Grep"]}}`)
+	if err := r.apply(ctx, model, lastApplied); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	want = decode(t, `{"theme": "dark", "model": "opus", "feedbackSurveyState": {"lastShownTime": 1}, "permissions": {"allow": ["Read", "Grep"], "deny": ["Bash(rm:*)"]}}`)
+	if got := readJSON(t, filePath); !jsonmerge.Equal(got, want) {
+		t.Errorf("after update: got %v, want %v", got, want)
+	}
+	if model.Content.ValueString() != string(mustEncode(t, want)) {
+		t.Errorf("content does not match file: %s", model.Content.ValueString())
+	}
+}
+
+func TestApply_RejectsNonObjectFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(filePath, []byte(`[1, 2]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	model := &SettingsResourceModel{
+		Path:         types.StringValue(filePath),
+		SettingsJSON: types.StringValue(`{"model":"opus"}`),
+	}
+	if err := (&SettingsResource{}).apply(context.Background(), model, nil); err == nil {
+		t.Fatal("expected error for non-object settings file")
+	}
+}
+
+func TestRemoveManaged(t *testing.T) {
+	dir := t.TempDir()
+	lastApplied := decode(t, `{"model": "opus", "env": {"FOO": "1"}}`)
+
+	shared := filepath.Join(dir, "shared.json")
+	if err := os.WriteFile(shared, []byte(`{"model": "opus", "env": {"FOO": "1", "BAR": "2"}, "theme": "dark"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	remaining, err := removeManaged(shared, lastApplied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 2 {
+		t.Errorf("remaining = %d, want 2", remaining)
+	}
+	if got, want := readJSON(t, shared), decode(t, `{"env": {"BAR": "2"}, "theme": "dark"}`); !jsonmerge.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	owned := filepath.Join(dir, "owned.json")
+	if err := os.WriteFile(owned, []byte(`{"model": "opus", "env": {"FOO": "1"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := removeManaged(owned, lastApplied); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(owned); !os.IsNotExist(err) {
+		t.Error("expected file with only managed keys to be removed")
+	}
+
+	if _, err := removeManaged(filepath.Join(dir, "missing.json"), lastApplied); err != nil {
+		t.Errorf("missing file: %v", err)
+	}
+}
+
+func mustEncode(t *testing.T, v map[string]any) []byte {
+	t.Helper()
+	data, err := jsonmerge.Encode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
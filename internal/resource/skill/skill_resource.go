@@ -2,10 +2,16 @@ package skill
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -14,15 +20,23 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
 	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/contentscan"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
 	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
 	"github.com/agentctx/terraform-provider-agentctx/internal/manifest"
+	"github.com/agentctx/terraform-provider-agentctx/internal/provenance"
 	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
 )
 
 // Compile-time interface checks.
@@ -54,6 +68,7 @@ func registryStateAttrTypes() map[string]attr.Type {
 		"skill_id":         types.StringType,
 		"deployed_version": types.StringType,
 		"latest_version":   types.StringType,
+		"managed_versions": types.ListType{ElemType: types.StringType},
 	}
 }
 
@@ -62,10 +77,14 @@ func registryStateAttrTypes() map[string]attr.Type {
 func targetStateAttrTypes() map[string]attr.Type {
 	return map[string]attr.Type{
 		"active_deployment_id": types.StringType,
+		"stable_deployment_id": types.StringType,
 		"staged_deployment_id": types.StringType,
 		"deployed_bundle_hash": types.StringType,
 		"last_synced_at":       types.StringType,
 		"managed_deploy_ids":   types.ListType{ElemType: types.StringType},
+		"active_etag":          types.StringType,
+		"active_generation":    types.Int64Type,
+		"stale":                types.BoolType,
 	}
 }
 
@@ -88,10 +107,22 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 		MarkdownDescription: "Manages a skill bundle deployed to one or more cloud object storage targets.",
 
 		Attributes: map[string]schema.Attribute{
-			// ---- Required ----
+			// ---- Source (exactly one) ----
 			"source_dir": schema.StringAttribute{
-				MarkdownDescription: "Path to the local directory containing the skill source files.",
-				Required:            true,
+				MarkdownDescription: "Path to the local directory containing the skill source files. Exactly one of `source_dir`, `source_archive`, `source_url`, and a `source_git` block is required.",
+				Optional:            true,
+			},
+			"source_archive": schema.StringAttribute{
+				MarkdownDescription: "Path to a local `.zip`, `.tar`, `.tar.gz` or `.tgz` archive of the skill source files, such as one built by another tool. It is extracted to a temporary directory at plan and apply time; a single top-level directory shared by every entry is stripped. The skill name is the archive's base name without its extension. Entries outside the archive root, symlinks and other special files are rejected.",
+				Optional:            true,
+			},
+			"source_url": schema.StringAttribute{
+				MarkdownDescription: "HTTP(S) URL of a `.zip`, `.tar`, `.tar.gz` or `.tgz` archive of the skill source files, downloaded and extracted like `source_archive` at apply time. Requires `source_checksum`. The skill name is the archive's base name in the URL path, without its extension.",
+				Optional:            true,
+			},
+			"source_checksum": schema.StringAttribute{
+				MarkdownDescription: "SHA-256 of the `source_url` archive, as `sha256:<hex>`. The apply fails if the download does not match, and changing it is how a new archive is rolled out.",
+				Optional:            true,
 			},
 
 			// ---- Optional ----
@@ -121,6 +152,27 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:            true,
 				Default:             int64default.StaticInt64(5),
 			},
+			"cleanup_orphaned_deployments": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, each deploy first deletes deployments under the skill's prefix that neither the ACTIVE pointer nor `managed_deploy_ids` refers to and that are older than `orphan_grace_period_seconds`, such as partial uploads of interrupted runs. Do not enable it when other Terraform configurations or tools deploy the same skill name to the same target. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"orphan_grace_period_seconds": schema.Int64Attribute{
+				MarkdownDescription: "Minimum age, taken from the timestamp in the deployment ID, of a deployment removed by `cleanup_orphaned_deployments`. Protects deploys still in progress elsewhere. Defaults to `86400` (one day).",
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(86400),
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"adopt_unmanaged_deployments": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, each deploy first adds the deployments under the skill's prefix that `managed_deploy_ids` does not list, such as those written by other tools, to `managed_deploy_ids` once their manifests and files check out, so pruning and destroy manage them from then on. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
 			"allow_external_symlinks": schema.BoolAttribute{
 				MarkdownDescription: "Whether to allow symlinks that resolve outside `source_dir`. Defaults to `false`.",
 				Optional:            true,
@@ -145,17 +197,89 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:            true,
 				Default:             booldefault.StaticBool(false),
 			},
+			"preview_destroy": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, planning the destruction of this resource lists every remote object key and Anthropic registry version that the destroy would remove, as a plan warning. Costs one list per deployment (or prefix) per target at plan time. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"tolerate_unreachable_targets": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, a target that cannot be reached during refresh is marked `stale` in `target_states` with a warning instead of failing the refresh. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
 			"deep_drift_check": schema.BoolAttribute{
 				MarkdownDescription: "When `true`, Read performs per-file hash checks rather than relying solely on the bundle hash. Defaults to `false`.",
 				Optional:            true,
 				Computed:            true,
 				Default:             booldefault.StaticBool(false),
 			},
+			"integrity_check_interval": schema.StringAttribute{
+				MarkdownDescription: "When set, Read downloads every file of the active deployment on each target and compares it with the hash in the manifest, at most once per this interval, as a Go duration such as `\"24h\"`. " +
+					"The time of each check and the files it found modified are recorded in the skill's deployment index, so the interval holds across runs and workspaces. Files that fail the check are reported as drift.",
+				Optional: true,
+			},
 			"tags": schema.MapAttribute{
 				MarkdownDescription: "Arbitrary key-value tags stored in the deployment manifest.",
 				Optional:            true,
 				ElementType:         types.StringType,
 			},
+			"depends_on_skills": schema.ListAttribute{
+				MarkdownDescription: "Names or Anthropic skill IDs of the skills this skill expects to be deployed alongside it, such as skills whose output it reads. " +
+					"Recorded as `depends_on_skills` in each deployment's manifest. The provider does not order deployments by it; " +
+					"`agentctx_catalog` and the `agentctx_skill_dependencies` data source report references to skills that are missing.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(validators.SkillReference()),
+				},
+			},
+			"deployment_alias": schema.StringAttribute{
+				MarkdownDescription: "Human-readable name for the deployments this apply creates, such as a release tag like `v1.4.0`. " +
+					"Recorded as `deployment_alias` in each deployment's manifest and in the skill's deployment index, next to the generated `dep_...` ID. " +
+					"Up to 128 letters, digits, `.`, `_`, `+`, and `-`; must not start with `dep_`. Changing it redeploys the skill.",
+				Optional: true,
+				Validators: []validator.String{
+					validators.DeploymentAlias(),
+				},
+			},
+			"deployment_strategy": schema.StringAttribute{
+				MarkdownDescription: "How an apply releases a new deployment. `\"immediate\"` moves ACTIVE to it on every target. " +
+					"`\"staged\"` only uploads it and records it as `staged_deployment_id` in `target_states`, leaving ACTIVE on the deployment consumers already load; " +
+					"an `agentctx_skill_promotion` resource later moves ACTIVE to it in a single write. Cannot be combined with a `canary` or `verify` block. Defaults to `\"immediate\"`.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(deploymentStrategyImmediate),
+				Validators: []validator.String{
+					stringvalidator.OneOf(deploymentStrategyImmediate, deploymentStrategyStaged),
+				},
+			},
+			"preview_id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of a preview deployment, such as a pull request number. Requires `preview_ttl`. When set, the skill is deployed under `previews/<preview_id>/<skill_name>/` instead of `<skill_name>/`, apart from the skill's regular deployments.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(previewIDPattern, "must be 1-64 letters, digits, '.', '_', or '-'"),
+				},
+			},
+			"preview_ttl": schema.StringAttribute{
+				MarkdownDescription: "How long a preview deployment lives after each apply that deploys it, as a Go duration such as `\"72h\"`. Requires `preview_id`. The expiry is recorded in the manifest; expired previews are deleted by skills with `cleanup_expired_previews` enabled.",
+				Optional:            true,
+			},
+			"cleanup_expired_previews": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, each apply that deploys this skill deletes the previews of the same skill name whose `preview_ttl` has passed, on every target of the resource. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"provenance": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, generates a SLSA provenance statement (an in-toto attestation listing every bundle file with its hash, the git repository and commit of `source_dir`, and the provider version), exposes it as `provenance_json`, and uploads it next to each deployment's manifest as `provenance.intoto.json`. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
 
 			// ---- Computed ----
 			"id": schema.StringAttribute{
@@ -163,8 +287,17 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Computed:            true,
 			},
 			"skill_name": schema.StringAttribute{
-				MarkdownDescription: "Derived skill name (base name of `source_dir`).",
+				MarkdownDescription: "Derived skill name: the base name of `source_dir`, of `source_archive` or `source_url` without its extension, or of the `source_git` `subdir`.",
+				Computed:            true,
+			},
+			"skill_description": schema.StringAttribute{
+				MarkdownDescription: "The `description` field of the `SKILL.md` frontmatter at the root of `source_dir`. Null when the bundle has no `SKILL.md` or its frontmatter has no description.",
+				Computed:            true,
+			},
+			"skill_metadata": schema.MapAttribute{
+				MarkdownDescription: "Every top-level field of the `SKILL.md` frontmatter, `name` and `description` included. Scalars are kept as written; lists and maps are JSON-encoded. Null when the bundle has no `SKILL.md` or it has no frontmatter.",
 				Computed:            true,
+				ElementType:         types.StringType,
 			},
 			"source_hash": schema.StringAttribute{
 				MarkdownDescription: "SHA-256 hash of the source directory structure and metadata.",
@@ -174,6 +307,27 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				MarkdownDescription: "Deterministic SHA-256 hash over all file hashes in the bundle.",
 				Computed:            true,
 			},
+			"bundle_json": schema.StringAttribute{
+				MarkdownDescription: "JSON descriptor of the scanned bundle: the absolute `source_dir`, the `bundle_hash`, and the hash of every file that survived exclusion and symlink validation. Pass it to an `agentctx_plugin` `skill` block's `source_bundle` to copy exactly this file set. Null for sources other than `source_dir`.",
+				Computed:            true,
+			},
+			"provenance_json": schema.StringAttribute{
+				MarkdownDescription: "The in-toto provenance statement uploaded with the deployment when `provenance` is `true`; null otherwise.",
+				Computed:            true,
+			},
+			"registry_skipped": schema.BoolAttribute{
+				MarkdownDescription: "Whether the last apply deployed the skill to storage only because the Anthropic registry was unavailable and the provider's `registry_failure_policy` is `\"warn_and_skip\"`. While `true`, every plan updates the resource to retry the registration.",
+				Computed:            true,
+			},
+			"drift_detected": schema.BoolAttribute{
+				MarkdownDescription: "Whether the last refresh found any target out of sync with this resource: a different bundle hash behind ACTIVE, a missing ACTIVE pointer, or (with `deep_drift_check`) missing files, or (with `integrity_check_interval`) modified files; or a registry version created outside Terraform. Always `false` right after apply.",
+				Computed:            true,
+			},
+			"drift_details": schema.ListAttribute{
+				MarkdownDescription: "Human-readable description of each drift found by the last refresh, one entry per finding, prefixed with the target name, or with `anthropic registry:` for a registry version created outside Terraform. Empty when `drift_detected` is `false`.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
 			"registry_state": schema.SingleNestedAttribute{
 				MarkdownDescription: "State of the skill in the Anthropic registry (populated only when the `anthropic` block is configured).",
 				Computed:            true,
@@ -187,8 +341,13 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 						Computed:            true,
 					},
 					"latest_version": schema.StringAttribute{
-						MarkdownDescription: "Latest available version string.",
+						MarkdownDescription: "Latest version of the skill in the registry, as of the last refresh. Differs from `deployed_version` when a version was created outside Terraform; see `on_version_drift`.",
+						Computed:            true,
+					},
+					"managed_versions": schema.ListAttribute{
+						MarkdownDescription: "Versions created by this resource, oldest first. Destroy deletes only these, unless `destroy_all_versions` is set.",
 						Computed:            true,
+						ElementType:         types.StringType,
 					},
 				},
 			},
@@ -201,8 +360,12 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 							MarkdownDescription: "Deployment ID currently pointed to by the ACTIVE marker.",
 							Computed:            true,
 						},
+						"stable_deployment_id": schema.StringAttribute{
+							MarkdownDescription: "Deployment that receives the traffic the canary does not, while a `canary` block rolls out `active_deployment_id`. Empty when ACTIVE points at a single deployment.",
+							Computed:            true,
+						},
 						"staged_deployment_id": schema.StringAttribute{
-							MarkdownDescription: "Deployment ID staged but not yet promoted to active.",
+							MarkdownDescription: "Deployment left behind by a deploy that failed or was interrupted before ACTIVE was moved to it, or, with `deployment_strategy = \"staged\"`, the deployment the last apply staged, whether promoted yet or not. The next apply deletes its objects before deploying unless ACTIVE points at it; destroy deletes them too. Empty when there is none.",
 							Computed:            true,
 						},
 						"deployed_bundle_hash": schema.StringAttribute{
@@ -218,12 +381,50 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 							Computed:            true,
 							ElementType:         types.StringType,
 						},
+						"active_etag": schema.StringAttribute{
+							MarkdownDescription: "ETag of the ACTIVE marker as last observed. The next deploy only moves the marker if it is unchanged.",
+							Computed:            true,
+						},
+						"active_generation": schema.Int64Attribute{
+							MarkdownDescription: "Object generation of the ACTIVE marker as last observed (GCS targets; `0` elsewhere).",
+							Computed:            true,
+						},
+						"stale": schema.BoolAttribute{
+							MarkdownDescription: "Whether the target was unreachable at the last refresh and the other values are carried over from an earlier read. Only set with `tolerate_unreachable_targets`.",
+							Computed:            true,
+						},
 					},
 				},
 			},
 		},
 
 		Blocks: map[string]schema.Block{
+			"source_git": schema.ListNestedBlock{
+				MarkdownDescription: "Git repository the skill source files are fetched from at apply time, with the `git` command. At most one block may be specified.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"url": schema.StringAttribute{
+							MarkdownDescription: "Repository URL: an `https://`, `ssh://`, or `git://` URL, or an SSH address like `git@github.com:org/repo.git`.",
+							Required:            true,
+						},
+						"ref": schema.StringAttribute{
+							MarkdownDescription: "Branch, tag, or commit ID to fetch.",
+							Required:            true,
+						},
+						"subdir": schema.StringAttribute{
+							MarkdownDescription: "Directory inside the repository containing the skill source files. Defaults to the repository root. The skill name is its base name, or the repository name without `.git` when omitted.",
+							Optional:            true,
+						},
+						"commit": schema.StringAttribute{
+							MarkdownDescription: "Full commit ID `ref` must resolve to; the apply fails otherwise. Required unless `ref` is itself a full commit ID.",
+							Optional:            true,
+						},
+					},
+				},
+			},
 			"anthropic": schema.ListNestedBlock{
 				MarkdownDescription: "Configuration for Anthropic registry integration. At most one block may be specified.",
 				NestedObject: schema.NestedBlockObject{
@@ -260,9 +461,94 @@ func (r *SkillResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 							MarkdownDescription: "Version string to use when `version_strategy` is `\"pinned\"`. Required when strategy is `\"pinned\"`.",
 							Optional:            true,
 						},
+						"version_notes": schema.StringAttribute{
+							MarkdownDescription: "Notes describing what changed, sent with each version the resource creates and recorded in the deployment manifest. Changing only the notes does not create a new version.",
+							Optional:            true,
+						},
+						"version_labels": schema.MapAttribute{
+							MarkdownDescription: "Labels sent with each version the resource creates and recorded in the deployment manifest. Changing only the labels does not create a new version.",
+							ElementType:         types.StringType,
+							Optional:            true,
+						},
+						"on_destroy": schema.StringAttribute{
+							MarkdownDescription: "What happens to the registry skill when the resource is destroyed: `\"delete\"` deletes the skill and its versions, `\"detach\"` leaves them in the registry for another team or pipeline to adopt. Defaults to the provider's `destroy_remote` setting.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(anthropic.OnDestroyDelete, anthropic.OnDestroyDetach),
+							},
+						},
+						"destroy_all_versions": schema.BoolAttribute{
+							MarkdownDescription: "Delete every version of the skill in the registry on destroy, including versions created outside Terraform, so that the skill itself can be deleted. By default only the versions listed in `registry_state.managed_versions` are deleted, and a skill with other versions is left in the registry. Defaults to `false`.",
+							Optional:            true,
+							Computed:            true,
+							Default:             booldefault.StaticBool(false),
+						},
+						"on_version_drift": schema.StringAttribute{
+							MarkdownDescription: "What a plan does when the last refresh found that the registry's latest version of the skill was created outside Terraform: `\"warn\"` reports it, `\"reconcile\"` updates the resource so that the apply publishes the configured bundle as a new latest version. Reconciling requires `auto_version`. Defaults to `\"warn\"`.",
+							Optional:            true,
+							Computed:            true,
+							Default:             stringdefault.StaticString(versionDriftWarn),
+							Validators: []validator.String{
+								stringvalidator.OneOf(versionDriftWarn, versionDriftReconcile),
+							},
+						},
+					},
+				},
+			},
+			"verify": schema.ListNestedBlock{
+				MarkdownDescription: "Smoke test run on each target after the ACTIVE pointer moves to a new deployment. If it fails, ACTIVE is rolled back to the previous deployment and the apply fails. At most one block may be specified.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"command": schema.StringAttribute{
+							MarkdownDescription: "Shell command that must exit with status 0, run with `sh -c` (`cmd /C` on Windows). A Go template: `{{.Target}}`, `{{.SkillName}}`, `{{.DeploymentID}}`, `{{.PreviousDeploymentID}}`, and `{{.BundleHash}}` are replaced with the deployment's values, which are also set as `AGENTCTX_TARGET`, `AGENTCTX_SKILL_NAME`, `AGENTCTX_DEPLOYMENT_ID`, `AGENTCTX_PREVIOUS_DEPLOYMENT_ID`, and `AGENTCTX_BUNDLE_HASH` in its environment. Conflicts with `url`.",
+							Optional:            true,
+						},
+						"url": schema.StringAttribute{
+							MarkdownDescription: "URL that must answer a GET request with `expected_status`. A Go template with the same fields as `command`. Conflicts with `command`.",
+							Optional:            true,
+						},
+						"expected_status": schema.Int64Attribute{
+							MarkdownDescription: "HTTP status `url` must answer with. Defaults to `200`.",
+							Optional:            true,
+							Computed:            true,
+							Default:             int64default.StaticInt64(200),
+							Validators: []validator.Int64{
+								int64validator.Between(100, 599),
+							},
+						},
+						"timeout_seconds": schema.Int64Attribute{
+							MarkdownDescription: "How long the check may take on each target before it fails. Defaults to `60`.",
+							Optional:            true,
+							Computed:            true,
+							Default:             int64default.StaticInt64(60),
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+					},
+				},
+			},
+			"canary": schema.ListNestedBlock{
+				MarkdownDescription: "Rolls each new deployment out gradually: ACTIVE is written as a weighted pointer that sends `weight` percent of consumers to the new deployment and the rest to the deployment it replaces. Remove the block to promote the canary. At most one block may be specified.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"weight": schema.Int64Attribute{
+							MarkdownDescription: "Percentage of consumers, from `1` to `99`, that load the new deployment.",
+							Required:            true,
+							Validators: []validator.Int64{
+								int64validator.Between(1, 99),
+							},
+						},
 					},
 				},
 			},
+			"content_scan": contentscan.Block("the Markdown files of the skill bundle, at plan time and again at apply time,"),
 		},
 	}
 }
@@ -279,7 +565,7 @@ func (r *SkillResource) Configure(_ context.Context, req resource.ConfigureReque
 	pd, ok := req.ProviderData.(*providerdata.ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
-			"Unexpected Resource Configure Type",
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
 			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
@@ -293,6 +579,11 @@ func (r *SkillResource) Configure(_ context.Context, req resource.ConfigureReque
 // --------------------------------------------------------------------------
 
 func (r *SkillResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan SkillResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -314,40 +605,92 @@ func (r *SkillResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	// 3. Scan source bundle.
-	sourceDir := plan.SourceDir.ValueString()
+	src, err := openSkillSource(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidBundle.Summary("Skill Source Failed"), fmt.Sprintf("Failed to prepare the skill source: %s", err))
+		return
+	}
+	defer src.close()
+	sourceDir := src.dir
 	allowExtSym := plan.AllowExternalSymlinks.ValueBool()
 
 	b, err := bundle.ScanBundle(sourceDir, excludes, allowExtSym)
 	if err != nil {
-		resp.Diagnostics.AddError("Bundle Scan Failed", fmt.Sprintf("Failed to scan source directory %q: %s", sourceDir, err))
+		resp.Diagnostics.AddError(errcode.InvalidBundle.Summary("Bundle Scan Failed"), fmt.Sprintf("Failed to scan source %q: %s", src.path, err))
 		return
 	}
 
-	skillName := filepath.Base(sourceDir)
+	skillName := src.name
+	skillKey := storageName(plan, skillName)
 	plan.SkillName = types.StringValue(skillName)
 	plan.SourceHash = types.StringValue(b.BundleHash)
 	plan.BundleHash = types.StringValue(b.BundleHash)
+	setSkillFrontmatter(&plan, b)
+	resp.Diagnostics.Append(checkSkillName(b)...)
+	resp.Diagnostics.Append(scanContent(ctx, plan, b)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dependsOn, diags := dependsOnSkills(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bundleJSON, diags := bundleDescriptor(b, src)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.BundleJSON = bundleJSON
+	plan.DriftDetected = types.BoolValue(false)
+
+	provenanceJSON, diags := r.buildProvenance(&plan, b, src, excludes)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.DriftDetails = types.ListValueMust(types.StringType, []attr.Value{})
 
 	// 4. If validate_only, save minimal state and return.
 	if plan.ValidateOnly.ValueBool() {
 		plan.ID = types.StringValue("validate:" + skillName)
 		plan.RegistryState = types.ObjectNull(registryStateAttrTypes())
+		plan.RegistrySkipped = types.BoolValue(false)
 		plan.TargetStates = types.MapNull(types.ObjectType{AttrTypes: targetStateAttrTypes()})
 		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 		return
 	}
 
-	eng := engine.New(r.providerData.Semaphore)
+	eng := engine.New(r.providerData.Scheduler, engine.WithListeners(r.providerData.Listeners...))
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill",
+			Operation: dryrun.OpCreate,
+			ID:        skillKey,
+			Targets: r.dryRunDeploy(ctx, eng, resolvedTargets, engine.DeployInput{
+				SkillName:  skillKey,
+				Bundle:     b,
+				SourceDir:  src.sourceDir(),
+				Provenance: provenanceJSON,
+				StageOnly:  stageOnly(plan),
+			}),
+			Registry: dryRunRegistry(plan, "", true),
+		})...)
+		return
+	}
 
 	// 5. Anthropic registry integration.
 	var registryInfo *manifest.ManifestRegistry
 	registryState := types.ObjectNull(registryStateAttrTypes())
+	registrySkipped := false
 
 	if len(plan.Anthropic) == 1 && plan.Anthropic[0].Enabled.ValueBool() {
 		anthCfg := plan.Anthropic[0]
 		if r.providerData.Anthropic == nil {
 			resp.Diagnostics.AddError(
-				"Anthropic Client Not Configured",
+				errcode.AnthropicNotConfigured.Summary("Anthropic Client Not Configured"),
 				"The resource anthropic block is enabled but the provider does not have an anthropic block configured.",
 			)
 			return
@@ -361,44 +704,50 @@ func (r *SkillResource) Create(ctx context.Context, req resource.CreateRequest,
 			}
 
 			// Create skill in the Anthropic registry.
-			skill, createErr := r.providerData.Anthropic.CreateSkill(ctx, sourceDir, displayTitle)
-			if createErr != nil {
-				resp.Diagnostics.AddError("Anthropic Create Skill Failed", fmt.Sprintf("Failed to create skill: %s", createErr))
+			skill, createErr := r.providerData.Anthropic.CreateSkill(ctx, sourceDir, displayTitle, anthropic.IdempotencyKey(src.path, displayTitle, b.BundleHash))
+			switch {
+			case createErr == nil:
+				registryInfo = &manifest.ManifestRegistry{
+					Type:    "anthropic",
+					SkillID: skill.ID,
+				}
+			case r.skipRegistry(&resp.Diagnostics, skillName, createErr):
+				registrySkipped = true
+			default:
+				resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Anthropic Create Skill Failed"), fmt.Sprintf("Failed to create skill: %s", createErr))
 				return
 			}
 
-			registryInfo = &manifest.ManifestRegistry{
-				Type:    "anthropic",
-				SkillID: skill.ID,
-			}
-
 			// Optionally create a version.
-			if anthCfg.AutoVersion.ValueBool() {
-				ver, verErr := r.providerData.Anthropic.CreateVersion(ctx, skill.ID, sourceDir)
-				if verErr != nil {
-					resp.Diagnostics.AddError("Anthropic Create Version Failed", fmt.Sprintf("Failed to create version: %s", verErr))
+			var deployedVersion string
+			if registryInfo != nil && anthCfg.AutoVersion.ValueBool() {
+				versionReq, reqDiags := createVersionRequest(ctx, anthCfg, skill.ID, b.BundleHash)
+				resp.Diagnostics.Append(reqDiags...)
+				if resp.Diagnostics.HasError() {
 					return
 				}
-
-				registryInfo.Version = ver.Version
-				registryInfo.BundleHash = b.BundleHash
-
-				rsVal, rsDiags := types.ObjectValueFrom(ctx, registryStateAttrTypes(), RegistryStateValue{
-					SkillID:         types.StringValue(skill.ID),
-					DeployedVersion: types.StringValue(ver.Version),
-					LatestVersion:   types.StringValue(ver.Version),
-				})
-				resp.Diagnostics.Append(rsDiags...)
-				if resp.Diagnostics.HasError() {
+				ver, verErr := r.providerData.Anthropic.CreateVersion(ctx, skill.ID, sourceDir, versionReq)
+				switch {
+				case verErr == nil:
+					registryInfo.Version = ver.Version
+					registryInfo.BundleHash = b.BundleHash
+					registryInfo.Notes = versionReq.Notes
+					registryInfo.Labels = versionReq.Labels
+					deployedVersion = ver.Version
+				case r.skipRegistry(&resp.Diagnostics, skillName, verErr):
+					registrySkipped = true
+				default:
+					resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Anthropic Create Version Failed"), fmt.Sprintf("Failed to create version: %s", verErr))
 					return
 				}
-				registryState = rsVal
-			} else {
-				rsVal, rsDiags := types.ObjectValueFrom(ctx, registryStateAttrTypes(), RegistryStateValue{
-					SkillID:         types.StringValue(skill.ID),
-					DeployedVersion: types.StringValue(""),
-					LatestVersion:   types.StringValue(""),
-				})
+			}
+
+			if registryInfo != nil {
+				var managed []string
+				if deployedVersion != "" {
+					managed = []string{deployedVersion}
+				}
+				rsVal, rsDiags := registryStateObject(ctx, skill.ID, deployedVersion, deployedVersion, managed)
 				resp.Diagnostics.Append(rsDiags...)
 				if resp.Diagnostics.HasError() {
 					return
@@ -408,17 +757,19 @@ func (r *SkillResource) Create(ctx context.Context, req resource.CreateRequest,
 		}
 	}
 	plan.RegistryState = registryState
+	plan.RegistrySkipped = types.BoolValue(registrySkipped)
 
 	// 6. Deploy to each target.
 	targetStates := make(map[string]attr.Value, len(resolvedTargets))
 	var firstDeployID string
 	deployIDByTarget := make(map[string]string, len(resolvedTargets))
+	managedIDsByTarget := make(map[string][]string, len(resolvedTargets))
 
 	for _, tName := range resolvedTargets {
-		t, ok := r.providerData.Targets[tName]
+		t, ok := r.providerData.Targets.Get(tName)
 		if !ok {
 			resp.Diagnostics.AddError(
-				"Target Not Found",
+				errcode.UnknownTarget.Summary("Target Not Found"),
 				fmt.Sprintf("Target %q referenced by the resource is not defined in the provider.", tName),
 			)
 			return
@@ -429,40 +780,114 @@ func (r *SkillResource) Create(ctx context.Context, req resource.CreateRequest,
 			"target":     tName,
 		})
 
+		var adoptedIDs []string
+		if plan.AdoptUnmanagedDeployments.ValueBool() {
+			adoptedIDs = adoptDeployments(ctx, eng, t, tName, skillKey, nil)
+		}
+
 		result, deployErr := eng.Deploy(ctx, t, engine.DeployInput{
-			SkillName:       skillName,
+			SkillName:       skillKey,
 			Bundle:          b,
 			CanonicalStore:  r.providerData.CanonicalStore,
-			ProviderVersion: "dev",
+			ProviderVersion: r.providerData.Version,
 			ResourceName:    skillName,
-			SourceDir:       sourceDir,
+			SourceDir:       src.sourceDir(),
+			SourceArchive:   src.originOf(sourceKindArchive),
+			SourceURL:       src.originOf(sourceKindURL, sourceKindGit),
 			RegistryInfo:    registryInfo,
+			DeploymentAlias: plan.DeploymentAlias.ValueString(),
+			DependsOnSkills: dependsOn,
+
+			CleanupOrphans:    plan.CleanupOrphanedDeployments.ValueBool(),
+			OrphanGracePeriod: time.Duration(plan.OrphanGracePeriodSeconds.ValueInt64()) * time.Second,
+			ManagedDeployIDs:  adoptedIDs,
+			Provenance:        provenanceJSON,
+
+			Workspace:   r.providerData.Workspace,
+			Environment: r.providerData.Environment,
+			ExpiresAt:   previewExpiry(plan, time.Now()),
+			Verify:      verifier(plan),
+
+			CanaryWeight:   canaryWeight(plan),
+			StageOnly:      stageOnly(plan),
+			ReadAfterWrite: r.readAfterWrite(tName),
 		})
+		var verifyErr *engine.VerifyError
+		if errors.As(deployErr, &verifyErr) {
+			// Record the rejected deployment as staged, so the replacement
+			// apply removes it.
+			resp.Diagnostics.Append(verifyFailedDiag(skillName, tName, verifyErr))
+			tsVal, tsDiags := verifyFailedTargetState(ctx, TargetStateValue{}, verifyErr)
+			if tsDiags.HasError() {
+				resp.Diagnostics.Append(tsDiags...)
+				return
+			}
+			targetStates[tName] = tsVal
+			resp.Diagnostics.Append(savePartialState(ctx, &resp.State, plan, skillName, firstDeployID, targetStates)...)
+			return
+		}
 		if deployErr != nil {
 			resp.Diagnostics.AddError(
-				"Deployment Failed",
+				errcode.DeployFailed.Summary("Deployment Failed"),
 				fmt.Sprintf("Failed to deploy skill %q to target %q: %s", skillName, tName, deployErr),
 			)
+			// Save what was deployed so far, plus the partial upload as
+			// staged, so the replacement apply removes it.
+			var stagedErr *engine.StagedError
+			if errors.As(deployErr, &stagedErr) {
+				tsVal, tsDiags := stagedTargetState(ctx, TargetStateValue{}, stagedErr.DeploymentID)
+				resp.Diagnostics.Append(tsDiags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				targetStates[tName] = tsVal
+				resp.Diagnostics.Append(savePartialState(ctx, &resp.State, plan, skillName, firstDeployID, targetStates)...)
+			}
 			return
 		}
 
+		logOrphanCleanup(ctx, tName, result)
+		logIndexFailure(ctx, tName, result)
+		logConsistencyFailure(ctx, tName, result)
+
 		if firstDeployID == "" {
 			firstDeployID = result.DeploymentID
 		}
-		deployIDByTarget[tName] = result.DeploymentID
+		if result.Staged {
+			// Nothing is active yet; the deployment becomes managed once
+			// it is promoted and a refresh sees ACTIVE point at it.
+			managedIDsByTarget[tName] = adoptedIDs
+		} else {
+			deployIDByTarget[tName] = result.DeploymentID
+			managedIDsByTarget[tName] = appendUnique(adoptedIDs, result.DeploymentID)
+		}
 
-		managedIDs, idDiags := types.ListValueFrom(ctx, types.StringType, []string{result.DeploymentID})
+		managedIDs, idDiags := types.ListValueFrom(ctx, types.StringType, managedIDsByTarget[tName])
 		resp.Diagnostics.Append(idDiags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
 
+		if result.Staged {
+			tsVal, tsDiags := stagedTargetState(ctx, TargetStateValue{ManagedDeployIDs: managedIDs}, result.DeploymentID)
+			resp.Diagnostics.Append(tsDiags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			targetStates[tName] = tsVal
+			continue
+		}
+
 		tsVal, tsDiags := types.ObjectValueFrom(ctx, targetStateAttrTypes(), TargetStateValue{
 			ActiveDeploymentID: types.StringValue(result.DeploymentID),
+			StableDeploymentID: types.StringValue(result.StableDeploymentID),
 			StagedDeploymentID: types.StringValue(""),
 			DeployedBundleHash: types.StringValue(result.BundleHash),
 			LastSyncedAt:       types.StringValue(time.Now().UTC().Format(time.RFC3339)),
 			ManagedDeployIDs:   managedIDs,
+			ActiveETag:         types.StringValue(result.ActiveETag),
+			ActiveGeneration:   types.Int64Value(result.ActiveGeneration),
+			Stale:              types.BoolValue(false),
 		})
 		resp.Diagnostics.Append(tsDiags...)
 		if resp.Diagnostics.HasError() {
@@ -480,19 +905,18 @@ func (r *SkillResource) Create(ctx context.Context, req resource.CreateRequest,
 	plan.TargetStates = tsMap
 
 	// 7. Set the resource ID.
-	if firstDeployID != "" {
-		plan.ID = types.StringValue(skillName + ":" + firstDeployID)
-	} else {
-		plan.ID = types.StringValue(skillName)
-	}
+	plan.ID = types.StringValue(resourceID(skillName, firstDeployID))
+
+	// Replicas trail their primary; lag is a warning, not a failure.
+	resp.Diagnostics.Append(r.verifyReplicas(ctx, eng, skillKey, deployIDByTarget)...)
 
 	// 8. Prune old deployments if enabled.
 	if plan.PruneDeployments.ValueBool() {
 		retain := int(plan.RetainDeployments.ValueInt64())
 		for _, tName := range resolvedTargets {
-			t := r.providerData.Targets[tName]
+			t, _ := r.providerData.Targets.Get(tName)
 			activeDeployID := deployIDByTarget[tName]
-			_, pruneErr := eng.Prune(ctx, t, skillName, activeDeployID, []string{activeDeployID}, retain)
+			_, pruneErr := eng.Prune(ctx, t, skillKey, activeDeployID, managedIDsByTarget[tName], retain)
 			if pruneErr != nil {
 				tflog.Warn(ctx, "prune failed", map[string]interface{}{
 					"target": tName,
@@ -502,6 +926,11 @@ func (r *SkillResource) Create(ctx context.Context, req resource.CreateRequest,
 		}
 	}
 
+	// 9. Remove expired previews of the skill if enabled.
+	if plan.CleanupExpiredPreviews.ValueBool() {
+		r.collectExpiredPreviews(ctx, eng, skillName, resolvedTargets)
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
@@ -523,32 +952,91 @@ func (r *SkillResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	eng := engine.New(r.providerData.Semaphore)
+	priorTargetStates, diags := targetStateValues(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eng := engine.New(r.providerData.Scheduler, engine.WithListeners(r.providerData.Listeners...))
 
 	expectedHash := state.BundleHash.ValueString()
 	deepCheck := state.DeepDriftCheck.ValueBool()
 	targetStates := make(map[string]attr.Value, len(resolvedTargets))
+	var driftDetails []string
+	skillName := storageName(state, state.SkillName.ValueString())
+
+	// Refresh all targets first, in parallel within the provider's
+	// refresh_concurrency, then handle the results in target order.
+	type refreshOutcome struct {
+		result *engine.RefreshResult
+		err    error
+
+		// integrityFailed and integrityErr are the outcome of the
+		// integrity check integrity_check_interval asks for, if one was
+		// due.
+		integrityFailed []string
+		integrityErr    error
+	}
+	interval := integrityInterval(state)
+	recordIntegrity := !r.providerData.ReadOnly && !r.providerData.DryRunning()
+	outcomes := make([]refreshOutcome, len(resolvedTargets))
+	poolErr := r.providerData.RefreshPool.Do(ctx, len(resolvedTargets), func(i int) {
+		if t, ok := r.providerData.Targets.Get(resolvedTargets[i]); ok {
+			// On a target whose reads may trail writes, a deploy this
+			// resource just made may not be visible yet; retry before
+			// reporting it as drift.
+			tName := resolvedTargets[i]
+			want := priorTargetStates[tName].ActiveDeploymentID.ValueString()
+			outcomes[i].result, outcomes[i].err = eng.RefreshSettled(ctx, t, skillName, expectedHash, deepCheck, want, r.readAfterWrite(tName))
+			if result := outcomes[i].result; outcomes[i].err == nil && !result.MissingManifest {
+				outcomes[i].integrityFailed, outcomes[i].integrityErr = checkIntegrity(ctx, eng, t, skillName, result.ActiveDeploymentID, interval, recordIntegrity)
+			}
+		}
+	})
+	if poolErr != nil {
+		resp.Diagnostics.AddError(
+			errcode.RefreshFailed.Summary("Refresh Failed"),
+			fmt.Sprintf("Refresh of skill %q was interrupted: %s", skillName, poolErr),
+		)
+		return
+	}
 
-	for _, tName := range resolvedTargets {
-		t, ok := r.providerData.Targets[tName]
-		if !ok {
+	for i, tName := range resolvedTargets {
+		if _, ok := r.providerData.Targets.Get(tName); !ok {
 			tflog.Warn(ctx, "target no longer configured, removing from state", map[string]interface{}{
 				"target": tName,
 			})
 			continue
 		}
 
-		skillName := state.SkillName.ValueString()
-		result, refreshErr := eng.Refresh(ctx, t, skillName, expectedHash, deepCheck)
+		result, refreshErr := outcomes[i].result, outcomes[i].err
+		if refreshErr != nil && state.TolerateUnreachableTargets.ValueBool() {
+			resp.Diagnostics.AddWarning(
+				errcode.TargetUnreachable.Summary("Target Unreachable"),
+				fmt.Sprintf("Could not refresh skill %q from target %q: %s. The target is marked stale and keeps its last known state until it can be read again.", skillName, tName, refreshErr),
+			)
+			tsVal, tsDiags := staleTargetState(ctx, priorTargetStates[tName])
+			resp.Diagnostics.Append(tsDiags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			targetStates[tName] = tsVal
+			continue
+		}
 		if refreshErr != nil {
 			resp.Diagnostics.AddError(
-				"Refresh Failed",
+				errcode.RefreshFailed.Summary("Refresh Failed"),
 				fmt.Sprintf("Failed to refresh skill %q from target %q: %s", skillName, tName, refreshErr),
 			)
 			return
 		}
 
 		if result.MissingManifest {
+			resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_skill", state.ID.ValueString(), fmt.Sprintf("the skill's manifest on target %q was deleted", tName))...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
 			tflog.Info(ctx, "skill manifest not found on target, resource may have been deleted externally", map[string]interface{}{
 				"target": tName,
 			})
@@ -556,10 +1044,30 @@ func (r *SkillResource) Read(ctx context.Context, req resource.ReadRequest, resp
 			return
 		}
 
-		// Build the managed deploy IDs list.
+		// Keep the deployments the resource already manages, such as older
+		// ones awaiting pruning and adopted ones, and add those ACTIVE
+		// refers to.
 		var managedIDs []string
+		if pts, exists := priorTargetStates[tName]; exists && !pts.ManagedDeployIDs.IsNull() && !pts.ManagedDeployIDs.IsUnknown() {
+			resp.Diagnostics.Append(pts.ManagedDeployIDs.ElementsAs(ctx, &managedIDs, false)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
 		if result.ActiveDeploymentID != "" {
-			managedIDs = append(managedIDs, result.ActiveDeploymentID)
+			managedIDs = appendUnique(managedIDs, result.ActiveDeploymentID)
+		}
+		if result.StableDeploymentID != "" {
+			managedIDs = appendUnique(managedIDs, result.StableDeploymentID)
+		}
+
+		// Keep a staged deployment until a deploy cleans it up, unless the
+		// ACTIVE write that reported failure landed after all. A deployment
+		// staged by deployment_strategy = "staged" is kept once promoted,
+		// so that a promotion configured from it stays unchanged.
+		stagedID := priorTargetStates[tName].StagedDeploymentID.ValueString()
+		if stagedID == result.ActiveDeploymentID && !stageOnly(state) {
+			stagedID = ""
 		}
 
 		managedIDsList, idDiags := types.ListValueFrom(ctx, types.StringType, managedIDs)
@@ -575,10 +1083,14 @@ func (r *SkillResource) Read(ctx context.Context, req resource.ReadRequest, resp
 
 		tsVal, tsDiags := types.ObjectValueFrom(ctx, targetStateAttrTypes(), TargetStateValue{
 			ActiveDeploymentID: types.StringValue(result.ActiveDeploymentID),
-			StagedDeploymentID: types.StringValue(""),
+			StableDeploymentID: types.StringValue(result.StableDeploymentID),
+			StagedDeploymentID: types.StringValue(stagedID),
 			DeployedBundleHash: types.StringValue(bundleHash),
 			LastSyncedAt:       types.StringValue(time.Now().UTC().Format(time.RFC3339)),
 			ManagedDeployIDs:   managedIDsList,
+			ActiveETag:         types.StringValue(result.ActiveETag),
+			ActiveGeneration:   types.Int64Value(result.ActiveGeneration),
+			Stale:              types.BoolValue(false),
 		})
 		resp.Diagnostics.Append(tsDiags...)
 		if resp.Diagnostics.HasError() {
@@ -587,14 +1099,53 @@ func (r *SkillResource) Read(ctx context.Context, req resource.ReadRequest, resp
 
 		targetStates[tName] = tsVal
 
-		// Detect drift.
-		if result.Drifted {
+		// Detect drift. Until a staged deployment is promoted, ACTIVE is
+		// expected to select an older one.
+		if promotionPending(state, stagedID, result.ActiveDeploymentID) {
+			tflog.Info(ctx, "staged deployment awaiting promotion", map[string]interface{}{
+				"target": tName,
+				"staged": stagedID,
+				"active": result.ActiveDeploymentID,
+			})
+		} else if details := describeDrift(tName, result, expectedHash); len(details) > 0 {
 			tflog.Warn(ctx, "drift detected on target", map[string]interface{}{
 				"target":   tName,
 				"deployed": bundleHash,
 				"expected": expectedHash,
+				"details":  details,
 			})
+			driftDetails = append(driftDetails, details...)
+		}
+		if err := outcomes[i].integrityErr; err != nil {
+			resp.Diagnostics.AddWarning(
+				errcode.RefreshFailed.Summary("Integrity Check Failed"),
+				fmt.Sprintf("Could not check the integrity of skill %q on target %q: %s. The check is retried on the next refresh.", skillName, tName, err),
+			)
 		}
+		driftDetails = append(driftDetails, describeIntegrityFailures(tName, result.ActiveDeploymentID, outcomes[i].integrityFailed)...)
+	}
+
+	registryDrift, diags := r.refreshRegistry(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	driftDetails = append(driftDetails, registryDrift...)
+
+	if !state.ValidateOnly.ValueBool() {
+		if len(driftDetails) > 0 {
+			resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_skill", state.ID.ValueString(), strings.Join(driftDetails, "; "))...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+		driftList, driftDiags := types.ListValueFrom(ctx, types.StringType, append([]string{}, driftDetails...))
+		resp.Diagnostics.Append(driftDiags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.DriftDetected = types.BoolValue(len(driftDetails) > 0)
+		state.DriftDetails = driftList
 	}
 
 	// Update target_states in state.
@@ -615,6 +1166,11 @@ func (r *SkillResource) Read(ctx context.Context, req resource.ReadRequest, resp
 // --------------------------------------------------------------------------
 
 func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan SkillResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -642,36 +1198,96 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	// 3. Scan source bundle.
-	sourceDir := plan.SourceDir.ValueString()
+	src, err := openSkillSource(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidBundle.Summary("Skill Source Failed"), fmt.Sprintf("Failed to prepare the skill source: %s", err))
+		return
+	}
+	defer src.close()
+	sourceDir := src.dir
 	allowExtSym := plan.AllowExternalSymlinks.ValueBool()
 
 	b, err := bundle.ScanBundle(sourceDir, excludes, allowExtSym)
 	if err != nil {
-		resp.Diagnostics.AddError("Bundle Scan Failed", fmt.Sprintf("Failed to scan source directory %q: %s", sourceDir, err))
+		resp.Diagnostics.AddError(errcode.InvalidBundle.Summary("Bundle Scan Failed"), fmt.Sprintf("Failed to scan source %q: %s", src.path, err))
 		return
 	}
 
-	skillName := filepath.Base(sourceDir)
+	skillName := src.name
+	skillKey := storageName(plan, skillName)
 	plan.SkillName = types.StringValue(skillName)
 	plan.SourceHash = types.StringValue(b.BundleHash)
 	plan.BundleHash = types.StringValue(b.BundleHash)
+	setSkillFrontmatter(&plan, b)
+	resp.Diagnostics.Append(checkSkillName(b)...)
+	resp.Diagnostics.Append(scanContent(ctx, plan, b)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	dependsOn, diags := dependsOnSkills(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bundleJSON, diags := bundleDescriptor(b, src)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.BundleJSON = bundleJSON
+	plan.DriftDetected = types.BoolValue(false)
+
+	provenanceJSON, diags := r.buildProvenance(&plan, b, src, excludes)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.DriftDetails = types.ListValueMust(types.StringType, []attr.Value{})
 	priorSkillName := priorState.SkillName.ValueString()
-	cleanupPriorSkill := priorSkillName != "" && priorSkillName != skillName
+	var priorSkillKey string
+	if priorSkillName != "" {
+		priorSkillKey = storageName(priorState, priorSkillName)
+	}
+	cleanupPriorSkill := priorSkillKey != "" && priorSkillKey != skillKey
 
 	// 4. Detect whether the bundle actually changed.
 	bundleChanged := priorState.BundleHash.ValueString() != b.BundleHash
 
-	eng := engine.New(r.providerData.Semaphore)
+	eng := engine.New(r.providerData.Scheduler, engine.WithListeners(r.providerData.Listeners...))
 
-	// 5. Anthropic registry update.
+	// 5. Anthropic registry update. A registration a prior apply skipped
+	// under registry_failure_policy is retried as if the bundle changed.
 	var registryInfo *manifest.ManifestRegistry
 	registryState := priorState.RegistryState
+	retryRegistry := priorState.RegistrySkipped.ValueBool()
+	priorRegistry, _, diags := registryStateValue(ctx, priorState)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	reconcileRegistry := versionDrifted(priorRegistry) && len(plan.Anthropic) == 1 &&
+		plan.Anthropic[0].OnVersionDrift.ValueString() == versionDriftReconcile
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRunUpdate(ctx, eng, plan, priorState, dryRunUpdateInput{
+			targets:        resolvedTargets,
+			skillKey:       skillKey,
+			priorSkillKey:  priorSkillKey,
+			bundle:         b,
+			src:            src,
+			provenanceJSON: provenanceJSON,
+			newVersion:     bundleChanged || retryRegistry || reconcileRegistry,
+		})...)
+		return
+	}
+	registrySkipped := false
 
 	if len(plan.Anthropic) == 1 && plan.Anthropic[0].Enabled.ValueBool() {
 		anthCfg := plan.Anthropic[0]
 		if r.providerData.Anthropic == nil {
 			resp.Diagnostics.AddError(
-				"Anthropic Client Not Configured",
+				errcode.AnthropicNotConfigured.Summary("Anthropic Client Not Configured"),
 				"The resource anthropic block is enabled but the provider does not have an anthropic block configured.",
 			)
 			return
@@ -699,59 +1315,90 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 				_, updateErr := r.providerData.Anthropic.UpdateSkill(ctx, existingSkillID, anthropic.UpdateSkillRequest{
 					DisplayTitle: displayTitle,
 				})
-				if updateErr != nil {
-					resp.Diagnostics.AddError("Anthropic Update Skill Failed", fmt.Sprintf("Failed to update skill: %s", updateErr))
+				switch {
+				case updateErr == nil:
+					registryInfo = &manifest.ManifestRegistry{
+						Type:    "anthropic",
+						SkillID: existingSkillID,
+					}
+				case r.skipRegistry(&resp.Diagnostics, skillName, updateErr):
+					registrySkipped = true
+				default:
+					resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Anthropic Update Skill Failed"), fmt.Sprintf("Failed to update skill: %s", updateErr))
 					return
 				}
-
-				registryInfo = &manifest.ManifestRegistry{
-					Type:    "anthropic",
-					SkillID: existingSkillID,
-				}
 			} else {
 				// Create new skill.
-				skill, createErr := r.providerData.Anthropic.CreateSkill(ctx, sourceDir, displayTitle)
-				if createErr != nil {
-					resp.Diagnostics.AddError("Anthropic Create Skill Failed", fmt.Sprintf("Failed to create skill: %s", createErr))
+				skill, createErr := r.providerData.Anthropic.CreateSkill(ctx, sourceDir, displayTitle, anthropic.IdempotencyKey(src.path, displayTitle, b.BundleHash))
+				switch {
+				case createErr == nil:
+					existingSkillID = skill.ID
+					registryInfo = &manifest.ManifestRegistry{
+						Type:    "anthropic",
+						SkillID: skill.ID,
+					}
+				case r.skipRegistry(&resp.Diagnostics, skillName, createErr):
+					registrySkipped = true
+				default:
+					resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Anthropic Create Skill Failed"), fmt.Sprintf("Failed to create skill: %s", createErr))
 					return
 				}
-				existingSkillID = skill.ID
-				registryInfo = &manifest.ManifestRegistry{
-					Type:    "anthropic",
-					SkillID: skill.ID,
-				}
 			}
 
-			// Create a new version if the bundle changed and auto_version is on.
-			if bundleChanged && anthCfg.AutoVersion.ValueBool() {
-				ver, verErr := r.providerData.Anthropic.CreateVersion(ctx, existingSkillID, sourceDir)
-				if verErr != nil {
-					resp.Diagnostics.AddError("Anthropic Create Version Failed", fmt.Sprintf("Failed to create version: %s", verErr))
+			// Create a new version if the bundle changed and auto_version is
+			// on, or to reconcile a version created outside Terraform.
+			if registryInfo != nil && (bundleChanged || retryRegistry || reconcileRegistry) && anthCfg.AutoVersion.ValueBool() {
+				versionReq, reqDiags := createVersionRequest(ctx, anthCfg, existingSkillID, b.BundleHash)
+				resp.Diagnostics.Append(reqDiags...)
+				if resp.Diagnostics.HasError() {
 					return
 				}
+				ver, verErr := r.providerData.Anthropic.CreateVersion(ctx, existingSkillID, sourceDir, versionReq)
+				switch {
+				case verErr == nil:
+					registryInfo.Version = ver.Version
+					registryInfo.BundleHash = b.BundleHash
+					registryInfo.Notes = versionReq.Notes
+					registryInfo.Labels = versionReq.Labels
+
+					managed, mvDiags := managedVersions(ctx, priorRegistry)
+					resp.Diagnostics.Append(mvDiags...)
+					rsVal, rsDiags := registryStateObject(ctx, existingSkillID, ver.Version, ver.Version, append(managed, ver.Version))
+					resp.Diagnostics.Append(rsDiags...)
+					if resp.Diagnostics.HasError() {
+						return
+					}
+					registryState = rsVal
+				case r.skipRegistry(&resp.Diagnostics, skillName, verErr):
+					registrySkipped = true
+				default:
+					resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Anthropic Create Version Failed"), fmt.Sprintf("Failed to create version: %s", verErr))
+					return
+				}
+			} else if !bundleChanged {
+				// Keep existing registry state.
+				registryState = priorState.RegistryState
+			}
 
-				registryInfo.Version = ver.Version
-				registryInfo.BundleHash = b.BundleHash
-
-				rsVal, rsDiags := types.ObjectValueFrom(ctx, registryStateAttrTypes(), RegistryStateValue{
-					SkillID:         types.StringValue(existingSkillID),
-					DeployedVersion: types.StringValue(ver.Version),
-					LatestVersion:   types.StringValue(ver.Version),
-				})
+			// Record a skill created by this update, so a retry updates it
+			// rather than creating another, and keep the versions created
+			// by earlier applies for destroy.
+			if registryInfo != nil && registryState.IsNull() {
+				managed, mvDiags := managedVersions(ctx, priorRegistry)
+				resp.Diagnostics.Append(mvDiags...)
+				rsVal, rsDiags := registryStateObject(ctx, existingSkillID, "", "", managed)
 				resp.Diagnostics.Append(rsDiags...)
 				if resp.Diagnostics.HasError() {
 					return
 				}
 				registryState = rsVal
-			} else if !bundleChanged {
-				// Keep existing registry state.
-				registryState = priorState.RegistryState
 			}
 		}
 	} else {
 		registryState = types.ObjectNull(registryStateAttrTypes())
 	}
 	plan.RegistryState = registryState
+	plan.RegistrySkipped = types.BoolValue(registrySkipped)
 
 	// 6. Re-deploy to each target.
 	targetStates := make(map[string]attr.Value, len(resolvedTargets))
@@ -760,21 +1407,10 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 	managedIDsByTarget := make(map[string][]string, len(resolvedTargets))
 
 	// Read prior target states for previous deploy IDs.
-	priorTargetStates := make(map[string]TargetStateValue)
-	if !priorState.TargetStates.IsNull() && !priorState.TargetStates.IsUnknown() {
-		priorTSMap := make(map[string]types.Object)
-		resp.Diagnostics.Append(priorState.TargetStates.ElementsAs(ctx, &priorTSMap, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		for k, v := range priorTSMap {
-			var tsv TargetStateValue
-			resp.Diagnostics.Append(v.As(ctx, &tsv, basetypes.ObjectAsOptions{})...)
-			if resp.Diagnostics.HasError() {
-				return
-			}
-			priorTargetStates[k] = tsv
-		}
+	priorTargetStates, diags := targetStateValues(ctx, priorState)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	resolvedTargetSet := make(map[string]struct{}, len(resolvedTargets))
@@ -783,14 +1419,15 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	// Clean up targets no longer managed by this resource, and clean up the
-	// previous skill name if source_dir changed across an update.
+	// previous skill name if source_dir or preview_id changed across an
+	// update.
 	for tName, pts := range priorTargetStates {
 		_, stillManaged := resolvedTargetSet[tName]
 		if stillManaged && !cleanupPriorSkill {
 			continue
 		}
 
-		t, ok := r.providerData.Targets[tName]
+		t, ok := r.providerData.Targets.Get(tName)
 		if !ok {
 			tflog.Warn(ctx, "target no longer configured, skipping cleanup", map[string]interface{}{
 				"target": tName,
@@ -798,21 +1435,21 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 			continue
 		}
 
-		var managedIDs []string
-		resp.Diagnostics.Append(pts.ManagedDeployIDs.ElementsAs(ctx, &managedIDs, false)...)
+		managedIDs, idDiags := destroyDeployIDs(ctx, pts)
+		resp.Diagnostics.Append(idDiags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
 
-		destroySkillName := priorSkillName
+		destroySkillName := priorSkillKey
 		if destroySkillName == "" {
-			destroySkillName = skillName
+			destroySkillName = skillKey
 		}
 		if cleanupPriorSkill && stillManaged {
 			tflog.Info(ctx, "cleaning up previous skill name on target", map[string]interface{}{
 				"target":     tName,
 				"skill_name": destroySkillName,
-				"new_skill":  skillName,
+				"new_skill":  skillKey,
 			})
 		} else if !stillManaged {
 			tflog.Info(ctx, "cleaning up removed target", map[string]interface{}{
@@ -827,9 +1464,13 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 			ManagedDeployIDs:         managedIDs,
 			ActiveDeployID:           pts.ActiveDeploymentID.ValueString(),
 		})
+		if errors.Is(destroyErr, engine.ErrReferenced) {
+			resp.Diagnostics.Append(referencedDiag(destroySkillName, tName, destroyErr))
+			return
+		}
 		if destroyErr != nil {
 			resp.Diagnostics.AddError(
-				"Cleanup Failed",
+				errcode.FileDelete.Summary("Cleanup Failed"),
 				fmt.Sprintf("Failed to clean up skill %q from target %q: %s", destroySkillName, tName, destroyErr),
 			)
 			return
@@ -837,20 +1478,30 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	for _, tName := range resolvedTargets {
-		t, ok := r.providerData.Targets[tName]
+		t, ok := r.providerData.Targets.Get(tName)
 		if !ok {
 			resp.Diagnostics.AddError(
-				"Target Not Found",
+				errcode.UnknownTarget.Summary("Target Not Found"),
 				fmt.Sprintf("Target %q is not defined in the provider.", tName),
 			)
 			return
 		}
 
-		// Determine previous deploy ID for conditional writes.
-		var prevDeployID string
+		// Determine previous deploy ID and ACTIVE metadata for
+		// conditional writes.
+		var prevDeployID, prevActiveETag, stagedDeployID string
+		var prevActiveGeneration int64
+		var prevManagedIDs []string
 		if !cleanupPriorSkill {
 			if pts, exists := priorTargetStates[tName]; exists {
 				prevDeployID = pts.ActiveDeploymentID.ValueString()
+				prevActiveETag = pts.ActiveETag.ValueString()
+				prevActiveGeneration = pts.ActiveGeneration.ValueInt64()
+				stagedDeployID = pts.StagedDeploymentID.ValueString()
+				resp.Diagnostics.Append(pts.ManagedDeployIDs.ElementsAs(ctx, &prevManagedIDs, false)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
 			}
 		}
 
@@ -859,40 +1510,109 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 			"target":     tName,
 		})
 
+		if plan.AdoptUnmanagedDeployments.ValueBool() {
+			known := append(append([]string{}, prevManagedIDs...), stagedDeployID)
+			for _, id := range adoptDeployments(ctx, eng, t, tName, skillKey, known) {
+				prevManagedIDs = appendUnique(prevManagedIDs, id)
+			}
+		}
+
 		result, deployErr := eng.Deploy(ctx, t, engine.DeployInput{
-			SkillName:        skillName,
+			SkillName:        skillKey,
 			Bundle:           b,
 			CanonicalStore:   r.providerData.CanonicalStore,
-			ProviderVersion:  "dev",
+			ProviderVersion:  r.providerData.Version,
 			ResourceName:     skillName,
-			SourceDir:        sourceDir,
+			SourceDir:        src.sourceDir(),
+			SourceArchive:    src.originOf(sourceKindArchive),
+			SourceURL:        src.originOf(sourceKindURL, sourceKindGit),
 			RegistryInfo:     registryInfo,
 			PreviousDeployID: prevDeployID,
+			StagedDeployID:   stagedDeployID,
+			ActiveETag:       prevActiveETag,
+			ActiveGeneration: prevActiveGeneration,
+			DeploymentAlias:  plan.DeploymentAlias.ValueString(),
+			DependsOnSkills:  dependsOn,
+
+			CleanupOrphans:    plan.CleanupOrphanedDeployments.ValueBool(),
+			OrphanGracePeriod: time.Duration(plan.OrphanGracePeriodSeconds.ValueInt64()) * time.Second,
+			ManagedDeployIDs:  prevManagedIDs,
+			Provenance:        provenanceJSON,
+
+			Workspace:   r.providerData.Workspace,
+			Environment: r.providerData.Environment,
+			ExpiresAt:   previewExpiry(plan, time.Now()),
+			Verify:      verifier(plan),
+
+			CanaryWeight:   canaryWeight(plan),
+			StageOnly:      stageOnly(plan),
+			ReadAfterWrite: r.readAfterWrite(tName),
 		})
+		var stagedErr *engine.StagedError
+		var verifyErr *engine.VerifyError
+		isStaged := errors.As(deployErr, &stagedErr)
+		isRejected := errors.As(deployErr, &verifyErr)
+		if (isStaged || isRejected) && !cleanupPriorSkill {
+			// Keep the prior state, with this target's partial upload or
+			// rejected deployment recorded as staged, so the next apply
+			// retries and removes it.
+			var tsVal types.Object
+			var tsDiags diag.Diagnostics
+			if isRejected {
+				tsVal, tsDiags = verifyFailedTargetState(ctx, priorTargetStates[tName], verifyErr)
+			} else {
+				tsVal, tsDiags = stagedTargetState(ctx, priorTargetStates[tName], stagedErr.DeploymentID)
+			}
+			resp.Diagnostics.Append(tsDiags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			partial := make(map[string]attr.Value, len(priorTargetStates))
+			for pName := range priorTargetStates {
+				if _, ok := resolvedTargetSet[pName]; ok {
+					partial[pName] = priorState.TargetStates.Elements()[pName]
+				}
+			}
+			for pName, v := range targetStates {
+				partial[pName] = v
+			}
+			partial[tName] = tsVal
+			resp.Diagnostics.Append(savePartialState(ctx, &resp.State, priorState, priorSkillName, "", partial)...)
+		}
+		if isRejected {
+			resp.Diagnostics.Append(verifyFailedDiag(skillName, tName, verifyErr))
+			return
+		}
+		if errors.Is(deployErr, engine.ErrActiveModified) {
+			resp.Diagnostics.AddError(
+				errcode.DriftDetected.Summary("ACTIVE Pointer Modified Outside Terraform"),
+				fmt.Sprintf("The ACTIVE pointer for skill %q on target %q changed since Terraform last read it, so it was not overwritten: %s. "+
+					"Run terraform refresh (or terraform apply -refresh-only) to accept the current pointer, then apply again.", skillName, tName, deployErr),
+			)
+			return
+		}
 		if deployErr != nil {
 			resp.Diagnostics.AddError(
-				"Deployment Failed",
+				errcode.DeployFailed.Summary("Deployment Failed"),
 				fmt.Sprintf("Failed to deploy skill %q to target %q: %s", skillName, tName, deployErr),
 			)
 			return
 		}
 
+		logOrphanCleanup(ctx, tName, result)
+		logIndexFailure(ctx, tName, result)
+		logConsistencyFailure(ctx, tName, result)
+
 		if firstDeployID == "" {
 			firstDeployID = result.DeploymentID
 		}
-		deployIDByTarget[tName] = result.DeploymentID
-
-		// Merge managed deploy IDs.
-		var managedIDs []string
-		if !cleanupPriorSkill {
-			if pts, exists := priorTargetStates[tName]; exists {
-				resp.Diagnostics.Append(pts.ManagedDeployIDs.ElementsAs(ctx, &managedIDs, false)...)
-				if resp.Diagnostics.HasError() {
-					return
-				}
-			}
+		// Merge managed deploy IDs. A staged deployment becomes managed
+		// once it is promoted and a refresh sees ACTIVE point at it.
+		managedIDs := prevManagedIDs
+		if !result.Staged {
+			deployIDByTarget[tName] = result.DeploymentID
+			managedIDs = appendUnique(prevManagedIDs, result.DeploymentID)
 		}
-		managedIDs = appendUnique(managedIDs, result.DeploymentID)
 		managedIDsByTarget[tName] = managedIDs
 
 		managedIDsList, idDiags := types.ListValueFrom(ctx, types.StringType, managedIDs)
@@ -901,12 +1621,33 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 			return
 		}
 
+		if result.Staged {
+			// ACTIVE still selects the prior deployment; keep its state
+			// and record the new one as staged, for promotion.
+			var prior TargetStateValue
+			if !cleanupPriorSkill {
+				prior = priorTargetStates[tName]
+			}
+			prior.ManagedDeployIDs = managedIDsList
+			tsVal, tsDiags := stagedTargetState(ctx, prior, result.DeploymentID)
+			resp.Diagnostics.Append(tsDiags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			targetStates[tName] = tsVal
+			continue
+		}
+
 		tsVal, tsDiags := types.ObjectValueFrom(ctx, targetStateAttrTypes(), TargetStateValue{
 			ActiveDeploymentID: types.StringValue(result.DeploymentID),
+			StableDeploymentID: types.StringValue(result.StableDeploymentID),
 			StagedDeploymentID: types.StringValue(""),
 			DeployedBundleHash: types.StringValue(result.BundleHash),
 			LastSyncedAt:       types.StringValue(time.Now().UTC().Format(time.RFC3339)),
 			ManagedDeployIDs:   managedIDsList,
+			ActiveETag:         types.StringValue(result.ActiveETag),
+			ActiveGeneration:   types.Int64Value(result.ActiveGeneration),
+			Stale:              types.BoolValue(false),
 		})
 		resp.Diagnostics.Append(tsDiags...)
 		if resp.Diagnostics.HasError() {
@@ -930,14 +1671,17 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 		plan.ID = priorState.ID
 	}
 
+	// Replicas trail their primary; lag is a warning, not a failure.
+	resp.Diagnostics.Append(r.verifyReplicas(ctx, eng, skillKey, deployIDByTarget)...)
+
 	// 8. Prune old deployments if enabled.
 	if plan.PruneDeployments.ValueBool() {
 		retain := int(plan.RetainDeployments.ValueInt64())
 		for _, tName := range resolvedTargets {
-			t := r.providerData.Targets[tName]
+			t, _ := r.providerData.Targets.Get(tName)
 			activeDeployID := deployIDByTarget[tName]
 			managedIDs := managedIDsByTarget[tName]
-			_, pruneErr := eng.Prune(ctx, t, skillName, activeDeployID, managedIDs, retain)
+			_, pruneErr := eng.Prune(ctx, t, skillKey, activeDeployID, managedIDs, retain)
 			if pruneErr != nil {
 				tflog.Warn(ctx, "prune failed", map[string]interface{}{
 					"target": tName,
@@ -947,6 +1691,11 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 		}
 	}
 
+	// 9. Remove expired previews of the skill if enabled.
+	if plan.CleanupExpiredPreviews.ValueBool() {
+		r.collectExpiredPreviews(ctx, eng, skillName, resolvedTargets)
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
@@ -955,6 +1704,11 @@ func (r *SkillResource) Update(ctx context.Context, req resource.UpdateRequest,
 // --------------------------------------------------------------------------
 
 func (r *SkillResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var state SkillResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -969,29 +1723,44 @@ func (r *SkillResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	}
 
 	// Read prior target states.
-	priorTargetStates := make(map[string]TargetStateValue)
-	if !state.TargetStates.IsNull() && !state.TargetStates.IsUnknown() {
-		priorTSMap := make(map[string]types.Object)
-		resp.Diagnostics.Append(state.TargetStates.ElementsAs(ctx, &priorTSMap, false)...)
+	priorTargetStates, diags := targetStateValues(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eng := engine.New(r.providerData.Scheduler, engine.WithListeners(r.providerData.Listeners...))
+	skillName := storageName(state, state.SkillName.ValueString())
+
+	if r.providerData.DryRunning() {
+		targetChanges, diags := r.dryRunDestroy(ctx, eng, state, skillName, resolvedTargets, priorTargetStates)
+		resp.Diagnostics.Append(diags...)
+		registryChanges, diags := r.dryRunRegistryDestroy(ctx, state)
+		resp.Diagnostics.Append(diags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
-		for k, v := range priorTSMap {
-			var tsv TargetStateValue
-			resp.Diagnostics.Append(v.As(ctx, &tsv, basetypes.ObjectAsOptions{})...)
-			if resp.Diagnostics.HasError() {
-				return
-			}
-			priorTargetStates[k] = tsv
-		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Targets:   targetChanges,
+			Registry:  registryChanges,
+		})...)
+		return
 	}
 
-	eng := engine.New(r.providerData.Semaphore)
-	skillName := state.SkillName.ValueString()
-
-	// 1. Destroy from each target.
+	// 1. Destroy from each target. References are checked on every target
+	// first: Destroy checks only the target it removes from, so a reference
+	// on a later target would leave the earlier ones already destroyed.
+	if !state.ForceDestroy.ValueBool() {
+		resp.Diagnostics.Append(checkDestroyReferences(ctx, eng, r.providerData.Targets, skillName, resolvedTargets)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 	for _, tName := range resolvedTargets {
-		t, ok := r.providerData.Targets[tName]
+		t, ok := r.providerData.Targets.Get(tName)
 		if !ok {
 			tflog.Warn(ctx, "target no longer configured, skipping destroy", map[string]interface{}{
 				"target": tName,
@@ -1002,10 +1771,12 @@ func (r *SkillResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		var managedIDs []string
 		var activeDeployID string
 		if pts, exists := priorTargetStates[tName]; exists {
-			resp.Diagnostics.Append(pts.ManagedDeployIDs.ElementsAs(ctx, &managedIDs, false)...)
+			ids, idDiags := destroyDeployIDs(ctx, pts)
+			resp.Diagnostics.Append(idDiags...)
 			if resp.Diagnostics.HasError() {
 				return
 			}
+			managedIDs = ids
 			activeDeployID = pts.ActiveDeploymentID.ValueString()
 		}
 
@@ -1020,20 +1791,28 @@ func (r *SkillResource) Delete(ctx context.Context, req resource.DeleteRequest,
 			ManagedDeployIDs:         managedIDs,
 			ActiveDeployID:           activeDeployID,
 		})
+		if errors.Is(destroyErr, engine.ErrReferenced) {
+			resp.Diagnostics.Append(referencedDiag(skillName, tName, destroyErr))
+			return
+		}
 		if destroyErr != nil {
 			resp.Diagnostics.AddError(
-				"Destroy Failed",
+				errcode.DestroyFailed.Summary("Destroy Failed"),
 				fmt.Sprintf("Failed to destroy skill %q from target %q: %s", skillName, tName, destroyErr),
 			)
 			return
 		}
 	}
 
-	// 2. If anthropic integration is enabled and destroy_remote, delete
-	// managed versions first, then delete the skill if no versions remain.
-	// Per spec §12.2, the API requires all versions to be deleted before
-	// the skill can be deleted.
-	if r.providerData.Anthropic != nil && r.providerData.Anthropic.DestroyRemote() {
+	// 2. Unless the skill is detached (on_destroy, or destroy_remote when
+	// on_destroy is unset), delete the versions this resource created
+	// first, then delete the skill if no versions remain. Per spec §12.2, the API requires all
+	// versions to be deleted before the skill can be deleted.
+	var onDestroy string
+	if len(state.Anthropic) == 1 {
+		onDestroy = state.Anthropic[0].OnDestroy.ValueString()
+	}
+	if r.providerData.Anthropic != nil && r.providerData.Anthropic.DeleteOnDestroy(onDestroy) {
 		if !state.RegistryState.IsNull() && !state.RegistryState.IsUnknown() {
 			var rsv RegistryStateValue
 			resp.Diagnostics.Append(state.RegistryState.As(ctx, &rsv, basetypes.ObjectAsOptions{})...)
@@ -1043,26 +1822,24 @@ func (r *SkillResource) Delete(ctx context.Context, req resource.DeleteRequest,
 
 			skillID := rsv.SkillID.ValueString()
 			if skillID != "" {
-				// Delete all versions managed by this resource.
-				versions, listErr := r.providerData.Anthropic.ListVersions(ctx, skillID)
-				if listErr != nil {
-					tflog.Warn(ctx, "failed to list versions for cleanup", map[string]interface{}{
+				// Delete the versions this resource created, or every
+				// version with destroy_all_versions.
+				versions, vDiags := r.destroyVersions(ctx, state, rsv)
+				resp.Diagnostics.Append(vDiags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				for _, version := range versions {
+					tflog.Info(ctx, "deleting skill version from Anthropic registry", map[string]interface{}{
 						"skill_id": skillID,
-						"error":    listErr.Error(),
+						"version":  version,
 					})
-				} else {
-					for _, v := range versions {
-						tflog.Info(ctx, "deleting skill version from Anthropic registry", map[string]interface{}{
+					if delErr := r.providerData.Anthropic.DeleteVersion(ctx, skillID, version); delErr != nil {
+						tflog.Warn(ctx, "failed to delete version", map[string]interface{}{
 							"skill_id": skillID,
-							"version":  v.Version,
+							"version":  version,
+							"error":    delErr.Error(),
 						})
-						if delErr := r.providerData.Anthropic.DeleteVersion(ctx, skillID, v.Version); delErr != nil {
-							tflog.Warn(ctx, "failed to delete version", map[string]interface{}{
-								"skill_id": skillID,
-								"version":  v.Version,
-								"error":    delErr.Error(),
-							})
-						}
 					}
 				}
 
@@ -1074,17 +1851,23 @@ func (r *SkillResource) Delete(ctx context.Context, req resource.DeleteRequest,
 						"error":    remainErr.Error(),
 					})
 				} else if len(remaining) > 0 {
-					tflog.Warn(ctx, "skill has versions not managed by Terraform, skipping skill deletion", map[string]interface{}{
-						"skill_id":           skillID,
-						"remaining_versions": len(remaining),
-					})
+					remainingVersions := make([]string, len(remaining))
+					for i, v := range remaining {
+						remainingVersions[i] = v.Version
+					}
+					resp.Diagnostics.AddWarning(
+						errcode.AnthropicRequestFailed.Summary("Anthropic Skill Not Deleted"),
+						fmt.Sprintf("Skill %q was left in the Anthropic registry because it has versions this resource did not create: %s. "+
+							"Delete them separately, or set destroy_all_versions = true in the anthropic block to delete every version on destroy.",
+							skillID, strings.Join(remainingVersions, ", ")),
+					)
 				} else {
 					tflog.Info(ctx, "deleting skill from Anthropic registry", map[string]interface{}{
 						"skill_id": skillID,
 					})
 					if err := r.providerData.Anthropic.DeleteSkill(ctx, skillID); err != nil {
 						resp.Diagnostics.AddError(
-							"Anthropic Delete Skill Failed",
+							errcode.AnthropicRequestFailed.Summary("Anthropic Delete Skill Failed"),
 							fmt.Sprintf("Failed to delete skill %q from Anthropic registry: %s", skillID, err),
 						)
 						return
@@ -1126,6 +1909,18 @@ func (r *SkillResource) resolveTargets(ctx context.Context, model SkillResourceM
 			// We cannot distinguish these in terraform-plugin-framework with
 			// a ListDefault, so we treat empty as "omitted" and fall through.
 		} else {
+			for _, tName := range explicit {
+				if cfg, ok := r.providerData.Targets.Config(tName); ok && cfg.ReplicaOf.ValueString() != "" {
+					diags.AddError(
+						errcode.InvalidConfig.Summary("Replica Target Not Writable"),
+						fmt.Sprintf("Target %q is a replica of %q and is never written to. List %q instead; "+
+							"its replicas are verified after every deploy.", tName, cfg.ReplicaOf.ValueString(), cfg.ReplicaOf.ValueString()),
+					)
+				}
+			}
+			if diags.HasError() {
+				return nil, diags
+			}
 			return explicit, diags
 		}
 	}
@@ -1135,24 +1930,422 @@ func (r *SkillResource) resolveTargets(ctx context.Context, model SkillResourceM
 		return r.providerData.DefaultTargets, diags
 	}
 
-	// Implicit single target: only if exactly 1 target is configured.
-	if len(r.providerData.Targets) == 1 {
-		all := make([]string, 0, 1)
-		for name := range r.providerData.Targets {
-			all = append(all, name)
-		}
-		return all, diags
+	if r.providerData.Targets.Len() == 0 {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Missing Target Configuration"),
+			"agentctx_skill deploys to storage targets, but the provider has no target blocks. "+
+				"Add a target block, or use agentctx_anthropic_skill to manage a registry-only skill.",
+		)
+		return nil, diags
+	}
+
+	// Implicit single target: only if exactly 1 writable target is
+	// configured. Replicas of it do not count.
+	if writable := r.writableTargets(); len(writable) == 1 {
+		return writable, diags
 	}
 
 	// 2+ targets, no default_targets, resource omits targets → error per §3.2.
 	diags.AddError(
-		"Ambiguous Target Configuration",
+		errcode.InvalidConfig.Summary("Ambiguous Target Configuration"),
 		"Multiple targets are configured in the provider but neither `default_targets` on the provider nor `targets` on the resource is set. "+
 			"Set `default_targets` on the provider or specify `targets` on the resource.",
 	)
 	return nil, diags
 }
 
+// targetStateValues decodes the target_states map of model, keyed by target
+// name. A null or unknown map yields an empty result.
+func targetStateValues(ctx context.Context, model SkillResourceModel) (map[string]TargetStateValue, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	values := make(map[string]TargetStateValue)
+	if model.TargetStates.IsNull() || model.TargetStates.IsUnknown() {
+		return values, diags
+	}
+
+	objects := make(map[string]types.Object)
+	diags.Append(model.TargetStates.ElementsAs(ctx, &objects, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	for k, v := range objects {
+		var tsv TargetStateValue
+		diags.Append(v.As(ctx, &tsv, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		values[k] = tsv
+	}
+
+	return values, diags
+}
+
+// createVersionRequest builds the CreateVersion parameters for skillID from
+// the anthropic block's version_notes and version_labels.
+func createVersionRequest(ctx context.Context, cfg AnthropicBlockModel, skillID, bundleHash string) (anthropic.CreateVersionRequest, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	req := anthropic.CreateVersionRequest{
+		IdempotencyKey: anthropic.IdempotencyKey(skillID, bundleHash),
+		Notes:          cfg.VersionNotes.ValueString(),
+	}
+	if !cfg.VersionLabels.IsNull() && !cfg.VersionLabels.IsUnknown() {
+		diags.Append(cfg.VersionLabels.ElementsAs(ctx, &req.Labels, false)...)
+	}
+	return req, diags
+}
+
+// staleTargetState returns prior marked as stale. A target without prior
+// state gets empty values, so its next successful refresh fills them in.
+func staleTargetState(ctx context.Context, prior TargetStateValue) (types.Object, diag.Diagnostics) {
+	stale := TargetStateValue{
+		ActiveDeploymentID: types.StringValue(prior.ActiveDeploymentID.ValueString()),
+		StableDeploymentID: types.StringValue(prior.StableDeploymentID.ValueString()),
+		StagedDeploymentID: types.StringValue(prior.StagedDeploymentID.ValueString()),
+		DeployedBundleHash: types.StringValue(prior.DeployedBundleHash.ValueString()),
+		LastSyncedAt:       types.StringValue(prior.LastSyncedAt.ValueString()),
+		ManagedDeployIDs:   prior.ManagedDeployIDs,
+		ActiveETag:         types.StringValue(prior.ActiveETag.ValueString()),
+		ActiveGeneration:   types.Int64Value(prior.ActiveGeneration.ValueInt64()),
+		Stale:              types.BoolValue(true),
+	}
+	if stale.ManagedDeployIDs.IsNull() || stale.ManagedDeployIDs.IsUnknown() {
+		stale.ManagedDeployIDs = types.ListValueMust(types.StringType, []attr.Value{})
+	}
+	return types.ObjectValueFrom(ctx, targetStateAttrTypes(), stale)
+}
+
+// stagedTargetState returns prior with stagedID recorded as its staged
+// deployment. A target without prior state gets empty values otherwise.
+func stagedTargetState(ctx context.Context, prior TargetStateValue, stagedID string) (types.Object, diag.Diagnostics) {
+	staged := TargetStateValue{
+		ActiveDeploymentID: types.StringValue(prior.ActiveDeploymentID.ValueString()),
+		StableDeploymentID: types.StringValue(prior.StableDeploymentID.ValueString()),
+		StagedDeploymentID: types.StringValue(stagedID),
+		DeployedBundleHash: types.StringValue(prior.DeployedBundleHash.ValueString()),
+		LastSyncedAt:       types.StringValue(prior.LastSyncedAt.ValueString()),
+		ManagedDeployIDs:   prior.ManagedDeployIDs,
+		ActiveETag:         types.StringValue(prior.ActiveETag.ValueString()),
+		ActiveGeneration:   types.Int64Value(prior.ActiveGeneration.ValueInt64()),
+		Stale:              types.BoolValue(prior.Stale.ValueBool()),
+	}
+	if staged.ManagedDeployIDs.IsNull() || staged.ManagedDeployIDs.IsUnknown() {
+		staged.ManagedDeployIDs = types.ListValueMust(types.StringType, []attr.Value{})
+	}
+	return types.ObjectValueFrom(ctx, targetStateAttrTypes(), staged)
+}
+
+// verifyFailedTargetState returns prior with the deployment err rejected
+// recorded as staged. When ACTIVE was rolled back, the restored pointer's
+// metadata replaces prior's, so the next apply's conditional write matches
+// it.
+func verifyFailedTargetState(ctx context.Context, prior TargetStateValue, err *engine.VerifyError) (types.Object, diag.Diagnostics) {
+	if err.RolledBack {
+		prior.ActiveETag = types.StringValue(err.ActiveETag)
+		prior.ActiveGeneration = types.Int64Value(err.ActiveGeneration)
+	}
+	return stagedTargetState(ctx, prior, err.DeploymentID)
+}
+
+// savePartialState writes model to state with targetStates as its
+// target_states, after a deploy failed part way. Recording staged
+// deployments lets the next apply, or a destroy, remove them instead of
+// leaving objects on the target that no state refers to.
+func savePartialState(ctx context.Context, state *tfsdk.State, model SkillResourceModel, skillName, firstDeployID string, targetStates map[string]attr.Value) diag.Diagnostics {
+	tsMap, diags := types.MapValue(types.ObjectType{AttrTypes: targetStateAttrTypes()}, targetStates)
+	if diags.HasError() {
+		return diags
+	}
+	model.TargetStates = tsMap
+	if model.ID.IsNull() || model.ID.IsUnknown() {
+		model.ID = types.StringValue(resourceID(skillName, firstDeployID))
+	}
+	diags.Append(state.Set(ctx, &model)...)
+	return diags
+}
+
+// setSkillFrontmatter records the SKILL.md frontmatter of b in plan's
+// skill_description and skill_metadata.
+func setSkillFrontmatter(plan *SkillResourceModel, b *bundle.Bundle) {
+	if b.Skill == nil {
+		plan.SkillDescription = types.StringNull()
+		plan.SkillMetadata = types.MapNull(types.StringType)
+		return
+	}
+
+	if b.Skill.Description != "" {
+		plan.SkillDescription = types.StringValue(b.Skill.Description)
+	} else {
+		plan.SkillDescription = types.StringNull()
+	}
+
+	fields := make(map[string]attr.Value, len(b.Skill.Fields))
+	for k, v := range b.Skill.Fields {
+		fields[k] = types.StringValue(v)
+	}
+	plan.SkillMetadata = types.MapValueMust(types.StringType, fields)
+}
+
+// checkSkillName returns an error if the SKILL.md frontmatter of b sets a
+// name that is not a valid skill name. A bundle without one is deployed
+// under its directory name and is not checked.
+func checkSkillName(b *bundle.Bundle) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if b.Skill == nil || b.Skill.Name == "" {
+		return diags
+	}
+	if err := validators.CheckName(b.Skill.Name); err != nil {
+		diags.AddError(errcode.InvalidBundle.Summary("Invalid Skill Name"),
+			fmt.Sprintf("The name in the %s frontmatter is invalid: %s", bundle.SkillFile, err))
+	}
+	return diags
+}
+
+// bundleDescriptor returns the bundle_json of b, scanned from src. It is
+// null for sources other than source_dir: the descriptor names the
+// directory its files are copied from, and their temporary directory does
+// not outlive the apply.
+func bundleDescriptor(b *bundle.Bundle, src *skillSource) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if src.kind != sourceKindDir {
+		return types.StringNull(), diags
+	}
+	bundleJSON, err := b.MarshalDescriptor()
+	if err != nil {
+		diags.AddError(errcode.InvalidBundle.Summary("Bundle Descriptor Failed"), fmt.Sprintf("Failed to build bundle descriptor for %q: %s", src.path, err))
+		return types.StringNull(), diags
+	}
+	return types.StringValue(bundleJSON), diags
+}
+
+// buildProvenance renders the provenance statement for b, scanned from src,
+// when provenance is enabled and records it in plan.ProvenanceJSON. It
+// returns nil when provenance is disabled.
+func (r *SkillResource) buildProvenance(plan *SkillResourceModel, b *bundle.Bundle, src *skillSource, excludes []string) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !plan.Provenance.ValueBool() {
+		plan.ProvenanceJSON = types.StringNull()
+		return nil, diags
+	}
+
+	// Other sources are read from a temporary directory, so their origin
+	// is recorded instead. Only local paths can resolve to a git
+	// repository.
+	sourcePath := src.origin
+	var sourcePaths []string
+	switch src.kind {
+	case sourceKindDir:
+		sourceDir, err := filepath.Abs(b.SourceDir)
+		if err != nil {
+			diags.AddError(errcode.PathResolution.Summary("Path Resolution Failed"), fmt.Sprintf("Failed to resolve absolute path for %q: %s", b.SourceDir, err))
+			return nil, diags
+		}
+		sourcePath = sourceDir
+		sourcePaths = []string{sourceDir}
+	case sourceKindArchive:
+		sourcePaths = []string{sourcePath}
+	}
+	if excludes == nil {
+		excludes = []string{}
+	}
+	data, err := provenance.Generate(provenance.Input{
+		BuildType: provenance.BuildTypeSkill,
+		Files:     b.FileHashes,
+		Parameters: map[string]any{
+			"skill_name":  src.name,
+			src.kind:      sourcePath,
+			"exclude":     excludes,
+			"bundle_hash": b.BundleHash,
+		},
+		SourcePaths:     sourcePaths,
+		ProviderVersion: r.providerData.Version,
+	})
+	if err != nil {
+		diags.AddError(errcode.Encoding.Summary("Provenance Generation Failed"), fmt.Sprintf("Failed to generate provenance for %q: %s", src.name, err))
+		return nil, diags
+	}
+	plan.ProvenanceJSON = types.StringValue(string(data))
+	return data, diags
+}
+
+// resourceID returns the resource ID for skillName whose first target got
+// deployment firstDeployID, which may be empty.
+func resourceID(skillName, firstDeployID string) string {
+	if firstDeployID == "" {
+		return skillName
+	}
+	return skillName + ":" + firstDeployID
+}
+
+// destroyDeployIDs returns the deployments Destroy should remove for a
+// target: the managed ones plus any staged one.
+func destroyDeployIDs(ctx context.Context, ts TargetStateValue) ([]string, diag.Diagnostics) {
+	var ids []string
+	diags := ts.ManagedDeployIDs.ElementsAs(ctx, &ids, false)
+	if staged := ts.StagedDeploymentID.ValueString(); staged != "" {
+		ids = appendUnique(ids, staged)
+	}
+	return ids, diags
+}
+
+// checkDestroyReferences returns an error for each of targetNames on which
+// consumers hold reference markers on skillName. Targets that are no longer
+// configured are skipped, as Delete skips them.
+func checkDestroyReferences(ctx context.Context, eng *engine.Engine, targets providerdata.TargetRegistry, skillName string, targetNames []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, tName := range targetNames {
+		t, ok := targets.Get(tName)
+		if !ok {
+			continue
+		}
+		consumers, err := eng.References(ctx, t, skillName)
+		if err != nil {
+			diags.AddError(
+				errcode.DestroyFailed.Summary("Destroy Failed"),
+				fmt.Sprintf("Failed to list the references to skill %q on target %q: %s", skillName, tName, err),
+			)
+			continue
+		}
+		if len(consumers) > 0 {
+			diags.Append(referencedDiag(skillName, tName, fmt.Errorf("%w: %s", engine.ErrReferenced, strings.Join(consumers, ", "))))
+		}
+	}
+	return diags
+}
+
+// referencedDiag explains a destroy of skillName on tName that Destroy
+// refused because consumers hold reference markers on it.
+func referencedDiag(skillName, tName string, err error) diag.Diagnostic {
+	return diag.NewErrorDiagnostic(
+		errcode.SkillReferenced.Summary("Skill Still Referenced"),
+		fmt.Sprintf("Refusing to destroy skill %q on target %q: %s.\n\n"+
+			"Other stacks declared that they depend on this skill with agentctx_skill_verification. "+
+			"Destroy those resources first, or set force_destroy = true to remove the skill and its reference markers anyway.",
+			skillName, tName, err),
+	)
+}
+
+// skipRegistry reports whether a registry request that failed with err is
+// skipped under the provider's registry_failure_policy, and if so adds a
+// warning saying that skillName was deployed to storage only.
+func (r *SkillResource) skipRegistry(diags *diag.Diagnostics, skillName string, err error) bool {
+	if !r.providerData.Anthropic.SkipOnFailure(err) {
+		return false
+	}
+	diags.AddWarning(
+		errcode.RegistrySkipped.Summary("Registry Unavailable"),
+		fmt.Sprintf("Skill %q is deployed to its storage targets without registering it with the Anthropic registry: %s.\n\n"+
+			"The provider's registry_failure_policy is \"warn_and_skip\". The next apply retries the registration.", skillName, err),
+	)
+	return true
+}
+
+// logOrphanCleanup logs what orphan cleanup did before a deploy to tName.
+// Like pruning, a failed cleanup is only worth a warning in the log.
+func logOrphanCleanup(ctx context.Context, tName string, result *engine.DeployResult) {
+	if len(result.OrphansRemoved) > 0 {
+		tflog.Info(ctx, "removed orphaned deployments", map[string]interface{}{
+			"target":      tName,
+			"deployments": result.OrphansRemoved,
+		})
+	}
+	if result.OrphanErr != nil {
+		tflog.Warn(ctx, "orphan cleanup failed", map[string]interface{}{
+			"target": tName,
+			"error":  result.OrphanErr.Error(),
+		})
+	}
+}
+
+// adoptDeployments returns the deployments of skillKey on t, the target
+// tName, that known does not list and whose manifests check out, for the
+// resource to manage from now on. Rejected deployments are logged and left
+// alone. Like orphan cleanup, adoption is best-effort: a target that cannot
+// be listed is logged and adopts nothing.
+func adoptDeployments(ctx context.Context, eng *engine.Engine, t target.Target, tName, skillKey string, known []string) []string {
+	adopted, rejected, err := eng.AdoptableDeployments(ctx, t, skillKey, known)
+	if err != nil {
+		tflog.Warn(ctx, "deployment adoption failed", map[string]interface{}{
+			"target": tName,
+			"error":  err.Error(),
+		})
+		return nil
+	}
+	for _, rd := range rejected {
+		tflog.Warn(ctx, "unmanaged deployment not adopted", map[string]interface{}{
+			"target":        tName,
+			"deployment_id": rd.DeploymentID,
+			"reason":        rd.Reason,
+		})
+	}
+	if len(adopted) > 0 {
+		tflog.Info(ctx, "adopted unmanaged deployments", map[string]interface{}{
+			"target":      tName,
+			"deployments": adopted,
+		})
+	}
+	return adopted
+}
+
+// logIndexFailure logs a deploy to tName that could neither record itself
+// in the skill's deployment index nor remove the stale index. Listings of
+// the skill's deployments may miss it until the next deploy.
+func logIndexFailure(ctx context.Context, tName string, result *engine.DeployResult) {
+	if result.IndexErr != nil {
+		tflog.Warn(ctx, "deployment index update failed", map[string]interface{}{
+			"target": tName,
+			"error":  result.IndexErr.Error(),
+		})
+	}
+}
+
+// logConsistencyFailure logs a deploy to tName whose new ACTIVE pointer
+// reads did not show within the target's read_after_write_seconds. The
+// next refresh retries for as long before reporting drift.
+func logConsistencyFailure(ctx context.Context, tName string, result *engine.DeployResult) {
+	if result.ConsistencyErr != nil {
+		tflog.Warn(ctx, "deployment not yet visible on target", map[string]interface{}{
+			"target": tName,
+			"error":  result.ConsistencyErr.Error(),
+		})
+	}
+}
+
+// readAfterWrite returns how long reads from target tName may trail its
+// writes, per its read_after_write_seconds.
+func (r *SkillResource) readAfterWrite(tName string) time.Duration {
+	cfg, _ := r.providerData.Targets.Config(tName)
+	return cfg.ReadAfterWrite()
+}
+
+// describeDrift returns one human-readable line per way the refreshed target
+// differs from what this resource last deployed. It returns nil when the
+// target is in sync.
+func describeDrift(tName string, result *engine.RefreshResult, expectedHash string) []string {
+	if result.ActiveDeploymentID == "" {
+		return []string{fmt.Sprintf("target %q: ACTIVE pointer is missing", tName)}
+	}
+
+	var details []string
+	if result.Drifted && result.Manifest != nil {
+		details = append(details, fmt.Sprintf(
+			"target %q: deployment %q has bundle hash %s, expected %s",
+			tName, result.ActiveDeploymentID, result.Manifest.BundleHash, expectedHash,
+		))
+	}
+	if len(result.MissingFiles) > 0 {
+		missing := append([]string(nil), result.MissingFiles...)
+		sort.Strings(missing)
+		details = append(details, fmt.Sprintf(
+			"target %q: deployment %q is missing %d file(s): %s",
+			tName, result.ActiveDeploymentID, len(missing), strings.Join(missing, ", "),
+		))
+	}
+	return details
+}
+
 // appendUnique appends s to the slice only if it is not already present.
 func appendUnique(slice []string, s string) []string {
 	for _, existing := range slice {
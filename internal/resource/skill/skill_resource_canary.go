@@ -0,0 +1,10 @@
+package skill
+
+// canaryWeight returns the engine.DeployInput.CanaryWeight for m: the
+// weight of its canary block, or 0 to write a plain ACTIVE pointer.
+func canaryWeight(m SkillResourceModel) int {
+	if len(m.Canary) == 0 || m.Canary[0].Weight.IsNull() || m.Canary[0].Weight.IsUnknown() {
+		return 0
+	}
+	return int(m.Canary[0].Weight.ValueInt64())
+}
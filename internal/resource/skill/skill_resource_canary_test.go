@@ -0,0 +1,17 @@
+package skill
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCanaryWeight(t *testing.T) {
+	if got := canaryWeight(SkillResourceModel{}); got != 0 {
+		t.Errorf("canaryWeight without a canary block = %d, want 0", got)
+	}
+	m := SkillResourceModel{Canary: []CanaryBlockModel{{Weight: types.Int64Value(10)}}}
+	if got := canaryWeight(m); got != 10 {
+		t.Errorf("canaryWeight = %d, want 10", got)
+	}
+}
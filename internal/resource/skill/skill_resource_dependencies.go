@@ -0,0 +1,41 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// dependsOnSkills returns the engine.DeployInput.DependsOnSkills for m. A
+// skill that depends on its own skill_name is an error: it would always
+// find its dependency and so never detect a missing one. The check is
+// skipped while skill_name is unknown.
+func dependsOnSkills(ctx context.Context, m SkillResourceModel) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if m.DependsOnSkills.IsNull() || m.DependsOnSkills.IsUnknown() {
+		return nil, diags
+	}
+
+	var refs []string
+	diags.Append(m.DependsOnSkills.ElementsAs(ctx, &refs, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	if name := m.SkillName.ValueString(); name != "" {
+		if i := slices.Index(refs, name); i >= 0 {
+			diags.AddAttributeError(
+				path.Root("depends_on_skills").AtListIndex(i),
+				errcode.InvalidConfig.Summary("Skill Depends On Itself"),
+				fmt.Sprintf("depends_on_skills lists %q, the skill's own name. List only the other skills it needs.", name),
+			)
+			return nil, diags
+		}
+	}
+	return refs, diags
+}
@@ -0,0 +1,49 @@
+package skill
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDependsOnSkills(t *testing.T) {
+	ctx := context.Background()
+	list := func(refs ...string) types.List {
+		values := make([]attr.Value, len(refs))
+		for i, ref := range refs {
+			values[i] = types.StringValue(ref)
+		}
+		return types.ListValueMust(types.StringType, values)
+	}
+
+	refs, diags := dependsOnSkills(ctx, SkillResourceModel{DependsOnSkills: types.ListNull(types.StringType)})
+	if diags.HasError() || refs != nil {
+		t.Errorf("null depends_on_skills = %v, %v; want none", refs, diags)
+	}
+
+	m := SkillResourceModel{
+		SkillName:       types.StringValue("code-review"),
+		DependsOnSkills: list("style-guide", "skill_01abc"),
+	}
+	refs, diags = dependsOnSkills(ctx, m)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if want := []string{"style-guide", "skill_01abc"}; !slices.Equal(refs, want) {
+		t.Errorf("dependsOnSkills = %v, want %v", refs, want)
+	}
+
+	m.DependsOnSkills = list("style-guide", "code-review")
+	if _, diags := dependsOnSkills(ctx, m); !diags.HasError() {
+		t.Error("expected an error for a skill that depends on itself")
+	}
+
+	// Before the source is scanned the skill name is unknown.
+	m.SkillName = types.StringUnknown()
+	if _, diags := dependsOnSkills(ctx, m); diags.HasError() {
+		t.Errorf("unexpected error with an unknown skill name: %v", diags)
+	}
+}
@@ -0,0 +1,188 @@
+package skill
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+)
+
+// dryRunDeploy returns what deploying input to each of targets would
+// change, compared with the deployment ACTIVE points to. A target that
+// cannot be read records the error instead.
+func (r *SkillResource) dryRunDeploy(ctx context.Context, eng *engine.Engine, targets []string, input engine.DeployInput) []dryrun.TargetChange {
+	changes := make([]dryrun.TargetChange, 0, len(targets))
+	for _, tName := range targets {
+		change := dryrun.TargetChange{Target: tName, BundleHash: input.Bundle.BundleHash}
+		t, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			change.Error = "target is not defined in the provider"
+			changes = append(changes, change)
+			continue
+		}
+		plan, err := eng.PlanDeploy(ctx, t, input)
+		if err != nil {
+			change.Error = err.Error()
+			changes = append(changes, change)
+			continue
+		}
+		change.ActiveDeploymentID = plan.ActiveDeploymentID
+		change.Added = plan.Added
+		change.Modified = plan.Modified
+		change.Removed = plan.Removed
+		change.Unchanged = plan.Unchanged
+		change.Uploads = plan.Uploads
+		change.UploadBytes = plan.UploadBytes
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// dryRunDestroy returns the object keys destroying skillKey from each of
+// targets would remove, with the destroy options of m and the deployments
+// recorded in targetStates.
+func (r *SkillResource) dryRunDestroy(ctx context.Context, eng *engine.Engine, m SkillResourceModel, skillKey string, targets []string, targetStates map[string]TargetStateValue) ([]dryrun.TargetChange, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	changes := make([]dryrun.TargetChange, 0, len(targets))
+	for _, tName := range targets {
+		change := dryrun.TargetChange{Target: tName}
+		t, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			// Delete skips targets that are no longer configured.
+			continue
+		}
+
+		var managedIDs []string
+		var activeDeployID string
+		if pts, exists := targetStates[tName]; exists {
+			ids, idDiags := destroyDeployIDs(ctx, pts)
+			diags.Append(idDiags...)
+			if diags.HasError() {
+				return nil, diags
+			}
+			managedIDs = ids
+			activeDeployID = pts.ActiveDeploymentID.ValueString()
+		}
+
+		keys, err := eng.PreviewDestroy(ctx, t, skillKey, engine.DestroyOptions{
+			ForceDestroy:             m.ForceDestroy.ValueBool(),
+			ForceDestroySharedPrefix: m.ForceDestroySharedPrefix.ValueBool(),
+			ManagedDeployIDs:         managedIDs,
+			ActiveDeployID:           activeDeployID,
+		})
+		if err != nil {
+			change.Error = err.Error()
+		}
+		change.Deletes = keys
+		changes = append(changes, change)
+	}
+	return changes, diags
+}
+
+// dryRunRegistry returns the registry requests Create or Update would send
+// for the anthropic block of plan: registering the skill, updating skillID
+// when it is already registered, and creating a version when newVersion is
+// set and auto_version is on.
+func dryRunRegistry(plan SkillResourceModel, skillID string, newVersion bool) []dryrun.RegistryChange {
+	if len(plan.Anthropic) != 1 || !plan.Anthropic[0].Enabled.ValueBool() || !plan.Anthropic[0].Register.ValueBool() {
+		return nil
+	}
+	changes := []dryrun.RegistryChange{{Action: dryrun.RegistryCreateSkill}}
+	if skillID != "" {
+		changes[0] = dryrun.RegistryChange{Action: dryrun.RegistryUpdateSkill, SkillID: skillID}
+	}
+	if newVersion && plan.Anthropic[0].AutoVersion.ValueBool() {
+		changes = append(changes, dryrun.RegistryChange{Action: dryrun.RegistryCreateVersion, SkillID: skillID})
+	}
+	return changes
+}
+
+// dryRunRegistryDestroy returns the registry requests Delete would send for
+// state: deleting the versions selected by destroyVersions, then the skill.
+func (r *SkillResource) dryRunRegistryDestroy(ctx context.Context, state SkillResourceModel) ([]dryrun.RegistryChange, diag.Diagnostics) {
+	var onDestroy string
+	if len(state.Anthropic) == 1 {
+		onDestroy = state.Anthropic[0].OnDestroy.ValueString()
+	}
+	if r.providerData.Anthropic == nil || !r.providerData.Anthropic.DeleteOnDestroy(onDestroy) {
+		return nil, nil
+	}
+	rsv, ok, diags := registryStateValue(ctx, state)
+	if !ok || rsv.SkillID.ValueString() == "" {
+		return nil, diags
+	}
+
+	skillID := rsv.SkillID.ValueString()
+	versions, d := r.destroyVersions(ctx, state, rsv)
+	diags.Append(d...)
+	var changes []dryrun.RegistryChange
+	for _, version := range versions {
+		changes = append(changes, dryrun.RegistryChange{Action: dryrun.RegistryDeleteVersion, SkillID: skillID, Version: version})
+	}
+	return append(changes, dryrun.RegistryChange{Action: dryrun.RegistryDeleteSkill, SkillID: skillID}), diags
+}
+
+// dryRunUpdateInput holds what Update computed before it writes anything.
+type dryRunUpdateInput struct {
+	targets        []string
+	skillKey       string
+	priorSkillKey  string
+	bundle         *bundle.Bundle
+	src            *skillSource
+	provenanceJSON []byte
+	// newVersion is set when Update would create a registry version.
+	newVersion bool
+}
+
+// dryRunUpdate records the changes Update would make in the dry-run report:
+// removing the skill from targets it no longer manages, or from every
+// target when its storage name changed, redeploying it, and the registry
+// requests.
+func (r *SkillResource) dryRunUpdate(ctx context.Context, eng *engine.Engine, plan, priorState SkillResourceModel, in dryRunUpdateInput) diag.Diagnostics {
+	var diags diag.Diagnostics
+	priorTargetStates, d := targetStateValues(ctx, priorState)
+	diags.Append(d...)
+	priorRegistry, _, d := registryStateValue(ctx, priorState)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	cleanupSkill := in.priorSkillKey
+	if cleanupSkill == "" {
+		cleanupSkill = in.skillKey
+	}
+	var cleanupTargets []string
+	for tName := range priorTargetStates {
+		if cleanupSkill != in.skillKey || !slices.Contains(in.targets, tName) {
+			cleanupTargets = append(cleanupTargets, tName)
+		}
+	}
+	sort.Strings(cleanupTargets)
+	targetChanges, d := r.dryRunDestroy(ctx, eng, plan, cleanupSkill, cleanupTargets, priorTargetStates)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	targetChanges = append(targetChanges, r.dryRunDeploy(ctx, eng, in.targets, engine.DeployInput{
+		SkillName:  in.skillKey,
+		Bundle:     in.bundle,
+		SourceDir:  in.src.sourceDir(),
+		Provenance: in.provenanceJSON,
+		StageOnly:  stageOnly(plan),
+	})...)
+	diags.Append(r.providerData.RecordDryRun(dryrun.Change{
+		Resource:  "agentctx_skill",
+		Operation: dryrun.OpUpdate,
+		ID:        priorState.ID.ValueString(),
+		Targets:   targetChanges,
+		Registry:  dryRunRegistry(plan, priorRegistry.SkillID.ValueString(), in.newVersion),
+	})...)
+	return diags
+}
@@ -2,6 +2,7 @@ package skill
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
 )
 
 // ImportState implements resource.ResourceWithImportState. It supports three
@@ -26,66 +28,9 @@ import (
 //   - Combined:       "skill_01AbCdEf...,target:shared_s3:dep_..."
 //     Processes each segment independently.
 func (r *SkillResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	segments := strings.Split(req.ID, ",")
-
-	var (
-		skillID      string
-		targetImports []targetImport
-	)
-
-	for _, seg := range segments {
-		seg = strings.TrimSpace(seg)
-		if seg == "" {
-			continue
-		}
-
-		switch {
-		case strings.HasPrefix(seg, "skill_"):
-			// Skill import: the entire segment is the skill ID.
-			if skillID != "" {
-				resp.Diagnostics.AddError(
-					"Invalid Import ID",
-					"Only one skill ID may be specified in the import string.",
-				)
-				return
-			}
-			skillID = seg
-
-		case strings.HasPrefix(seg, "target:"):
-			// Target import: "target:<name>:<deploy_id>"
-			parts := strings.SplitN(seg, ":", 3)
-			if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
-				resp.Diagnostics.AddError(
-					"Invalid Import ID",
-					fmt.Sprintf(
-						"Target import segment %q must be in the format \"target:<target_name>:<deployment_id>\".",
-						seg,
-					),
-				)
-				return
-			}
-			targetImports = append(targetImports, targetImport{
-				targetName:   parts[1],
-				deploymentID: parts[2],
-			})
-
-		default:
-			resp.Diagnostics.AddError(
-				"Invalid Import ID",
-				fmt.Sprintf(
-					"Unrecognized import segment %q. Expected a skill ID (\"skill_...\") or a target reference (\"target:<name>:<deploy_id>\").",
-					seg,
-				),
-			)
-			return
-		}
-	}
-
-	if skillID == "" && len(targetImports) == 0 {
-		resp.Diagnostics.AddError(
-			"Invalid Import ID",
-			"Import ID must contain at least one skill ID or target import segment.",
-		)
+	skillID, targetImports, err := parseImportID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(errcode.InvalidImportID.Summary("Invalid Import ID"), err.Error())
 		return
 	}
 
@@ -104,11 +49,7 @@ func (r *SkillResource) ImportState(ctx context.Context, req resource.ImportStat
 
 	// Handle skill import.
 	if skillID != "" {
-		rsVal, rsDiags := types.ObjectValueFrom(ctx, registryStateAttrTypes(), RegistryStateValue{
-			SkillID:         types.StringValue(skillID),
-			DeployedVersion: types.StringValue(""),
-			LatestVersion:   types.StringValue(""),
-		})
+		rsVal, rsDiags := registryStateObject(ctx, skillID, "", "", nil)
 		resp.Diagnostics.Append(rsDiags...)
 		if resp.Diagnostics.HasError() {
 			return
@@ -121,14 +62,14 @@ func (r *SkillResource) ImportState(ctx context.Context, req resource.ImportStat
 
 	// Handle target imports.
 	if len(targetImports) > 0 {
-		eng := engine.New(r.providerData.Semaphore)
+		eng := engine.New(r.providerData.Scheduler)
 		targetStates := make(map[string]attr.Value, len(targetImports))
 
 		for _, ti := range targetImports {
-			t, ok := r.providerData.Targets[ti.targetName]
+			t, ok := r.providerData.Targets.Get(ti.targetName)
 			if !ok {
 				resp.Diagnostics.AddError(
-					"Unknown Target",
+					errcode.UnknownTarget.Summary("Unknown Target"),
 					fmt.Sprintf("Target %q referenced in the import ID is not configured in the provider.", ti.targetName),
 				)
 				return
@@ -141,7 +82,7 @@ func (r *SkillResource) ImportState(ctx context.Context, req resource.ImportStat
 			result, refreshErr := eng.Refresh(ctx, t, ti.deploymentID, "", false)
 			if refreshErr != nil {
 				resp.Diagnostics.AddError(
-					"Import Refresh Failed",
+					errcode.RefreshFailed.Summary("Import Refresh Failed"),
 					fmt.Sprintf("Failed to read deployment %q from target %q: %s", ti.deploymentID, ti.targetName, refreshErr),
 				)
 				return
@@ -171,10 +112,14 @@ func (r *SkillResource) ImportState(ctx context.Context, req resource.ImportStat
 
 			tsVal, tsDiags := types.ObjectValueFrom(ctx, targetStateAttrTypes(), TargetStateValue{
 				ActiveDeploymentID: types.StringValue(result.ActiveDeploymentID),
+				StableDeploymentID: types.StringValue(result.StableDeploymentID),
 				StagedDeploymentID: types.StringValue(""),
 				DeployedBundleHash: types.StringValue(bundleHash),
 				LastSyncedAt:       types.StringValue(""),
 				ManagedDeployIDs:   managedIDs,
+				ActiveETag:         types.StringValue(result.ActiveETag),
+				ActiveGeneration:   types.Int64Value(result.ActiveGeneration),
+				Stale:              types.BoolValue(false),
 			})
 			resp.Diagnostics.Append(tsDiags...)
 			if resp.Diagnostics.HasError() {
@@ -199,3 +144,55 @@ type targetImport struct {
 	targetName   string
 	deploymentID string
 }
+
+// parseImportID splits an import ID into its skill ID and target segments.
+// The returned error message is suitable for an "Invalid Import ID"
+// diagnostic.
+func parseImportID(id string) (string, []targetImport, error) {
+	var (
+		skillID       string
+		targetImports []targetImport
+	)
+
+	for _, seg := range strings.Split(id, ",") {
+		seg = strings.TrimSpace(seg)
+		if seg == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(seg, "skill_"):
+			// Skill import: the entire segment is the skill ID.
+			if skillID != "" {
+				return "", nil, errors.New("Only one skill ID may be specified in the import string.")
+			}
+			skillID = seg
+
+		case strings.HasPrefix(seg, "target:"):
+			// Target import: "target:<name>:<deploy_id>"
+			parts := strings.SplitN(seg, ":", 3)
+			if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+				return "", nil, fmt.Errorf(
+					"Target import segment %q must be in the format \"target:<target_name>:<deployment_id>\".",
+					seg,
+				)
+			}
+			targetImports = append(targetImports, targetImport{
+				targetName:   parts[1],
+				deploymentID: parts[2],
+			})
+
+		default:
+			return "", nil, fmt.Errorf(
+				"Unrecognized import segment %q. Expected a skill ID (\"skill_...\") or a target reference (\"target:<name>:<deploy_id>\").",
+				seg,
+			)
+		}
+	}
+
+	if skillID == "" && len(targetImports) == 0 {
+		return "", nil, errors.New("Import ID must contain at least one skill ID or target import segment.")
+	}
+
+	return skillID, targetImports, nil
+}
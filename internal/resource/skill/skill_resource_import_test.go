@@ -0,0 +1,56 @@
+package skill
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseImportID(t *testing.T) {
+	skillID, targets, err := parseImportID("skill_01Abc, target:us_east:dep_20260213T200102Z_6f2c9a1b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if skillID != "skill_01Abc" {
+		t.Errorf("skillID = %q, want skill_01Abc", skillID)
+	}
+	if len(targets) != 1 || targets[0].targetName != "us_east" || targets[0].deploymentID != "dep_20260213T200102Z_6f2c9a1b" {
+		t.Errorf("unexpected targets: %+v", targets)
+	}
+
+	for _, id := range []string{"", ",", "skill_a,skill_b", "target:only", "target::dep", "bogus"} {
+		if _, _, err := parseImportID(id); err == nil {
+			t.Errorf("parseImportID(%q): expected error", id)
+		}
+	}
+}
+
+func FuzzParseImportID(f *testing.F) {
+	for _, seed := range []string{
+		"skill_01AbCdEf12345678",
+		"target:shared_s3:dep_20260213T200102Z_6f2c9a1b",
+		"skill_01AbCdEf12345678,target:shared_s3:dep_20260213T200102Z_6f2c9a1b",
+		"target:a:b:c,target:d:e",
+		" , ,",
+		"target:",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, id string) {
+		skillID, targets, err := parseImportID(id)
+		if err != nil {
+			return
+		}
+		if skillID == "" && len(targets) == 0 {
+			t.Fatalf("parseImportID(%q) succeeded without any segment", id)
+		}
+		if skillID != "" && !strings.HasPrefix(skillID, "skill_") {
+			t.Fatalf("parseImportID(%q) returned skill ID %q", id, skillID)
+		}
+		for _, ti := range targets {
+			if ti.targetName == "" || ti.deploymentID == "" {
+				t.Fatalf("parseImportID(%q) returned empty target segment %+v", id, ti)
+			}
+		}
+	})
+}
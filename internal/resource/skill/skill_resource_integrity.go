@@ -0,0 +1,102 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// integrityInterval returns the integrity_check_interval of m, or zero when
+// it is unset. integrity_check_interval was validated at plan time.
+func integrityInterval(m SkillResourceModel) time.Duration {
+	if m.IntegrityCheckInterval.IsNull() || m.IntegrityCheckInterval.IsUnknown() {
+		return 0
+	}
+	interval, err := time.ParseDuration(m.IntegrityCheckInterval.ValueString())
+	if err != nil || interval <= 0 {
+		return 0
+	}
+	return interval
+}
+
+// validateIntegrityInterval checks that integrity_check_interval is a
+// positive duration.
+func validateIntegrityInterval(m SkillResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if m.IntegrityCheckInterval.IsNull() || m.IntegrityCheckInterval.IsUnknown() {
+		return diags
+	}
+	interval, err := time.ParseDuration(m.IntegrityCheckInterval.ValueString())
+	if err != nil || interval <= 0 {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Integrity Check Interval"),
+			fmt.Sprintf("integrity_check_interval must be a positive duration such as \"24h\", got %q.", m.IntegrityCheckInterval.ValueString()),
+		)
+	}
+	return diags
+}
+
+// integrityCheckDue reports whether a deployment whose integrity was last
+// checked at last is due for another check at now.
+func integrityCheckDue(last, now time.Time, interval time.Duration) bool {
+	return last.IsZero() || !now.Before(last.Add(interval))
+}
+
+// checkIntegrity verifies the hash of every file of the active deployment
+// depID of skillName on tgt if its last check, as recorded in the skill's
+// deployment index, is at least interval old. When record is set the check
+// is recorded in the index, so that the next refresh within interval skips
+// it. It returns the files found missing or modified; nil means none were,
+// or no check was due.
+func checkIntegrity(ctx context.Context, eng *engine.Engine, tgt target.Target, skillName, depID string, interval time.Duration, record bool) ([]string, error) {
+	if interval <= 0 || depID == "" {
+		return nil, nil
+	}
+	last, err := eng.IntegrityCheckedAt(ctx, tgt, skillName, depID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !integrityCheckDue(last, now, interval) {
+		return nil, nil
+	}
+
+	failed, err := eng.CheckIntegrity(ctx, tgt, skillName, depID)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Info(ctx, "checked deployment integrity", map[string]interface{}{
+		"target":        tgt.Name(),
+		"deployment_id": depID,
+		"failed_files":  len(failed),
+	})
+	if record {
+		if err := eng.RecordIntegrityCheck(ctx, tgt, skillName, depID, now, failed); err != nil {
+			tflog.Warn(ctx, "could not record integrity check in the deployment index", map[string]interface{}{
+				"target": tgt.Name(),
+				"error":  err.Error(),
+			})
+		}
+	}
+	return failed, nil
+}
+
+// describeIntegrityFailures returns the drift detail for the files of the
+// deployment depID on target tName that failed an integrity check.
+func describeIntegrityFailures(tName, depID string, failed []string) []string {
+	if len(failed) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf(
+		"target %q: deployment %q has %d file(s) whose content does not match the manifest hash: %s",
+		tName, depID, len(failed), strings.Join(failed, ", "),
+	)}
+}
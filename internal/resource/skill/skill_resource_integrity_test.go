@@ -0,0 +1,59 @@
+package skill
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestIntegrityInterval(t *testing.T) {
+	if got := integrityInterval(SkillResourceModel{IntegrityCheckInterval: types.StringNull()}); got != 0 {
+		t.Errorf("integrityInterval unset = %v, want 0", got)
+	}
+	if got := integrityInterval(SkillResourceModel{IntegrityCheckInterval: types.StringValue("24h")}); got != 24*time.Hour {
+		t.Errorf("integrityInterval = %v, want 24h", got)
+	}
+}
+
+func TestValidateIntegrityInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval types.String
+		wantErr  bool
+	}{
+		{"unset", types.StringNull(), false},
+		{"unknown", types.StringUnknown(), false},
+		{"valid", types.StringValue("12h"), false},
+		{"bad", types.StringValue("daily"), true},
+		{"zero", types.StringValue("0s"), true},
+		{"negative", types.StringValue("-1h"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateIntegrityInterval(SkillResourceModel{IntegrityCheckInterval: tt.interval})
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("validateIntegrityInterval: error = %v, want %v (%v)", diags.HasError(), tt.wantErr, diags)
+			}
+		})
+	}
+}
+
+func TestIntegrityCheckDue(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		last time.Time
+		want bool
+	}{
+		{"never checked", time.Time{}, true},
+		{"recent", now.Add(-time.Hour), false},
+		{"exactly the interval ago", now.Add(-24 * time.Hour), true},
+		{"overdue", now.Add(-48 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		if got := integrityCheckDue(tt.last, now, 24*time.Hour); got != tt.want {
+			t.Errorf("%s: integrityCheckDue = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,41 +1,101 @@
 package skill
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/contentscan"
+)
 
 // SkillResourceModel maps the agentctx_skill resource schema to a Go struct.
 type SkillResourceModel struct {
 	// Config
-	SourceDir                types.String          `tfsdk:"source_dir"`
-	Targets                  types.List            `tfsdk:"targets"`                    // optional list of strings
-	Exclude                  types.List            `tfsdk:"exclude"`                    // optional list of strings
-	PruneDeployments         types.Bool            `tfsdk:"prune_deployments"`          // default true
-	RetainDeployments        types.Int64           `tfsdk:"retain_deployments"`         // default 5
-	AllowExternalSymlinks    types.Bool            `tfsdk:"allow_external_symlinks"`    // default false
-	ValidateOnly             types.Bool            `tfsdk:"validate_only"`              // default false
-	ForceDestroy             types.Bool            `tfsdk:"force_destroy"`              // default false
-	ForceDestroySharedPrefix types.Bool            `tfsdk:"force_destroy_shared_prefix"` // default false
-	DeepDriftCheck           types.Bool            `tfsdk:"deep_drift_check"`           // default false
-	Tags                     types.Map             `tfsdk:"tags"`                       // optional map of strings
-	Anthropic                []AnthropicBlockModel `tfsdk:"anthropic"`                  // optional block, max 1
+	SourceDir                  types.String             `tfsdk:"source_dir"`                   // exactly one of source_dir,
+	SourceArchive              types.String             `tfsdk:"source_archive"`               // source_archive,
+	SourceURL                  types.String             `tfsdk:"source_url"`                   // source_url,
+	SourceGit                  []SourceGitBlockModel    `tfsdk:"source_git"`                   // and source_git (block, max 1)
+	SourceChecksum             types.String             `tfsdk:"source_checksum"`              // required with source_url
+	Targets                    types.List               `tfsdk:"targets"`                      // optional list of strings
+	Exclude                    types.List               `tfsdk:"exclude"`                      // optional list of strings
+	PruneDeployments           types.Bool               `tfsdk:"prune_deployments"`            // default true
+	RetainDeployments          types.Int64              `tfsdk:"retain_deployments"`           // default 5
+	CleanupOrphanedDeployments types.Bool               `tfsdk:"cleanup_orphaned_deployments"` // default false
+	OrphanGracePeriodSeconds   types.Int64              `tfsdk:"orphan_grace_period_seconds"`  // default 86400
+	AdoptUnmanagedDeployments  types.Bool               `tfsdk:"adopt_unmanaged_deployments"`  // default false
+	AllowExternalSymlinks      types.Bool               `tfsdk:"allow_external_symlinks"`      // default false
+	ValidateOnly               types.Bool               `tfsdk:"validate_only"`                // default false
+	ForceDestroy               types.Bool               `tfsdk:"force_destroy"`                // default false
+	ForceDestroySharedPrefix   types.Bool               `tfsdk:"force_destroy_shared_prefix"`  // default false
+	PreviewDestroy             types.Bool               `tfsdk:"preview_destroy"`              // default false
+	DeepDriftCheck             types.Bool               `tfsdk:"deep_drift_check"`             // default false
+	IntegrityCheckInterval     types.String             `tfsdk:"integrity_check_interval"`     // optional duration
+	TolerateUnreachableTargets types.Bool               `tfsdk:"tolerate_unreachable_targets"` // default false
+	Tags                       types.Map                `tfsdk:"tags"`                         // optional map of strings
+	DependsOnSkills            types.List               `tfsdk:"depends_on_skills"`            // optional list of strings
+	DeploymentAlias            types.String             `tfsdk:"deployment_alias"`             // optional
+	DeploymentStrategy         types.String             `tfsdk:"deployment_strategy"`          // default "immediate"
+	PreviewID                  types.String             `tfsdk:"preview_id"`                   // optional
+	PreviewTTL                 types.String             `tfsdk:"preview_ttl"`                  // optional duration
+	CleanupExpiredPreviews     types.Bool               `tfsdk:"cleanup_expired_previews"`     // default false
+	Provenance                 types.Bool               `tfsdk:"provenance"`                   // default false
+	Anthropic                  []AnthropicBlockModel    `tfsdk:"anthropic"`                    // optional block, max 1
+	Verify                     []VerifyBlockModel       `tfsdk:"verify"`                       // optional block, max 1
+	Canary                     []CanaryBlockModel       `tfsdk:"canary"`                       // optional block, max 1
+	ContentScan                []contentscan.BlockModel `tfsdk:"content_scan"`                 // optional block, max 1
 
 	// Computed
-	ID            types.String `tfsdk:"id"`
-	SkillName     types.String `tfsdk:"skill_name"`
-	SourceHash    types.String `tfsdk:"source_hash"`
-	BundleHash    types.String `tfsdk:"bundle_hash"`
-	RegistryState types.Object `tfsdk:"registry_state"`
-	TargetStates  types.Map    `tfsdk:"target_states"`
+	ID               types.String `tfsdk:"id"`
+	SkillName        types.String `tfsdk:"skill_name"`
+	SkillDescription types.String `tfsdk:"skill_description"`
+	SkillMetadata    types.Map    `tfsdk:"skill_metadata"` // map of strings
+	SourceHash       types.String `tfsdk:"source_hash"`
+	BundleHash       types.String `tfsdk:"bundle_hash"`
+	BundleJSON       types.String `tfsdk:"bundle_json"`
+	ProvenanceJSON   types.String `tfsdk:"provenance_json"`
+	RegistryState    types.Object `tfsdk:"registry_state"`
+	RegistrySkipped  types.Bool   `tfsdk:"registry_skipped"`
+	TargetStates     types.Map    `tfsdk:"target_states"`
+	DriftDetected    types.Bool   `tfsdk:"drift_detected"`
+	DriftDetails     types.List   `tfsdk:"drift_details"` // list of strings
+}
+
+// SourceGitBlockModel maps the optional source_git {} block inside the
+// agentctx_skill resource. At most one block may be specified.
+type SourceGitBlockModel struct {
+	URL    types.String `tfsdk:"url"`    // required
+	Ref    types.String `tfsdk:"ref"`    // required
+	Subdir types.String `tfsdk:"subdir"` // optional
+	Commit types.String `tfsdk:"commit"` // optional, required unless ref is a commit ID
 }
 
 // AnthropicBlockModel maps the optional anthropic {} block inside the
 // agentctx_skill resource. At most one block may be specified.
 type AnthropicBlockModel struct {
-	Enabled         types.Bool   `tfsdk:"enabled"`          // default false
-	Register        types.Bool   `tfsdk:"register"`         // default true
-	DisplayTitle    types.String `tfsdk:"display_title"`    // optional
-	AutoVersion     types.Bool   `tfsdk:"auto_version"`     // default true
-	VersionStrategy types.String `tfsdk:"version_strategy"` // default "auto"
-	PinnedVersion   types.String `tfsdk:"pinned_version"`   // optional
+	Enabled            types.Bool   `tfsdk:"enabled"`              // default false
+	Register           types.Bool   `tfsdk:"register"`             // default true
+	DisplayTitle       types.String `tfsdk:"display_title"`        // optional
+	AutoVersion        types.Bool   `tfsdk:"auto_version"`         // default true
+	VersionStrategy    types.String `tfsdk:"version_strategy"`     // default "auto"
+	PinnedVersion      types.String `tfsdk:"pinned_version"`       // optional
+	VersionNotes       types.String `tfsdk:"version_notes"`        // optional
+	VersionLabels      types.Map    `tfsdk:"version_labels"`       // optional, map of strings
+	OnDestroy          types.String `tfsdk:"on_destroy"`           // optional: "delete" | "detach"
+	OnVersionDrift     types.String `tfsdk:"on_version_drift"`     // default "warn"
+	DestroyAllVersions types.Bool   `tfsdk:"destroy_all_versions"` // default false
+}
+
+// VerifyBlockModel maps the optional verify {} block inside the
+// agentctx_skill resource. Exactly one of Command and URL is set.
+type VerifyBlockModel struct {
+	Command        types.String `tfsdk:"command"`         // optional template
+	URL            types.String `tfsdk:"url"`             // optional template
+	ExpectedStatus types.Int64  `tfsdk:"expected_status"` // default 200
+	TimeoutSeconds types.Int64  `tfsdk:"timeout_seconds"` // default 60
+}
+
+// CanaryBlockModel maps the optional canary {} block inside the
+// agentctx_skill resource.
+type CanaryBlockModel struct {
+	Weight types.Int64 `tfsdk:"weight"` // required, 1-99
 }
 
 // RegistryStateValue represents the computed registry_state nested object.
@@ -43,6 +103,7 @@ type RegistryStateValue struct {
 	SkillID         types.String `tfsdk:"skill_id"`
 	DeployedVersion types.String `tfsdk:"deployed_version"`
 	LatestVersion   types.String `tfsdk:"latest_version"`
+	ManagedVersions types.List   `tfsdk:"managed_versions"` // list of strings
 }
 
 // TargetStateValue represents a single entry in the computed target_states
@@ -50,8 +111,12 @@ type RegistryStateValue struct {
 // state for that target.
 type TargetStateValue struct {
 	ActiveDeploymentID types.String `tfsdk:"active_deployment_id"`
+	StableDeploymentID types.String `tfsdk:"stable_deployment_id"`
 	StagedDeploymentID types.String `tfsdk:"staged_deployment_id"`
 	DeployedBundleHash types.String `tfsdk:"deployed_bundle_hash"`
 	LastSyncedAt       types.String `tfsdk:"last_synced_at"`
 	ManagedDeployIDs   types.List   `tfsdk:"managed_deploy_ids"` // list of strings
+	ActiveETag         types.String `tfsdk:"active_etag"`
+	ActiveGeneration   types.Int64  `tfsdk:"active_generation"`
+	Stale              types.Bool   `tfsdk:"stale"`
 }
@@ -5,19 +5,33 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
 )
 
 // ModifyPlan implements resource.ResourceWithModifyPlan. It performs
 // validation and plan-time computations before Terraform applies changes.
 func (r *SkillResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
-	// If the entire resource is being destroyed there is nothing to validate.
+	// If the entire resource is being destroyed there is nothing to
+	// validate; optionally preview what the destroy will remove.
 	if req.Plan.Raw.IsNull() {
+		var state SkillResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if state.PreviewDestroy.ValueBool() && r.providerData != nil {
+			resp.Diagnostics.Append(r.previewDestroy(ctx, state)...)
+		}
 		return
 	}
 
@@ -38,9 +52,9 @@ func (r *SkillResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanR
 		}
 
 		for _, tName := range targetNames {
-			if _, exists := r.providerData.Targets[tName]; !exists {
+			if _, exists := r.providerData.Targets.Get(tName); !exists {
 				resp.Diagnostics.AddError(
-					"Invalid Target Reference",
+					errcode.UnknownTarget.Summary("Invalid Target Reference"),
 					fmt.Sprintf(
 						"Target %q is referenced in the resource targets list but is not defined in the provider configuration.",
 						tName,
@@ -69,20 +83,20 @@ func (r *SkillResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanR
 		case "auto":
 			if !anthCfg.PinnedVersion.IsNull() && !anthCfg.PinnedVersion.IsUnknown() && anthCfg.PinnedVersion.ValueString() != "" {
 				resp.Diagnostics.AddError(
-					"Invalid Version Configuration",
+					errcode.InvalidConfig.Summary("Invalid Version Configuration"),
 					"pinned_version must not be set when version_strategy is \"auto\". Either remove pinned_version or set version_strategy to \"pinned\" or \"manual\".",
 				)
 			}
 		case "pinned", "manual":
 			if anthCfg.PinnedVersion.IsNull() || anthCfg.PinnedVersion.IsUnknown() || anthCfg.PinnedVersion.ValueString() == "" {
 				resp.Diagnostics.AddError(
-					"Invalid Version Configuration",
+					errcode.InvalidConfig.Summary("Invalid Version Configuration"),
 					fmt.Sprintf("pinned_version is required when version_strategy is %q. Reference an agentctx_skill_version resource.", strategy),
 				)
 			}
 		default:
 			resp.Diagnostics.AddError(
-				"Invalid Version Strategy",
+				errcode.InvalidConfig.Summary("Invalid Version Strategy"),
 				fmt.Sprintf("version_strategy must be \"auto\", \"pinned\", or \"manual\", got %q.", strategy),
 			)
 		}
@@ -93,71 +107,248 @@ func (r *SkillResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanR
 	}
 
 	// ---------------------------------------------------------------
-	// 3. Warn if validate_only is set.
+	// 3. Validate preview_id / preview_ttl, integrity_check_interval,
+	//    the verify block, and deployment_strategy.
+	// ---------------------------------------------------------------
+	resp.Diagnostics.Append(validateSource(plan)...)
+	resp.Diagnostics.Append(validatePreview(plan)...)
+	resp.Diagnostics.Append(validateIntegrityInterval(plan)...)
+	resp.Diagnostics.Append(validateVerify(plan)...)
+	resp.Diagnostics.Append(validateDeploymentStrategy(plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// ---------------------------------------------------------------
+	// 4. Retry a registration the last apply skipped because the
+	//    registry was unavailable, and handle registry version drift.
+	// ---------------------------------------------------------------
+	if !req.State.Raw.IsNull() && len(plan.Anthropic) == 1 && plan.Anthropic[0].Enabled.ValueBool() {
+		var state SkillResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if state.RegistrySkipped.ValueBool() {
+			plan.RegistrySkipped = types.BoolUnknown()
+			plan.RegistryState = types.ObjectUnknown(registryStateAttrTypes())
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		}
+
+		// Reconcile or warn about a version created outside Terraform.
+		reconcile, diags := planVersionDrift(ctx, &plan, state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if reconcile {
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		}
+	}
+
+	// ---------------------------------------------------------------
+	// 5. Warn if validate_only is set.
 	// ---------------------------------------------------------------
 	if !plan.ValidateOnly.IsNull() && !plan.ValidateOnly.IsUnknown() && plan.ValidateOnly.ValueBool() {
 		resp.Diagnostics.AddWarning(
-			"Validate-Only Mode",
+			errcode.PlanNotice.Summary("Validate-Only Mode"),
 			"The resource is configured with validate_only = true. No deployment will be performed; only bundle validation will run during apply.",
 		)
 	}
 
 	// ---------------------------------------------------------------
-	// 4. Compute plan-time source_hash if source_dir is known.
+	// 6. Compute plan-time source_hash if the source is known.
 	// ---------------------------------------------------------------
-	if !plan.SourceDir.IsNull() && !plan.SourceDir.IsUnknown() {
-		sourceDir := plan.SourceDir.ValueString()
-
-		// Only attempt to hash if the directory actually exists on disk
-		// during the plan phase. It may not exist in CI plan-only runs.
-		absDir, absErr := filepath.Abs(sourceDir)
-		if absErr == nil {
-			if info, statErr := os.Stat(absDir); statErr == nil && info.IsDir() {
-				var excludes []string
-				if !plan.Exclude.IsNull() && !plan.Exclude.IsUnknown() {
-					resp.Diagnostics.Append(plan.Exclude.ElementsAs(ctx, &excludes, false)...)
-					if resp.Diagnostics.HasError() {
-						return
-					}
-				}
-
-				allowExtSym := false
-				if !plan.AllowExternalSymlinks.IsNull() && !plan.AllowExternalSymlinks.IsUnknown() {
-					allowExtSym = plan.AllowExternalSymlinks.ValueBool()
-				}
-
-				b, scanErr := bundle.ScanBundle(sourceDir, excludes, allowExtSym)
-				if scanErr != nil {
-					tflog.Warn(ctx, "plan-time bundle scan failed, hash will be computed at apply", map[string]interface{}{
-						"source_dir": sourceDir,
-						"error":      scanErr.Error(),
-					})
-				} else {
-					newHash := b.BundleHash
-					plan.SourceHash = types.StringValue(newHash)
-					plan.BundleHash = types.StringValue(newHash)
-					plan.SkillName = types.StringValue(filepath.Base(sourceDir))
-
-					// On update, if the bundle hash changed, mark
-					// mutable computed attributes as unknown so
-					// Terraform knows they will change during apply.
-					if !req.State.Raw.IsNull() {
-						var state SkillResourceModel
-						resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
-						if resp.Diagnostics.HasError() {
-							return
-						}
-
-						if state.BundleHash.ValueString() != newHash {
-							plan.ID = types.StringUnknown()
-							plan.TargetStates = types.MapUnknown(types.ObjectType{AttrTypes: targetStateAttrTypes()})
-							plan.RegistryState = types.ObjectUnknown(registryStateAttrTypes())
-						}
-					}
-
-					resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
-				}
+	src := planSkillSource(ctx, plan)
+	if src == nil {
+		return
+	}
+	defer src.close()
+
+	var excludes []string
+	if !plan.Exclude.IsNull() && !plan.Exclude.IsUnknown() {
+		resp.Diagnostics.Append(plan.Exclude.ElementsAs(ctx, &excludes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	allowExtSym := false
+	if !plan.AllowExternalSymlinks.IsNull() && !plan.AllowExternalSymlinks.IsUnknown() {
+		allowExtSym = plan.AllowExternalSymlinks.ValueBool()
+	}
+
+	b, scanErr := bundle.ScanBundle(src.dir, excludes, allowExtSym)
+	if scanErr != nil {
+		tflog.Warn(ctx, "plan-time bundle scan failed, hash will be computed at apply", map[string]interface{}{
+			"source": src.path,
+			"error":  scanErr.Error(),
+		})
+		return
+	}
+
+	newHash := b.BundleHash
+	plan.SourceHash = types.StringValue(newHash)
+	plan.BundleHash = types.StringValue(newHash)
+	plan.SkillName = types.StringValue(src.name)
+	setSkillFrontmatter(&plan, b)
+	resp.Diagnostics.Append(checkSkillName(b)...)
+	resp.Diagnostics.Append(scanContent(ctx, plan, b)...)
+	_, diags := dependsOnSkills(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if bundleJSON, descDiags := bundleDescriptor(b, src); !descDiags.HasError() {
+		plan.BundleJSON = bundleJSON
+	}
+
+	// On update, if the bundle hash changed, mark mutable computed
+	// attributes as unknown so Terraform knows they will change during
+	// apply.
+	if !req.State.Raw.IsNull() {
+		var state SkillResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if state.BundleHash.ValueString() != newHash {
+			plan.ID = types.StringUnknown()
+			plan.TargetStates = types.MapUnknown(types.ObjectType{AttrTypes: targetStateAttrTypes()})
+			plan.RegistryState = types.ObjectUnknown(registryStateAttrTypes())
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// planSkillSource opens the skill source of plan for plan-time hashing, or
+// returns nil when it is remote, unknown, or not on disk yet, as in CI
+// plan-only runs. Remote sources are only fetched at apply time; their
+// pins are part of the configuration, so a change of content is a change
+// of plan. The caller must close a non-nil source.
+func planSkillSource(ctx context.Context, plan SkillResourceModel) *skillSource {
+	if isRemoteSource(plan) {
+		return nil
+	}
+	configured := plan.SourceDir
+	if configured.IsNull() {
+		configured = plan.SourceArchive
+	}
+	if configured.IsNull() || configured.IsUnknown() {
+		return nil
+	}
+
+	// Only attempt to hash if the source actually exists on disk during
+	// the plan phase.
+	absPath, err := filepath.Abs(configured.ValueString())
+	if err != nil {
+		return nil
+	}
+	info, err := os.Stat(absPath)
+	if err != nil || info.IsDir() != plan.SourceArchive.IsNull() {
+		return nil
+	}
+
+	src, err := openSkillSource(ctx, plan)
+	if err != nil {
+		tflog.Warn(ctx, "plan-time archive extraction failed, hash will be computed at apply", map[string]interface{}{
+			"source_archive": configured.ValueString(),
+			"error":          err.Error(),
+		})
+		return nil
+	}
+	return src
+}
+
+// previewDestroy returns a warning listing every remote object key and
+// Anthropic registry version that Delete would remove for state. It uses the
+// same destroy options as Delete, so graceful, force_destroy and
+// force_destroy_shared_prefix are reflected exactly as of plan time.
+func (r *SkillResource) previewDestroy(ctx context.Context, state SkillResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	resolvedTargets, d := r.resolveTargets(ctx, state)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	priorTargetStates, d := targetStateValues(ctx, state)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	skillName := storageName(state, state.SkillName.ValueString())
+
+	var b strings.Builder
+	for _, tName := range resolvedTargets {
+		t, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			fmt.Fprintf(&b, "Target %q: no longer configured, nothing will be removed.\n", tName)
+			continue
+		}
+
+		var managedIDs []string
+		var activeDeployID string
+		if pts, exists := priorTargetStates[tName]; exists {
+			ids, idDiags := destroyDeployIDs(ctx, pts)
+			diags.Append(idDiags...)
+			if diags.HasError() {
+				return diags
+			}
+			managedIDs = ids
+			activeDeployID = pts.ActiveDeploymentID.ValueString()
+		}
+
+		keys, err := eng.PreviewDestroy(ctx, t, skillName, engine.DestroyOptions{
+			ForceDestroy:             state.ForceDestroy.ValueBool(),
+			ForceDestroySharedPrefix: state.ForceDestroySharedPrefix.ValueBool(),
+			ManagedDeployIDs:         managedIDs,
+			ActiveDeployID:           activeDeployID,
+		})
+		if err != nil {
+			diags.AddWarning(
+				errcode.DestroyFailed.Summary("Destroy Preview Failed"),
+				fmt.Sprintf("Could not preview destroy of skill %q on target %q: %s", skillName, tName, err),
+			)
+			continue
+		}
+
+		fmt.Fprintf(&b, "Target %q (%d objects):\n", tName, len(keys))
+		for _, key := range keys {
+			fmt.Fprintf(&b, "  - %s\n", key)
+		}
+	}
+
+	if r.providerData.Anthropic != nil && r.providerData.Anthropic.DestroyRemote() &&
+		!state.RegistryState.IsNull() && !state.RegistryState.IsUnknown() {
+		var rsv RegistryStateValue
+		diags.Append(state.RegistryState.As(ctx, &rsv, basetypes.ObjectAsOptions{})...)
+		if diags.HasError() {
+			return diags
+		}
+
+		if skillID := rsv.SkillID.ValueString(); skillID != "" {
+			versions, vDiags := r.destroyVersions(ctx, state, rsv)
+			diags.Append(vDiags...)
+			if diags.HasError() {
+				return diags
+			}
+			fmt.Fprintf(&b, "Anthropic registry skill %q (%d versions, then the skill itself if no other versions remain):\n", skillID, len(versions))
+			for _, version := range versions {
+				fmt.Fprintf(&b, "  - version %s\n", version)
 			}
 		}
 	}
+
+	diags.AddWarning(
+		errcode.PlanNotice.Summary("Destroy Preview"),
+		fmt.Sprintf("Destroying skill %q will remove:\n\n%s", skillName, b.String()),
+	)
+	return diags
 }
@@ -0,0 +1,111 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// previewIDPattern restricts preview_id to characters that are safe in an
+// object key segment.
+var previewIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// storageName returns the name the engine stores skillName under for m:
+// the preview prefix when preview_id is set, skillName itself otherwise.
+func storageName(m SkillResourceModel, skillName string) string {
+	if m.PreviewID.IsNull() || m.PreviewID.IsUnknown() || m.PreviewID.ValueString() == "" {
+		return skillName
+	}
+	return engine.PreviewSkillName(m.PreviewID.ValueString(), skillName)
+}
+
+// previewExpiry returns the expiry to record for a deploy of m at now, or
+// the zero time when m is not a preview. preview_ttl was validated at plan
+// time.
+func previewExpiry(m SkillResourceModel, now time.Time) time.Time {
+	if m.PreviewTTL.IsNull() || m.PreviewTTL.IsUnknown() {
+		return time.Time{}
+	}
+	ttl, err := time.ParseDuration(m.PreviewTTL.ValueString())
+	if err != nil {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// validatePreview checks that preview_id and preview_ttl are set together,
+// that preview_ttl is a positive duration, and that a preview does not
+// register with the Anthropic registry, which has no notion of previews.
+func validatePreview(m SkillResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if m.PreviewID.IsUnknown() || m.PreviewTTL.IsUnknown() {
+		return diags
+	}
+
+	hasID, hasTTL := !m.PreviewID.IsNull(), !m.PreviewTTL.IsNull()
+	switch {
+	case hasID && !hasTTL:
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Preview Configuration"),
+			"preview_id requires preview_ttl. Previews must expire so that cleanup_expired_previews can remove them.",
+		)
+		return diags
+	case hasTTL && !hasID:
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Preview Configuration"),
+			"preview_ttl requires preview_id, which names the preview's prefix, previews/<preview_id>/<skill_name>/.",
+		)
+		return diags
+	case !hasID:
+		return diags
+	}
+
+	ttl, err := time.ParseDuration(m.PreviewTTL.ValueString())
+	if err != nil || ttl <= 0 {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Preview TTL"),
+			fmt.Sprintf("preview_ttl must be a positive duration such as \"72h\", got %q.", m.PreviewTTL.ValueString()),
+		)
+	}
+	if len(m.Anthropic) == 1 && m.Anthropic[0].Enabled.ValueBool() {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Preview Configuration"),
+			"A preview deployment cannot register with the Anthropic registry. Disable the anthropic block when preview_id is set.",
+		)
+	}
+	return diags
+}
+
+// collectExpiredPreviews deletes the expired previews of skillName on each
+// of targetNames. Failures are logged and do not fail the apply; the next
+// apply retries them.
+func (r *SkillResource) collectExpiredPreviews(ctx context.Context, eng *engine.Engine, skillName string, targetNames []string) {
+	now := time.Now()
+	for _, tName := range targetNames {
+		t, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			continue
+		}
+		removed, err := eng.CollectExpiredPreviews(ctx, t, skillName, now)
+		if len(removed) > 0 {
+			tflog.Info(ctx, "removed expired previews", map[string]interface{}{
+				"target":      tName,
+				"skill_name":  skillName,
+				"preview_ids": removed,
+			})
+		}
+		if err != nil {
+			tflog.Warn(ctx, "expired preview cleanup failed", map[string]interface{}{
+				"target": tName,
+				"error":  err.Error(),
+			})
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package skill
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestStorageName(t *testing.T) {
+	m := SkillResourceModel{PreviewID: types.StringNull()}
+	if got := storageName(m, "summarizer"); got != "summarizer" {
+		t.Errorf("storageName without preview = %q, want summarizer", got)
+	}
+	m.PreviewID = types.StringValue("pr-42")
+	if got := storageName(m, "summarizer"); got != "previews/pr-42/summarizer" {
+		t.Errorf("storageName with preview = %q, want previews/pr-42/summarizer", got)
+	}
+}
+
+func TestPreviewExpiry(t *testing.T) {
+	now := time.Date(2026, 2, 13, 20, 0, 0, 0, time.UTC)
+	m := SkillResourceModel{PreviewTTL: types.StringNull()}
+	if got := previewExpiry(m, now); !got.IsZero() {
+		t.Errorf("previewExpiry without preview = %v, want zero", got)
+	}
+	m.PreviewTTL = types.StringValue("72h")
+	if got, want := previewExpiry(m, now), now.Add(72*time.Hour); !got.Equal(want) {
+		t.Errorf("previewExpiry = %v, want %v", got, want)
+	}
+}
+
+func TestValidatePreview(t *testing.T) {
+	tests := []struct {
+		name    string
+		id, ttl types.String
+		wantErr bool
+	}{
+		{"neither", types.StringNull(), types.StringNull(), false},
+		{"both", types.StringValue("pr-42"), types.StringValue("72h"), false},
+		{"unknown", types.StringUnknown(), types.StringValue("72h"), false},
+		{"id without ttl", types.StringValue("pr-42"), types.StringNull(), true},
+		{"ttl without id", types.StringNull(), types.StringValue("72h"), true},
+		{"bad ttl", types.StringValue("pr-42"), types.StringValue("three days"), true},
+		{"negative ttl", types.StringValue("pr-42"), types.StringValue("-1h"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validatePreview(SkillResourceModel{PreviewID: tt.id, PreviewTTL: tt.ttl})
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("validatePreview: error = %v, want %v (%v)", diags.HasError(), tt.wantErr, diags)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package skill
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestCheckDestroyReferences(t *testing.T) {
+	ctx := context.Background()
+	eng := engine.New(nil)
+	first, second := target.NewMemoryTarget("first"), target.NewMemoryTarget("second")
+	targets := providerdata.NewTargetRegistry()
+	for name, tgt := range map[string]target.Target{"first": first, "second": second} {
+		if err := targets.Register(name, tgt, providerdata.TargetConfigModel{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.AddReference(ctx, second, "review", engine.Reference{Consumer: "stack-b", CreatedAt: "2026-01-01T00:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if diags := checkDestroyReferences(ctx, eng, targets, "review", []string{"first", "gone"}); diags.HasError() {
+		t.Errorf("unreferenced targets: unexpected errors: %v", diags)
+	}
+	diags := checkDestroyReferences(ctx, eng, targets, "review", []string{"first", "second"})
+	if diags.ErrorsCount() != 1 {
+		t.Fatalf("got %d errors, want one for the referenced target: %v", diags.ErrorsCount(), diags)
+	}
+	if detail := diags.Errors()[0].Detail(); !strings.Contains(detail, `target "second"`) || !strings.Contains(detail, "stack-b") {
+		t.Errorf("detail = %q, want it to name target second and consumer stack-b", detail)
+	}
+}
@@ -0,0 +1,195 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// Values of the anthropic block's on_version_drift.
+const (
+	versionDriftWarn      = "warn"
+	versionDriftReconcile = "reconcile"
+)
+
+// registryStateValue returns m's registry_state, and false when it is null
+// or unknown.
+func registryStateValue(ctx context.Context, m SkillResourceModel) (RegistryStateValue, bool, diag.Diagnostics) {
+	var rsv RegistryStateValue
+	if m.RegistryState.IsNull() || m.RegistryState.IsUnknown() {
+		return rsv, false, nil
+	}
+	diags := m.RegistryState.As(ctx, &rsv, basetypes.ObjectAsOptions{})
+	return rsv, !diags.HasError(), diags
+}
+
+// registryStateObject returns a registry_state value.
+func registryStateObject(ctx context.Context, skillID, deployed, latest string, managed []string) (types.Object, diag.Diagnostics) {
+	managedList, diags := types.ListValueFrom(ctx, types.StringType, append([]string{}, managed...))
+	if diags.HasError() {
+		return types.ObjectNull(registryStateAttrTypes()), diags
+	}
+	return types.ObjectValueFrom(ctx, registryStateAttrTypes(), RegistryStateValue{
+		SkillID:         types.StringValue(skillID),
+		DeployedVersion: types.StringValue(deployed),
+		LatestVersion:   types.StringValue(latest),
+		ManagedVersions: managedList,
+	})
+}
+
+// managedVersions returns the registry versions created by the resource
+// whose registry_state is rsv. States written before managed_versions was
+// recorded only know the deployed version.
+func managedVersions(ctx context.Context, rsv RegistryStateValue) ([]string, diag.Diagnostics) {
+	var managed []string
+	var diags diag.Diagnostics
+	if !rsv.ManagedVersions.IsNull() && !rsv.ManagedVersions.IsUnknown() {
+		diags.Append(rsv.ManagedVersions.ElementsAs(ctx, &managed, false)...)
+	}
+	if deployed := rsv.DeployedVersion.ValueString(); deployed != "" && !slices.Contains(managed, deployed) {
+		managed = append(managed, deployed)
+	}
+	return managed, diags
+}
+
+// destroyVersions returns the versions of skillID that destroying the
+// resource whose registry_state is rsv deletes: those it created, or every
+// version in the registry with destroy_all_versions.
+func (r *SkillResource) destroyVersions(ctx context.Context, state SkillResourceModel, rsv RegistryStateValue) ([]string, diag.Diagnostics) {
+	if len(state.Anthropic) == 1 && state.Anthropic[0].DestroyAllVersions.ValueBool() {
+		var diags diag.Diagnostics
+		var all []string
+		for v, err := range r.providerData.Anthropic.Versions(ctx, rsv.SkillID.ValueString()) {
+			if err != nil {
+				diags.AddWarning(
+					errcode.AnthropicRequestFailed.Summary("Anthropic List Versions Failed"),
+					fmt.Sprintf("Could not list the versions of skill %q: %s. Only the versions this resource created are deleted.", rsv.SkillID.ValueString(), err),
+				)
+				managed, d := managedVersions(ctx, rsv)
+				diags.Append(d...)
+				return managed, diags
+			}
+			all = append(all, v.Version)
+		}
+		return all, diags
+	}
+	return managedVersions(ctx, rsv)
+}
+
+// versionDrifted reports whether the registry's latest version of the skill
+// is not the one this resource deployed: someone created a version outside
+// Terraform. Resources that never deployed a version do not drift.
+func versionDrifted(rsv RegistryStateValue) bool {
+	deployed, latest := rsv.DeployedVersion.ValueString(), rsv.LatestVersion.ValueString()
+	return deployed != "" && latest != "" && latest != deployed
+}
+
+// latestRegistryVersion returns the latest version of skillID in the
+// registry: the skill's latest_version when the API reports it, otherwise
+// the most recently created of its versions. It returns "" for a skill
+// without versions.
+func latestRegistryVersion(ctx context.Context, client *anthropic.Client, skillID string) (string, error) {
+	skill, err := client.GetSkill(ctx, skillID)
+	if err != nil {
+		return "", err
+	}
+	if skill.LatestVersion != "" {
+		return skill.LatestVersion, nil
+	}
+
+	var (
+		latest   anthropic.SkillVersion
+		latestAt time.Time
+		seen     bool
+	)
+	for v, err := range client.Versions(ctx, skillID) {
+		if err != nil {
+			return "", err
+		}
+		createdAt, _ := time.Parse(time.RFC3339, v.CreatedAt)
+		// Versions are listed oldest first; a later entry wins a tie.
+		if !seen || !createdAt.Before(latestAt) {
+			latest, latestAt, seen = v, createdAt, true
+		}
+	}
+	return latest.Version, nil
+}
+
+// refreshRegistry updates registry_state.latest_version of state from the
+// Anthropic registry and returns a drift detail when the latest version was
+// created outside Terraform. A registry that cannot be read only warns, so
+// that refreshing the storage targets still succeeds.
+func (r *SkillResource) refreshRegistry(ctx context.Context, state *SkillResourceModel) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if r.providerData.Anthropic == nil || len(state.Anthropic) != 1 || !state.Anthropic[0].Enabled.ValueBool() {
+		return nil, diags
+	}
+	rsv, ok, d := registryStateValue(ctx, *state)
+	diags.Append(d...)
+	if !ok || rsv.SkillID.ValueString() == "" {
+		return nil, diags
+	}
+
+	skillID := rsv.SkillID.ValueString()
+	latest, err := latestRegistryVersion(ctx, r.providerData.Anthropic, skillID)
+	if err != nil {
+		diags.AddWarning(
+			errcode.AnthropicRequestFailed.Summary("Registry Refresh Failed"),
+			fmt.Sprintf("Could not read the latest version of skill %q from the Anthropic registry: %s. registry_state keeps its last known value.", skillID, err),
+		)
+		return nil, diags
+	}
+
+	rsv.LatestVersion = types.StringValue(latest)
+	rsVal, d := types.ObjectValueFrom(ctx, registryStateAttrTypes(), rsv)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	state.RegistryState = rsVal
+
+	if !versionDrifted(rsv) {
+		return nil, diags
+	}
+	return []string{fmt.Sprintf("anthropic registry: latest version of skill %q is %q, created outside Terraform; this resource deployed %q",
+		skillID, latest, rsv.DeployedVersion.ValueString())}, diags
+}
+
+// planVersionDrift handles a registry version created outside Terraform,
+// as found by the last refresh of state. With on_version_drift =
+// "reconcile" and auto_version, it marks registry_state unknown so that
+// the apply publishes the configured bundle as a new latest version, and
+// returns true. Otherwise it warns.
+func planVersionDrift(ctx context.Context, plan *SkillResourceModel, state SkillResourceModel) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if len(plan.Anthropic) != 1 || !plan.Anthropic[0].Enabled.ValueBool() {
+		return false, diags
+	}
+	rsv, ok, d := registryStateValue(ctx, state)
+	diags.Append(d...)
+	if !ok || !versionDrifted(rsv) {
+		return false, diags
+	}
+
+	anthCfg := plan.Anthropic[0]
+	if anthCfg.OnVersionDrift.ValueString() == versionDriftReconcile && anthCfg.AutoVersion.ValueBool() {
+		plan.RegistryState = types.ObjectUnknown(registryStateAttrTypes())
+		return true, diags
+	}
+
+	diags.AddWarning(
+		errcode.DriftDetected.Summary("Registry Version Drift"),
+		fmt.Sprintf("The latest version of skill %q in the Anthropic registry is %q, which was created outside Terraform; this resource deployed %q.\n\n"+
+			"Set on_version_drift = \"reconcile\" in the anthropic block, with auto_version enabled, to publish the configured bundle as a new version on the next apply.",
+			rsv.SkillID.ValueString(), rsv.LatestVersion.ValueString(), rsv.DeployedVersion.ValueString()),
+	)
+	return false, diags
+}
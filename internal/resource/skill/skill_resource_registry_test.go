@@ -0,0 +1,151 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
+)
+
+func registryState(t *testing.T, deployed, latest string) types.Object {
+	t.Helper()
+	v, diags := registryStateObject(context.Background(), "skill_1", deployed, latest, []string{deployed})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return v
+}
+
+func TestManagedVersions(t *testing.T) {
+	ctx := context.Background()
+	managedList := func(versions ...string) types.List {
+		l, diags := types.ListValueFrom(ctx, types.StringType, versions)
+		if diags.HasError() {
+			t.Fatal(diags)
+		}
+		return l
+	}
+	cases := map[string]struct {
+		rsv  RegistryStateValue
+		want []string
+	}{
+		"tracked": {
+			rsv:  RegistryStateValue{DeployedVersion: types.StringValue("v3"), ManagedVersions: managedList("v1", "v3")},
+			want: []string{"v1", "v3"},
+		},
+		// States written before managed_versions only know deployed_version.
+		"untracked": {
+			rsv:  RegistryStateValue{DeployedVersion: types.StringValue("v2"), ManagedVersions: types.ListNull(types.StringType)},
+			want: []string{"v2"},
+		},
+		"none": {
+			rsv: RegistryStateValue{DeployedVersion: types.StringValue(""), ManagedVersions: managedList()},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, diags := managedVersions(ctx, c.rsv)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if !slices.Equal(got, c.want) {
+				t.Errorf("managedVersions = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLatestRegistryVersion(t *testing.T) {
+	latestField := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/skills/skill_1":
+			fmt.Fprintf(w, `{"id":"skill_1","latest_version":%q}`, latestField)
+		case "/v1/skills/skill_1/versions":
+			fmt.Fprint(w, `{"data":[
+				{"version":"v1","created_at":"2026-01-01T00:00:00Z"},
+				{"version":"v3","created_at":"2026-03-01T00:00:00Z"},
+				{"version":"v2","created_at":"2026-02-01T00:00:00Z"}
+			]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	client := anthropic.NewClient(anthropic.ClientConfig{BaseURL: srv.URL, APIKey: "test"})
+
+	// Without latest_version on the skill, the newest version wins.
+	got, err := latestRegistryVersion(context.Background(), client, "skill_1")
+	if err != nil || got != "v3" {
+		t.Errorf("latestRegistryVersion = %q, %v; want v3", got, err)
+	}
+
+	latestField = "v4"
+	got, err = latestRegistryVersion(context.Background(), client, "skill_1")
+	if err != nil || got != "v4" {
+		t.Errorf("latestRegistryVersion = %q, %v; want the skill's latest_version v4", got, err)
+	}
+}
+
+func TestVersionDrifted(t *testing.T) {
+	cases := []struct {
+		deployed, latest string
+		want             bool
+	}{
+		{"v2", "v2", false},
+		{"v2", "v3", true},
+		{"", "v3", false},
+		{"v2", "", false},
+	}
+	for _, c := range cases {
+		rsv := RegistryStateValue{DeployedVersion: types.StringValue(c.deployed), LatestVersion: types.StringValue(c.latest)}
+		if got := versionDrifted(rsv); got != c.want {
+			t.Errorf("versionDrifted(deployed %q, latest %q) = %v, want %v", c.deployed, c.latest, got, c.want)
+		}
+	}
+}
+
+func TestPlanVersionDrift(t *testing.T) {
+	anthropicBlock := func(onDrift string, autoVersion bool) []AnthropicBlockModel {
+		return []AnthropicBlockModel{{
+			Enabled:        types.BoolValue(true),
+			AutoVersion:    types.BoolValue(autoVersion),
+			OnVersionDrift: types.StringValue(onDrift),
+		}}
+	}
+	state := SkillResourceModel{RegistryState: registryState(t, "v2", "v3")}
+
+	tests := map[string]struct {
+		anthropic     []AnthropicBlockModel
+		state         SkillResourceModel
+		wantReconcile bool
+		wantWarnings  int
+	}{
+		"warn":                     {anthropic: anthropicBlock(versionDriftWarn, true), state: state, wantWarnings: 1},
+		"reconcile":                {anthropic: anthropicBlock(versionDriftReconcile, true), state: state, wantReconcile: true},
+		"reconcile without auto":   {anthropic: anthropicBlock(versionDriftReconcile, false), state: state, wantWarnings: 1},
+		"no drift":                 {anthropic: anthropicBlock(versionDriftReconcile, true), state: SkillResourceModel{RegistryState: registryState(t, "v3", "v3")}},
+		"anthropic block disabled": {state: state},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			plan := SkillResourceModel{Anthropic: tt.anthropic, RegistryState: registryState(t, "v2", "v3")}
+			reconcile, diags := planVersionDrift(context.Background(), &plan, tt.state)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if reconcile != tt.wantReconcile || plan.RegistryState.IsUnknown() != tt.wantReconcile {
+				t.Errorf("reconcile = %v, registry_state unknown = %v; want %v", reconcile, plan.RegistryState.IsUnknown(), tt.wantReconcile)
+			}
+			if diags.WarningsCount() != tt.wantWarnings {
+				t.Errorf("warnings = %d, want %d", diags.WarningsCount(), tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package skill
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+)
+
+// urlPath returns the path of rawURL, or rawURL itself if it does not
+// parse, for deriving the archive name.
+func urlPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
+
+// openURLSource downloads the archive at rawURL, checks it against
+// checksum, and extracts it. The skill is named after the archive in the
+// URL path.
+func openURLSource(ctx context.Context, rawURL, checksum string) (*skillSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("source_url %q must be an http or https URL", rawURL)
+	}
+	name, err := bundle.ArchiveName(u.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := newTempSource(sourceKindURL, name, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	src.origin = rawURL
+
+	// Keep the archive's extension, which selects its format.
+	archivePath := filepath.Join(src.tmpDir, "archive-"+path.Base(u.Path))
+	if err := downloadArchive(ctx, rawURL, checksum, archivePath); err != nil {
+		src.close()
+		return nil, err
+	}
+	if err := bundle.ExtractArchive(archivePath, src.dir); err != nil {
+		src.close()
+		return nil, err
+	}
+	return src, nil
+}
+
+// downloadArchive writes the body of a GET of rawURL to dst and returns an
+// error unless its SHA-256 matches checksum.
+func downloadArchive(ctx context.Context, rawURL, checksum, dst string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("download source: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("download source: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download source: %s returned %s", rawURL, resp.Status)
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("download source: %w", err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(resp.Body, bundle.MaxArchiveSize+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("download source: %w", err)
+	}
+	if n > bundle.MaxArchiveSize {
+		return fmt.Errorf("download source: %s is larger than %d bytes", rawURL, int64(bundle.MaxArchiveSize))
+	}
+
+	if got := fmt.Sprintf("sha256:%x", h.Sum(nil)); got != checksum {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", rawURL, got, checksum)
+	}
+	return nil
+}
+
+// checkSubdir returns the cleaned slash-separated form of a source_git
+// subdir, or an error when it leaves the repository.
+func checkSubdir(subdir string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(subdir, "\\", "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("subdir %q must be a relative path inside the repository", subdir)
+	}
+	return clean, nil
+}
+
+// gitSchemes are the URL schemes a source_git url may use.
+var gitSchemes = map[string]bool{"https": true, "ssh": true, "git": true}
+
+// scpLikePattern matches the scp-like form git accepts for SSH URLs,
+// user@host:path.
+var scpLikePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^/]`)
+
+// checkGitURL returns an error when repoURL is not a source_git url: an
+// https, ssh, or git URL, or the scp-like user@host:path. Other forms are
+// refused because git would run them as options or through transports
+// that execute commands, such as ext::.
+func checkGitURL(repoURL string) error {
+	if strings.HasPrefix(repoURL, "-") {
+		return fmt.Errorf("url %q must not start with \"-\"", repoURL)
+	}
+	if scpLikePattern.MatchString(repoURL) {
+		return nil
+	}
+	if u, err := url.Parse(repoURL); err == nil && gitSchemes[strings.ToLower(u.Scheme)] && u.Host != "" {
+		return nil
+	}
+	return fmt.Errorf("url %q must be an https://, ssh://, or git:// URL, or an SSH address like git@example.com:org/repo.git", repoURL)
+}
+
+// checkGitRef returns an error when ref would be read by git as an option.
+func checkGitRef(ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("ref %q must not start with \"-\"", ref)
+	}
+	return nil
+}
+
+// gitSourceName returns the skill name of a source_git: the base name of
+// subdir, or the repository name when subdir is empty.
+func gitSourceName(repoURL, subdir string) string {
+	if subdir != "" && subdir != "." {
+		return path.Base(subdir)
+	}
+	name := strings.TrimRight(repoURL, "/")
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, ".git")
+}
+
+// gitSourceSpec formats a git source in the go-getter style Terraform uses
+// for module sources: git::<url>//<subdir>?ref=<ref>.
+func gitSourceSpec(repoURL, subdir, ref string) string {
+	spec := "git::" + repoURL
+	if subdir != "" && subdir != "." {
+		spec += "//" + subdir
+	}
+	return spec + "?ref=" + url.QueryEscape(ref)
+}
+
+// openGitSource fetches ref of the source_git repository with the git
+// command, checks that it resolves to the pinned commit, and returns the
+// subdirectory. Only the one commit is fetched. The url and ref are checked
+// again here, since validateSource skips values unknown at plan time.
+func openGitSource(ctx context.Context, g SourceGitBlockModel) (*skillSource, error) {
+	repoURL, ref := g.URL.ValueString(), g.Ref.ValueString()
+	if err := checkGitURL(repoURL); err != nil {
+		return nil, err
+	}
+	if err := checkGitRef(ref); err != nil {
+		return nil, err
+	}
+	subdir, err := checkSubdir(g.Subdir.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	name := gitSourceName(repoURL, subdir)
+	if name == "" {
+		return nil, fmt.Errorf("cannot derive a skill name from source_git url %q; set subdir", repoURL)
+	}
+
+	src, err := newTempSource(sourceKindGit, name, gitSourceSpec(repoURL, subdir, ref))
+	if err != nil {
+		return nil, err
+	}
+	checkout := filepath.Join(src.tmpDir, ".checkout")
+
+	commit, err := gitCheckout(ctx, checkout, repoURL, ref)
+	if err != nil {
+		src.close()
+		return nil, err
+	}
+	if want := g.Commit.ValueString(); want != "" && commit != want {
+		src.close()
+		return nil, fmt.Errorf("ref %q of %s resolves to commit %s, want %s", ref, repoURL, commit, want)
+	}
+	src.origin = gitSourceSpec(repoURL, subdir, commit)
+
+	// Move the skill directory into place and drop the rest of the
+	// checkout, .git included.
+	skillDir := filepath.Join(checkout, filepath.FromSlash(subdir))
+	if info, err := os.Stat(skillDir); err != nil || !info.IsDir() {
+		src.close()
+		return nil, fmt.Errorf("subdir %q is not a directory at commit %s of %s", subdir, commit, repoURL)
+	}
+	err = os.Remove(src.dir)
+	if err == nil {
+		err = os.Rename(skillDir, src.dir)
+	}
+	if err == nil {
+		err = os.RemoveAll(checkout)
+	}
+	if err == nil {
+		err = os.RemoveAll(filepath.Join(src.dir, ".git"))
+	}
+	if err != nil {
+		src.close()
+		return nil, fmt.Errorf("prepare git checkout: %w", err)
+	}
+	return src, nil
+}
+
+// gitCheckout fetches ref of repoURL into a new repository at dir, checks
+// it out, and returns the commit ID.
+func gitCheckout(ctx context.Context, dir, repoURL, ref string) (string, error) {
+	steps := [][]string{
+		{"init", "--quiet", dir},
+		{"-C", dir, "fetch", "--quiet", "--depth", "1", "--no-tags", "--", repoURL, ref},
+		{"-C", dir, "-c", "advice.detachedHead=false", "checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if _, err := runGit(ctx, args...); err != nil {
+			return "", err
+		}
+	}
+	out, err := runGit(ctx, "-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// runGit runs git with args and returns its standard output. Prompts for
+// credentials are disabled, so that a private repository without
+// configured credentials fails instead of hanging the apply.
+func runGit(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("git %s: %w", gitCommand(args), err)
+		}
+		return "", fmt.Errorf("git %s: %w\n%s", gitCommand(args), err, msg)
+	}
+	return stdout.String(), nil
+}
+
+// gitCommand returns the git subcommand in args, skipping -C and -c
+// options.
+func gitCommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-C", "-c":
+			i++
+		default:
+			return args[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package skill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+)
+
+// defaultReplicationTimeout is how long a replica may trail its primary
+// when the target block does not set replication_timeout_seconds.
+const defaultReplicationTimeout = 300 * time.Second
+
+// writableTargets returns the names of the configured targets that are not
+// replicas.
+func (r *SkillResource) writableTargets() []string {
+	var names []string
+	for _, name := range r.providerData.Targets.Names() {
+		if cfg, _ := r.providerData.Targets.Config(name); cfg.ReplicaOf.ValueString() == "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// verifyReplicas waits, in parallel, for every replica of the targets in
+// deployIDByTarget to serve the deployment made to its primary. Replicas
+// that do not catch up within their timeout, or cannot be read, produce a
+// ReplicationLag warning.
+func (r *SkillResource) verifyReplicas(ctx context.Context, eng *engine.Engine, skillName string, deployIDByTarget map[string]string) diag.Diagnostics {
+	type check struct {
+		primary, replica, deploymentID string
+		timeout                        time.Duration
+	}
+	var checks []check
+	for primary, deploymentID := range deployIDByTarget {
+		for _, replica := range providerdata.ReplicasOf(r.providerData.Targets, primary) {
+			timeout := defaultReplicationTimeout
+			if cfg, _ := r.providerData.Targets.Config(replica); !cfg.ReplicationTimeoutSeconds.IsNull() && !cfg.ReplicationTimeoutSeconds.IsUnknown() {
+				timeout = time.Duration(cfg.ReplicationTimeoutSeconds.ValueInt64()) * time.Second
+			}
+			checks = append(checks, check{primary, replica, deploymentID, timeout})
+		}
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].replica < checks[j].replica })
+
+	var (
+		wg       sync.WaitGroup
+		failures = make([]error, len(checks))
+	)
+	for i, c := range checks {
+		t, _ := r.providerData.Targets.Get(c.replica)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := eng.VerifyReplica(ctx, t, skillName, c.deploymentID, c.timeout)
+			tflog.Debug(ctx, "verified replica target", map[string]interface{}{
+				"skill_name": skillName,
+				"primary":    c.primary,
+				"replica":    c.replica,
+				"elapsed_ms": time.Since(start).Milliseconds(),
+				"ok":         err == nil,
+			})
+			failures[i] = err
+		}()
+	}
+	wg.Wait()
+
+	var diags diag.Diagnostics
+	for i, c := range checks {
+		err := failures[i]
+		if err == nil {
+			continue
+		}
+		detail := fmt.Sprintf("Replica target %q did not serve deployment %s of skill %q within %s after it was deployed to %q: %s.",
+			c.replica, c.deploymentID, skillName, c.timeout, c.primary, err)
+		if errors.Is(err, engine.ErrReplicationLag) {
+			detail += " Clients reading from the replica see the previous deployment until replication catches up; " +
+				"check the bucket's replication status or raise replication_timeout_seconds."
+		}
+		diags.AddWarning(errcode.ReplicationLag.Summary("Replication Lag"), detail)
+	}
+	return diags
+}
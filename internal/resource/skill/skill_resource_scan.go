@@ -0,0 +1,44 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/contentscan"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// scanContent runs the content_scan block of plan over the Markdown files
+// of b. It reports nothing when there is no block, or while its values are
+// unknown; apply scans the bundle again once they are known.
+func scanContent(ctx context.Context, plan SkillResourceModel, b *bundle.Bundle) diag.Diagnostics {
+	opts, policy, ok, diags := contentscan.Config(ctx, plan.ContentScan)
+	if !ok {
+		return diags
+	}
+
+	var findings []contentscan.Finding
+	for _, f := range b.Files {
+		if !contentscan.IsMarkdown(f.RelPath) || opts.Skip(f.RelPath) {
+			continue
+		}
+		data, err := os.ReadFile(longpath.Path(f.AbsPath))
+		if err != nil {
+			diags.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read %q for content_scan: %s", f.RelPath, err))
+			return diags
+		}
+		findings = append(findings, opts.File(f.RelPath, data)...)
+	}
+
+	name := plan.SkillName.ValueString()
+	if b.Skill != nil && b.Skill.Name != "" {
+		name = b.Skill.Name
+	}
+	diags.Append(contentscan.Report(findings, policy, fmt.Sprintf("skill %q", name))...)
+	return diags
+}
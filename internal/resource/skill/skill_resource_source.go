@@ -0,0 +1,210 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// Source attributes of agentctx_skill. Exactly one of them is set.
+const (
+	sourceKindDir     = "source_dir"
+	sourceKindArchive = "source_archive"
+	sourceKindURL     = "source_url"
+	sourceKindGit     = "source_git"
+)
+
+// checksumPattern matches a source_checksum.
+var checksumPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// commitPattern matches a full SHA-1 or SHA-256 git commit ID.
+var commitPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// skillSource is the local directory a skill bundle is scanned from: either
+// source_dir itself, or a temporary extraction of source_archive, a
+// download of source_url, or a checkout of source_git.
+type skillSource struct {
+	// dir is the directory to scan and upload.
+	dir string
+	// name is the derived skill name. See skillSourceName.
+	name string
+	// kind is the source attribute the plan sets, e.g. sourceKindDir.
+	kind string
+	// path identifies the source in messages and idempotency keys, which
+	// must not depend on the temporary directory: the configured
+	// source_dir, source_archive or source_url, or the source_git URL,
+	// subdirectory and ref.
+	path string
+	// origin is recorded in deployment manifests and provenance: the
+	// absolute source_archive, the source_url, or the source_git URL,
+	// subdirectory and resolved commit. Empty for source_dir.
+	origin string
+	// tmpDir is removed by close.
+	tmpDir string
+}
+
+// validateSource checks that m sets exactly one source, and that remote
+// sources are pinned to their content.
+func validateSource(m SkillResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if m.SourceDir.IsUnknown() || m.SourceArchive.IsUnknown() || m.SourceURL.IsUnknown() {
+		return diags
+	}
+
+	set := 0
+	for _, v := range []bool{!m.SourceDir.IsNull(), !m.SourceArchive.IsNull(), !m.SourceURL.IsNull(), len(m.SourceGit) > 0} {
+		if v {
+			set++
+		}
+	}
+	if set != 1 {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Skill Source"),
+			"Exactly one of source_dir, source_archive, source_url, and a source_git block must be set.",
+		)
+		return diags
+	}
+
+	if !m.SourceURL.IsNull() {
+		if !m.SourceChecksum.IsUnknown() && !checksumPattern.MatchString(m.SourceChecksum.ValueString()) {
+			diags.AddAttributeError(path.Root("source_checksum"), errcode.InvalidConfig.Summary("Invalid Source Checksum"),
+				fmt.Sprintf("source_url requires source_checksum, the SHA-256 of the archive as \"sha256:<hex>\", got %q.", m.SourceChecksum.ValueString()))
+		}
+		if _, err := bundle.ArchiveName(urlPath(m.SourceURL.ValueString())); err != nil {
+			diags.AddAttributeError(path.Root("source_url"), errcode.InvalidConfig.Summary("Invalid Source URL"), err.Error())
+		}
+	} else if !m.SourceChecksum.IsNull() {
+		diags.AddAttributeError(path.Root("source_checksum"), errcode.InvalidConfig.Summary("Invalid Source Checksum"),
+			"source_checksum is only used with source_url.")
+	}
+
+	if len(m.SourceGit) == 1 {
+		g := m.SourceGit[0]
+		blockPath := path.Root("source_git").AtListIndex(0)
+		if !g.URL.IsNull() && !g.URL.IsUnknown() {
+			if err := checkGitURL(g.URL.ValueString()); err != nil {
+				diags.AddAttributeError(blockPath.AtName("url"), errcode.InvalidConfig.Summary("Invalid Source URL"), err.Error())
+			}
+		}
+		if g.Ref.IsUnknown() || g.Commit.IsUnknown() {
+			return diags
+		}
+		if err := checkGitRef(g.Ref.ValueString()); err != nil {
+			diags.AddAttributeError(blockPath.AtName("ref"), errcode.InvalidConfig.Summary("Invalid Source Ref"), err.Error())
+			return diags
+		}
+		switch {
+		case !g.Commit.IsNull() && !commitPattern.MatchString(g.Commit.ValueString()):
+			diags.AddAttributeError(blockPath.AtName("commit"), errcode.InvalidConfig.Summary("Invalid Source Commit"),
+				fmt.Sprintf("commit must be a full commit ID, got %q.", g.Commit.ValueString()))
+		case g.Commit.IsNull() && !commitPattern.MatchString(g.Ref.ValueString()):
+			diags.AddAttributeError(blockPath.AtName("ref"), errcode.InvalidConfig.Summary("Unpinned Git Source"),
+				fmt.Sprintf("ref %q is not a full commit ID, so the content it names can change without a change to the configuration. Set commit to the commit ID ref is expected to resolve to.", g.Ref.ValueString()))
+		}
+		if !g.Subdir.IsNull() && !g.Subdir.IsUnknown() {
+			if _, err := checkSubdir(g.Subdir.ValueString()); err != nil {
+				diags.AddAttributeError(blockPath.AtName("subdir"), errcode.InvalidConfig.Summary("Invalid Source Subdirectory"), err.Error())
+			}
+		}
+	}
+	return diags
+}
+
+// isRemoteSource reports whether plan fetches its source at apply time.
+func isRemoteSource(plan SkillResourceModel) bool {
+	return !plan.SourceURL.IsNull() || len(plan.SourceGit) > 0
+}
+
+// openSkillSource returns the skill source configured in plan. Sources
+// other than source_dir are extracted, downloaded or checked out into a
+// temporary directory named after the skill, so that uploads nest files
+// under the same top-level directory as source_dir would; call close to
+// remove it.
+func openSkillSource(ctx context.Context, plan SkillResourceModel) (*skillSource, error) {
+	switch {
+	case !plan.SourceArchive.IsNull() && plan.SourceArchive.ValueString() != "":
+		archivePath := plan.SourceArchive.ValueString()
+		name, err := bundle.ArchiveName(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		absArchive, err := filepath.Abs(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("resolve source archive: %w", err)
+		}
+		src, err := newTempSource(sourceKindArchive, name, archivePath)
+		if err != nil {
+			return nil, err
+		}
+		src.origin = absArchive
+		if err := bundle.ExtractArchive(absArchive, src.dir); err != nil {
+			src.close()
+			return nil, err
+		}
+		return src, nil
+
+	case !plan.SourceURL.IsNull():
+		return openURLSource(ctx, plan.SourceURL.ValueString(), plan.SourceChecksum.ValueString())
+
+	case len(plan.SourceGit) == 1:
+		return openGitSource(ctx, plan.SourceGit[0])
+	}
+
+	sourceDir := plan.SourceDir.ValueString()
+	return &skillSource{dir: sourceDir, name: filepath.Base(sourceDir), kind: sourceKindDir, path: sourceDir}, nil
+}
+
+// newTempSource returns a source of the given kind whose dir is an empty
+// temporary directory named name.
+func newTempSource(kind, name, sourcePath string) (*skillSource, error) {
+	tmpDir, err := os.MkdirTemp("", "agentctx-skill-")
+	if err != nil {
+		return nil, fmt.Errorf("create temporary directory: %w", err)
+	}
+	src := &skillSource{
+		dir:    filepath.Join(tmpDir, name),
+		name:   name,
+		kind:   kind,
+		path:   sourcePath,
+		tmpDir: tmpDir,
+	}
+	if err := os.Mkdir(src.dir, 0o755); err != nil {
+		src.close()
+		return nil, fmt.Errorf("create temporary directory: %w", err)
+	}
+	return src, nil
+}
+
+// sourceDir returns the directory recorded as the deployment's origin:
+// source_dir, or "" for a source whose directory is temporary.
+func (s *skillSource) sourceDir() string {
+	if s.kind != sourceKindDir {
+		return ""
+	}
+	return s.dir
+}
+
+// originOf returns the origin of s if it is of the given kind, or "".
+func (s *skillSource) originOf(kinds ...string) string {
+	for _, kind := range kinds {
+		if s.kind == kind {
+			return s.origin
+		}
+	}
+	return ""
+}
+
+// close removes the temporary directory of s, if any.
+func (s *skillSource) close() {
+	if s.tmpDir != "" {
+		os.RemoveAll(s.tmpDir)
+	}
+}
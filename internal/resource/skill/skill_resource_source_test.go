@@ -0,0 +1,347 @@
+package skill
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// sourceModel returns a model with every source attribute null.
+func sourceModel() SkillResourceModel {
+	return SkillResourceModel{
+		SourceDir:      types.StringNull(),
+		SourceArchive:  types.StringNull(),
+		SourceURL:      types.StringNull(),
+		SourceChecksum: types.StringNull(),
+	}
+}
+
+func testZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpenSkillSource_Archive(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "report-writer.zip")
+	if err := os.WriteFile(archivePath, testZip(t, map[string]string{"SKILL.md": "# Reports\n"}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := sourceModel()
+	m.SourceArchive = types.StringValue(archivePath)
+	src, err := openSkillSource(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Uploads nest files under the base name of the directory, so the
+	// extraction must be named after the skill.
+	if src.name != "report-writer" || filepath.Base(src.dir) != "report-writer" {
+		t.Errorf("name = %q, dir = %q; want both named report-writer", src.name, src.dir)
+	}
+	if src.sourceDir() != "" || src.originOf(sourceKindArchive) != archivePath {
+		t.Errorf("sourceDir() = %q, origin = %q; want the archive as the origin", src.sourceDir(), src.origin)
+	}
+	if _, err := os.Stat(filepath.Join(src.dir, "SKILL.md")); err != nil {
+		t.Errorf("SKILL.md not extracted: %v", err)
+	}
+
+	src.close()
+	if _, err := os.Stat(src.dir); !os.IsNotExist(err) {
+		t.Errorf("extraction not removed by close: %v", err)
+	}
+}
+
+func TestOpenSkillSource_Dir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "report-writer")
+	m := sourceModel()
+	m.SourceDir = types.StringValue(dir)
+	src, err := openSkillSource(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.close()
+	if src.dir != dir || src.name != "report-writer" || src.sourceDir() != dir {
+		t.Errorf("source = %+v, want source_dir used as is", src)
+	}
+}
+
+func TestOpenSkillSource_URL(t *testing.T) {
+	archive := testZip(t, map[string]string{"report-writer/SKILL.md": "# Reports\n"})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	m := sourceModel()
+	m.SourceURL = types.StringValue(srv.URL + "/releases/report-writer.zip")
+	m.SourceChecksum = types.StringValue(fmt.Sprintf("sha256:%x", sha256.Sum256(archive)))
+	src, err := openSkillSource(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.close()
+	if src.name != "report-writer" || src.originOf(sourceKindURL, sourceKindGit) != m.SourceURL.ValueString() {
+		t.Errorf("name = %q, origin = %q", src.name, src.origin)
+	}
+	if _, err := os.Stat(filepath.Join(src.dir, "SKILL.md")); err != nil {
+		t.Errorf("SKILL.md not extracted: %v", err)
+	}
+
+	m.SourceChecksum = types.StringValue("sha256:" + strings.Repeat("0", 64))
+	if _, err := openSkillSource(context.Background(), m); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("err = %v, want a checksum mismatch", err)
+	}
+}
+
+func TestOpenSkillSource_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	if err := os.MkdirAll(filepath.Join(repo, "skills", "report-writer"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "skills", "report-writer", "SKILL.md"), []byte("# Reports\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# Skills\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "--quiet", "-m", "Add report-writer")
+	commit := git("rev-parse", "HEAD")
+
+	// source_git refuses file URLs; allow them to fetch the local
+	// repository.
+	gitSchemes["file"] = true
+	t.Cleanup(func() { delete(gitSchemes, "file") })
+
+	m := sourceModel()
+	m.SourceGit = []SourceGitBlockModel{{
+		URL:    types.StringValue("file://localhost" + repo),
+		Ref:    types.StringValue("main"),
+		Subdir: types.StringValue("skills/report-writer"),
+		Commit: types.StringValue(commit),
+	}}
+	src, err := openSkillSource(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.close()
+
+	if src.name != "report-writer" || filepath.Base(src.dir) != "report-writer" {
+		t.Errorf("name = %q, dir = %q; want both named report-writer", src.name, src.dir)
+	}
+	if want := "git::file://localhost" + repo + "//skills/report-writer?ref=" + commit; src.origin != want {
+		t.Errorf("origin = %q, want %q", src.origin, want)
+	}
+	entries, err := os.ReadDir(src.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "SKILL.md" {
+		t.Errorf("checkout = %v, want only the subdirectory's SKILL.md", entries)
+	}
+
+	m.SourceGit[0].Commit = types.StringValue(strings.Repeat("0", 40))
+	if _, err := openSkillSource(context.Background(), m); err == nil || !strings.Contains(err.Error(), "resolves to commit") {
+		t.Errorf("err = %v, want a commit mismatch", err)
+	}
+}
+
+func TestOpenGitSource_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		ref     string
+		wantErr string
+	}{
+		{"file url", "file:///srv/skills.git", "main", "must be an https://"},
+		{"option-like ref", "https://example.com/skills.git", "--upload-pack=touch /tmp/pwned", "must not start with"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := openGitSource(context.Background(), SourceGitBlockModel{
+				URL:    types.StringValue(tt.url),
+				Ref:    types.StringValue(tt.ref),
+				Subdir: types.StringNull(),
+				Commit: types.StringNull(),
+			})
+			if err == nil {
+				src.close()
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSource(t *testing.T) {
+	commit := strings.Repeat("a", 40)
+	gitURLBlock := func(repoURL, ref, commit string) []SourceGitBlockModel {
+		c := types.StringNull()
+		if commit != "" {
+			c = types.StringValue(commit)
+		}
+		return []SourceGitBlockModel{{
+			URL:    types.StringValue(repoURL),
+			Ref:    types.StringValue(ref),
+			Subdir: types.StringNull(),
+			Commit: c,
+		}}
+	}
+	gitBlock := func(ref, commit string) []SourceGitBlockModel {
+		return gitURLBlock("https://example.com/skills.git", ref, commit)
+	}
+
+	tests := map[string]struct {
+		modify  func(*SkillResourceModel)
+		wantErr string
+	}{
+		"dir": {
+			modify: func(m *SkillResourceModel) { m.SourceDir = types.StringValue("skills/a") },
+		},
+		"none": {
+			modify:  func(*SkillResourceModel) {},
+			wantErr: "Exactly one of",
+		},
+		"dir and git": {
+			modify: func(m *SkillResourceModel) {
+				m.SourceDir = types.StringValue("skills/a")
+				m.SourceGit = gitBlock(commit, "")
+			},
+			wantErr: "Exactly one of",
+		},
+		"url without checksum": {
+			modify:  func(m *SkillResourceModel) { m.SourceURL = types.StringValue("https://example.com/a.zip") },
+			wantErr: "requires source_checksum",
+		},
+		"url with checksum": {
+			modify: func(m *SkillResourceModel) {
+				m.SourceURL = types.StringValue("https://example.com/a.zip")
+				m.SourceChecksum = types.StringValue("sha256:" + strings.Repeat("0", 64))
+			},
+		},
+		"url without archive extension": {
+			modify: func(m *SkillResourceModel) {
+				m.SourceURL = types.StringValue("https://example.com/a")
+				m.SourceChecksum = types.StringValue("sha256:" + strings.Repeat("0", 64))
+			},
+			wantErr: "must be a .zip",
+		},
+		"checksum without url": {
+			modify: func(m *SkillResourceModel) {
+				m.SourceDir = types.StringValue("skills/a")
+				m.SourceChecksum = types.StringValue("sha256:" + strings.Repeat("0", 64))
+			},
+			wantErr: "only used with source_url",
+		},
+		"git pinned by ref": {
+			modify: func(m *SkillResourceModel) { m.SourceGit = gitBlock(commit, "") },
+		},
+		"git pinned by commit": {
+			modify: func(m *SkillResourceModel) { m.SourceGit = gitBlock("v1.2.0", commit) },
+		},
+		"git unpinned": {
+			modify:  func(m *SkillResourceModel) { m.SourceGit = gitBlock("main", "") },
+			wantErr: "not a full commit ID",
+		},
+		"git short commit": {
+			modify:  func(m *SkillResourceModel) { m.SourceGit = gitBlock("main", "abc123") },
+			wantErr: "must be a full commit ID",
+		},
+		"git ssh url": {
+			modify: func(m *SkillResourceModel) { m.SourceGit = gitURLBlock("ssh://git@example.com/skills.git", commit, "") },
+		},
+		"git scp-like url": {
+			modify: func(m *SkillResourceModel) { m.SourceGit = gitURLBlock("git@example.com:acme/skills.git", commit, "") },
+		},
+		"git option url": {
+			modify:  func(m *SkillResourceModel) { m.SourceGit = gitURLBlock("--upload-pack=touch /tmp/pwned", commit, "") },
+			wantErr: `must not start with "-"`,
+		},
+		"git ext url": {
+			modify:  func(m *SkillResourceModel) { m.SourceGit = gitURLBlock("ext::sh -c touch% /tmp/pwned", commit, "") },
+			wantErr: "must be an https://",
+		},
+		"git file url": {
+			modify:  func(m *SkillResourceModel) { m.SourceGit = gitURLBlock("file:///srv/skills.git", commit, "") },
+			wantErr: "must be an https://",
+		},
+		"git option ref": {
+			modify:  func(m *SkillResourceModel) { m.SourceGit = gitBlock("--upload-pack=touch /tmp/pwned", commit) },
+			wantErr: `must not start with "-"`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := sourceModel()
+			tt.modify(&m)
+			diags := validateSource(m)
+			if tt.wantErr == "" {
+				if diags.HasError() {
+					t.Fatalf("unexpected error: %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), tt.wantErr) {
+				t.Fatalf("diags = %v, want an error containing %q", diags, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitSourceName(t *testing.T) {
+	cases := []struct{ url, subdir, want string }{
+		{"https://github.com/acme/report-writer.git", ".", "report-writer"},
+		{"git@github.com:acme/report-writer.git", ".", "report-writer"},
+		{"https://github.com/acme/skills", "skills/summarizer", "summarizer"},
+	}
+	for _, c := range cases {
+		if got := gitSourceName(c.url, c.subdir); got != c.want {
+			t.Errorf("gitSourceName(%q, %q) = %q, want %q", c.url, c.subdir, got, c.want)
+		}
+	}
+}
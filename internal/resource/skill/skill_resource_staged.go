@@ -0,0 +1,51 @@
+package skill
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// Values of deployment_strategy.
+const (
+	deploymentStrategyImmediate = "immediate"
+	deploymentStrategyStaged    = "staged"
+)
+
+// stageOnly returns the engine.DeployInput.StageOnly for m: whether its
+// deployments are uploaded without moving ACTIVE.
+func stageOnly(m SkillResourceModel) bool {
+	return m.DeploymentStrategy.ValueString() == deploymentStrategyStaged
+}
+
+// validateDeploymentStrategy checks that a staged deployment_strategy is not
+// combined with a canary or verify block, which both act when ACTIVE moves
+// and so never would.
+func validateDeploymentStrategy(m SkillResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !stageOnly(m) {
+		return diags
+	}
+	if len(m.Canary) > 0 {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Deployment Strategy"),
+			"A canary block cannot be combined with deployment_strategy = \"staged\": staged deployments do not move ACTIVE. "+
+				"Remove the canary block, or promote staged deployments with agentctx_skill_promotion.",
+		)
+	}
+	if len(m.Verify) > 0 {
+		diags.AddError(
+			errcode.InvalidConfig.Summary("Invalid Deployment Strategy"),
+			"A verify block cannot be combined with deployment_strategy = \"staged\": verification runs once ACTIVE moves, and staged deployments do not move it.",
+		)
+	}
+	return diags
+}
+
+// promotionPending reports whether, for a skill configured as m, ACTIVE on
+// a target has yet to be moved to stagedID, the deployment the last apply
+// staged there. ACTIVE then still selects an older deployment, which is not
+// drift.
+func promotionPending(m SkillResourceModel, stagedID, activeID string) bool {
+	return stageOnly(m) && stagedID != "" && stagedID != activeID
+}
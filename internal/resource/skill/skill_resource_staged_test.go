@@ -0,0 +1,59 @@
+package skill
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestValidateDeploymentStrategy(t *testing.T) {
+	staged := types.StringValue(deploymentStrategyStaged)
+	tests := []struct {
+		name    string
+		model   SkillResourceModel
+		wantErr bool
+	}{
+		{"immediate with canary", SkillResourceModel{
+			DeploymentStrategy: types.StringValue(deploymentStrategyImmediate),
+			Canary:             []CanaryBlockModel{{Weight: types.Int64Value(10)}},
+		}, false},
+		{"staged", SkillResourceModel{DeploymentStrategy: staged}, false},
+		{"staged with canary", SkillResourceModel{
+			DeploymentStrategy: staged,
+			Canary:             []CanaryBlockModel{{Weight: types.Int64Value(10)}},
+		}, true},
+		{"staged with verify", SkillResourceModel{
+			DeploymentStrategy: staged,
+			Verify:             []VerifyBlockModel{{URL: types.StringValue("https://example.com/health")}},
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateDeploymentStrategy(tt.model)
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("validateDeploymentStrategy: error = %v, want %v (%v)", diags.HasError(), tt.wantErr, diags)
+			}
+		})
+	}
+}
+
+func TestPromotionPending(t *testing.T) {
+	staged := SkillResourceModel{DeploymentStrategy: types.StringValue(deploymentStrategyStaged)}
+	immediate := SkillResourceModel{DeploymentStrategy: types.StringValue(deploymentStrategyImmediate)}
+
+	if !promotionPending(staged, "dep-2", "dep-1") {
+		t.Error("staged deployment not yet active: want pending")
+	}
+	if !promotionPending(staged, "dep-1", "") {
+		t.Error("staged first deployment without ACTIVE: want pending")
+	}
+	if promotionPending(staged, "dep-2", "dep-2") {
+		t.Error("promoted deployment: want not pending")
+	}
+	if promotionPending(staged, "", "dep-1") {
+		t.Error("nothing staged: want not pending")
+	}
+	if promotionPending(immediate, "dep-2", "dep-1") {
+		t.Error("immediate strategy: a staged deployment is a failed deploy, not a pending promotion")
+	}
+}
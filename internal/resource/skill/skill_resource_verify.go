@@ -0,0 +1,175 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// verifyOutputLimit is how much of a failing verify command's output is
+// quoted in the error, from the end, where the reason usually is.
+const verifyOutputLimit = 2048
+
+// validateVerify checks that the verify block sets exactly one of command
+// and url, and that both are valid templates.
+func validateVerify(m SkillResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(m.Verify) == 0 {
+		return diags
+	}
+	v := m.Verify[0]
+	blockPath := path.Root("verify").AtListIndex(0)
+
+	if v.Command.IsUnknown() || v.URL.IsUnknown() {
+		return diags
+	}
+	if v.Command.IsNull() == v.URL.IsNull() {
+		diags.AddAttributeError(blockPath, errcode.InvalidConfig.Summary("Invalid Verify Configuration"), "The verify block must set exactly one of command and url.")
+		return diags
+	}
+
+	for name, value := range map[string]string{"command": v.Command.ValueString(), "url": v.URL.ValueString()} {
+		if _, err := parseVerifyTemplate(name, value); err != nil {
+			diags.AddAttributeError(blockPath.AtName(name), errcode.InvalidConfig.Summary("Invalid Verify Template"), err.Error())
+		}
+	}
+	return diags
+}
+
+// parseVerifyTemplate parses a command or url template. Referencing a field
+// engine.VerifyInfo does not have fails when the template is executed.
+func parseVerifyTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// verifier returns the engine.Verifier for m's verify block, or nil when
+// there is none. The block was validated at plan time.
+func verifier(m SkillResourceModel) engine.Verifier {
+	if len(m.Verify) == 0 {
+		return nil
+	}
+	v := m.Verify[0]
+	timeout := time.Duration(v.TimeoutSeconds.ValueInt64()) * time.Second
+
+	return func(ctx context.Context, info engine.VerifyInfo) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		tflog.Info(ctx, "verifying deployment", map[string]interface{}{
+			"target":        info.Target,
+			"deployment_id": info.DeploymentID,
+		})
+		if !v.Command.IsNull() {
+			cmd, err := renderVerifyTemplate("command", v.Command.ValueString(), info)
+			if err != nil {
+				return err
+			}
+			return runVerifyCommand(ctx, cmd, info)
+		}
+		url, err := renderVerifyTemplate("url", v.URL.ValueString(), info)
+		if err != nil {
+			return err
+		}
+		return checkVerifyURL(ctx, url, int(v.ExpectedStatus.ValueInt64()))
+	}
+}
+
+// renderVerifyTemplate executes the command or url template text with info.
+func renderVerifyTemplate(name, text string, info engine.VerifyInfo) (string, error) {
+	tmpl, err := parseVerifyTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, info); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// runVerifyCommand runs command with the system shell. The deployment is
+// also described in AGENTCTX_* environment variables, which are safer to
+// use than template values in shell syntax.
+func runVerifyCommand(ctx context.Context, command string, info engine.VerifyInfo) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"AGENTCTX_TARGET="+info.Target,
+		"AGENTCTX_SKILL_NAME="+info.SkillName,
+		"AGENTCTX_DEPLOYMENT_ID="+info.DeploymentID,
+		"AGENTCTX_PREVIOUS_DEPLOYMENT_ID="+info.PreviousDeploymentID,
+		"AGENTCTX_BUNDLE_HASH="+info.BundleHash,
+	)
+	// Children of the shell may outlive it on timeout and keep the output
+	// pipe open; stop waiting for them shortly after.
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out")
+	}
+	output := strings.TrimSpace(string(out))
+	if len(output) > verifyOutputLimit {
+		output = "..." + output[len(output)-verifyOutputLimit:]
+	}
+	if output == "" {
+		return fmt.Errorf("verify command failed: %w", err)
+	}
+	return fmt.Errorf("verify command failed: %w\n%s", err, output)
+}
+
+// checkVerifyURL sends a GET request to url and returns an error unless it
+// answers with wantStatus.
+func checkVerifyURL(ctx context.Context, url string, wantStatus int) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("verify url: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("verify url: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		return fmt.Errorf("verify url %s returned %s, want %d", url, resp.Status, wantStatus)
+	}
+	return nil
+}
+
+// verifyFailedDiag describes a deployment of skillName to tName that its
+// verify block rejected.
+func verifyFailedDiag(skillName, tName string, err *engine.VerifyError) diag.Diagnostic {
+	if !err.RolledBack {
+		return diag.NewErrorDiagnostic(
+			errcode.VerifyFailed.Summary("Deployment Verification Failed"),
+			fmt.Sprintf("Deployment %s of skill %q on target %q failed verification: %s\n\n"+
+				"Rolling back the ACTIVE pointer also failed (%s), so the rejected deployment may still be active. "+
+				"Run terraform refresh to read the current pointer before applying again.", err.DeploymentID, skillName, tName, err.Err, err.RollbackErr),
+		)
+	}
+	return diag.NewErrorDiagnostic(
+		errcode.VerifyFailed.Summary("Deployment Verification Failed"),
+		fmt.Sprintf("Deployment %s of skill %q on target %q failed verification, and the ACTIVE pointer was rolled back: %s\n\n"+
+			"The rejected deployment is recorded as staged and removed by the next apply.", err.DeploymentID, skillName, tName, err.Err),
+	)
+}
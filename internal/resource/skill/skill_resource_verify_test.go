@@ -0,0 +1,127 @@
+package skill
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+)
+
+func verifyModel(command, url string) SkillResourceModel {
+	v := VerifyBlockModel{
+		Command:        types.StringNull(),
+		URL:            types.StringNull(),
+		ExpectedStatus: types.Int64Value(200),
+		TimeoutSeconds: types.Int64Value(10),
+	}
+	if command != "" {
+		v.Command = types.StringValue(command)
+	}
+	if url != "" {
+		v.URL = types.StringValue(url)
+	}
+	return SkillResourceModel{Verify: []VerifyBlockModel{v}}
+}
+
+var testVerifyInfo = engine.VerifyInfo{
+	Target:               "primary",
+	SkillName:            "reports",
+	DeploymentID:         "20260101T000000Z-aaaa",
+	PreviousDeploymentID: "20251231T000000Z-bbbb",
+	BundleHash:           "sha256:abc",
+}
+
+func TestValidateVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   SkillResourceModel
+		wantErr string
+	}{
+		{"no block", SkillResourceModel{}, ""},
+		{"command", verifyModel("curl -f https://example.com/{{.DeploymentID}}", ""), ""},
+		{"url", verifyModel("", "https://example.com/health?d={{.DeploymentID}}"), ""},
+		{"neither", verifyModel("", ""), "exactly one of command and url"},
+		{"both", verifyModel("true", "https://example.com"), "exactly one of command and url"},
+		{"bad template", verifyModel("echo {{.DeploymentID", ""), "Invalid Verify Template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateVerify(tt.model)
+			if tt.wantErr == "" {
+				if diags.HasError() {
+					t.Errorf("unexpected errors: %v", diags.Errors())
+				}
+				return
+			}
+			if !diags.HasError() {
+				t.Fatalf("expected an error mentioning %q", tt.wantErr)
+			}
+			got := diags.Errors()[0].Summary() + " " + diags.Errors()[0].Detail()
+			if !strings.Contains(got, tt.wantErr) {
+				t.Errorf("error = %q, want it to mention %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderVerifyTemplate(t *testing.T) {
+	got, err := renderVerifyTemplate("url", "https://example.com/{{.Target}}/{{.SkillName}}?d={{.DeploymentID}}&p={{.PreviousDeploymentID}}", testVerifyInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://example.com/primary/reports?d=20260101T000000Z-aaaa&p=20251231T000000Z-bbbb"; got != want {
+		t.Errorf("rendered %q, want %q", got, want)
+	}
+
+	if _, err := renderVerifyTemplate("url", "{{.Unknown}}", testVerifyInfo); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestVerifier_Command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell syntax")
+	}
+	ctx := context.Background()
+
+	ok := verifier(verifyModel(`test "$AGENTCTX_DEPLOYMENT_ID" = "{{.DeploymentID}}"`, ""))
+	if err := ok(ctx, testVerifyInfo); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	fail := verifier(verifyModel("echo smoke test failed for $AGENTCTX_TARGET; exit 3", ""))
+	err := fail(ctx, testVerifyInfo)
+	if err == nil || !strings.Contains(err.Error(), "smoke test failed for primary") {
+		t.Errorf("error = %v, want it to include the command output", err)
+	}
+
+	slow := verifyModel("sleep 5", "")
+	slow.Verify[0].TimeoutSeconds = types.Int64Value(1)
+	if err := verifier(slow)(ctx, testVerifyInfo); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("error = %v, want a timeout", err)
+	}
+}
+
+func TestVerifier_URL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("d") != testVerifyInfo.DeploymentID {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if err := verifier(verifyModel("", srv.URL+"/?d={{.DeploymentID}}"))(ctx, testVerifyInfo); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := verifier(verifyModel("", srv.URL+"/?d=stale"))(ctx, testVerifyInfo)
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("error = %v, want a status mismatch", err)
+	}
+}
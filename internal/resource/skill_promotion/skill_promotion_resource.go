@@ -0,0 +1,387 @@
+package skillpromotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource              = &SkillPromotionResource{}
+	_ resource.ResourceWithConfigure = &SkillPromotionResource{}
+)
+
+// NewSkillPromotionResource returns a new resource.Resource for the
+// agentctx_skill_promotion type.
+func NewSkillPromotionResource() resource.Resource {
+	return &SkillPromotionResource{}
+}
+
+// SkillPromotionResource implements the agentctx_skill_promotion Terraform
+// resource. It moves the ACTIVE pointer of a skill to deployments that an
+// agentctx_skill with deployment_strategy = "staged" uploaded without
+// activating, one conditional write per target.
+type SkillPromotionResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *SkillPromotionResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_skill_promotion"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *SkillPromotionResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Promotes deployments staged by an `agentctx_skill` with `deployment_strategy = \"staged\"`: moves the skill's ACTIVE pointer on each target " +
+			"to the given deployment in a single conditional write. Changing `deployment_ids` promotes the new deployments; destroying the resource leaves ACTIVE where it is.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"skill_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the deployed skill, as in the `skill_name` attribute of the `agentctx_skill` that staged the deployments. Changing it forces recreation.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"deployment_ids": schema.MapAttribute{
+				MarkdownDescription: "Deployment to make active, keyed by target name, such as the `staged_deployment_id` of each of the skill's `target_states`.",
+				Required:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+					mapvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource identifier, the `skill_name`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"previous_deployment_ids": schema.MapAttribute{
+				MarkdownDescription: "Deployment ACTIVE selected on each target before it was last promoted, keyed by target name, for rolling back. Empty for a target the skill had no ACTIVE pointer on.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"promoted_at": schema.StringAttribute{
+				MarkdownDescription: "RFC 3339 timestamp of the last promotion.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *SkillPromotionResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *SkillPromotionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_promotion", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan SkillPromotionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.promote(ctx, &plan, nil, dryrun.OpCreate, &resp.Diagnostics) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	}
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *SkillPromotionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state SkillPromotionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var wanted map[string]string
+	resp.Diagnostics.Append(state.DeploymentIDs.ElementsAs(ctx, &wanted, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	skillName := state.SkillName.ValueString()
+
+	// A pointer moved since the promotion is recorded as the deployment
+	// ACTIVE selects now, so the next apply promotes the configured one
+	// again.
+	for _, tName := range slices.Sorted(maps.Keys(wanted)) {
+		tgt, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			continue
+		}
+		res, err := eng.Refresh(ctx, tgt, skillName, "", false)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				errcode.RefreshFailed.Summary("Refresh Failed"),
+				fmt.Sprintf("Failed to read the ACTIVE pointer of skill %q on target %q: %s", skillName, tName, err),
+			)
+			return
+		}
+		if res.ActiveDeploymentID == wanted[tName] {
+			continue
+		}
+		detail := fmt.Sprintf("ACTIVE on target %q selects %q instead of the promoted deployment %q", tName, res.ActiveDeploymentID, wanted[tName])
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_skill_promotion", state.ID.ValueString(), detail)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		tflog.Warn(ctx, "promoted deployment no longer active", map[string]interface{}{
+			"skill_name": skillName,
+			"target":     tName,
+			"active":     res.ActiveDeploymentID,
+			"promoted":   wanted[tName],
+		})
+		wanted[tName] = res.ActiveDeploymentID
+	}
+
+	ids, diags := types.MapValueFrom(ctx, types.StringType, wanted)
+	resp.Diagnostics.Append(diags...)
+	state.DeploymentIDs = ids
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *SkillPromotionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_promotion", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state SkillPromotionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var previous map[string]string
+	if !state.PreviousDeploymentIDs.IsNull() && !state.PreviousDeploymentIDs.IsUnknown() {
+		resp.Diagnostics.Append(state.PreviousDeploymentIDs.ElementsAs(ctx, &previous, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if r.promote(ctx, &plan, previous, dryrun.OpUpdate, &resp.Diagnostics) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	}
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+// Delete only removes the resource from state: un-promoting would need a
+// deployment to return to, and the skill's own deployments already decide
+// what ACTIVE selects next.
+func (r *SkillPromotionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_promotion", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state SkillPromotionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill_promotion",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+		})...)
+	}
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// promote moves ACTIVE on every target of plan to its deployment and fills
+// in plan's computed attributes. previous holds the previous deployments
+// recorded by an earlier promotion, kept for targets whose ACTIVE already
+// selects the deployment. It reports whether plan should be saved: false
+// on error, and on a dry run, which records op instead.
+func (r *SkillPromotionResource) promote(ctx context.Context, plan *SkillPromotionResourceModel, previous map[string]string, op string, diags *diag.Diagnostics) bool {
+	var wanted map[string]string
+	diags.Append(plan.DeploymentIDs.ElementsAs(ctx, &wanted, false)...)
+	if diags.HasError() {
+		return false
+	}
+
+	targets, d := r.lookupTargets(wanted)
+	diags.Append(d...)
+	if diags.HasError() {
+		return false
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	skillName := plan.SkillName.ValueString()
+
+	if r.providerData.DryRunning() {
+		changes := make([]dryrun.TargetChange, 0, len(targets))
+		for _, tName := range slices.Sorted(maps.Keys(wanted)) {
+			change := dryrun.TargetChange{Target: tName}
+			res, err := eng.Refresh(ctx, targets[tName], skillName, "", false)
+			switch {
+			case err != nil:
+				change.Error = err.Error()
+			case res.ActiveDeploymentID != wanted[tName]:
+				change.ActiveDeploymentID = res.ActiveDeploymentID
+				change.Uploads = 1
+			default:
+				change.ActiveDeploymentID = res.ActiveDeploymentID
+			}
+			changes = append(changes, change)
+		}
+		diags.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill_promotion",
+			Operation: op,
+			ID:        skillName,
+			Targets:   changes,
+		})...)
+		return false
+	}
+
+	prior := make(map[string]string, len(wanted))
+	for _, tName := range slices.Sorted(maps.Keys(wanted)) {
+		depID := wanted[tName]
+		tflog.Info(ctx, "promoting staged deployment", map[string]interface{}{
+			"skill_name":    skillName,
+			"target":        tName,
+			"deployment_id": depID,
+		})
+		res, err := eng.Promote(ctx, targets[tName], skillName, depID)
+		if errors.Is(err, engine.ErrActiveModified) {
+			diags.AddError(
+				errcode.DriftDetected.Summary("ACTIVE Pointer Modified Outside Terraform"),
+				fmt.Sprintf("The ACTIVE pointer for skill %q on target %q changed while it was being promoted, so it was not overwritten: %s. Apply again to promote %q.", skillName, tName, err, depID),
+			)
+			return false
+		}
+		if err != nil {
+			diags.AddError(
+				errcode.DeployFailed.Summary("Promotion Failed"),
+				fmt.Sprintf("Failed to promote deployment %q of skill %q on target %q: %s", depID, skillName, tName, err),
+			)
+			return false
+		}
+		prior[tName] = res.PreviousDeploymentID
+		if res.PreviousDeploymentID == depID {
+			prior[tName] = previous[tName]
+		}
+	}
+
+	plan.ID = types.StringValue(skillName)
+	priorMap, d := types.MapValueFrom(ctx, types.StringType, prior)
+	diags.Append(d...)
+	plan.PreviousDeploymentIDs = priorMap
+	plan.PromotedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+	return !diags.HasError()
+}
+
+// lookupTargets resolves the target names of deploymentIDs against the
+// provider's target registry. Replica targets are rejected: ACTIVE must be
+// written to the target the skill is deployed to.
+func (r *SkillPromotionResource) lookupTargets(deploymentIDs map[string]string) (map[string]target.Target, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if r.providerData == nil {
+		diags.AddError(
+			errcode.Internal.Summary("Provider Not Configured"),
+			"agentctx_skill_promotion requires a configured provider.",
+		)
+		return nil, diags
+	}
+
+	targets := make(map[string]target.Target, len(deploymentIDs))
+	for _, tName := range slices.Sorted(maps.Keys(deploymentIDs)) {
+		tgt, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			diags.AddError(
+				errcode.UnknownTarget.Summary("Target Not Found"),
+				fmt.Sprintf("Target %q is not defined in the provider.", tName),
+			)
+			continue
+		}
+		if cfg, _ := r.providerData.Targets.Config(tName); cfg.ReplicaOf.ValueString() != "" {
+			diags.AddError(
+				errcode.InvalidConfig.Summary("Replica Target Not Writable"),
+				fmt.Sprintf("Target %q is a replica of %q and is never written to. Promote on %q instead.",
+					tName, cfg.ReplicaOf.ValueString(), cfg.ReplicaOf.ValueString()),
+			)
+			continue
+		}
+		targets[tName] = tgt
+	}
+	return targets, diags
+}
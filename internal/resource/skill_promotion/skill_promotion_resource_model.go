@@ -0,0 +1,16 @@
+package skillpromotion
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// SkillPromotionResourceModel maps the agentctx_skill_promotion resource
+// schema to a Go struct.
+type SkillPromotionResourceModel struct {
+	// Required
+	SkillName     types.String `tfsdk:"skill_name"`
+	DeploymentIDs types.Map    `tfsdk:"deployment_ids"` // target name -> deployment ID
+
+	// Computed
+	ID                    types.String `tfsdk:"id"`
+	PreviousDeploymentIDs types.Map    `tfsdk:"previous_deployment_ids"` // target name -> deployment ID
+	PromotedAt            types.String `tfsdk:"promoted_at"`
+}
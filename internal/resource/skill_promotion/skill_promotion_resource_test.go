@@ -0,0 +1,93 @@
+package skillpromotion
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestPromote(t *testing.T) {
+	ctx := context.Background()
+	tgt := target.NewMemoryTarget("primary")
+	reg := providerdata.NewTargetRegistry()
+	if err := reg.Register("primary", tgt, providerdata.TargetConfigModel{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("replica", target.NewMemoryTarget("replica"), providerdata.TargetConfigModel{ReplicaOf: types.StringValue("primary")}); err != nil {
+		t.Fatal(err)
+	}
+	r := &SkillPromotionResource{providerData: &providerdata.ProviderData{Targets: reg, Scheduler: concurrency.NewUniform(4)}}
+
+	put := func(key, body string) {
+		t.Helper()
+		if err := tgt.Put(ctx, key, strings.NewReader(body), target.PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	active := func() string {
+		t.Helper()
+		rc, _, err := tgt.Get(ctx, "my-skill/.agentctx/ACTIVE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	put("my-skill/.agentctx/ACTIVE", "dep-1")
+	put("my-skill/.agentctx/deployments/dep-1/manifest.json", `{"schema_version":2,"deployment_id":"dep-1","files":{}}`)
+	put("my-skill/.agentctx/deployments/dep-2/manifest.json", `{"schema_version":2,"deployment_id":"dep-2","files":{}}`)
+
+	plan := &SkillPromotionResourceModel{SkillName: types.StringValue("my-skill")}
+	plan.DeploymentIDs, _ = types.MapValueFrom(ctx, types.StringType, map[string]string{"primary": "dep-2"})
+
+	var diags diag.Diagnostics
+	if !r.promote(ctx, plan, nil, dryrun.OpCreate, &diags) {
+		t.Fatalf("promote failed: %v", diags)
+	}
+	if got := active(); got != "dep-2" {
+		t.Errorf("ACTIVE = %q, want dep-2", got)
+	}
+	if got := plan.PreviousDeploymentIDs.Elements()["primary"]; got != types.StringValue("dep-1") {
+		t.Errorf("previous_deployment_ids[primary] = %v, want dep-1", got)
+	}
+	if plan.ID.ValueString() != "my-skill" || plan.PromotedAt.ValueString() == "" {
+		t.Errorf("id = %q, promoted_at = %q", plan.ID.ValueString(), plan.PromotedAt.ValueString())
+	}
+
+	// Promoting the active deployment again keeps the recorded previous one.
+	if !r.promote(ctx, plan, map[string]string{"primary": "dep-1"}, dryrun.OpUpdate, &diags) {
+		t.Fatalf("promote again failed: %v", diags)
+	}
+	if got := plan.PreviousDeploymentIDs.Elements()["primary"]; got != types.StringValue("dep-1") {
+		t.Errorf("previous_deployment_ids[primary] = %v, want dep-1 kept", got)
+	}
+
+	// A deployment without a manifest is never promoted.
+	plan.DeploymentIDs, _ = types.MapValueFrom(ctx, types.StringType, map[string]string{"primary": "dep-3"})
+	diags = nil
+	if r.promote(ctx, plan, nil, dryrun.OpUpdate, &diags) || !diags.HasError() {
+		t.Error("expected promotion of a missing deployment to fail")
+	}
+	if got := active(); got != "dep-2" {
+		t.Errorf("ACTIVE = %q, want dep-2 kept", got)
+	}
+
+	plan.DeploymentIDs, _ = types.MapValueFrom(ctx, types.StringType, map[string]string{"replica": "dep-2"})
+	diags = nil
+	if r.promote(ctx, plan, nil, dryrun.OpUpdate, &diags) || !diags.HasError() {
+		t.Error("expected error promoting on a replica target")
+	}
+}
@@ -0,0 +1,415 @@
+package skillrollback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource              = &SkillRollbackResource{}
+	_ resource.ResourceWithConfigure = &SkillRollbackResource{}
+)
+
+// NewSkillRollbackResource returns a new resource.Resource for the
+// agentctx_skill_rollback type.
+func NewSkillRollbackResource() resource.Resource {
+	return &SkillRollbackResource{}
+}
+
+// SkillRollbackResource implements the agentctx_skill_rollback Terraform
+// resource. It moves the ACTIVE pointer of a skill back to earlier
+// deployments that are still on their targets, after checking every file
+// of each against its manifest, without uploading the bundle again.
+type SkillRollbackResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *SkillRollbackResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_skill_rollback"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *SkillRollbackResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Rolls the ACTIVE pointer of a skill back to earlier deployments without uploading the bundle again. Each deployment must still be in the skill's index " +
+			"and pass an integrity check of every file before ACTIVE is moved to it in a single conditional write. Destroying the resource leaves ACTIVE where it is.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"skill_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the deployed skill, as in the `skill_name` attribute of its `agentctx_skill`. Changing it forces recreation.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"deployment_ids": schema.MapAttribute{
+				MarkdownDescription: "Deployment to roll back to, keyed by target name: a deployment ID, such as one of the skill's `managed_deploy_ids`, or a `deployment_alias`, which selects the newest deployment with that alias.",
+				Required:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+					mapvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource identifier, the `skill_name`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"active_deployment_ids": schema.MapAttribute{
+				MarkdownDescription: "ID of the deployment ACTIVE was rolled back to on each target, keyed by target name, with aliases in `deployment_ids` resolved.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"previous_deployment_ids": schema.MapAttribute{
+				MarkdownDescription: "Deployment ACTIVE selected on each target before it was last rolled back, keyed by target name. Empty for a target the skill had no ACTIVE pointer on.",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"rolled_back_at": schema.StringAttribute{
+				MarkdownDescription: "RFC 3339 timestamp of the last rollback.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *SkillRollbackResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *SkillRollbackResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_rollback", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan SkillRollbackResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.rollback(ctx, &plan, nil, dryrun.OpCreate, &resp.Diagnostics) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	}
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *SkillRollbackResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state SkillRollbackResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var wanted, active map[string]string
+	resp.Diagnostics.Append(state.DeploymentIDs.ElementsAs(ctx, &wanted, false)...)
+	resp.Diagnostics.Append(state.ActiveDeploymentIDs.ElementsAs(ctx, &active, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if active == nil {
+		active = make(map[string]string, len(wanted))
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	skillName := state.SkillName.ValueString()
+
+	// A pointer moved since the rollback, for example by the next deploy
+	// of the skill, is recorded as the deployment ACTIVE selects now, so
+	// the next apply rolls back again.
+	for _, tName := range slices.Sorted(maps.Keys(wanted)) {
+		tgt, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			continue
+		}
+		res, err := eng.Refresh(ctx, tgt, skillName, "", false)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				errcode.RefreshFailed.Summary("Refresh Failed"),
+				fmt.Sprintf("Failed to read the ACTIVE pointer of skill %q on target %q: %s", skillName, tName, err),
+			)
+			return
+		}
+		if res.ActiveDeploymentID == active[tName] {
+			continue
+		}
+		detail := fmt.Sprintf("ACTIVE on target %q selects %q instead of the rolled-back deployment %q", tName, res.ActiveDeploymentID, active[tName])
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_skill_rollback", state.ID.ValueString(), detail)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		tflog.Warn(ctx, "rolled-back deployment no longer active", map[string]interface{}{
+			"skill_name":  skillName,
+			"target":      tName,
+			"active":      res.ActiveDeploymentID,
+			"rolled_back": active[tName],
+		})
+		wanted[tName] = res.ActiveDeploymentID
+		active[tName] = res.ActiveDeploymentID
+	}
+
+	ids, diags := types.MapValueFrom(ctx, types.StringType, wanted)
+	resp.Diagnostics.Append(diags...)
+	state.DeploymentIDs = ids
+	activeIDs, diags := types.MapValueFrom(ctx, types.StringType, active)
+	resp.Diagnostics.Append(diags...)
+	state.ActiveDeploymentIDs = activeIDs
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *SkillRollbackResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_rollback", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan, state SkillRollbackResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var previous map[string]string
+	if !state.PreviousDeploymentIDs.IsNull() && !state.PreviousDeploymentIDs.IsUnknown() {
+		resp.Diagnostics.Append(state.PreviousDeploymentIDs.ElementsAs(ctx, &previous, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if r.rollback(ctx, &plan, previous, dryrun.OpUpdate, &resp.Diagnostics) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	}
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+// Delete only removes the resource from state: the next deploy of the skill
+// moves ACTIVE on, and rolling forward again is a deploy, not a delete.
+func (r *SkillRollbackResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_rollback", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state SkillRollbackResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill_rollback",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+		})...)
+	}
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// rollback moves ACTIVE on every target of plan back to its deployment and
+// fills in plan's computed attributes. previous holds the previous
+// deployments recorded by an earlier rollback, kept for targets whose
+// ACTIVE already selects the deployment. It reports whether plan should be
+// saved: false on error, and on a dry run, which records op instead.
+func (r *SkillRollbackResource) rollback(ctx context.Context, plan *SkillRollbackResourceModel, previous map[string]string, op string, diags *diag.Diagnostics) bool {
+	var wanted map[string]string
+	diags.Append(plan.DeploymentIDs.ElementsAs(ctx, &wanted, false)...)
+	if diags.HasError() {
+		return false
+	}
+
+	targets, d := r.lookupTargets(wanted)
+	diags.Append(d...)
+	if diags.HasError() {
+		return false
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	skillName := plan.SkillName.ValueString()
+
+	if r.providerData.DryRunning() {
+		changes := make([]dryrun.TargetChange, 0, len(targets))
+		for _, tName := range slices.Sorted(maps.Keys(wanted)) {
+			change := dryrun.TargetChange{Target: tName}
+			depID, err := eng.ResolveDeployment(ctx, targets[tName], skillName, wanted[tName])
+			var res *engine.RefreshResult
+			if err == nil {
+				res, err = eng.Refresh(ctx, targets[tName], skillName, "", false)
+			}
+			switch {
+			case err != nil:
+				change.Error = err.Error()
+			case res.ActiveDeploymentID != depID:
+				change.ActiveDeploymentID = res.ActiveDeploymentID
+				change.Uploads = 1
+			default:
+				change.ActiveDeploymentID = res.ActiveDeploymentID
+			}
+			changes = append(changes, change)
+		}
+		diags.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill_rollback",
+			Operation: op,
+			ID:        skillName,
+			Targets:   changes,
+		})...)
+		return false
+	}
+
+	active := make(map[string]string, len(wanted))
+	prior := make(map[string]string, len(wanted))
+	for _, tName := range slices.Sorted(maps.Keys(wanted)) {
+		ref := wanted[tName]
+		tflog.Info(ctx, "rolling back skill", map[string]interface{}{
+			"skill_name": skillName,
+			"target":     tName,
+			"deployment": ref,
+		})
+		depID, res, err := eng.Rollback(ctx, targets[tName], skillName, ref)
+		switch {
+		case errors.Is(err, engine.ErrActiveModified):
+			diags.AddError(
+				errcode.DriftDetected.Summary("ACTIVE Pointer Modified Outside Terraform"),
+				fmt.Sprintf("The ACTIVE pointer for skill %q on target %q changed while it was being rolled back, so it was not overwritten: %s. Apply again to roll back to %q.", skillName, tName, err, ref),
+			)
+			return false
+		case errors.Is(err, engine.ErrUnhealthyDeployment), errors.Is(err, target.ErrNotFound):
+			diags.AddError(
+				errcode.DeployFailed.Summary("Rollback Target Unavailable"),
+				fmt.Sprintf("Cannot roll skill %q on target %q back to %q, so ACTIVE was left alone: %s. "+
+					"Pick another of the skill's deployments, or deploy the skill again.", skillName, tName, ref, err),
+			)
+			return false
+		case err != nil:
+			diags.AddError(
+				errcode.DeployFailed.Summary("Rollback Failed"),
+				fmt.Sprintf("Failed to roll skill %q on target %q back to %q: %s", skillName, tName, ref, err),
+			)
+			return false
+		}
+		active[tName] = depID
+		prior[tName] = res.PreviousDeploymentID
+		if res.PreviousDeploymentID == depID {
+			prior[tName] = previous[tName]
+		}
+	}
+
+	plan.ID = types.StringValue(skillName)
+	activeMap, d := types.MapValueFrom(ctx, types.StringType, active)
+	diags.Append(d...)
+	plan.ActiveDeploymentIDs = activeMap
+	priorMap, d := types.MapValueFrom(ctx, types.StringType, prior)
+	diags.Append(d...)
+	plan.PreviousDeploymentIDs = priorMap
+	plan.RolledBackAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+	return !diags.HasError()
+}
+
+// lookupTargets resolves the target names of deploymentIDs against the
+// provider's target registry. Replica targets are rejected: ACTIVE must be
+// written to the target the skill is deployed to.
+func (r *SkillRollbackResource) lookupTargets(deploymentIDs map[string]string) (map[string]target.Target, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if r.providerData == nil {
+		diags.AddError(
+			errcode.Internal.Summary("Provider Not Configured"),
+			"agentctx_skill_rollback requires a configured provider.",
+		)
+		return nil, diags
+	}
+
+	targets := make(map[string]target.Target, len(deploymentIDs))
+	for _, tName := range slices.Sorted(maps.Keys(deploymentIDs)) {
+		tgt, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			diags.AddError(
+				errcode.UnknownTarget.Summary("Target Not Found"),
+				fmt.Sprintf("Target %q is not defined in the provider.", tName),
+			)
+			continue
+		}
+		if cfg, _ := r.providerData.Targets.Config(tName); cfg.ReplicaOf.ValueString() != "" {
+			diags.AddError(
+				errcode.InvalidConfig.Summary("Replica Target Not Writable"),
+				fmt.Sprintf("Target %q is a replica of %q and is never written to. Roll back on %q instead.",
+					tName, cfg.ReplicaOf.ValueString(), cfg.ReplicaOf.ValueString()),
+			)
+			continue
+		}
+		targets[tName] = tgt
+	}
+	return targets, diags
+}
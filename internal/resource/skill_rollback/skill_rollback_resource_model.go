@@ -0,0 +1,17 @@
+package skillrollback
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// SkillRollbackResourceModel maps the agentctx_skill_rollback resource
+// schema to a Go struct.
+type SkillRollbackResourceModel struct {
+	// Required
+	SkillName     types.String `tfsdk:"skill_name"`
+	DeploymentIDs types.Map    `tfsdk:"deployment_ids"` // target name -> deployment ID or alias
+
+	// Computed
+	ID                    types.String `tfsdk:"id"`
+	ActiveDeploymentIDs   types.Map    `tfsdk:"active_deployment_ids"`   // target name -> deployment ID
+	PreviousDeploymentIDs types.Map    `tfsdk:"previous_deployment_ids"` // target name -> deployment ID
+	RolledBackAt          types.String `tfsdk:"rolled_back_at"`
+}
@@ -0,0 +1,97 @@
+package skillrollback
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	tgt := target.NewMemoryTarget("primary")
+	reg := providerdata.NewTargetRegistry()
+	if err := reg.Register("primary", tgt, providerdata.TargetConfigModel{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register("replica", target.NewMemoryTarget("replica"), providerdata.TargetConfigModel{ReplicaOf: types.StringValue("primary")}); err != nil {
+		t.Fatal(err)
+	}
+	r := &SkillRollbackResource{providerData: &providerdata.ProviderData{Targets: reg, Scheduler: concurrency.NewUniform(4)}}
+
+	put := func(key, body string) {
+		t.Helper()
+		if err := tgt.Put(ctx, key, strings.NewReader(body), target.PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	active := func() string {
+		t.Helper()
+		rc, _, err := tgt.Get(ctx, "my-skill/.agentctx/ACTIVE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	hash, err := bundle.ComputeReaderHash(strings.NewReader("v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const (
+		dep1 = "dep_20260101T000000Z_00000001"
+		dep2 = "dep_20260102T000000Z_00000002"
+	)
+	put("my-skill/.agentctx/ACTIVE", dep2)
+	put("my-skill/.agentctx/deployments/"+dep1+"/manifest.json", `{"schema_version":2,"deployment_id":"`+dep1+`","deployment_alias":"stable","files":{"SKILL.md":"`+hash+`"}}`)
+	put("my-skill/.agentctx/deployments/"+dep1+"/files/SKILL.md", "v1")
+	put("my-skill/.agentctx/deployments/"+dep2+"/manifest.json", `{"schema_version":2,"deployment_id":"`+dep2+`","files":{}}`)
+
+	plan := &SkillRollbackResourceModel{SkillName: types.StringValue("my-skill")}
+	plan.DeploymentIDs, _ = types.MapValueFrom(ctx, types.StringType, map[string]string{"primary": "stable"})
+
+	var diags diag.Diagnostics
+	if !r.rollback(ctx, plan, nil, dryrun.OpCreate, &diags) {
+		t.Fatalf("rollback failed: %v", diags)
+	}
+	if got := active(); got != dep1 {
+		t.Errorf("ACTIVE = %q, want %s", got, dep1)
+	}
+	if got := plan.ActiveDeploymentIDs.Elements()["primary"]; got != types.StringValue(dep1) {
+		t.Errorf("active_deployment_ids[primary] = %v, want %s", got, dep1)
+	}
+	if got := plan.PreviousDeploymentIDs.Elements()["primary"]; got != types.StringValue(dep2) {
+		t.Errorf("previous_deployment_ids[primary] = %v, want %s", got, dep2)
+	}
+
+	// A deployment whose files no longer match its manifest is never
+	// made active.
+	put("my-skill/.agentctx/ACTIVE", dep2)
+	put("my-skill/.agentctx/deployments/"+dep1+"/files/SKILL.md", "tampered")
+	diags = nil
+	if r.rollback(ctx, plan, nil, dryrun.OpUpdate, &diags) || !diags.HasError() {
+		t.Error("expected rollback to an unhealthy deployment to fail")
+	}
+	if got := active(); got != dep2 {
+		t.Errorf("ACTIVE = %q, want %s kept", got, dep2)
+	}
+
+	plan.DeploymentIDs, _ = types.MapValueFrom(ctx, types.StringType, map[string]string{"replica": dep1})
+	diags = nil
+	if r.rollback(ctx, plan, nil, dryrun.OpUpdate, &diags) || !diags.HasError() {
+		t.Error("expected error rolling back on a replica target")
+	}
+}
@@ -0,0 +1,466 @@
+package skillverification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+// consumerPattern restricts consumer names to a single object key segment.
+var consumerPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Compile-time interface checks.
+var (
+	_ resource.Resource              = &SkillVerificationResource{}
+	_ resource.ResourceWithConfigure = &SkillVerificationResource{}
+)
+
+// NewSkillVerificationResource returns a new resource.Resource for the
+// agentctx_skill_verification type.
+func NewSkillVerificationResource() resource.Resource {
+	return &SkillVerificationResource{}
+}
+
+// SkillVerificationResource implements the agentctx_skill_verification
+// Terraform resource. It lets a stack that consumes a skill deployed by
+// another stack check that the skill is deployed on its targets and, with
+// consumer set, record a reference marker that keeps the owning stack from
+// destroying the skill while the consumer exists.
+type SkillVerificationResource struct {
+	providerData *providerdata.ProviderData
+}
+
+// --------------------------------------------------------------------------
+// Metadata
+// --------------------------------------------------------------------------
+
+func (r *SkillVerificationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_skill_verification"
+}
+
+// --------------------------------------------------------------------------
+// Schema
+// --------------------------------------------------------------------------
+
+func (r *SkillVerificationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Verifies that a skill deployed by another stack is active on one or more targets, and optionally records a reference marker " +
+			"that keeps the owning `agentctx_skill` from being destroyed while this resource exists. Changing any argument forces recreation.",
+
+		Attributes: map[string]schema.Attribute{
+			// ---- Required ----
+			"skill_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the deployed skill, as in the `skill_name` attribute of the `agentctx_skill` that deploys it.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"targets": schema.ListAttribute{
+				MarkdownDescription: "Names of the provider targets the skill must be deployed on.",
+				Required:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.UniqueValues(),
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+
+			// ---- Optional ----
+			"consumer": schema.StringAttribute{
+				MarkdownDescription: "Name of the consumer, usually the stack that declares this resource. When set, a reference marker " +
+					"`<skill_name>/.agentctx/refs/<consumer>` is written to every target on create and deleted on destroy. " +
+					"While a marker exists, destroying the skill fails unless it sets `force_destroy = true`. " +
+					"Up to 128 letters, digits, `.`, `_`, and `-`.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(consumerPattern, "must be 1-128 letters, digits, '.', '_', or '-'"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+
+			// ---- Computed ----
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Resource identifier, `<skill_name>` or `<skill_name>:<consumer>`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"active_deployment_ids": schema.MapAttribute{
+				MarkdownDescription: "Deployment the ACTIVE pointer of the skill selects on each target, keyed by target name, as of the last refresh. " +
+					"Empty for a target the skill is no longer deployed on.",
+				Computed:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *SkillVerificationResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// Create
+// --------------------------------------------------------------------------
+
+func (r *SkillVerificationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_verification", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var plan SkillVerificationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	targets, diags := r.lookupTargets(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	skillName := plan.SkillName.ValueString()
+	consumer := plan.Consumer.ValueString()
+
+	// 1. Verify the skill is deployed on every target before writing any
+	// marker, so a failed verification leaves nothing behind.
+	active := make(map[string]string, len(targets))
+	for _, tName := range slices.Sorted(maps.Keys(targets)) {
+		depID, err := activeDeploymentID(ctx, eng, targets[tName], skillName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				errcode.RefreshFailed.Summary("Verification Failed"),
+				fmt.Sprintf("Failed to read skill %q on target %q: %s", skillName, tName, err),
+			)
+			return
+		}
+		if depID == "" {
+			resp.Diagnostics.AddError(
+				errcode.SkillNotDeployed.Summary("Skill Not Deployed"),
+				fmt.Sprintf("Skill %q has no active deployment on target %q. Apply the stack that deploys it first.", skillName, tName),
+			)
+			return
+		}
+		active[tName] = depID
+	}
+
+	if r.providerData.DryRunning() {
+		changes := make([]dryrun.TargetChange, 0, len(targets))
+		for _, tName := range slices.Sorted(maps.Keys(targets)) {
+			change := dryrun.TargetChange{Target: tName, ActiveDeploymentID: active[tName]}
+			if consumer != "" {
+				change.Uploads = 1
+			}
+			changes = append(changes, change)
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill_verification",
+			Operation: dryrun.OpCreate,
+			ID:        resourceID(skillName, consumer),
+			Targets:   changes,
+		})...)
+		return
+	}
+
+	// 2. Record the reference on every target.
+	if consumer != "" {
+		now := time.Now().UTC().Format(time.RFC3339)
+		for _, tName := range slices.Sorted(maps.Keys(targets)) {
+			tflog.Info(ctx, "recording skill reference", map[string]interface{}{
+				"skill_name": skillName,
+				"target":     tName,
+				"consumer":   consumer,
+			})
+			err := eng.AddReference(ctx, targets[tName], skillName, engine.Reference{
+				Consumer:     consumer,
+				DeploymentID: active[tName],
+				CreatedAt:    now,
+			})
+			if err != nil {
+				resp.Diagnostics.AddError(
+					errcode.DeployFailed.Summary("Reference Write Failed"),
+					fmt.Sprintf("Failed to record reference of consumer %q to skill %q on target %q: %s", consumer, skillName, tName, err),
+				)
+				return
+			}
+		}
+	}
+
+	// 3. Save state.
+	plan.ID = types.StringValue(resourceID(skillName, consumer))
+	activeMap, diags := types.MapValueFrom(ctx, types.StringType, active)
+	resp.Diagnostics.Append(diags...)
+	plan.ActiveDeploymentIDs = activeMap
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// --------------------------------------------------------------------------
+// Read
+// --------------------------------------------------------------------------
+
+func (r *SkillVerificationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state SkillVerificationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	targets, diags := r.lookupTargets(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	skillName := state.SkillName.ValueString()
+	consumer := state.Consumer.ValueString()
+
+	active := make(map[string]string, len(targets))
+	for _, tName := range slices.Sorted(maps.Keys(targets)) {
+		tgt := targets[tName]
+		depID, err := activeDeploymentID(ctx, eng, tgt, skillName)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				errcode.RefreshFailed.Summary("Verification Failed"),
+				fmt.Sprintf("Failed to read skill %q on target %q: %s", skillName, tName, err),
+			)
+			return
+		}
+		if depID == "" {
+			resp.Diagnostics.AddWarning(
+				errcode.SkillNotDeployed.Summary("Skill Not Deployed"),
+				fmt.Sprintf("Skill %q no longer has an active deployment on target %q.", skillName, tName),
+			)
+		}
+		active[tName] = depID
+
+		if consumer == "" {
+			continue
+		}
+		// A marker removed outside Terraform, e.g. by a forced destroy of
+		// the skill, is recreated by the next apply.
+		if _, err := eng.ReadReference(ctx, tgt, skillName, consumer); err != nil {
+			if errors.Is(err, target.ErrNotFound) {
+				tflog.Info(ctx, "skill reference marker missing, removing from state", map[string]interface{}{
+					"skill_name": skillName,
+					"target":     tName,
+					"consumer":   consumer,
+				})
+				resp.State.RemoveResource(ctx)
+				return
+			}
+			resp.Diagnostics.AddError(
+				errcode.RefreshFailed.Summary("Verification Failed"),
+				fmt.Sprintf("Failed to read reference of consumer %q to skill %q on target %q: %s", consumer, skillName, tName, err),
+			)
+			return
+		}
+	}
+
+	activeMap, diags := types.MapValueFrom(ctx, types.StringType, active)
+	resp.Diagnostics.Append(diags...)
+	state.ActiveDeploymentIDs = activeMap
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// --------------------------------------------------------------------------
+// Update
+// --------------------------------------------------------------------------
+
+func (r *SkillVerificationResource) Update(_ context.Context, _ resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.AddError(
+		errcode.Internal.Summary("Update Not Supported"),
+		"agentctx_skill_verification does not support in-place updates. Changes to any argument force replacement.",
+	)
+}
+
+// --------------------------------------------------------------------------
+// Delete
+// --------------------------------------------------------------------------
+
+func (r *SkillVerificationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_verification", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state SkillVerificationResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	consumer := state.Consumer.ValueString()
+	if consumer == "" {
+		if r.providerData.DryRunning() {
+			resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+				Resource:  "agentctx_skill_verification",
+				Operation: dryrun.OpDelete,
+				ID:        state.ID.ValueString(),
+			})...)
+		}
+		return
+	}
+
+	var targetNames []string
+	resp.Diagnostics.Append(state.Targets.ElementsAs(ctx, &targetNames, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	eng := engine.New(r.providerData.Scheduler)
+	skillName := state.SkillName.ValueString()
+	if r.providerData.DryRunning() {
+		var changes []dryrun.TargetChange
+		for _, tName := range targetNames {
+			if _, ok := r.providerData.Targets.Get(tName); ok {
+				changes = append(changes, dryrun.TargetChange{Target: tName, Deletes: []string{engine.ReferenceKey(skillName, consumer)}})
+			}
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill_verification",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Targets:   changes,
+		})...)
+		return
+	}
+	for _, tName := range targetNames {
+		tgt, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			tflog.Warn(ctx, "target no longer configured, skipping reference removal", map[string]interface{}{
+				"target": tName,
+			})
+			continue
+		}
+		if err := eng.RemoveReference(ctx, tgt, skillName, consumer); err != nil {
+			resp.Diagnostics.AddError(
+				errcode.DestroyFailed.Summary("Reference Removal Failed"),
+				fmt.Sprintf("Failed to remove reference of consumer %q to skill %q on target %q: %s", consumer, skillName, tName, err),
+			)
+			return
+		}
+	}
+}
+
+// --------------------------------------------------------------------------
+// Helpers
+// --------------------------------------------------------------------------
+
+// lookupTargets resolves the targets of model against the provider's
+// target registry. Replica targets are rejected: markers must be written to
+// the target the skill is deployed to.
+func (r *SkillVerificationResource) lookupTargets(ctx context.Context, model SkillVerificationResourceModel) (map[string]target.Target, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if r.providerData == nil {
+		diags.AddError(
+			errcode.Internal.Summary("Provider Not Configured"),
+			"agentctx_skill_verification requires a configured provider.",
+		)
+		return nil, diags
+	}
+
+	var names []string
+	diags.Append(model.Targets.ElementsAs(ctx, &names, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	targets := make(map[string]target.Target, len(names))
+	for _, tName := range names {
+		tgt, ok := r.providerData.Targets.Get(tName)
+		if !ok {
+			diags.AddError(
+				errcode.UnknownTarget.Summary("Target Not Found"),
+				fmt.Sprintf("Target %q is not defined in the provider.", tName),
+			)
+			continue
+		}
+		if cfg, _ := r.providerData.Targets.Config(tName); cfg.ReplicaOf.ValueString() != "" {
+			diags.AddError(
+				errcode.InvalidConfig.Summary("Replica Target Not Writable"),
+				fmt.Sprintf("Target %q is a replica of %q and is never written to. List %q instead.",
+					tName, cfg.ReplicaOf.ValueString(), cfg.ReplicaOf.ValueString()),
+			)
+			continue
+		}
+		targets[tName] = tgt
+	}
+	return targets, diags
+}
+
+// activeDeploymentID returns the deployment ACTIVE selects for skillName on
+// tgt, or "" when the skill is not deployed or its manifest is missing.
+func activeDeploymentID(ctx context.Context, eng *engine.Engine, tgt target.Target, skillName string) (string, error) {
+	res, err := eng.Refresh(ctx, tgt, skillName, "", false)
+	if err != nil {
+		return "", err
+	}
+	if res.MissingManifest {
+		return "", nil
+	}
+	return res.ActiveDeploymentID, nil
+}
+
+// resourceID returns the ID of a verification of skillName by consumer.
+func resourceID(skillName, consumer string) string {
+	if consumer == "" {
+		return skillName
+	}
+	return skillName + ":" + consumer
+}
@@ -0,0 +1,18 @@
+package skillverification
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+// SkillVerificationResourceModel maps the agentctx_skill_verification
+// resource schema to a Go struct.
+type SkillVerificationResourceModel struct {
+	// Required
+	SkillName types.String `tfsdk:"skill_name"`
+	Targets   types.List   `tfsdk:"targets"` // list of strings
+
+	// Optional
+	Consumer types.String `tfsdk:"consumer"`
+
+	// Computed
+	ID                  types.String `tfsdk:"id"`
+	ActiveDeploymentIDs types.Map    `tfsdk:"active_deployment_ids"` // target name -> deployment ID
+}
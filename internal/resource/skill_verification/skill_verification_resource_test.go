@@ -0,0 +1,48 @@
+package skillverification
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/concurrency"
+	"github.com/agentctx/terraform-provider-agentctx/internal/engine"
+	"github.com/agentctx/terraform-provider-agentctx/internal/target"
+)
+
+func TestActiveDeploymentID(t *testing.T) {
+	ctx := context.Background()
+	eng := engine.New(concurrency.NewUniform(4))
+	tgt := target.NewMemoryTarget("primary")
+
+	put := func(key, body string) {
+		t.Helper()
+		if err := tgt.Put(ctx, key, bytes.NewReader([]byte(body)), target.PutOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if id, err := activeDeploymentID(ctx, eng, tgt, "my-skill"); err != nil || id != "" {
+		t.Errorf("undeployed skill: got %q, %v; want empty", id, err)
+	}
+
+	// ACTIVE without a manifest is not a usable deployment.
+	put("my-skill/.agentctx/ACTIVE", "dep-1")
+	if id, err := activeDeploymentID(ctx, eng, tgt, "my-skill"); err != nil || id != "" {
+		t.Errorf("missing manifest: got %q, %v; want empty", id, err)
+	}
+
+	put("my-skill/.agentctx/deployments/dep-1/manifest.json", `{"schema_version":2,"deployment_id":"dep-1","files":{}}`)
+	if id, err := activeDeploymentID(ctx, eng, tgt, "my-skill"); err != nil || id != "dep-1" {
+		t.Errorf("deployed skill: got %q, %v; want dep-1", id, err)
+	}
+}
+
+func TestResourceID(t *testing.T) {
+	if got := resourceID("my-skill", ""); got != "my-skill" {
+		t.Errorf("resourceID without consumer = %q", got)
+	}
+	if got := resourceID("my-skill", "stack-b"); got != "my-skill:stack-b" {
+		t.Errorf("resourceID with consumer = %q", got)
+	}
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/agentctx/terraform-provider-agentctx/internal/anthropic"
 	"github.com/agentctx/terraform-provider-agentctx/internal/bundle"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
 	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
 )
 
@@ -103,7 +105,7 @@ func (r *SkillVersionResource) Configure(_ context.Context, req resource.Configu
 	pd, ok := req.ProviderData.(*providerdata.ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
-			"Unexpected Resource Configure Type",
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
 			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
@@ -117,6 +119,11 @@ func (r *SkillVersionResource) Configure(_ context.Context, req resource.Configu
 // --------------------------------------------------------------------------
 
 func (r *SkillVersionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_version", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan SkillVersionResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -125,7 +132,7 @@ func (r *SkillVersionResource) Create(ctx context.Context, req resource.CreateRe
 
 	if r.providerData.Anthropic == nil {
 		resp.Diagnostics.AddError(
-			"Anthropic Client Not Configured",
+			errcode.AnthropicNotConfigured.Summary("Anthropic Client Not Configured"),
 			"The agentctx_skill_version resource requires the provider anthropic block to be configured.",
 		)
 		return
@@ -135,20 +142,31 @@ func (r *SkillVersionResource) Create(ctx context.Context, req resource.CreateRe
 	sourceDir := plan.SourceDir.ValueString()
 	b, err := bundle.ScanBundle(sourceDir, nil, false)
 	if err != nil {
-		resp.Diagnostics.AddError("Bundle Scan Failed", fmt.Sprintf("Failed to scan source directory %q: %s", sourceDir, err))
+		resp.Diagnostics.AddError(errcode.InvalidBundle.Summary("Bundle Scan Failed"), fmt.Sprintf("Failed to scan source directory %q: %s", sourceDir, err))
 		return
 	}
 
 	// 2. Create the version in the Anthropic registry.
 	skillID := plan.SkillID.ValueString()
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_skill_version",
+			Operation: dryrun.OpCreate,
+			ID:        skillID,
+			Registry:  []dryrun.RegistryChange{{Action: dryrun.RegistryCreateVersion, SkillID: skillID}},
+		})...)
+		return
+	}
 	tflog.Info(ctx, "creating skill version", map[string]interface{}{
 		"skill_id":    skillID,
 		"bundle_hash": b.BundleHash,
 	})
 
-	ver, createErr := r.providerData.Anthropic.CreateVersion(ctx, skillID, sourceDir)
+	ver, createErr := r.providerData.Anthropic.CreateVersion(ctx, skillID, sourceDir, anthropic.CreateVersionRequest{
+		IdempotencyKey: anthropic.IdempotencyKey(skillID, b.BundleHash),
+	})
 	if createErr != nil {
-		resp.Diagnostics.AddError("Create Version Failed", fmt.Sprintf("Failed to create version for skill %q: %s", skillID, createErr))
+		resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Create Version Failed"), fmt.Sprintf("Failed to create version for skill %q: %s", skillID, createErr))
 		return
 	}
 
@@ -195,7 +213,7 @@ func (r *SkillVersionResource) Read(ctx context.Context, req resource.ReadReques
 			return
 		}
 
-		resp.Diagnostics.AddError("Read Version Failed", fmt.Sprintf("Failed to read version %q for skill %q: %s", versionStr, skillID, err))
+		resp.Diagnostics.AddError(errcode.AnthropicRequestFailed.Summary("Read Version Failed"), fmt.Sprintf("Failed to read version %q for skill %q: %s", versionStr, skillID, err))
 		return
 	}
 
@@ -213,7 +231,7 @@ func (r *SkillVersionResource) Read(ctx context.Context, req resource.ReadReques
 
 func (r *SkillVersionResource) Update(_ context.Context, _ resource.UpdateRequest, resp *resource.UpdateResponse) {
 	resp.Diagnostics.AddError(
-		"Update Not Supported",
+		errcode.Internal.Summary("Update Not Supported"),
 		"agentctx_skill_version does not support in-place updates. Changes to skill_id or source_dir force replacement.",
 	)
 }
@@ -223,6 +241,11 @@ func (r *SkillVersionResource) Update(_ context.Context, _ resource.UpdateReques
 // --------------------------------------------------------------------------
 
 func (r *SkillVersionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_skill_version", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var state SkillVersionResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -231,7 +254,20 @@ func (r *SkillVersionResource) Delete(ctx context.Context, req resource.DeleteRe
 
 	// Only delete the remote version if the provider is configured for
 	// destroy_remote.
-	if r.providerData.Anthropic != nil && r.providerData.Anthropic.DestroyRemote() {
+	destroyRemote := r.providerData.Anthropic != nil && r.providerData.Anthropic.DestroyRemote()
+	if r.providerData.DryRunning() {
+		change := dryrun.Change{
+			Resource:  "agentctx_skill_version",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+		}
+		if destroyRemote {
+			change.Registry = []dryrun.RegistryChange{{Action: dryrun.RegistryDeleteVersion, SkillID: state.SkillID.ValueString(), Version: state.Version.ValueString()}}
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(change)...)
+		return
+	}
+	if destroyRemote {
 		skillID := state.SkillID.ValueString()
 		versionStr := state.Version.ValueString()
 
@@ -245,7 +281,7 @@ func (r *SkillVersionResource) Delete(ctx context.Context, req resource.DeleteRe
 			// If already gone, suppress the error.
 			if !isAPINotFound(err, &apiErr) {
 				resp.Diagnostics.AddError(
-					"Delete Version Failed",
+					errcode.AnthropicRequestFailed.Summary("Delete Version Failed"),
 					fmt.Sprintf("Failed to delete version %q for skill %q: %s", versionStr, skillID, err),
 				)
 				return
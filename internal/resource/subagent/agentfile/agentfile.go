@@ -0,0 +1,63 @@
+// Package agentfile renders sub-agent Markdown files: YAML frontmatter
+// followed by the system prompt. It is shared by agentctx_subagent and the
+// agent blocks of agentctx_plugin, which the subagent package imports and
+// so cannot import it back.
+package agentfile
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter represents the YAML frontmatter of a sub-agent markdown file.
+// Field names use yaml tags matching the Claude Code sub-agent specification.
+type Frontmatter struct {
+	Name            string                   `yaml:"name"`
+	Description     string                   `yaml:"description"`
+	Tools           string                   `yaml:"tools,omitempty"`
+	DisallowedTools string                   `yaml:"disallowedTools,omitempty"`
+	Model           string                   `yaml:"model,omitempty"`
+	PermissionMode  string                   `yaml:"permissionMode,omitempty"`
+	MaxTurns        int64                    `yaml:"maxTurns,omitempty"`
+	Skills          []string                 `yaml:"skills,omitempty"`
+	Memory          string                   `yaml:"memory,omitempty"`
+	McpServers      map[string]McpServer     `yaml:"mcpServers,omitempty"`
+	Hooks           map[string][]HookMatcher `yaml:"hooks,omitempty"`
+}
+
+// McpServer represents an MCP server entry in the frontmatter.
+type McpServer struct {
+	Command string            `yaml:"command,omitempty"`
+	Args    []string          `yaml:"args,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	URL     string            `yaml:"url,omitempty"`
+}
+
+// HookMatcher represents a single hook matcher entry.
+type HookMatcher struct {
+	Matcher string      `yaml:"matcher,omitempty"`
+	Hooks   []HookEntry `yaml:"hooks"`
+}
+
+// HookEntry represents a single hook command.
+type HookEntry struct {
+	Type    string `yaml:"type"`
+	Command string `yaml:"command"`
+}
+
+// RenderFile returns a sub-agent file of fm followed by prompt, trimmed of
+// leading and trailing whitespace.
+func RenderFile(fm Frontmatter, prompt string) (string, error) {
+	yamlBytes, err := yaml.Marshal(&fm)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.Write(yamlBytes)
+	sb.WriteString("---\n\n")
+	sb.WriteString(strings.TrimSpace(prompt))
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
@@ -0,0 +1,83 @@
+package subagent
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/golden"
+)
+
+// Golden tests render representative sub-agent configurations and compare the
+// output with testdata/golden/<case>.md. Run with AGENTCTX_UPDATE_GOLDEN=1 to
+// accept intentional output changes.
+
+func TestGolden_Subagents(t *testing.T) {
+	env, _ := types.MapValueFrom(context.Background(), types.StringType, map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}"})
+
+	cases := map[string]*SubagentResourceModel{
+		"minimal": {
+			Name:            stringValue("helper"),
+			Description:     stringValue("General helper"),
+			OutputDir:       stringValue("unused"),
+			Prompt:          stringValue("You help with small tasks."),
+			Model:           types.StringNull(),
+			Tools:           types.ListNull(types.StringType),
+			DisallowedTools: types.ListNull(types.StringType),
+			PermissionMode:  types.StringNull(),
+			MaxTurns:        types.Int64Null(),
+			Skills:          types.ListNull(types.StringType),
+			Memory:          types.StringNull(),
+		},
+		"full": {
+			Name:            stringValue("security-reviewer"),
+			Description:     stringValue("Reviews changes for security issues: injection, secrets, authz"),
+			OutputDir:       stringValue("unused"),
+			Prompt:          stringValue("You are a security reviewer.\n\nFlag anything that handles untrusted input.\n"),
+			Model:           stringValue("sonnet"),
+			Tools:           listValue("Read", "Grep", "Bash"),
+			DisallowedTools: listValue("Write"),
+			PermissionMode:  stringValue("plan"),
+			MaxTurns:        types.Int64Value(12),
+			Skills:          listValue("threat-model"),
+			Memory:          stringValue("project"),
+			McpServers: []McpServerModel{
+				{
+					Name:    stringValue("github"),
+					Command: stringValue("github-mcp"),
+					Args:    listValue("--read-only"),
+					Env:     env,
+					URL:     types.StringNull(),
+				},
+			},
+			Hooks: []HooksModel{
+				{
+					PreToolUse: []HookMatcherModel{
+						{
+							Matcher: stringValue("Bash"),
+							Hooks:   []HookEntryModel{{Type: stringValue("command"), Command: stringValue("./scripts/guard.sh")}},
+						},
+					},
+					Stop: []HookMatcherModel{
+						{
+							Matcher: types.StringNull(),
+							Hooks:   []HookEntryModel{{Type: stringValue("command"), Command: stringValue("./scripts/report.sh")}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, model := range cases {
+		t.Run(name, func(t *testing.T) {
+			content, diags := Render(context.Background(), model)
+			if diags.HasError() {
+				t.Fatalf("unexpected errors: %v", diags.Errors())
+			}
+			golden.AssertFile(t, filepath.Join("testdata", "golden", name+".md"), []byte(content))
+		})
+	}
+}
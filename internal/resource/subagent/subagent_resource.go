@@ -6,30 +6,39 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-	"gopkg.in/yaml.v3"
-)
 
-// namePattern validates sub-agent names: lowercase letters, numbers, and
-// hyphens, starting and ending with a letter or number.
-var namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/contentcache"
+	"github.com/agentctx/terraform-provider-agentctx/internal/dryrun"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/plugin"
+	"github.com/agentctx/terraform-provider-agentctx/internal/resource/subagent/agentfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
+)
 
 // Compile-time interface checks.
 var (
-	_ resource.Resource = &SubagentResource{}
+	_ resource.Resource                   = &SubagentResource{}
+	_ resource.ResourceWithConfigure      = &SubagentResource{}
+	_ resource.ResourceWithValidateConfig = &SubagentResource{}
 )
 
 // NewSubagentResource returns a new resource.Resource for the
@@ -41,7 +50,9 @@ func NewSubagentResource() resource.Resource {
 // SubagentResource implements the agentctx_subagent Terraform resource.
 // It generates a Claude Code sub-agent markdown file (YAML frontmatter +
 // system prompt) and writes it to a local directory.
-type SubagentResource struct{}
+type SubagentResource struct {
+	providerData *providerdata.ProviderData
+}
 
 // --------------------------------------------------------------------------
 // Metadata
@@ -57,7 +68,7 @@ func (r *SubagentResource) Metadata(_ context.Context, req resource.MetadataRequ
 
 func (r *SubagentResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Manages a Claude Code sub-agent definition file. Generates a Markdown file with YAML frontmatter that conforms to the Claude Code sub-agent specification and writes it to a local directory.",
+		MarkdownDescription: "Manages a Claude Code sub-agent definition file. Generates a Markdown file with YAML frontmatter that conforms to the Claude Code sub-agent specification and writes it to a local directory or into a plugin generated by `agentctx_plugin`.",
 
 		Attributes: map[string]schema.Attribute{
 			// ---- Required ----
@@ -68,29 +79,36 @@ func (r *SubagentResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 					stringplanmodifier.RequiresReplace(),
 				},
 				Validators: []validator.String{
-					stringvalidator.RegexMatches(
-						namePattern,
-						"must contain only lowercase letters, numbers, and hyphens, and must start and end with a letter or number",
-					),
+					validators.Name(),
 				},
 			},
 			"description": schema.StringAttribute{
 				MarkdownDescription: "Describes when Claude should delegate to this sub-agent. Claude uses this to decide automatic delegation.",
 				Required:            true,
 			},
-			"output_dir": schema.StringAttribute{
-				MarkdownDescription: "Directory where the sub-agent markdown file will be written. Typically `.claude/agents` for project-level agents or a plugin `agents/` directory.",
-				Required:            true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
 			"prompt": schema.StringAttribute{
 				MarkdownDescription: "The system prompt for the sub-agent, written as the Markdown body after the YAML frontmatter.",
 				Required:            true,
 			},
 
 			// ---- Optional ----
+			"output_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory where the sub-agent markdown file will be written, typically `.claude/agents` for project-level agents. Exactly one of `output_dir` and `plugin_dir` is required.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("output_dir"), path.MatchRoot("plugin_dir")),
+				},
+			},
+			"plugin_dir": schema.StringAttribute{
+				MarkdownDescription: "`plugin_dir` of an `agentctx_plugin` to write the sub-agent into, as `agents/<name>.md`. The sub-agent is also listed in the plugin's `plugin.json`, and the plugin keeps it when it regenerates its directory. The plugin must not define an agent of the same name.",
+				Optional:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"model": schema.StringAttribute{
 				MarkdownDescription: "Model the sub-agent uses. Valid values: `sonnet`, `opus`, `haiku`, `inherit`. Defaults to `inherit` (same model as the main conversation).",
 				Optional:            true,
@@ -134,6 +152,30 @@ func (r *SubagentResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 					stringvalidator.OneOf("user", "project", "local"),
 				},
 			},
+			"content_storage": schema.StringAttribute{
+				MarkdownDescription: "What is kept in state for the rendered file: `\"full\"` stores it in `content`; `\"hash_only\"` stores `content` as null and keeps only `content_hash`, which refresh recomputes from the file on disk. Overrides the provider's `state_content` for this resource; unset follows it.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("full", "hash_only"),
+				},
+			},
+			"compatibility_level": schema.StringAttribute{
+				MarkdownDescription: "Oldest Claude Code release, such as `2.0.30`, the generated file must load in. Overrides the provider's `compatibility_level`. Frontmatter fields and MCP servers that need a newer release are left out of the file, with a warning.",
+				Optional:            true,
+			},
+			"regenerate_if_missing": schema.BoolAttribute{
+				MarkdownDescription: "When `true`, a refresh that finds the sub-agent file missing writes it again, from `cache_dir` if it holds the content or else by rendering the last applied arguments, instead of removing the resource from state. For scratch output directories such as clean CI workspaces. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"cache_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory of a content-addressed cache, keyed by `content_hash`, that the rendered file is also written to and that `regenerate_if_missing` restores it from. Can be shared between workspaces.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
 
 			// ---- Computed ----
 			"id": schema.StringAttribute{
@@ -144,7 +186,7 @@ func (r *SubagentResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				},
 			},
 			"content": schema.StringAttribute{
-				MarkdownDescription: "The rendered Markdown content of the sub-agent file (YAML frontmatter + prompt).",
+				MarkdownDescription: "The rendered Markdown content of the sub-agent file (YAML frontmatter + prompt). Null when `content_storage` is `\"hash_only\"`, or when it is unset and the provider's `state_content` is `\"hashes\"`.",
 				Computed:            true,
 			},
 			"file_path": schema.StringAttribute{
@@ -158,6 +200,10 @@ func (r *SubagentResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				MarkdownDescription: "SHA-256 hash of the rendered file content, prefixed with `sha256:`.",
 				Computed:            true,
 			},
+			"memory_file": schema.StringAttribute{
+				MarkdownDescription: "Absolute path to the sub-agent's `MEMORY.md` when a `memory_scaffold` block is set, null otherwise.",
+				Computed:            true,
+			},
 		},
 
 		Blocks: map[string]schema.Block{
@@ -190,6 +236,32 @@ func (r *SubagentResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 					},
 				},
 			},
+			"memory_scaffold": schema.ListNestedBlock{
+				MarkdownDescription: "Create the sub-agent's memory directory and its `MEMORY.md` for the `memory` scope, so the location the frontmatter refers to exists from the start. An existing `MEMORY.md` is never overwritten. Requires `memory`. At most one block may be specified.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"initial_content": schema.StringAttribute{
+							MarkdownDescription: "Content of `MEMORY.md` when it is created. Defaults to a `# <name> memory` heading.",
+							Optional:            true,
+						},
+						"project_dir": schema.StringAttribute{
+							MarkdownDescription: "Project whose `.claude` directory holds `project` and `local` memory. Defaults to the project of an `output_dir` of the form `<project>/.claude/agents`.",
+							Optional:            true,
+						},
+						"directory": schema.StringAttribute{
+							MarkdownDescription: "Memory directory to create, overriding the one derived from the scope.",
+							Optional:            true,
+						},
+						"delete_on_destroy": schema.BoolAttribute{
+							MarkdownDescription: "Delete `MEMORY.md`, and the memory directory if nothing else is in it, when the resource is destroyed. Defaults to `false`, keeping what the sub-agent learned.",
+							Optional:            true,
+						},
+					},
+				},
+			},
 			"hooks": schema.ListNestedBlock{
 				MarkdownDescription: "Lifecycle hooks scoped to this sub-agent. At most one block may be specified.",
 				Validators: []validator.List{
@@ -243,11 +315,94 @@ func hookMatcherBlockSchema(description string) schema.ListNestedBlock {
 	}
 }
 
+// --------------------------------------------------------------------------
+// Configure
+// --------------------------------------------------------------------------
+
+func (r *SubagentResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	pd, ok := req.ProviderData.(*providerdata.ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			errcode.Internal.Summary("Unexpected Resource Configure Type"),
+			fmt.Sprintf("Expected *providerdata.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = pd
+}
+
+// --------------------------------------------------------------------------
+// ValidateConfig
+// --------------------------------------------------------------------------
+
+// ValidateConfig checks the compatibility_level syntax and that a
+// memory_scaffold block comes with a memory scope.
+func (r *SubagentResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var memory types.String
+	var scaffold types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("memory"), &memory)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("memory_scaffold"), &scaffold)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if memory.IsNull() && !scaffold.IsUnknown() && len(scaffold.Elements()) > 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("memory_scaffold"),
+			errcode.InvalidConfig.Summary("Invalid Memory Scaffold"),
+			"memory_scaffold requires memory to be set to the scope whose directory it creates.",
+		)
+	}
+
+	var servers []McpServerModel
+	if d := req.Config.GetAttribute(ctx, path.Root("mcp_server"), &servers); !d.HasError() {
+		resp.Diagnostics.Append(ValidateURLs(servers, r.providerData.URLSchemes())...)
+	}
+
+	var level types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("compatibility_level"), &level)...)
+	if resp.Diagnostics.HasError() || level.IsNull() || level.IsUnknown() {
+		return
+	}
+	if _, err := claudeversion.ParseLevel(level.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("compatibility_level"),
+			errcode.ClaudeVersion.Summary("Invalid Compatibility Level"),
+			fmt.Sprintf("compatibility_level %q is not a Claude Code version: %s", level.ValueString(), err),
+		)
+	}
+}
+
+// ValidateURLs checks the url of each mcp_server block with
+// validators.CheckURL against schemes, the provider's allowed_url_schemes.
+// Unknown values are skipped.
+func ValidateURLs(servers []McpServerModel, schemes []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i, s := range servers {
+		if s.URL.IsNull() || s.URL.IsUnknown() {
+			continue
+		}
+		if err := validators.CheckURL(s.URL.ValueString(), schemes); err != nil {
+			diags.AddAttributeError(path.Root("mcp_server").AtListIndex(i).AtName("url"), errcode.InvalidConfig.Summary("Invalid URL"), err.Error())
+		}
+	}
+	return diags
+}
+
 // --------------------------------------------------------------------------
 // Create
 // --------------------------------------------------------------------------
 
 func (r *SubagentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_subagent", "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan SubagentResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -260,16 +415,27 @@ func (r *SubagentResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRun(&plan, dryrun.OpCreate, content)...)
+		return
+	}
+
 	filePath, err := r.writeFile(ctx, &plan, content)
 	if err != nil {
-		resp.Diagnostics.AddError("File Write Failed", fmt.Sprintf("Failed to write sub-agent file: %s", err))
+		resp.Diagnostics.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write sub-agent file: %s", err))
 		return
 	}
 
 	hash := computeHash(content)
+	resp.Diagnostics.Append(cacheContent(&plan, content)...)
+
+	resp.Diagnostics.Append(scaffoldMemory(&plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	plan.ID = types.StringValue(filePath)
-	plan.Content = types.StringValue(content)
+	plan.Content = r.providerData.ContentFor(plan.ContentStorage, content)
 	plan.FilePath = types.StringValue(filePath)
 	plan.ContentHash = types.StringValue(hash)
 
@@ -294,23 +460,42 @@ func (r *SubagentResource) Read(ctx context.Context, req resource.ReadRequest, r
 
 	filePath := state.FilePath.ValueString()
 
-	data, err := os.ReadFile(filePath)
+	data, err := os.ReadFile(longpath.Path(filePath))
 	if err != nil {
-		if os.IsNotExist(err) {
+		if !os.IsNotExist(err) {
+			resp.Diagnostics.AddError(errcode.FileRead.Summary("File Read Failed"), fmt.Sprintf("Failed to read sub-agent file %q: %s", filePath, err))
+			return
+		}
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_subagent", state.ID.ValueString(), fmt.Sprintf("file %q was deleted", filePath))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !state.RegenerateIfMissing.ValueBool() || r.providerData.CheckWritable("agentctx_subagent", "regenerate").HasError() || r.providerData.DryRunning() {
 			tflog.Info(ctx, "sub-agent file not found on disk, removing from state", map[string]interface{}{
 				"file_path": filePath,
 			})
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		resp.Diagnostics.AddError("File Read Failed", fmt.Sprintf("Failed to read sub-agent file %q: %s", filePath, err))
-		return
+
+		var diags diag.Diagnostics
+		data, diags = r.regenerate(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 	}
 
 	diskContent := string(data)
 	diskHash := computeHash(diskContent)
+	if prior := state.ContentHash.ValueString(); prior != "" && diskHash != prior {
+		resp.Diagnostics.Append(r.providerData.CheckDrift("agentctx_subagent", state.ID.ValueString(), fmt.Sprintf("file %q has hash %s, want %s", filePath, diskHash, prior))...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 
-	state.Content = types.StringValue(diskContent)
+	state.Content = r.providerData.ContentFor(state.ContentStorage, diskContent)
 	state.ContentHash = types.StringValue(diskHash)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
@@ -321,6 +506,11 @@ func (r *SubagentResource) Read(ctx context.Context, req resource.ReadRequest, r
 // --------------------------------------------------------------------------
 
 func (r *SubagentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_subagent", "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var plan SubagentResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
@@ -333,16 +523,27 @@ func (r *SubagentResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	if r.providerData.DryRunning() {
+		resp.Diagnostics.Append(r.dryRun(&plan, dryrun.OpUpdate, content)...)
+		return
+	}
+
 	filePath, err := r.writeFile(ctx, &plan, content)
 	if err != nil {
-		resp.Diagnostics.AddError("File Write Failed", fmt.Sprintf("Failed to write sub-agent file: %s", err))
+		resp.Diagnostics.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to write sub-agent file: %s", err))
 		return
 	}
 
 	hash := computeHash(content)
+	resp.Diagnostics.Append(cacheContent(&plan, content)...)
+
+	resp.Diagnostics.Append(scaffoldMemory(&plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	plan.ID = types.StringValue(filePath)
-	plan.Content = types.StringValue(content)
+	plan.Content = r.providerData.ContentFor(plan.ContentStorage, content)
 	plan.FilePath = types.StringValue(filePath)
 	plan.ContentHash = types.StringValue(hash)
 
@@ -359,6 +560,11 @@ func (r *SubagentResource) Update(ctx context.Context, req resource.UpdateReques
 // --------------------------------------------------------------------------
 
 func (r *SubagentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(r.providerData.CheckWritable("agentctx_subagent", "delete")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var state SubagentResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
@@ -367,8 +573,32 @@ func (r *SubagentResource) Delete(ctx context.Context, req resource.DeleteReques
 
 	filePath := state.FilePath.ValueString()
 
-	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
-		resp.Diagnostics.AddError("File Delete Failed", fmt.Sprintf("Failed to delete sub-agent file %q: %s", filePath, err))
+	if r.providerData.DryRunning() {
+		files := []dryrun.FileChange{dryrun.RemoveFile(filePath)}
+		if len(state.MemoryScaffold) == 1 && state.MemoryScaffold[0].DeleteOnDestroy.ValueBool() && state.MemoryFile.ValueString() != "" {
+			files = append(files, dryrun.RemoveFile(state.MemoryFile.ValueString()))
+		}
+		resp.Diagnostics.Append(r.providerData.RecordDryRun(dryrun.Change{
+			Resource:  "agentctx_subagent",
+			Operation: dryrun.OpDelete,
+			ID:        state.ID.ValueString(),
+			Files:     files,
+		})...)
+		return
+	}
+
+	if pluginDir := state.PluginDir.ValueString(); pluginDir != "" {
+		if err := plugin.UnregisterAgent(ctx, pluginDir, state.Name.ValueString()); err != nil {
+			resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to remove sub-agent %q from the plugin in %q: %s", state.Name.ValueString(), pluginDir, err))
+			return
+		}
+	} else if err := os.Remove(longpath.Path(filePath)); err != nil && !os.IsNotExist(err) {
+		resp.Diagnostics.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete sub-agent file %q: %s", filePath, err))
+		return
+	}
+
+	resp.Diagnostics.Append(removeMemory(&state)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -382,45 +612,20 @@ func (r *SubagentResource) Delete(ctx context.Context, req resource.DeleteReques
 // Rendering
 // --------------------------------------------------------------------------
 
-// frontmatter represents the YAML frontmatter of a sub-agent markdown file.
-// Field names use yaml tags matching the Claude Code sub-agent specification.
-type frontmatter struct {
-	Name            string                              `yaml:"name"`
-	Description     string                              `yaml:"description"`
-	Tools           string                              `yaml:"tools,omitempty"`
-	DisallowedTools string                              `yaml:"disallowedTools,omitempty"`
-	Model           string                              `yaml:"model,omitempty"`
-	PermissionMode  string                              `yaml:"permissionMode,omitempty"`
-	MaxTurns        int64                               `yaml:"maxTurns,omitempty"`
-	Skills          []string                            `yaml:"skills,omitempty"`
-	Memory          string                              `yaml:"memory,omitempty"`
-	McpServers      map[string]mcpServerFrontmatter     `yaml:"mcpServers,omitempty"`
-	Hooks           map[string][]hookMatcherFrontmatter `yaml:"hooks,omitempty"`
-}
-
-// mcpServerFrontmatter represents an MCP server entry in the frontmatter.
-type mcpServerFrontmatter struct {
-	Command string            `yaml:"command,omitempty"`
-	Args    []string          `yaml:"args,omitempty"`
-	Env     map[string]string `yaml:"env,omitempty"`
-	URL     string            `yaml:"url,omitempty"`
-}
-
-// hookMatcherFrontmatter represents a single hook matcher entry.
-type hookMatcherFrontmatter struct {
-	Matcher string                 `yaml:"matcher,omitempty"`
-	Hooks   []hookEntryFrontmatter `yaml:"hooks"`
-}
-
-// hookEntryFrontmatter represents a single hook command.
-type hookEntryFrontmatter struct {
-	Type    string `yaml:"type"`
-	Command string `yaml:"command"`
+// Render returns the Markdown content (YAML frontmatter + prompt) of the
+// sub-agent described by model. It is shared with resources that generate
+// sub-agent files of their own, such as agentctx_agent_team.
+func Render(ctx context.Context, model *SubagentResourceModel) (string, diag.Diagnostics) {
+	return (&SubagentResource{}).renderContent(ctx, model)
 }
 
 // renderContent builds the full markdown file content from the resource model.
 func (r *SubagentResource) renderContent(ctx context.Context, model *SubagentResourceModel) (string, diag.Diagnostics) {
-	fm := frontmatter{
+	var diags diag.Diagnostics
+	level := r.providerData.Compatibility(model.CompatibilityLevel)
+	var omitted []claudeversion.Feature
+
+	fm := agentfile.Frontmatter{
 		Name:        model.Name.ValueString(),
 		Description: model.Description.ValueString(),
 	}
@@ -471,15 +676,25 @@ func (r *SubagentResource) renderContent(ctx context.Context, model *SubagentRes
 
 	// MCP Servers
 	if len(model.McpServers) > 0 {
-		fm.McpServers = make(map[string]mcpServerFrontmatter, len(model.McpServers))
+		fm.McpServers = make(map[string]agentfile.McpServer, len(model.McpServers))
+		urlFeature, _ := claudeversion.McpFieldFeature("url")
 		for _, srv := range model.McpServers {
-			entry := mcpServerFrontmatter{}
+			// A remote server without its url would fail to start, so the
+			// whole entry goes when the level predates url transport.
+			if srv.URL.ValueString() != "" && !level.Allows(urlFeature) {
+				omitted = append(omitted, claudeversion.Feature{
+					Name:       fmt.Sprintf("MCP server %q (url transport)", srv.Name.ValueString()),
+					MinVersion: urlFeature.MinVersion,
+				})
+				continue
+			}
+			entry := agentfile.McpServer{}
 
 			if !srv.Command.IsNull() && !srv.Command.IsUnknown() {
 				entry.Command = srv.Command.ValueString()
 			}
 			if !srv.URL.IsNull() && !srv.URL.IsUnknown() {
-				entry.URL = srv.URL.ValueString()
+				entry.URL = validators.NormalizeURL(srv.URL.ValueString())
 			}
 			if !srv.Args.IsNull() && !srv.Args.IsUnknown() {
 				var args []string
@@ -503,9 +718,11 @@ func (r *SubagentResource) renderContent(ctx context.Context, model *SubagentRes
 	}
 
 	// Hooks
-	if len(model.Hooks) > 0 {
+	if hooksFeature, ok := claudeversion.SubagentFieldFeature("hooks"); ok && len(model.Hooks) > 0 && !level.Allows(hooksFeature) {
+		omitted = append(omitted, hooksFeature)
+	} else if len(model.Hooks) > 0 {
 		hooks := model.Hooks[0]
-		fm.Hooks = make(map[string][]hookMatcherFrontmatter)
+		fm.Hooks = make(map[string][]agentfile.HookMatcher)
 
 		if len(hooks.PreToolUse) > 0 {
 			fm.Hooks["PreToolUse"] = convertHookMatchers(hooks.PreToolUse)
@@ -518,36 +735,38 @@ func (r *SubagentResource) renderContent(ctx context.Context, model *SubagentRes
 		}
 	}
 
-	// Marshal frontmatter to YAML
-	yamlBytes, err := yaml.Marshal(&fm)
+	if len(omitted) > 0 {
+		names := make([]string, len(omitted))
+		for i, f := range omitted {
+			names[i] = fmt.Sprintf("%s (requires Claude Code >= %s)", f.Name, f.MinVersion)
+		}
+		diags.AddWarning(
+			errcode.ClaudeVersion.Summary("Features Omitted For Compatibility Level"),
+			fmt.Sprintf("compatibility_level %s predates %s. These were left out of the sub-agent file; "+
+				"raise compatibility_level once every Claude Code installation is at least %s to emit them.",
+				level, strings.Join(names, ", "), claudeversion.MinVersion(omitted)),
+		)
+	}
+
+	content, err := agentfile.RenderFile(fm, model.Prompt.ValueString())
 	if err != nil {
-		var diags diag.Diagnostics
-		diags.AddError("YAML Marshal Failed", fmt.Sprintf("Failed to marshal sub-agent frontmatter: %s", err))
+		diags.AddError(errcode.Encoding.Summary("YAML Marshal Failed"), fmt.Sprintf("Failed to marshal sub-agent frontmatter: %s", err))
 		return "", diags
 	}
-
-	// Build the full markdown content
-	var sb strings.Builder
-	sb.WriteString("---\n")
-	sb.Write(yamlBytes)
-	sb.WriteString("---\n\n")
-	sb.WriteString(strings.TrimSpace(model.Prompt.ValueString()))
-	sb.WriteString("\n")
-
-	return sb.String(), nil
+	return content, diags
 }
 
 // convertHookMatchers converts the Terraform model hook matchers to the
 // frontmatter representation.
-func convertHookMatchers(matchers []HookMatcherModel) []hookMatcherFrontmatter {
-	result := make([]hookMatcherFrontmatter, 0, len(matchers))
+func convertHookMatchers(matchers []HookMatcherModel) []agentfile.HookMatcher {
+	result := make([]agentfile.HookMatcher, 0, len(matchers))
 	for _, m := range matchers {
-		entry := hookMatcherFrontmatter{}
+		entry := agentfile.HookMatcher{}
 		if !m.Matcher.IsNull() && !m.Matcher.IsUnknown() {
 			entry.Matcher = m.Matcher.ValueString()
 		}
 		for _, h := range m.Hooks {
-			entry.Hooks = append(entry.Hooks, hookEntryFrontmatter{
+			entry.Hooks = append(entry.Hooks, agentfile.HookEntry{
 				Type:    h.Type.ValueString(),
 				Command: h.Command.ValueString(),
 			})
@@ -561,31 +780,120 @@ func convertHookMatchers(matchers []HookMatcherModel) []hookMatcherFrontmatter {
 // File operations
 // --------------------------------------------------------------------------
 
-// writeFile writes the rendered content to the output directory and returns
-// the absolute file path.
-func (r *SubagentResource) writeFile(_ context.Context, model *SubagentResourceModel, content string) (string, error) {
+// writeFile writes the rendered content to the output directory, or
+// registers it in the plugin in plugin_dir, and returns the absolute file
+// path.
+func (r *SubagentResource) writeFile(ctx context.Context, model *SubagentResourceModel, content string) (string, error) {
+	if pluginDir := model.PluginDir.ValueString(); pluginDir != "" {
+		return plugin.RegisterAgent(ctx, pluginDir, model.Name.ValueString(), []byte(content))
+	}
+
 	outputDir := model.OutputDir.ValueString()
-	name := model.Name.ValueString()
-	fileName := name + ".md"
 
-	// Ensure the output directory exists.
-	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+	// Ensure the output directory exists. Long paths and UNC shares need the
+	// extended-length form on Windows.
+	if err := os.MkdirAll(longpath.Path(outputDir), 0o755); err != nil {
 		return "", fmt.Errorf("creating output directory %q: %w", outputDir, err)
 	}
 
-	filePath := filepath.Join(outputDir, fileName)
+	absPath, err := outputFilePath(model)
+	if err != nil {
+		return "", err
+	}
+
+	if err := atomicfile.WriteFile(longpath.Path(absPath), []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("writing file %q: %w", absPath, err)
+	}
+
+	return absPath, nil
+}
 
-	// Resolve to absolute path for consistency.
+// outputFilePath returns the absolute path of the sub-agent file of model.
+func outputFilePath(model *SubagentResourceModel) (string, error) {
+	dir := model.OutputDir.ValueString()
+	if pluginDir := model.PluginDir.ValueString(); pluginDir != "" {
+		dir = filepath.Join(pluginDir, "agents")
+	}
+	filePath := filepath.Join(dir, model.Name.ValueString()+".md")
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
 		return "", fmt.Errorf("resolving absolute path for %q: %w", filePath, err)
 	}
+	return absPath, nil
+}
 
-	if err := os.WriteFile(absPath, []byte(content), 0o644); err != nil {
-		return "", fmt.Errorf("writing file %q: %w", absPath, err)
+// dryRun records the sub-agent file Create or Update would write in the
+// dry-run report.
+func (r *SubagentResource) dryRun(model *SubagentResourceModel, operation, content string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	filePath, err := outputFilePath(model)
+	if err != nil {
+		diags.AddError(errcode.PathResolution.Summary("Path Resolution Failed"), err.Error())
+		return diags
 	}
+	return r.providerData.RecordDryRun(dryrun.Change{
+		Resource:  "agentctx_subagent",
+		Operation: operation,
+		ID:        filePath,
+		Files:     []dryrun.FileChange{dryrun.WriteFile(filePath, []byte(content))},
+	})
+}
 
-	return absPath, nil
+// regenerate writes the missing sub-agent file of state again and returns
+// its content. The content is restored from cache_dir when it holds an entry
+// for content_hash, and rendered from the arguments in state otherwise.
+func (r *SubagentResource) regenerate(ctx context.Context, state *SubagentResourceModel) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	source := "rendered"
+	var content string
+	if cacheDir := state.CacheDir.ValueString(); cacheDir != "" {
+		data, err := contentcache.Get(cacheDir, state.ContentHash.ValueString())
+		switch {
+		case err == nil:
+			content = string(data)
+			source = "cache"
+		case !os.IsNotExist(err):
+			tflog.Warn(ctx, "ignoring unreadable content cache entry", map[string]interface{}{
+				"cache_dir": cacheDir,
+				"error":     err.Error(),
+			})
+		}
+	}
+	if source == "rendered" {
+		rendered, d := r.renderContent(ctx, state)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		content = rendered
+		diags.Append(cacheContent(state, content)...)
+	}
+
+	if _, err := r.writeFile(ctx, state, content); err != nil {
+		diags.AddError(errcode.FileWrite.Summary("File Write Failed"), fmt.Sprintf("Failed to regenerate sub-agent file: %s", err))
+		return nil, diags
+	}
+
+	tflog.Info(ctx, "regenerated missing sub-agent file", map[string]interface{}{
+		"file_path": state.FilePath.ValueString(),
+		"source":    source,
+	})
+	return []byte(content), diags
+}
+
+// cacheContent stores content in the model's cache_dir, if set. The cache
+// only speeds up regeneration, so a failure is a warning.
+func cacheContent(model *SubagentResourceModel, content string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	cacheDir := model.CacheDir.ValueString()
+	if cacheDir == "" {
+		return diags
+	}
+	if _, err := contentcache.Put(cacheDir, []byte(content)); err != nil {
+		diags.AddAttributeWarning(path.Root("cache_dir"), errcode.FileWrite.Summary("Content Cache Write Failed"), fmt.Sprintf("Failed to store the sub-agent file in the content cache: %s", err))
+	}
+	return diags
 }
 
 // --------------------------------------------------------------------------
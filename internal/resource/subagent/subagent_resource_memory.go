@@ -0,0 +1,154 @@
+package subagent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/atomicfile"
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+)
+
+// memoryFileName is the file Claude Code loads from a sub-agent's memory
+// directory at startup and updates as the sub-agent learns.
+const memoryFileName = "MEMORY.md"
+
+// memoryScopeDirs maps a memory scope to the directory, relative to the
+// scope's root, that holds one memory directory per sub-agent. The user
+// scope is rooted at the home directory, the others at the project.
+var memoryScopeDirs = map[string]string{
+	"user":    filepath.Join(".claude", "agent-memory"),
+	"project": filepath.Join(".claude", "agent-memory"),
+	"local":   filepath.Join(".claude", "agent-memory-local"),
+}
+
+// memoryDir returns the memory directory of the sub-agent described by
+// model, which must have a memory_scaffold block: the block's directory
+// if set, and otherwise the scope's directory under home (user scope) or
+// the project. The project defaults to the parent of .claude when
+// output_dir is <project>/.claude/agents.
+func memoryDir(model *SubagentResourceModel, home string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	scaffold := model.MemoryScaffold[0]
+	scaffoldPath := path.Root("memory_scaffold").AtListIndex(0)
+
+	if dir := scaffold.Directory.ValueString(); dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			diags.AddAttributeError(scaffoldPath.AtName("directory"), errcode.PathResolution.Summary("Path Resolution Failed"),
+				fmt.Sprintf("Failed to resolve absolute path for %q: %s", dir, err))
+			return "", diags
+		}
+		return abs, diags
+	}
+
+	scope := model.Memory.ValueString()
+	root := home
+	if scope != "user" {
+		root = scaffold.ProjectDir.ValueString()
+		if root == "" && model.OutputDir.IsNull() {
+			diags.AddAttributeError(scaffoldPath.AtName("project_dir"), errcode.InvalidConfig.Summary("Invalid Memory Scaffold"),
+				fmt.Sprintf("The sub-agent is written into a plugin, so the %s memory scope's project cannot be derived. Set project_dir or directory.", scope))
+			return "", diags
+		}
+		if root == "" {
+			outputDir, err := filepath.Abs(model.OutputDir.ValueString())
+			if err != nil {
+				diags.AddAttributeError(path.Root("output_dir"), errcode.PathResolution.Summary("Path Resolution Failed"),
+					fmt.Sprintf("Failed to resolve absolute path for %q: %s", model.OutputDir.ValueString(), err))
+				return "", diags
+			}
+			claudeDir := filepath.Dir(outputDir)
+			if filepath.Base(outputDir) != "agents" || filepath.Base(claudeDir) != ".claude" {
+				diags.AddAttributeError(scaffoldPath.AtName("project_dir"), errcode.InvalidConfig.Summary("Invalid Memory Scaffold"),
+					fmt.Sprintf("output_dir %q is not a project's .claude/agents directory, so the %s memory scope's project cannot be derived. "+
+						"Set project_dir or directory.", model.OutputDir.ValueString(), scope))
+				return "", diags
+			}
+			root = filepath.Dir(claudeDir)
+		}
+	}
+
+	abs, err := filepath.Abs(filepath.Join(root, memoryScopeDirs[scope], model.Name.ValueString()))
+	if err != nil {
+		diags.AddAttributeError(scaffoldPath, errcode.PathResolution.Summary("Path Resolution Failed"),
+			fmt.Sprintf("Failed to resolve the memory directory: %s", err))
+		return "", diags
+	}
+	return abs, diags
+}
+
+// scaffoldMemory creates the memory directory of model and its MEMORY.md
+// with initial_content, and sets memory_file. An existing MEMORY.md holds
+// what the sub-agent has learned and is never overwritten. Without a
+// memory_scaffold block, memory_file is null.
+func scaffoldMemory(model *SubagentResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(model.MemoryScaffold) == 0 {
+		model.MemoryFile = types.StringNull()
+		return diags
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil && model.Memory.ValueString() == "user" && model.MemoryScaffold[0].Directory.ValueString() == "" {
+		diags.AddAttributeError(path.Root("memory"), errcode.PathResolution.Summary("Path Resolution Failed"),
+			fmt.Sprintf("Failed to find the home directory for the user memory scope: %s", err))
+		return diags
+	}
+	dir, d := memoryDir(model, home)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	memoryFile := filepath.Join(dir, memoryFileName)
+	model.MemoryFile = types.StringValue(memoryFile)
+
+	if _, err := os.Stat(longpath.Path(memoryFile)); err == nil {
+		return diags
+	} else if !os.IsNotExist(err) {
+		diags.AddAttributeError(path.Root("memory_scaffold").AtListIndex(0), errcode.FileRead.Summary("File Read Failed"),
+			fmt.Sprintf("Failed to check memory file %q: %s", memoryFile, err))
+		return diags
+	}
+
+	if err := os.MkdirAll(longpath.Path(dir), 0o755); err != nil {
+		diags.AddAttributeError(path.Root("memory_scaffold").AtListIndex(0), errcode.FileWrite.Summary("Directory Create Failed"),
+			fmt.Sprintf("Failed to create memory directory %q: %s", dir, err))
+		return diags
+	}
+	content := fmt.Sprintf("# %s memory\n", model.Name.ValueString())
+	if initial := model.MemoryScaffold[0].InitialContent; !initial.IsNull() && !initial.IsUnknown() {
+		content = initial.ValueString()
+	}
+	if err := atomicfile.WriteFile(longpath.Path(memoryFile), []byte(content), 0o644); err != nil {
+		diags.AddAttributeError(path.Root("memory_scaffold").AtListIndex(0).AtName("initial_content"), errcode.FileWrite.Summary("File Write Failed"),
+			fmt.Sprintf("Failed to write memory file %q: %s", memoryFile, err))
+		return diags
+	}
+	return diags
+}
+
+// removeMemory deletes the memory file of state, and its directory if that
+// is then empty, when the memory_scaffold block sets delete_on_destroy.
+func removeMemory(state *SubagentResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(state.MemoryScaffold) == 0 || !state.MemoryScaffold[0].DeleteOnDestroy.ValueBool() || state.MemoryFile.ValueString() == "" {
+		return diags
+	}
+
+	memoryFile := state.MemoryFile.ValueString()
+	if err := os.Remove(longpath.Path(memoryFile)); err != nil && !os.IsNotExist(err) {
+		diags.AddError(errcode.FileDelete.Summary("File Delete Failed"), fmt.Sprintf("Failed to delete memory file %q: %s", memoryFile, err))
+		return diags
+	}
+	// Other files the sub-agent wrote next to MEMORY.md are kept, and so is
+	// the directory holding them.
+	_ = os.Remove(longpath.Path(filepath.Dir(memoryFile)))
+	return diags
+}
@@ -0,0 +1,159 @@
+package subagent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func memoryModel(outputDir, scope string, scaffold MemoryScaffoldModel) *SubagentResourceModel {
+	return &SubagentResourceModel{
+		Name:           stringValue("reviewer"),
+		OutputDir:      stringValue(outputDir),
+		Memory:         stringValue(scope),
+		MemoryScaffold: []MemoryScaffoldModel{scaffold},
+	}
+}
+
+func emptyScaffold() MemoryScaffoldModel {
+	return MemoryScaffoldModel{
+		InitialContent:  types.StringNull(),
+		ProjectDir:      types.StringNull(),
+		Directory:       types.StringNull(),
+		DeleteOnDestroy: types.BoolNull(),
+	}
+}
+
+func TestMemoryDir(t *testing.T) {
+	project := t.TempDir()
+	home := t.TempDir()
+	agents := filepath.Join(project, ".claude", "agents")
+
+	cases := []struct {
+		name     string
+		scope    string
+		scaffold func(*MemoryScaffoldModel)
+		want     string
+	}{
+		{"user", "user", nil, filepath.Join(home, ".claude", "agent-memory", "reviewer")},
+		{"project from output_dir", "project", nil, filepath.Join(project, ".claude", "agent-memory", "reviewer")},
+		{"local from output_dir", "local", nil, filepath.Join(project, ".claude", "agent-memory-local", "reviewer")},
+		{"explicit directory", "project", func(s *MemoryScaffoldModel) { s.Directory = stringValue(filepath.Join(home, "mem")) }, filepath.Join(home, "mem")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scaffold := emptyScaffold()
+			if tc.scaffold != nil {
+				tc.scaffold(&scaffold)
+			}
+			got, diags := memoryDir(memoryModel(agents, tc.scope, scaffold), home)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if got != tc.want {
+				t.Errorf("memoryDir = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMemoryDir_ProjectNotDerivable(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "plugin", "agents")
+	if _, diags := memoryDir(memoryModel(outputDir, "project", emptyScaffold()), ""); !diags.HasError() {
+		t.Fatal("expected an error for an output_dir outside .claude/agents")
+	}
+
+	project := t.TempDir()
+	scaffold := emptyScaffold()
+	scaffold.ProjectDir = stringValue(project)
+	got, diags := memoryDir(memoryModel(outputDir, "project", scaffold), "")
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if want := filepath.Join(project, ".claude", "agent-memory", "reviewer"); got != want {
+		t.Errorf("memoryDir = %q, want %q", got, want)
+	}
+}
+
+func TestScaffoldMemory_KeepsExistingMemory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mem")
+	scaffold := emptyScaffold()
+	scaffold.Directory = stringValue(dir)
+	scaffold.InitialContent = stringValue("# Conventions\n")
+	model := memoryModel(t.TempDir(), "project", scaffold)
+
+	if diags := scaffoldMemory(model); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	memoryFile := filepath.Join(dir, "MEMORY.md")
+	if model.MemoryFile.ValueString() != memoryFile {
+		t.Errorf("memory_file = %q, want %q", model.MemoryFile.ValueString(), memoryFile)
+	}
+	data, err := os.ReadFile(memoryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "# Conventions\n" {
+		t.Errorf("MEMORY.md = %q, want the initial content", data)
+	}
+
+	// What the sub-agent learned survives the next apply.
+	if err := os.WriteFile(memoryFile, []byte("learned"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if diags := scaffoldMemory(model); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if data, _ := os.ReadFile(memoryFile); string(data) != "learned" {
+		t.Errorf("MEMORY.md overwritten: %q", data)
+	}
+}
+
+func TestScaffoldMemory_DefaultContentAndNoBlock(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mem")
+	scaffold := emptyScaffold()
+	scaffold.Directory = stringValue(dir)
+	model := memoryModel(t.TempDir(), "user", scaffold)
+	if diags := scaffoldMemory(model); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "MEMORY.md")); string(data) != "# reviewer memory\n" {
+		t.Errorf("MEMORY.md = %q, want the default heading", data)
+	}
+
+	model.MemoryScaffold = nil
+	if diags := scaffoldMemory(model); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if !model.MemoryFile.IsNull() {
+		t.Errorf("memory_file = %v without a memory_scaffold block, want null", model.MemoryFile)
+	}
+}
+
+func TestRemoveMemory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mem")
+	scaffold := emptyScaffold()
+	scaffold.Directory = stringValue(dir)
+	model := memoryModel(t.TempDir(), "project", scaffold)
+	if diags := scaffoldMemory(model); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	// Kept by default.
+	if diags := removeMemory(model); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, err := os.Stat(model.MemoryFile.ValueString()); err != nil {
+		t.Fatalf("memory file removed without delete_on_destroy: %v", err)
+	}
+
+	model.MemoryScaffold[0].DeleteOnDestroy = types.BoolValue(true)
+	if diags := removeMemory(model); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("empty memory directory left behind (stat err %v)", err)
+	}
+}
@@ -9,9 +9,12 @@ type SubagentResourceModel struct {
 	// Required
 	Name        types.String `tfsdk:"name"`
 	Description types.String `tfsdk:"description"`
-	OutputDir   types.String `tfsdk:"output_dir"`
 	Prompt      types.String `tfsdk:"prompt"`
 
+	// Optional – destination, exactly one of
+	OutputDir types.String `tfsdk:"output_dir"`
+	PluginDir types.String `tfsdk:"plugin_dir"`
+
 	// Optional – simple fields
 	Model           types.String `tfsdk:"model"`
 	Tools           types.List   `tfsdk:"tools"`
@@ -20,16 +23,34 @@ type SubagentResourceModel struct {
 	MaxTurns        types.Int64  `tfsdk:"max_turns"`
 	Skills          types.List   `tfsdk:"skills"`
 	Memory          types.String `tfsdk:"memory"`
+	ContentStorage  types.String `tfsdk:"content_storage"`
+
+	// Optional – compatibility
+	CompatibilityLevel types.String `tfsdk:"compatibility_level"`
+
+	// Optional – scratch output directories
+	RegenerateIfMissing types.Bool   `tfsdk:"regenerate_if_missing"`
+	CacheDir            types.String `tfsdk:"cache_dir"`
 
 	// Optional – blocks
-	McpServers []McpServerModel `tfsdk:"mcp_server"`
-	Hooks      []HooksModel     `tfsdk:"hooks"`
+	McpServers     []McpServerModel      `tfsdk:"mcp_server"`
+	Hooks          []HooksModel          `tfsdk:"hooks"`
+	MemoryScaffold []MemoryScaffoldModel `tfsdk:"memory_scaffold"`
 
 	// Computed
 	ID          types.String `tfsdk:"id"`
 	Content     types.String `tfsdk:"content"`
 	FilePath    types.String `tfsdk:"file_path"`
 	ContentHash types.String `tfsdk:"content_hash"`
+	MemoryFile  types.String `tfsdk:"memory_file"`
+}
+
+// MemoryScaffoldModel maps the memory_scaffold {} block.
+type MemoryScaffoldModel struct {
+	InitialContent  types.String `tfsdk:"initial_content"`
+	ProjectDir      types.String `tfsdk:"project_dir"`
+	Directory       types.String `tfsdk:"directory"`
+	DeleteOnDestroy types.Bool   `tfsdk:"delete_on_destroy"`
 }
 
 // HooksModel maps the hooks {} block.
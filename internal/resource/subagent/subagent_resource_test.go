@@ -8,6 +8,11 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/claudeversion"
+	"github.com/agentctx/terraform-provider-agentctx/internal/longpath"
+	"github.com/agentctx/terraform-provider-agentctx/internal/providerdata"
+	"github.com/agentctx/terraform-provider-agentctx/internal/validators"
 )
 
 func TestComputeHash(t *testing.T) {
@@ -347,6 +352,54 @@ func TestRenderContent_PromptWhitespaceTrimmed(t *testing.T) {
 // writeFile tests
 // --------------------------------------------------------------------------
 
+func TestRenderContent_CompatibilityLevel(t *testing.T) {
+	level, err := claudeversion.ParseLevel("1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := &SubagentResourceModel{
+		Name:            stringValue("reviewer"),
+		Description:     stringValue("Reviews code"),
+		Prompt:          stringValue("Review."),
+		Tools:           types.ListNull(types.StringType),
+		DisallowedTools: types.ListNull(types.StringType),
+		Skills:          types.ListNull(types.StringType),
+		McpServers: []McpServerModel{
+			{Name: stringValue("local"), Command: stringValue("npx"), Args: types.ListNull(types.StringType), Env: types.MapNull(types.StringType), URL: types.StringNull()},
+			{Name: stringValue("remote"), Command: types.StringNull(), Args: types.ListNull(types.StringType), Env: types.MapNull(types.StringType), URL: stringValue("https://mcp.example.com")},
+		},
+		Hooks: []HooksModel{{
+			Stop: []HookMatcherModel{{
+				Matcher: types.StringNull(),
+				Hooks:   []HookEntryModel{{Type: stringValue("command"), Command: stringValue("./done.sh")}},
+			}},
+		}},
+	}
+
+	r := &SubagentResource{providerData: &providerdata.ProviderData{CompatibilityLevel: level}}
+	content, diags := r.renderContent(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags.Errors())
+	}
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("warnings = %d, want 1: %v", diags.WarningsCount(), diags)
+	}
+	assertContains(t, diags.Warnings()[0].Detail(), "hooks sub-agent frontmatter field")
+	assertContains(t, diags.Warnings()[0].Detail(), `MCP server "remote"`)
+	assertContains(t, content, "local:")
+	assertNotContains(t, content, "remote:")
+	assertNotContains(t, content, "hooks:")
+
+	// The resource's own level overrides the provider's.
+	model.CompatibilityLevel = stringValue("2.1.0")
+	content, diags = r.renderContent(context.Background(), model)
+	if diags.HasError() || diags.WarningsCount() != 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	assertContains(t, content, "remote:")
+	assertContains(t, content, "hooks:")
+}
+
 func TestWriteFile_CreatesDirectoryAndFile(t *testing.T) {
 	r := &SubagentResource{}
 	dir := filepath.Join(t.TempDir(), "nested", "agents")
@@ -386,6 +439,30 @@ func TestWriteFile_CreatesDirectoryAndFile(t *testing.T) {
 	}
 }
 
+func TestWriteFile_BeyondMaxPath(t *testing.T) {
+	r := &SubagentResource{}
+	dir := t.TempDir()
+	for len(dir) <= 300 {
+		dir = filepath.Join(dir, strings.Repeat("a", 40))
+	}
+
+	model := &SubagentResourceModel{
+		Name:      stringValue("deep-agent"),
+		OutputDir: stringValue(dir),
+	}
+	filePath, err := r.writeFile(context.Background(), model, "deep content")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := filepath.Join(dir, "deep-agent.md"); filePath != want {
+		t.Errorf("file_path = %q, want %q", filePath, want)
+	}
+	data, err := os.ReadFile(longpath.Path(filePath))
+	if err != nil || string(data) != "deep content" {
+		t.Errorf("content = %q, %v", data, err)
+	}
+}
+
 func TestWriteFile_OverwritesExisting(t *testing.T) {
 	r := &SubagentResource{}
 	dir := t.TempDir()
@@ -414,6 +491,73 @@ func TestWriteFile_OverwritesExisting(t *testing.T) {
 	}
 }
 
+// --------------------------------------------------------------------------
+// Regeneration tests
+// --------------------------------------------------------------------------
+
+func regenerateModel(t *testing.T) *SubagentResourceModel {
+	t.Helper()
+	outputDir := t.TempDir()
+	return &SubagentResourceModel{
+		Name:            stringValue("code-reviewer"),
+		Description:     stringValue("Reviews code for quality"),
+		Prompt:          stringValue("You are a code reviewer."),
+		OutputDir:       stringValue(outputDir),
+		Tools:           types.ListNull(types.StringType),
+		DisallowedTools: types.ListNull(types.StringType),
+		Skills:          types.ListNull(types.StringType),
+		FilePath:        stringValue(filepath.Join(outputDir, "code-reviewer.md")),
+		CacheDir:        stringValue(t.TempDir()),
+	}
+}
+
+func TestRegenerate_FromCache(t *testing.T) {
+	model := regenerateModel(t)
+
+	// The cached file was written by an earlier apply; it differs from what
+	// the arguments render to, so the test can tell where it came from.
+	cached := "---\nname: code-reviewer\n---\n\nCached.\n"
+	if diags := cacheContent(model, cached); diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags.Errors())
+	}
+	model.ContentHash = stringValue(computeHash(cached))
+
+	data, diags := (&SubagentResource{}).regenerate(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags.Errors())
+	}
+	if string(data) != cached {
+		t.Errorf("regenerated content = %q, want the cached content", data)
+	}
+	disk, err := os.ReadFile(model.FilePath.ValueString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(disk) != cached {
+		t.Errorf("file on disk = %q, want the cached content", disk)
+	}
+}
+
+func TestRegenerate_RendersOnCacheMiss(t *testing.T) {
+	model := regenerateModel(t)
+	model.ContentHash = stringValue(computeHash("not cached"))
+
+	data, diags := (&SubagentResource{}).regenerate(context.Background(), model)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %s", diags.Errors())
+	}
+	assertContains(t, string(data), "You are a code reviewer.\n")
+
+	// The rendered content is cached for the next clean workspace.
+	if _, err := os.Stat(model.FilePath.ValueString()); err != nil {
+		t.Errorf("file not written: %s", err)
+	}
+	hexHash := strings.TrimPrefix(computeHash(string(data)), "sha256:")
+	if _, err := os.Stat(filepath.Join(model.CacheDir.ValueString(), "sha256", hexHash[:2], hexHash)); err != nil {
+		t.Errorf("rendered content not cached: %s", err)
+	}
+}
+
 // --------------------------------------------------------------------------
 // Name pattern validation tests
 // --------------------------------------------------------------------------
@@ -442,12 +586,12 @@ func TestNamePattern(t *testing.T) {
 	}
 
 	for _, name := range valid {
-		if !namePattern.MatchString(name) {
+		if validators.CheckName(name) != nil {
 			t.Errorf("expected %q to be valid", name)
 		}
 	}
 	for _, name := range invalid {
-		if namePattern.MatchString(name) {
+		if validators.CheckName(name) == nil {
 			t.Errorf("expected %q to be invalid", name)
 		}
 	}
@@ -5,10 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
@@ -25,17 +25,22 @@ type azureTarget struct {
 	name            string
 }
 
-// newAzureTarget constructs an Azure Blob Storage-backed Target.
+// newAzureTarget constructs an Azure Blob Storage-backed Target. The blob
+// client is shared with other targets on the same account that authenticate
+// the same way.
 func newAzureTarget(cfg Config) (Target, error) {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	sasToken, err := azureSASToken(cfg.AzureSASToken)
 	if err != nil {
-		return nil, fmt.Errorf("creating Azure credential: %w", err)
+		return nil, err
+	}
+	if sasToken != "" && cfg.AzureClientID != "" {
+		return nil, errors.New("a SAS token and a managed identity client ID cannot both be set")
 	}
 
 	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net", cfg.StorageAccount)
-	client, err := azblob.NewClient(serviceURL, cred, nil)
+	client, err := sharedClients.azureClient(serviceURL, sasToken, cfg.AzureClientID)
 	if err != nil {
-		return nil, fmt.Errorf("creating Azure blob client: %w", err)
+		return nil, err
 	}
 
 	prefix := cfg.Prefix
@@ -52,6 +57,25 @@ func newAzureTarget(cfg Config) (Target, error) {
 	}, nil
 }
 
+// azureSASToken normalizes a shared access signature: surrounding
+// whitespace and a leading "?" are removed. It returns an empty token for an
+// empty value and an error when the value is not a query string with a
+// signature.
+func azureSASToken(token string) (string, error) {
+	token = strings.TrimPrefix(strings.TrimSpace(token), "?")
+	if token == "" {
+		return "", nil
+	}
+	values, err := url.ParseQuery(token)
+	if err != nil {
+		return "", fmt.Errorf("SAS token is not a valid query string: %w", err)
+	}
+	if values.Get("sig") == "" {
+		return "", errors.New(`SAS token has no "sig" parameter; pass the query string of a SAS URL, such as "sv=...&sp=racwdl&sig=..."`)
+	}
+	return token, nil
+}
+
 func (t *azureTarget) Name() string {
 	return t.name
 }
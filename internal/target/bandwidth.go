@@ -0,0 +1,105 @@
+package target
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// BandwidthLimitedTarget wraps another Target and throttles the bodies of
+// uploads with a shared rate.Limiter counting bytes. A single limiter is
+// shared by all targets of a provider, so concurrent uploads to any number
+// of targets together stay under the configured bytes per second. Reads and
+// metadata requests are not throttled.
+type BandwidthLimitedTarget struct {
+	inner   Target
+	limiter *rate.Limiter
+}
+
+// NewBandwidthLimitedTarget creates a Target whose uploads read their body
+// at most at limiter's rate in bytes per second. The limiter's burst bounds
+// the size of each read from the body, so it should be well above zero.
+func NewBandwidthLimitedTarget(inner Target, limiter *rate.Limiter) Target {
+	return &BandwidthLimitedTarget{
+		inner:   inner,
+		limiter: limiter,
+	}
+}
+
+// NewBandwidthLimiter returns a limiter for NewBandwidthLimitedTarget that
+// allows bytesPerSecond, with a burst of at most maxBandwidthBurst bytes so
+// a slow link is not flooded at the start of each upload.
+func NewBandwidthLimiter(bytesPerSecond int64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), int(max(1, min(bytesPerSecond, maxBandwidthBurst))))
+}
+
+// maxBandwidthBurst caps the burst of limiters from NewBandwidthLimiter.
+const maxBandwidthBurst = 256 << 10
+
+func (b *BandwidthLimitedTarget) Name() string {
+	return b.inner.Name()
+}
+
+func (b *BandwidthLimitedTarget) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	return b.inner.Put(ctx, key, b.throttle(ctx, body), opts)
+}
+
+func (b *BandwidthLimitedTarget) Get(ctx context.Context, key string) (io.ReadCloser, ObjectMeta, error) {
+	return b.inner.Get(ctx, key)
+}
+
+func (b *BandwidthLimitedTarget) Head(ctx context.Context, key string) (ObjectMeta, error) {
+	return b.inner.Head(ctx, key)
+}
+
+func (b *BandwidthLimitedTarget) Delete(ctx context.Context, key string) error {
+	return b.inner.Delete(ctx, key)
+}
+
+func (b *BandwidthLimitedTarget) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	return b.inner.List(ctx, prefix)
+}
+
+func (b *BandwidthLimitedTarget) ConditionalPut(ctx context.Context, key string, body io.Reader, condition WriteCondition, opts PutOptions) error {
+	return b.inner.ConditionalPut(ctx, key, b.throttle(ctx, body), condition, opts)
+}
+
+// throttle wraps body in a reader that waits on the limiter for every byte
+// it returns. Seekable bodies stay seekable, since the S3 SDK seeks to
+// determine the content length.
+func (b *BandwidthLimitedTarget) throttle(ctx context.Context, body io.Reader) io.Reader {
+	r := &throttledReader{ctx: ctx, r: body, limiter: b.limiter}
+	if s, ok := body.(io.Seeker); ok {
+		return &throttledReadSeeker{throttledReader: r, s: s}
+	}
+	return r
+}
+
+type throttledReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if burst := t.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if waitErr := t.limiter.WaitN(t.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+type throttledReadSeeker struct {
+	*throttledReader
+	s io.Seeker
+}
+
+func (t *throttledReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return t.s.Seek(offset, whence)
+}
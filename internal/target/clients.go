@@ -0,0 +1,175 @@
+package target
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	gcsstorage "cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"google.golang.org/api/option"
+)
+
+// clientCache holds SDK clients shared by every target in the provider
+// process. SDK clients are safe for concurrent use and carry their own
+// connection pools and credential caches (including STS session
+// credentials), so targets that point at the same region, project, or
+// storage account reuse one client instead of each resolving credentials
+// and opening connections of their own. The cache also survives repeated
+// provider Configure calls within the same plugin process.
+type clientCache struct {
+	mu        sync.Mutex
+	s3        map[s3ClientKey]*s3.Client
+	azure     map[azureClientKey]*azblob.Client
+	azureCred map[string]azcore.TokenCredential // managed identity client ID -> credential
+	gcs       map[string]*gcsstorage.Client     // credentials hash -> client
+}
+
+// azureClientKey identifies an Azure blob client: its service URL and how
+// it authenticates. At most one of sasHash and clientID is set; with
+// neither, the client uses DefaultAzureCredential.
+type azureClientKey struct {
+	serviceURL string
+	sasHash    string
+	clientID   string
+}
+
+// s3ClientKey identifies an S3 client: its region, whether it uses FIPS
+// endpoints, and the S3-compatible endpoint it reaches instead of AWS.
+type s3ClientKey struct {
+	region   string
+	fips     bool
+	endpoint s3Endpoint
+}
+
+// sharedClients is the process-wide client cache used by the target
+// constructors.
+var sharedClients = &clientCache{
+	s3:        make(map[s3ClientKey]*s3.Client),
+	azure:     make(map[azureClientKey]*azblob.Client),
+	azureCred: make(map[string]azcore.TokenCredential),
+	gcs:       make(map[string]*gcsstorage.Client),
+}
+
+// s3Client returns the S3 client for region, creating it on first use. An
+// empty region resolves the region from the default AWS configuration. With
+// fips set the client uses the region's FIPS endpoints; with endpoint set it
+// reaches that S3-compatible service instead and signs for region.
+func (c *clientCache) s3Client(ctx context.Context, region string, fips bool, endpoint s3Endpoint) (*s3.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := s3ClientKey{region: region, fips: fips, endpoint: endpoint}
+	if client, ok := c.s3[key]; ok {
+		return client, nil
+	}
+
+	var optFns []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		optFns = append(optFns, awsconfig.WithRegion(region))
+	}
+	if fips {
+		optFns = append(optFns, awsconfig.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint.url != "" {
+			o.BaseEndpoint = aws.String(endpoint.url)
+			o.UsePathStyle = endpoint.pathStyle
+		}
+	})
+	c.s3[key] = client
+	return client, nil
+}
+
+// azureClient returns the blob client for serviceURL, creating it on first
+// use. With sasToken set the client authenticates with the SAS token; with
+// clientID set, with that user-assigned managed identity; otherwise with the
+// shared DefaultAzureCredential. Token credentials are created once and
+// shared by every storage account they reach.
+func (c *clientCache) azureClient(serviceURL, sasToken, clientID string) (*azblob.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Keyed by a hash so the cache does not hold a second copy of the token.
+	key := azureClientKey{serviceURL: serviceURL, clientID: clientID}
+	if sasToken != "" {
+		key.sasHash = fmt.Sprintf("%x", sha256.Sum256([]byte(sasToken)))
+	}
+	if client, ok := c.azure[key]; ok {
+		return client, nil
+	}
+
+	if sasToken != "" {
+		client, err := azblob.NewClientWithNoCredential(serviceURL+"?"+sasToken, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating Azure blob client: %w", err)
+		}
+		c.azure[key] = client
+		return client, nil
+	}
+
+	cred, ok := c.azureCred[clientID]
+	if !ok {
+		var err error
+		if clientID != "" {
+			cred, err = azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+				ID: azidentity.ClientID(clientID),
+			})
+		} else {
+			cred, err = azidentity.NewDefaultAzureCredential(nil)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("creating Azure credential: %w", err)
+		}
+		c.azureCred[clientID] = cred
+	}
+
+	client, err := azblob.NewClient(serviceURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating Azure blob client: %w", err)
+	}
+
+	c.azure[key] = client
+	return client, nil
+}
+
+// gcsClient returns the GCS client for credentialsJSON, creating it on
+// first use. A single client serves every bucket reached with the same
+// credentials; empty credentials use Application Default Credentials.
+func (c *clientCache) gcsClient(ctx context.Context, credentialsJSON []byte) (*gcsstorage.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Keyed by a hash so the cache does not hold a second copy of the key.
+	var key string
+	if len(credentialsJSON) > 0 {
+		key = fmt.Sprintf("%x", sha256.Sum256(credentialsJSON))
+	}
+	if client, ok := c.gcs[key]; ok {
+		return client, nil
+	}
+
+	var opts []option.ClientOption
+	if len(credentialsJSON) > 0 {
+		opts = append(opts, option.WithCredentialsJSON(credentialsJSON))
+	}
+	client, err := gcsstorage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCS client: %w", err)
+	}
+
+	c.gcs[key] = client
+	return client, nil
+}
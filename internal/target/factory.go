@@ -3,8 +3,13 @@ package target
 import "fmt"
 
 // NewTarget creates a Target based on the provided Config.
-// It dispatches to the appropriate backend constructor (S3, Azure, or GCS)
-// and wraps the result in a RetryTarget if MaxRetries > 0.
+// It dispatches to the appropriate backend constructor (S3, Azure, or GCS;
+// the r2 and spaces types are S3 targets with preset endpoints)
+// and wraps the result in a BandwidthLimitedTarget if an UploadLimiter is
+// set, a RateLimitedTarget if a Limiter is set, and a RetryTarget if
+// MaxRetries > 0, so every retry attempt is rate limited too. A ReadOnly
+// target is wrapped in a ReadOnlyTarget last, so rejected writes are not
+// retried.
 func NewTarget(cfg Config) (Target, error) {
 	var (
 		t   Target
@@ -12,22 +17,32 @@ func NewTarget(cfg Config) (Target, error) {
 	)
 
 	switch cfg.Type {
-	case "s3":
+	case "s3", "r2", "spaces":
 		t, err = newS3Target(cfg)
 	case "azure":
 		t, err = newAzureTarget(cfg)
 	case "gcs":
 		t, err = newGCSTarget(cfg)
 	case "memory":
-		return GetOrCreateMemoryTarget(cfg.Name), nil
+		// Memory targets get the same wrappers as real backends, so faults
+		// injected with MemoryTarget.SetFaults go through RetryTarget.
+		t = GetOrCreateMemoryTarget(cfg.Name)
 	default:
-		return nil, fmt.Errorf("unsupported target type: %q (must be s3, azure, gcs, or memory)", cfg.Type)
+		return nil, fmt.Errorf("unsupported target type: %q (must be s3, r2, spaces, azure, gcs, or memory)", cfg.Type)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("creating %s target %q: %w", cfg.Type, cfg.Name, err)
 	}
 
+	if cfg.UploadLimiter != nil {
+		t = NewBandwidthLimitedTarget(t, cfg.UploadLimiter)
+	}
+
+	if cfg.Limiter != nil {
+		t = NewRateLimitedTarget(t, cfg.Limiter)
+	}
+
 	if cfg.MaxRetries > 0 {
 		backoff := cfg.RetryBackoff
 		if backoff == "" {
@@ -36,5 +51,9 @@ func NewTarget(cfg Config) (Target, error) {
 		t = NewRetryTarget(t, cfg.MaxRetries, backoff)
 	}
 
+	if cfg.ReadOnly {
+		t = NewReadOnlyTarget(t)
+	}
+
 	return t, nil
 }
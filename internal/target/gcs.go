@@ -2,9 +2,11 @@ package target
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	gcsstorage "cloud.google.com/go/storage"
@@ -20,13 +22,20 @@ type gcsTarget struct {
 	name       string
 }
 
-// newGCSTarget constructs a GCS-backed Target using Application Default Credentials.
+// newGCSTarget constructs a GCS-backed Target using cfg.GCSCredentials, or
+// Application Default Credentials when it is empty. The GCS client is shared
+// with other GCS targets using the same credentials.
 func newGCSTarget(cfg Config) (Target, error) {
 	ctx := context.Background()
 
-	client, err := gcsstorage.NewClient(ctx)
+	credentialsJSON, err := gcsCredentials(cfg.GCSCredentials)
 	if err != nil {
-		return nil, fmt.Errorf("creating GCS client: %w", err)
+		return nil, err
+	}
+
+	client, err := sharedClients.gcsClient(ctx, credentialsJSON)
+	if err != nil {
+		return nil, err
 	}
 
 	prefix := cfg.Prefix
@@ -43,6 +52,38 @@ func newGCSTarget(cfg Config) (Target, error) {
 	}, nil
 }
 
+// gcsCredentials returns the credentials JSON of credentials: the value
+// itself when it is a JSON object, or else the content of the file it
+// names. It returns nil for empty credentials. The JSON must carry the
+// "type" field Google credentials files have, such as "service_account",
+// so that a mistyped path or a truncated key fails here rather than on the
+// first request.
+func gcsCredentials(credentials string) ([]byte, error) {
+	credentials = strings.TrimSpace(credentials)
+	if credentials == "" {
+		return nil, nil
+	}
+
+	data := []byte(credentials)
+	if !strings.HasPrefix(credentials, "{") {
+		var err error
+		if data, err = os.ReadFile(credentials); err != nil {
+			return nil, fmt.Errorf("reading credentials file: %w", err)
+		}
+	}
+
+	var key struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("credentials are not a valid JSON key: %w", err)
+	}
+	if key.Type == "" {
+		return nil, errors.New(`credentials JSON has no "type" field; expected a key such as a service account key`)
+	}
+	return data, nil
+}
+
 func (t *gcsTarget) Name() string {
 	return t.name
 }
@@ -21,11 +21,15 @@ type memoryObject struct {
 }
 
 // MemoryTarget is an in-memory implementation of Target, intended for testing.
+// SetFaults makes it inject errors, latency, and write conflicts.
 type MemoryTarget struct {
 	name       string
 	mu         sync.RWMutex
 	objects    map[string]*memoryObject
 	genCounter atomic.Int64
+
+	faultMu sync.Mutex
+	faults  memoryFaults
 }
 
 // NewMemoryTarget creates a new in-memory Target with the given name.
@@ -40,7 +44,11 @@ func (m *MemoryTarget) Name() string {
 	return m.name
 }
 
-func (m *MemoryTarget) Put(_ context.Context, key string, body io.Reader, opts PutOptions) error {
+func (m *MemoryTarget) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	if err := m.injectFault(ctx, memoryOpPut, key); err != nil {
+		return err
+	}
+
 	data, err := io.ReadAll(body)
 	if err != nil {
 		return fmt.Errorf("reading body: %w", err)
@@ -67,7 +75,11 @@ func (m *MemoryTarget) Put(_ context.Context, key string, body io.Reader, opts P
 	return nil
 }
 
-func (m *MemoryTarget) Get(_ context.Context, key string) (io.ReadCloser, ObjectMeta, error) {
+func (m *MemoryTarget) Get(ctx context.Context, key string) (io.ReadCloser, ObjectMeta, error) {
+	if err := m.injectFault(ctx, memoryOpRead, key); err != nil {
+		return nil, ObjectMeta{}, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -88,7 +100,11 @@ func (m *MemoryTarget) Get(_ context.Context, key string) (io.ReadCloser, Object
 	return io.NopCloser(bytes.NewReader(buf)), meta, nil
 }
 
-func (m *MemoryTarget) Head(_ context.Context, key string) (ObjectMeta, error) {
+func (m *MemoryTarget) Head(ctx context.Context, key string) (ObjectMeta, error) {
+	if err := m.injectFault(ctx, memoryOpRead, key); err != nil {
+		return ObjectMeta{}, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -104,7 +120,29 @@ func (m *MemoryTarget) Head(_ context.Context, key string) (ObjectMeta, error) {
 	}, nil
 }
 
-func (m *MemoryTarget) Delete(_ context.Context, key string) error {
+// Metadata returns a copy of the metadata the object at key was written
+// with. It lets tests check PutOptions.Metadata, which Target has no way to
+// read back.
+func (m *MemoryTarget) Metadata(key string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	obj, ok := m.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	md := make(map[string]string, len(obj.metadata))
+	for k, v := range obj.metadata {
+		md[k] = v
+	}
+	return md, nil
+}
+
+func (m *MemoryTarget) Delete(ctx context.Context, key string) error {
+	if err := m.injectFault(ctx, memoryOpDelete, key); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -112,7 +150,11 @@ func (m *MemoryTarget) Delete(_ context.Context, key string) error {
 	return nil
 }
 
-func (m *MemoryTarget) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+func (m *MemoryTarget) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if err := m.injectFault(ctx, memoryOpRead, prefix); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -135,7 +177,11 @@ func (m *MemoryTarget) List(_ context.Context, prefix string) ([]ObjectInfo, err
 	return results, nil
 }
 
-func (m *MemoryTarget) ConditionalPut(_ context.Context, key string, body io.Reader, condition WriteCondition, opts PutOptions) error {
+func (m *MemoryTarget) ConditionalPut(ctx context.Context, key string, body io.Reader, condition WriteCondition, opts PutOptions) error {
+	if err := m.injectFault(ctx, memoryOpConditionalPut, key); err != nil {
+		return err
+	}
+
 	data, err := io.ReadAll(body)
 	if err != nil {
 		return fmt.Errorf("reading body: %w", err)
@@ -0,0 +1,113 @@
+package target
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// ErrInjectedFault is returned by a MemoryTarget for faults injected through
+// FaultConfig.ErrorRate and FaultConfig.FailOnNthPut. It is transient, so a
+// RetryTarget retries it like a network error.
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultConfig describes the faults a MemoryTarget injects, so tests can
+// exercise the engine's retry and rollback paths deterministically. The zero
+// value injects nothing.
+type FaultConfig struct {
+	// ErrorRate is the probability, from 0 to 1, that any operation fails
+	// with ErrInjectedFault before touching the store.
+	ErrorRate float64
+	// Seed seeds the generator behind ErrorRate, so a failing run can be
+	// replayed exactly.
+	Seed int64
+	// Latency is added before every operation. It respects context
+	// cancellation.
+	Latency time.Duration
+	// FailOnNthPut makes the nth write (Put or ConditionalPut, counted from
+	// 1 since SetFaults) fail with ErrInjectedFault. 0 disables it.
+	FailOnNthPut int
+	// ConditionalPutConflicts makes the next n ConditionalPut calls fail
+	// with ErrPreconditionFailed, as if another process had won the write.
+	ConditionalPutConflicts int
+}
+
+// memoryFaults is the fault injection state of a MemoryTarget. It is
+// guarded by MemoryTarget.faultMu.
+type memoryFaults struct {
+	cfg       FaultConfig
+	rng       *rand.Rand
+	puts      int
+	conflicts int
+	injected  int
+}
+
+// memoryOp identifies the operation a fault is injected into.
+type memoryOp int
+
+const (
+	memoryOpRead memoryOp = iota
+	memoryOpPut
+	memoryOpConditionalPut
+	memoryOpDelete
+)
+
+// SetFaults replaces the target's fault configuration and resets its
+// counters. Pass FaultConfig{} to stop injecting faults.
+func (m *MemoryTarget) SetFaults(cfg FaultConfig) {
+	m.faultMu.Lock()
+	defer m.faultMu.Unlock()
+
+	m.faults = memoryFaults{
+		cfg:       cfg,
+		rng:       rand.New(rand.NewSource(cfg.Seed)),
+		conflicts: cfg.ConditionalPutConflicts,
+	}
+}
+
+// InjectedFaults returns the number of faults injected since the last
+// SetFaults call, latency excluded.
+func (m *MemoryTarget) InjectedFaults() int {
+	m.faultMu.Lock()
+	defer m.faultMu.Unlock()
+
+	return m.faults.injected
+}
+
+// injectFault applies the configured latency and returns the fault, if any,
+// that op should fail with.
+func (m *MemoryTarget) injectFault(ctx context.Context, op memoryOp, key string) error {
+	m.faultMu.Lock()
+	f := &m.faults
+	latency := f.cfg.Latency
+
+	var err error
+	if op == memoryOpPut || op == memoryOpConditionalPut {
+		f.puts++
+		if f.cfg.FailOnNthPut > 0 && f.puts == f.cfg.FailOnNthPut {
+			err = fmt.Errorf("put %q (write %d): %w", key, f.puts, ErrInjectedFault)
+		}
+	}
+	if err == nil && op == memoryOpConditionalPut && f.conflicts > 0 {
+		f.conflicts--
+		err = ErrPreconditionFailed
+	}
+	if err == nil && f.cfg.ErrorRate > 0 && f.rng.Float64() < f.cfg.ErrorRate {
+		err = fmt.Errorf("%q: %w", key, ErrInjectedFault)
+	}
+	if err != nil {
+		f.injected++
+	}
+	m.faultMu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(latency):
+		}
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package target
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// probePrefix is the key prefix under which Probe writes its test object.
+// It sits next to the per-skill directories and never collides with a skill
+// name because skill names cannot start with a dot.
+const probePrefix = ".agentctx-probe/"
+
+// Probe verifies that the credentials behind t can put, get, list, and
+// delete objects under the target's prefix. It writes a small uniquely
+// named object, reads it back, lists it, and deletes it again.
+//
+// Every operation that fails is reported in the returned error, joined with
+// errors.Join, so a single call shows all missing permissions. If the put
+// itself fails the remaining operations are skipped. The probe object is
+// always deleted when it was written.
+func Probe(ctx context.Context, t Target) error {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Errorf("probe: generate key: %w", err)
+	}
+	key := probePrefix + hex.EncodeToString(b)
+	body := []byte("agentctx credential probe\n")
+
+	if err := t.Put(ctx, key, bytes.NewReader(body), PutOptions{ContentType: "text/plain"}); err != nil {
+		return fmt.Errorf("put: %w", err)
+	}
+
+	var errs []error
+
+	rc, _, err := t.Get(ctx, key)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("get: %w", err))
+	} else {
+		got, readErr := io.ReadAll(rc)
+		rc.Close()
+		if readErr != nil {
+			errs = append(errs, fmt.Errorf("get: read body: %w", readErr))
+		} else if !bytes.Equal(got, body) {
+			errs = append(errs, fmt.Errorf("get: probe object content does not match what was written"))
+		}
+	}
+
+	objects, err := t.List(ctx, probePrefix)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("list: %w", err))
+	} else {
+		found := false
+		for _, obj := range objects {
+			if obj.Key == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("list: probe object %q not returned when listing %q", key, probePrefix))
+		}
+	}
+
+	if err := t.Delete(ctx, key); err != nil {
+		errs = append(errs, fmt.Errorf("delete: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ProbeReadOnly verifies that the credentials behind t can list objects
+// under the target's prefix. It is used for replica targets, which the
+// provider only reads from and which may deny writes.
+func ProbeReadOnly(ctx context.Context, t Target) error {
+	if _, err := t.List(ctx, probePrefix); err != nil {
+		return fmt.Errorf("list: %w", err)
+	}
+	return nil
+}
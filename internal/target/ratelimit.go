@@ -0,0 +1,72 @@
+package target
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitedTarget wraps another Target and waits on a shared rate.Limiter
+// before every storage request. A single limiter is shared by all targets of
+// a provider so the combined request rate stays under the configured QPS,
+// no matter how many targets or skills an apply touches.
+type RateLimitedTarget struct {
+	inner   Target
+	limiter *rate.Limiter
+}
+
+// NewRateLimitedTarget creates a Target that issues at most limiter's rate of
+// requests. Retries performed by an outer RetryTarget are limited as well.
+func NewRateLimitedTarget(inner Target, limiter *rate.Limiter) Target {
+	return &RateLimitedTarget{
+		inner:   inner,
+		limiter: limiter,
+	}
+}
+
+func (r *RateLimitedTarget) Name() string {
+	return r.inner.Name()
+}
+
+func (r *RateLimitedTarget) Put(ctx context.Context, key string, body io.Reader, opts PutOptions) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return r.inner.Put(ctx, key, body, opts)
+}
+
+func (r *RateLimitedTarget) Get(ctx context.Context, key string) (io.ReadCloser, ObjectMeta, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, ObjectMeta{}, err
+	}
+	return r.inner.Get(ctx, key)
+}
+
+func (r *RateLimitedTarget) Head(ctx context.Context, key string) (ObjectMeta, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return ObjectMeta{}, err
+	}
+	return r.inner.Head(ctx, key)
+}
+
+func (r *RateLimitedTarget) Delete(ctx context.Context, key string) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return r.inner.Delete(ctx, key)
+}
+
+func (r *RateLimitedTarget) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.inner.List(ctx, prefix)
+}
+
+func (r *RateLimitedTarget) ConditionalPut(ctx context.Context, key string, body io.Reader, condition WriteCondition, opts PutOptions) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return r.inner.ConditionalPut(ctx, key, body, condition, opts)
+}
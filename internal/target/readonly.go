@@ -0,0 +1,52 @@
+package target
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrReadOnly is returned by a ReadOnlyTarget for every write.
+var ErrReadOnly = errors.New("target is read-only")
+
+// ReadOnlyTarget wraps another Target and rejects Put, ConditionalPut, and
+// Delete with ErrReadOnly without calling it. It backs the provider's
+// read_only mode, so that no code path can write even if a resource
+// operation is not guarded.
+type ReadOnlyTarget struct {
+	inner Target
+}
+
+// NewReadOnlyTarget creates a Target that only reads from inner.
+func NewReadOnlyTarget(inner Target) Target {
+	return &ReadOnlyTarget{inner: inner}
+}
+
+func (r *ReadOnlyTarget) Name() string {
+	return r.inner.Name()
+}
+
+func (r *ReadOnlyTarget) Put(_ context.Context, key string, _ io.Reader, _ PutOptions) error {
+	return fmt.Errorf("put %q: %w", key, ErrReadOnly)
+}
+
+func (r *ReadOnlyTarget) Get(ctx context.Context, key string) (io.ReadCloser, ObjectMeta, error) {
+	return r.inner.Get(ctx, key)
+}
+
+func (r *ReadOnlyTarget) Head(ctx context.Context, key string) (ObjectMeta, error) {
+	return r.inner.Head(ctx, key)
+}
+
+func (r *ReadOnlyTarget) Delete(_ context.Context, key string) error {
+	return fmt.Errorf("delete %q: %w", key, ErrReadOnly)
+}
+
+func (r *ReadOnlyTarget) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	return r.inner.List(ctx, prefix)
+}
+
+func (r *ReadOnlyTarget) ConditionalPut(_ context.Context, key string, _ io.Reader, _ WriteCondition, _ PutOptions) error {
+	return fmt.Errorf("conditional put %q: %w", key, ErrReadOnly)
+}
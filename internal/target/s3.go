@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
@@ -22,22 +21,27 @@ type s3Target struct {
 	name     string
 }
 
-// newS3Target constructs an S3-backed Target from the provided Config.
+// newS3Target constructs an S3-backed Target from the provided Config. The
+// S3 client is shared with other targets in the same region. Target types
+// other than s3 name an S3-compatible service whose endpoint and signing
+// region are resolved by s3Preset.
 func newS3Target(cfg Config) (Target, error) {
 	ctx := context.Background()
 
-	var optFns []func(*awsconfig.LoadOptions) error
-	if cfg.Region != "" {
-		optFns = append(optFns, awsconfig.WithRegion(cfg.Region))
+	region, fips, endpoint := cfg.Region, cfg.FIPS, s3Endpoint{}
+	if cfg.Type != "s3" {
+		var err error
+		if region, endpoint, err = s3Preset(cfg); err != nil {
+			return nil, err
+		}
+		fips = false
 	}
 
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
+	client, err := sharedClients.s3Client(ctx, region, fips, endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("loading AWS config: %w", err)
+		return nil, err
 	}
 
-	client := s3.NewFromConfig(awsCfg)
-
 	prefix := cfg.Prefix
 	if prefix != "" && !strings.HasSuffix(prefix, "/") {
 		prefix += "/"
@@ -0,0 +1,62 @@
+package target
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// s3Endpoint describes how an S3 client reaches an S3-compatible service
+// other than AWS. The zero value uses the AWS endpoints of the region.
+type s3Endpoint struct {
+	// url is the base endpoint the client sends requests to.
+	url string
+	// pathStyle addresses buckets as <url>/<bucket> instead of
+	// <bucket>.<host>.
+	pathStyle bool
+}
+
+// r2AccountIDPattern matches a Cloudflare account ID.
+var r2AccountIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// spacesRegionPattern matches a DigitalOcean Spaces datacenter, e.g. nyc3.
+var spacesRegionPattern = regexp.MustCompile(`^[a-z]{3}[0-9]$`)
+
+// s3Preset resolves the region the client signs requests for and the
+// endpoint of an S3-compatible target type:
+//
+//   - r2 reaches https://<account_id>.r2.cloudflarestorage.com with
+//     path-style addressing, signing for the "auto" region R2 expects.
+//   - spaces reaches https://<region>.digitaloceanspaces.com with
+//     virtual-hosted addressing. Spaces accepts signatures for us-east-1
+//     only, whatever datacenter the bucket is in.
+//
+// Neither service has KMS or FIPS endpoints, so cfg.KMSKeyID is rejected
+// and cfg.FIPS does not apply.
+func s3Preset(cfg Config) (region string, endpoint s3Endpoint, err error) {
+	if cfg.KMSKeyID != "" {
+		return "", s3Endpoint{}, fmt.Errorf("kms_key_id is not supported by %s targets; objects are encrypted at rest by the service", cfg.Type)
+	}
+
+	switch cfg.Type {
+	case "r2":
+		if !r2AccountIDPattern.MatchString(cfg.AccountID) {
+			return "", s3Endpoint{}, fmt.Errorf("r2 targets require account_id, the 32-character hex Cloudflare account ID (got %q)", cfg.AccountID)
+		}
+		if cfg.Region != "" && cfg.Region != "auto" {
+			return "", s3Endpoint{}, fmt.Errorf("r2 targets do not take a region (got %q); R2 places buckets itself", cfg.Region)
+		}
+		return "auto", s3Endpoint{
+			url:       "https://" + cfg.AccountID + ".r2.cloudflarestorage.com",
+			pathStyle: true,
+		}, nil
+	case "spaces":
+		if !spacesRegionPattern.MatchString(cfg.Region) {
+			return "", s3Endpoint{}, fmt.Errorf("spaces targets require region, the datacenter of the Space such as nyc3 (got %q)", cfg.Region)
+		}
+		return "us-east-1", s3Endpoint{
+			url: "https://" + cfg.Region + ".digitaloceanspaces.com",
+		}, nil
+	default:
+		return "", s3Endpoint{}, fmt.Errorf("no S3 preset for target type %q", cfg.Type)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"io"
+
+	"golang.org/x/time/rate"
 )
 
 // Sentinel errors for target operations.
@@ -73,7 +75,7 @@ type Target interface {
 // Config holds the configuration used by NewTarget to construct a Target.
 type Config struct {
 	Name            string
-	Type            string // "s3", "azure", "gcs"
+	Type            string // "s3", "r2", "spaces", "azure", "gcs"
 	Bucket          string
 	Region          string
 	Prefix          string
@@ -86,4 +88,34 @@ type Config struct {
 	MaxRetries      int
 	TimeoutSeconds  int
 	RetryBackoff    string // "exponential" | "linear"
+
+	// Limiter, when non-nil, bounds the request rate of the target. Pass
+	// the same limiter to every target to enforce a provider-wide QPS.
+	Limiter *rate.Limiter
+	// UploadLimiter, when non-nil, bounds the upload bandwidth of the
+	// target in bytes per second. Pass the same limiter to every target to
+	// enforce a provider-wide bandwidth. See NewBandwidthLimiter.
+	UploadLimiter *rate.Limiter
+	// ReadOnly wraps the target in a ReadOnlyTarget, so writes fail with
+	// ErrReadOnly before they reach the backend.
+	ReadOnly bool
+	// FIPS makes S3 targets use the FIPS endpoints of their region. Other
+	// backends, including r2 and spaces, have no separate FIPS endpoints.
+	FIPS bool
+	// AccountID is the Cloudflare account ID of an r2 target, which names
+	// its endpoint.
+	AccountID string
+	// GCSCredentials authenticates gcs targets: a Google Cloud credentials
+	// JSON key, such as a service account key, or the path of a key file.
+	// Empty uses Application Default Credentials.
+	GCSCredentials string
+	// AzureSASToken authenticates azure targets with a shared access
+	// signature: the query string of a SAS URL, with or without the
+	// leading "?". It cannot be combined with AzureClientID.
+	AzureSASToken string
+	// AzureClientID authenticates azure targets with the user-assigned
+	// managed identity that has this client ID. With neither it nor
+	// AzureSASToken set, azure targets use DefaultAzureCredential, which
+	// includes a system-assigned managed identity.
+	AzureClientID string
 }
@@ -6,9 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"golang.org/x/time/rate"
 )
 
 // ---------------------------------------------------------------------------
@@ -350,6 +356,84 @@ func (f *faultyTarget) getCallCount() int {
 	return f.callCount
 }
 
+func TestMemoryTarget_FailOnNthPut(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryTarget("test")
+	mem.SetFaults(FaultConfig{FailOnNthPut: 2})
+
+	if err := mem.Put(ctx, "a", strings.NewReader("1"), PutOptions{}); err != nil {
+		t.Fatalf("first Put: %v", err)
+	}
+	if err := mem.Put(ctx, "b", strings.NewReader("2"), PutOptions{}); !errors.Is(err, ErrInjectedFault) {
+		t.Fatalf("second Put: expected ErrInjectedFault, got %v", err)
+	}
+	if _, err := mem.Head(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("failed Put must not store the object, Head returned %v", err)
+	}
+	if err := mem.Put(ctx, "b", strings.NewReader("2"), PutOptions{}); err != nil {
+		t.Fatalf("third Put: %v", err)
+	}
+	if got := mem.InjectedFaults(); got != 1 {
+		t.Errorf("InjectedFaults = %d, want 1", got)
+	}
+}
+
+func TestMemoryTarget_ConditionalPutConflicts(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryTarget("test")
+	mem.SetFaults(FaultConfig{ConditionalPutConflicts: 1})
+
+	err := mem.ConditionalPut(ctx, "ACTIVE", strings.NewReader("x"), WriteCondition{IfMatch: "*"}, PutOptions{})
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if err := mem.ConditionalPut(ctx, "ACTIVE", strings.NewReader("x"), WriteCondition{IfMatch: "*"}, PutOptions{}); err != nil {
+		t.Fatalf("second ConditionalPut: %v", err)
+	}
+}
+
+func TestMemoryTarget_ErrorRateIsSeeded(t *testing.T) {
+	run := func() []bool {
+		mem := NewMemoryTarget("test")
+		mem.SetFaults(FaultConfig{ErrorRate: 0.5, Seed: 42})
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			_, err := mem.List(context.Background(), "")
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+	a, b := run(), run()
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("same seed produced different faults:\n%v\n%v", a, b)
+	}
+}
+
+func TestMemoryTarget_LatencyHonorsCancellation(t *testing.T) {
+	mem := NewMemoryTarget("test")
+	mem.SetFaults(FaultConfig{Latency: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := mem.Get(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRetryTarget_RetriesInjectedFaults(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryTarget("test")
+	mem.SetFaults(FaultConfig{FailOnNthPut: 1})
+
+	rt := NewRetryTarget(mem, 2, "linear")
+	if err := rt.Put(ctx, "k", strings.NewReader("v"), PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := mem.InjectedFaults(); got != 1 {
+		t.Errorf("InjectedFaults = %d, want 1", got)
+	}
+}
+
 func TestRetryTarget_NoRetryOnSuccess(t *testing.T) {
 	ctx := context.Background()
 	mem := NewMemoryTarget("test")
@@ -481,6 +565,372 @@ func TestNewTarget_UnsupportedType(t *testing.T) {
 	}
 }
 
+func TestNewTarget_SharesS3ClientPerRegion(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	a, err := NewTarget(Config{Name: "a", Type: "s3", Bucket: "bucket-a", Region: "us-west-2"})
+	if err != nil {
+		t.Fatalf("NewTarget a: %v", err)
+	}
+	b, err := NewTarget(Config{Name: "b", Type: "s3", Bucket: "bucket-b", Region: "us-west-2"})
+	if err != nil {
+		t.Fatalf("NewTarget b: %v", err)
+	}
+	c, err := NewTarget(Config{Name: "c", Type: "s3", Bucket: "bucket-c", Region: "eu-west-1"})
+	if err != nil {
+		t.Fatalf("NewTarget c: %v", err)
+	}
+
+	clientA := a.(*s3Target).client
+	if clientA != b.(*s3Target).client {
+		t.Error("targets in the same region should share an S3 client")
+	}
+	if clientA == c.(*s3Target).client {
+		t.Error("targets in different regions should not share an S3 client")
+	}
+}
+
+func TestNewTarget_S3Presets(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	const account = "0123456789abcdef0123456789abcdef"
+	for _, tt := range []struct {
+		cfg       Config
+		region    string
+		endpoint  string
+		pathStyle bool
+	}{
+		{Config{Name: "r2", Type: "r2", Bucket: "skills", AccountID: account}, "auto", "https://" + account + ".r2.cloudflarestorage.com", true},
+		{Config{Name: "spaces", Type: "spaces", Bucket: "skills", Region: "fra1", FIPS: true}, "us-east-1", "https://fra1.digitaloceanspaces.com", false},
+	} {
+		tgt, err := NewTarget(tt.cfg)
+		if err != nil {
+			t.Fatalf("NewTarget %s: %v", tt.cfg.Type, err)
+		}
+		opts := tgt.(*s3Target).client.Options()
+		if opts.Region != tt.region || aws.ToString(opts.BaseEndpoint) != tt.endpoint || opts.UsePathStyle != tt.pathStyle {
+			t.Errorf("%s client: region %q, endpoint %q, path style %v; want %q, %q, %v", tt.cfg.Type,
+				opts.Region, aws.ToString(opts.BaseEndpoint), opts.UsePathStyle, tt.region, tt.endpoint, tt.pathStyle)
+		}
+		if opts.EndpointOptions.UseFIPSEndpoint == aws.FIPSEndpointStateEnabled {
+			t.Errorf("%s client uses FIPS endpoints", tt.cfg.Type)
+		}
+	}
+
+	s3Tgt, err := NewTarget(Config{Name: "s3", Type: "s3", Bucket: "skills", Region: "us-east-1"})
+	if err != nil {
+		t.Fatalf("NewTarget s3: %v", err)
+	}
+	if opts := s3Tgt.(*s3Target).client.Options(); opts.BaseEndpoint != nil {
+		t.Errorf("s3 client endpoint = %q, want the AWS default", *opts.BaseEndpoint)
+	}
+}
+
+func TestNewTarget_S3PresetErrors(t *testing.T) {
+	for _, tt := range []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{Type: "r2", Bucket: "skills"}, "require account_id"},
+		{Config{Type: "r2", Bucket: "skills", AccountID: "my-account"}, "require account_id"},
+		{Config{Type: "r2", Bucket: "skills", AccountID: "0123456789abcdef0123456789abcdef", Region: "us-east-1"}, "do not take a region"},
+		{Config{Type: "spaces", Bucket: "skills"}, "require region"},
+		{Config{Type: "spaces", Bucket: "skills", Region: "us-east-1"}, "require region"},
+		{Config{Type: "spaces", Bucket: "skills", Region: "nyc3", KMSKeyID: "key"}, "kms_key_id is not supported"},
+	} {
+		tt.cfg.Name = tt.cfg.Type
+		if _, err := NewTarget(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("NewTarget(%+v) error = %v, want it to contain %q", tt.cfg, err, tt.want)
+		}
+	}
+}
+
+// fakeServiceAccountKey returns a service account key for email. Its
+// private key is never used: clients only sign tokens on their first
+// request.
+func fakeServiceAccountKey(email string) string {
+	return fmt.Sprintf(`{"type":"service_account","project_id":"test","private_key_id":"1","private_key":"unused","client_email":%q,"token_uri":"https://oauth2.googleapis.com/token"}`, email)
+}
+
+func TestGCSCredentials(t *testing.T) {
+	key := fakeServiceAccountKey("deployer@test.iam.gserviceaccount.com")
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(keyFile, []byte(key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := gcsCredentials(""); err != nil || got != nil {
+		t.Errorf("gcsCredentials(\"\") = %q, %v; want nil for Application Default Credentials", got, err)
+	}
+	for _, credentials := range []string{key, "\n" + key + "\n", keyFile} {
+		got, err := gcsCredentials(credentials)
+		if err != nil {
+			t.Errorf("gcsCredentials(%.20q): %v", credentials, err)
+			continue
+		}
+		if strings.TrimSpace(string(got)) != key {
+			t.Errorf("gcsCredentials(%.20q) = %q, want the key", credentials, got)
+		}
+	}
+
+	for credentials, want := range map[string]string{
+		filepath.Join(t.TempDir(), "missing.json"): "reading credentials file",
+		`{"client_email":"x"`:                      "not a valid JSON key",
+		`{"client_email":"x"}`:                     `no "type" field`,
+	} {
+		if _, err := gcsCredentials(credentials); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("gcsCredentials(%q) = %v, want an error containing %q", credentials, err, want)
+		}
+	}
+}
+
+func TestNewTarget_SharesGCSClientPerCredentials(t *testing.T) {
+	keyA := fakeServiceAccountKey("a@test.iam.gserviceaccount.com")
+	keyB := fakeServiceAccountKey("b@test.iam.gserviceaccount.com")
+
+	a, err := NewTarget(Config{Name: "a", Type: "gcs", Bucket: "bucket-a", GCSCredentials: keyA})
+	if err != nil {
+		t.Fatalf("NewTarget a: %v", err)
+	}
+	b, err := NewTarget(Config{Name: "b", Type: "gcs", Bucket: "bucket-b", GCSCredentials: keyA})
+	if err != nil {
+		t.Fatalf("NewTarget b: %v", err)
+	}
+	c, err := NewTarget(Config{Name: "c", Type: "gcs", Bucket: "bucket-c", GCSCredentials: keyB})
+	if err != nil {
+		t.Fatalf("NewTarget c: %v", err)
+	}
+
+	clientA := a.(*gcsTarget).client
+	if clientA != b.(*gcsTarget).client {
+		t.Error("targets with the same credentials should share a GCS client")
+	}
+	if clientA == c.(*gcsTarget).client {
+		t.Error("targets with different credentials should not share a GCS client")
+	}
+
+	if _, err := NewTarget(Config{Name: "d", Type: "gcs", Bucket: "bucket-d", GCSCredentials: `{"client_email":"x"}`}); err == nil {
+		t.Error("NewTarget with invalid credentials: expected error, got nil")
+	}
+}
+
+func TestAzureSASToken(t *testing.T) {
+	const token = "sv=2022-11-02&ss=b&srt=co&sp=racwdl&se=2030-01-01T00:00:00Z&sig=abc%2Bdef%3D"
+
+	if got, err := azureSASToken(""); err != nil || got != "" {
+		t.Errorf(`azureSASToken("") = %q, %v; want no token`, got, err)
+	}
+	for _, in := range []string{token, "?" + token, " " + token + "\n"} {
+		if got, err := azureSASToken(in); err != nil || got != token {
+			t.Errorf("azureSASToken(%q) = %q, %v; want %q", in, got, err, token)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"sv=2022-11-02&sp=racwdl": `no "sig" parameter`,
+		"sig=%zz":                 "not a valid query string",
+	} {
+		if _, err := azureSASToken(in); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("azureSASToken(%q) = %v, want an error containing %q", in, err, want)
+		}
+	}
+}
+
+func TestNewTarget_SharesAzureClientPerAuth(t *testing.T) {
+	const (
+		sasA     = "sv=2022-11-02&sp=racwdl&sig=a"
+		sasB     = "sv=2022-11-02&sp=racwdl&sig=b"
+		clientID = "00000000-0000-0000-0000-000000000001"
+	)
+	newAzure := func(name, sas, id string) *azureTarget {
+		t.Helper()
+		tgt, err := NewTarget(Config{Name: name, Type: "azure", StorageAccount: "acct", ContainerName: name, AzureSASToken: sas, AzureClientID: id})
+		if err != nil {
+			t.Fatalf("NewTarget %s: %v", name, err)
+		}
+		return tgt.(*azureTarget)
+	}
+
+	a := newAzure("a", sasA, "")
+	if a.client != newAzure("b", "?"+sasA, "").client {
+		t.Error("targets with the same SAS token should share an Azure client")
+	}
+	if a.client == newAzure("c", sasB, "").client {
+		t.Error("targets with different SAS tokens should not share an Azure client")
+	}
+	mi := newAzure("d", "", clientID)
+	if mi.client == a.client || mi.client != newAzure("e", "", clientID).client {
+		t.Error("targets with the same managed identity should share one Azure client of their own")
+	}
+
+	if _, err := NewTarget(Config{Name: "f", Type: "azure", StorageAccount: "acct", ContainerName: "f", AzureSASToken: sasA, AzureClientID: clientID}); err == nil {
+		t.Error("NewTarget with a SAS token and a managed identity: expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// RateLimitedTarget tests
+// ---------------------------------------------------------------------------
+
+func TestRateLimitedTarget_BoundsRequestRate(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryTarget("test")
+	if err := mem.Put(ctx, "key", strings.NewReader("data"), PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// 50 requests per second with a burst of 1: six requests take at
+	// least five intervals of 20ms.
+	limiter := rate.NewLimiter(rate.Limit(50), 1)
+	a := NewRateLimitedTarget(mem, limiter)
+	b := NewRateLimitedTarget(mem, limiter)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := a.Head(ctx, "key"); err != nil {
+			t.Fatalf("Head a: %v", err)
+		}
+		if _, err := b.Head(ctx, "key"); err != nil {
+			t.Fatalf("Head b: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("6 requests through a shared 50 QPS limiter took %v, want >= 100ms", elapsed)
+	}
+}
+
+func TestRateLimitedTarget_HonorsContextCancellation(t *testing.T) {
+	limiter := rate.NewLimiter(rate.Limit(0.001), 1)
+	rl := NewRateLimitedTarget(NewMemoryTarget("test"), limiter)
+
+	// Consume the only token, then cancel while waiting for the next.
+	if err := rl.Put(context.Background(), "a", strings.NewReader("x"), PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rl.Put(ctx, "b", strings.NewReader("y"), PutOptions{}); err == nil {
+		t.Fatal("expected error from cancelled context, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// BandwidthLimitedTarget tests
+// ---------------------------------------------------------------------------
+
+func TestBandwidthLimitedTarget_BoundsUploadRate(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemoryTarget("test")
+
+	// 10 KiB/s with a 1 KiB burst: two 1 KiB uploads through separate
+	// targets sharing the limiter take at least 100ms after the burst.
+	limiter := rate.NewLimiter(rate.Limit(10<<10), 1<<10)
+	a := NewBandwidthLimitedTarget(mem, limiter)
+	b := NewBandwidthLimitedTarget(mem, limiter)
+	body := strings.Repeat("x", 1<<10)
+
+	start := time.Now()
+	if err := a.Put(ctx, "a", strings.NewReader(body), PutOptions{}); err != nil {
+		t.Fatalf("Put a: %v", err)
+	}
+	if err := b.ConditionalPut(ctx, "b", strings.NewReader(body), WriteCondition{}, PutOptions{}); err != nil {
+		t.Fatalf("ConditionalPut b: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("2 KiB through a shared 10 KiB/s limiter took %v, want >= 100ms", elapsed)
+	}
+
+	rc, _, err := a.Get(ctx, "b")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer rc.Close()
+	if got, _ := io.ReadAll(rc); string(got) != body {
+		t.Errorf("uploaded body has %d bytes, want %d", len(got), len(body))
+	}
+}
+
+func TestBandwidthLimitedTarget_KeepsBodySeekable(t *testing.T) {
+	bl := &BandwidthLimitedTarget{limiter: NewBandwidthLimiter(1 << 20)}
+	if _, ok := bl.throttle(context.Background(), strings.NewReader("x")).(io.Seeker); !ok {
+		t.Error("throttled strings.Reader is not an io.Seeker")
+	}
+	if _, ok := bl.throttle(context.Background(), io.MultiReader(strings.NewReader("x"))).(io.Seeker); ok {
+		t.Error("throttled MultiReader is an io.Seeker")
+	}
+}
+
+func TestNewBandwidthLimiter(t *testing.T) {
+	for _, tc := range []struct {
+		bps       int64
+		wantBurst int
+	}{
+		{1, 1}, {1000, 1000}, {1 << 30, maxBandwidthBurst},
+	} {
+		if got := NewBandwidthLimiter(tc.bps).Burst(); got != tc.wantBurst {
+			t.Errorf("NewBandwidthLimiter(%d).Burst() = %d, want %d", tc.bps, got, tc.wantBurst)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Probe tests
+// ---------------------------------------------------------------------------
+
+// denyListDeleteTarget wraps a Target and rejects List and Delete calls, as
+// a bucket policy granting only object read/write would.
+type denyListDeleteTarget struct {
+	Target
+}
+
+func (d *denyListDeleteTarget) List(context.Context, string) ([]ObjectInfo, error) {
+	return nil, errors.New("AccessDenied: s3:ListBucket")
+}
+
+func (d *denyListDeleteTarget) Delete(context.Context, string) error {
+	return errors.New("AccessDenied: s3:DeleteObject")
+}
+
+func TestProbe(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryTarget("test")
+
+	if err := Probe(ctx, m); err != nil {
+		t.Fatalf("Probe: unexpected error: %v", err)
+	}
+
+	// The probe object must not be left behind.
+	objs, err := m.List(ctx, "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objs) != 0 {
+		t.Errorf("expected no objects after probe, got %d", len(objs))
+	}
+}
+
+func TestProbe_ReportsEveryFailingOperation(t *testing.T) {
+	ctx := context.Background()
+	d := &denyListDeleteTarget{Target: NewMemoryTarget("test")}
+
+	err := Probe(ctx, d)
+	if err == nil {
+		t.Fatal("Probe: expected error, got nil")
+	}
+	for _, want := range []string{"list: AccessDenied: s3:ListBucket", "delete: AccessDenied: s3:DeleteObject"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err.Error(), want)
+		}
+	}
+	if strings.Contains(err.Error(), "get:") {
+		t.Errorf("error %q unexpectedly reports get", err.Error())
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Helper: verify MemoryTarget implements Target interface at compile time.
 // ---------------------------------------------------------------------------
@@ -493,3 +943,35 @@ var _ interface {
 
 // Verify the unused import suppressor.
 var _ = bytes.NewReader
+
+func TestReadOnlyTarget(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemoryTarget("ro")
+	if err := inner.Put(ctx, "key", strings.NewReader("v1"), PutOptions{}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	ro := NewReadOnlyTarget(inner)
+
+	if err := ro.Put(ctx, "key", strings.NewReader("v2"), PutOptions{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Put: got err = %v, want ErrReadOnly", err)
+	}
+	if err := ro.ConditionalPut(ctx, "key", strings.NewReader("v2"), WriteCondition{IfMatch: "*"}, PutOptions{}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("ConditionalPut: got err = %v, want ErrReadOnly", err)
+	}
+	if err := ro.Delete(ctx, "key"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Delete: got err = %v, want ErrReadOnly", err)
+	}
+
+	rc, _, err := ro.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	if string(data) != "v1" {
+		t.Errorf("Get = %q, want %q", data, "v1")
+	}
+	if objs, err := ro.List(ctx, ""); err != nil || len(objs) != 1 {
+		t.Errorf("List = %v, %v; want one object", objs, err)
+	}
+}
@@ -0,0 +1,56 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// DeploymentAliasPattern matches deployment aliases: up to 128 letters,
+// digits, '.', '_', '+', and '-', starting with a letter or digit, so that
+// release tags such as v1.4.0 or 2026.10.1+build.7 fit.
+var DeploymentAliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]{0,127}$`)
+
+// CheckDeploymentAlias returns an error describing why alias is not a valid
+// deployment alias, or nil if it is one. Aliases may not start with "dep_",
+// so they are never mistaken for generated deployment IDs.
+func CheckDeploymentAlias(alias string) error {
+	if !DeploymentAliasPattern.MatchString(alias) {
+		return fmt.Errorf("%q must be 1-128 letters, digits, '.', '_', '+', or '-', starting with a letter or digit", alias)
+	}
+	if strings.HasPrefix(alias, "dep_") {
+		return fmt.Errorf("%q starts with \"dep_\", which is reserved for generated deployment IDs", alias)
+	}
+	return nil
+}
+
+// DeploymentAlias returns a validator that checks a string attribute with
+// CheckDeploymentAlias.
+func DeploymentAlias() validator.String {
+	return deploymentAliasValidator{}
+}
+
+// deploymentAliasValidator implements DeploymentAlias.
+type deploymentAliasValidator struct{}
+
+func (v deploymentAliasValidator) Description(_ context.Context) string {
+	return "must be 1-128 letters, digits, '.', '_', '+', or '-', starting with a letter or digit and not with \"dep_\""
+}
+
+func (v deploymentAliasValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v deploymentAliasValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if err := CheckDeploymentAlias(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, errcode.InvalidConfig.Summary("Invalid Deployment Alias"), err.Error())
+	}
+}
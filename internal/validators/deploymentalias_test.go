@@ -0,0 +1,47 @@
+package validators
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCheckDeploymentAlias(t *testing.T) {
+	for alias, wantErr := range map[string]bool{
+		"v1.4.0":                        false,
+		"2026.10.1+build.7":             false,
+		"release_candidate-2":           false,
+		"":                              true,
+		"-v1":                           true,
+		"v1/hotfix":                     true,
+		"v1 hotfix":                     true,
+		"dep_20260213T200102Z_6f2c9a1b": true,
+		"dep_release":                   true,
+		"deploy-1":                      false,
+		strings.Repeat("a", 128):        false,
+		strings.Repeat("a", 129):        true,
+	} {
+		if err := CheckDeploymentAlias(alias); (err != nil) != wantErr {
+			t.Errorf("CheckDeploymentAlias(%q) = %v, want error %t", alias, err, wantErr)
+		}
+	}
+}
+
+func TestDeploymentAlias(t *testing.T) {
+	for value, wantErr := range map[types.String]bool{
+		types.StringValue("v1.4.0"): false,
+		types.StringValue("dep_v1"): true,
+		types.StringNull():          false,
+		types.StringUnknown():       false,
+	} {
+		resp := &validator.StringResponse{}
+		DeploymentAlias().ValidateString(context.Background(), validator.StringRequest{Path: path.Root("deployment_alias"), ConfigValue: value}, resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Errorf("DeploymentAlias() on %v: errors = %v, want error %t", value, resp.Diagnostics, wantErr)
+		}
+	}
+}
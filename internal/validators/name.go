@@ -0,0 +1,72 @@
+// Package validators holds the schema validators shared by the provider's
+// resources and data sources, so that the same kind of argument is checked
+// the same way everywhere.
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// MaxNameLength is the longest name Claude Code accepts for a skill,
+// sub-agent, plugin, or command.
+const MaxNameLength = 64
+
+// NamePattern matches kebab-case names: lowercase letters, numbers, and
+// single hyphens, starting and ending with a letter or number.
+var NamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ReservedWords may not appear as a hyphen-separated part of a name, so
+// that user components cannot pass for ones Anthropic ships.
+var ReservedWords = []string{"anthropic", "claude"}
+
+// CheckName returns an error describing why name is not a valid component
+// name, or nil if it is one.
+func CheckName(name string) error {
+	if !NamePattern.MatchString(name) {
+		return fmt.Errorf("%q must contain only lowercase letters, numbers, and hyphens, and must start and end with a letter or number", name)
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("%q is %d characters long; names are limited to %d", name, len(name), MaxNameLength)
+	}
+	for _, part := range strings.Split(name, "-") {
+		for _, reserved := range ReservedWords {
+			if part == reserved {
+				return fmt.Errorf("%q contains the reserved word %q", name, reserved)
+			}
+		}
+	}
+	return nil
+}
+
+// Name returns a validator that checks a string attribute with CheckName.
+func Name() validator.String {
+	return nameValidator{}
+}
+
+// nameValidator implements Name.
+type nameValidator struct{}
+
+func (v nameValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("must be a kebab-case name of at most %d characters that does not use the reserved words %s",
+		MaxNameLength, strings.Join(ReservedWords, ", "))
+}
+
+func (v nameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v nameValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if err := CheckName(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, errcode.InvalidConfig.Summary("Invalid Name"), err.Error())
+	}
+}
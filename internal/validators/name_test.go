@@ -0,0 +1,57 @@
+package validators
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCheckName(t *testing.T) {
+	valid := []string{"reviewer", "code-reviewer", "a1", "claudette", "my-anthropics", strings.Repeat("a", MaxNameLength)}
+	for _, name := range valid {
+		if err := CheckName(name); err != nil {
+			t.Errorf("CheckName(%q): unexpected error: %v", name, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"Reviewer":                           "lowercase",
+		"code_reviewer":                      "lowercase",
+		"-reviewer":                          "start and end",
+		"code--reviewer":                     "lowercase",
+		"":                                   "lowercase",
+		strings.Repeat("a", MaxNameLength+1): "limited to 64",
+		"claude":                             `reserved word "claude"`,
+		"claude-helper":                      `reserved word "claude"`,
+		"team-anthropic-tools":               `reserved word "anthropic"`,
+	}
+	for name, want := range invalid {
+		err := CheckName(name)
+		if err == nil {
+			t.Errorf("CheckName(%q): expected an error", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckName(%q) = %v, want it to mention %s", name, err, want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	for value, wantErr := range map[types.String]bool{
+		types.StringValue("reviewer"):      false,
+		types.StringValue("claude-helper"): true,
+		types.StringNull():                 false,
+		types.StringUnknown():              false,
+	} {
+		resp := &validator.StringResponse{}
+		Name().ValidateString(context.Background(), validator.StringRequest{Path: path.Root("name"), ConfigValue: value}, resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Errorf("Name() on %v: errors = %v, want error %t", value, resp.Diagnostics, wantErr)
+		}
+	}
+}
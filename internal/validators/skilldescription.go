@@ -0,0 +1,62 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// MaxSkillDescriptionLength is the longest description, in characters,
+// Claude accepts in the SKILL.md frontmatter of a skill.
+const MaxSkillDescriptionLength = 1024
+
+// xmlTagPattern matches an opening, closing, or self-closing XML tag, which
+// skill descriptions may not contain.
+var xmlTagPattern = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9_.:-]*(\s[^<>]*)?/?>`)
+
+// CheckSkillDescription returns an error describing why description is not
+// a valid skill description, or nil if it is one.
+func CheckSkillDescription(description string) error {
+	if strings.TrimSpace(description) == "" {
+		return fmt.Errorf("a skill description must not be empty")
+	}
+	if n := utf8.RuneCountInString(description); n > MaxSkillDescriptionLength {
+		return fmt.Errorf("the description is %d characters long; skill descriptions are limited to %d", n, MaxSkillDescriptionLength)
+	}
+	if tag := xmlTagPattern.FindString(description); tag != "" {
+		return fmt.Errorf("the description contains the XML tag %q; skill descriptions may not contain XML tags", tag)
+	}
+	return nil
+}
+
+// SkillDescription returns a validator that checks a string attribute with
+// CheckSkillDescription.
+func SkillDescription() validator.String {
+	return skillDescriptionValidator{}
+}
+
+// skillDescriptionValidator implements SkillDescription.
+type skillDescriptionValidator struct{}
+
+func (v skillDescriptionValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("must be a non-empty skill description of at most %d characters without XML tags", MaxSkillDescriptionLength)
+}
+
+func (v skillDescriptionValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v skillDescriptionValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if err := CheckSkillDescription(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, errcode.InvalidConfig.Summary("Invalid Skill Description"), err.Error())
+	}
+}
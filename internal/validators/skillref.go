@@ -0,0 +1,56 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+
+	"github.com/agentctx/terraform-provider-agentctx/internal/errcode"
+)
+
+// SkillIDPattern matches the IDs the Anthropic registry assigns to skills,
+// such as skill_01AbCdEf.
+var SkillIDPattern = regexp.MustCompile(`^skill_[A-Za-z0-9_]+$`)
+
+// IsSkillID reports whether ref is an Anthropic registry skill ID rather
+// than a skill name.
+func IsSkillID(ref string) bool {
+	return SkillIDPattern.MatchString(ref)
+}
+
+// CheckSkillReference returns an error describing why ref is neither a
+// skill name nor a registry skill ID, or nil if it is one of them.
+func CheckSkillReference(ref string) error {
+	if IsSkillID(ref) || NamePattern.MatchString(ref) {
+		return nil
+	}
+	return fmt.Errorf("%q must be a skill name of lowercase letters, numbers, and hyphens, or an Anthropic skill ID such as skill_01AbCdEf", ref)
+}
+
+// SkillReference returns a validator that checks a string attribute with
+// CheckSkillReference.
+func SkillReference() validator.String {
+	return skillReferenceValidator{}
+}
+
+// skillReferenceValidator implements SkillReference.
+type skillReferenceValidator struct{}
+
+func (v skillReferenceValidator) Description(_ context.Context) string {
+	return "must be a kebab-case skill name or an Anthropic skill ID"
+}
+
+func (v skillReferenceValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v skillReferenceValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if err := CheckSkillReference(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, errcode.InvalidConfig.Summary("Invalid Skill Reference"), err.Error())
+	}
+}
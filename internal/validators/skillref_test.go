@@ -0,0 +1,48 @@
+package validators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCheckSkillReference(t *testing.T) {
+	for ref, wantErr := range map[string]bool{
+		"code-review":        false,
+		"claude-helper":      false, // skill names come from directories; CheckName is stricter
+		"skill_01AbCdEf":     false,
+		"skill_mock_1":       false,
+		"Code-Review":        true,
+		"skill-01abcdef":     false, // a name, not an ID
+		"skill_":             true,
+		"../code-review":     true,
+		"":                   true,
+		"skill_01AbCdEf/1.0": true,
+	} {
+		if err := CheckSkillReference(ref); (err != nil) != wantErr {
+			t.Errorf("CheckSkillReference(%q) = %v, want error %t", ref, err, wantErr)
+		}
+	}
+
+	if !IsSkillID("skill_01AbCdEf") || IsSkillID("code-review") {
+		t.Error("IsSkillID does not tell registry IDs from names")
+	}
+}
+
+func TestSkillReference(t *testing.T) {
+	for value, wantErr := range map[types.String]bool{
+		types.StringValue("code-review"): false,
+		types.StringValue("Code Review"): true,
+		types.StringNull():               false,
+		types.StringUnknown():            false,
+	} {
+		resp := &validator.StringResponse{}
+		SkillReference().ValidateString(context.Background(), validator.StringRequest{Path: path.Root("depends_on_skills"), ConfigValue: value}, resp)
+		if resp.Diagnostics.HasError() != wantErr {
+			t.Errorf("SkillReference() on %v: errors = %v, want error %t", value, resp.Diagnostics, wantErr)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package validators
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultURLSchemes are the schemes URL attributes accept when the
+// provider's allowed_url_schemes is not set.
+var DefaultURLSchemes = []string{"https"}
+
+// URLSchemePattern matches a URL scheme as listed in allowed_url_schemes:
+// lowercase, as RFC 3986 schemes are compared case-insensitively.
+var URLSchemePattern = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+
+// CheckURL returns an error describing why raw is not a URL that may be
+// shipped in a generated manifest, or nil if it is one: it must be
+// absolute, use one of schemes, name a host (unless it is a file URL), and
+// carry no user name or password. A value with ${VAR} references, which
+// Claude Code expands at runtime, only has its scheme checked, and only
+// when the scheme is not itself a reference. Errors never repeat a
+// password.
+func CheckURL(raw string, schemes []string) error {
+	if strings.Contains(raw, "${") {
+		scheme, _, ok := strings.Cut(raw, "://")
+		if !ok || strings.Contains(scheme, "${") {
+			return nil
+		}
+		return checkScheme(scheme, raw, schemes)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL", raw)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("%q must be an absolute URL such as https://example.com", raw)
+	}
+	if err := checkScheme(u.Scheme, u.Redacted(), schemes); err != nil {
+		return err
+	}
+	if u.User != nil {
+		return fmt.Errorf("%q must not contain a user name or password; manifests are shipped to every user of the plugin", u.Redacted())
+	}
+	if u.Host == "" && !strings.EqualFold(u.Scheme, "file") {
+		return fmt.Errorf("%q has no host", raw)
+	}
+	return nil
+}
+
+// checkScheme implements the scheme allowlist of CheckURL for the URL
+// shown as display.
+func checkScheme(scheme, display string, schemes []string) error {
+	if slices.Contains(schemes, strings.ToLower(scheme)) {
+		return nil
+	}
+	return fmt.Errorf("%q uses the %s scheme; allowed schemes are %s (see the provider's allowed_url_schemes)",
+		display, strings.ToLower(scheme), strings.Join(schemes, ", "))
+}
+
+// NormalizeURL returns raw with its scheme and host lowercased, the form
+// written to generated files. Values CheckURL does not fully parse, such
+// as those with ${VAR} references, are returned unchanged.
+func NormalizeURL(raw string) string {
+	if strings.Contains(raw, "${") {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return u.String()
+}